@@ -13,16 +13,37 @@ import (
 	"github.com/go-chi/chi"
 
 	"github.com/disintegration/bebop/avatar"
+	"github.com/disintegration/bebop/events"
 	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/localauth"
+	"github.com/disintegration/bebop/session"
 	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/webhook"
 )
 
+// maxCursorLimit is the maximum page size of the cursor-paginated listings.
+const maxCursorLimit = 100
+
 // Config is an API handler configuration.
 type Config struct {
-	Logger        *log.Logger
-	Store         store.Store
-	JWTService    jwt.Service
-	AvatarService avatar.Service
+	Logger         *log.Logger
+	Store          store.Store
+	JWTService     jwt.Service
+	AvatarService  avatar.Service
+	SessionService session.Service
+	// LocalAuthService is nil if the email and password authentication is disabled.
+	LocalAuthService localauth.Service
+	// Reactions is the set of emoji names users can react to comments with.
+	Reactions []string
+	// Events is the hub the content events are published to.
+	// The event stream is not available if it is nil.
+	Events *events.Hub
+	// Webhooks is the dispatcher the webhook events are queued with.
+	// No webhook events are queued if it is nil.
+	Webhooks *webhook.Dispatcher
+	// SetOAuthLinkCookie passes a link code to the OAuth flow in a cookie
+	// of the signed in user's browser.
+	SetOAuthLinkCookie func(w http.ResponseWriter, code string)
 }
 
 // Handler handles API requests.
@@ -38,6 +59,27 @@ func New(config *Config) *Handler {
 	h.router = chi.NewRouter()
 
 	h.router.Get("/me", h.handleMe)
+	h.router.Get("/me/identities", h.handleGetIdentities)
+	h.router.Get("/me/name-suggestion", h.handleGetNameSuggestion)
+	h.router.Delete("/me/identities/{id}", h.handleDeleteIdentity)
+	h.router.Get("/me/digest", h.handleGetDigestMode)
+	h.router.Put("/me/digest", h.handleSetDigestMode)
+	h.router.Post("/digest/unsubscribe", h.handleDigestUnsubscribe)
+
+	h.router.Post("/auth/refresh", h.handleRefresh)
+	h.router.Post("/auth/exchange", h.handleExchange)
+	h.router.Post("/auth/link", h.handleLink)
+	h.router.Post("/auth/logout", h.handleLogout)
+	h.router.Post("/auth/logout-all", h.handleLogoutAll)
+
+	h.router.Post("/auth/register", h.handleRegister)
+	h.router.Post("/auth/verify", h.handleVerify)
+	h.router.Post("/auth/verify/resend", h.handleResendVerification)
+	h.router.Post("/auth/login", h.handleLogin)
+	h.router.Post("/auth/password-reset", h.handlePasswordReset)
+	h.router.Post("/auth/password-reset/confirm", h.handlePasswordResetConfirm)
+	h.router.Post("/auth/magic-link", h.handleMagicLink)
+	h.router.Post("/auth/magic-link/confirm", h.handleMagicLinkConfirm)
 
 	h.router.Get("/users", h.handleGetUsers)
 	h.router.Get("/users/{id}", h.handleGetUser)
@@ -48,12 +90,49 @@ func New(config *Config) *Handler {
 	h.router.Get("/topics", h.handleGetTopics)
 	h.router.Post("/topics", h.handleNewTopic)
 	h.router.Get("/topics/{id}", h.handleGetTopic)
+	h.router.Patch("/topics/{id}", h.handleEditTopic)
 	h.router.Delete("/topics/{id}", h.handleDeleteTopic)
+	h.router.Put("/topics/{id}/category", h.handleSetTopicCategory)
+	h.router.Put("/topics/{id}/pinned", h.handleSetTopicPinned)
+	h.router.Put("/topics/{id}/locked", h.handleSetTopicLocked)
+	h.router.Get("/topics/{id}/revisions", h.handleGetTopicRevisions)
+	h.router.Get("/topics/{id}/watching", h.handleGetTopicWatching)
+	h.router.Put("/topics/{id}/watching", h.handleSetTopicWatching)
 
 	h.router.Get("/comments", h.handleGetComments)
 	h.router.Post("/comments", h.handleNewComment)
 	h.router.Get("/comments/{id}", h.handleGetComment)
+	h.router.Patch("/comments/{id}", h.handleEditComment)
 	h.router.Delete("/comments/{id}", h.handleDeleteComment)
+	h.router.Get("/comments/{id}/revisions", h.handleGetCommentRevisions)
+	h.router.Post("/comments/{id}/reactions/{emoji}", h.handleAddReaction)
+	h.router.Delete("/comments/{id}/reactions/{emoji}", h.handleRemoveReaction)
+
+	h.router.Get("/categories", h.handleGetCategories)
+	h.router.Post("/categories", h.handleNewCategory)
+	h.router.Get("/categories/{id}", h.handleGetCategory)
+	h.router.Patch("/categories/{id}", h.handleEditCategory)
+	h.router.Delete("/categories/{id}", h.handleDeleteCategory)
+
+	h.router.Get("/reports", h.handleGetReports)
+	h.router.Post("/reports", h.handleNewReport)
+	h.router.Post("/reports/{id}/resolve", h.handleResolveReport)
+
+	h.router.Get("/audit", h.handleGetAudit)
+
+	h.router.Get("/webhooks", h.handleGetWebhooks)
+	h.router.Post("/webhooks", h.handleNewWebhook)
+	h.router.Get("/webhooks/{id}", h.handleGetWebhook)
+	h.router.Delete("/webhooks/{id}", h.handleDeleteWebhook)
+	h.router.Get("/webhooks/{id}/deliveries", h.handleGetWebhookDeliveries)
+
+	h.router.Get("/notifications", h.handleGetNotifications)
+	h.router.Post("/notifications/read", h.handleMarkAllNotificationsRead)
+	h.router.Post("/notifications/{id}/read", h.handleMarkNotificationRead)
+
+	h.router.Get("/search", h.handleSearch)
+
+	h.router.Get("/events", h.handleEvents)
 
 	return h
 }
@@ -75,7 +154,7 @@ func (h *Handler) currentUser(r *http.Request) *store.User {
 	}
 	token := authHeader[7:]
 
-	userID, _, err := h.JWTService.Verify(token)
+	userID, issuedAt, err := h.JWTService.Verify(token)
 	if err != nil {
 		return nil
 	}
@@ -92,9 +171,30 @@ func (h *Handler) currentUser(r *http.Request) *store.User {
 		return nil
 	}
 
+	// The tokens issued before the user logged out of all the sessions are revoked.
+	if issuedAt.Before(user.TokensValidAfter) {
+		return nil
+	}
+
 	return user
 }
 
+// can checks if the user is granted the permission by any of their roles.
+// Anonymous users have no permissions. Store errors deny the access.
+func (h *Handler) can(user *store.User, permission string) bool {
+	if user == nil {
+		return false
+	}
+
+	ok, err := h.Store.Roles().HasPermission(user.ID, permission)
+	if err != nil {
+		h.logError("check user permission: %s", err)
+		return false
+	}
+
+	return ok
+}
+
 func (h *Handler) parseRequest(r *http.Request, data interface{}) error {
 	const maxRequestLen = 16 * 1024 * 1024
 	lr := io.LimitReader(r.Body, maxRequestLen)
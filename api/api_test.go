@@ -1,13 +1,16 @@
 package api
 
 import (
+	"errors"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/disintegration/bebop/jwt"
 	"github.com/disintegration/bebop/store"
@@ -15,7 +18,7 @@ import (
 )
 
 func TestCurrentUser(t *testing.T) {
-	jwtService, err := jwt.NewService(strings.Repeat("0", 64))
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -33,10 +36,21 @@ func TestCurrentUser(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	user4 := &store.User{ID: 4, Name: "User4", TokensValidAfter: time.Now().Add(time.Minute)}
+	token4, err := jwtService.Create(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user5 := &store.User{ID: 5, Name: "User5", TokensValidAfter: time.Now().Add(-time.Minute)}
+	token5, err := jwtService.Create(5)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
 			UserStore: &mock.UserStore{
 				OnGet: func(id int64) (*store.User, error) {
 					switch id {
@@ -44,6 +58,10 @@ func TestCurrentUser(t *testing.T) {
 						return user1, nil
 					case 2:
 						return user2, nil
+					case 4:
+						return user4, nil
+					case 5:
+						return user5, nil
 					}
 					return nil, store.ErrNotFound
 				},
@@ -82,6 +100,16 @@ func TestCurrentUser(t *testing.T) {
 			token:    token1,
 			wantUser: user1,
 		},
+		{
+			desc:     "revoked token",
+			token:    token4,
+			wantUser: nil,
+		},
+		{
+			desc:     "token issued after revocation",
+			token:    token5,
+			wantUser: user5,
+		},
 	}
 
 	for _, tc := range tests {
@@ -99,6 +127,43 @@ func TestCurrentUser(t *testing.T) {
 	}
 }
 
+func TestCan(t *testing.T) {
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: &mock.RoleStore{
+				OnHasPermission: func(userID int64, permission string) (bool, error) {
+					if userID == 100 {
+						return false, errors.New("test error")
+					}
+					return getTestRoleStore().HasPermission(userID, permission)
+				},
+			},
+		},
+	})
+
+	tests := []struct {
+		desc       string
+		user       *store.User
+		permission string
+		want       bool
+	}{
+		{"anonymous", nil, store.PermCommentDelete, false},
+		{"user", &store.User{ID: 1}, store.PermCommentDelete, false},
+		{"admin", &store.User{ID: 2}, store.PermUserBlock, true},
+		{"moderator", &store.User{ID: 3}, store.PermCommentDelete, true},
+		{"moderator, admin permission", &store.User{ID: 3}, store.PermUserBlock, false},
+		{"unknown permission", &store.User{ID: 2}, "unknown", false},
+		{"store error", &store.User{ID: 100}, store.PermCommentDelete, false},
+	}
+
+	for _, tc := range tests {
+		if got := apiHandler.can(tc.user, tc.permission); got != tc.want {
+			t.Fatalf("test %q: want %v got %v", tc.desc, tc.want, got)
+		}
+	}
+}
+
 func TestRenderError(t *testing.T) {
 	tests := []struct {
 		desc          string
@@ -134,3 +199,61 @@ func TestRenderError(t *testing.T) {
 		}
 	}
 }
+
+// getTestRoleStore returns a role store where user 2 is an admin
+// and user 3 is a moderator.
+func getTestRoleStore() *mock.RoleStore {
+	return &mock.RoleStore{
+		OnGetByUser: func(userID int64) ([]string, error) {
+			switch userID {
+			case 2:
+				return []string{store.RoleAdmin}, nil
+			case 3:
+				return []string{store.RoleModerator}, nil
+			}
+			return []string{}, nil
+		},
+		OnGetUsers: func(role string) ([]int64, error) {
+			switch role {
+			case store.RoleAdmin:
+				return []int64{2}, nil
+			case store.RoleModerator:
+				return []int64{3}, nil
+			}
+			return []int64{}, nil
+		},
+		OnGetPermissions: func(roles []string) ([]string, error) {
+			permissions := []string{}
+			for _, permission := range store.AllPermissions {
+				for _, role := range roles {
+					if hasTestPermission(role, permission) {
+						permissions = append(permissions, permission)
+						break
+					}
+				}
+			}
+			sort.Strings(permissions)
+			return permissions, nil
+		},
+		OnHasPermission: func(userID int64, permission string) (bool, error) {
+			switch userID {
+			case 2:
+				return hasTestPermission(store.RoleAdmin, permission), nil
+			case 3:
+				return hasTestPermission(store.RoleModerator, permission), nil
+			}
+			return false, nil
+		},
+	}
+}
+
+// hasTestPermission reports whether the role is granted
+// the permission by default.
+func hasTestPermission(role, permission string) bool {
+	for _, p := range store.DefaultRolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+// auditState is a partial target state recorded in the audit log.
+type auditState map[string]interface{}
+
+// audit appends an entry to the audit log. The before and after states are
+// encoded to JSON. Failures are logged and do not affect the response.
+func (h *Handler) audit(r *http.Request, actor *store.User, action, targetType string, targetID int64, before, after interface{}) {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		h.logError("marshal audit state: %s", err)
+		return
+	}
+
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		h.logError("marshal audit state: %s", err)
+		return
+	}
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	_, err = h.Store.Audit().New(&store.AuditEntry{
+		ActorID:    actor.ID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+		IP:         ip,
+	})
+	if err != nil {
+		h.logError("add audit entry: %s", err)
+	}
+}
+
+func (h *Handler) handleGetAudit(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermAuditRead) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	var err error
+
+	filter := &store.AuditFilter{
+		Action:     r.URL.Query().Get("action"),
+		TargetType: r.URL.Query().Get("targetType"),
+	}
+
+	actorParam := r.URL.Query().Get("actor")
+	if actorParam != "" {
+		filter.ActorID, err = strconv.ParseInt(actorParam, 10, 64)
+		if err != nil || filter.ActorID < 0 {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid actor")
+			return
+		}
+	}
+
+	targetIDParam := r.URL.Query().Get("targetId")
+	if targetIDParam != "" {
+		filter.TargetID, err = strconv.ParseInt(targetIDParam, 10, 64)
+		if err != nil || filter.TargetID < 0 {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid target ID")
+			return
+		}
+	}
+
+	sinceParam := r.URL.Query().Get("since")
+	if sinceParam != "" {
+		filter.Since, err = time.Parse(time.RFC3339, sinceParam)
+		if err != nil {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid since")
+			return
+		}
+	}
+
+	untilParam := r.URL.Query().Get("until")
+	if untilParam != "" {
+		filter.Until, err = time.Parse(time.RFC3339, untilParam)
+		if err != nil {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid until")
+			return
+		}
+	}
+
+	offset := 0
+	offsetParam := r.URL.Query().Get("offset")
+	if offsetParam != "" {
+		offset, err = strconv.Atoi(offsetParam)
+		if err != nil || offset < 0 {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid offset")
+			return
+		}
+	}
+
+	limit := 10
+	limitParam := r.URL.Query().Get("limit")
+	if limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > 1000 {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid limit")
+			return
+		}
+	}
+
+	entries, count, err := h.Store.Audit().GetByFilter(filter, offset, limit)
+	if err != nil {
+		h.logError("get audit entries: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	response := struct {
+		Entries []*store.AuditEntry `json:"entries"`
+		Count   int                 `json:"count"`
+	}{
+		Entries: entries,
+		Count:   count,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/mock"
+)
+
+func TestHandleGetAudit(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := jwtService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := &store.AuditEntry{
+		ID:         1,
+		ActorID:    2,
+		Action:     store.AuditTopicPinned,
+		TargetType: store.AuditTargetTopic,
+		TargetID:   5,
+		Before:     json.RawMessage(`{"pinned":false}`),
+		After:      json.RawMessage(`{"pinned":true}`),
+		IP:         "192.0.2.1",
+		CreatedAt:  testTime,
+	}
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			UserStore: getReportTestUserStore(testTime),
+			AuditStore: &mock.AuditStore{
+				OnGetByFilter: func(filter *store.AuditFilter, offset, limit int) ([]*store.AuditEntry, int, error) {
+					if *filter == (store.AuditFilter{}) && offset == 0 && limit == 10 {
+						return []*store.AuditEntry{entry}, 1, nil
+					}
+					if filter.ActorID == 2 && filter.Action == store.AuditTopicPinned &&
+						filter.TargetType == store.AuditTargetTopic && filter.TargetID == 5 &&
+						filter.Since.Equal(testTime) && filter.Until.Equal(testTime.Add(time.Hour)) &&
+						offset == 1 && limit == 1 {
+						return []*store.AuditEntry{}, 1, nil
+					}
+					t.Fatalf("OnGetByFilter: unexpected params (unknown test)")
+					return nil, 0, nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc     string
+		url      string
+		token    string
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "no token",
+			url:      "/audit",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "not admin",
+			url:      "/audit",
+			token:    token1,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
+		},
+		{
+			desc:     "all entries",
+			url:      "/audit",
+			token:    token2,
+			wantCode: http.StatusOK,
+			wantBody: `{"entries":[{"id":1,"actorId":2,"action":"topic.pinned","targetType":"topic","targetId":5,"before":{"pinned":false},"after":{"pinned":true},"ip":"192.0.2.1","createdAt":"2001-02-03T04:05:06Z"}],"count":1}`,
+		},
+		{
+			desc:     "filtered entries",
+			url:      "/audit?actor=2&action=topic.pinned&targetType=topic&targetId=5&since=2001-02-03T04:05:06Z&until=2001-02-03T05:05:06Z&offset=1&limit=1",
+			token:    token2,
+			wantCode: http.StatusOK,
+			wantBody: `{"entries":[],"count":1}`,
+		},
+		{
+			desc:     "bad actor",
+			url:      "/audit?actor=BAD",
+			token:    token2,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid actor"}}`,
+		},
+		{
+			desc:     "bad target id",
+			url:      "/audit?targetId=-1",
+			token:    token2,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid target ID"}}`,
+		},
+		{
+			desc:     "bad since",
+			url:      "/audit?since=yesterday",
+			token:    token2,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid since"}}`,
+		},
+		{
+			desc:     "bad until",
+			url:      "/audit?until=2001-02-03",
+			token:    token2,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid until"}}`,
+		},
+		{
+			desc:     "bad limit",
+			url:      "/audit?limit=1001",
+			token:    token2,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid limit"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest("GET", tc.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+	}
+}
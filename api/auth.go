@@ -0,0 +1,147 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/disintegration/bebop/session"
+)
+
+func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	req := struct {
+		RefreshToken *string `json:"refreshToken"`
+	}{}
+
+	err := h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.RefreshToken == nil || *req.RefreshToken == "" {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid refresh token")
+		return
+	}
+
+	tokens, err := h.SessionService.Refresh(*req.RefreshToken)
+	switch {
+	case err == session.ErrInvalidToken:
+		h.renderError(w, http.StatusUnauthorized, "InvalidRefreshToken", "Refresh token is invalid or expired")
+		return
+	case err == session.ErrUserBlocked:
+		h.renderError(w, http.StatusForbidden, "UserBlocked", "User is blocked")
+		return
+	case err != nil:
+		h.logError("refresh session: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.render(w, http.StatusOK, tokens)
+}
+
+func (h *Handler) handleExchange(w http.ResponseWriter, r *http.Request) {
+	req := struct {
+		Code         *string `json:"code"`
+		CodeVerifier *string `json:"codeVerifier"`
+	}{}
+
+	err := h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.Code == nil || *req.Code == "" {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid code")
+		return
+	}
+
+	if req.CodeVerifier == nil || *req.CodeVerifier == "" {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid code verifier")
+		return
+	}
+
+	tokens, err := h.SessionService.Exchange(*req.Code, *req.CodeVerifier)
+	switch {
+	case err == session.ErrInvalidCode:
+		h.renderError(w, http.StatusUnauthorized, "InvalidCode", "Code is invalid or expired")
+		return
+	case err == session.ErrUserBlocked:
+		h.renderError(w, http.StatusForbidden, "UserBlocked", "User is blocked")
+		return
+	case err != nil:
+		h.logError("exchange code: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.render(w, http.StatusOK, tokens)
+}
+
+// handleLink issues a link code that starts linking a login provider identity
+// to the current user. The code is set in a cookie, never passed in a URL,
+// so the link is bound to the browser of the current user.
+func (h *Handler) handleLink(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	code, err := h.SessionService.CreateLinkCode(currentUser.ID, "")
+	if err != nil {
+		h.logError("create link code: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.SetOAuthLinkCookie(w, code)
+
+	h.render(w, http.StatusOK, struct{}{})
+}
+
+func (h *Handler) handleLogout(w http.ResponseWriter, r *http.Request) {
+	req := struct {
+		RefreshToken *string `json:"refreshToken"`
+	}{}
+
+	err := h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.RefreshToken == nil || *req.RefreshToken == "" {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid refresh token")
+		return
+	}
+
+	// Logging out of an ended session is not an error.
+	err = h.SessionService.Revoke(*req.RefreshToken)
+	if err != nil && err != session.ErrInvalidToken {
+		h.logError("revoke session: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.render(w, http.StatusOK, struct{}{})
+}
+
+func (h *Handler) handleLogoutAll(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	err := h.SessionService.RevokeAll(currentUser.ID)
+	if err != nil {
+		h.logError("revoke all sessions: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.render(w, http.StatusOK, struct{}{})
+}
@@ -0,0 +1,408 @@
+package api
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/session"
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/mock"
+)
+
+func TestHandleRefresh(t *testing.T) {
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		SessionService: &session.MockService{
+			OnRefresh: func(refreshToken string) (*session.Tokens, error) {
+				switch refreshToken {
+				case "token1":
+					return &session.Tokens{AccessToken: "access2", RefreshToken: "token2"}, nil
+				case "token3":
+					return nil, session.ErrUserBlocked
+				}
+				return nil, session.ErrInvalidToken
+			},
+		},
+	})
+
+	tests := []struct {
+		desc     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "good token",
+			body:     `{"refreshToken":"token1"}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"accessToken":"access2","refreshToken":"token2"}`,
+		},
+		{
+			desc:     "invalid token",
+			body:     `{"refreshToken":"token2"}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"InvalidRefreshToken","message":"Refresh token is invalid or expired"}}`,
+		},
+		{
+			desc:     "blocked user",
+			body:     `{"refreshToken":"token3"}`,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"UserBlocked","message":"User is blocked"}}`,
+		},
+		{
+			desc:     "no token",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid refresh token"}}`,
+		},
+		{
+			desc:     "bad body",
+			body:     `BAD`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid request body"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest("POST", "/auth/refresh", ioutil.NopCloser(strings.NewReader(tc.body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+	}
+}
+
+func TestHandleExchange(t *testing.T) {
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		SessionService: &session.MockService{
+			OnExchange: func(code, codeVerifier string) (*session.Tokens, error) {
+				switch {
+				case code == "code1" && codeVerifier == "verifier1":
+					return &session.Tokens{AccessToken: "access1", RefreshToken: "token1"}, nil
+				case code == "code2" && codeVerifier == "verifier2":
+					return nil, session.ErrUserBlocked
+				}
+				return nil, session.ErrInvalidCode
+			},
+		},
+	})
+
+	tests := []struct {
+		desc     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "good code",
+			body:     `{"code":"code1","codeVerifier":"verifier1"}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"accessToken":"access1","refreshToken":"token1"}`,
+		},
+		{
+			desc:     "wrong verifier",
+			body:     `{"code":"code1","codeVerifier":"verifier2"}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"InvalidCode","message":"Code is invalid or expired"}}`,
+		},
+		{
+			desc:     "blocked user",
+			body:     `{"code":"code2","codeVerifier":"verifier2"}`,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"UserBlocked","message":"User is blocked"}}`,
+		},
+		{
+			desc:     "no code",
+			body:     `{"codeVerifier":"verifier1"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid code"}}`,
+		},
+		{
+			desc:     "no verifier",
+			body:     `{"code":"code1"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid code verifier"}}`,
+		},
+		{
+			desc:     "bad body",
+			body:     `BAD`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid request body"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest("POST", "/auth/exchange", ioutil.NopCloser(strings.NewReader(tc.body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+	}
+}
+
+func TestHandleLink(t *testing.T) {
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			UserStore: &mock.UserStore{
+				OnGet: func(id int64) (*store.User, error) {
+					if id == 1 {
+						return &store.User{ID: 1, Name: "TestUser1"}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+			},
+		},
+		JWTService: jwtService,
+		SessionService: &session.MockService{
+			OnCreateLinkCode: func(userID int64, state string) (string, error) {
+				return "link-code-" + strconv.FormatInt(userID, 10) + state, nil
+			},
+		},
+		SetOAuthLinkCookie: func(w http.ResponseWriter, code string) {
+			http.SetCookie(w, &http.Cookie{Name: "link", Value: code, HttpOnly: true})
+		},
+	})
+
+	tests := []struct {
+		desc       string
+		token      string
+		wantCode   int
+		wantBody   string
+		wantCookie string
+	}{
+		{
+			desc:       "signed in",
+			token:      token1,
+			wantCode:   http.StatusOK,
+			wantBody:   `{}`,
+			wantCookie: "link-code-1",
+		},
+		{
+			desc:     "no token",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest("POST", "/auth/link", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+
+		var cookie string
+		for _, c := range w.Result().Cookies() {
+			if c.Name == "link" {
+				cookie = c.Value
+			}
+		}
+		if tc.wantCookie != cookie {
+			t.Fatalf("test %q: want link cookie %q got %q", tc.desc, tc.wantCookie, cookie)
+		}
+	}
+}
+
+func TestHandleLogout(t *testing.T) {
+	var revoked string
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		SessionService: &session.MockService{
+			OnRevoke: func(refreshToken string) error {
+				if refreshToken != "token1" {
+					return session.ErrInvalidToken
+				}
+				revoked = refreshToken
+				return nil
+			},
+		},
+	})
+
+	tests := []struct {
+		desc        string
+		body        string
+		wantCode    int
+		wantBody    string
+		wantRevoked string
+	}{
+		{
+			desc:        "good token",
+			body:        `{"refreshToken":"token1"}`,
+			wantCode:    http.StatusOK,
+			wantBody:    `{}`,
+			wantRevoked: "token1",
+		},
+		{
+			desc:     "ended session",
+			body:     `{"refreshToken":"token2"}`,
+			wantCode: http.StatusOK,
+			wantBody: `{}`,
+		},
+		{
+			desc:     "no token",
+			body:     `{"refreshToken":""}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid refresh token"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		revoked = ""
+
+		req, err := http.NewRequest("POST", "/auth/logout", ioutil.NopCloser(strings.NewReader(tc.body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+
+		if tc.wantRevoked != revoked {
+			t.Fatalf("test %q: want revoked token %q got %q", tc.desc, tc.wantRevoked, revoked)
+		}
+	}
+}
+
+func TestHandleLogoutAll(t *testing.T) {
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var revokedUserID int64
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			UserStore: &mock.UserStore{
+				OnGet: func(id int64) (*store.User, error) {
+					if id == 1 {
+						return &store.User{ID: 1, Name: "TestUser1"}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+			},
+		},
+		JWTService: jwtService,
+		SessionService: &session.MockService{
+			OnRevokeAll: func(userID int64) error {
+				revokedUserID = userID
+				return nil
+			},
+		},
+	})
+
+	tests := []struct {
+		desc          string
+		token         string
+		wantCode      int
+		wantBody      string
+		wantRevokedID int64
+	}{
+		{
+			desc:     "no token",
+			token:    "",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:          "good token",
+			token:         token1,
+			wantCode:      http.StatusOK,
+			wantBody:      `{}`,
+			wantRevokedID: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		revokedUserID = 0
+
+		req, err := http.NewRequest("POST", "/auth/logout-all", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+
+		if tc.wantRevokedID != revokedUserID {
+			t.Fatalf("test %q: want revoked user %d got %d", tc.desc, tc.wantRevokedID, revokedUserID)
+		}
+	}
+}
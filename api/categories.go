@@ -0,0 +1,289 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/disintegration/bebop/store"
+)
+
+// getCategoryByParam finds a category by a numeric ID or a slug.
+func (h *Handler) getCategoryByParam(param string) (*store.Category, error) {
+	id, err := strconv.ParseInt(param, 10, 64)
+	if err == nil {
+		return h.Store.Categories().Get(id)
+	}
+	return h.Store.Categories().GetBySlug(param)
+}
+
+func (h *Handler) handleGetCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.Store.Categories().GetAll()
+	if err != nil {
+		h.logError("get all categories: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	response := struct {
+		Categories []*store.Category `json:"categories"`
+	}{
+		Categories: categories,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+func (h *Handler) handleGetCategory(w http.ResponseWriter, r *http.Request) {
+	category, err := h.getCategoryByParam(h.urlParam(r, "id"))
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Category not found")
+			return
+		}
+		h.logError("get category: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	response := struct {
+		Category *store.Category `json:"category"`
+	}{
+		Category: category,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+func (h *Handler) handleNewCategory(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermCategoryManage) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	req := struct {
+		Slug        *string `json:"slug"`
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+		SortOrder   *int    `json:"sortOrder"`
+		AdminOnly   *bool   `json:"adminOnly"`
+	}{}
+
+	err := h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.Slug == nil || !store.ValidCategorySlug(*req.Slug) {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid category slug")
+		return
+	}
+
+	if req.Name == nil || !store.ValidCategoryName(*req.Name) {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid category name")
+		return
+	}
+
+	description := ""
+	if req.Description != nil {
+		description = *req.Description
+	}
+	if !store.ValidCategoryDescription(description) {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid category description")
+		return
+	}
+
+	sortOrder := 0
+	if req.SortOrder != nil {
+		sortOrder = *req.SortOrder
+	}
+
+	adminOnly := false
+	if req.AdminOnly != nil {
+		adminOnly = *req.AdminOnly
+	}
+
+	id, err := h.Store.Categories().New(*req.Slug, *req.Name, description, sortOrder, adminOnly)
+	if err != nil {
+		if err == store.ErrConflict {
+			h.renderError(w, http.StatusConflict, "UnavailableCategorySlug", "Category slug is already taken")
+			return
+		}
+		h.logError("create category: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.audit(r, currentUser, store.AuditCategoryCreate, store.AuditTargetCategory, id, nil, &store.Category{
+		ID:          id,
+		Slug:        *req.Slug,
+		Name:        *req.Name,
+		Description: description,
+		SortOrder:   sortOrder,
+		AdminOnly:   adminOnly,
+	})
+
+	response := struct {
+		ID int64 `json:"id"`
+	}{
+		ID: id,
+	}
+
+	h.render(w, http.StatusCreated, response)
+}
+
+func (h *Handler) handleEditCategory(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermCategoryManage) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid category ID")
+		return
+	}
+
+	category, err := h.Store.Categories().Get(id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Category not found")
+			return
+		}
+		h.logError("get category: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+	before := *category
+
+	req := struct {
+		Slug        *string `json:"slug"`
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+		SortOrder   *int    `json:"sortOrder"`
+		AdminOnly   *bool   `json:"adminOnly"`
+	}{}
+
+	err = h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.Slug != nil {
+		if !store.ValidCategorySlug(*req.Slug) {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid category slug")
+			return
+		}
+		category.Slug = *req.Slug
+	}
+
+	if req.Name != nil {
+		if !store.ValidCategoryName(*req.Name) {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid category name")
+			return
+		}
+		category.Name = *req.Name
+	}
+
+	if req.Description != nil {
+		if !store.ValidCategoryDescription(*req.Description) {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid category description")
+			return
+		}
+		category.Description = *req.Description
+	}
+
+	if req.SortOrder != nil {
+		category.SortOrder = *req.SortOrder
+	}
+
+	if req.AdminOnly != nil {
+		category.AdminOnly = *req.AdminOnly
+	}
+
+	err = h.Store.Categories().Update(category)
+	if err != nil {
+		if err == store.ErrConflict {
+			h.renderError(w, http.StatusConflict, "UnavailableCategorySlug", "Category slug is already taken")
+			return
+		}
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Category not found")
+			return
+		}
+		h.logError("update category: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.audit(r, currentUser, store.AuditCategoryUpdate, store.AuditTargetCategory, id, &before, category)
+
+	response := struct {
+		Category *store.Category `json:"category"`
+	}{
+		Category: category,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+func (h *Handler) handleDeleteCategory(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermCategoryManage) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid category ID")
+		return
+	}
+
+	category, err := h.Store.Categories().Get(id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Category not found")
+			return
+		}
+		h.logError("get category: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	err = h.Store.Categories().Delete(id)
+	if err != nil {
+		if err == store.ErrConflict {
+			h.renderError(w, http.StatusConflict, "CategoryNotEmpty", "Category has topics")
+			return
+		}
+		h.logError("delete category: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.audit(r, currentUser, store.AuditCategoryDelete, store.AuditTargetCategory, id, category, nil)
+
+	h.render(w, http.StatusOK, struct{}{})
+}
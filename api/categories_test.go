@@ -0,0 +1,538 @@
+package api
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/mock"
+)
+
+func TestHandleGetCategories(t *testing.T) {
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			CategoryStore: &mock.CategoryStore{
+				OnGetAll: func() ([]*store.Category, error) {
+					return []*store.Category{
+						{ID: 2, Slug: "announcements", Name: "Announcements", SortOrder: 1, AdminOnly: true},
+						{ID: 1, Slug: "help", Name: "Help", Description: "Ask for help", SortOrder: 2},
+					}, nil
+				},
+			},
+		},
+	})
+
+	req, err := http.NewRequest("GET", "/categories", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	apiHandler.ServeHTTP(w, req)
+
+	wantCode := http.StatusOK
+	wantBody := `{"categories":[{"id":2,"slug":"announcements","name":"Announcements","description":"","sortOrder":1,"adminOnly":true},{"id":1,"slug":"help","name":"Help","description":"Ask for help","sortOrder":2,"adminOnly":false}]}`
+
+	if wantCode != w.Code {
+		t.Fatalf("want status code %d got %d", wantCode, w.Code)
+	}
+
+	if wantBody != w.Body.String() {
+		t.Fatalf("want response body %q got %q", wantBody, w.Body.String())
+	}
+}
+
+func TestHandleGetCategory(t *testing.T) {
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			CategoryStore: &mock.CategoryStore{
+				OnGet: func(id int64) (*store.Category, error) {
+					if id == 1 {
+						return &store.Category{ID: 1, Slug: "help", Name: "Help"}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+				OnGetBySlug: func(slug string) (*store.Category, error) {
+					if slug == "help" {
+						return &store.Category{ID: 1, Slug: "help", Name: "Help"}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+			},
+		},
+	})
+
+	tests := []struct {
+		desc     string
+		id       string
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "id",
+			id:       "1",
+			wantCode: http.StatusOK,
+			wantBody: `{"category":{"id":1,"slug":"help","name":"Help","description":"","sortOrder":0,"adminOnly":false}}`,
+		},
+		{
+			desc:     "slug",
+			id:       "help",
+			wantCode: http.StatusOK,
+			wantBody: `{"category":{"id":1,"slug":"help","name":"Help","description":"","sortOrder":0,"adminOnly":false}}`,
+		},
+		{
+			desc:     "not found",
+			id:       "2",
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Category not found"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest("GET", "/categories/"+tc.id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+	}
+}
+
+func getCategoryTestUserStore(testTime time.Time) *mock.UserStore {
+	return &mock.UserStore{
+		OnGet: func(id int64) (*store.User, error) {
+			switch id {
+			case 1:
+				return &store.User{
+					ID:        1,
+					Name:      "TestUser1",
+					CreatedAt: testTime,
+				}, nil
+			case 2:
+				return &store.User{
+					ID:        2,
+					Name:      "TestUser2",
+					CreatedAt: testTime,
+				}, nil
+			}
+			return nil, store.ErrNotFound
+		},
+	}
+}
+
+func TestHandleNewCategory(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := jwtService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var created *store.Category
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			AuditStore: &mock.AuditStore{
+				OnNew: func(entry *store.AuditEntry) (int64, error) {
+					return 1, nil
+				},
+			},
+			UserStore: getCategoryTestUserStore(testTime),
+			CategoryStore: &mock.CategoryStore{
+				OnNew: func(slug, name, description string, sortOrder int, adminOnly bool) (int64, error) {
+					if slug == "taken" {
+						return 0, store.ErrConflict
+					}
+					created = &store.Category{
+						Slug:        slug,
+						Name:        name,
+						Description: description,
+						SortOrder:   sortOrder,
+						AdminOnly:   adminOnly,
+					}
+					return 3, nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc        string
+		token       string
+		body        string
+		wantCode    int
+		wantBody    string
+		wantCreated *store.Category
+	}{
+		{
+			desc:     "no token",
+			body:     `{"slug":"help","name":"Help"}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "not admin",
+			token:    token1,
+			body:     `{"slug":"help","name":"Help"}`,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
+		},
+		{
+			desc:        "admin",
+			token:       token2,
+			body:        `{"slug":"news","name":"News","description":"Project news","sortOrder":-1,"adminOnly":true}`,
+			wantCode:    http.StatusCreated,
+			wantBody:    `{"id":3}`,
+			wantCreated: &store.Category{Slug: "news", Name: "News", Description: "Project news", SortOrder: -1, AdminOnly: true},
+		},
+		{
+			desc:        "defaults",
+			token:       token2,
+			body:        `{"slug":"off-topic","name":"Off-topic"}`,
+			wantCode:    http.StatusCreated,
+			wantBody:    `{"id":3}`,
+			wantCreated: &store.Category{Slug: "off-topic", Name: "Off-topic"},
+		},
+		{
+			desc:     "bad slug",
+			token:    token2,
+			body:     `{"slug":"Bad Slug","name":"Help"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid category slug"}}`,
+		},
+		{
+			desc:     "numeric slug",
+			token:    token2,
+			body:     `{"slug":"123","name":"Help"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid category slug"}}`,
+		},
+		{
+			desc:     "empty name",
+			token:    token2,
+			body:     `{"slug":"help","name":""}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid category name"}}`,
+		},
+		{
+			desc:     "large description",
+			token:    token2,
+			body:     `{"slug":"help","name":"Help","description":"` + strings.Repeat("X", 501) + `"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid category description"}}`,
+		},
+		{
+			desc:     "taken slug",
+			token:    token2,
+			body:     `{"slug":"taken","name":"Help"}`,
+			wantCode: http.StatusConflict,
+			wantBody: `{"error":{"code":"UnavailableCategorySlug","message":"Category slug is already taken"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		created = nil
+
+		req, err := http.NewRequest("POST", "/categories", ioutil.NopCloser(strings.NewReader(tc.body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+
+		if tc.wantCreated != nil && (created == nil || *created != *tc.wantCreated) {
+			t.Fatalf("test %q: want created category %+v got %+v", tc.desc, tc.wantCreated, created)
+		}
+	}
+}
+
+func TestHandleEditCategory(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := jwtService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			AuditStore: &mock.AuditStore{
+				OnNew: func(entry *store.AuditEntry) (int64, error) {
+					return 1, nil
+				},
+			},
+			UserStore: getCategoryTestUserStore(testTime),
+			CategoryStore: &mock.CategoryStore{
+				OnGet: func(id int64) (*store.Category, error) {
+					if id == 1 {
+						return &store.Category{ID: 1, Slug: "help", Name: "Help", Description: "Ask for help", SortOrder: 2}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+				OnUpdate: func(category *store.Category) error {
+					if category.Slug == "taken" {
+						return store.ErrConflict
+					}
+					return nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc     string
+		id       string
+		token    string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "no token",
+			id:       "1",
+			body:     `{"name":"Support"}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "not admin",
+			id:       "1",
+			token:    token1,
+			body:     `{"name":"Support"}`,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
+		},
+		{
+			desc:     "partial update",
+			id:       "1",
+			token:    token2,
+			body:     `{"name":"Support","adminOnly":true}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"category":{"id":1,"slug":"help","name":"Support","description":"Ask for help","sortOrder":2,"adminOnly":true}}`,
+		},
+		{
+			desc:     "not found",
+			id:       "2",
+			token:    token2,
+			body:     `{"name":"Support"}`,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Category not found"}}`,
+		},
+		{
+			desc:     "bad slug",
+			id:       "1",
+			token:    token2,
+			body:     `{"slug":""}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid category slug"}}`,
+		},
+		{
+			desc:     "taken slug",
+			id:       "1",
+			token:    token2,
+			body:     `{"slug":"taken"}`,
+			wantCode: http.StatusConflict,
+			wantBody: `{"error":{"code":"UnavailableCategorySlug","message":"Category slug is already taken"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest("PATCH", "/categories/"+tc.id, ioutil.NopCloser(strings.NewReader(tc.body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+	}
+}
+
+func TestHandleDeleteCategory(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := jwtService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var deletedID int64
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			AuditStore: &mock.AuditStore{
+				OnNew: func(entry *store.AuditEntry) (int64, error) {
+					return 1, nil
+				},
+			},
+			UserStore: getCategoryTestUserStore(testTime),
+			CategoryStore: &mock.CategoryStore{
+				OnGet: func(id int64) (*store.Category, error) {
+					if id == 1 || id == 2 {
+						return &store.Category{ID: id}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+				OnDelete: func(id int64) error {
+					if id == 2 {
+						return store.ErrConflict
+					}
+					deletedID = id
+					return nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc          string
+		id            string
+		token         string
+		wantCode      int
+		wantBody      string
+		wantDeletedID int64
+	}{
+		{
+			desc:     "no token",
+			id:       "1",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "not admin",
+			id:       "1",
+			token:    token1,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
+		},
+		{
+			desc:          "admin",
+			id:            "1",
+			token:         token2,
+			wantCode:      http.StatusOK,
+			wantBody:      `{}`,
+			wantDeletedID: 1,
+		},
+		{
+			desc:     "not empty",
+			id:       "2",
+			token:    token2,
+			wantCode: http.StatusConflict,
+			wantBody: `{"error":{"code":"CategoryNotEmpty","message":"Category has topics"}}`,
+		},
+		{
+			desc:     "not found",
+			id:       "3",
+			token:    token2,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Category not found"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		deletedID = 0
+
+		req, err := http.NewRequest("DELETE", "/categories/"+tc.id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+
+		if tc.wantDeletedID != deletedID {
+			t.Fatalf("test %q: want deleted id %d got %d", tc.desc, tc.wantDeletedID, deletedID)
+		}
+	}
+}
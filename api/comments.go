@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/disintegration/bebop/events"
 	"github.com/disintegration/bebop/store"
 )
 
@@ -25,11 +26,14 @@ func (h *Handler) handleGetComments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Cursor mode is enabled by the "cursor" parameter. An empty cursor means the first page.
+	_, cursorMode := r.URL.Query()["cursor"]
+
 	offset := 0
 	offsetParam := r.URL.Query().Get("offset")
 	if offsetParam != "" {
 		offset, err = strconv.Atoi(offsetParam)
-		if err != nil || offset < 0 {
+		if err != nil || offset < 0 || cursorMode {
 			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid offset")
 			return
 		}
@@ -39,12 +43,57 @@ func (h *Handler) handleGetComments(w http.ResponseWriter, r *http.Request) {
 	limitParam := r.URL.Query().Get("limit")
 	if limitParam != "" {
 		limit, err = strconv.Atoi(limitParam)
-		if err != nil || limit < 1 || limit > 1000 {
+		if err != nil || limit < 1 || limit > 1000 || (cursorMode && limit > maxCursorLimit) {
 			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid limit")
 			return
 		}
 	}
 
+	if cursorMode {
+		var cursor *store.CommentCursor
+		if cursorParam := r.URL.Query().Get("cursor"); cursorParam != "" {
+			cursor, err = store.ParseCommentCursor(cursorParam)
+			if err != nil {
+				h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid cursor")
+				return
+			}
+		}
+
+		// Fetch one extra comment to find out if there is a next page.
+		comments, err := h.Store.Comments().GetByTopicAfter(topic, cursor, limit+1)
+		if err != nil {
+			h.logError("get comments by topic after cursor: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+
+		nextCursor := ""
+		if len(comments) > limit {
+			comments = comments[:limit]
+			nextCursor = store.NewCommentCursor(comments[limit-1]).String()
+		}
+
+		reactions, err := h.getCommentReactions(r, comments)
+		if err != nil {
+			h.logError("get reaction counts: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+
+		response := struct {
+			Comments   []*store.Comment                 `json:"comments"`
+			Reactions  map[int64][]*store.ReactionCount `json:"reactions"`
+			NextCursor string                           `json:"nextCursor"`
+		}{
+			Comments:   comments,
+			Reactions:  reactions,
+			NextCursor: nextCursor,
+		}
+
+		h.render(w, http.StatusOK, response)
+		return
+	}
+
 	comments, count, err := h.Store.Comments().GetByTopic(topic, offset, limit)
 	if err != nil {
 		h.logError("get comments by topic: %s", err)
@@ -52,17 +101,42 @@ func (h *Handler) handleGetComments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	reactions, err := h.getCommentReactions(r, comments)
+	if err != nil {
+		h.logError("get reaction counts: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
 	response := struct {
-		Comments []*store.Comment `json:"comments"`
-		Count    int              `json:"count"`
+		Comments  []*store.Comment                 `json:"comments"`
+		Reactions map[int64][]*store.ReactionCount `json:"reactions"`
+		Count     int                              `json:"count"`
 	}{
-		Comments: comments,
-		Count:    count,
+		Comments:  comments,
+		Reactions: reactions,
+		Count:     count,
 	}
 
 	h.render(w, http.StatusOK, response)
 }
 
+// getCommentReactions returns the reaction counts of the comments keyed by comment ID.
+// The Reacted flags are set if the request is authenticated.
+func (h *Handler) getCommentReactions(r *http.Request, comments []*store.Comment) (map[int64][]*store.ReactionCount, error) {
+	var userID int64
+	if currentUser := h.currentUser(r); currentUser != nil {
+		userID = currentUser.ID
+	}
+
+	ids := make([]int64, len(comments))
+	for i, c := range comments {
+		ids[i] = c.ID
+	}
+
+	return h.getReactionCounts(ids, userID)
+}
+
 func (h *Handler) handleNewComment(w http.ResponseWriter, r *http.Request) {
 	currentUser := h.currentUser(r)
 	if currentUser == nil {
@@ -97,7 +171,7 @@ func (h *Handler) handleNewComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = h.Store.Topics().Get(*req.Topic)
+	topic, err := h.Store.Topics().Get(*req.Topic)
 	if err != nil {
 		if err == store.ErrNotFound {
 			h.renderError(w, http.StatusNotFound, "NotFound", "Topic not found")
@@ -108,7 +182,12 @@ func (h *Handler) handleNewComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := h.Store.Comments().New(*req.Topic, currentUser.ID, *req.Content)
+	if topic.Locked && !h.can(currentUser, store.PermCommentLockedTopic) {
+		h.renderError(w, http.StatusForbidden, "TopicLocked", "Topic is locked")
+		return
+	}
+
+	id, err := h.Store.Comments().New(*req.Topic, currentUser.ID, *req.Content, h.mentionedUserIDs(*req.Content))
 	if err != nil {
 		h.logError("create comment: %s", err)
 		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
@@ -130,6 +209,10 @@ func (h *Handler) handleNewComment(w http.ResponseWriter, r *http.Request) {
 		Count: count,
 	}
 
+	h.watch(currentUser.ID, *req.Topic)
+	h.publish(events.CommentCreated, *req.Topic, id)
+	h.publishCreatedWebhook(store.WebhookCommentCreated, currentUser, *req.Topic, id)
+
 	h.render(w, http.StatusCreated, response)
 }
 
@@ -160,7 +243,7 @@ func (h *Handler) handleGetComment(w http.ResponseWriter, r *http.Request) {
 	h.render(w, http.StatusOK, response)
 }
 
-func (h *Handler) handleDeleteComment(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) handleEditComment(w http.ResponseWriter, r *http.Request) {
 	currentUser := h.currentUser(r)
 	if currentUser == nil {
 		w.Header().Set("WWW-Authenticate", "Bearer")
@@ -168,11 +251,87 @@ func (h *Handler) handleDeleteComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !currentUser.Admin {
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid comment ID")
+		return
+	}
+
+	comment, err := h.Store.Comments().Get(id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Comment not found")
+			return
+		}
+		h.logError("get comment: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	if currentUser.ID != comment.AuthorID && !h.can(currentUser, store.PermCommentEdit) {
 		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
 		return
 	}
 
+	topic, err := h.Store.Topics().Get(comment.TopicID)
+	if err != nil {
+		h.logError("get topic: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	if topic.Locked && !h.can(currentUser, store.PermCommentLockedTopic) {
+		h.renderError(w, http.StatusForbidden, "TopicLocked", "Topic is locked")
+		return
+	}
+
+	req := struct {
+		Content *string `json:"content"`
+	}{}
+
+	err = h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.Content == nil || !store.ValidCommentContent(*req.Content) {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid comment content")
+		return
+	}
+
+	if comment.Content != *req.Content {
+		err = h.Store.Comments().SetContent(id, currentUser.ID, *req.Content)
+		if err != nil {
+			h.logError("set comment content: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+
+		before := comment
+		comment, err = h.Store.Comments().Get(id)
+		if err != nil {
+			h.logError("get comment: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+
+		// The edits of other users' comments are moderation actions.
+		if currentUser.ID != comment.AuthorID {
+			h.audit(r, currentUser, store.AuditCommentEdit, store.AuditTargetComment, id, before, comment)
+		}
+	}
+
+	response := struct {
+		Comment *store.Comment `json:"comment"`
+	}{
+		Comment: comment,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+func (h *Handler) handleGetCommentRevisions(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
 	if err != nil {
 		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid comment ID")
@@ -190,6 +349,52 @@ func (h *Handler) handleDeleteComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	revisions, err := h.Store.Comments().GetRevisions(id)
+	if err != nil {
+		h.logError("get comment revisions: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	response := struct {
+		Revisions []*store.CommentRevision `json:"revisions"`
+	}{
+		Revisions: revisions,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+func (h *Handler) handleDeleteComment(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermCommentDelete) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid comment ID")
+		return
+	}
+
+	comment, err := h.Store.Comments().Get(id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Comment not found")
+			return
+		}
+		h.logError("get comment: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
 	err = h.Store.Comments().Delete(id)
 	if err != nil {
 		h.logError("delete comment: %s", err)
@@ -197,5 +402,10 @@ func (h *Handler) handleDeleteComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.audit(r, currentUser, store.AuditCommentDelete, store.AuditTargetComment, id, comment, nil)
+
+	h.publish(events.CommentDeleted, comment.TopicID, id)
+	h.publishWebhook(store.WebhookCommentDeleted, currentUser, nil, comment, nil)
+
 	h.render(w, http.StatusOK, struct{}{})
 }
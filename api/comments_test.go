@@ -1,10 +1,12 @@
 package api
 
 import (
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -20,9 +22,12 @@ func TestHandleGetComments(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	comment1Cursor := &store.CommentCursor{CreatedAt: testTime, ID: 1}
+
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
 			TopicStore: &mock.TopicStore{
 				OnGet: func(id int64) (*store.Topic, error) {
 					switch id {
@@ -52,6 +57,7 @@ func TestHandleGetComments(t *testing.T) {
 								AuthorID:  1,
 								Content:   "Comment1",
 								CreatedAt: testTime,
+								UpdatedAt: testTime,
 							},
 							{
 								ID:        2,
@@ -59,6 +65,7 @@ func TestHandleGetComments(t *testing.T) {
 								AuthorID:  2,
 								Content:   "Comment2",
 								CreatedAt: testTime,
+								UpdatedAt: testTime,
 							},
 						}, 2, nil
 					}
@@ -68,23 +75,59 @@ func TestHandleGetComments(t *testing.T) {
 					t.Fatalf("OnGetByTopic: unexpected params (unknown test)")
 					return nil, 0, nil
 				},
+				OnGetByTopicAfter: func(topicID int64, cursor *store.CommentCursor, limit int) ([]*store.Comment, error) {
+					if topicID == 1 && cursor == nil && limit == 2 {
+						return []*store.Comment{
+							{ID: 1, TopicID: 1, AuthorID: 1, Content: "Comment1", CreatedAt: testTime, UpdatedAt: testTime},
+							{ID: 2, TopicID: 1, AuthorID: 2, Content: "Comment2", CreatedAt: testTime, UpdatedAt: testTime},
+						}, nil
+					}
+					if topicID == 1 && reflect.DeepEqual(cursor, comment1Cursor) && limit == 2 {
+						return []*store.Comment{
+							{ID: 2, TopicID: 1, AuthorID: 2, Content: "Comment2", CreatedAt: testTime, UpdatedAt: testTime},
+						}, nil
+					}
+					t.Fatalf("OnGetByTopicAfter: unexpected params (unknown test)")
+					return nil, nil
+				},
+			},
+			ReactionStore: &mock.ReactionStore{
+				OnGetCounts: func(commentIDs []int64, userID int64) (map[int64][]*store.ReactionCount, error) {
+					if userID != 0 {
+						t.Fatalf("OnGetCounts: unexpected params (unknown test)")
+					}
+					counts := make(map[int64][]*store.ReactionCount)
+					for _, id := range commentIDs {
+						if id == 1 {
+							counts[1] = []*store.ReactionCount{
+								{Emoji: "+1", Count: 2},
+								{Emoji: "heart", Count: 1},
+								{Emoji: "removed", Count: 1},
+							}
+						}
+					}
+					return counts, nil
+				},
 			},
 		},
+		Reactions: []string{"heart", "+1"},
 	})
 
 	tests := []struct {
-		desc     string
-		topicID  string
-		offset   string
-		limit    string
-		wantCode int
-		wantBody string
+		desc       string
+		topicID    string
+		offset     string
+		limit      string
+		cursorMode bool
+		cursor     string
+		wantCode   int
+		wantBody   string
 	}{
 		{
 			desc:     "no offset",
 			topicID:  "1",
 			wantCode: http.StatusOK,
-			wantBody: `{"comments":[{"id":1,"topicId":1,"authorId":1,"content":"Comment1","createdAt":"2001-02-03T04:05:06Z"},{"id":2,"topicId":1,"authorId":2,"content":"Comment2","createdAt":"2001-02-03T04:05:06Z"}],"count":2}`,
+			wantBody: `{"comments":[{"id":1,"topicId":1,"authorId":1,"content":"Comment1","createdAt":"2001-02-03T04:05:06Z","updatedAt":"2001-02-03T04:05:06Z","editCount":0},{"id":2,"topicId":1,"authorId":2,"content":"Comment2","createdAt":"2001-02-03T04:05:06Z","updatedAt":"2001-02-03T04:05:06Z","editCount":0}],"reactions":{"1":[{"emoji":"heart","count":1,"reacted":false},{"emoji":"+1","count":2,"reacted":false}]},"count":2}`,
 		},
 		{
 			desc:     "offset 0",
@@ -92,7 +135,7 @@ func TestHandleGetComments(t *testing.T) {
 			offset:   "0",
 			limit:    "100",
 			wantCode: http.StatusOK,
-			wantBody: `{"comments":[{"id":1,"topicId":1,"authorId":1,"content":"Comment1","createdAt":"2001-02-03T04:05:06Z"},{"id":2,"topicId":1,"authorId":2,"content":"Comment2","createdAt":"2001-02-03T04:05:06Z"}],"count":2}`,
+			wantBody: `{"comments":[{"id":1,"topicId":1,"authorId":1,"content":"Comment1","createdAt":"2001-02-03T04:05:06Z","updatedAt":"2001-02-03T04:05:06Z","editCount":0},{"id":2,"topicId":1,"authorId":2,"content":"Comment2","createdAt":"2001-02-03T04:05:06Z","updatedAt":"2001-02-03T04:05:06Z","editCount":0}],"reactions":{"1":[{"emoji":"heart","count":1,"reacted":false},{"emoji":"+1","count":2,"reacted":false}]},"count":2}`,
 		},
 		{
 			desc:     "offset 100",
@@ -100,7 +143,7 @@ func TestHandleGetComments(t *testing.T) {
 			offset:   "100",
 			limit:    "100",
 			wantCode: http.StatusOK,
-			wantBody: `{"comments":[],"count":2}`,
+			wantBody: `{"comments":[],"reactions":{},"count":2}`,
 		},
 		{
 			desc:     "unknown topic",
@@ -128,6 +171,39 @@ func TestHandleGetComments(t *testing.T) {
 			wantCode: http.StatusBadRequest,
 			wantBody: `{"error":{"code":"BadRequest","message":"Invalid limit"}}`,
 		},
+		{
+			desc:       "cursor first page",
+			topicID:    "1",
+			limit:      "1",
+			cursorMode: true,
+			wantCode:   http.StatusOK,
+			wantBody:   `{"comments":[{"id":1,"topicId":1,"authorId":1,"content":"Comment1","createdAt":"2001-02-03T04:05:06Z","updatedAt":"2001-02-03T04:05:06Z","editCount":0}],"reactions":{"1":[{"emoji":"heart","count":1,"reacted":false},{"emoji":"+1","count":2,"reacted":false}]},"nextCursor":"` + comment1Cursor.String() + `"}`,
+		},
+		{
+			desc:       "cursor last page",
+			topicID:    "1",
+			limit:      "1",
+			cursorMode: true,
+			cursor:     comment1Cursor.String(),
+			wantCode:   http.StatusOK,
+			wantBody:   `{"comments":[{"id":2,"topicId":1,"authorId":2,"content":"Comment2","createdAt":"2001-02-03T04:05:06Z","updatedAt":"2001-02-03T04:05:06Z","editCount":0}],"reactions":{},"nextCursor":""}`,
+		},
+		{
+			desc:       "bad cursor",
+			topicID:    "1",
+			cursorMode: true,
+			cursor:     "BAD_CURSOR",
+			wantCode:   http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"BadRequest","message":"Invalid cursor"}}`,
+		},
+		{
+			desc:       "cursor with offset",
+			topicID:    "1",
+			offset:     "0",
+			cursorMode: true,
+			wantCode:   http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"BadRequest","message":"Invalid offset"}}`,
+		},
 	}
 
 	for _, tc := range tests {
@@ -138,6 +214,9 @@ func TestHandleGetComments(t *testing.T) {
 		if tc.limit != "" {
 			url += "&limit=" + tc.limit
 		}
+		if tc.cursorMode {
+			url += "&cursor=" + tc.cursor
+		}
 		req, err := http.NewRequest("GET", url, nil)
 		if err != nil {
 			t.Fatal(err)
@@ -161,7 +240,7 @@ func TestHandleNewComment(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	jwtService, err := jwt.NewService(strings.Repeat("0", 64))
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -185,6 +264,7 @@ func TestHandleNewComment(t *testing.T) {
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
 			UserStore: &mock.UserStore{
 				OnGet: func(id int64) (*store.User, error) {
 					switch id {
@@ -196,7 +276,6 @@ func TestHandleNewComment(t *testing.T) {
 							AuthService: "TestAuthService1",
 							AuthID:      "TestAuthID1",
 							Blocked:     false,
-							Admin:       false,
 							Avatar:      "Avatar1",
 						}, nil
 					case 2:
@@ -207,7 +286,6 @@ func TestHandleNewComment(t *testing.T) {
 							AuthService: "TestAuthService2",
 							AuthID:      "TestAuthID2",
 							Blocked:     true,
-							Admin:       false,
 							Avatar:      "Avatar2",
 						}, nil
 					case 3:
@@ -218,12 +296,17 @@ func TestHandleNewComment(t *testing.T) {
 							AuthService: "TestAuthService3",
 							AuthID:      "TestAuthID3",
 							Blocked:     false,
-							Admin:       false,
 							Avatar:      "Avatar3",
 						}, nil
 					}
 					return nil, store.ErrNotFound
 				},
+				OnGetByName: func(name string) (*store.User, error) {
+					if name == "TestUser2" {
+						return &store.User{ID: 2, Name: "TestUser2"}, nil
+					}
+					return nil, store.ErrNotFound
+				},
 			},
 			TopicStore: &mock.TopicStore{
 				OnGet: func(id int64) (*store.Topic, error) {
@@ -237,21 +320,52 @@ func TestHandleNewComment(t *testing.T) {
 							LastCommentAt: testTime,
 							CommentCount:  2,
 						}, nil
+					case 2:
+						return &store.Topic{
+							ID:            2,
+							AuthorID:      1,
+							Title:         "Topic2",
+							CreatedAt:     testTime,
+							LastCommentAt: testTime,
+							CommentCount:  1,
+							Locked:        true,
+						}, nil
 					}
 					return nil, store.ErrNotFound
 				},
 			},
 			CommentStore: &mock.CommentStore{
-				OnNew: func(topicID int64, authorID int64, content string) (int64, error) {
-					if topicID != 1 || authorID != 1 || content != "Comment1" {
+				OnNew: func(topicID int64, authorID int64, content string, mentionIDs []int64) (int64, error) {
+					if topicID != 1 || authorID != 1 {
 						t.Fatalf("OnNew: unexpected params: %d, %d, %q", topicID, authorID, content)
 					}
-					return 11, nil
+					switch content {
+					case "Comment1":
+						if mentionIDs != nil {
+							t.Fatalf("OnNew: unexpected mentions: %v", mentionIDs)
+						}
+						return 11, nil
+					case "Hi @TestUser2 and @Nobody, @TestUser2":
+						if !reflect.DeepEqual(mentionIDs, []int64{2}) {
+							t.Fatalf("OnNew: unexpected mentions: %v", mentionIDs)
+						}
+						return 12, nil
+					}
+					t.Fatalf("OnNew: unexpected content: %q", content)
+					return 0, nil
 				},
 				OnGetByTopic: func(topicID int64, offset, limit int) ([]*store.Comment, int, error) {
 					return []*store.Comment{}, 10, nil
 				},
 			},
+			WatchStore: &mock.WatchStore{
+				OnWatch: func(userID, topicID int64) error {
+					if userID != 1 || topicID != 1 {
+						t.Fatalf("WatchStore.OnWatch: unexpected params: %d, %d", userID, topicID)
+					}
+					return nil
+				},
+			},
 		},
 		JWTService: jwtService,
 	})
@@ -291,6 +405,13 @@ func TestHandleNewComment(t *testing.T) {
 			wantCode: http.StatusUnauthorized,
 			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
 		},
+		{
+			desc:     "locked topic",
+			body:     `{"topic":2,"content":"Comment1"}`,
+			token:    token1,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"TopicLocked","message":"Topic is locked"}}`,
+		},
 		{
 			desc:     "good token",
 			body:     `{"topic":1,"content":"Comment1"}`,
@@ -298,6 +419,13 @@ func TestHandleNewComment(t *testing.T) {
 			wantCode: http.StatusCreated,
 			wantBody: `{"id":11,"count":10}`,
 		},
+		{
+			desc:     "mentions",
+			body:     `{"topic":1,"content":"Hi @TestUser2 and @Nobody, @TestUser2"}`,
+			token:    token1,
+			wantCode: http.StatusCreated,
+			wantBody: `{"id":12,"count":10}`,
+		},
 		{
 			desc:     "bad request body",
 			body:     `{bad request body}`,
@@ -359,6 +487,7 @@ func TestHandleGetComment(t *testing.T) {
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
 			CommentStore: &mock.CommentStore{
 				OnGet: func(id int64) (*store.Comment, error) {
 					switch id {
@@ -369,6 +498,7 @@ func TestHandleGetComment(t *testing.T) {
 							AuthorID:  1,
 							Content:   "Comment1",
 							CreatedAt: testTime,
+							UpdatedAt: testTime,
 						}, nil
 					}
 					return nil, store.ErrNotFound
@@ -387,7 +517,7 @@ func TestHandleGetComment(t *testing.T) {
 			desc:     "found",
 			id:       "1",
 			wantCode: http.StatusOK,
-			wantBody: `{"comment":{"id":1,"topicId":1,"authorId":1,"content":"Comment1","createdAt":"2001-02-03T04:05:06Z"}}`,
+			wantBody: `{"comment":{"id":1,"topicId":1,"authorId":1,"content":"Comment1","createdAt":"2001-02-03T04:05:06Z","updatedAt":"2001-02-03T04:05:06Z","editCount":0}}`,
 		},
 		{
 			desc:     "not found",
@@ -427,7 +557,7 @@ func TestDeleteComment(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	jwtService, err := jwt.NewService(strings.Repeat("0", 64))
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -439,10 +569,20 @@ func TestDeleteComment(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	token3, err := jwtService.Create(3)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			AuditStore: &mock.AuditStore{
+				OnNew: func(entry *store.AuditEntry) (int64, error) {
+					return 1, nil
+				},
+			},
 			UserStore: &mock.UserStore{
 				OnGet: func(id int64) (*store.User, error) {
 					switch id {
@@ -454,7 +594,6 @@ func TestDeleteComment(t *testing.T) {
 							AuthService: "TestAuthService1",
 							AuthID:      "TestAuthID1",
 							Blocked:     false,
-							Admin:       false,
 							Avatar:      "Avatar1",
 						}, nil
 					case 2:
@@ -465,9 +604,18 @@ func TestDeleteComment(t *testing.T) {
 							AuthService: "TestAuthService2",
 							AuthID:      "TestAuthID2",
 							Blocked:     false,
-							Admin:       true,
 							Avatar:      "Avatar2",
 						}, nil
+					case 3:
+						return &store.User{
+							ID:          3,
+							Name:        "TestUser3",
+							CreatedAt:   testTime,
+							AuthService: "TestAuthService3",
+							AuthID:      "TestAuthID3",
+							Blocked:     false,
+							Avatar:      "Avatar3",
+						}, nil
 					}
 					return nil, store.ErrNotFound
 				},
@@ -482,6 +630,7 @@ func TestDeleteComment(t *testing.T) {
 							AuthorID:  1,
 							Content:   "Comment1",
 							CreatedAt: testTime,
+							UpdatedAt: testTime,
 						}, nil
 					}
 					return nil, store.ErrNotFound
@@ -518,6 +667,13 @@ func TestDeleteComment(t *testing.T) {
 			wantCode: http.StatusForbidden,
 			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
 		},
+		{
+			desc:     "moderator token",
+			token:    token3,
+			id:       "1",
+			wantCode: http.StatusOK,
+			wantBody: `{}`,
+		},
 		{
 			desc:     "admin token, bad id",
 			token:    token2,
@@ -562,3 +718,334 @@ func TestDeleteComment(t *testing.T) {
 		}
 	}
 }
+
+func TestEditComment(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	editTime, err := time.Parse(time.RFC3339, "2001-02-04T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := jwtService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token3, err := jwtService.Create(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		editedID       int64
+		editedEditorID int64
+		editedContent  string
+		auditAction    string
+	)
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			AuditStore: &mock.AuditStore{
+				OnNew: func(entry *store.AuditEntry) (int64, error) {
+					auditAction = fmt.Sprintf("%s %s %d", entry.Action, entry.TargetType, entry.TargetID)
+					return 1, nil
+				},
+			},
+			TopicStore: &mock.TopicStore{
+				OnGet: func(id int64) (*store.Topic, error) {
+					return &store.Topic{ID: id, Locked: id == 2}, nil
+				},
+			},
+			UserStore: &mock.UserStore{
+				OnGet: func(id int64) (*store.User, error) {
+					switch id {
+					case 1:
+						return &store.User{ID: 1, Name: "TestUser1", CreatedAt: testTime}, nil
+					case 2:
+						return &store.User{ID: 2, Name: "TestUser2", CreatedAt: testTime}, nil
+					case 3:
+						return &store.User{ID: 3, Name: "TestUser3", CreatedAt: testTime}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+			},
+			CommentStore: &mock.CommentStore{
+				OnGet: func(id int64) (*store.Comment, error) {
+					switch id {
+					case 1:
+						c := &store.Comment{
+							ID:        1,
+							TopicID:   1,
+							AuthorID:  1,
+							Content:   "Comment1",
+							CreatedAt: testTime,
+							UpdatedAt: testTime,
+						}
+						if editedID == 1 {
+							c.Content = editedContent
+							c.UpdatedAt = editTime
+							c.EditCount = 1
+						}
+						return c, nil
+					case 2:
+						c := &store.Comment{
+							ID:        2,
+							TopicID:   2,
+							AuthorID:  1,
+							Content:   "Comment2",
+							CreatedAt: testTime,
+							UpdatedAt: testTime,
+						}
+						if editedID == 2 {
+							c.Content = editedContent
+							c.UpdatedAt = editTime
+							c.EditCount = 1
+						}
+						return c, nil
+					}
+					return nil, store.ErrNotFound
+				},
+				OnSetContent: func(id int64, editorID int64, content string) error {
+					editedID, editedEditorID, editedContent = id, editorID, content
+					return nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc         string
+		token        string
+		id           string
+		body         string
+		wantCode     int
+		wantBody     string
+		wantEditorID int64
+		wantAudit    string
+	}{
+		{
+			desc:     "no token",
+			token:    "",
+			id:       "1",
+			body:     `{"content":"Edited"}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "bad id",
+			token:    token1,
+			id:       "BAD_ID",
+			body:     `{"content":"Edited"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid comment ID"}}`,
+		},
+		{
+			desc:     "unknown id",
+			token:    token1,
+			id:       "100",
+			body:     `{"content":"Edited"}`,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Comment not found"}}`,
+		},
+		{
+			desc:     "not an author",
+			token:    token3,
+			id:       "1",
+			body:     `{"content":"Edited"}`,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
+		},
+		{
+			desc:     "bad request body",
+			token:    token1,
+			id:       "1",
+			body:     `{bad request body}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid request body"}}`,
+		},
+		{
+			desc:     "empty content",
+			token:    token1,
+			id:       "1",
+			body:     `{"content":""}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid comment content"}}`,
+		},
+		{
+			desc:     "same content",
+			token:    token1,
+			id:       "1",
+			body:     `{"content":"Comment1"}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"comment":{"id":1,"topicId":1,"authorId":1,"content":"Comment1","createdAt":"2001-02-03T04:05:06Z","updatedAt":"2001-02-03T04:05:06Z","editCount":0}}`,
+		},
+		{
+			desc:         "author",
+			token:        token1,
+			id:           "1",
+			body:         `{"content":"Edited by author"}`,
+			wantCode:     http.StatusOK,
+			wantBody:     `{"comment":{"id":1,"topicId":1,"authorId":1,"content":"Edited by author","createdAt":"2001-02-03T04:05:06Z","updatedAt":"2001-02-04T04:05:06Z","editCount":1}}`,
+			wantEditorID: 1,
+		},
+		{
+			desc:         "admin",
+			token:        token2,
+			id:           "1",
+			body:         `{"content":"Edited by admin"}`,
+			wantCode:     http.StatusOK,
+			wantBody:     `{"comment":{"id":1,"topicId":1,"authorId":1,"content":"Edited by admin","createdAt":"2001-02-03T04:05:06Z","updatedAt":"2001-02-04T04:05:06Z","editCount":1}}`,
+			wantEditorID: 2,
+			wantAudit:    "comment.edit comment 1",
+		},
+		{
+			desc:     "locked topic",
+			token:    token1,
+			id:       "2",
+			body:     `{"content":"Edited by author"}`,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"TopicLocked","message":"Topic is locked"}}`,
+		},
+		{
+			desc:         "locked topic admin",
+			token:        token2,
+			id:           "2",
+			body:         `{"content":"Edited by admin"}`,
+			wantCode:     http.StatusOK,
+			wantBody:     `{"comment":{"id":2,"topicId":2,"authorId":1,"content":"Edited by admin","createdAt":"2001-02-03T04:05:06Z","updatedAt":"2001-02-04T04:05:06Z","editCount":1}}`,
+			wantEditorID: 2,
+			wantAudit:    "comment.edit comment 2",
+		},
+	}
+
+	for _, tc := range tests {
+		editedID, editedEditorID, editedContent, auditAction = 0, 0, "", ""
+
+		req, err := http.NewRequest("PATCH", "/comments/"+tc.id, ioutil.NopCloser(strings.NewReader(tc.body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+
+		if tc.wantEditorID != editedEditorID {
+			t.Fatalf("test %q: want editor id %d got %d", tc.desc, tc.wantEditorID, editedEditorID)
+		}
+
+		if tc.wantAudit != auditAction {
+			t.Fatalf("test %q: want audit %q got %q", tc.desc, tc.wantAudit, auditAction)
+		}
+	}
+}
+
+func TestHandleGetCommentRevisions(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			CommentStore: &mock.CommentStore{
+				OnGet: func(id int64) (*store.Comment, error) {
+					switch id {
+					case 1:
+						return &store.Comment{ID: 1, TopicID: 1, AuthorID: 1, Content: "Edited", CreatedAt: testTime, UpdatedAt: testTime, EditCount: 1}, nil
+					case 2:
+						return &store.Comment{ID: 2, TopicID: 1, AuthorID: 1, Content: "Comment2", CreatedAt: testTime, UpdatedAt: testTime}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+				OnGetRevisions: func(id int64) ([]*store.CommentRevision, error) {
+					switch id {
+					case 1:
+						return []*store.CommentRevision{
+							{ID: 1, CommentID: 1, EditorID: 1, Content: "Comment1", CreatedAt: testTime},
+							{ID: 2, CommentID: 1, EditorID: 2, Content: "Edited", CreatedAt: testTime},
+						}, nil
+					case 2:
+						return []*store.CommentRevision{}, nil
+					}
+					t.Fatalf("OnGetRevisions: unexpected params: %d", id)
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	tests := []struct {
+		desc     string
+		id       string
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "edited",
+			id:       "1",
+			wantCode: http.StatusOK,
+			wantBody: `{"revisions":[{"id":1,"commentId":1,"editorId":1,"content":"Comment1","createdAt":"2001-02-03T04:05:06Z"},{"id":2,"commentId":1,"editorId":2,"content":"Edited","createdAt":"2001-02-03T04:05:06Z"}]}`,
+		},
+		{
+			desc:     "not edited",
+			id:       "2",
+			wantCode: http.StatusOK,
+			wantBody: `{"revisions":[]}`,
+		},
+		{
+			desc:     "not found",
+			id:       "100",
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Comment not found"}}`,
+		},
+		{
+			desc:     "bad id",
+			id:       "BAD_ID",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid comment ID"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest("GET", "/comments/"+tc.id+"/revisions", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// eventsHeartbeatInterval is the interval of the comment lines sent to the idle
+// event streams to keep the connections open through proxies.
+const eventsHeartbeatInterval = 30 * time.Second
+
+// handleEvents streams the content events as Server-Sent Events.
+// The "topic" parameter limits the stream to the events of one topic.
+// A reconnecting client resumes the stream after the Last-Event-ID.
+func (h *Handler) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if h.Events == nil {
+		h.renderError(w, http.StatusNotFound, "NotFound", "Events are not available")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.logError("events: streaming is not supported by the response writer")
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	var topicID int64
+	if topicParam := r.URL.Query().Get("topic"); topicParam != "" {
+		var err error
+		topicID, err = strconv.ParseInt(topicParam, 10, 64)
+		if err != nil || topicID <= 0 {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid topic")
+			return
+		}
+	}
+
+	var lastEventID int64
+	if lastEventParam := r.Header.Get("Last-Event-ID"); lastEventParam != "" {
+		var err error
+		lastEventID, err = strconv.ParseInt(lastEventParam, 10, 64)
+		if err != nil || lastEventID < 0 {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid Last-Event-ID")
+			return
+		}
+	}
+
+	sub := h.Events.Subscribe(topicID, lastEventID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case e, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				h.logError("marshal event: %s", err)
+				return
+			}
+			_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+			if err != nil {
+				return
+			}
+
+		case <-heartbeat.C:
+			_, err := fmt.Fprint(w, ": heartbeat\n\n")
+			if err != nil {
+				return
+			}
+
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// publish sends a content event to the event stream subscribers.
+func (h *Handler) publish(typ string, topicID, commentID int64) {
+	if h.Events != nil {
+		h.Events.Publish(typ, topicID, commentID)
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/disintegration/bebop/events"
+	"github.com/disintegration/bebop/store/mock"
+)
+
+func TestHandleEvents(t *testing.T) {
+	hub := events.NewHub()
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store:  &mock.Store{},
+		Events: hub,
+	})
+
+	tests := []struct {
+		desc        string
+		url         string
+		lastEventID string
+		wantCode    int
+		wantBody    string
+	}{
+		{
+			desc:     "bad topic",
+			url:      "/events?topic=BAD",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid topic"}}`,
+		},
+		{
+			desc:        "bad last event id",
+			url:         "/events",
+			lastEventID: "BAD",
+			wantCode:    http.StatusBadRequest,
+			wantBody:    `{"error":{"code":"BadRequest","message":"Invalid Last-Event-ID"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest("GET", tc.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.lastEventID != "" {
+			req.Header.Set("Last-Event-ID", tc.lastEventID)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+	}
+
+	server := httptest.NewServer(apiHandler)
+	defer server.Close()
+
+	hub.Publish(events.TopicCreated, 1, 0)
+	hub.Publish(events.CommentCreated, 1, 5)
+
+	req, err := http.NewRequest("GET", server.URL+"/events?topic=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to get events: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("bad event stream response: %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// The response headers are sent after subscribing.
+	hub.Publish(events.CommentCreated, 2, 6)
+	hub.Publish(events.CommentDeleted, 1, 5)
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		var lines []string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				if err == io.EOF && len(lines) == 0 {
+					return ""
+				}
+				t.Fatalf("failed to read event: %s", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				return strings.Join(lines, "\n")
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	for _, want := range []struct {
+		prefix string
+		data   string
+	}{
+		{"id: 2\nevent: comment.created\ndata: ", `{"id":2,"type":"comment.created","topicId":1,"commentId":5,"createdAt":`},
+		{"id: 4\nevent: comment.deleted\ndata: ", `{"id":4,"type":"comment.deleted","topicId":1,"commentId":5,"createdAt":`},
+	} {
+		got := readEvent()
+		if !strings.HasPrefix(got, want.prefix+want.data) {
+			t.Fatalf("want event %q got %q", want.prefix+want.data+"...", got)
+		}
+	}
+
+	hub.Close()
+	if got := readEvent(); got != "" {
+		t.Fatalf("expected the stream to end on hub close, got %q", got)
+	}
+}
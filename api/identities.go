@@ -0,0 +1,116 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func (h *Handler) handleGetIdentities(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	identities, err := h.Store.Identities().GetByUser(currentUser.ID)
+	if err != nil {
+		h.logError("get identities: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	response := struct {
+		Identities []*store.Identity `json:"identities"`
+	}{
+		Identities: identities,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+// nameSuggestionAttempts limits the number of suffixed names tried
+// when the name derived from a display name is taken.
+const nameSuggestionAttempts = 10
+
+// handleGetNameSuggestion suggests a user name that is not taken
+// based on the display names of the current user identities.
+func (h *Handler) handleGetNameSuggestion(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	identities, err := h.Store.Identities().GetByUser(currentUser.ID)
+	if err != nil {
+		h.logError("get identities: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	var displayName string
+	for _, identity := range identities {
+		if store.SuggestUserName(identity.DisplayName, 1) != "" {
+			displayName = identity.DisplayName
+			break
+		}
+	}
+
+	response := struct {
+		Name string `json:"name"`
+	}{}
+
+	if displayName != "" {
+		for n := 1; n <= nameSuggestionAttempts; n++ {
+			name := store.SuggestUserName(displayName, n)
+			user, err := h.Store.Users().GetByNameFold(name)
+			if err == store.ErrNotFound || err == nil && user.ID == currentUser.ID {
+				response.Name = name
+				break
+			}
+			if err != nil {
+				h.logError("get user by name: %s", err)
+				h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+				return
+			}
+		}
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+func (h *Handler) handleDeleteIdentity(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid identity ID")
+		return
+	}
+
+	err = h.Store.Identities().Delete(currentUser.ID, id)
+	switch err {
+	case nil:
+	case store.ErrNotFound:
+		h.renderError(w, http.StatusNotFound, "NotFound", "Identity not found")
+		return
+	case store.ErrConflict:
+		h.renderError(w, http.StatusConflict, "LastIdentity", "Cannot unlink the last identity")
+		return
+	default:
+		h.logError("delete identity: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.render(w, http.StatusOK, struct{}{})
+}
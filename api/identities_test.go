@@ -0,0 +1,193 @@
+package api
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/mock"
+)
+
+func TestHandleIdentities(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := jwtService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			UserStore: &mock.UserStore{
+				OnGet: func(id int64) (*store.User, error) {
+					if id == 1 {
+						return &store.User{ID: 1, Name: "TestUser1"}, nil
+					}
+					if id == 2 {
+						return &store.User{ID: 2}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+				OnGetByNameFold: func(name string) (*store.User, error) {
+					if strings.EqualFold(name, "jane_doe") {
+						return &store.User{ID: 3, Name: "jane_doe"}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+			},
+			IdentityStore: &mock.IdentityStore{
+				OnGetByUser: func(userID int64) ([]*store.Identity, error) {
+					switch userID {
+					case 1:
+						return []*store.Identity{
+							{ID: 1, UserID: 1, AuthService: "github", AuthID: "123", CreatedAt: testTime},
+							{ID: 2, UserID: 1, AuthService: "google", AuthID: "456", CreatedAt: testTime, DisplayName: "Jane Doe", Picture: "https://example.test/jane.png"},
+						}, nil
+					case 2:
+						return []*store.Identity{
+							{ID: 3, UserID: 2, AuthService: "github", AuthID: "789", CreatedAt: testTime, DisplayName: "李"},
+						}, nil
+					}
+					t.Fatalf("OnGetByUser: unexpected user %d", userID)
+					return nil, nil
+				},
+				OnDelete: func(userID int64, id int64) error {
+					switch {
+					case userID == 1 && id == 2:
+						return nil
+					case userID == 1 && id == 1:
+						return store.ErrConflict
+					}
+					return store.ErrNotFound
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc     string
+		method   string
+		url      string
+		token    string
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "list",
+			method:   "GET",
+			url:      "/me/identities",
+			token:    token1,
+			wantCode: http.StatusOK,
+			wantBody: `{"identities":[{"id":1,"userId":1,"authService":"github","createdAt":"2001-02-03T04:05:06Z","displayName":"","picture":""},{"id":2,"userId":1,"authService":"google","createdAt":"2001-02-03T04:05:06Z","displayName":"Jane Doe","picture":"https://example.test/jane.png"}]}`,
+		},
+		{
+			desc:     "list no token",
+			method:   "GET",
+			url:      "/me/identities",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "name suggestion",
+			method:   "GET",
+			url:      "/me/name-suggestion",
+			token:    token1,
+			wantCode: http.StatusOK,
+			wantBody: `{"name":"Jane_Doe2"}`,
+		},
+		{
+			desc:     "no name suggestion",
+			method:   "GET",
+			url:      "/me/name-suggestion",
+			token:    token2,
+			wantCode: http.StatusOK,
+			wantBody: `{"name":""}`,
+		},
+		{
+			desc:     "name suggestion no token",
+			method:   "GET",
+			url:      "/me/name-suggestion",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "unlink",
+			method:   "DELETE",
+			url:      "/me/identities/2",
+			token:    token1,
+			wantCode: http.StatusOK,
+			wantBody: `{}`,
+		},
+		{
+			desc:     "unlink last",
+			method:   "DELETE",
+			url:      "/me/identities/1",
+			token:    token1,
+			wantCode: http.StatusConflict,
+			wantBody: `{"error":{"code":"LastIdentity","message":"Cannot unlink the last identity"}}`,
+		},
+		{
+			desc:     "unlink unknown",
+			method:   "DELETE",
+			url:      "/me/identities/3",
+			token:    token1,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Identity not found"}}`,
+		},
+		{
+			desc:     "unlink bad id",
+			method:   "DELETE",
+			url:      "/me/identities/BAD",
+			token:    token1,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid identity ID"}}`,
+		},
+		{
+			desc:     "unlink no token",
+			method:   "DELETE",
+			url:      "/me/identities/2",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest(tc.method, tc.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/disintegration/bebop/localauth"
+	"github.com/disintegration/bebop/session"
+)
+
+// localAuthRequest is the request body of the local authentication endpoints.
+type localAuthRequest struct {
+	Email    *string `json:"email"`
+	Password *string `json:"password"`
+	Token    *string `json:"token"`
+}
+
+// parseLocalAuthRequest parses the request and checks that the given fields are set.
+// It renders an error and returns nil if the request is invalid.
+func (h *Handler) parseLocalAuthRequest(w http.ResponseWriter, r *http.Request, fields ...string) *localAuthRequest {
+	if h.LocalAuthService == nil {
+		h.renderError(w, http.StatusNotFound, "NotFound", "Local authentication is disabled")
+		return nil
+	}
+
+	req := &localAuthRequest{}
+	err := h.parseRequest(r, req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return nil
+	}
+
+	for _, f := range fields {
+		switch {
+		case f == "email" && req.Email == nil:
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid email")
+			return nil
+		case f == "password" && req.Password == nil:
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid password")
+			return nil
+		case f == "token" && (req.Token == nil || *req.Token == ""):
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid token")
+			return nil
+		}
+	}
+
+	if req.Email != nil {
+		*req.Email = strings.TrimSpace(*req.Email)
+	}
+
+	return req
+}
+
+// renderLocalAuthResult renders the session tokens or the local authentication error.
+func (h *Handler) renderLocalAuthResult(w http.ResponseWriter, action string, tokens *session.Tokens, err error) {
+	switch err {
+	case nil:
+	case localauth.ErrInvalidEmail:
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid email")
+		return
+	case localauth.ErrInvalidPassword:
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid password")
+		return
+	case localauth.ErrInvalidCredentials:
+		h.renderError(w, http.StatusUnauthorized, "InvalidCredentials", "Invalid email or password")
+		return
+	case localauth.ErrInvalidToken:
+		h.renderError(w, http.StatusUnauthorized, "InvalidToken", "Token is invalid or expired")
+		return
+	case localauth.ErrNotVerified:
+		h.renderError(w, http.StatusForbidden, "EmailNotVerified", "Email is not verified")
+		return
+	case localauth.ErrUserBlocked:
+		h.renderError(w, http.StatusForbidden, "UserBlocked", "User is blocked")
+		return
+	case localauth.ErrMagicLinksDisabled:
+		h.renderError(w, http.StatusNotFound, "NotFound", "Magic links are disabled")
+		return
+	default:
+		h.Logger.Printf("ERROR: %s: %s", action, err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	if tokens == nil {
+		h.render(w, http.StatusOK, struct{}{})
+		return
+	}
+	h.render(w, http.StatusOK, tokens)
+}
+
+func (h *Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
+	req := h.parseLocalAuthRequest(w, r, "email", "password")
+	if req == nil {
+		return
+	}
+
+	err := h.LocalAuthService.Register(*req.Email, *req.Password)
+	h.renderLocalAuthResult(w, "register", nil, err)
+}
+
+func (h *Handler) handleResendVerification(w http.ResponseWriter, r *http.Request) {
+	req := h.parseLocalAuthRequest(w, r, "email")
+	if req == nil {
+		return
+	}
+
+	err := h.LocalAuthService.ResendVerification(*req.Email)
+	h.renderLocalAuthResult(w, "resend verification", nil, err)
+}
+
+func (h *Handler) handleVerify(w http.ResponseWriter, r *http.Request) {
+	req := h.parseLocalAuthRequest(w, r, "token")
+	if req == nil {
+		return
+	}
+
+	tokens, err := h.LocalAuthService.Verify(*req.Token)
+	h.renderLocalAuthResult(w, "verify email", tokens, err)
+}
+
+func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
+	req := h.parseLocalAuthRequest(w, r, "email", "password")
+	if req == nil {
+		return
+	}
+
+	tokens, err := h.LocalAuthService.Login(*req.Email, *req.Password)
+	h.renderLocalAuthResult(w, "login", tokens, err)
+}
+
+func (h *Handler) handlePasswordReset(w http.ResponseWriter, r *http.Request) {
+	req := h.parseLocalAuthRequest(w, r, "email")
+	if req == nil {
+		return
+	}
+
+	err := h.LocalAuthService.RequestPasswordReset(*req.Email)
+	h.renderLocalAuthResult(w, "request password reset", nil, err)
+}
+
+func (h *Handler) handlePasswordResetConfirm(w http.ResponseWriter, r *http.Request) {
+	req := h.parseLocalAuthRequest(w, r, "token", "password")
+	if req == nil {
+		return
+	}
+
+	tokens, err := h.LocalAuthService.ResetPassword(*req.Token, *req.Password)
+	h.renderLocalAuthResult(w, "reset password", tokens, err)
+}
+
+func (h *Handler) handleMagicLink(w http.ResponseWriter, r *http.Request) {
+	req := h.parseLocalAuthRequest(w, r, "email")
+	if req == nil {
+		return
+	}
+
+	err := h.LocalAuthService.RequestMagicLink(*req.Email)
+	h.renderLocalAuthResult(w, "request magic link", nil, err)
+}
+
+func (h *Handler) handleMagicLinkConfirm(w http.ResponseWriter, r *http.Request) {
+	req := h.parseLocalAuthRequest(w, r, "token")
+	if req == nil {
+		return
+	}
+
+	tokens, err := h.LocalAuthService.LoginWithMagicLink(*req.Token)
+	h.renderLocalAuthResult(w, "login with magic link", tokens, err)
+}
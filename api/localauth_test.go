@@ -0,0 +1,243 @@
+package api
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/disintegration/bebop/localauth"
+	"github.com/disintegration/bebop/session"
+)
+
+func TestHandleLocalAuth(t *testing.T) {
+	tokens := &session.Tokens{AccessToken: "access1", RefreshToken: "refresh1"}
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		LocalAuthService: &localauth.MockService{
+			OnRegister: func(email, password string) error {
+				switch {
+				case password == "short":
+					return localauth.ErrInvalidPassword
+				case email == "user@example.test":
+					return nil
+				}
+				return localauth.ErrInvalidEmail
+			},
+			OnResendVerification: func(email string) error {
+				return nil
+			},
+			OnVerify: func(token string) (*session.Tokens, error) {
+				if token == "verify1" {
+					return tokens, nil
+				}
+				return nil, localauth.ErrInvalidToken
+			},
+			OnLogin: func(email, password string) (*session.Tokens, error) {
+				switch {
+				case email == "user@example.test" && password == "password1":
+					return tokens, nil
+				case email == "new@example.test":
+					return nil, localauth.ErrNotVerified
+				case email == "blocked@example.test":
+					return nil, localauth.ErrUserBlocked
+				case email == "error@example.test":
+					return nil, errors.New("store error")
+				}
+				return nil, localauth.ErrInvalidCredentials
+			},
+			OnRequestPasswordReset: func(email string) error {
+				return nil
+			},
+			OnResetPassword: func(token, password string) (*session.Tokens, error) {
+				if token == "reset1" && password == "password2" {
+					return tokens, nil
+				}
+				return nil, localauth.ErrInvalidToken
+			},
+			OnRequestMagicLink: func(email string) error {
+				return localauth.ErrMagicLinksDisabled
+			},
+			OnLoginWithMagicLink: func(token string) (*session.Tokens, error) {
+				return nil, localauth.ErrMagicLinksDisabled
+			},
+		},
+	})
+
+	tests := []struct {
+		desc     string
+		url      string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "register",
+			url:      "/auth/register",
+			body:     `{"email":" user@example.test ","password":"password1"}`,
+			wantCode: http.StatusOK,
+			wantBody: `{}`,
+		},
+		{
+			desc:     "register invalid email",
+			url:      "/auth/register",
+			body:     `{"email":"user","password":"password1"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid email"}}`,
+		},
+		{
+			desc:     "register short password",
+			url:      "/auth/register",
+			body:     `{"email":"user@example.test","password":"short"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid password"}}`,
+		},
+		{
+			desc:     "register no password",
+			url:      "/auth/register",
+			body:     `{"email":"user@example.test"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid password"}}`,
+		},
+		{
+			desc:     "register bad body",
+			url:      "/auth/register",
+			body:     `BAD`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid request body"}}`,
+		},
+		{
+			desc:     "resend verification",
+			url:      "/auth/verify/resend",
+			body:     `{"email":"user@example.test"}`,
+			wantCode: http.StatusOK,
+			wantBody: `{}`,
+		},
+		{
+			desc:     "verify",
+			url:      "/auth/verify",
+			body:     `{"token":"verify1"}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"accessToken":"access1","refreshToken":"refresh1"}`,
+		},
+		{
+			desc:     "verify invalid token",
+			url:      "/auth/verify",
+			body:     `{"token":"verify2"}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"InvalidToken","message":"Token is invalid or expired"}}`,
+		},
+		{
+			desc:     "verify no token",
+			url:      "/auth/verify",
+			body:     `{"token":""}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid token"}}`,
+		},
+		{
+			desc:     "login",
+			url:      "/auth/login",
+			body:     `{"email":"user@example.test","password":"password1"}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"accessToken":"access1","refreshToken":"refresh1"}`,
+		},
+		{
+			desc:     "login wrong password",
+			url:      "/auth/login",
+			body:     `{"email":"user@example.test","password":"password2"}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"InvalidCredentials","message":"Invalid email or password"}}`,
+		},
+		{
+			desc:     "login not verified",
+			url:      "/auth/login",
+			body:     `{"email":"new@example.test","password":"password1"}`,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"EmailNotVerified","message":"Email is not verified"}}`,
+		},
+		{
+			desc:     "login blocked",
+			url:      "/auth/login",
+			body:     `{"email":"blocked@example.test","password":"password1"}`,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"UserBlocked","message":"User is blocked"}}`,
+		},
+		{
+			desc:     "login server error",
+			url:      "/auth/login",
+			body:     `{"email":"error@example.test","password":"password1"}`,
+			wantCode: http.StatusInternalServerError,
+			wantBody: `{"error":{"code":"ServerError","message":"Server error"}}`,
+		},
+		{
+			desc:     "request password reset",
+			url:      "/auth/password-reset",
+			body:     `{"email":"user@example.test"}`,
+			wantCode: http.StatusOK,
+			wantBody: `{}`,
+		},
+		{
+			desc:     "reset password",
+			url:      "/auth/password-reset/confirm",
+			body:     `{"token":"reset1","password":"password2"}`,
+			wantCode: http.StatusOK,
+			wantBody: `{"accessToken":"access1","refreshToken":"refresh1"}`,
+		},
+		{
+			desc:     "reset password no password",
+			url:      "/auth/password-reset/confirm",
+			body:     `{"token":"reset1"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid password"}}`,
+		},
+		{
+			desc:     "magic links disabled",
+			url:      "/auth/magic-link",
+			body:     `{"email":"user@example.test"}`,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Magic links are disabled"}}`,
+		},
+		{
+			desc:     "magic link login disabled",
+			url:      "/auth/magic-link/confirm",
+			body:     `{"token":"magic1"}`,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Magic links are disabled"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest("POST", tc.url, ioutil.NopCloser(strings.NewReader(tc.body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+	}
+
+	// The endpoints are not found if the local authentication is disabled.
+	apiHandler = New(&Config{Logger: log.New(ioutil.Discard, "", 0)})
+	req, err := http.NewRequest("POST", "/auth/login", ioutil.NopCloser(strings.NewReader(`{}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	apiHandler.ServeHTTP(w, req)
+	wantBody := `{"error":{"code":"NotFound","message":"Local authentication is disabled"}}`
+	if w.Code != http.StatusNotFound || w.Body.String() != wantBody {
+		t.Fatalf("disabled: want %d %q got %d %q", http.StatusNotFound, wantBody, w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/disintegration/bebop/store"
+)
+
+// mentionedUserIDs finds the users mentioned in the comment content.
+// Unknown user names are skipped. Store errors are logged and skip the name as well:
+// a missing notification is not worth failing the comment.
+func (h *Handler) mentionedUserIDs(content string) []int64 {
+	var ids []int64
+	for _, name := range store.Mentions(content) {
+		user, err := h.Store.Users().GetByName(name)
+		if err != nil {
+			if err != store.ErrNotFound {
+				h.logError("get user by name: %s", err)
+			}
+			continue
+		}
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+func (h *Handler) handleGetNotifications(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	var err error
+
+	unreadOnly := false
+	unreadParam := r.URL.Query().Get("unread")
+	if unreadParam != "" {
+		unreadOnly, err = strconv.ParseBool(unreadParam)
+		if err != nil {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid unread")
+			return
+		}
+	}
+
+	offset := 0
+	offsetParam := r.URL.Query().Get("offset")
+	if offsetParam != "" {
+		offset, err = strconv.Atoi(offsetParam)
+		if err != nil || offset < 0 {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid offset")
+			return
+		}
+	}
+
+	limit := 20
+	limitParam := r.URL.Query().Get("limit")
+	if limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > 100 {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid limit")
+			return
+		}
+	}
+
+	notifications, count, err := h.Store.Notifications().GetByUser(currentUser.ID, unreadOnly, offset, limit)
+	if err != nil {
+		h.logError("get notifications by user: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	response := struct {
+		Notifications []*store.Notification `json:"notifications"`
+		Count         int                   `json:"count"`
+	}{
+		Notifications: notifications,
+		Count:         count,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+func (h *Handler) handleMarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid notification ID")
+		return
+	}
+
+	err = h.Store.Notifications().MarkRead(currentUser.ID, id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Notification not found")
+			return
+		}
+		h.logError("mark notification read: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.render(w, http.StatusOK, struct{}{})
+}
+
+func (h *Handler) handleMarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	err := h.Store.Notifications().MarkAllRead(currentUser.ID)
+	if err != nil {
+		h.logError("mark all notifications read: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.render(w, http.StatusOK, struct{}{})
+}
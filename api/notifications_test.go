@@ -0,0 +1,245 @@
+package api
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/mock"
+)
+
+func TestHandleGetNotifications(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			UserStore: getReportTestUserStore(testTime),
+			NotifyStore: &mock.NotificationStore{
+				OnGetByUser: func(userID int64, unreadOnly bool, offset, limit int) ([]*store.Notification, int, error) {
+					if userID != 1 {
+						t.Fatalf("OnGetByUser: unexpected user %d", userID)
+					}
+					if unreadOnly && offset == 0 && limit == 20 {
+						return []*store.Notification{}, 0, nil
+					}
+					if !unreadOnly && offset == 1 && limit == 1 {
+						return []*store.Notification{
+							{
+								ID:         3,
+								UserID:     1,
+								Type:       store.NotificationMention,
+								TopicID:    4,
+								TopicTitle: "Topic4",
+								CommentID:  5,
+								ActorID:    2,
+								CreatedAt:  testTime,
+							},
+						}, 2, nil
+					}
+					t.Fatalf("OnGetByUser: unexpected params: %v, %d, %d", unreadOnly, offset, limit)
+					return nil, 0, nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc     string
+		url      string
+		token    string
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "no token",
+			url:      "/notifications",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "unread",
+			url:      "/notifications?unread=true",
+			token:    token1,
+			wantCode: http.StatusOK,
+			wantBody: `{"notifications":[],"count":0}`,
+		},
+		{
+			desc:     "page",
+			url:      "/notifications?offset=1&limit=1",
+			token:    token1,
+			wantCode: http.StatusOK,
+			wantBody: `{"notifications":[{"id":3,"userId":1,"type":"mention","topicId":4,"topicTitle":"Topic4","commentId":5,"actorId":2,"read":false,"createdAt":"2001-02-03T04:05:06Z"}],"count":2}`,
+		},
+		{
+			desc:     "bad unread",
+			url:      "/notifications?unread=BAD",
+			token:    token1,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid unread"}}`,
+		},
+		{
+			desc:     "bad limit",
+			url:      "/notifications?limit=1000",
+			token:    token1,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid limit"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest("GET", tc.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+	}
+}
+
+func TestHandleMarkNotificationsRead(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var marked []int64
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			UserStore: getReportTestUserStore(testTime),
+			NotifyStore: &mock.NotificationStore{
+				OnMarkRead: func(userID int64, id int64) error {
+					if userID != 1 || id != 3 {
+						return store.ErrNotFound
+					}
+					marked = append(marked, id)
+					return nil
+				},
+				OnMarkAllRead: func(userID int64) error {
+					marked = append(marked, -userID)
+					return nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc       string
+		url        string
+		token      string
+		wantCode   int
+		wantBody   string
+		wantMarked int64
+	}{
+		{
+			desc:     "no token",
+			url:      "/notifications/3/read",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:       "mark read",
+			url:        "/notifications/3/read",
+			token:      token1,
+			wantCode:   http.StatusOK,
+			wantBody:   `{}`,
+			wantMarked: 3,
+		},
+		{
+			desc:     "unknown notification",
+			url:      "/notifications/4/read",
+			token:    token1,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Notification not found"}}`,
+		},
+		{
+			desc:     "bad id",
+			url:      "/notifications/BAD_ID/read",
+			token:    token1,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid notification ID"}}`,
+		},
+		{
+			desc:     "mark all read, no token",
+			url:      "/notifications/read",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:       "mark all read",
+			url:        "/notifications/read",
+			token:      token1,
+			wantCode:   http.StatusOK,
+			wantBody:   `{}`,
+			wantMarked: -1,
+		},
+	}
+
+	for _, tc := range tests {
+		marked = nil
+
+		req, err := http.NewRequest("POST", tc.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+
+		if tc.wantMarked == 0 && len(marked) != 0 || tc.wantMarked != 0 && (len(marked) != 1 || marked[0] != tc.wantMarked) {
+			t.Fatalf("test %q: want marked %d got %v", tc.desc, tc.wantMarked, marked)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func (h *Handler) handleAddReaction(w http.ResponseWriter, r *http.Request) {
+	h.handleReaction(w, r, true)
+}
+
+func (h *Handler) handleRemoveReaction(w http.ResponseWriter, r *http.Request) {
+	h.handleReaction(w, r, false)
+}
+
+// handleReaction adds or removes the current user reaction to a comment
+// and renders the updated reaction counts of the comment.
+func (h *Handler) handleReaction(w http.ResponseWriter, r *http.Request, add bool) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid comment ID")
+		return
+	}
+
+	// Emoji characters may come percent-encoded in the URL path.
+	emoji, err := url.PathUnescape(h.urlParam(r, "emoji"))
+	if err != nil || !h.validReaction(emoji) {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid emoji")
+		return
+	}
+
+	_, err = h.Store.Comments().Get(id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Comment not found")
+			return
+		}
+		h.logError("get comment: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	if add {
+		err = h.Store.Reactions().Add(id, currentUser.ID, emoji)
+		if err != nil {
+			if err == store.ErrConflict {
+				h.renderError(w, http.StatusConflict, "AlreadyReacted", "Already reacted with this emoji")
+				return
+			}
+			h.logError("add reaction: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+	} else {
+		err = h.Store.Reactions().Remove(id, currentUser.ID, emoji)
+		if err != nil {
+			if err == store.ErrNotFound {
+				h.renderError(w, http.StatusNotFound, "NotFound", "Reaction not found")
+				return
+			}
+			h.logError("remove reaction: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+	}
+
+	counts, err := h.getReactionCounts([]int64{id}, currentUser.ID)
+	if err != nil {
+		h.logError("get reaction counts: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	reactions := counts[id]
+	if reactions == nil {
+		reactions = []*store.ReactionCount{}
+	}
+
+	response := struct {
+		Reactions []*store.ReactionCount `json:"reactions"`
+	}{
+		Reactions: reactions,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+// validReaction reports whether the emoji is in the configured reaction set.
+func (h *Handler) validReaction(emoji string) bool {
+	for _, e := range h.Reactions {
+		if e == emoji {
+			return true
+		}
+	}
+	return false
+}
+
+// getReactionCounts returns the reaction counts of the given comments.
+// Only the configured emoji are returned, in the configured order.
+func (h *Handler) getReactionCounts(commentIDs []int64, userID int64) (map[int64][]*store.ReactionCount, error) {
+	result := make(map[int64][]*store.ReactionCount)
+	if len(commentIDs) == 0 || len(h.Reactions) == 0 {
+		return result, nil
+	}
+
+	counts, err := h.Store.Reactions().GetCounts(commentIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for commentID, commentCounts := range counts {
+		byEmoji := make(map[string]*store.ReactionCount)
+		for _, rc := range commentCounts {
+			byEmoji[rc.Emoji] = rc
+		}
+		for _, emoji := range h.Reactions {
+			if rc, ok := byEmoji[emoji]; ok {
+				result[commentID] = append(result[commentID], rc)
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/mock"
+)
+
+func TestHandleReaction(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reactions := map[string]bool{"heart": true}
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			UserStore: &mock.UserStore{
+				OnGet: func(id int64) (*store.User, error) {
+					if id == 1 {
+						return &store.User{ID: 1, Name: "TestUser1", CreatedAt: testTime}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+			},
+			CommentStore: &mock.CommentStore{
+				OnGet: func(id int64) (*store.Comment, error) {
+					if id == 1 {
+						return &store.Comment{ID: 1, TopicID: 1, AuthorID: 1, Content: "Comment1"}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+			},
+			ReactionStore: &mock.ReactionStore{
+				OnAdd: func(commentID int64, userID int64, emoji string) error {
+					if commentID != 1 || userID != 1 {
+						t.Fatalf("OnAdd: unexpected params (unknown test)")
+					}
+					if reactions[emoji] {
+						return store.ErrConflict
+					}
+					reactions[emoji] = true
+					return nil
+				},
+				OnRemove: func(commentID int64, userID int64, emoji string) error {
+					if commentID != 1 || userID != 1 {
+						t.Fatalf("OnRemove: unexpected params (unknown test)")
+					}
+					if !reactions[emoji] {
+						return store.ErrNotFound
+					}
+					delete(reactions, emoji)
+					return nil
+				},
+				OnGetCounts: func(commentIDs []int64, userID int64) (map[int64][]*store.ReactionCount, error) {
+					if len(commentIDs) != 1 || commentIDs[0] != 1 || userID != 1 {
+						t.Fatalf("OnGetCounts: unexpected params (unknown test)")
+					}
+					counts := make(map[int64][]*store.ReactionCount)
+					for _, emoji := range []string{"+1", "heart", "😀"} {
+						if reactions[emoji] {
+							counts[1] = append(counts[1], &store.ReactionCount{Emoji: emoji, Count: 1, Reacted: true})
+						}
+					}
+					return counts, nil
+				},
+			},
+		},
+		JWTService: jwtService,
+		Reactions:  []string{"+1", "heart", "😀"},
+	})
+
+	tests := []struct {
+		desc     string
+		method   string
+		url      string
+		token    string
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "no token",
+			method:   "POST",
+			url:      "/comments/1/reactions/+1",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "add",
+			method:   "POST",
+			url:      "/comments/1/reactions/+1",
+			token:    token1,
+			wantCode: http.StatusOK,
+			wantBody: `{"reactions":[{"emoji":"+1","count":1,"reacted":true},{"emoji":"heart","count":1,"reacted":true}]}`,
+		},
+		{
+			desc:     "add again",
+			method:   "POST",
+			url:      "/comments/1/reactions/+1",
+			token:    token1,
+			wantCode: http.StatusConflict,
+			wantBody: `{"error":{"code":"AlreadyReacted","message":"Already reacted with this emoji"}}`,
+		},
+		{
+			desc:     "add percent-encoded emoji",
+			method:   "POST",
+			url:      "/comments/1/reactions/%F0%9F%98%80",
+			token:    token1,
+			wantCode: http.StatusOK,
+			wantBody: `{"reactions":[{"emoji":"+1","count":1,"reacted":true},{"emoji":"heart","count":1,"reacted":true},{"emoji":"😀","count":1,"reacted":true}]}`,
+		},
+		{
+			desc:     "unknown emoji",
+			method:   "POST",
+			url:      "/comments/1/reactions/rocket",
+			token:    token1,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid emoji"}}`,
+		},
+		{
+			desc:     "bad comment id",
+			method:   "POST",
+			url:      "/comments/BAD_ID/reactions/+1",
+			token:    token1,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid comment ID"}}`,
+		},
+		{
+			desc:     "comment not found",
+			method:   "POST",
+			url:      "/comments/2/reactions/+1",
+			token:    token1,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Comment not found"}}`,
+		},
+		{
+			desc:     "remove",
+			method:   "DELETE",
+			url:      "/comments/1/reactions/heart",
+			token:    token1,
+			wantCode: http.StatusOK,
+			wantBody: `{"reactions":[{"emoji":"+1","count":1,"reacted":true},{"emoji":"😀","count":1,"reacted":true}]}`,
+		},
+		{
+			desc:     "remove again",
+			method:   "DELETE",
+			url:      "/comments/1/reactions/heart",
+			token:    token1,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Reaction not found"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest(tc.method, tc.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+	}
+}
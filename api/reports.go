@@ -0,0 +1,314 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/disintegration/bebop/events"
+	"github.com/disintegration/bebop/store"
+)
+
+// reportActions maps the report resolution actions to the resolved report statuses.
+var reportActions = map[string]string{
+	"dismiss": store.ReportStatusDismissed,
+	"delete":  store.ReportStatusDeleted,
+	"block":   store.ReportStatusBlocked,
+}
+
+func (h *Handler) handleNewReport(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	req := struct {
+		TargetType *string `json:"targetType"`
+		TargetID   *int64  `json:"targetId"`
+		Reason     *string `json:"reason"`
+	}{}
+
+	err := h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.TargetType == nil || (*req.TargetType != store.ReportTargetTopic && *req.TargetType != store.ReportTargetComment) {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid target type")
+		return
+	}
+
+	if req.TargetID == nil || *req.TargetID < 1 {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid target ID")
+		return
+	}
+
+	if req.Reason == nil || !store.ValidReportReason(*req.Reason) {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid report reason")
+		return
+	}
+
+	var authorID int64
+	switch *req.TargetType {
+	case store.ReportTargetTopic:
+		topic, err := h.Store.Topics().Get(*req.TargetID)
+		if err != nil {
+			if err == store.ErrNotFound {
+				h.renderError(w, http.StatusNotFound, "NotFound", "Topic not found")
+				return
+			}
+			h.logError("get topic: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+		authorID = topic.AuthorID
+
+	case store.ReportTargetComment:
+		comment, err := h.Store.Comments().Get(*req.TargetID)
+		if err != nil {
+			if err == store.ErrNotFound {
+				h.renderError(w, http.StatusNotFound, "NotFound", "Comment not found")
+				return
+			}
+			h.logError("get comment: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+		authorID = comment.AuthorID
+	}
+
+	id, err := h.Store.Reports().New(currentUser.ID, *req.TargetType, *req.TargetID, authorID, *req.Reason)
+	if err != nil {
+		if err == store.ErrConflict {
+			h.renderError(w, http.StatusConflict, "AlreadyReported", "Content is already reported")
+			return
+		}
+		h.logError("create report: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	response := struct {
+		ID int64 `json:"id"`
+	}{
+		ID: id,
+	}
+
+	h.render(w, http.StatusCreated, response)
+}
+
+func (h *Handler) handleGetReports(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermReportManage) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" && !store.ValidReportStatus(status) {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid status")
+		return
+	}
+
+	var err error
+
+	offset := 0
+	offsetParam := r.URL.Query().Get("offset")
+	if offsetParam != "" {
+		offset, err = strconv.Atoi(offsetParam)
+		if err != nil || offset < 0 {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid offset")
+			return
+		}
+	}
+
+	limit := 10
+	limitParam := r.URL.Query().Get("limit")
+	if limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > 1000 {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid limit")
+			return
+		}
+	}
+
+	reports, count, err := h.Store.Reports().GetByStatus(status, offset, limit)
+	if err != nil {
+		h.logError("get reports by status: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	response := struct {
+		Reports []*store.Report `json:"reports"`
+		Count   int             `json:"count"`
+	}{
+		Reports: reports,
+		Count:   count,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+func (h *Handler) handleResolveReport(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermReportManage) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid report ID")
+		return
+	}
+
+	req := struct {
+		Action *string `json:"action"`
+	}{}
+
+	err = h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.Action == nil || reportActions[*req.Action] == "" {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid action")
+		return
+	}
+	status := reportActions[*req.Action]
+
+	report, err := h.Store.Reports().Get(id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Report not found")
+			return
+		}
+		h.logError("get report: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	if report.Status != store.ReportStatusOpen {
+		h.renderError(w, http.StatusConflict, "ReportResolved", "Report is already resolved")
+		return
+	}
+
+	// Deleting the content and blocking the author need their own permissions.
+	var permission string
+	switch {
+	case status == store.ReportStatusDeleted && report.TargetType == store.ReportTargetTopic:
+		permission = store.PermTopicDelete
+	case status == store.ReportStatusDeleted:
+		permission = store.PermCommentDelete
+	case status == store.ReportStatusBlocked:
+		permission = store.PermUserBlock
+	}
+	if permission != "" && !h.can(currentUser, permission) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	switch status {
+	case store.ReportStatusDeleted:
+		if report.TargetType == store.ReportTargetTopic {
+			// The deleted topic is sent with the webhook event.
+			var topic *store.Topic
+			if h.Webhooks != nil {
+				topic, err = h.Store.Topics().Get(report.TargetID)
+				if err != nil && err != store.ErrNotFound {
+					h.logError("get reported topic: %s", err)
+					h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+					return
+				}
+			}
+			err = h.Store.Topics().Delete(report.TargetID)
+			if err != nil {
+				h.logError("delete reported topic: %s", err)
+				h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+				return
+			}
+			h.audit(r, currentUser, store.AuditTopicDelete, store.AuditTargetTopic, report.TargetID, nil, nil)
+			h.publish(events.TopicDeleted, report.TargetID, 0)
+			if topic != nil {
+				h.publishWebhook(store.WebhookTopicDeleted, currentUser, topic, nil, nil)
+			}
+			break
+		}
+
+		// The comment topic is needed for the event.
+		// The comment is not found if it is already deleted.
+		var comment *store.Comment
+		comment, err = h.Store.Comments().Get(report.TargetID)
+		if err != nil && err != store.ErrNotFound {
+			h.logError("get reported comment: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+		err = h.Store.Comments().Delete(report.TargetID)
+		if err != nil {
+			h.logError("delete reported comment: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+		h.audit(r, currentUser, store.AuditCommentDelete, store.AuditTargetComment, report.TargetID, nil, nil)
+		if comment != nil {
+			h.publish(events.CommentDeleted, comment.TopicID, report.TargetID)
+			h.publishWebhook(store.WebhookCommentDeleted, currentUser, nil, comment, nil)
+		}
+
+	case store.ReportStatusBlocked:
+		err = h.Store.Users().SetBlocked(report.AuthorID, true)
+		if err != nil {
+			h.logError("set user blocked: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+		h.audit(r, currentUser, store.AuditUserBlocked, store.AuditTargetUser, report.AuthorID, nil, auditState{"blocked": true})
+		h.publishBlockedWebhook(currentUser, report.AuthorID)
+	}
+
+	err = h.Store.Reports().Resolve(id, status, currentUser.ID)
+	if err != nil {
+		if err == store.ErrConflict {
+			h.renderError(w, http.StatusConflict, "ReportResolved", "Report is already resolved")
+			return
+		}
+		h.logError("resolve report: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	before := report
+	report, err = h.Store.Reports().Get(id)
+	if err != nil {
+		h.logError("get report: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.audit(r, currentUser, store.AuditReportResolve, store.AuditTargetReport, id, before, report)
+
+	response := struct {
+		Report *store.Report `json:"report"`
+	}{
+		Report: report,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
@@ -0,0 +1,525 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/events"
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/mock"
+)
+
+func getReportTestUserStore(testTime time.Time) *mock.UserStore {
+	return &mock.UserStore{
+		OnGet: func(id int64) (*store.User, error) {
+			switch id {
+			case 1:
+				return &store.User{ID: 1, Name: "TestUser1", CreatedAt: testTime}, nil
+			case 2:
+				return &store.User{ID: 2, Name: "TestUser2", CreatedAt: testTime}, nil
+			}
+			return nil, store.ErrNotFound
+		},
+	}
+}
+
+func TestHandleNewReport(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			UserStore: getReportTestUserStore(testTime),
+			TopicStore: &mock.TopicStore{
+				OnGet: func(id int64) (*store.Topic, error) {
+					if id == 1 {
+						return &store.Topic{ID: 1, AuthorID: 3, Title: "Topic1"}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+			},
+			CommentStore: &mock.CommentStore{
+				OnGet: func(id int64) (*store.Comment, error) {
+					if id == 1 {
+						return &store.Comment{ID: 1, TopicID: 1, AuthorID: 4, Content: "Comment1"}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+			},
+			ReportStore: &mock.ReportStore{
+				OnNew: func(reporterID int64, targetType string, targetID int64, authorID int64, reason string) (int64, error) {
+					if reporterID != 1 || targetID != 1 || reason != "spam" {
+						t.Fatalf("OnNew: unexpected params (unknown test)")
+					}
+					switch {
+					case targetType == store.ReportTargetTopic && authorID == 3:
+						return 10, nil
+					case targetType == store.ReportTargetComment && authorID == 4:
+						return 0, store.ErrConflict
+					}
+					t.Fatalf("OnNew: unexpected params (unknown test)")
+					return 0, nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc     string
+		token    string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "no token",
+			body:     `{"targetType":"topic","targetId":1,"reason":"spam"}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "report topic",
+			token:    token1,
+			body:     `{"targetType":"topic","targetId":1,"reason":"spam"}`,
+			wantCode: http.StatusCreated,
+			wantBody: `{"id":10}`,
+		},
+		{
+			desc:     "already reported",
+			token:    token1,
+			body:     `{"targetType":"comment","targetId":1,"reason":"spam"}`,
+			wantCode: http.StatusConflict,
+			wantBody: `{"error":{"code":"AlreadyReported","message":"Content is already reported"}}`,
+		},
+		{
+			desc:     "topic not found",
+			token:    token1,
+			body:     `{"targetType":"topic","targetId":2,"reason":"spam"}`,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Topic not found"}}`,
+		},
+		{
+			desc:     "comment not found",
+			token:    token1,
+			body:     `{"targetType":"comment","targetId":2,"reason":"spam"}`,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Comment not found"}}`,
+		},
+		{
+			desc:     "bad target type",
+			token:    token1,
+			body:     `{"targetType":"user","targetId":1,"reason":"spam"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid target type"}}`,
+		},
+		{
+			desc:     "no target id",
+			token:    token1,
+			body:     `{"targetType":"topic","reason":"spam"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid target ID"}}`,
+		},
+		{
+			desc:     "empty reason",
+			token:    token1,
+			body:     `{"targetType":"topic","targetId":1,"reason":""}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid report reason"}}`,
+		},
+		{
+			desc:     "bad body",
+			token:    token1,
+			body:     `{`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid request body"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest("POST", "/reports", ioutil.NopCloser(strings.NewReader(tc.body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+	}
+}
+
+func TestHandleGetReports(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := jwtService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			UserStore: getReportTestUserStore(testTime),
+			ReportStore: &mock.ReportStore{
+				OnGetByStatus: func(status string, offset, limit int) ([]*store.Report, int, error) {
+					if status == store.ReportStatusOpen && offset == 0 && limit == 10 {
+						return []*store.Report{
+							{ID: 1, ReporterID: 1, TargetType: "comment", TargetID: 5, AuthorID: 3, Reason: "spam", Status: "open", CreatedAt: testTime},
+						}, 1, nil
+					}
+					if status == "" && offset == 1 && limit == 1 {
+						return []*store.Report{
+							{ID: 2, ReporterID: 1, TargetType: "topic", TargetID: 6, AuthorID: 3, Reason: "rude", Status: "blocked", CreatedAt: testTime, ResolvedBy: 2, ResolvedAt: &testTime},
+						}, 2, nil
+					}
+					t.Fatalf("OnGetByStatus: unexpected params (unknown test)")
+					return nil, 0, nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc     string
+		url      string
+		token    string
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "no token",
+			url:      "/reports?status=open",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "not admin",
+			url:      "/reports?status=open",
+			token:    token1,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
+		},
+		{
+			desc:     "open reports",
+			url:      "/reports?status=open",
+			token:    token2,
+			wantCode: http.StatusOK,
+			wantBody: `{"reports":[{"id":1,"reporterId":1,"targetType":"comment","targetId":5,"authorId":3,"reason":"spam","status":"open","createdAt":"2001-02-03T04:05:06Z","resolvedBy":0,"resolvedAt":null}],"count":1}`,
+		},
+		{
+			desc:     "all reports",
+			url:      "/reports?offset=1&limit=1",
+			token:    token2,
+			wantCode: http.StatusOK,
+			wantBody: `{"reports":[{"id":2,"reporterId":1,"targetType":"topic","targetId":6,"authorId":3,"reason":"rude","status":"blocked","createdAt":"2001-02-03T04:05:06Z","resolvedBy":2,"resolvedAt":"2001-02-03T04:05:06Z"}],"count":2}`,
+		},
+		{
+			desc:     "bad status",
+			url:      "/reports?status=closed",
+			token:    token2,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid status"}}`,
+		},
+		{
+			desc:     "bad limit",
+			url:      "/reports?limit=0",
+			token:    token2,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid limit"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest("GET", tc.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+	}
+}
+
+func TestHandleResolveReport(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := jwtService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		deletedTopic   int64
+		deletedComment int64
+		blockedUser    int64
+		resolved       = make(map[int64]string)
+	)
+
+	hub := events.NewHub()
+	sub := hub.Subscribe(0, 0)
+
+	getReport := func(id int64) *store.Report {
+		r := &store.Report{ID: id, ReporterID: 1, AuthorID: 3, Reason: "spam", Status: store.ReportStatusOpen, CreatedAt: testTime}
+		switch id {
+		case 1, 3:
+			r.TargetType, r.TargetID = store.ReportTargetTopic, 7
+		case 2:
+			r.TargetType, r.TargetID = store.ReportTargetComment, 8
+		case 4:
+			r.TargetType, r.TargetID = store.ReportTargetComment, 9
+			r.Status = store.ReportStatusDismissed
+		default:
+			return nil
+		}
+		if status, ok := resolved[id]; ok {
+			r.Status = status
+			r.ResolvedBy = 2
+			r.ResolvedAt = &testTime
+		}
+		return r
+	}
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			AuditStore: &mock.AuditStore{
+				OnNew: func(entry *store.AuditEntry) (int64, error) {
+					return 1, nil
+				},
+			},
+			UserStore: &mock.UserStore{
+				OnGet: getReportTestUserStore(testTime).OnGet,
+				OnSetBlocked: func(id int64, blocked bool) error {
+					if !blocked {
+						t.Fatalf("OnSetBlocked: unexpected params (unknown test)")
+					}
+					blockedUser = id
+					return nil
+				},
+			},
+			TopicStore: &mock.TopicStore{
+				OnDelete: func(id int64) error {
+					deletedTopic = id
+					return nil
+				},
+			},
+			CommentStore: &mock.CommentStore{
+				OnGet: func(id int64) (*store.Comment, error) {
+					return &store.Comment{ID: id, TopicID: 6, AuthorID: 3, CreatedAt: testTime}, nil
+				},
+				OnDelete: func(id int64) error {
+					deletedComment = id
+					return nil
+				},
+			},
+			ReportStore: &mock.ReportStore{
+				OnGet: func(id int64) (*store.Report, error) {
+					if r := getReport(id); r != nil {
+						return r, nil
+					}
+					return nil, store.ErrNotFound
+				},
+				OnResolve: func(id int64, status string, resolverID int64) error {
+					if resolverID != 2 {
+						t.Fatalf("OnResolve: unexpected params (unknown test)")
+					}
+					resolved[id] = status
+					return nil
+				},
+			},
+		},
+		JWTService: jwtService,
+		Events:     hub,
+	})
+
+	tests := []struct {
+		desc               string
+		url                string
+		token              string
+		body               string
+		wantCode           int
+		wantBody           string
+		wantDeletedTopic   int64
+		wantDeletedComment int64
+		wantBlockedUser    int64
+		wantEvent          string
+	}{
+		{
+			desc:     "no token",
+			url:      "/reports/1/resolve",
+			body:     `{"action":"dismiss"}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "not admin",
+			url:      "/reports/1/resolve",
+			token:    token1,
+			body:     `{"action":"dismiss"}`,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
+		},
+		{
+			desc:             "delete topic",
+			url:              "/reports/1/resolve",
+			token:            token2,
+			body:             `{"action":"delete"}`,
+			wantCode:         http.StatusOK,
+			wantBody:         `{"report":{"id":1,"reporterId":1,"targetType":"topic","targetId":7,"authorId":3,"reason":"spam","status":"deleted","createdAt":"2001-02-03T04:05:06Z","resolvedBy":2,"resolvedAt":"2001-02-03T04:05:06Z"}}`,
+			wantDeletedTopic: 7,
+			wantEvent:        "topic.deleted 7 0",
+		},
+		{
+			desc:               "delete comment",
+			url:                "/reports/2/resolve",
+			token:              token2,
+			body:               `{"action":"delete"}`,
+			wantCode:           http.StatusOK,
+			wantBody:           `{"report":{"id":2,"reporterId":1,"targetType":"comment","targetId":8,"authorId":3,"reason":"spam","status":"deleted","createdAt":"2001-02-03T04:05:06Z","resolvedBy":2,"resolvedAt":"2001-02-03T04:05:06Z"}}`,
+			wantDeletedComment: 8,
+			wantEvent:          "comment.deleted 6 8",
+		},
+		{
+			desc:            "block author",
+			url:             "/reports/3/resolve",
+			token:           token2,
+			body:            `{"action":"block"}`,
+			wantCode:        http.StatusOK,
+			wantBody:        `{"report":{"id":3,"reporterId":1,"targetType":"topic","targetId":7,"authorId":3,"reason":"spam","status":"blocked","createdAt":"2001-02-03T04:05:06Z","resolvedBy":2,"resolvedAt":"2001-02-03T04:05:06Z"}}`,
+			wantBlockedUser: 3,
+		},
+		{
+			desc:     "already resolved",
+			url:      "/reports/4/resolve",
+			token:    token2,
+			body:     `{"action":"dismiss"}`,
+			wantCode: http.StatusConflict,
+			wantBody: `{"error":{"code":"ReportResolved","message":"Report is already resolved"}}`,
+		},
+		{
+			desc:     "not found",
+			url:      "/reports/5/resolve",
+			token:    token2,
+			body:     `{"action":"dismiss"}`,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Report not found"}}`,
+		},
+		{
+			desc:     "bad action",
+			url:      "/reports/1/resolve",
+			token:    token2,
+			body:     `{"action":"ignore"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid action"}}`,
+		},
+		{
+			desc:     "bad report id",
+			url:      "/reports/BAD_ID/resolve",
+			token:    token2,
+			body:     `{"action":"dismiss"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid report ID"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		deletedTopic, deletedComment, blockedUser = 0, 0, 0
+
+		req, err := http.NewRequest("POST", tc.url, ioutil.NopCloser(strings.NewReader(tc.body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+
+		if tc.wantDeletedTopic != deletedTopic || tc.wantDeletedComment != deletedComment || tc.wantBlockedUser != blockedUser {
+			t.Fatalf("test %q: want deleted topic %d, deleted comment %d, blocked user %d got %d, %d, %d",
+				tc.desc, tc.wantDeletedTopic, tc.wantDeletedComment, tc.wantBlockedUser, deletedTopic, deletedComment, blockedUser)
+		}
+
+		var event string
+		select {
+		case e := <-sub.Events():
+			event = fmt.Sprintf("%s %d %d", e.Type, e.TopicID, e.CommentID)
+		default:
+		}
+		if tc.wantEvent != event {
+			t.Fatalf("test %q: want event %q got %q", tc.desc, tc.wantEvent, event)
+		}
+	}
+}
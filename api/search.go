@@ -0,0 +1,196 @@
+package api
+
+import (
+	"bytes"
+	"html"
+	"net/http"
+	"strconv"
+	"unicode"
+
+	"github.com/disintegration/bebop/store"
+)
+
+// snippetLen is the maximum length of a search result snippet in characters.
+const snippetLen = 200
+
+func (h *Handler) handleSearch(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	query := r.URL.Query().Get("q")
+	if !store.ValidSearchQuery(query) {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid search query")
+		return
+	}
+
+	searchType := r.URL.Query().Get("type")
+	if searchType == "" {
+		searchType = "topics"
+	}
+	if searchType != "topics" && searchType != "comments" {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid search type")
+		return
+	}
+
+	offset := 0
+	offsetParam := r.URL.Query().Get("offset")
+	if offsetParam != "" {
+		offset, err = strconv.Atoi(offsetParam)
+		if err != nil || offset < 0 {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid offset")
+			return
+		}
+	}
+
+	limit := 10
+	limitParam := r.URL.Query().Get("limit")
+	if limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > 100 {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid limit")
+			return
+		}
+	}
+
+	terms := store.SearchTerms(query)
+
+	if searchType == "topics" {
+		topics, count, err := h.Store.Topics().Search(query, offset, limit)
+		if err != nil {
+			h.logError("search topics: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+
+		type topicHit struct {
+			Topic   *store.Topic `json:"topic"`
+			Snippet string       `json:"snippet"`
+		}
+
+		hits := []topicHit{}
+		for _, topic := range topics {
+			hits = append(hits, topicHit{
+				Topic:   topic,
+				Snippet: highlight(topic.Title, terms, snippetLen),
+			})
+		}
+
+		response := struct {
+			Results []topicHit `json:"results"`
+			Count   int        `json:"count"`
+		}{
+			Results: hits,
+			Count:   count,
+		}
+
+		h.render(w, http.StatusOK, response)
+		return
+	}
+
+	comments, count, err := h.Store.Comments().Search(query, offset, limit)
+	if err != nil {
+		h.logError("search comments: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	type commentHit struct {
+		Comment *store.Comment `json:"comment"`
+		Snippet string         `json:"snippet"`
+	}
+
+	hits := []commentHit{}
+	for _, comment := range comments {
+		hits = append(hits, commentHit{
+			Comment: comment,
+			Snippet: highlight(comment.Content, terms, snippetLen),
+		})
+	}
+
+	response := struct {
+		Results []commentHit `json:"results"`
+		Count   int          `json:"count"`
+	}{
+		Results: hits,
+		Count:   count,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+// highlight cuts a fragment of at most maxLen characters around the first search term
+// occurrence from the text. The fragment is HTML-escaped and all the term occurrences
+// are wrapped in <mark> tags. Terms must be lowercase.
+func highlight(text string, terms []string, maxLen int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// marked[i] is the length of the term matched at position i, if any.
+	marked := make([]int, len(runes))
+	first := -1
+	for _, term := range terms {
+		t := []rune(term)
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if marked[i] < len(t) && runesEqual(lower[i:i+len(t)], t) {
+				marked[i] = len(t)
+				if first == -1 || i < first {
+					first = i
+				}
+			}
+		}
+	}
+
+	start := 0
+	if first > maxLen/4 {
+		start = first - maxLen/4
+	}
+	if start+maxLen > len(runes) {
+		start = len(runes) - maxLen
+		if start < 0 {
+			start = 0
+		}
+	}
+	end := start + maxLen
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	buf := new(bytes.Buffer)
+	if start > 0 {
+		buf.WriteString("…")
+	}
+	for i := start; i < end; {
+		n := marked[i]
+		if n == 0 {
+			buf.WriteString(html.EscapeString(string(runes[i])))
+			i++
+			continue
+		}
+		if i+n > end {
+			n = end - i
+		}
+		buf.WriteString("<mark>")
+		buf.WriteString(html.EscapeString(string(runes[i : i+n])))
+		buf.WriteString("</mark>")
+		i += n
+	}
+	if end < len(runes) {
+		buf.WriteString("…")
+	}
+
+	return buf.String()
+}
+
+func runesEqual(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/mock"
+)
+
+func TestHandleSearch(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			TopicStore: &mock.TopicStore{
+				OnSearch: func(query string, offset, limit int) ([]*store.Topic, int, error) {
+					if query == "hello" && offset == 0 && limit == 10 {
+						return []*store.Topic{
+							{
+								ID:            1,
+								AuthorID:      1,
+								Title:         "Hello <world>",
+								CreatedAt:     testTime,
+								LastCommentAt: testTime,
+								CommentCount:  10,
+							},
+						}, 1, nil
+					}
+					if query == "hello" && offset == 100 && limit == 5 {
+						return []*store.Topic{}, 1, nil
+					}
+					t.Fatalf("OnSearch: unexpected params (unknown test)")
+					return nil, 0, nil
+				},
+			},
+			CommentStore: &mock.CommentStore{
+				OnSearch: func(query string, offset, limit int) ([]*store.Comment, int, error) {
+					if query == "world" && offset == 0 && limit == 10 {
+						return []*store.Comment{
+							{
+								ID:        2,
+								TopicID:   1,
+								AuthorID:  1,
+								Content:   "Hello, World!",
+								CreatedAt: testTime,
+								UpdatedAt: testTime,
+							},
+						}, 1, nil
+					}
+					t.Fatalf("OnSearch: unexpected params (unknown test)")
+					return nil, 0, nil
+				},
+			},
+		},
+	})
+
+	tests := []struct {
+		desc     string
+		url      string
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "topics",
+			url:      "/search?q=hello",
+			wantCode: http.StatusOK,
+			wantBody: `{"results":[{"topic":{"id":1,"authorId":1,"categoryId":0,"title":"Hello \u003cworld\u003e","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":10,"pinned":false,"locked":false},"snippet":"\u003cmark\u003eHello\u003c/mark\u003e \u0026lt;world\u0026gt;"}],"count":1}`,
+		},
+		{
+			desc:     "topics offset 100",
+			url:      "/search?q=hello&type=topics&offset=100&limit=5",
+			wantCode: http.StatusOK,
+			wantBody: `{"results":[],"count":1}`,
+		},
+		{
+			desc:     "comments",
+			url:      "/search?q=world&type=comments",
+			wantCode: http.StatusOK,
+			wantBody: `{"results":[{"comment":{"id":2,"topicId":1,"authorId":1,"content":"Hello, World!","createdAt":"2001-02-03T04:05:06Z","updatedAt":"2001-02-03T04:05:06Z","editCount":0},"snippet":"Hello, \u003cmark\u003eWorld\u003c/mark\u003e!"}],"count":1}`,
+		},
+		{
+			desc:     "empty query",
+			url:      "/search?q=+",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid search query"}}`,
+		},
+		{
+			desc:     "bad type",
+			url:      "/search?q=hello&type=users",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid search type"}}`,
+		},
+		{
+			desc:     "bad offset",
+			url:      "/search?q=hello&offset=BAD_OFFSET",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid offset"}}`,
+		},
+		{
+			desc:     "bad limit",
+			url:      "/search?q=hello&limit=1000",
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid limit"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest("GET", tc.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text   string
+		terms  []string
+		maxLen int
+		want   string
+	}{
+		{"no match", []string{"foo"}, 100, "no match"},
+		{"Foo bar foo", []string{"foo"}, 100, "<mark>Foo</mark> bar <mark>foo</mark>"},
+		{"a < b & c", []string{"b"}, 100, "a &lt; <mark>b</mark> &amp; c"},
+		{"Доброе утро", []string{"утро"}, 100, "Доброе <mark>утро</mark>"},
+		{"0123456789abcdefghij", []string{"ab"}, 8, "…89<mark>ab</mark>cdef…"},
+		{"0123456789abcdef", []string{"ef"}, 8, "…89abcd<mark>ef</mark>"},
+		{"foobar", []string{"foo", "foobar"}, 100, "<mark>foobar</mark>"},
+	}
+
+	for _, tc := range tests {
+		got := highlight(tc.text, tc.terms, tc.maxLen)
+		if got != tc.want {
+			t.Fatalf("highlight(%q, %q, %d): got %q want %q", tc.text, tc.terms, tc.maxLen, got, tc.want)
+		}
+	}
+}
@@ -4,17 +4,21 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/disintegration/bebop/events"
 	"github.com/disintegration/bebop/store"
 )
 
 func (h *Handler) handleGetTopics(w http.ResponseWriter, r *http.Request) {
 	var err error
 
+	// Cursor mode is enabled by the "cursor" parameter. An empty cursor means the first page.
+	_, cursorMode := r.URL.Query()["cursor"]
+
 	offset := 0
 	offsetParam := r.URL.Query().Get("offset")
 	if offsetParam != "" {
 		offset, err = strconv.Atoi(offsetParam)
-		if err != nil || offset < 0 {
+		if err != nil || offset < 0 || cursorMode {
 			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid offset")
 			return
 		}
@@ -24,20 +28,87 @@ func (h *Handler) handleGetTopics(w http.ResponseWriter, r *http.Request) {
 	limitParam := r.URL.Query().Get("limit")
 	if limitParam != "" {
 		limit, err = strconv.Atoi(limitParam)
-		if err != nil || limit < 1 || limit > 1000 {
+		if err != nil || limit < 1 || limit > 1000 || (cursorMode && limit > maxCursorLimit) {
 			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid limit")
 			return
 		}
 	}
 
+	var cursor *store.TopicCursor
+	if cursorParam := r.URL.Query().Get("cursor"); cursorParam != "" {
+		cursor, err = store.ParseTopicCursor(cursorParam)
+		if err != nil {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid cursor")
+			return
+		}
+	}
+
+	var categoryID int64
+	categoryParam := r.URL.Query().Get("category")
+	if categoryParam != "" {
+		category, err := h.getCategoryByParam(categoryParam)
+		if err != nil {
+			if err == store.ErrNotFound {
+				h.renderError(w, http.StatusNotFound, "NotFound", "Category not found")
+				return
+			}
+			h.logError("get category: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+		categoryID = category.ID
+	}
+
+	if cursorMode {
+		var topics []*store.Topic
+
+		// Fetch one extra topic to find out if there is a next page.
+		if categoryID != 0 {
+			topics, err = h.Store.Topics().GetByCategoryAfter(categoryID, cursor, limit+1)
+		} else {
+			topics, err = h.Store.Topics().GetLatestAfter(cursor, limit+1)
+		}
+		if err != nil {
+			h.logError("get topics after cursor: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+
+		nextCursor := ""
+		if len(topics) > limit {
+			topics = topics[:limit]
+			nextCursor = store.NewTopicCursor(topics[limit-1]).String()
+		}
+
+		response := struct {
+			Topics     []*store.Topic `json:"topics"`
+			NextCursor string         `json:"nextCursor"`
+		}{
+			Topics:     topics,
+			NextCursor: nextCursor,
+		}
+
+		h.render(w, http.StatusOK, response)
+		return
+	}
+
 	var topics []*store.Topic
 	var count int
 
-	topics, count, err = h.Store.Topics().GetLatest(offset, limit)
-	if err != nil {
-		h.logError("get all topics: %s", err)
-		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
-		return
+	if categoryID != 0 {
+		topics, count, err = h.Store.Topics().GetByCategory(categoryID, offset, limit)
+		if err != nil {
+			h.logError("get topics by category: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+	} else {
+		topics, count, err = h.Store.Topics().GetLatest(offset, limit)
+		if err != nil {
+			h.logError("get all topics: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
 	}
 
 	response := struct {
@@ -65,8 +136,9 @@ func (h *Handler) handleNewTopic(w http.ResponseWriter, r *http.Request) {
 	}
 
 	req := struct {
-		Title   *string `json:"title"`
-		Content *string `json:"content"`
+		Title      *string `json:"title"`
+		Content    *string `json:"content"`
+		CategoryID *int64  `json:"categoryId"`
 	}{}
 
 	err := h.parseRequest(r, &req)
@@ -85,14 +157,35 @@ func (h *Handler) handleNewTopic(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := h.Store.Topics().New(currentUser.ID, *req.Title)
+	var categoryID int64
+	if req.CategoryID != nil && *req.CategoryID != 0 {
+		category, err := h.Store.Categories().Get(*req.CategoryID)
+		if err != nil {
+			if err == store.ErrNotFound {
+				h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid category")
+				return
+			}
+			h.logError("get category: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+
+		if category.AdminOnly && !h.can(currentUser, store.PermTopicPostAdminOnly) {
+			h.renderError(w, http.StatusForbidden, "Forbidden", "Only admins can post in this category")
+			return
+		}
+
+		categoryID = category.ID
+	}
+
+	id, err := h.Store.Topics().New(currentUser.ID, categoryID, *req.Title)
 	if err != nil {
 		h.logError("create topic: %s", err)
 		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
 		return
 	}
 
-	commentID, err := h.Store.Comments().New(id, currentUser.ID, *req.Content)
+	commentID, err := h.Store.Comments().New(id, currentUser.ID, *req.Content, h.mentionedUserIDs(*req.Content))
 	if err != nil {
 		h.Store.Topics().Delete(id)
 		h.logError("create comment: %s", err)
@@ -108,6 +201,10 @@ func (h *Handler) handleNewTopic(w http.ResponseWriter, r *http.Request) {
 		CommentID: commentID,
 	}
 
+	h.watch(currentUser.ID, id)
+	h.publish(events.TopicCreated, id, 0)
+	h.publishCreatedWebhook(store.WebhookTopicCreated, currentUser, id, commentID)
+
 	h.render(w, http.StatusCreated, response)
 }
 
@@ -138,7 +235,153 @@ func (h *Handler) handleGetTopic(w http.ResponseWriter, r *http.Request) {
 	h.render(w, http.StatusOK, response)
 }
 
-func (h *Handler) handleDeleteTopic(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) handleEditTopic(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid topic ID")
+		return
+	}
+
+	topic, err := h.Store.Topics().Get(id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Topic not found")
+			return
+		}
+		h.logError("get topic: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	if currentUser.ID != topic.AuthorID && !h.can(currentUser, store.PermTopicEdit) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	req := struct {
+		Title *string `json:"title"`
+	}{}
+
+	err = h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.Title == nil || !store.ValidTopicTitle(*req.Title) {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid topic title")
+		return
+	}
+
+	if topic.Title != *req.Title {
+		err = h.Store.Topics().SetTitle(id, currentUser.ID, *req.Title)
+		if err != nil {
+			h.logError("set topic title: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+
+		topic, err = h.Store.Topics().Get(id)
+		if err != nil {
+			h.logError("get topic: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+	}
+
+	response := struct {
+		Topic *store.Topic `json:"topic"`
+	}{
+		Topic: topic,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+func (h *Handler) handleSetTopicCategory(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermTopicMove) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid topic ID")
+		return
+	}
+
+	req := struct {
+		CategoryID *int64 `json:"categoryId"`
+	}{}
+
+	err = h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.CategoryID == nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid category")
+		return
+	}
+
+	topic, err := h.Store.Topics().Get(id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Topic not found")
+			return
+		}
+		h.logError("get topic: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	if *req.CategoryID != 0 {
+		_, err = h.Store.Categories().Get(*req.CategoryID)
+		if err != nil {
+			if err == store.ErrNotFound {
+				h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid category")
+				return
+			}
+			h.logError("get category: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+	}
+
+	if topic.CategoryID == *req.CategoryID {
+		h.render(w, http.StatusOK, struct{}{})
+		return
+	}
+
+	err = h.Store.Topics().SetCategory(id, *req.CategoryID)
+	if err != nil {
+		h.logError("set topic category: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.audit(r, currentUser, store.AuditTopicCategory, store.AuditTargetTopic, id,
+		auditState{"categoryId": topic.CategoryID}, auditState{"categoryId": *req.CategoryID})
+
+	h.render(w, http.StatusOK, struct{}{})
+}
+
+func (h *Handler) handleSetTopicPinned(w http.ResponseWriter, r *http.Request) {
 	currentUser := h.currentUser(r)
 	if currentUser == nil {
 		w.Header().Set("WWW-Authenticate", "Bearer")
@@ -146,7 +389,7 @@ func (h *Handler) handleDeleteTopic(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !currentUser.Admin {
+	if !h.can(currentUser, store.PermTopicPin) {
 		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
 		return
 	}
@@ -157,6 +400,120 @@ func (h *Handler) handleDeleteTopic(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	req := struct {
+		Pinned *bool `json:"pinned"`
+	}{}
+
+	err = h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.Pinned == nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid pinned")
+		return
+	}
+
+	topic, err := h.Store.Topics().Get(id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Topic not found")
+			return
+		}
+		h.logError("get topic: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	if topic.Pinned == *req.Pinned {
+		h.render(w, http.StatusOK, struct{}{})
+		return
+	}
+
+	err = h.Store.Topics().SetPinned(id, *req.Pinned)
+	if err != nil {
+		h.logError("set topic pinned: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.audit(r, currentUser, store.AuditTopicPinned, store.AuditTargetTopic, id,
+		auditState{"pinned": topic.Pinned}, auditState{"pinned": *req.Pinned})
+
+	h.render(w, http.StatusOK, struct{}{})
+}
+
+func (h *Handler) handleSetTopicLocked(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermTopicLock) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid topic ID")
+		return
+	}
+
+	req := struct {
+		Locked *bool `json:"locked"`
+	}{}
+
+	err = h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.Locked == nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid locked")
+		return
+	}
+
+	topic, err := h.Store.Topics().Get(id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Topic not found")
+			return
+		}
+		h.logError("get topic: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	if topic.Locked == *req.Locked {
+		h.render(w, http.StatusOK, struct{}{})
+		return
+	}
+
+	err = h.Store.Topics().SetLocked(id, *req.Locked)
+	if err != nil {
+		h.logError("set topic locked: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.audit(r, currentUser, store.AuditTopicLocked, store.AuditTargetTopic, id,
+		auditState{"locked": topic.Locked}, auditState{"locked": *req.Locked})
+
+	h.render(w, http.StatusOK, struct{}{})
+}
+
+func (h *Handler) handleGetTopicRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid topic ID")
+		return
+	}
+
 	_, err = h.Store.Topics().Get(id)
 	if err != nil {
 		if err == store.ErrNotFound {
@@ -168,6 +525,52 @@ func (h *Handler) handleDeleteTopic(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	revisions, err := h.Store.Topics().GetRevisions(id)
+	if err != nil {
+		h.logError("get topic revisions: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	response := struct {
+		Revisions []*store.TopicRevision `json:"revisions"`
+	}{
+		Revisions: revisions,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+func (h *Handler) handleDeleteTopic(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermTopicDelete) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid topic ID")
+		return
+	}
+
+	topic, err := h.Store.Topics().Get(id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Topic not found")
+			return
+		}
+		h.logError("get topic: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
 	err = h.Store.Topics().Delete(id)
 	if err != nil {
 		h.logError("delete topic: %s", err)
@@ -175,5 +578,10 @@ func (h *Handler) handleDeleteTopic(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.audit(r, currentUser, store.AuditTopicDelete, store.AuditTargetTopic, id, topic, nil)
+
+	h.publish(events.TopicDeleted, id, 0)
+	h.publishWebhook(store.WebhookTopicDeleted, currentUser, topic, nil, nil)
+
 	h.render(w, http.StatusOK, struct{}{})
 }
@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -20,9 +21,12 @@ func TestHandleGetTopics(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	topic2Cursor := &store.TopicCursor{LastCommentAt: testTime, ID: 2}
+
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
 			TopicStore: &mock.TopicStore{
 				OnGetLatest: func(offset, limit int) ([]*store.Topic, int, error) {
 					if offset == 0 {
@@ -51,28 +55,87 @@ func TestHandleGetTopics(t *testing.T) {
 					t.Fatalf("OnGetByTopic: unexpected params (unknown test)")
 					return nil, 0, nil
 				},
+				OnGetLatestAfter: func(cursor *store.TopicCursor, limit int) ([]*store.Topic, error) {
+					if cursor == nil && limit == 3 {
+						return []*store.Topic{
+							{ID: 1, AuthorID: 1, Title: "Topic1", CreatedAt: testTime, LastCommentAt: testTime, Pinned: true},
+							{ID: 2, AuthorID: 2, Title: "Topic2", CreatedAt: testTime, LastCommentAt: testTime},
+							{ID: 4, AuthorID: 2, Title: "Topic4", CreatedAt: testTime, LastCommentAt: testTime},
+						}, nil
+					}
+					if cursor != nil && reflect.DeepEqual(cursor, topic2Cursor) && limit == 3 {
+						return []*store.Topic{
+							{ID: 4, AuthorID: 2, Title: "Topic4", CreatedAt: testTime, LastCommentAt: testTime},
+						}, nil
+					}
+					t.Fatalf("OnGetLatestAfter: unexpected params (unknown test)")
+					return nil, nil
+				},
+				OnGetByCategoryAfter: func(categoryID int64, cursor *store.TopicCursor, limit int) ([]*store.Topic, error) {
+					if categoryID == 5 && cursor == nil && limit == 11 {
+						return []*store.Topic{
+							{ID: 3, AuthorID: 1, CategoryID: 5, Title: "Topic3", CreatedAt: testTime, LastCommentAt: testTime},
+						}, nil
+					}
+					t.Fatalf("OnGetByCategoryAfter: unexpected params (unknown test)")
+					return nil, nil
+				},
+				OnGetByCategory: func(categoryID int64, offset, limit int) ([]*store.Topic, int, error) {
+					if categoryID == 5 && offset == 0 {
+						return []*store.Topic{
+							{
+								ID:            3,
+								AuthorID:      1,
+								CategoryID:    5,
+								Title:         "Topic3",
+								CreatedAt:     testTime,
+								LastCommentAt: testTime,
+								CommentCount:  30,
+							},
+						}, 1, nil
+					}
+					t.Fatalf("OnGetByCategory: unexpected params (unknown test)")
+					return nil, 0, nil
+				},
+			},
+			CategoryStore: &mock.CategoryStore{
+				OnGet: func(id int64) (*store.Category, error) {
+					if id == 5 {
+						return &store.Category{ID: 5, Slug: "help", Name: "Help"}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+				OnGetBySlug: func(slug string) (*store.Category, error) {
+					if slug == "help" {
+						return &store.Category{ID: 5, Slug: "help", Name: "Help"}, nil
+					}
+					return nil, store.ErrNotFound
+				},
 			},
 		},
 	})
 
 	tests := []struct {
-		desc     string
-		offset   string
-		limit    string
-		wantCode int
-		wantBody string
+		desc       string
+		offset     string
+		limit      string
+		category   string
+		cursorMode bool
+		cursor     string
+		wantCode   int
+		wantBody   string
 	}{
 		{
 			desc:     "no offset",
 			wantCode: http.StatusOK,
-			wantBody: `{"topics":[{"id":1,"authorId":1,"title":"Topic1","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":10},{"id":2,"authorId":2,"title":"Topic2","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":20}],"count":2}`,
+			wantBody: `{"topics":[{"id":1,"authorId":1,"categoryId":0,"title":"Topic1","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":10,"pinned":false,"locked":false},{"id":2,"authorId":2,"categoryId":0,"title":"Topic2","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":20,"pinned":false,"locked":false}],"count":2}`,
 		},
 		{
 			desc:     "offset 0",
 			offset:   "0",
 			limit:    "100",
 			wantCode: http.StatusOK,
-			wantBody: `{"topics":[{"id":1,"authorId":1,"title":"Topic1","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":10},{"id":2,"authorId":2,"title":"Topic2","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":20}],"count":2}`,
+			wantBody: `{"topics":[{"id":1,"authorId":1,"categoryId":0,"title":"Topic1","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":10,"pinned":false,"locked":false},{"id":2,"authorId":2,"categoryId":0,"title":"Topic2","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":20,"pinned":false,"locked":false}],"count":2}`,
 		},
 		{
 			desc:     "offset 100",
@@ -93,6 +156,67 @@ func TestHandleGetTopics(t *testing.T) {
 			wantCode: http.StatusBadRequest,
 			wantBody: `{"error":{"code":"BadRequest","message":"Invalid limit"}}`,
 		},
+		{
+			desc:     "category id",
+			category: "5",
+			wantCode: http.StatusOK,
+			wantBody: `{"topics":[{"id":3,"authorId":1,"categoryId":5,"title":"Topic3","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":30,"pinned":false,"locked":false}],"count":1}`,
+		},
+		{
+			desc:     "category slug",
+			category: "help",
+			wantCode: http.StatusOK,
+			wantBody: `{"topics":[{"id":3,"authorId":1,"categoryId":5,"title":"Topic3","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":30,"pinned":false,"locked":false}],"count":1}`,
+		},
+		{
+			desc:     "not found category",
+			category: "off-topic",
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Category not found"}}`,
+		},
+		{
+			desc:       "cursor first page",
+			limit:      "2",
+			cursorMode: true,
+			wantCode:   http.StatusOK,
+			wantBody:   `{"topics":[{"id":1,"authorId":1,"categoryId":0,"title":"Topic1","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":0,"pinned":true,"locked":false},{"id":2,"authorId":2,"categoryId":0,"title":"Topic2","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":0,"pinned":false,"locked":false}],"nextCursor":"` + topic2Cursor.String() + `"}`,
+		},
+		{
+			desc:       "cursor last page",
+			limit:      "2",
+			cursorMode: true,
+			cursor:     topic2Cursor.String(),
+			wantCode:   http.StatusOK,
+			wantBody:   `{"topics":[{"id":4,"authorId":2,"categoryId":0,"title":"Topic4","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":0,"pinned":false,"locked":false}],"nextCursor":""}`,
+		},
+		{
+			desc:       "cursor category",
+			category:   "help",
+			cursorMode: true,
+			wantCode:   http.StatusOK,
+			wantBody:   `{"topics":[{"id":3,"authorId":1,"categoryId":5,"title":"Topic3","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":0,"pinned":false,"locked":false}],"nextCursor":""}`,
+		},
+		{
+			desc:       "bad cursor",
+			cursorMode: true,
+			cursor:     "BAD_CURSOR",
+			wantCode:   http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"BadRequest","message":"Invalid cursor"}}`,
+		},
+		{
+			desc:       "cursor with offset",
+			offset:     "10",
+			cursorMode: true,
+			wantCode:   http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"BadRequest","message":"Invalid offset"}}`,
+		},
+		{
+			desc:       "cursor limit too big",
+			limit:      "1000",
+			cursorMode: true,
+			wantCode:   http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"BadRequest","message":"Invalid limit"}}`,
+		},
 	}
 
 	for _, tc := range tests {
@@ -103,6 +227,12 @@ func TestHandleGetTopics(t *testing.T) {
 		if tc.limit != "" {
 			url += "&limit=" + tc.limit
 		}
+		if tc.category != "" {
+			url += "&category=" + tc.category
+		}
+		if tc.cursorMode {
+			url += "&cursor=" + tc.cursor
+		}
 		req, err := http.NewRequest("GET", url, nil)
 		if err != nil {
 			t.Fatal(err)
@@ -126,7 +256,7 @@ func TestHandleNewTopic(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	jwtService, err := jwt.NewService(strings.Repeat("0", 64))
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -150,6 +280,7 @@ func TestHandleNewTopic(t *testing.T) {
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
 			UserStore: &mock.UserStore{
 				OnGet: func(id int64) (*store.User, error) {
 					switch id {
@@ -161,7 +292,6 @@ func TestHandleNewTopic(t *testing.T) {
 							AuthService: "TestAuthService1",
 							AuthID:      "TestAuthID1",
 							Blocked:     false,
-							Admin:       false,
 							Avatar:      "Avatar1",
 						}, nil
 					case 2:
@@ -172,7 +302,6 @@ func TestHandleNewTopic(t *testing.T) {
 							AuthService: "TestAuthService2",
 							AuthID:      "TestAuthID2",
 							Blocked:     true,
-							Admin:       false,
 							Avatar:      "Avatar2",
 						}, nil
 					case 3:
@@ -183,7 +312,6 @@ func TestHandleNewTopic(t *testing.T) {
 							AuthService: "TestAuthService3",
 							AuthID:      "TestAuthID3",
 							Blocked:     false,
-							Admin:       false,
 							Avatar:      "Avatar3",
 						}, nil
 					}
@@ -191,21 +319,40 @@ func TestHandleNewTopic(t *testing.T) {
 				},
 			},
 			TopicStore: &mock.TopicStore{
-				OnNew: func(authorID int64, title string) (int64, error) {
-					if authorID != 1 || title != "Topic1" {
-						t.Fatalf("TopicStore.OnNew: unexpected params: %d, %q", authorID, title)
+				OnNew: func(authorID int64, categoryID int64, title string) (int64, error) {
+					if authorID != 1 || (categoryID != 0 && categoryID != 5) || title != "Topic1" {
+						t.Fatalf("TopicStore.OnNew: unexpected params: %d, %d, %q", authorID, categoryID, title)
 					}
 					return 11, nil
 				},
 			},
+			CategoryStore: &mock.CategoryStore{
+				OnGet: func(id int64) (*store.Category, error) {
+					switch id {
+					case 5:
+						return &store.Category{ID: 5, Slug: "help", Name: "Help"}, nil
+					case 6:
+						return &store.Category{ID: 6, Slug: "announcements", Name: "Announcements", AdminOnly: true}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+			},
 			CommentStore: &mock.CommentStore{
-				OnNew: func(topicID int64, authorID int64, content string) (int64, error) {
+				OnNew: func(topicID int64, authorID int64, content string, mentionIDs []int64) (int64, error) {
 					if topicID != 11 || authorID != 1 || content != "Comment1" {
 						t.Fatalf("CommentStore.OnNew: unexpected params: %d, %d, %q", topicID, authorID, content)
 					}
 					return 12, nil
 				},
 			},
+			WatchStore: &mock.WatchStore{
+				OnWatch: func(userID, topicID int64) error {
+					if userID != 1 || topicID != 11 {
+						t.Fatalf("WatchStore.OnWatch: unexpected params: %d, %d", userID, topicID)
+					}
+					return nil
+				},
+			},
 		},
 		JWTService: jwtService,
 	})
@@ -252,6 +399,27 @@ func TestHandleNewTopic(t *testing.T) {
 			wantCode: http.StatusCreated,
 			wantBody: `{"id":11,"commentId":12}`,
 		},
+		{
+			desc:     "good token with category",
+			body:     `{"title":"Topic1","content":"Comment1","categoryId":5}`,
+			token:    token1,
+			wantCode: http.StatusCreated,
+			wantBody: `{"id":11,"commentId":12}`,
+		},
+		{
+			desc:     "admin-only category",
+			body:     `{"title":"Topic1","content":"Comment1","categoryId":6}`,
+			token:    token1,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"Forbidden","message":"Only admins can post in this category"}}`,
+		},
+		{
+			desc:     "not found category",
+			body:     `{"title":"Topic1","content":"Comment1","categoryId":100}`,
+			token:    token1,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid category"}}`,
+		},
 		{
 			desc:     "bad request body",
 			body:     `{bad request body}`,
@@ -320,6 +488,7 @@ func TestHandleGetTopic(t *testing.T) {
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
 			TopicStore: &mock.TopicStore{
 				OnGet: func(id int64) (*store.Topic, error) {
 					switch id {
@@ -349,7 +518,7 @@ func TestHandleGetTopic(t *testing.T) {
 			desc:     "found",
 			id:       "1",
 			wantCode: http.StatusOK,
-			wantBody: `{"topic":{"id":1,"authorId":1,"title":"Topic1","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":10}}`,
+			wantBody: `{"topic":{"id":1,"authorId":1,"categoryId":0,"title":"Topic1","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":10,"pinned":false,"locked":false}}`,
 		},
 		{
 			desc:     "not found",
@@ -389,7 +558,7 @@ func TestDeleteTopic(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	jwtService, err := jwt.NewService(strings.Repeat("0", 64))
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -405,6 +574,12 @@ func TestDeleteTopic(t *testing.T) {
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			AuditStore: &mock.AuditStore{
+				OnNew: func(entry *store.AuditEntry) (int64, error) {
+					return 1, nil
+				},
+			},
 			UserStore: &mock.UserStore{
 				OnGet: func(id int64) (*store.User, error) {
 					switch id {
@@ -416,7 +591,6 @@ func TestDeleteTopic(t *testing.T) {
 							AuthService: "TestAuthService1",
 							AuthID:      "TestAuthID1",
 							Blocked:     false,
-							Admin:       false,
 							Avatar:      "Avatar1",
 						}, nil
 					case 2:
@@ -427,7 +601,6 @@ func TestDeleteTopic(t *testing.T) {
 							AuthService: "TestAuthService2",
 							AuthID:      "TestAuthID2",
 							Blocked:     false,
-							Admin:       true,
 							Avatar:      "Avatar2",
 						}, nil
 					}
@@ -525,3 +698,341 @@ func TestDeleteTopic(t *testing.T) {
 		}
 	}
 }
+
+func TestEditTopic(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := jwtService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token3, err := jwtService.Create(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		editedEditorID int64
+		editedTitle    string
+	)
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			UserStore: &mock.UserStore{
+				OnGet: func(id int64) (*store.User, error) {
+					switch id {
+					case 1:
+						return &store.User{ID: 1, Name: "TestUser1", CreatedAt: testTime}, nil
+					case 2:
+						return &store.User{ID: 2, Name: "TestUser2", CreatedAt: testTime}, nil
+					case 3:
+						return &store.User{ID: 3, Name: "TestUser3", CreatedAt: testTime}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+			},
+			TopicStore: &mock.TopicStore{
+				OnGet: func(id int64) (*store.Topic, error) {
+					switch id {
+					case 1:
+						title := "Topic1"
+						if editedTitle != "" {
+							title = editedTitle
+						}
+						return &store.Topic{
+							ID:            1,
+							AuthorID:      1,
+							Title:         title,
+							CreatedAt:     testTime,
+							LastCommentAt: testTime,
+							CommentCount:  10,
+						}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+				OnSetTitle: func(id int64, editorID int64, title string) error {
+					if id != 1 {
+						t.Fatalf("OnSetTitle: unexpected params: %d, %d, %q", id, editorID, title)
+					}
+					editedEditorID, editedTitle = editorID, title
+					return nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc         string
+		token        string
+		id           string
+		body         string
+		wantCode     int
+		wantBody     string
+		wantEditorID int64
+	}{
+		{
+			desc:     "no token",
+			token:    "",
+			id:       "1",
+			body:     `{"title":"Edited"}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "bad id",
+			token:    token1,
+			id:       "BAD_ID",
+			body:     `{"title":"Edited"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid topic ID"}}`,
+		},
+		{
+			desc:     "unknown id",
+			token:    token1,
+			id:       "100",
+			body:     `{"title":"Edited"}`,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Topic not found"}}`,
+		},
+		{
+			desc:     "not an author",
+			token:    token3,
+			id:       "1",
+			body:     `{"title":"Edited"}`,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
+		},
+		{
+			desc:     "large title",
+			token:    token1,
+			id:       "1",
+			body:     `{"title":"` + strings.Repeat("X", 101) + `"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid topic title"}}`,
+		},
+		{
+			desc:         "author",
+			token:        token1,
+			id:           "1",
+			body:         `{"title":"Edited by author"}`,
+			wantCode:     http.StatusOK,
+			wantBody:     `{"topic":{"id":1,"authorId":1,"categoryId":0,"title":"Edited by author","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":10,"pinned":false,"locked":false}}`,
+			wantEditorID: 1,
+		},
+		{
+			desc:         "admin",
+			token:        token2,
+			id:           "1",
+			body:         `{"title":"Edited by admin"}`,
+			wantCode:     http.StatusOK,
+			wantBody:     `{"topic":{"id":1,"authorId":1,"categoryId":0,"title":"Edited by admin","createdAt":"2001-02-03T04:05:06Z","lastCommentAt":"2001-02-03T04:05:06Z","commentCount":10,"pinned":false,"locked":false}}`,
+			wantEditorID: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		editedEditorID, editedTitle = 0, ""
+
+		req, err := http.NewRequest("PATCH", "/topics/"+tc.id, ioutil.NopCloser(strings.NewReader(tc.body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+
+		if tc.wantEditorID != editedEditorID {
+			t.Fatalf("test %q: want editor id %d got %d", tc.desc, tc.wantEditorID, editedEditorID)
+		}
+	}
+}
+
+func TestHandleSetTopicPinnedAndLocked(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := jwtService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		setID    int64
+		setField string
+		setValue bool
+	)
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			AuditStore: &mock.AuditStore{
+				OnNew: func(entry *store.AuditEntry) (int64, error) {
+					return 1, nil
+				},
+			},
+			UserStore: &mock.UserStore{
+				OnGet: func(id int64) (*store.User, error) {
+					switch id {
+					case 1:
+						return &store.User{ID: 1, Name: "TestUser1", CreatedAt: testTime}, nil
+					case 2:
+						return &store.User{ID: 2, Name: "TestUser2", CreatedAt: testTime}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+			},
+			TopicStore: &mock.TopicStore{
+				OnGet: func(id int64) (*store.Topic, error) {
+					if id == 1 {
+						return &store.Topic{ID: 1, AuthorID: 1, Title: "Topic1", Pinned: true}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+				OnSetPinned: func(id int64, pinned bool) error {
+					setID, setField, setValue = id, "pinned", pinned
+					return nil
+				},
+				OnSetLocked: func(id int64, locked bool) error {
+					setID, setField, setValue = id, "locked", locked
+					return nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc      string
+		url       string
+		token     string
+		body      string
+		wantCode  int
+		wantBody  string
+		wantID    int64
+		wantField string
+		wantValue bool
+	}{
+		{
+			desc:     "no token",
+			url:      "/topics/1/pinned",
+			body:     `{"pinned":true}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "not admin",
+			url:      "/topics/1/locked",
+			token:    token1,
+			body:     `{"locked":true}`,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
+		},
+		{
+			desc:      "unpin",
+			url:       "/topics/1/pinned",
+			token:     token2,
+			body:      `{"pinned":false}`,
+			wantCode:  http.StatusOK,
+			wantBody:  `{}`,
+			wantID:    1,
+			wantField: "pinned",
+			wantValue: false,
+		},
+		{
+			desc:     "already pinned",
+			url:      "/topics/1/pinned",
+			token:    token2,
+			body:     `{"pinned":true}`,
+			wantCode: http.StatusOK,
+			wantBody: `{}`,
+		},
+		{
+			desc:      "lock",
+			url:       "/topics/1/locked",
+			token:     token2,
+			body:      `{"locked":true}`,
+			wantCode:  http.StatusOK,
+			wantBody:  `{}`,
+			wantID:    1,
+			wantField: "locked",
+			wantValue: true,
+		},
+		{
+			desc:     "no value",
+			url:      "/topics/1/locked",
+			token:    token2,
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid locked"}}`,
+		},
+		{
+			desc:     "not found",
+			url:      "/topics/2/locked",
+			token:    token2,
+			body:     `{"locked":true}`,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Topic not found"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		setID, setField, setValue = 0, "", false
+
+		req, err := http.NewRequest("PUT", tc.url, ioutil.NopCloser(strings.NewReader(tc.body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+
+		if tc.wantID != setID || tc.wantField != setField || tc.wantValue != setValue {
+			t.Fatalf("test %q: want set (%d, %q, %t) got (%d, %q, %t)", tc.desc, tc.wantID, tc.wantField, tc.wantValue, setID, setField, setValue)
+		}
+	}
+}
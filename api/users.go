@@ -15,17 +15,43 @@ import (
 const avatarUploadMaxBytes = 5 * 1024 * 1024
 
 // extUser is a copy of store.User with more fields marshalled to JSON.
+// Admin is true if the user has the admin role. It is kept for the API clients
+// written before the roles.
 type extUser struct {
 	ID          int64     `json:"id"`
 	Name        string    `json:"name"`
 	CreatedAt   time.Time `json:"createdAt"`
 	AuthService string    `json:"authService"`
-	AuthID      string    `json:"-"`
 	Blocked     bool      `json:"blocked"`
 	Admin       bool      `json:"admin"`
 	Avatar      string    `json:"avatar"`
 }
 
+func newExtUser(user *store.User, admin bool) *extUser {
+	if user == nil {
+		return nil
+	}
+	return &extUser{
+		ID:          user.ID,
+		Name:        user.Name,
+		CreatedAt:   user.CreatedAt,
+		AuthService: user.AuthService,
+		Blocked:     user.Blocked,
+		Admin:       admin,
+		Avatar:      user.Avatar,
+	}
+}
+
+// hasRole checks if the role is in the list.
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *Handler) handleMe(w http.ResponseWriter, r *http.Request) {
 	currentUser := h.currentUser(r)
 
@@ -33,12 +59,48 @@ func (h *Handler) handleMe(w http.ResponseWriter, r *http.Request) {
 		currentUser.Avatar = h.AvatarService.URL(currentUser)
 	}
 
+	var (
+		roles       []string
+		permissions []string
+		unread      *int
+	)
+	if currentUser != nil {
+		var err error
+		roles, err = h.Store.Roles().GetByUser(currentUser.ID)
+		if err != nil {
+			h.logError("get user roles: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+
+		permissions, err = h.Store.Roles().GetPermissions(roles)
+		if err != nil {
+			h.logError("get role permissions: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+
+		count, err := h.Store.Notifications().CountUnread(currentUser.ID)
+		if err != nil {
+			h.logError("count unread notifications: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+		unread = &count
+	}
+
 	response := struct {
-		Authenticated bool     `json:"authenticated"`
-		User          *extUser `json:"user,omitempty"`
+		Authenticated       bool     `json:"authenticated"`
+		User                *extUser `json:"user,omitempty"`
+		Roles               []string `json:"roles,omitempty"`
+		Permissions         []string `json:"permissions,omitempty"`
+		UnreadNotifications *int     `json:"unreadNotifications,omitempty"`
 	}{
-		Authenticated: currentUser != nil,
-		User:          (*extUser)(currentUser),
+		Authenticated:       currentUser != nil,
+		User:                newExtUser(currentUser, hasRole(roles, store.RoleAdmin)),
+		Roles:               roles,
+		Permissions:         permissions,
+		UnreadNotifications: unread,
 	}
 
 	h.render(w, http.StatusOK, response)
@@ -86,10 +148,21 @@ func (h *Handler) handleGetUsers(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if currentUser != nil && currentUser.Admin {
+	if h.can(currentUser, store.PermUserView) {
+		adminIDs, err := h.Store.Roles().GetUsers(store.RoleAdmin)
+		if err != nil {
+			h.logError("get admins: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+		admins := make(map[int64]bool, len(adminIDs))
+		for _, id := range adminIDs {
+			admins[id] = true
+		}
+
 		users := make([]*extUser, 0, len(usermap))
 		for _, user := range usermap {
-			users = append(users, (*extUser)(user))
+			users = append(users, newExtUser(user, admins[user.ID]))
 		}
 		sort.Slice(users, func(i, j int) bool {
 			return users[i].ID < users[j].ID
@@ -146,11 +219,18 @@ func (h *Handler) handleGetUser(w http.ResponseWriter, r *http.Request) {
 		user.Avatar = h.AvatarService.URL(user)
 	}
 
-	if currentUser != nil && currentUser.Admin {
+	if h.can(currentUser, store.PermUserView) {
+		roles, err := h.Store.Roles().GetByUser(user.ID)
+		if err != nil {
+			h.logError("get user roles: %s", err)
+			h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+			return
+		}
+
 		response := struct {
 			User *extUser `json:"user"`
 		}{
-			User: (*extUser)(user),
+			User: newExtUser(user, hasRole(roles, store.RoleAdmin)),
 		}
 		h.render(w, http.StatusOK, response)
 		return
@@ -178,7 +258,7 @@ func (h *Handler) handleSetUserName(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !currentUser.Admin && currentUser.ID != id {
+	if currentUser.ID != id && !h.can(currentUser, store.PermUserRename) {
 		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
 		return
 	}
@@ -251,7 +331,7 @@ func (h *Handler) handleSetUserAvatar(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !currentUser.Admin && currentUser.ID != id {
+	if currentUser.ID != id && !h.can(currentUser, store.PermUserAvatar) {
 		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
 		return
 	}
@@ -325,7 +405,7 @@ func (h *Handler) handleSetUserBlocked(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !currentUser.Admin {
+	if !h.can(currentUser, store.PermUserBlock) {
 		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
 		return
 	}
@@ -374,5 +454,10 @@ func (h *Handler) handleSetUserBlocked(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.audit(r, currentUser, store.AuditUserBlocked, store.AuditTargetUser, id,
+		auditState{"blocked": user.Blocked}, auditState{"blocked": *req.Blocked})
+
+	h.publishBlockedWebhook(currentUser, id)
+
 	h.render(w, http.StatusOK, struct{}{})
 }
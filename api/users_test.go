@@ -22,7 +22,7 @@ func TestHandleMe(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	jwtService, err := jwt.NewService(strings.Repeat("0", 64))
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -30,6 +30,14 @@ func TestHandleMe(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	token2, err := jwtService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token3, err := jwtService.Create(3)
+	if err != nil {
+		t.Fatal(err)
+	}
 	token100, err := jwtService.Create(100)
 	if err != nil {
 		t.Fatal(err)
@@ -38,6 +46,15 @@ func TestHandleMe(t *testing.T) {
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			NotifyStore: &mock.NotificationStore{
+				OnCountUnread: func(userID int64) (int, error) {
+					if userID == 3 {
+						return 5, nil
+					}
+					return 0, nil
+				},
+			},
 			UserStore: &mock.UserStore{
 				OnGet: func(id int64) (*store.User, error) {
 					switch id {
@@ -49,9 +66,24 @@ func TestHandleMe(t *testing.T) {
 							AuthService: "TestAuthService1",
 							AuthID:      "TestAuthID1",
 							Blocked:     false,
-							Admin:       false,
 							Avatar:      "Avatar1",
 						}, nil
+					case 2:
+						return &store.User{
+							ID:          2,
+							Name:        "TestUser2",
+							CreatedAt:   testTime,
+							AuthService: "TestAuthService2",
+							AuthID:      "TestAuthID2",
+						}, nil
+					case 3:
+						return &store.User{
+							ID:          3,
+							Name:        "TestUser3",
+							CreatedAt:   testTime,
+							AuthService: "TestAuthService3",
+							AuthID:      "TestAuthID3",
+						}, nil
 					}
 					return nil, store.ErrNotFound
 				},
@@ -93,7 +125,19 @@ func TestHandleMe(t *testing.T) {
 			desc:     "known user",
 			token:    token1,
 			wantCode: http.StatusOK,
-			wantBody: `{"authenticated":true,"user":{"id":1,"name":"TestUser1","createdAt":"2001-02-03T04:05:06Z","authService":"TestAuthService1","blocked":false,"admin":false,"avatar":"https://example.com/avatars/Avatar1"}}`,
+			wantBody: `{"authenticated":true,"user":{"id":1,"name":"TestUser1","createdAt":"2001-02-03T04:05:06Z","authService":"TestAuthService1","blocked":false,"admin":false,"avatar":"https://example.com/avatars/Avatar1"},"unreadNotifications":0}`,
+		},
+		{
+			desc:     "admin",
+			token:    token2,
+			wantCode: http.StatusOK,
+			wantBody: `{"authenticated":true,"user":{"id":2,"name":"TestUser2","createdAt":"2001-02-03T04:05:06Z","authService":"TestAuthService2","blocked":false,"admin":true,"avatar":""},"roles":["admin"],"permissions":["audit.read","category.manage","comment.delete","comment.edit","comment.locked_topic","report.manage","topic.delete","topic.edit","topic.lock","topic.move","topic.pin","topic.post_admin_only","user.avatar","user.block","user.rename","user.view","webhook.manage"],"unreadNotifications":0}`,
+		},
+		{
+			desc:     "moderator",
+			token:    token3,
+			wantCode: http.StatusOK,
+			wantBody: `{"authenticated":true,"user":{"id":3,"name":"TestUser3","createdAt":"2001-02-03T04:05:06Z","authService":"TestAuthService3","blocked":false,"admin":false,"avatar":""},"roles":["moderator"],"permissions":["comment.delete","comment.locked_topic","report.manage","topic.delete","topic.lock","topic.move","topic.pin","user.view"],"unreadNotifications":5}`,
 		},
 	}
 
@@ -124,7 +168,7 @@ func TestHandleGetUsers(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	jwtService, err := jwt.NewService(strings.Repeat("0", 64))
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -140,6 +184,7 @@ func TestHandleGetUsers(t *testing.T) {
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
 			UserStore: &mock.UserStore{
 				OnGet: func(id int64) (*store.User, error) {
 					switch id {
@@ -151,7 +196,6 @@ func TestHandleGetUsers(t *testing.T) {
 							AuthService: "TestAuthService1",
 							AuthID:      "TestAuthID1",
 							Blocked:     false,
-							Admin:       false,
 							Avatar:      "Avatar1",
 						}, nil
 					case 2:
@@ -162,7 +206,6 @@ func TestHandleGetUsers(t *testing.T) {
 							AuthService: "TestAuthService2",
 							AuthID:      "TestAuthID2",
 							Blocked:     false,
-							Admin:       true,
 							Avatar:      "Avatar2",
 						}, nil
 					}
@@ -180,7 +223,6 @@ func TestHandleGetUsers(t *testing.T) {
 								AuthService: "TestAuthService1",
 								AuthID:      "TestAuthID1",
 								Blocked:     false,
-								Admin:       false,
 								Avatar:      "Avatar1",
 							}
 						case 2:
@@ -191,7 +233,6 @@ func TestHandleGetUsers(t *testing.T) {
 								AuthService: "TestAuthService2",
 								AuthID:      "TestAuthID2",
 								Blocked:     false,
-								Admin:       true,
 								Avatar:      "Avatar2",
 							}
 						default:
@@ -288,7 +329,7 @@ func TestHandleGetUser(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	jwtService, err := jwt.NewService(strings.Repeat("0", 64))
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -304,6 +345,7 @@ func TestHandleGetUser(t *testing.T) {
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
 			UserStore: &mock.UserStore{
 				OnGet: func(id int64) (*store.User, error) {
 					switch id {
@@ -315,7 +357,6 @@ func TestHandleGetUser(t *testing.T) {
 							AuthService: "TestAuthService1",
 							AuthID:      "TestAuthID1",
 							Blocked:     false,
-							Admin:       false,
 							Avatar:      "Avatar1",
 						}, nil
 					case 2:
@@ -326,7 +367,6 @@ func TestHandleGetUser(t *testing.T) {
 							AuthService: "TestAuthService2",
 							AuthID:      "TestAuthID2",
 							Blocked:     false,
-							Admin:       true,
 							Avatar:      "Avatar2",
 						}, nil
 					}
@@ -413,7 +453,7 @@ func TestHandleSetUserName(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	jwtService, err := jwt.NewService(strings.Repeat("0", 64))
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -432,6 +472,7 @@ func TestHandleSetUserName(t *testing.T) {
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
 			UserStore: &mock.UserStore{
 				OnGet: func(id int64) (*store.User, error) {
 					switch id {
@@ -443,7 +484,6 @@ func TestHandleSetUserName(t *testing.T) {
 							AuthService: "TestAuthService1",
 							AuthID:      "TestAuthID1",
 							Blocked:     false,
-							Admin:       false,
 							Avatar:      "Avatar1",
 						}, nil
 					case 2:
@@ -454,7 +494,6 @@ func TestHandleSetUserName(t *testing.T) {
 							AuthService: "TestAuthService2",
 							AuthID:      "TestAuthID2",
 							Blocked:     false,
-							Admin:       true,
 							Avatar:      "Avatar2",
 						}, nil
 					}
@@ -613,7 +652,7 @@ func TestHandleSetUserAvatar(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	jwtService, err := jwt.NewService(strings.Repeat("0", 64))
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -632,6 +671,7 @@ func TestHandleSetUserAvatar(t *testing.T) {
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
 			UserStore: &mock.UserStore{
 				OnGet: func(id int64) (*store.User, error) {
 					switch id {
@@ -643,7 +683,6 @@ func TestHandleSetUserAvatar(t *testing.T) {
 							AuthService: "TestAuthService1",
 							AuthID:      "TestAuthID1",
 							Blocked:     false,
-							Admin:       false,
 							Avatar:      "Avatar1",
 						}, nil
 					case 2:
@@ -654,7 +693,6 @@ func TestHandleSetUserAvatar(t *testing.T) {
 							AuthService: "TestAuthService2",
 							AuthID:      "TestAuthID2",
 							Blocked:     false,
-							Admin:       true,
 							Avatar:      "Avatar2",
 						}, nil
 					}
@@ -818,7 +856,7 @@ func TestHandleSetUserBlocked(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	jwtService, err := jwt.NewService(strings.Repeat("0", 64))
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -830,15 +868,21 @@ func TestHandleSetUserBlocked(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	token3, err := jwtService.Create(3)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	var (
 		userID      int64
 		userBlocked bool
+		auditEntry  *store.AuditEntry
 	)
 
 	apiHandler := New(&Config{
 		Logger: log.New(ioutil.Discard, "", 0),
 		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
 			UserStore: &mock.UserStore{
 				OnGet: func(id int64) (*store.User, error) {
 					switch id {
@@ -850,7 +894,6 @@ func TestHandleSetUserBlocked(t *testing.T) {
 							AuthService: "TestAuthService1",
 							AuthID:      "TestAuthID1",
 							Blocked:     false,
-							Admin:       false,
 							Avatar:      "Avatar1",
 						}, nil
 					case 2:
@@ -861,9 +904,18 @@ func TestHandleSetUserBlocked(t *testing.T) {
 							AuthService: "TestAuthService2",
 							AuthID:      "TestAuthID2",
 							Blocked:     false,
-							Admin:       true,
 							Avatar:      "Avatar2",
 						}, nil
+					case 3:
+						return &store.User{
+							ID:          3,
+							Name:        "TestUser3",
+							CreatedAt:   testTime,
+							AuthService: "TestAuthService3",
+							AuthID:      "TestAuthID3",
+							Blocked:     false,
+							Avatar:      "Avatar3",
+						}, nil
 					}
 					return nil, store.ErrNotFound
 				},
@@ -873,6 +925,12 @@ func TestHandleSetUserBlocked(t *testing.T) {
 					return nil
 				},
 			},
+			AuditStore: &mock.AuditStore{
+				OnNew: func(entry *store.AuditEntry) (int64, error) {
+					auditEntry = entry
+					return 1, nil
+				},
+			},
 		},
 		JWTService: jwtService,
 	})
@@ -903,6 +961,14 @@ func TestHandleSetUserBlocked(t *testing.T) {
 			wantCode: http.StatusForbidden,
 			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
 		},
+		{
+			desc:     "moderator token",
+			id:       "1",
+			token:    token3,
+			body:     `{"blocked":true}`,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
+		},
 		{
 			desc:        "admin token",
 			id:          "1",
@@ -946,12 +1012,14 @@ func TestHandleSetUserBlocked(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
+		req.RemoteAddr = "192.0.2.1:1234"
 		if tc.token != "" {
 			req.Header.Set("Authorization", "Bearer "+tc.token)
 		}
 
 		userID = 0
 		userBlocked = false
+		auditEntry = nil
 
 		w := httptest.NewRecorder()
 		apiHandler.ServeHTTP(w, req)
@@ -971,5 +1039,22 @@ func TestHandleSetUserBlocked(t *testing.T) {
 		if tc.wantBlocked != userBlocked {
 			t.Fatalf("test %q: want avatar data %v got %v", tc.desc, tc.wantBlocked, userBlocked)
 		}
+
+		if tc.wantID == 0 {
+			if auditEntry != nil {
+				t.Fatalf("test %q: want no audit entry got %+v", tc.desc, auditEntry)
+			}
+			continue
+		}
+		if auditEntry == nil {
+			t.Fatalf("test %q: want audit entry got nil", tc.desc)
+		}
+		if auditEntry.ActorID != 2 || auditEntry.Action != store.AuditUserBlocked || auditEntry.TargetType != store.AuditTargetUser ||
+			auditEntry.TargetID != tc.wantID || string(auditEntry.Before) != `{"blocked":false}` || string(auditEntry.After) != `{"blocked":true}` {
+			t.Fatalf("test %q: bad audit entry %+v", tc.desc, auditEntry)
+		}
+		if auditEntry.IP != "192.0.2.1" {
+			t.Fatalf("test %q: want audit ip %q got %q", tc.desc, "192.0.2.1", auditEntry.IP)
+		}
 	}
 }
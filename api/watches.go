@@ -0,0 +1,193 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/disintegration/bebop/digest"
+	"github.com/disintegration/bebop/store"
+)
+
+// watch makes the user watch the topic they took part in.
+// Store errors are logged: a missing watch is not worth failing the request.
+func (h *Handler) watch(userID, topicID int64) {
+	err := h.Store.Watches().Watch(userID, topicID)
+	if err != nil {
+		h.logError("watch topic: %s", err)
+	}
+}
+
+func (h *Handler) handleGetTopicWatching(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid topic ID")
+		return
+	}
+
+	watching, err := h.Store.Watches().IsWatching(currentUser.ID, id)
+	if err != nil {
+		h.logError("check topic watch: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	response := struct {
+		Watching bool `json:"watching"`
+	}{
+		Watching: watching,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+func (h *Handler) handleSetTopicWatching(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid topic ID")
+		return
+	}
+
+	req := struct {
+		Watching *bool `json:"watching"`
+	}{}
+
+	err = h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.Watching == nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid watching")
+		return
+	}
+
+	_, err = h.Store.Topics().Get(id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Topic not found")
+			return
+		}
+		h.logError("get topic: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	if *req.Watching {
+		err = h.Store.Watches().Watch(currentUser.ID, id)
+	} else {
+		err = h.Store.Watches().Unwatch(currentUser.ID, id)
+	}
+	if err != nil {
+		h.logError("set topic watching: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.render(w, http.StatusOK, struct{}{})
+}
+
+func (h *Handler) handleGetDigestMode(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	mode, err := h.Store.Digests().GetMode(currentUser.ID)
+	if err != nil {
+		h.logError("get digest mode: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	response := struct {
+		Mode string `json:"mode"`
+	}{
+		Mode: mode,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+func (h *Handler) handleSetDigestMode(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	req := struct {
+		Mode *string `json:"mode"`
+	}{}
+
+	err := h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.Mode == nil || !store.ValidDigestMode(*req.Mode) {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid mode")
+		return
+	}
+
+	err = h.Store.Digests().SetMode(currentUser.ID, *req.Mode)
+	if err != nil {
+		h.logError("set digest mode: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.render(w, http.StatusOK, struct{}{})
+}
+
+// handleDigestUnsubscribe turns the email digest off using the signed token
+// of an unsubscribe link. It does not require authentication.
+func (h *Handler) handleDigestUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	req := struct {
+		Token *string `json:"token"`
+	}{}
+
+	err := h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.Token == nil || *req.Token == "" {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid token")
+		return
+	}
+
+	userID, err := h.JWTService.VerifyPurpose(*req.Token, digest.UnsubscribePurpose)
+	if err != nil {
+		h.renderError(w, http.StatusUnauthorized, "InvalidToken", "Token is invalid or expired")
+		return
+	}
+
+	err = h.Store.Digests().SetMode(userID, store.DigestOff)
+	if err != nil {
+		h.logError("set digest mode: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.render(w, http.StatusOK, struct{}{})
+}
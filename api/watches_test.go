@@ -0,0 +1,338 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/digest"
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/mock"
+)
+
+func TestHandleTopicWatching(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var changes []string
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			UserStore: getReportTestUserStore(testTime),
+			TopicStore: &mock.TopicStore{
+				OnGet: func(id int64) (*store.Topic, error) {
+					if id == 1 {
+						return &store.Topic{ID: 1, AuthorID: 2, Title: "Topic1", CreatedAt: testTime}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+			},
+			WatchStore: &mock.WatchStore{
+				OnWatch: func(userID, topicID int64) error {
+					changes = append(changes, fmt.Sprintf("watch %d %d", userID, topicID))
+					return nil
+				},
+				OnUnwatch: func(userID, topicID int64) error {
+					changes = append(changes, fmt.Sprintf("unwatch %d %d", userID, topicID))
+					return nil
+				},
+				OnIsWatching: func(userID, topicID int64) (bool, error) {
+					return userID == 1 && topicID == 1, nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc       string
+		method     string
+		url        string
+		token      string
+		body       string
+		wantCode   int
+		wantBody   string
+		wantChange string
+	}{
+		{
+			desc:     "get, no token",
+			method:   "GET",
+			url:      "/topics/1/watching",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "get watching",
+			method:   "GET",
+			url:      "/topics/1/watching",
+			token:    token1,
+			wantCode: http.StatusOK,
+			wantBody: `{"watching":true}`,
+		},
+		{
+			desc:     "get not watching",
+			method:   "GET",
+			url:      "/topics/2/watching",
+			token:    token1,
+			wantCode: http.StatusOK,
+			wantBody: `{"watching":false}`,
+		},
+		{
+			desc:     "get, bad id",
+			method:   "GET",
+			url:      "/topics/BAD_ID/watching",
+			token:    token1,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid topic ID"}}`,
+		},
+		{
+			desc:     "set, no token",
+			method:   "PUT",
+			url:      "/topics/1/watching",
+			body:     `{"watching":true}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:       "watch",
+			method:     "PUT",
+			url:        "/topics/1/watching",
+			token:      token1,
+			body:       `{"watching":true}`,
+			wantCode:   http.StatusOK,
+			wantBody:   `{}`,
+			wantChange: "watch 1 1",
+		},
+		{
+			desc:       "unwatch",
+			method:     "PUT",
+			url:        "/topics/1/watching",
+			token:      token1,
+			body:       `{"watching":false}`,
+			wantCode:   http.StatusOK,
+			wantBody:   `{}`,
+			wantChange: "unwatch 1 1",
+		},
+		{
+			desc:     "set, no watching",
+			method:   "PUT",
+			url:      "/topics/1/watching",
+			token:    token1,
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid watching"}}`,
+		},
+		{
+			desc:     "set, unknown topic",
+			method:   "PUT",
+			url:      "/topics/2/watching",
+			token:    token1,
+			body:     `{"watching":true}`,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Topic not found"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		changes = nil
+
+		req, err := http.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+
+		if tc.wantChange == "" && len(changes) != 0 || tc.wantChange != "" && (len(changes) != 1 || changes[0] != tc.wantChange) {
+			t.Fatalf("test %q: want change %q got %v", tc.desc, tc.wantChange, changes)
+		}
+	}
+}
+
+func TestHandleDigestMode(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsubscribeToken, err := jwtService.CreatePurpose(2, digest.UnsubscribePurpose, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPurposeToken, err := jwtService.CreatePurpose(2, "other", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var changes []string
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			UserStore: getReportTestUserStore(testTime),
+			DigestStore: &mock.DigestStore{
+				OnGetMode: func(userID int64) (string, error) {
+					if userID != 1 {
+						t.Fatalf("OnGetMode: unexpected user %d", userID)
+					}
+					return store.DigestDaily, nil
+				},
+				OnSetMode: func(userID int64, mode string) error {
+					changes = append(changes, fmt.Sprintf("%d %s", userID, mode))
+					return nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc       string
+		method     string
+		url        string
+		token      string
+		body       string
+		wantCode   int
+		wantBody   string
+		wantChange string
+	}{
+		{
+			desc:     "get, no token",
+			method:   "GET",
+			url:      "/me/digest",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "get",
+			method:   "GET",
+			url:      "/me/digest",
+			token:    token1,
+			wantCode: http.StatusOK,
+			wantBody: `{"mode":"daily"}`,
+		},
+		{
+			desc:     "set, no token",
+			method:   "PUT",
+			url:      "/me/digest",
+			body:     `{"mode":"immediate"}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:       "set",
+			method:     "PUT",
+			url:        "/me/digest",
+			token:      token1,
+			body:       `{"mode":"immediate"}`,
+			wantCode:   http.StatusOK,
+			wantBody:   `{}`,
+			wantChange: "1 immediate",
+		},
+		{
+			desc:     "set, bad mode",
+			method:   "PUT",
+			url:      "/me/digest",
+			token:    token1,
+			body:     `{"mode":"weekly"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid mode"}}`,
+		},
+		{
+			desc:       "unsubscribe",
+			method:     "POST",
+			url:        "/digest/unsubscribe",
+			body:       `{"token":"` + unsubscribeToken + `"}`,
+			wantCode:   http.StatusOK,
+			wantBody:   `{}`,
+			wantChange: "2 off",
+		},
+		{
+			desc:     "unsubscribe, access token",
+			method:   "POST",
+			url:      "/digest/unsubscribe",
+			body:     `{"token":"` + token1 + `"}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"InvalidToken","message":"Token is invalid or expired"}}`,
+		},
+		{
+			desc:     "unsubscribe, other purpose token",
+			method:   "POST",
+			url:      "/digest/unsubscribe",
+			body:     `{"token":"` + otherPurposeToken + `"}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"InvalidToken","message":"Token is invalid or expired"}}`,
+		},
+		{
+			desc:     "unsubscribe, no token",
+			method:   "POST",
+			url:      "/digest/unsubscribe",
+			body:     `{}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid token"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		changes = nil
+
+		req, err := http.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+
+		if tc.wantChange == "" && len(changes) != 0 || tc.wantChange != "" && (len(changes) != 1 || changes[0] != tc.wantChange) {
+			t.Fatalf("test %q: want change %q got %v", tc.desc, tc.wantChange, changes)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/webhook"
+)
+
+// publishWebhook queues a webhook event. It does nothing if the webhooks are disabled.
+func (h *Handler) publishWebhook(event string, actor *store.User, topic *store.Topic, comment *store.Comment, user *store.User) {
+	if h.Webhooks == nil {
+		return
+	}
+	h.Webhooks.Publish(&webhook.Payload{
+		Event:   event,
+		ActorID: actor.ID,
+		Topic:   topic,
+		Comment: comment,
+		User:    user,
+	})
+}
+
+// publishCreatedWebhook queues a webhook event with the stored state of a new topic
+// and its first comment, or of a new comment. It does nothing if the webhooks are disabled.
+// Store errors are logged: a missing webhook event is not worth failing the request.
+func (h *Handler) publishCreatedWebhook(event string, actor *store.User, topicID, commentID int64) {
+	if h.Webhooks == nil {
+		return
+	}
+
+	topic, err := h.Store.Topics().Get(topicID)
+	if err != nil {
+		h.logError("get webhook topic: %s", err)
+		return
+	}
+
+	comment, err := h.Store.Comments().Get(commentID)
+	if err != nil {
+		h.logError("get webhook comment: %s", err)
+		return
+	}
+
+	h.publishWebhook(event, actor, topic, comment, nil)
+}
+
+// publishBlockedWebhook queues a webhook event with the stored state of a blocked
+// or unblocked user. It does nothing if the webhooks are disabled.
+func (h *Handler) publishBlockedWebhook(actor *store.User, userID int64) {
+	if h.Webhooks == nil {
+		return
+	}
+
+	user, err := h.Store.Users().Get(userID)
+	if err != nil {
+		h.logError("get webhook user: %s", err)
+		return
+	}
+
+	event := store.WebhookUserUnblocked
+	if user.Blocked {
+		event = store.WebhookUserBlocked
+	}
+	h.publishWebhook(event, actor, nil, nil, user)
+}
+
+func (h *Handler) handleGetWebhooks(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermWebhookManage) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	webhooks, err := h.Store.Webhooks().GetAll()
+	if err != nil {
+		h.logError("get all webhooks: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	response := struct {
+		Webhooks []*store.Webhook `json:"webhooks"`
+	}{
+		Webhooks: webhooks,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+func (h *Handler) handleNewWebhook(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermWebhookManage) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	req := struct {
+		URL    *string  `json:"url"`
+		Secret *string  `json:"secret"`
+		Events []string `json:"events"`
+	}{}
+
+	err := h.parseRequest(r, &req)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid request body")
+		return
+	}
+
+	if req.URL == nil || !webhook.ValidURL(*req.URL) {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid webhook URL")
+		return
+	}
+
+	if req.Secret == nil || *req.Secret == "" {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid webhook secret")
+		return
+	}
+
+	if len(req.Events) == 0 {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid webhook events")
+		return
+	}
+	for _, event := range req.Events {
+		if !store.ValidWebhookEvent(event) {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid webhook events")
+			return
+		}
+	}
+
+	id, err := h.Store.Webhooks().New(*req.URL, *req.Secret, req.Events)
+	if err != nil {
+		h.logError("create webhook: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	hook, err := h.Store.Webhooks().Get(id)
+	if err != nil {
+		h.logError("get webhook: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.audit(r, currentUser, store.AuditWebhookCreate, store.AuditTargetWebhook, id, nil, hook)
+
+	response := struct {
+		ID int64 `json:"id"`
+	}{
+		ID: id,
+	}
+
+	h.render(w, http.StatusCreated, response)
+}
+
+// getWebhookByParam finds a webhook by the "id" URL parameter and renders
+// an error response if it fails. It returns nil in that case.
+func (h *Handler) getWebhookByParam(w http.ResponseWriter, r *http.Request) *store.Webhook {
+	id, err := strconv.ParseInt(h.urlParam(r, "id"), 10, 64)
+	if err != nil {
+		h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid webhook ID")
+		return nil
+	}
+
+	hook, err := h.Store.Webhooks().Get(id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			h.renderError(w, http.StatusNotFound, "NotFound", "Webhook not found")
+			return nil
+		}
+		h.logError("get webhook: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return nil
+	}
+
+	return hook
+}
+
+func (h *Handler) handleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermWebhookManage) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	hook := h.getWebhookByParam(w, r)
+	if hook == nil {
+		return
+	}
+
+	response := struct {
+		Webhook *store.Webhook `json:"webhook"`
+	}{
+		Webhook: hook,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
+
+func (h *Handler) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermWebhookManage) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	hook := h.getWebhookByParam(w, r)
+	if hook == nil {
+		return
+	}
+
+	err := h.Store.Webhooks().Delete(hook.ID)
+	if err != nil {
+		h.logError("delete webhook: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	h.audit(r, currentUser, store.AuditWebhookDelete, store.AuditTargetWebhook, hook.ID, hook, nil)
+
+	h.render(w, http.StatusOK, struct{}{})
+}
+
+func (h *Handler) handleGetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	currentUser := h.currentUser(r)
+	if currentUser == nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.renderError(w, http.StatusUnauthorized, "Unauthorized", "Authentication required")
+		return
+	}
+
+	if !h.can(currentUser, store.PermWebhookManage) {
+		h.renderError(w, http.StatusForbidden, "Forbidden", "Access denied")
+		return
+	}
+
+	var err error
+
+	offset := 0
+	offsetParam := r.URL.Query().Get("offset")
+	if offsetParam != "" {
+		offset, err = strconv.Atoi(offsetParam)
+		if err != nil || offset < 0 {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid offset")
+			return
+		}
+	}
+
+	limit := 10
+	limitParam := r.URL.Query().Get("limit")
+	if limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > 1000 {
+			h.renderError(w, http.StatusBadRequest, "BadRequest", "Invalid limit")
+			return
+		}
+	}
+
+	hook := h.getWebhookByParam(w, r)
+	if hook == nil {
+		return
+	}
+
+	deliveries, count, err := h.Store.Webhooks().GetDeliveries(hook.ID, offset, limit)
+	if err != nil {
+		h.logError("get webhook deliveries: %s", err)
+		h.renderError(w, http.StatusInternalServerError, "ServerError", "Server error")
+		return
+	}
+
+	response := struct {
+		Deliveries []*store.WebhookDelivery `json:"deliveries"`
+		Count      int                      `json:"count"`
+	}{
+		Deliveries: deliveries,
+		Count:      count,
+	}
+
+	h.render(w, http.StatusOK, response)
+}
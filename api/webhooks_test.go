@@ -0,0 +1,427 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/mock"
+	"github.com/disintegration/bebop/webhook"
+)
+
+func TestHandleNewWebhook(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := jwtService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var created string
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			AuditStore: &mock.AuditStore{
+				OnNew: func(entry *store.AuditEntry) (int64, error) {
+					return 1, nil
+				},
+			},
+			UserStore: getReportTestUserStore(testTime),
+			WebhookStore: &mock.WebhookStore{
+				OnNew: func(url, secret string, events []string) (int64, error) {
+					created = url + " " + secret + " " + strings.Join(events, ",")
+					return 3, nil
+				},
+				OnGet: func(id int64) (*store.Webhook, error) {
+					return &store.Webhook{ID: id, URL: "https://example.com/hook", Secret: "s", Events: []string{"topic.created", "user.blocked"}, CreatedAt: testTime}, nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc        string
+		token       string
+		body        string
+		wantCode    int
+		wantBody    string
+		wantCreated string
+	}{
+		{
+			desc:     "no token",
+			body:     `{"url":"https://example.com/hook","secret":"s","events":["topic.created"]}`,
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "not admin",
+			token:    token1,
+			body:     `{"url":"https://example.com/hook","secret":"s","events":["topic.created"]}`,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
+		},
+		{
+			desc:        "admin",
+			token:       token2,
+			body:        `{"url":"https://example.com/hook","secret":"s","events":["topic.created","user.blocked"]}`,
+			wantCode:    http.StatusCreated,
+			wantBody:    `{"id":3}`,
+			wantCreated: "https://example.com/hook s topic.created,user.blocked",
+		},
+		{
+			desc:     "bad url",
+			token:    token2,
+			body:     `{"url":"ftp://example.com/hook","secret":"s","events":["topic.created"]}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid webhook URL"}}`,
+		},
+		{
+			desc:     "no secret",
+			token:    token2,
+			body:     `{"url":"https://example.com/hook","events":["topic.created"]}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid webhook secret"}}`,
+		},
+		{
+			desc:     "no events",
+			token:    token2,
+			body:     `{"url":"https://example.com/hook","secret":"s","events":[]}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid webhook events"}}`,
+		},
+		{
+			desc:     "bad event",
+			token:    token2,
+			body:     `{"url":"https://example.com/hook","secret":"s","events":["topic.created","topic.edited"]}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid webhook events"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		created = ""
+
+		req, err := http.NewRequest("POST", "/webhooks", ioutil.NopCloser(strings.NewReader(tc.body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+
+		if tc.wantCreated != created {
+			t.Fatalf("test %q: want created webhook %q got %q", tc.desc, tc.wantCreated, created)
+		}
+	}
+}
+
+func TestHandleWebhooks(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token1, err := jwtService.Create(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := jwtService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var deleted int64
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			AuditStore: &mock.AuditStore{
+				OnNew: func(entry *store.AuditEntry) (int64, error) {
+					return 1, nil
+				},
+			},
+			UserStore: getReportTestUserStore(testTime),
+			WebhookStore: &mock.WebhookStore{
+				OnGetAll: func() ([]*store.Webhook, error) {
+					return []*store.Webhook{
+						{ID: 1, URL: "https://example.com/hook", Secret: "s", Events: []string{"topic.created"}, CreatedAt: testTime},
+					}, nil
+				},
+				OnGet: func(id int64) (*store.Webhook, error) {
+					if id == 1 {
+						return &store.Webhook{ID: 1, URL: "https://example.com/hook", Secret: "s", Events: []string{"topic.created"}, CreatedAt: testTime}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+				OnDelete: func(id int64) error {
+					deleted = id
+					return nil
+				},
+				OnGetDeliveries: func(webhookID int64, offset, limit int) ([]*store.WebhookDelivery, int, error) {
+					if offset != 0 || limit != 1 {
+						t.Fatalf("bad deliveries page: offset %d limit %d", offset, limit)
+					}
+					return []*store.WebhookDelivery{
+						{
+							ID:            5,
+							WebhookID:     webhookID,
+							Event:         "topic.created",
+							Payload:       json.RawMessage(`{"event":"topic.created"}`),
+							Status:        store.WebhookDeliveryPending,
+							Attempts:      1,
+							ResponseCode:  500,
+							Error:         "unexpected response status: 500 Internal Server Error",
+							NextAttemptAt: testTime,
+							CreatedAt:     testTime,
+							UpdatedAt:     testTime,
+						},
+					}, 2, nil
+				},
+			},
+		},
+		JWTService: jwtService,
+	})
+
+	tests := []struct {
+		desc        string
+		method      string
+		url         string
+		token       string
+		wantCode    int
+		wantBody    string
+		wantDeleted int64
+	}{
+		{
+			desc:     "list, no token",
+			method:   "GET",
+			url:      "/webhooks",
+			wantCode: http.StatusUnauthorized,
+			wantBody: `{"error":{"code":"Unauthorized","message":"Authentication required"}}`,
+		},
+		{
+			desc:     "list, not admin",
+			method:   "GET",
+			url:      "/webhooks",
+			token:    token1,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
+		},
+		{
+			desc:     "list",
+			method:   "GET",
+			url:      "/webhooks",
+			token:    token2,
+			wantCode: http.StatusOK,
+			wantBody: `{"webhooks":[{"id":1,"url":"https://example.com/hook","events":["topic.created"],"createdAt":"2001-02-03T04:05:06Z"}]}`,
+		},
+		{
+			desc:     "get",
+			method:   "GET",
+			url:      "/webhooks/1",
+			token:    token2,
+			wantCode: http.StatusOK,
+			wantBody: `{"webhook":{"id":1,"url":"https://example.com/hook","events":["topic.created"],"createdAt":"2001-02-03T04:05:06Z"}}`,
+		},
+		{
+			desc:     "get, bad id",
+			method:   "GET",
+			url:      "/webhooks/BAD",
+			token:    token2,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid webhook ID"}}`,
+		},
+		{
+			desc:     "get, not found",
+			method:   "GET",
+			url:      "/webhooks/2",
+			token:    token2,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Webhook not found"}}`,
+		},
+		{
+			desc:     "delete, not admin",
+			method:   "DELETE",
+			url:      "/webhooks/1",
+			token:    token1,
+			wantCode: http.StatusForbidden,
+			wantBody: `{"error":{"code":"Forbidden","message":"Access denied"}}`,
+		},
+		{
+			desc:     "delete, not found",
+			method:   "DELETE",
+			url:      "/webhooks/2",
+			token:    token2,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Webhook not found"}}`,
+		},
+		{
+			desc:        "delete",
+			method:      "DELETE",
+			url:         "/webhooks/1",
+			token:       token2,
+			wantCode:    http.StatusOK,
+			wantBody:    `{}`,
+			wantDeleted: 1,
+		},
+		{
+			desc:     "deliveries",
+			method:   "GET",
+			url:      "/webhooks/1/deliveries?limit=1",
+			token:    token2,
+			wantCode: http.StatusOK,
+			wantBody: `{"deliveries":[{"id":5,"webhookId":1,"event":"topic.created","payload":{"event":"topic.created"},"status":"pending","attempts":1,"responseCode":500,"error":"unexpected response status: 500 Internal Server Error","nextAttemptAt":"2001-02-03T04:05:06Z","createdAt":"2001-02-03T04:05:06Z","updatedAt":"2001-02-03T04:05:06Z"}],"count":2}`,
+		},
+		{
+			desc:     "deliveries, bad limit",
+			method:   "GET",
+			url:      "/webhooks/1/deliveries?limit=0",
+			token:    token2,
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"error":{"code":"BadRequest","message":"Invalid limit"}}`,
+		},
+		{
+			desc:     "deliveries, not found",
+			method:   "GET",
+			url:      "/webhooks/2/deliveries",
+			token:    token2,
+			wantCode: http.StatusNotFound,
+			wantBody: `{"error":{"code":"NotFound","message":"Webhook not found"}}`,
+		},
+	}
+
+	for _, tc := range tests {
+		deleted = 0
+
+		req, err := http.NewRequest(tc.method, tc.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		w := httptest.NewRecorder()
+		apiHandler.ServeHTTP(w, req)
+
+		if tc.wantCode != w.Code {
+			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
+		}
+
+		if tc.wantBody != w.Body.String() {
+			t.Fatalf("test %q: want response body %q got %q", tc.desc, tc.wantBody, w.Body.String())
+		}
+
+		if tc.wantDeleted != deleted {
+			t.Fatalf("test %q: want deleted webhook %d got %d", tc.desc, tc.wantDeleted, deleted)
+		}
+	}
+}
+
+func TestWebhookEvents(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "2001-02-03T04:05:06Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token2, err := jwtService.Create(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var enqueued []string
+
+	apiHandler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		Store: &mock.Store{
+			RoleStore: getTestRoleStore(),
+			AuditStore: &mock.AuditStore{
+				OnNew: func(entry *store.AuditEntry) (int64, error) {
+					return 1, nil
+				},
+			},
+			UserStore: getReportTestUserStore(testTime),
+			CommentStore: &mock.CommentStore{
+				OnGet: func(id int64) (*store.Comment, error) {
+					if id == 1 {
+						return &store.Comment{ID: 1, TopicID: 2, AuthorID: 1, Content: "Comment", CreatedAt: testTime}, nil
+					}
+					return nil, store.ErrNotFound
+				},
+				OnDelete: func(id int64) error {
+					return nil
+				},
+			},
+		},
+		JWTService: jwtService,
+		Webhooks: webhook.NewDispatcher(&webhook.Config{
+			Logger: log.New(ioutil.Discard, "", 0),
+			WebhookStore: &mock.WebhookStore{
+				OnEnqueue: func(event string, payload []byte) error {
+					var p webhook.Payload
+					if err := json.Unmarshal(payload, &p); err != nil {
+						t.Fatalf("failed to unmarshal payload: %s", err)
+					}
+					if p.Event != event || p.ActorID != 2 || p.CreatedAt.IsZero() {
+						t.Fatalf("bad %s payload: %s", event, payload)
+					}
+					enqueued = append(enqueued, event)
+					return nil
+				},
+			},
+		}),
+	})
+
+	req, err := http.NewRequest("DELETE", "/comments/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token2)
+
+	w := httptest.NewRecorder()
+	apiHandler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status code %d got %d", http.StatusOK, w.Code)
+	}
+	if len(enqueued) != 1 || enqueued[0] != store.WebhookCommentDeleted {
+		t.Fatalf("want enqueued events [%s] got %v", store.WebhookCommentDeleted, enqueued)
+	}
+}
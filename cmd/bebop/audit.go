@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+// printAudit prints the audit log entries, oldest first.
+func printAudit() {
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	fs.Usage = help
+	since := fs.String("since", "24h", "")
+	action := fs.String("action", "", "")
+	limit := fs.Int("limit", 100, "")
+	if err := fs.Parse(flag.Args()[1:]); err != nil || fs.NArg() != 0 || *limit < 1 {
+		help()
+		os.Exit(2)
+	}
+
+	// The start time is either an RFC 3339 timestamp or a duration back from now.
+	sinceTime, err := time.Parse(time.RFC3339, *since)
+	if err != nil {
+		d, err := time.ParseDuration(*since)
+		if err != nil || d < 0 {
+			logger.Fatalf("invalid -since value: %s", *since)
+		}
+		sinceTime = time.Now().Add(-d)
+	}
+
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Fatalf("failed to load configuration: %s", err)
+	}
+
+	s, err := getStore(cfg)
+	if err != nil {
+		logger.Fatalf("failed to get data store: %s", err)
+	}
+
+	filter := &store.AuditFilter{Action: *action, Since: sinceTime}
+	entries, count, err := s.Audit().GetByFilter(filter, 0, *limit)
+	if err != nil {
+		logger.Fatalf("failed to get audit log entries: %s", err)
+	}
+
+	var actorIDs []int64
+	for _, entry := range entries {
+		if entry.ActorID != 0 {
+			actorIDs = append(actorIDs, entry.ActorID)
+		}
+	}
+	actors, err := s.Users().GetMany(actorIDs)
+	if err != nil {
+		logger.Fatalf("failed to get audit log actors: %s", err)
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		actor := "cli"
+		if entry.ActorID != 0 {
+			actor = "#" + strconv.FormatInt(entry.ActorID, 10)
+			if user, ok := actors[entry.ActorID]; ok {
+				actor = user.Name
+			}
+		}
+		logger.Printf("%s %s %s %s:%d %s -> %s %s", entry.CreatedAt.UTC().Format("2006-01-02 15:04:05"), actor,
+			entry.Action, entry.TargetType, entry.TargetID, entry.Before, entry.After, entry.IP)
+	}
+
+	if count > len(entries) {
+		logger.Printf("showing the latest %d of %d entries", len(entries), count)
+	}
+}
@@ -1,15 +1,22 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/disintegration/bebop/config"
 	"github.com/disintegration/bebop/filestorage"
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/mailer"
 	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/memory"
 	"github.com/disintegration/bebop/store/mysql"
 	"github.com/disintegration/bebop/store/postgresql"
+	"github.com/disintegration/bebop/store/sqlite"
 )
 
 const configFile = "bebop.conf"
@@ -43,9 +50,61 @@ func getConfig() (cfg *config.Config, err error) {
 	return cfg, err
 }
 
-// genKey generates a random 32-byte hex-encoded key.
+// genKey generates a random 32-byte hex-encoded key or,
+// given an algorithm, a JWT key config block with a new key pair.
 func genKey() {
-	logger.Printf("key: %s", config.GenKeyHex(32))
+	alg := flag.Arg(1)
+	if alg == "" {
+		logger.Printf("key: %s", config.GenKeyHex(32))
+		return
+	}
+
+	key, err := config.GenJWTKey(alg)
+	if err != nil {
+		logger.Fatalf("failed to generate key: %s", err)
+	}
+	fmt.Print(key)
+}
+
+// getJWTService creates a JWT service from the configured key set.
+// The legacy secret, if any, is used as the signing key when no key set is defined
+// and as a verification-only key without ID otherwise.
+func getJWTService(cfg *config.Config, ttl time.Duration) (jwt.Service, error) {
+	if len(cfg.JWT.Keys) == 0 {
+		return jwt.NewService(cfg.JWT.Secret, ttl)
+	}
+
+	var signingKey *jwt.Key
+	var verificationKeys []*jwt.Key
+
+	if cfg.JWT.Secret != "" {
+		key, err := jwt.NewKey("", jwt.AlgHS256, cfg.JWT.Secret, "", "")
+		if err != nil {
+			return nil, err
+		}
+		verificationKeys = append(verificationKeys, key)
+	}
+
+	for _, k := range cfg.JWT.Keys {
+		if k.ID == "" {
+			return nil, errors.New("jwt key without id")
+		}
+		key, err := jwt.NewKey(k.ID, k.Algorithm, k.Secret, k.PrivateKey, k.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		if k.ID == cfg.JWT.SigningKey {
+			signingKey = key
+		} else {
+			verificationKeys = append(verificationKeys, key)
+		}
+	}
+
+	if signingKey == nil {
+		return nil, fmt.Errorf("jwt signing key not found: %q", cfg.JWT.SigningKey)
+	}
+
+	return jwt.NewKeySetService(signingKey, verificationKeys, ttl)
 }
 
 func getFileStorage(cfg *config.Config) (filestorage.FileStorage, error) {
@@ -71,7 +130,32 @@ func getFileStorage(cfg *config.Config) (filestorage.FileStorage, error) {
 	return nil, fmt.Errorf("unknown file storage type: %s", cfg.FileStorage.Type)
 }
 
+func getMailer(cfg *config.Config) (mailer.Mailer, error) {
+	switch cfg.Mail.Type {
+	case "smtp":
+		return mailer.NewSMTP(
+			cfg.Mail.SMTP.Address,
+			cfg.Mail.SMTP.Username,
+			cfg.Mail.SMTP.Password,
+			cfg.Mail.From,
+		)
+	case "file":
+		return mailer.NewFile(
+			cfg.Mail.File.Dir,
+			cfg.Mail.From,
+		)
+	case "log":
+		return mailer.NewLog(logger, cfg.Mail.From), nil
+	}
+	return nil, fmt.Errorf("unknown mail type: %s", cfg.Mail.Type)
+}
+
 func getStore(cfg *config.Config) (store.Store, error) {
+	migrate, err := store.ParseMigrateMode(cfg.Store.Migrate)
+	if err != nil {
+		return nil, err
+	}
+
 	switch cfg.Store.Type {
 	case "mysql":
 		return mysql.Connect(
@@ -79,6 +163,7 @@ func getStore(cfg *config.Config) (store.Store, error) {
 			cfg.Store.MySQL.Username,
 			cfg.Store.MySQL.Password,
 			cfg.Store.MySQL.Database,
+			migrate,
 		)
 	case "postgresql":
 		return postgresql.Connect(
@@ -88,7 +173,15 @@ func getStore(cfg *config.Config) (store.Store, error) {
 			cfg.Store.PostgreSQL.Database,
 			cfg.Store.PostgreSQL.SSLMode,
 			cfg.Store.PostgreSQL.SSLRootCert,
+			migrate,
+		)
+	case "sqlite":
+		return sqlite.Connect(
+			cfg.Store.SQLite.Path,
+			migrate,
 		)
+	case "memory":
+		return memory.New(), nil
 	}
 	return nil, fmt.Errorf("unknown store type: %s", cfg.Store.Type)
 }
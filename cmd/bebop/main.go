@@ -25,6 +25,10 @@ func main() {
 		"admins":       printAdmins,
 		"add-admin":    addAdmin,
 		"remove-admin": removeAdmin,
+		"role":         role,
+		"migrate":      migrate,
+		"audit":        printAudit,
+		"webhook":      manageWebhooks,
 		"help":         help,
 	}
 
@@ -41,9 +45,32 @@ func help() {
 	bebop start                      - start the server
 	bebop init                       - create an initial configuration file
 	bebop gen-key                    - generate a random 32-byte hex-encoded key
-	bebop admins                     - show the admin list
-	bebop add-admin <username>       - add a user to the admin list
-	bebop remove-admin <username>    - remove a user from the admin list
+	bebop gen-key <algorithm>        - generate a jwt key config block (HS256, RS256 or EdDSA)
+	bebop role list <role>           - show the users that have the role (e.g. admin or moderator)
+	bebop role permissions <role>    - show the permissions the role grants
+	bebop role add-permission <role> <permission>
+	                                 - grant the permission to the role, e.g. topic.pin
+	bebop role remove-permission <role> <permission>
+	                                 - revoke the permission from the role
+	bebop role grant <username> <role>
+	                                 - grant the role to a user
+	bebop role revoke <username> <role>
+	                                 - revoke the role from a user
+	bebop admins                     - same as "bebop role list admin"
+	bebop add-admin <username>       - same as "bebop role grant <username> admin"
+	bebop remove-admin <username>    - same as "bebop role revoke <username> admin"
+	bebop migrate status             - show the data store schema migrations
+	bebop migrate up                 - apply all the pending migrations
+	bebop migrate down [<n>]         - roll back the last n migrations (default 1)
+	bebop audit [-since <t>] [-action <a>] [-limit <n>]
+	                                 - show the audit log since an RFC 3339 time or a duration ago
+	                                   (default 24h), optionally filtered by action
+	bebop webhook list               - show the webhooks
+	bebop webhook add <url> <events> [<secret>]
+	                                 - add a webhook for the comma-separated events, e.g.
+	                                   topic.created,comment.created (a secret is generated if omitted)
+	bebop webhook delete <id>        - delete a webhook
+	bebop webhook deliveries <id>    - show the latest deliveries of a webhook
 	bebop help                       - show this message
 Use -e flag to read configuration from environment variables instead of a file. E.g.:
 	bebop -e start
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strconv"
+
+	"github.com/disintegration/bebop/store"
+)
+
+// migrate shows or changes the state of the data store schema migrations.
+func migrate() {
+	action := flag.Arg(1)
+	if action != "status" && action != "up" && action != "down" {
+		help()
+		os.Exit(2)
+	}
+
+	steps := 1
+	if action == "down" && flag.Arg(2) != "" {
+		n, err := strconv.Atoi(flag.Arg(2))
+		if err != nil || n < 1 {
+			help()
+			os.Exit(2)
+		}
+		steps = n
+	}
+
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Fatalf("failed to load configuration: %s", err)
+	}
+
+	// Connect without touching the schema regardless of the configured mode.
+	cfg.Store.Migrate = "none"
+
+	s, err := getStore(cfg)
+	if err != nil {
+		logger.Fatalf("failed to get data store: %s", err)
+	}
+
+	m, ok := s.(store.Migrator)
+	if !ok {
+		logger.Fatalf("data store %q does not support migrations", cfg.Store.Type)
+	}
+
+	switch action {
+	case "up":
+		err = m.Migrate()
+		if err != nil {
+			logger.Fatalf("failed to apply migrations: %s", err)
+		}
+	case "down":
+		err = m.Rollback(steps)
+		if err != nil {
+			logger.Fatalf("failed to roll back migrations: %s", err)
+		}
+	}
+
+	status, err := m.MigrationStatus()
+	if err != nil {
+		logger.Fatalf("failed to get migration status: %s", err)
+	}
+
+	for _, st := range status {
+		state := "pending"
+		if st.Applied {
+			state = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		if st.Modified {
+			state += " (modified)"
+		}
+		if st.Unknown {
+			state += " (unknown)"
+		}
+		logger.Printf("%d: %s - %s", st.Version, st.Name, state)
+	}
+}
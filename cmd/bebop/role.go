@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/disintegration/bebop/store"
+)
+
+// role shows, grants or revokes user roles and role permissions.
+func role() {
+	action := flag.Arg(1)
+	switch action {
+	case "list":
+		printRoleUsers(flag.Arg(2))
+	case "permissions":
+		printRolePermissions(flag.Arg(2))
+	case "grant", "revoke":
+		setRole(flag.Arg(2), flag.Arg(3), action == "grant")
+	case "add-permission", "remove-permission":
+		setRolePermission(flag.Arg(2), flag.Arg(3), action == "add-permission")
+	default:
+		help()
+		os.Exit(2)
+	}
+}
+
+// printAdmins prints all the administrator users.
+func printAdmins() {
+	printRoleUsers(store.RoleAdmin)
+}
+
+// addAdmin grants the admin role to a user.
+func addAdmin() {
+	setRole(flag.Arg(1), store.RoleAdmin, true)
+}
+
+// removeAdmin revokes the admin role from a user.
+func removeAdmin() {
+	setRole(flag.Arg(1), store.RoleAdmin, false)
+}
+
+// printRolePermissions prints the permissions the role grants.
+func printRolePermissions(roleName string) {
+	if !store.ValidRole(roleName) {
+		help()
+		os.Exit(2)
+	}
+
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Fatalf("failed to load configuration: %s", err)
+	}
+
+	s, err := getStore(cfg)
+	if err != nil {
+		logger.Fatalf("failed to get data store: %s", err)
+	}
+
+	permissions, err := s.Roles().GetPermissions([]string{roleName})
+	if err != nil {
+		logger.Fatalf("failed to get the %s role permissions: %s", roleName, err)
+	}
+
+	for _, p := range permissions {
+		logger.Printf("%s", p)
+	}
+}
+
+// printRoleUsers prints all the users that have the role.
+func printRoleUsers(roleName string) {
+	if !store.ValidRole(roleName) {
+		help()
+		os.Exit(2)
+	}
+
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Fatalf("failed to load configuration: %s", err)
+	}
+
+	s, err := getStore(cfg)
+	if err != nil {
+		logger.Fatalf("failed to get data store: %s", err)
+	}
+
+	ids, err := s.Roles().GetUsers(roleName)
+	if err != nil {
+		logger.Fatalf("failed to get the list of %s users: %s", roleName, err)
+	}
+
+	// GetMany fails if any of the users is missing, so the users are loaded one by one.
+	// The ID is printed for the missing users and the users that have not set a name yet.
+	for i, id := range ids {
+		user, err := s.Users().Get(id)
+		if err != nil && err != store.ErrNotFound {
+			logger.Fatalf("failed to get the %s user %d: %s", roleName, id, err)
+		}
+		if user == nil || user.Name == "" {
+			logger.Printf("%d: %d", i+1, id)
+			continue
+		}
+		logger.Printf("%d: %s", i+1, user.Name)
+	}
+}
+
+func setRole(username, roleName string, grant bool) {
+	if username == "" || !store.ValidRole(roleName) {
+		help()
+		os.Exit(2)
+	}
+
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Fatalf("failed to load configuration: %s", err)
+	}
+
+	s, err := getStore(cfg)
+	if err != nil {
+		logger.Fatalf("failed to get data store: %s", err)
+	}
+
+	user, err := s.Users().GetByName(username)
+	if err != nil {
+		if err == store.ErrNotFound {
+			logger.Fatalf("user not found: %s", username)
+		} else {
+			logger.Fatalf("user search by username failed: %s", err)
+		}
+	}
+
+	before, err := s.Roles().GetByUser(user.ID)
+	if err != nil {
+		logger.Fatalf("failed to get user roles: %s", err)
+	}
+
+	if grant {
+		err = s.Roles().Grant(user.ID, roleName)
+	} else {
+		err = s.Roles().Revoke(user.ID, roleName)
+	}
+	switch {
+	case err == store.ErrConflict:
+		logger.Fatalf("user %s already has the %s role", username, roleName)
+	case err == store.ErrNotFound:
+		logger.Fatalf("user %s does not have the %s role", username, roleName)
+	case err != nil:
+		logger.Fatalf("failed to change user roles: %s", err)
+	}
+
+	after, err := s.Roles().GetByUser(user.ID)
+	if err != nil {
+		logger.Fatalf("failed to get user roles: %s", err)
+	}
+
+	beforeJSON, _ := json.Marshal(map[string][]string{"roles": before})
+	afterJSON, _ := json.Marshal(map[string][]string{"roles": after})
+	_, err = s.Audit().New(&store.AuditEntry{
+		Action:     store.AuditUserRole,
+		TargetType: store.AuditTargetUser,
+		TargetID:   user.ID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+	})
+	if err != nil {
+		logger.Printf("failed to write audit log entry: %s", err)
+	}
+
+	if grant {
+		logger.Printf("user %s is granted the %s role", username, roleName)
+	} else {
+		logger.Printf("the %s role is revoked from user %s", roleName, username)
+	}
+}
+
+// setRolePermission grants the permission to the role or revokes it.
+func setRolePermission(roleName, permission string, grant bool) {
+	if !store.ValidRole(roleName) || !store.ValidPermission(permission) {
+		help()
+		os.Exit(2)
+	}
+
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Fatalf("failed to load configuration: %s", err)
+	}
+
+	s, err := getStore(cfg)
+	if err != nil {
+		logger.Fatalf("failed to get data store: %s", err)
+	}
+
+	before, err := s.Roles().GetPermissions([]string{roleName})
+	if err != nil {
+		logger.Fatalf("failed to get role permissions: %s", err)
+	}
+
+	if grant {
+		err = s.Roles().GrantPermission(roleName, permission)
+	} else {
+		err = s.Roles().RevokePermission(roleName, permission)
+	}
+	switch {
+	case err == store.ErrConflict:
+		logger.Fatalf("the %s role already grants %s", roleName, permission)
+	case err == store.ErrNotFound:
+		logger.Fatalf("the %s role does not grant %s", roleName, permission)
+	case err != nil:
+		logger.Fatalf("failed to change role permissions: %s", err)
+	}
+
+	after, err := s.Roles().GetPermissions([]string{roleName})
+	if err != nil {
+		logger.Fatalf("failed to get role permissions: %s", err)
+	}
+
+	// Roles have no IDs, so the role name is kept in the before and after states.
+	beforeJSON, _ := json.Marshal(map[string]interface{}{"role": roleName, "permissions": before})
+	afterJSON, _ := json.Marshal(map[string]interface{}{"role": roleName, "permissions": after})
+	_, err = s.Audit().New(&store.AuditEntry{
+		Action:     store.AuditRolePermission,
+		TargetType: store.AuditTargetRole,
+		Before:     beforeJSON,
+		After:      afterJSON,
+	})
+	if err != nil {
+		logger.Printf("failed to write audit log entry: %s", err)
+	}
+
+	if grant {
+		logger.Printf("the %s role is granted %s", roleName, permission)
+	} else {
+		logger.Printf("%s is revoked from the %s role", permission, roleName)
+	}
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
+	"os/signal"
 	"sort"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
@@ -13,12 +18,27 @@ import (
 	"github.com/disintegration/bebop/api"
 	"github.com/disintegration/bebop/avatar"
 	"github.com/disintegration/bebop/config"
+	"github.com/disintegration/bebop/digest"
+	"github.com/disintegration/bebop/events"
+	"github.com/disintegration/bebop/feed"
 	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/localauth"
+	"github.com/disintegration/bebop/mailer"
 	"github.com/disintegration/bebop/oauth"
+	"github.com/disintegration/bebop/session"
 	"github.com/disintegration/bebop/static"
+	bebopstore "github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/webhook"
 )
 
+// shutdownTimeout is the time the server waits for the active requests to complete on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+// webhookInterval is the interval the queued webhook events are delivered at.
+const webhookInterval = 10 * time.Second
+
 // startServer configures and starts the bebop web server.
+// The server shuts down gracefully on SIGINT or SIGTERM.
 func startServer() {
 	cfg, err := getConfig()
 	if err != nil {
@@ -30,6 +50,12 @@ func startServer() {
 		logger.Fatalf("failed to parse base url: %s", err)
 	}
 
+	for _, emoji := range cfg.Reactions {
+		if !bebopstore.ValidReactionEmoji(emoji) {
+			logger.Fatalf("invalid reaction emoji: %q", emoji)
+		}
+	}
+
 	fileStorage, err := getFileStorage(cfg)
 	if err != nil {
 		logger.Fatalf("failed to init file storage: %s", err)
@@ -37,29 +63,103 @@ func startServer() {
 
 	store, err := getStore(cfg)
 	if err != nil {
+		if err == bebopstore.ErrPendingMigrations {
+			logger.Fatalf("failed to init data store: %s: run \"bebop migrate up\" to apply them", err)
+		}
 		logger.Fatalf("failed to init data store: %s", err)
 	}
 
-	jwtService, err := jwt.NewService(cfg.JWT.Secret)
+	accessTokenTTL, err := time.ParseDuration(cfg.JWT.AccessTokenTTL)
+	if err != nil {
+		logger.Fatalf("failed to parse access token ttl: %s", err)
+	}
+
+	refreshTokenTTL, err := time.ParseDuration(cfg.JWT.RefreshTokenTTL)
+	if err != nil {
+		logger.Fatalf("failed to parse refresh token ttl: %s", err)
+	}
+
+	jwtService, err := getJWTService(cfg, accessTokenTTL)
 	if err != nil {
 		logger.Fatalf("failed to create jwt service: %s", err)
 	}
 
+	sessionService := session.NewService(store.Sessions(), store.Users(), store.AuthTokens(), jwtService, refreshTokenTTL)
+
 	avatarService := avatar.NewService(store.Users(), fileStorage, logger)
 
-	apiHandler := api.New(&api.Config{
-		Logger:        logger,
-		Store:         store,
-		JWTService:    jwtService,
-		AvatarService: avatarService,
+	var mailService mailer.Mailer
+	if cfg.LocalAuth.Enabled || cfg.Digest.Enabled {
+		mailService, err = getMailer(cfg)
+		if err != nil {
+			logger.Fatalf("failed to init mailer: %s", err)
+		}
+	}
+
+	var localAuthService localauth.Service
+	if cfg.LocalAuth.Enabled {
+		localAuthService = localauth.NewService(&localauth.Config{
+			LocalAccountStore: store.LocalAccounts(),
+			AuthTokenStore:    store.AuthTokens(),
+			UserStore:         store.Users(),
+			SessionService:    sessionService,
+			Mailer:            mailService,
+			BaseURL:           baseURL.String(),
+			Title:             cfg.Title,
+			MagicLinks:        cfg.LocalAuth.MagicLinks,
+		})
+	}
+
+	var digestSender *digest.Sender
+	var digestInterval time.Duration
+	if cfg.Digest.Enabled {
+		digestInterval, err = time.ParseDuration(cfg.Digest.Interval)
+		if err != nil || digestInterval <= 0 {
+			logger.Fatalf("failed to parse digest interval: %q", cfg.Digest.Interval)
+		}
+
+		digestSender = digest.NewSender(&digest.Config{
+			Logger:            logger,
+			DigestStore:       store.Digests(),
+			UserStore:         store.Users(),
+			LocalAccountStore: store.LocalAccounts(),
+			Mailer:            mailService,
+			JWTService:        jwtService,
+			BaseURL:           baseURL.String(),
+			Title:             cfg.Title,
+		})
+	}
+
+	eventHub := events.NewHub()
+
+	webhookDispatcher := webhook.NewDispatcher(&webhook.Config{
+		Logger:       logger,
+		WebhookStore: store.Webhooks(),
 	})
 
 	oauthHandler := oauth.New(&oauth.Config{
-		Logger:     logger,
-		UserStore:  store.Users(),
-		JWTService: jwtService,
-		MountURL:   baseURL.String() + "/oauth",
-		CookiePath: baseURL.Path + "/",
+		Logger:         logger,
+		UserStore:      store.Users(),
+		IdentityStore:  store.Identities(),
+		SessionService: sessionService,
+		AvatarService:  avatarService,
+		ImportAvatars:  cfg.OAuth.ImportAvatars,
+		MountURL:       baseURL.String() + "/oauth",
+		CookiePath:     baseURL.Path + "/",
+		AppURL:         baseURL.String() + "/",
+	})
+
+	apiHandler := api.New(&api.Config{
+		Logger:             logger,
+		Store:              store,
+		JWTService:         jwtService,
+		AvatarService:      avatarService,
+		SessionService:     sessionService,
+		LocalAuthService:   localAuthService,
+		Reactions:          cfg.Reactions,
+		Events:             eventHub,
+		Webhooks:           webhookDispatcher,
+		SetOAuthLinkCookie: oauthHandler.SetLinkCookie,
 	})
 
 	oauthProviders, err := initOAuthProviders(cfg, oauthHandler)
@@ -67,11 +167,25 @@ func startServer() {
 		logger.Fatalf("failed to init oauth providers: %s", err)
 	}
 
-	configHandler, err := newConfigHandler(cfg.Title, oauthProviders)
+	configHandler, err := newConfigHandler(cfg, oauthProviders)
 	if err != nil {
 		logger.Fatalf("failed to create config handler: %s", err)
 	}
 
+	feedHandler := feed.New(&feed.Config{
+		Logger:       logger,
+		TopicStore:   store.Topics(),
+		CommentStore: store.Comments(),
+		UserStore:    store.Users(),
+		BaseURL:      baseURL.String(),
+		Title:        cfg.Title,
+	})
+
+	jwksHandler, err := newJWKSHandler(jwtService)
+	if err != nil {
+		logger.Fatalf("failed to create jwks handler: %s", err)
+	}
+
 	router := chi.NewRouter()
 
 	router.Use(middleware.RequestLogger(&middleware.DefaultLogFormatter{Logger: logger}))
@@ -87,52 +201,145 @@ func startServer() {
 	}
 
 	router.Get("/config.json", configHandler)
+	router.Mount("/feeds", feedHandler)
+	router.Get("/.well-known/jwks.json", jwksHandler)
 	router.Get("/", static.EmbeddedFile("/frontend/app.html").ServeHTTP)
 
+	server := &http.Server{
+		Addr:    cfg.Address,
+		Handler: http.StripPrefix(baseURL.Path, router),
+	}
+
+	stopDigest := make(chan struct{})
+	digestDone := make(chan struct{})
+	go func() {
+		defer close(digestDone)
+		if digestSender != nil {
+			digestSender.Run(digestInterval, stopDigest)
+		}
+	}()
+
+	stopWebhooks := make(chan struct{})
+	webhooksDone := make(chan struct{})
+	go func() {
+		defer close(webhooksDone)
+		webhookDispatcher.Run(webhookInterval, stopWebhooks)
+	}()
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+
+		logger.Printf("shutting down the server")
+
+		// Close the event streams first, the server waits for them to end otherwise.
+		eventHub.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Printf("server shutdown failed: %v", err)
+		}
+	}()
+
 	logger.Printf("starting the server: %s", cfg.Address)
 
-	if err := http.ListenAndServe(cfg.Address, http.StripPrefix(baseURL.Path, router)); err != nil {
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
 		logger.Fatalf("listen and serve failed: %v", err)
 	}
+
+	<-shutdownDone
+
+	close(stopDigest)
+	<-digestDone
+
+	close(stopWebhooks)
+	<-webhooksDone
 }
 
 func initOAuthProviders(cfg *config.Config, h *oauth.Handler) ([]string, error) {
 	var providers []string
 
-	if cfg.OAuth.Google.ClientID != "" && cfg.OAuth.Google.Secret != "" {
-		err := h.AddProvider("google", cfg.OAuth.Google.ClientID, cfg.OAuth.Google.Secret)
-		if err != nil {
-			return nil, fmt.Errorf("failed to init google oauth provider: %s", err)
-		}
-		providers = append(providers, "google")
+	builtin := []struct {
+		name string
+		cfg  oauth.ProviderConfig
+	}{
+		{"google", oauth.ProviderConfig{
+			ClientID: cfg.OAuth.Google.ClientID,
+			Secret:   cfg.OAuth.Google.Secret,
+		}},
+		{"facebook", oauth.ProviderConfig{
+			ClientID: cfg.OAuth.Facebook.ClientID,
+			Secret:   cfg.OAuth.Facebook.Secret,
+		}},
+		{"github", oauth.ProviderConfig{
+			ClientID: cfg.OAuth.Github.ClientID,
+			Secret:   cfg.OAuth.Github.Secret,
+		}},
+		{"gitlab", oauth.ProviderConfig{
+			ClientID: cfg.OAuth.Gitlab.ClientID,
+			Secret:   cfg.OAuth.Gitlab.Secret,
+			BaseURL:  cfg.OAuth.Gitlab.BaseURL,
+		}},
+		{"discord", oauth.ProviderConfig{
+			ClientID: cfg.OAuth.Discord.ClientID,
+			Secret:   cfg.OAuth.Discord.Secret,
+		}},
+		{"microsoft", oauth.ProviderConfig{
+			ClientID: cfg.OAuth.Microsoft.ClientID,
+			Secret:   cfg.OAuth.Microsoft.Secret,
+			Tenant:   cfg.OAuth.Microsoft.Tenant,
+		}},
+		{"twitch", oauth.ProviderConfig{
+			ClientID: cfg.OAuth.Twitch.ClientID,
+			Secret:   cfg.OAuth.Twitch.Secret,
+		}},
 	}
 
-	if cfg.OAuth.Facebook.ClientID != "" && cfg.OAuth.Facebook.Secret != "" {
-		err := h.AddProvider("facebook", cfg.OAuth.Facebook.ClientID, cfg.OAuth.Facebook.Secret)
+	for _, p := range builtin {
+		if p.cfg.ClientID == "" || p.cfg.Secret == "" {
+			continue
+		}
+		err := h.AddProvider(p.name, &p.cfg)
 		if err != nil {
-			return nil, fmt.Errorf("failed to init facebook oauth provider: %s", err)
+			return nil, fmt.Errorf("failed to init %s oauth provider: %s", p.name, err)
 		}
-		providers = append(providers, "facebook")
+		providers = append(providers, p.name)
 	}
 
-	if cfg.OAuth.Github.ClientID != "" && cfg.OAuth.Github.Secret != "" {
-		err := h.AddProvider("github", cfg.OAuth.Github.ClientID, cfg.OAuth.Github.Secret)
+	for _, p := range cfg.OAuth.OIDC {
+		err := h.AddOIDCProvider(p.Name, &oauth.OIDCConfig{
+			Issuer:    p.Issuer,
+			ClientID:  p.ClientID,
+			Secret:    p.Secret,
+			Scopes:    p.Scopes,
+			IDClaim:   p.IDClaim,
+			NameClaim: p.NameClaim,
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to init github oauth provider: %s", err)
+			return nil, fmt.Errorf("failed to init %s oidc provider: %s", p.Name, err)
 		}
-		providers = append(providers, "github")
+		providers = append(providers, p.Name)
 	}
 
 	return providers, nil
 }
 
-func newConfigHandler(title string, oauthProviders []string) (http.HandlerFunc, error) {
+func newConfigHandler(cfg *config.Config, oauthProviders []string) (http.HandlerFunc, error) {
 	appConfig := struct {
-		Title string   `json:"title"`
-		OAuth []string `json:"oauth"`
+		Title      string   `json:"title"`
+		OAuth      []string `json:"oauth"`
+		LocalAuth  bool     `json:"localAuth"`
+		MagicLinks bool     `json:"magicLinks"`
 	}{
-		Title: title,
-		OAuth: oauthProviders,
+		Title:      cfg.Title,
+		OAuth:      oauthProviders,
+		LocalAuth:  cfg.LocalAuth.Enabled,
+		MagicLinks: cfg.LocalAuth.Enabled && cfg.LocalAuth.MagicLinks,
 	}
 
 	sort.Strings(appConfig.OAuth)
@@ -150,3 +357,18 @@ func newConfigHandler(title string, oauthProviders []string) (http.HandlerFunc,
 
 	return handler, nil
 }
+
+func newJWKSHandler(jwtService jwt.Service) (http.HandlerFunc, error) {
+	jsonData, err := json.Marshal(jwtService.JWKS())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal jwks json: %s", err)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonData)
+	})
+
+	return handler, nil
+}
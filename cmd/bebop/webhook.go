@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/bebop/config"
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/webhook"
+)
+
+// manageWebhooks shows, adds or deletes webhooks and shows their deliveries.
+func manageWebhooks() {
+	switch flag.Arg(1) {
+	case "list":
+		printWebhooks()
+	case "add":
+		addWebhook(flag.Arg(2), flag.Arg(3), flag.Arg(4))
+	case "delete":
+		deleteWebhook(flag.Arg(2))
+	case "deliveries":
+		printWebhookDeliveries(flag.Arg(2))
+	default:
+		help()
+		os.Exit(2)
+	}
+}
+
+// printWebhooks prints all the webhooks.
+func printWebhooks() {
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Fatalf("failed to load configuration: %s", err)
+	}
+
+	s, err := getStore(cfg)
+	if err != nil {
+		logger.Fatalf("failed to get data store: %s", err)
+	}
+
+	webhooks, err := s.Webhooks().GetAll()
+	if err != nil {
+		logger.Fatalf("failed to get the list of webhooks: %s", err)
+	}
+
+	for _, w := range webhooks {
+		logger.Printf("%d: %s %s", w.ID, w.URL, strings.Join(w.Events, ","))
+	}
+}
+
+func addWebhook(url, eventList, secret string) {
+	if url == "" || eventList == "" {
+		help()
+		os.Exit(2)
+	}
+
+	if !webhook.ValidURL(url) {
+		logger.Fatalf("invalid webhook url: %s", url)
+	}
+
+	events := strings.Split(eventList, ",")
+	for _, event := range events {
+		if !store.ValidWebhookEvent(event) {
+			logger.Fatalf("invalid webhook event: %q, valid events: %s", event, strings.Join(store.WebhookEvents, ","))
+		}
+	}
+
+	generated := secret == ""
+	if generated {
+		secret = config.GenKeyHex(32)
+	}
+
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Fatalf("failed to load configuration: %s", err)
+	}
+
+	s, err := getStore(cfg)
+	if err != nil {
+		logger.Fatalf("failed to get data store: %s", err)
+	}
+
+	id, err := s.Webhooks().New(url, secret, events)
+	if err != nil {
+		logger.Fatalf("failed to create webhook: %s", err)
+	}
+
+	w, err := s.Webhooks().Get(id)
+	if err != nil {
+		logger.Fatalf("failed to get webhook: %s", err)
+	}
+
+	afterJSON, _ := json.Marshal(w)
+	_, err = s.Audit().New(&store.AuditEntry{
+		Action:     store.AuditWebhookCreate,
+		TargetType: store.AuditTargetWebhook,
+		TargetID:   id,
+		Before:     json.RawMessage("null"),
+		After:      afterJSON,
+	})
+	if err != nil {
+		logger.Printf("failed to write audit log entry: %s", err)
+	}
+
+	logger.Printf("webhook %d is added", id)
+	if generated {
+		logger.Printf("webhook secret: %s", secret)
+	}
+}
+
+func deleteWebhook(idParam string) {
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		help()
+		os.Exit(2)
+	}
+
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Fatalf("failed to load configuration: %s", err)
+	}
+
+	s, err := getStore(cfg)
+	if err != nil {
+		logger.Fatalf("failed to get data store: %s", err)
+	}
+
+	w, err := s.Webhooks().Get(id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			logger.Fatalf("webhook not found: %d", id)
+		}
+		logger.Fatalf("failed to get webhook: %s", err)
+	}
+
+	err = s.Webhooks().Delete(id)
+	if err != nil {
+		logger.Fatalf("failed to delete webhook: %s", err)
+	}
+
+	beforeJSON, _ := json.Marshal(w)
+	_, err = s.Audit().New(&store.AuditEntry{
+		Action:     store.AuditWebhookDelete,
+		TargetType: store.AuditTargetWebhook,
+		TargetID:   id,
+		Before:     beforeJSON,
+		After:      json.RawMessage("null"),
+	})
+	if err != nil {
+		logger.Printf("failed to write audit log entry: %s", err)
+	}
+
+	logger.Printf("webhook %d is deleted", id)
+}
+
+// printWebhookDeliveries prints the latest deliveries of a webhook, oldest first.
+func printWebhookDeliveries(idParam string) {
+	const limit = 20
+
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		help()
+		os.Exit(2)
+	}
+
+	cfg, err := getConfig()
+	if err != nil {
+		logger.Fatalf("failed to load configuration: %s", err)
+	}
+
+	s, err := getStore(cfg)
+	if err != nil {
+		logger.Fatalf("failed to get data store: %s", err)
+	}
+
+	deliveries, count, err := s.Webhooks().GetDeliveries(id, 0, limit)
+	if err != nil {
+		logger.Fatalf("failed to get webhook deliveries: %s", err)
+	}
+
+	for i := len(deliveries) - 1; i >= 0; i-- {
+		d := deliveries[i]
+		logger.Printf("%d: %s %s %s attempts=%d code=%d next=%s %s", d.ID, d.CreatedAt.UTC().Format("2006-01-02 15:04:05"),
+			d.Event, d.Status, d.Attempts, d.ResponseCode, d.NextAttemptAt.UTC().Format("2006-01-02 15:04:05"), d.Error)
+	}
+
+	if count > len(deliveries) {
+		logger.Printf("showing the latest %d of %d deliveries", len(deliveries), count)
+	}
+}
@@ -0,0 +1,199 @@
+// Package digest provides a background job that emails the new comments
+// in the watched topics to the users, as soon as possible or once a day.
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/mailer"
+	"github.com/disintegration/bebop/store"
+)
+
+// UnsubscribePurpose is the purpose of the signed tokens in the unsubscribe links.
+const UnsubscribePurpose = "unsubscribe"
+
+const (
+	// unsubscribeTTL is the lifetime of the unsubscribe links.
+	unsubscribeTTL = 90 * 24 * time.Hour
+	// dailyInterval is the minimum interval between the daily digests.
+	dailyInterval = 24 * time.Hour
+	// maxComments is the maximum number of comments listed in one digest email.
+	maxComments = 50
+	// maxExcerptLen is the maximum length of a comment excerpt in runes.
+	maxExcerptLen = 300
+)
+
+// Config is a digest sender configuration.
+type Config struct {
+	Logger            *log.Logger
+	DigestStore       store.DigestStore
+	UserStore         store.UserStore
+	LocalAccountStore store.LocalAccountStore
+	Mailer            mailer.Mailer
+	JWTService        jwt.Service
+	// BaseURL is the web app URL the links in the emails point to.
+	BaseURL string
+	// Title is the forum title used in the emails.
+	Title string
+}
+
+// Sender sends the due email digests.
+type Sender struct {
+	*Config
+}
+
+// NewSender creates a new digest sender.
+func NewSender(config *Config) *Sender {
+	return &Sender{Config: config}
+}
+
+// Run sends the due digests every interval until the stop channel is closed.
+func (s *Sender) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.SendDue()
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// SendDue sends the digests of all the users whose digest is due.
+// The users without a verified email or blocked users get nothing,
+// but their queued comments are discarded all the same.
+func (s *Sender) SendDue() {
+	userIDs, err := s.DigestStore.GetDueUsers(time.Now().Add(-dailyInterval))
+	if err != nil {
+		s.Logger.Printf("digest: get due users: %s", err)
+		return
+	}
+
+	for _, userID := range userIDs {
+		err := s.send(userID)
+		if err != nil {
+			s.Logger.Printf("digest: send to user %d: %s", userID, err)
+		}
+	}
+}
+
+// send emails the queued comments to the user and removes them from the queue.
+func (s *Sender) send(userID int64) error {
+	comments, err := s.DigestStore.GetQueued(userID)
+	if err != nil {
+		return err
+	}
+	if len(comments) == 0 {
+		return nil
+	}
+	lastCommentID := comments[len(comments)-1].CommentID
+
+	email, err := s.email(userID)
+	if err != nil {
+		return err
+	}
+
+	if email != "" {
+		token, err := s.JWTService.CreatePurpose(userID, UnsubscribePurpose, unsubscribeTTL)
+		if err != nil {
+			return err
+		}
+
+		subject := "1 new comment"
+		if len(comments) > 1 {
+			subject = fmt.Sprintf("%d new comments", len(comments))
+		}
+
+		err = s.Mailer.Send(&mailer.Message{
+			To:      email,
+			Subject: s.Title + ": " + subject,
+			Body:    s.body(comments, token),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.DigestStore.MarkSent(userID, lastCommentID)
+}
+
+// email returns the verified email of the user or an empty string.
+func (s *Sender) email(userID int64) (string, error) {
+	user, err := s.UserStore.Get(userID)
+	if err == store.ErrNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if user.Blocked {
+		return "", nil
+	}
+
+	account, err := s.LocalAccountStore.Get(userID)
+	if err == store.ErrNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !account.Verified {
+		return "", nil
+	}
+	return account.Email, nil
+}
+
+// body formats the digest email text grouping the comments by topic.
+func (s *Sender) body(comments []*store.DigestComment, token string) string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "New comments in the topics you watch on %s:\n", s.Title)
+
+	shown := comments
+	if len(shown) > maxComments {
+		shown = shown[:maxComments]
+	}
+	shown = append([]*store.DigestComment(nil), shown...)
+	sort.SliceStable(shown, func(i, j int) bool {
+		return shown[i].TopicID < shown[j].TopicID
+	})
+
+	var topicID int64
+	for _, c := range shown {
+		if c.TopicID != topicID {
+			topicID = c.TopicID
+			fmt.Fprintf(buf, "\n%s\n%s/#/t/%d\n", c.TopicTitle, s.BaseURL, c.TopicID)
+		}
+		author := c.AuthorName
+		if author == "" {
+			author = fmt.Sprintf("User #%d", c.AuthorID)
+		}
+		fmt.Fprintf(buf, "\n  %s, %s:\n  %s\n", author, c.CreatedAt.UTC().Format("Jan 2 15:04 MST"),
+			strings.Replace(excerpt(c.Content), "\n", "\n  ", -1))
+	}
+	if len(comments) > maxComments {
+		fmt.Fprintf(buf, "\n...and %d more.\n", len(comments)-maxComments)
+	}
+
+	fmt.Fprintf(buf, "\nTo stop receiving these emails, open this link:\n%s/#/unsubscribe/%s\n", s.BaseURL, token)
+	return buf.String()
+}
+
+// excerpt shortens the comment content to maxExcerptLen runes.
+func excerpt(content string) string {
+	if utf8.RuneCountInString(content) <= maxExcerptLen {
+		return content
+	}
+	runes := []rune(content)
+	return string(runes[:maxExcerptLen]) + "..."
+}
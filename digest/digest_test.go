@@ -0,0 +1,151 @@
+package digest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/mailer"
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/memory"
+)
+
+func TestSender(t *testing.T) {
+	st := memory.New()
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sent []*mailer.Message
+	s := NewSender(&Config{
+		Logger:            log.New(ioutil.Discard, "", 0),
+		DigestStore:       st.Digests(),
+		UserStore:         st.Users(),
+		LocalAccountStore: st.LocalAccounts(),
+		Mailer: &mailer.MockMailer{
+			OnSend: func(msg *mailer.Message) error {
+				sent = append(sent, msg)
+				return nil
+			},
+		},
+		JWTService: jwtService,
+		BaseURL:    "https://example.test/forum",
+		Title:      "Forum",
+	})
+
+	// newUser creates a local account user with the given digest mode.
+	newUser := func(email, name string, verified bool, mode string) int64 {
+		userID, err := st.LocalAccounts().New(email, "hash")
+		if err != nil {
+			t.Fatalf("failed to create local account: %s", err)
+		}
+		if verified {
+			if err := st.LocalAccounts().SetVerified(userID); err != nil {
+				t.Fatalf("failed to verify local account: %s", err)
+			}
+		}
+		if err := st.Users().SetName(userID, name); err != nil {
+			t.Fatalf("failed to set user name: %s", err)
+		}
+		if err := st.Digests().SetMode(userID, mode); err != nil {
+			t.Fatalf("failed to set digest mode: %s", err)
+		}
+		return userID
+	}
+
+	u1 := newUser("user1@example.test", "user1", true, store.DigestImmediate)
+	u2 := newUser("user2@example.test", "user2", false, store.DigestImmediate)
+	u3 := newUser("user3@example.test", "user3", true, store.DigestDaily)
+
+	t1, err := st.Topics().New(u1, 0, "Topic one")
+	if err != nil {
+		t.Fatalf("failed to create topic: %s", err)
+	}
+	t2, err := st.Topics().New(u1, 0, "Topic two")
+	if err != nil {
+		t.Fatalf("failed to create topic: %s", err)
+	}
+	for _, userID := range []int64{u1, u2, u3} {
+		for _, topicID := range []int64{t1, t2} {
+			if err := st.Watches().Watch(userID, topicID); err != nil {
+				t.Fatalf("failed to watch topic: %s", err)
+			}
+		}
+	}
+
+	newComment := func(topicID, authorID int64, content string) {
+		if _, err := st.Comments().New(topicID, authorID, content, nil); err != nil {
+			t.Fatalf("failed to create comment: %s", err)
+		}
+	}
+	newComment(t1, u2, "first comment")
+	newComment(t2, u3, "second comment")
+	newComment(t1, u3, "third comment")
+
+	s.SendDue()
+
+	// The unverified user gets nothing, the daily digest of user3 is due for the first time.
+	if len(sent) != 2 {
+		t.Fatalf("expected 2 emails, got %d", len(sent))
+	}
+	msg := sent[0]
+	if msg.To != "user1@example.test" || msg.Subject != "Forum: 3 new comments" {
+		t.Fatalf("bad email: %+v", msg)
+	}
+	for _, want := range []string{
+		"Topic one\nhttps://example.test/forum/#/t/1\n\n  user2, ",
+		"first comment\n\n  user3, ",
+		"third comment\n\nTopic two\nhttps://example.test/forum/#/t/2\n",
+		"second comment\n",
+	} {
+		if !strings.Contains(msg.Body, want) {
+			t.Fatalf("expected %q in the email body: %q", want, msg.Body)
+		}
+	}
+	if msg := sent[1]; msg.To != "user3@example.test" || msg.Subject != "Forum: 1 new comment" || !strings.Contains(msg.Body, "first comment") {
+		t.Fatalf("bad email: %+v", msg)
+	}
+
+	prefix := "https://example.test/forum/#/unsubscribe/"
+	i := strings.Index(msg.Body, prefix)
+	if i < 0 {
+		t.Fatalf("no unsubscribe link in the email: %q", msg.Body)
+	}
+	token := strings.Fields(msg.Body[i+len(prefix):])[0]
+	userID, err := jwtService.VerifyPurpose(token, UnsubscribePurpose)
+	if err != nil || userID != u1 {
+		t.Fatalf("bad unsubscribe token: user %d, %v", userID, err)
+	}
+
+	// The queues are cleared, including the queue of the unverified user.
+	for _, userID := range []int64{u1, u2, u3} {
+		queued, err := st.Digests().GetQueued(userID)
+		if err != nil || len(queued) != 0 {
+			t.Fatalf("expected an empty queue for user %d, got %d, %v", userID, len(queued), err)
+		}
+	}
+
+	// The daily digest is not due again on the same day.
+	sent = nil
+	for i := 0; i < maxComments+2; i++ {
+		newComment(t2, u2, fmt.Sprintf("comment %d", i))
+	}
+	s.SendDue()
+	if len(sent) != 1 || sent[0].To != "user1@example.test" {
+		t.Fatalf("expected 1 email to user1, got %+v", sent)
+	}
+	if body := sent[0].Body; !strings.Contains(body, fmt.Sprintf("comment %d\n", maxComments-1)) ||
+		strings.Contains(body, fmt.Sprintf("comment %d\n", maxComments)) ||
+		!strings.Contains(body, "...and 2 more.") {
+		t.Fatalf("expected %d comments in the email: %q", maxComments, body)
+	}
+	queued, err := st.Digests().GetQueued(u3)
+	if err != nil || len(queued) != maxComments+2 {
+		t.Fatalf("expected %d queued comments for user3, got %d, %v", maxComments+2, len(queued), err)
+	}
+}
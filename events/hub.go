@@ -0,0 +1,186 @@
+// Package events provides an in-process hub that broadcasts bebop content
+// events, such as new comments, to any number of subscribers.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	TopicCreated   = "topic.created"
+	TopicDeleted   = "topic.deleted"
+	CommentCreated = "comment.created"
+	CommentDeleted = "comment.deleted"
+
+	// Reset tells a resuming subscriber that some of the events it missed
+	// are no longer available, e.g. after a server restart, and it should reload the data.
+	Reset = "reset"
+)
+
+// Event is a content change. CommentID is zero for the topic events.
+type Event struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	TopicID   int64     `json:"topicId"`
+	CommentID int64     `json:"commentId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+const (
+	// historySize is the number of the latest events kept to resume subscriptions.
+	historySize = 1000
+	// bufferSize is the number of events a subscriber can fall behind
+	// before it is dropped.
+	bufferSize = 64
+)
+
+// Hub broadcasts the published events to the subscribers.
+// The event IDs increase monotonically within the hub lifetime.
+type Hub struct {
+	mu      sync.Mutex
+	lastID  int64
+	history []Event
+	subs    map[*Subscription]bool
+	closed  bool
+}
+
+// NewHub creates a new event hub.
+func NewHub() *Hub {
+	return &Hub{
+		subs: make(map[*Subscription]bool),
+	}
+}
+
+// Subscription receives the events of a hub.
+type Subscription struct {
+	hub     *Hub
+	topicID int64
+	events  chan Event
+}
+
+// Events returns the channel the events are delivered to. The channel is closed
+// when the subscription is closed, when the hub is closed or when the subscriber
+// falls too far behind. A dropped subscriber can resume from the last event it received.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close unsubscribes from the hub.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Publish sends a new event to the subscribers. It does nothing if the hub is closed.
+func (h *Hub) Publish(typ string, topicID, commentID int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	h.lastID++
+	e := Event{
+		ID:        h.lastID,
+		Type:      typ,
+		TopicID:   topicID,
+		CommentID: commentID,
+		CreatedAt: time.Now(),
+	}
+
+	h.history = append(h.history, e)
+	if len(h.history) > historySize {
+		h.history = append(h.history[:0], h.history[len(h.history)-historySize:]...)
+	}
+
+	for s := range h.subs {
+		if !s.matches(e) {
+			continue
+		}
+		select {
+		case s.events <- e:
+		default:
+			h.remove(s)
+		}
+	}
+}
+
+// Subscribe subscribes to the events of the given topic, or to all the events if topicID is zero.
+// If lastEventID is not zero, the matching events published after it are delivered first.
+// If some of them are no longer available, a Reset event is delivered instead.
+// The subscription of a closed hub has its events channel closed.
+func (h *Hub) Subscribe(topicID, lastEventID int64) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var (
+		missed []Event
+		reset  bool
+	)
+	if lastEventID != 0 {
+		oldest := h.lastID + 1
+		if len(h.history) > 0 {
+			oldest = h.history[0].ID
+		}
+		if lastEventID < oldest-1 || lastEventID > h.lastID {
+			reset = true
+		} else {
+			for _, e := range h.history {
+				if e.ID > lastEventID {
+					missed = append(missed, e)
+				}
+			}
+		}
+	}
+
+	s := &Subscription{
+		hub:     h,
+		topicID: topicID,
+		events:  make(chan Event, bufferSize+len(missed)+1),
+	}
+
+	if h.closed {
+		close(s.events)
+		return s
+	}
+
+	if reset {
+		s.events <- Event{ID: h.lastID, Type: Reset, CreatedAt: time.Now()}
+	}
+	for _, e := range missed {
+		if s.matches(e) {
+			s.events <- e
+		}
+	}
+
+	h.subs[s] = true
+	return s
+}
+
+// Close closes all the subscriptions. The events published afterwards are discarded.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for s := range h.subs {
+		h.remove(s)
+	}
+}
+
+// remove unsubscribes s and closes its events channel. The caller must hold the lock.
+func (h *Hub) remove(s *Subscription) {
+	if !h.subs[s] {
+		return
+	}
+	delete(h.subs, s)
+	close(s.events)
+}
+
+func (s *Subscription) matches(e Event) bool {
+	return s.topicID == 0 || s.topicID == e.TopicID
+}
@@ -0,0 +1,103 @@
+package events
+
+import (
+	"testing"
+)
+
+// receive returns the events buffered in the subscription.
+func receive(s *Subscription) (events []Event, closed bool) {
+	for {
+		select {
+		case e, ok := <-s.Events():
+			if !ok {
+				return events, true
+			}
+			events = append(events, e)
+		default:
+			return events, false
+		}
+	}
+}
+
+func eventIDs(events []Event) []int64 {
+	ids := []int64{}
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestHub(t *testing.T) {
+	h := NewHub()
+
+	all := h.Subscribe(0, 0)
+	topic1 := h.Subscribe(1, 0)
+
+	h.Publish(TopicCreated, 1, 0)
+	h.Publish(CommentCreated, 1, 10)
+	h.Publish(CommentCreated, 2, 20)
+	h.Publish(CommentDeleted, 1, 10)
+
+	events, closed := receive(all)
+	if closed || len(events) != 4 {
+		t.Fatalf("expected 4 events, got %+v, closed %v", events, closed)
+	}
+	e := events[1]
+	if e.ID != 2 || e.Type != CommentCreated || e.TopicID != 1 || e.CommentID != 10 || e.CreatedAt.IsZero() {
+		t.Fatalf("bad event: %+v", e)
+	}
+
+	events, _ = receive(topic1)
+	if got := eventIDs(events); len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 4 {
+		t.Fatalf("expected topic 1 events [1 2 4], got %v", got)
+	}
+
+	topic1.Close()
+	h.Publish(TopicDeleted, 1, 0)
+	if _, closed := receive(topic1); !closed {
+		t.Fatalf("expected closed subscription")
+	}
+	topic1.Close()
+
+	// Resume after the event 2.
+	resumed := h.Subscribe(1, 2)
+	events, _ = receive(resumed)
+	if got := eventIDs(events); len(got) != 2 || got[0] != 4 || got[1] != 5 {
+		t.Fatalf("expected resumed events [4 5], got %v", got)
+	}
+	resumed.Close()
+
+	// Resume from an unknown event, e.g. after a restart.
+	resumed = h.Subscribe(0, 100)
+	events, _ = receive(resumed)
+	if len(events) != 1 || events[0].Type != Reset || events[0].ID != 5 {
+		t.Fatalf("expected a reset event, got %+v", events)
+	}
+	resumed.Close()
+
+	// Resume from an event that is no longer in the history.
+	for i := 0; i < historySize; i++ {
+		h.Publish(CommentCreated, 3, int64(i))
+	}
+	resumed = h.Subscribe(0, 2)
+	events, _ = receive(resumed)
+	if len(events) != 1 || events[0].Type != Reset {
+		t.Fatalf("expected a reset event, got %d events", len(events))
+	}
+	resumed.Close()
+
+	// A subscriber that falls behind is dropped.
+	if _, closed := receive(all); !closed {
+		t.Fatalf("expected a slow subscriber to be dropped")
+	}
+
+	s1 := h.Subscribe(0, 0)
+	s2 := h.Subscribe(2, 0)
+	h.Close()
+	for _, s := range []*Subscription{s1, s2, h.Subscribe(0, 0)} {
+		if _, closed := receive(s); !closed {
+			t.Fatalf("expected subscriptions to be closed with the hub")
+		}
+	}
+	h.Publish(TopicCreated, 4, 0)
+}
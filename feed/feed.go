@@ -0,0 +1,425 @@
+// Package feed provides an HTTP handler that serves the Atom and RSS 2.0 feeds
+// of the latest topics and of the latest comments of a topic.
+package feed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/disintegration/bebop/store"
+)
+
+const (
+	// feedSize is the number of entries in a feed.
+	feedSize = 20
+	// commentsPerPage is the page size of the web app comment list, used to link to the comments.
+	commentsPerPage = 20
+)
+
+// Feed formats.
+const (
+	formatAtom = "atom"
+	formatRSS  = "rss"
+)
+
+// Config is a feed handler configuration.
+type Config struct {
+	Logger       *log.Logger
+	TopicStore   store.TopicStore
+	CommentStore store.CommentStore
+	UserStore    store.UserStore
+	// BaseURL is the web app URL the feeds link to.
+	BaseURL string
+	Title   string
+}
+
+// Handler handles feed requests.
+type Handler struct {
+	*Config
+	router chi.Router
+}
+
+// New creates a new handler based on the given config.
+func New(config *Config) *Handler {
+	h := &Handler{Config: config}
+
+	h.router = chi.NewRouter()
+
+	// Feed readers check for updates with HEAD requests too.
+	for _, format := range []string{formatAtom, formatRSS} {
+		h.router.Get("/topics."+format, h.handleTopics(format))
+		h.router.Head("/topics."+format, h.handleTopics(format))
+		h.router.Get("/topics/{id}."+format, h.handleTopic(format))
+		h.router.Head("/topics/{id}."+format, h.handleTopic(format))
+	}
+
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.router.ServeHTTP(w, r)
+}
+
+// feed is a feed independent of its format.
+type feed struct {
+	Title       string
+	Description string
+	// Link is the web app page and SelfLink is the feed itself.
+	Link     string
+	SelfLink string
+	Updated  time.Time
+	Entries  []*entry
+}
+
+type entry struct {
+	Title     string
+	Link      string
+	Author    string
+	Content   string
+	Published time.Time
+	Updated   time.Time
+}
+
+func (h *Handler) handleTopics(format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		topics, _, err := h.TopicStore.GetLatest(0, feedSize)
+		if err != nil {
+			h.serverError(w, "get latest topics: %s", err)
+			return
+		}
+
+		// The first comment is the topic content.
+		var authorIDs []int64
+		firstComments := make(map[int64]*store.Comment)
+		for _, topic := range topics {
+			authorIDs = append(authorIDs, topic.AuthorID)
+			comments, _, err := h.CommentStore.GetByTopic(topic.ID, 0, 1)
+			if err != nil {
+				h.serverError(w, "get topic comments: %s", err)
+				return
+			}
+			if len(comments) > 0 {
+				firstComments[topic.ID] = comments[0]
+			}
+		}
+
+		authors, err := h.getAuthors(authorIDs)
+		if err != nil {
+			h.serverError(w, "get topic authors: %s", err)
+			return
+		}
+
+		f := &feed{
+			Title:       h.Title,
+			Description: "Latest topics",
+			Link:        h.BaseURL + "/",
+			SelfLink:    h.BaseURL + "/feeds/topics." + format,
+		}
+		for _, topic := range topics {
+			e := &entry{
+				Title:     topic.Title,
+				Link:      h.BaseURL + "/#/t/" + strconv.FormatInt(topic.ID, 10),
+				Author:    authorName(authors, topic.AuthorID),
+				Published: topic.CreatedAt,
+				Updated:   topic.LastCommentAt,
+			}
+			if comment, ok := firstComments[topic.ID]; ok {
+				e.Content = renderMarkdown(comment.Content)
+			}
+			f.add(e)
+		}
+
+		h.serveFeed(w, r, f, format)
+	}
+}
+
+func (h *Handler) handleTopic(format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid topic ID", http.StatusBadRequest)
+			return
+		}
+
+		topic, err := h.TopicStore.Get(id)
+		if err != nil {
+			if err == store.ErrNotFound {
+				http.Error(w, "Topic not found", http.StatusNotFound)
+				return
+			}
+			h.serverError(w, "get topic: %s", err)
+			return
+		}
+
+		// The latest comments are the last page of the topic.
+		offset := topic.CommentCount - feedSize
+		if offset < 0 {
+			offset = 0
+		}
+		comments, _, err := h.CommentStore.GetByTopic(id, offset, feedSize)
+		if err != nil {
+			h.serverError(w, "get topic comments: %s", err)
+			return
+		}
+
+		var authorIDs []int64
+		for _, comment := range comments {
+			authorIDs = append(authorIDs, comment.AuthorID)
+		}
+		authors, err := h.getAuthors(authorIDs)
+		if err != nil {
+			h.serverError(w, "get comment authors: %s", err)
+			return
+		}
+
+		topicURL := h.BaseURL + "/#/t/" + strconv.FormatInt(id, 10)
+		f := &feed{
+			Title:       topic.Title + " - " + h.Title,
+			Description: "Latest comments in " + topic.Title,
+			Link:        topicURL,
+			SelfLink:    h.BaseURL + "/feeds/topics/" + strconv.FormatInt(id, 10) + "." + format,
+			Updated:     topic.LastCommentAt,
+		}
+		// Newest first.
+		for i := len(comments) - 1; i >= 0; i-- {
+			comment := comments[i]
+			page := (offset+i)/commentsPerPage + 1
+			title := "Re: " + topic.Title
+			if offset+i == 0 {
+				title = topic.Title
+			}
+			f.add(&entry{
+				Title:     title,
+				Link:      topicURL + "/p/" + strconv.Itoa(page) + "/c/" + strconv.FormatInt(comment.ID, 10),
+				Author:    authorName(authors, comment.AuthorID),
+				Content:   renderMarkdown(comment.Content),
+				Published: comment.CreatedAt,
+				Updated:   latest(comment.CreatedAt, comment.UpdatedAt),
+			})
+		}
+
+		h.serveFeed(w, r, f, format)
+	}
+}
+
+// add appends the entry to the feed and updates the feed modification time.
+func (f *feed) add(e *entry) {
+	f.Entries = append(f.Entries, e)
+	f.Updated = latest(f.Updated, e.Updated)
+}
+
+// serveFeed writes the feed in the format. The ETag is the hash of the feed
+// and the Last-Modified time is the latest entry update, the conditional
+// requests are handled by http.ServeContent.
+func (h *Handler) serveFeed(w http.ResponseWriter, r *http.Request, f *feed, format string) {
+	var (
+		data        []byte
+		err         error
+		contentType string
+	)
+	if format == formatRSS {
+		data, err = marshalRSS(f)
+		contentType = "application/rss+xml; charset=utf-8"
+	} else {
+		data, err = marshalAtom(f)
+		contentType = "application/atom+xml; charset=utf-8"
+	}
+	if err != nil {
+		h.serverError(w, "marshal feed: %s", err)
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	http.ServeContent(w, r, "", f.Updated, bytes.NewReader(data))
+}
+
+func (h *Handler) serverError(w http.ResponseWriter, format string, v ...interface{}) {
+	h.Logger.Printf("feed: "+format, v...)
+	http.Error(w, "Server error", http.StatusInternalServerError)
+}
+
+// getAuthors finds the users by IDs. GetMany fails if any of the users is missing,
+// so in that case the users are loaded one by one and the missing ones are skipped.
+func (h *Handler) getAuthors(ids []int64) (map[int64]*store.User, error) {
+	authors, err := h.UserStore.GetMany(ids)
+	if err != store.ErrNotFound {
+		return authors, err
+	}
+
+	authors = make(map[int64]*store.User)
+	for _, id := range ids {
+		if _, ok := authors[id]; ok {
+			continue
+		}
+		user, err := h.UserStore.Get(id)
+		if err != nil && err != store.ErrNotFound {
+			return nil, err
+		}
+		authors[id] = user
+	}
+	return authors, nil
+}
+
+// authorName returns the name of the author, or "unknown" if the user
+// is missing or has not set a name yet.
+func authorName(authors map[int64]*store.User, id int64) string {
+	if user := authors[id]; user != nil && user.Name != "" {
+		return user.Name
+	}
+	return "unknown"
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+type atomFeed struct {
+	XMLName xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string       `xml:"title"`
+	ID      string       `xml:"id"`
+	Updated string       `xml:"updated"`
+	Links   []atomLink   `xml:"link"`
+	Entries []*atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title     string       `xml:"title"`
+	ID        string       `xml:"id"`
+	Link      atomLink     `xml:"link"`
+	Published string       `xml:"published"`
+	Updated   string       `xml:"updated"`
+	Author    atomAuthor   `xml:"author"`
+	Content   *atomContent `xml:"content,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func marshalAtom(f *feed) ([]byte, error) {
+	af := &atomFeed{
+		Title:   f.Title,
+		ID:      f.SelfLink,
+		Updated: formatAtomTime(f.Updated),
+		Links: []atomLink{
+			{Rel: "alternate", Type: "text/html", Href: f.Link},
+			{Rel: "self", Type: "application/atom+xml", Href: f.SelfLink},
+		},
+	}
+	for _, e := range f.Entries {
+		ae := &atomEntry{
+			Title:     e.Title,
+			ID:        e.Link,
+			Link:      atomLink{Rel: "alternate", Type: "text/html", Href: e.Link},
+			Published: formatAtomTime(e.Published),
+			Updated:   formatAtomTime(e.Updated),
+			Author:    atomAuthor{Name: e.Author},
+		}
+		if e.Content != "" {
+			ae.Content = &atomContent{Type: "html", Body: e.Content}
+		}
+		af.Entries = append(af.Entries, ae)
+	}
+	return marshalXML(af)
+}
+
+func formatAtomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	AtomLink      atomLink   `xml:"atom:link"`
+	LastBuildDate string     `xml:"lastBuildDate,omitempty"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Creator     string  `xml:"dc:creator"`
+	Description string  `xml:"description,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func marshalRSS(f *feed) ([]byte, error) {
+	rf := &rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			AtomLink:    atomLink{Rel: "self", Type: "application/rss+xml", Href: f.SelfLink},
+		},
+	}
+	if !f.Updated.IsZero() {
+		rf.Channel.LastBuildDate = formatRSSTime(f.Updated)
+	}
+	for _, e := range f.Entries {
+		rf.Channel.Items = append(rf.Channel.Items, &rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			GUID:        rssGUID{IsPermaLink: true, Value: e.Link},
+			PubDate:     formatRSSTime(e.Published),
+			Creator:     e.Author,
+			Description: e.Content,
+		})
+	}
+	return marshalXML(rf)
+}
+
+func formatRSSTime(t time.Time) string {
+	return t.UTC().Format(time.RFC1123Z)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
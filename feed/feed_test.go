@@ -0,0 +1,202 @@
+package feed
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/memory"
+	"github.com/disintegration/bebop/store/mock"
+)
+
+func TestHandler(t *testing.T) {
+	st := memory.New()
+
+	newUser := func(name string) int64 {
+		id, err := st.Users().New("test", name)
+		if err != nil {
+			t.Fatalf("failed to create user: %s", err)
+		}
+		if err := st.Users().SetName(id, name); err != nil {
+			t.Fatalf("failed to set user name: %s", err)
+		}
+		return id
+	}
+	u1 := newUser("alice")
+	u2 := newUser("bob")
+
+	t1, err := st.Topics().New(u1, 0, "First <topic>")
+	if err != nil {
+		t.Fatalf("failed to create topic: %s", err)
+	}
+	if _, err := st.Comments().New(t1, u1, "Hello **world** <script>alert(1)</script>", nil); err != nil {
+		t.Fatalf("failed to create comment: %s", err)
+	}
+	for i := 0; i < 25; i++ {
+		if _, err := st.Comments().New(t1, u2, "Reply", nil); err != nil {
+			t.Fatalf("failed to create comment: %s", err)
+		}
+	}
+	t2, err := st.Topics().New(u2, 0, "Second topic")
+	if err != nil {
+		t.Fatalf("failed to create topic: %s", err)
+	}
+	if _, err := st.Comments().New(t2, u2, "Content", nil); err != nil {
+		t.Fatalf("failed to create comment: %s", err)
+	}
+
+	h := New(&Config{
+		Logger:       log.New(ioutil.Discard, "", 0),
+		TopicStore:   st.Topics(),
+		CommentStore: st.Comments(),
+		UserStore:    st.Users(),
+		BaseURL:      "https://example.test/forum",
+		Title:        "Forum",
+	})
+
+	request := func(method, url string, header http.Header) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	get := func(url string, header http.Header) *httptest.ResponseRecorder {
+		return request("GET", url, header)
+	}
+
+	w := get("/topics.atom", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/atom+xml; charset=utf-8" {
+		t.Fatalf("bad atom feed response: %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom">`,
+		`<link rel="self" type="application/atom+xml" href="https://example.test/forum/feeds/topics.atom"></link>`,
+		`<title>First &lt;topic&gt;</title>`,
+		`<id>https://example.test/forum/#/t/1</id>`,
+		`<name>alice</name>`,
+		`<content type="html">&lt;p&gt;Hello &lt;strong&gt;world&lt;/strong&gt; &amp;lt;script&amp;gt;alert(1)&amp;lt;/script&amp;gt;&lt;/p&gt;</content>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("atom feed does not contain %q: %s", want, body)
+		}
+	}
+	if n := strings.Count(body, "<entry>"); n != 2 {
+		t.Fatalf("expected 2 atom entries, got %d", n)
+	}
+
+	w = get("/topics/1.rss", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/rss+xml; charset=utf-8" {
+		t.Fatalf("bad rss feed response: %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	body = w.Body.String()
+	if n := strings.Count(body, "<item>"); n != feedSize {
+		t.Fatalf("expected %d rss items, got %d", feedSize, n)
+	}
+	first := strings.Index(body, "<link>https://example.test/forum/#/t/1/p/2/c/26</link>")
+	last := strings.Index(body, "<link>https://example.test/forum/#/t/1/p/1/c/7</link>")
+	if first == -1 || last == -1 || first > last || strings.Contains(body, "/c/6<") {
+		t.Fatalf("expected the latest comments, newest first: %s", body)
+	}
+	if !strings.Contains(body, "<dc:creator>bob</dc:creator>") {
+		t.Fatalf("rss feed does not contain the comment author: %s", body)
+	}
+
+	// Conditional requests.
+	w = get("/topics/2.atom", nil)
+	etag, lastModified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Fatalf("expected ETag and Last-Modified, got %q and %q", etag, lastModified)
+	}
+	if w = get("/topics/2.atom", http.Header{"If-None-Match": {etag}}); w.Code != http.StatusNotModified {
+		t.Fatalf("If-None-Match: want status code %d got %d", http.StatusNotModified, w.Code)
+	}
+	if w = get("/topics/2.atom", http.Header{"If-Modified-Since": {lastModified}}); w.Code != http.StatusNotModified {
+		t.Fatalf("If-Modified-Since: want status code %d got %d", http.StatusNotModified, w.Code)
+	}
+	if w = request("HEAD", "/topics/2.atom", nil); w.Code != http.StatusOK || w.Header().Get("ETag") != etag || w.Body.Len() != 0 {
+		t.Fatalf("bad HEAD response: %d %q %q", w.Code, w.Header().Get("ETag"), w.Body.String())
+	}
+	if w = get("/topics/2.rss", http.Header{"If-None-Match": {etag}}); w.Code != http.StatusOK {
+		t.Fatalf("If-None-Match of another format: want status code %d got %d", http.StatusOK, w.Code)
+	}
+
+	if _, err := st.Comments().New(t2, u1, "New reply", nil); err != nil {
+		t.Fatalf("failed to create comment: %s", err)
+	}
+	w = get("/topics/2.atom", http.Header{"If-None-Match": {etag}})
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag || !strings.Contains(w.Body.String(), "New reply") {
+		t.Fatalf("expected an updated feed, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	for _, tc := range []struct {
+		url      string
+		wantCode int
+	}{
+		{"/topics/100.atom", http.StatusNotFound},
+		{"/topics/BAD.rss", http.StatusBadRequest},
+		{"/topics.json", http.StatusNotFound},
+	} {
+		if w = get(tc.url, nil); w.Code != tc.wantCode {
+			t.Fatalf("%s: want status code %d got %d", tc.url, tc.wantCode, w.Code)
+		}
+	}
+}
+
+func TestHandlerMissingAuthor(t *testing.T) {
+	h := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		TopicStore: &mock.TopicStore{
+			OnGetLatest: func(offset, limit int) ([]*store.Topic, int, error) {
+				return []*store.Topic{
+					{ID: 1, AuthorID: 1, Title: "First"},
+					{ID: 2, AuthorID: 2, Title: "Second"},
+				}, 2, nil
+			},
+		},
+		CommentStore: &mock.CommentStore{
+			OnGetByTopic: func(topicID int64, offset, limit int) ([]*store.Comment, int, error) {
+				return []*store.Comment{}, 0, nil
+			},
+		},
+		UserStore: &mock.UserStore{
+			OnGetMany: func(ids []int64) (map[int64]*store.User, error) {
+				return nil, store.ErrNotFound
+			},
+			OnGet: func(id int64) (*store.User, error) {
+				if id == 1 {
+					return &store.User{ID: 1, Name: "alice"}, nil
+				}
+				return nil, store.ErrNotFound
+			},
+		},
+		BaseURL: "https://example.test/forum",
+		Title:   "Forum",
+	})
+
+	req, err := http.NewRequest("GET", "/topics.atom", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status code %d got %d", http.StatusOK, w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"<name>alice</name>", "<name>unknown</name>"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("atom feed does not contain %q: %s", want, body)
+		}
+	}
+}
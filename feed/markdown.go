@@ -0,0 +1,261 @@
+package feed
+
+import (
+	"bytes"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The Markdown renderer supports the syntax the web app renders comments with:
+// paragraphs with line breaks, headings, block quotes, lists, code blocks,
+// horizontal rules, code spans, emphasis, links, images and autolinks.
+// The raw HTML in the source is escaped, like the app does,
+// and the rendered HTML is passed through sanitizeHTML.
+
+var (
+	headingRe     = regexp.MustCompile(`^ {0,3}(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+	listItemRe    = regexp.MustCompile(`^ {0,3}([-*+]|\d{1,9}[.)])\s+(.*)$`)
+	codeSpanRe    = regexp.MustCompile("(`+)(.+?)(`+)")
+	linkRe        = regexp.MustCompile(`(!?)\[([^\]]*)\]\(\s*([^)\s]+)(?:\s+&#34;[^)]*&#34;)?\s*\)`)
+	autolinkRe    = regexp.MustCompile(`https?://(?:[^\s&\x00]|&amp;)+`)
+	strongRe      = regexp.MustCompile(`\*\*([^*\s](?:[^*]*[^*\s])?)\*\*|__([^_\s](?:[^_]*[^_\s])?)__`)
+	emRe          = regexp.MustCompile(`\*([^*\s](?:[^*]*[^*\s])?)\*|\b_([^_\s](?:[^_]*[^_\s])?)_\b`)
+	delRe         = regexp.MustCompile(`~~([^~\s](?:[^~]*[^~\s])?)~~`)
+	placeholderRe = regexp.MustCompile("\x00([0-9]+)\x00")
+)
+
+// renderMarkdown renders the Markdown text to sanitized HTML.
+func renderMarkdown(text string) string {
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	text = strings.Replace(text, "\x00", "", -1)
+
+	var buf bytes.Buffer
+	renderBlocks(&buf, strings.Split(text, "\n"))
+	return sanitizeHTML(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+func renderBlocks(buf *bytes.Buffer, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case isBlank(line):
+			i++
+
+		case isFence(line):
+			fence := strings.TrimSpace(line)[:3]
+			i++
+			start := i
+			for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+				i++
+			}
+			writeCode(buf, lines[start:i])
+			if i < len(lines) {
+				i++
+			}
+
+		case isIndentedCode(line):
+			start := i
+			for i < len(lines) && (isIndentedCode(lines[i]) || isBlank(lines[i])) {
+				i++
+			}
+			end := i
+			for end > start && isBlank(lines[end-1]) {
+				end--
+			}
+			code := make([]string, 0, end-start)
+			for _, l := range lines[start:end] {
+				if strings.HasPrefix(l, "\t") {
+					code = append(code, l[1:])
+				} else if len(l) >= 4 {
+					code = append(code, l[4:])
+				} else {
+					code = append(code, "")
+				}
+			}
+			writeCode(buf, code)
+
+		case headingRe.MatchString(line):
+			m := headingRe.FindStringSubmatch(line)
+			level := strconv.Itoa(len(m[1]))
+			buf.WriteString("<h" + level + ">" + renderInline(m[2]) + "</h" + level + ">\n")
+			i++
+
+		case isRule(line):
+			buf.WriteString("<hr>\n")
+			i++
+
+		case isQuote(line):
+			var quoted []string
+			for i < len(lines) && isQuote(lines[i]) {
+				l := strings.TrimPrefix(strings.TrimLeft(lines[i], " "), ">")
+				quoted = append(quoted, strings.TrimPrefix(l, " "))
+				i++
+			}
+			buf.WriteString("<blockquote>\n")
+			renderBlocks(buf, quoted)
+			buf.WriteString("</blockquote>\n")
+
+		case listItemRe.MatchString(line):
+			ordered := isOrdered(line)
+			tag := "ul"
+			if ordered {
+				tag = "ol"
+			}
+			buf.WriteString("<" + tag + ">\n")
+			for i < len(lines) {
+				m := listItemRe.FindStringSubmatch(lines[i])
+				if m == nil || isRule(lines[i]) || isOrdered(lines[i]) != ordered {
+					break
+				}
+				item := []string{renderInline(m[2])}
+				i++
+				// The indented lines continue the item.
+				for i < len(lines) && !isBlank(lines[i]) && !listItemRe.MatchString(lines[i]) &&
+					strings.HasPrefix(lines[i], " ") {
+					item = append(item, renderInline(strings.TrimSpace(lines[i])))
+					i++
+				}
+				buf.WriteString("<li>" + strings.Join(item, "<br>\n") + "</li>\n")
+				// Blank lines between the items do not end the list.
+				next := i
+				for next < len(lines) && isBlank(lines[next]) {
+					next++
+				}
+				if next < len(lines) && listItemRe.MatchString(lines[next]) {
+					i = next
+				}
+			}
+			buf.WriteString("</" + tag + ">\n")
+
+		default:
+			var paragraph []string
+			for i < len(lines) && !isBlank(lines[i]) && (len(paragraph) == 0 || !startsBlock(lines[i])) {
+				paragraph = append(paragraph, renderInline(strings.TrimSpace(lines[i])))
+				i++
+			}
+			buf.WriteString("<p>" + strings.Join(paragraph, "<br>\n") + "</p>\n")
+		}
+	}
+}
+
+func writeCode(buf *bytes.Buffer, lines []string) {
+	buf.WriteString("<pre><code>")
+	for _, l := range lines {
+		buf.WriteString(html.EscapeString(l))
+		buf.WriteString("\n")
+	}
+	buf.WriteString("</code></pre>\n")
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func isFence(line string) bool {
+	l := strings.TrimSpace(line)
+	return strings.HasPrefix(l, "```") || strings.HasPrefix(l, "~~~")
+}
+
+func isIndentedCode(line string) bool {
+	return (strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")) && !isBlank(line)
+}
+
+func isQuote(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " "), ">")
+}
+
+func isOrdered(line string) bool {
+	l := strings.TrimLeft(line, " ")
+	return l != "" && l[0] >= '0' && l[0] <= '9'
+}
+
+// isRule checks if the line is a horizontal rule: three or more
+// of the same "-", "*" or "_" characters, optionally separated with spaces.
+func isRule(line string) bool {
+	l := strings.Replace(strings.TrimSpace(line), " ", "", -1)
+	if len(l) < 3 || strings.Trim(l, l[:1]) != "" {
+		return false
+	}
+	return l[0] == '-' || l[0] == '*' || l[0] == '_'
+}
+
+// startsBlock checks if the line interrupts a paragraph.
+func startsBlock(line string) bool {
+	return isFence(line) || headingRe.MatchString(line) || isRule(line) || isQuote(line) || listItemRe.MatchString(line)
+}
+
+// renderInline renders the inline elements of a line of text to HTML.
+// The code spans and the links are replaced with placeholders
+// first so that the emphasis is not applied inside them.
+func renderInline(text string) string {
+	var rendered []string
+	placeholder := func(s string) string {
+		rendered = append(rendered, s)
+		return "\x00" + strconv.Itoa(len(rendered)-1) + "\x00"
+	}
+
+	text = codeSpanRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := codeSpanRe.FindStringSubmatch(s)
+		if m[1] != m[3] {
+			return s
+		}
+		return placeholder("<code>" + html.EscapeString(strings.TrimSpace(m[2])) + "</code>")
+	})
+
+	text = html.EscapeString(text)
+
+	text = linkRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := linkRe.FindStringSubmatch(s)
+		href, ok := safeURL(m[3])
+		if !ok {
+			return s
+		}
+		if m[1] == "!" {
+			// The alt text is an attribute value: the code spans are not rendered in it.
+			alt := placeholderRe.ReplaceAllString(m[2], "")
+			return placeholder(`<img src="` + href + `" alt="` + alt + `">`)
+		}
+		return placeholder(`<a href="` + href + `" rel="nofollow">` + renderEmphasis(m[2]) + `</a>`)
+	})
+
+	text = autolinkRe.ReplaceAllStringFunc(text, func(s string) string {
+		link := strings.TrimRight(s, ".,:;!?)")
+		href, ok := safeURL(link)
+		if !ok {
+			return s
+		}
+		return placeholder(`<a href="`+href+`" rel="nofollow">`+link+`</a>`) + s[len(link):]
+	})
+
+	text = renderEmphasis(text)
+
+	// The links can contain the code span placeholders.
+	for placeholderRe.MatchString(text) {
+		text = placeholderRe.ReplaceAllStringFunc(text, func(s string) string {
+			n, _ := strconv.Atoi(s[1 : len(s)-1])
+			return rendered[n]
+		})
+	}
+	return text
+}
+
+func renderEmphasis(text string) string {
+	text = strongRe.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = emRe.ReplaceAllString(text, "<em>$1$2</em>")
+	text = delRe.ReplaceAllString(text, "<del>$1</del>")
+	return text
+}
+
+// safeURL checks the escaped URL of a link or an image and returns it
+// escaped for an attribute value. Only the http, https and mailto URLs are allowed.
+func safeURL(escaped string) (string, bool) {
+	u, err := url.Parse(html.UnescapeString(escaped))
+	if err != nil || !allowedURL(u.String()) {
+		return "", false
+	}
+	return html.EscapeString(u.String()), true
+}
@@ -0,0 +1,102 @@
+package feed
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// allowedTags are the elements the Markdown renderer produces
+// mapped to their allowed attributes.
+var allowedTags = map[string][]string{
+	"p":          nil,
+	"br":         nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"blockquote": nil,
+	"ul":         nil,
+	"ol":         nil,
+	"li":         nil,
+	"pre":        nil,
+	"code":       nil,
+	"strong":     nil,
+	"em":         nil,
+	"del":        nil,
+	"a":          {"href", "rel"},
+	"img":        {"src", "alt"},
+}
+
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+// sanitizeHTML rebuilds the rendered HTML from the allowed elements and attributes only.
+// The other tags are escaped and the links with unsafe URLs are dropped,
+// so that a bug in the renderer can not inject markup into the feeds.
+func sanitizeHTML(s string) string {
+	var buf bytes.Buffer
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return buf.String()
+
+		case html.TextToken:
+			buf.WriteString(html.EscapeString(string(z.Text())))
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			attrs, ok := allowedTags[t.Data]
+			if !ok {
+				buf.WriteString(html.EscapeString(t.String()))
+				continue
+			}
+			buf.WriteString("<" + t.Data)
+			for _, a := range t.Attr {
+				if a.Namespace != "" || !contains(attrs, a.Key) {
+					continue
+				}
+				if (a.Key == "href" || a.Key == "src") && !allowedURL(a.Val) {
+					continue
+				}
+				buf.WriteString(" " + a.Key + `="` + html.EscapeString(a.Val) + `"`)
+			}
+			buf.WriteString(">")
+
+		case html.EndTagToken:
+			t := z.Token()
+			if _, ok := allowedTags[t.Data]; !ok || voidTags[t.Data] {
+				buf.WriteString(html.EscapeString(t.String()))
+				continue
+			}
+			buf.WriteString("</" + t.Data + ">")
+		}
+	}
+}
+
+// allowedURL checks if the URL is an absolute http, https or mailto URL.
+func allowedURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
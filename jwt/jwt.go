@@ -3,9 +3,9 @@
 package jwt
 
 import (
-	"encoding/hex"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"gopkg.in/dgrijalva/jwt-go.v3"
@@ -15,43 +15,104 @@ import (
 type Service interface {
 	Create(userID int64) (token string, err error)
 	Verify(token string) (userID int64, issuedAt time.Time, err error)
+	// CreatePurpose creates a token that is not an auth token, e.g. for an unsubscribe link.
+	// It is only valid for the given purpose and expires after the given ttl.
+	CreatePurpose(userID int64, purpose string, ttl time.Duration) (token string, err error)
+	// VerifyPurpose verifies a token created by CreatePurpose for the given purpose.
+	VerifyPurpose(token, purpose string) (userID int64, err error)
+	// JWKS returns the public keys that verify the tokens.
+	JWKS() *JWKSet
 }
 
-// NewService creates a new JWT service using the given secret (32-byte hex-encoded).
-func NewService(secret string) (Service, error) {
-	secretBytes, err := hex.DecodeString(secret)
+// NewService creates a new JWT service using the given secret (32-byte hex-encoded)
+// as a single HS256 key without ID. The created tokens expire after the given ttl.
+func NewService(secret string, ttl time.Duration) (Service, error) {
+	key, err := NewKey("", AlgHS256, secret, "", "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode jwt secret from hex: %s", err)
+		return nil, err
+	}
+	return NewKeySetService(key, nil, ttl)
+}
+
+// NewKeySetService creates a new JWT service that signs the tokens with the signingKey
+// and verifies them with any of the given keys. Keep the previous signing keys
+// among the verificationKeys after a rotation, until the tokens they signed expire.
+// The created tokens expire after the given ttl.
+func NewKeySetService(signingKey *Key, verificationKeys []*Key, ttl time.Duration) (Service, error) {
+	if signingKey == nil || !signingKey.CanSign() {
+		return nil, errors.New("jwt: signing key has no private part")
 	}
 
-	if len(secretBytes) < 32 {
-		return nil, errors.New("jwt: secret too short")
+	if ttl <= 0 {
+		return nil, errors.New("jwt: non-positive token ttl")
 	}
 
-	return &service{secret: secretBytes}, nil
+	s := &service{
+		signingKey: signingKey,
+		keys:       map[string]*Key{signingKey.ID: signingKey},
+		ttl:        ttl,
+	}
+	for _, k := range verificationKeys {
+		if _, ok := s.keys[k.ID]; ok {
+			return nil, fmt.Errorf("jwt: duplicate key id %q", k.ID)
+		}
+		s.keys[k.ID] = k
+	}
+
+	return s, nil
 }
 
 type service struct {
-	secret []byte
+	signingKey *Key
+	keys       map[string]*Key
+	ttl        time.Duration
 }
 
 type claims struct {
 	jwt.StandardClaims
 	UserID *int64 `json:"_uid"`
+	// Purpose is empty in the auth tokens.
+	Purpose string `json:"_purpose,omitempty"`
+	// IssuedAtMicro is the issue time in microseconds. The standard "iat"
+	// claim is in seconds, which is too coarse to compare against the time
+	// the user logged out of all the sessions.
+	IssuedAtMicro int64 `json:"_iat_us,omitempty"`
 }
 
-// Create creates a JWT string using the given secret key.
+// Create creates a JWT string signed with the signing key.
 func (s *service) Create(userID int64) (string, error) {
+	return s.create(userID, "", s.ttl)
+}
+
+// CreatePurpose creates a JWT string for a purpose other than authentication.
+func (s *service) CreatePurpose(userID int64, purpose string, ttl time.Duration) (string, error) {
+	if purpose == "" {
+		return "", errors.New("jwt: empty token purpose")
+	}
+	if ttl <= 0 {
+		return "", errors.New("jwt: non-positive token ttl")
+	}
+	return s.create(userID, purpose, ttl)
+}
+
+func (s *service) create(userID int64, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now()
 	c := claims{
 		StandardClaims: jwt.StandardClaims{
-			IssuedAt: time.Now().Unix(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ttl).Unix(),
 		},
-		UserID: &userID,
+		UserID:        &userID,
+		Purpose:       purpose,
+		IssuedAtMicro: now.UnixMicro(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, c)
+	token := jwt.NewWithClaims(s.signingKey.signingMethod(), c)
+	if s.signingKey.ID != "" {
+		token.Header["kid"] = s.signingKey.ID
+	}
 
-	tokenString, err := token.SignedString(s.secret)
+	tokenString, err := token.SignedString(s.signingKey.signKey)
 	if err != nil {
 		return "", errors.New("jwt: token signing failed: " + err.Error())
 	}
@@ -59,38 +120,114 @@ func (s *service) Create(userID int64) (string, error) {
 	return tokenString, nil
 }
 
-// Verify verifies the JWT string using the given secret key.
+// Verify verifies the JWT string with the key from its "kid" header
+// and checks that it has not expired.
 // On success it returns the user ID and the time the token was issued.
 func (s *service) Verify(tokenString string) (int64, time.Time, error) {
+	c, err := s.parse(tokenString, "")
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	// Tokens without the microsecond issue time were issued by older versions.
+	if c.IssuedAtMicro == 0 {
+		return *c.UserID, time.Unix(c.IssuedAt, 0), nil
+	}
+	return *c.UserID, time.UnixMicro(c.IssuedAtMicro), nil
+}
+
+// VerifyPurpose verifies the JWT string like Verify
+// and checks that it was created for the given purpose.
+func (s *service) VerifyPurpose(tokenString, purpose string) (int64, error) {
+	if purpose == "" {
+		return 0, errors.New("jwt: empty token purpose")
+	}
+	c, err := s.parse(tokenString, purpose)
+	if err != nil {
+		return 0, err
+	}
+	return *c.UserID, nil
+}
+
+// parse verifies the JWT string and returns its claims.
+// The tokens created for other purposes are rejected.
+func (s *service) parse(tokenString, purpose string) (*claims, error) {
 	token, err := jwt.ParseWithClaims(
 		tokenString,
 		&claims{},
 		func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			kid := ""
+			if v, ok := token.Header["kid"]; ok {
+				if kid, ok = v.(string); !ok {
+					return nil, errors.New("jwt: bad key id")
+				}
+			}
+			key, ok := s.keys[kid]
+			if !ok {
+				return nil, fmt.Errorf("jwt: unknown key id %q", kid)
+			}
+			// The algorithm must match the key: otherwise e.g. an RSA public key
+			// could be used as an HMAC secret to forge a token.
+			if token.Method.Alg() != key.Algorithm {
 				return nil, errors.New("jwt: unexpected signing method")
 			}
-			return s.secret, nil
+			return key.verifyKey, nil
 		},
 	)
 	if err != nil {
-		return 0, time.Time{}, errors.New("jwt: ParseWithClaims failed: " + err.Error())
+		return nil, errors.New("jwt: ParseWithClaims failed: " + err.Error())
 	}
 	if !token.Valid {
-		return 0, time.Time{}, errors.New("jwt: token is not valid")
+		return nil, errors.New("jwt: token is not valid")
 	}
 
 	c, ok := token.Claims.(*claims)
 	if !ok {
-		return 0, time.Time{}, errors.New("jwt: failed to get token claims")
+		return nil, errors.New("jwt: failed to get token claims")
 	}
 
 	if c.UserID == nil {
-		return 0, time.Time{}, errors.New("jwt: UserID claim is not valid")
+		return nil, errors.New("jwt: UserID claim is not valid")
 	}
 
 	if c.IssuedAt == 0 {
-		return 0, time.Time{}, errors.New("jwt: IssuedAt claim is not valid")
+		return nil, errors.New("jwt: IssuedAt claim is not valid")
+	}
+
+	// Tokens without expiration time were issued by older versions.
+	if c.ExpiresAt == 0 {
+		return nil, errors.New("jwt: ExpiresAt claim is not valid")
 	}
 
-	return *c.UserID, time.Unix(c.IssuedAt, 0), nil
+	if c.Purpose != purpose {
+		return nil, errors.New("jwt: unexpected token purpose")
+	}
+
+	return c, nil
+}
+
+// JWKS returns the public keys that verify the tokens, signing key first.
+// HS256 keys are never included.
+func (s *service) JWKS() *JWKSet {
+	set := &JWKSet{Keys: []*JWK{}}
+	if jwk := s.signingKey.jwk(); jwk != nil {
+		set.Keys = append(set.Keys, jwk)
+	}
+	for _, k := range s.sortedKeys() {
+		if k == s.signingKey {
+			continue
+		}
+		if jwk := k.jwk(); jwk != nil {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+func (s *service) sortedKeys() []*Key {
+	keys := make([]*Key, 0, len(s.keys))
+	for _, k := range s.keys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
 }
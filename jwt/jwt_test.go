@@ -11,7 +11,7 @@ func TestCreateAndVerify(t *testing.T) {
 	secret := strings.Repeat("0", 64)
 	badSecret := strings.Repeat("1", 64)
 
-	s, err := NewService(secret)
+	s, err := NewService(secret, time.Hour)
 	if err != nil {
 		t.Fatalf("failed to create a new service: %s", err)
 	}
@@ -36,7 +36,7 @@ func TestCreateAndVerify(t *testing.T) {
 		t.Fatalf("bad issuedAt of the verified token: %v; now: %v", gotIssuedAt, now)
 	}
 
-	s, err = NewService(badSecret)
+	s, err = NewService(badSecret, time.Hour)
 	if err != nil {
 		t.Fatalf("failed to create a new service: %s", err)
 	}
@@ -45,3 +45,77 @@ func TestCreateAndVerify(t *testing.T) {
 		t.Fatalf("no error on verifying with bad secret")
 	}
 }
+
+func TestExpiredToken(t *testing.T) {
+	s, err := NewService(strings.Repeat("0", 64), -time.Minute)
+	if err == nil {
+		t.Fatalf("no error on creating a service with negative ttl")
+	}
+
+	s, err = NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create a new service: %s", err)
+	}
+	s.(*service).ttl = -time.Minute
+
+	tokenString, err := s.Create(10)
+	if err != nil {
+		t.Fatalf("failed to create a token: %s", err)
+	}
+
+	_, _, err = s.Verify(tokenString)
+	if err == nil {
+		t.Fatalf("no error on verifying an expired token")
+	}
+}
+
+func TestPurposeToken(t *testing.T) {
+	s, err := NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create a new service: %s", err)
+	}
+
+	tokenString, err := s.CreatePurpose(10, "unsubscribe", time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create a token: %s", err)
+	}
+
+	userID, err := s.VerifyPurpose(tokenString, "unsubscribe")
+	if err != nil {
+		t.Fatalf("failed to verify a token: %s", err)
+	}
+	if userID != 10 {
+		t.Fatalf("bad verified userID: got %v; want %v", userID, 10)
+	}
+
+	if _, err := s.VerifyPurpose(tokenString, "other"); err == nil {
+		t.Fatalf("no error on verifying a token for another purpose")
+	}
+	if _, _, err := s.Verify(tokenString); err == nil {
+		t.Fatalf("no error on verifying a purpose token as an auth token")
+	}
+
+	authToken, err := s.Create(10)
+	if err != nil {
+		t.Fatalf("failed to create a token: %s", err)
+	}
+	if _, err := s.VerifyPurpose(authToken, "unsubscribe"); err == nil {
+		t.Fatalf("no error on verifying an auth token as a purpose token")
+	}
+
+	if _, err := s.CreatePurpose(10, "", time.Hour); err == nil {
+		t.Fatalf("no error on creating a token with an empty purpose")
+	}
+	if _, err := s.VerifyPurpose(authToken, ""); err == nil {
+		t.Fatalf("no error on verifying a token with an empty purpose")
+	}
+
+	s.(*service).ttl = -time.Minute
+	tokenString, err = s.CreatePurpose(10, "unsubscribe", time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create a token: %s", err)
+	}
+	if _, err := s.VerifyPurpose(tokenString, "unsubscribe"); err != nil {
+		t.Fatalf("purpose token ttl does not override the service ttl: %s", err)
+	}
+}
@@ -0,0 +1,189 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"gopkg.in/dgrijalva/jwt-go.v3"
+)
+
+// Supported signing algorithms.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+const (
+	minSecretLen  = 32
+	minRSAKeyBits = 2048
+)
+
+// Key is a JWT signing or verification key.
+type Key struct {
+	// ID is sent in the "kid" token header. Tokens without the header
+	// are verified with the key that has an empty ID.
+	ID        string
+	Algorithm string
+
+	signKey   interface{} // nil for verification-only keys
+	verifyKey interface{}
+}
+
+// NewKey creates a key from its encoded form. HS256 keys require a hex-encoded
+// secret of at least 32 bytes. RS256 and EdDSA keys require either a PEM-encoded
+// private key (PKCS #1 or PKCS #8) or, for verification-only keys, a PEM-encoded
+// public key (PKIX).
+func NewKey(id, algorithm, secret, privateKeyPEM, publicKeyPEM string) (*Key, error) {
+	k := &Key{ID: id, Algorithm: algorithm}
+
+	if algorithm == AlgHS256 {
+		if privateKeyPEM != "" || publicKeyPEM != "" {
+			return nil, fmt.Errorf("jwt: key %q: HS256 keys use a secret only", id)
+		}
+		secretBytes, err := hex.DecodeString(secret)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: key %q: failed to decode secret from hex: %s", id, err)
+		}
+		if len(secretBytes) < minSecretLen {
+			return nil, fmt.Errorf("jwt: key %q: secret too short", id)
+		}
+		k.signKey = secretBytes
+		k.verifyKey = secretBytes
+		return k, nil
+	}
+
+	if secret != "" {
+		return nil, fmt.Errorf("jwt: key %q: %s keys do not use a secret", id, algorithm)
+	}
+	if (privateKeyPEM == "") == (publicKeyPEM == "") {
+		return nil, fmt.Errorf("jwt: key %q: either a private or a public key is required", id)
+	}
+
+	switch algorithm {
+	case AlgRS256:
+		if privateKeyPEM != "" {
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKeyPEM))
+			if err != nil {
+				return nil, fmt.Errorf("jwt: key %q: %s", id, err)
+			}
+			k.signKey = privateKey
+			k.verifyKey = &privateKey.PublicKey
+		} else {
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(publicKeyPEM))
+			if err != nil {
+				return nil, fmt.Errorf("jwt: key %q: %s", id, err)
+			}
+			k.verifyKey = publicKey
+		}
+		if k.verifyKey.(*rsa.PublicKey).N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("jwt: key %q: RSA key too short", id)
+		}
+
+	case AlgEdDSA:
+		if privateKeyPEM != "" {
+			key, err := parsePEM(privateKeyPEM, x509.ParsePKCS8PrivateKey)
+			if err != nil {
+				return nil, fmt.Errorf("jwt: key %q: %s", id, err)
+			}
+			privateKey, ok := key.(ed25519.PrivateKey)
+			if !ok {
+				return nil, fmt.Errorf("jwt: key %q: not an Ed25519 private key", id)
+			}
+			k.signKey = privateKey
+			k.verifyKey = privateKey.Public()
+		} else {
+			key, err := parsePEM(publicKeyPEM, x509.ParsePKIXPublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("jwt: key %q: %s", id, err)
+			}
+			publicKey, ok := key.(ed25519.PublicKey)
+			if !ok {
+				return nil, fmt.Errorf("jwt: key %q: not an Ed25519 public key", id)
+			}
+			k.verifyKey = publicKey
+		}
+
+	default:
+		return nil, fmt.Errorf("jwt: key %q: unsupported algorithm %q", id, algorithm)
+	}
+
+	return k, nil
+}
+
+func parsePEM(data string, parse func([]byte) (interface{}, error)) (interface{}, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("failed to decode PEM data")
+	}
+	return parse(block.Bytes)
+}
+
+// CanSign reports whether the key can be used to sign new tokens.
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+func (k *Key) signingMethod() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgEdDSA:
+		return signingMethodEd25519
+	}
+	return jwt.SigningMethodHS256
+}
+
+// JWK is a public key in the JSON Web Key format (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	KeyID     string `json:"kid,omitempty"`
+	Algorithm string `json:"alg"`
+	// RSA public key parameters.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 public key parameters.
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set.
+type JWKSet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// jwk returns the public part of the key. It returns nil for HS256 keys
+// because their secret must not be published.
+func (k *Key) jwk() *JWK {
+	enc := base64.RawURLEncoding.EncodeToString
+
+	switch publicKey := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		return &JWK{
+			KeyType:   "RSA",
+			Use:       "sig",
+			KeyID:     k.ID,
+			Algorithm: k.Algorithm,
+			N:         enc(publicKey.N.Bytes()),
+			E:         enc(big.NewInt(int64(publicKey.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return &JWK{
+			KeyType:   "OKP",
+			Use:       "sig",
+			KeyID:     k.ID,
+			Algorithm: k.Algorithm,
+			Curve:     "Ed25519",
+			X:         enc(publicKey),
+		}
+	}
+	return nil
+}
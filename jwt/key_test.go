@@ -0,0 +1,189 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/dgrijalva/jwt-go.v3"
+)
+
+func encodePEM(t *testing.T, typ string, der []byte, err error) string {
+	if err != nil {
+		t.Fatalf("failed to marshal a key: %s", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}))
+}
+
+func TestKeySetRotation(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate an RSA key: %s", err)
+	}
+	rsaDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	rsaPublicPEM := encodePEM(t, "PUBLIC KEY", rsaDER, err)
+	rsaDER, err = x509.MarshalPKCS8PrivateKey(rsaKey)
+	rsaPrivatePEM := encodePEM(t, "PRIVATE KEY", rsaDER, err)
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate an Ed25519 key: %s", err)
+	}
+	edDER, err := x509.MarshalPKIXPublicKey(edPublic)
+	edPublicPEM := encodePEM(t, "PUBLIC KEY", edDER, err)
+	edDER, err = x509.MarshalPKCS8PrivateKey(edPrivate)
+	edPrivatePEM := encodePEM(t, "PRIVATE KEY", edDER, err)
+
+	newKey := func(id, alg, secret, private, public string) *Key {
+		k, err := NewKey(id, alg, secret, private, public)
+		if err != nil {
+			t.Fatalf("failed to create key %q: %s", id, err)
+		}
+		return k
+	}
+
+	legacy, err := NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create a new service: %s", err)
+	}
+	legacyToken, err := legacy.Create(1)
+	if err != nil {
+		t.Fatalf("failed to create a token: %s", err)
+	}
+
+	hsService, err := NewKeySetService(newKey("hs", AlgHS256, strings.Repeat("1", 64), "", ""), nil, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create a new service: %s", err)
+	}
+	hsToken, err := hsService.Create(2)
+	if err != nil {
+		t.Fatalf("failed to create a token: %s", err)
+	}
+
+	rsService, err := NewKeySetService(newKey("rs", AlgRS256, "", rsaPrivatePEM, ""), nil, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create a new service: %s", err)
+	}
+	rsToken, err := rsService.Create(3)
+	if err != nil {
+		t.Fatalf("failed to create a token: %s", err)
+	}
+
+	// The EdDSA key signs now, the previous keys only verify.
+	s, err := NewKeySetService(
+		newKey("ed", AlgEdDSA, "", edPrivatePEM, ""),
+		[]*Key{
+			newKey("", AlgHS256, strings.Repeat("0", 64), "", ""),
+			newKey("hs", AlgHS256, strings.Repeat("1", 64), "", ""),
+			newKey("rs", AlgRS256, "", "", rsaPublicPEM),
+		},
+		time.Hour,
+	)
+	if err != nil {
+		t.Fatalf("failed to create a new service: %s", err)
+	}
+	edToken, err := s.Create(4)
+	if err != nil {
+		t.Fatalf("failed to create a token: %s", err)
+	}
+
+	for i, token := range []string{legacyToken, hsToken, rsToken, edToken} {
+		userID, _, err := s.Verify(token)
+		if err != nil {
+			t.Fatalf("failed to verify token #%d: %s", i, err)
+		}
+		if userID != int64(i+1) {
+			t.Fatalf("bad verified userID of token #%d: got %v; want %v", i, userID, i+1)
+		}
+	}
+
+	// The EdDSA public key verifies the tokens in another service.
+	verifier, err := NewKeySetService(
+		newKey("hs", AlgHS256, strings.Repeat("1", 64), "", ""),
+		[]*Key{newKey("ed", AlgEdDSA, "", "", edPublicPEM)},
+		time.Hour,
+	)
+	if err != nil {
+		t.Fatalf("failed to create a new service: %s", err)
+	}
+	if _, _, err := verifier.Verify(edToken); err != nil {
+		t.Fatalf("failed to verify a token with the public key: %s", err)
+	}
+	if _, _, err := verifier.Verify(rsToken); err == nil {
+		t.Fatalf("no error on verifying a token with unknown key id")
+	}
+
+	// Tokens must not be verified with a key of another algorithm,
+	// e.g. an HMAC token signed with the RSA public key as a secret.
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		StandardClaims: jwt.StandardClaims{
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		},
+	})
+	forged.Header["kid"] = "rs"
+	forgedToken, err := forged.SignedString([]byte(rsaPublicPEM))
+	if err != nil {
+		t.Fatalf("failed to sign a forged token: %s", err)
+	}
+	if _, _, err := s.Verify(forgedToken); err == nil {
+		t.Fatalf("no error on verifying a token with mismatched algorithm")
+	}
+
+	data, err := json.Marshal(s.JWKS())
+	if err != nil {
+		t.Fatalf("failed to marshal jwks: %s", err)
+	}
+	var jwks struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			Alg     string `json:"alg"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		t.Fatalf("failed to unmarshal jwks: %s", err)
+	}
+	if len(jwks.Keys) != 2 ||
+		jwks.Keys[0].KeyID != "ed" || jwks.Keys[0].KeyType != "OKP" || jwks.Keys[0].Alg != AlgEdDSA ||
+		jwks.Keys[1].KeyID != "rs" || jwks.Keys[1].KeyType != "RSA" || jwks.Keys[1].Alg != AlgRS256 {
+		t.Fatalf("bad jwks: %s", data)
+	}
+}
+
+func TestNewKeySetServiceErrors(t *testing.T) {
+	hs, err := NewKey("a", AlgHS256, strings.Repeat("0", 64), "", "")
+	if err != nil {
+		t.Fatalf("failed to create a key: %s", err)
+	}
+
+	if _, err := NewKeySetService(hs, []*Key{hs}, time.Hour); err == nil {
+		t.Fatalf("no error on duplicate key ids")
+	}
+
+	edPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate an Ed25519 key: %s", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(edPublic)
+	public, err := NewKey("b", AlgEdDSA, "", "", encodePEM(t, "PUBLIC KEY", der, err))
+	if err != nil {
+		t.Fatalf("failed to create a key: %s", err)
+	}
+	if _, err := NewKeySetService(public, nil, time.Hour); err == nil {
+		t.Fatalf("no error on signing with a public key")
+	}
+
+	if _, err := NewKey("c", "none", "", "", ""); err == nil {
+		t.Fatalf("no error on unsupported algorithm")
+	}
+	if _, err := NewKey("d", AlgHS256, strings.Repeat("0", 16), "", ""); err == nil {
+		t.Fatalf("no error on short secret")
+	}
+}
@@ -0,0 +1,42 @@
+package localauth
+
+import (
+	"github.com/disintegration/bebop/session"
+)
+
+// MockService is a mock implementation of localauth.Service
+type MockService struct {
+	OnRegister             func(email, password string) error
+	OnResendVerification   func(email string) error
+	OnVerify               func(token string) (*session.Tokens, error)
+	OnLogin                func(email, password string) (*session.Tokens, error)
+	OnRequestPasswordReset func(email string) error
+	OnResetPassword        func(token, password string) (*session.Tokens, error)
+	OnRequestMagicLink     func(email string) error
+	OnLoginWithMagicLink   func(token string) (*session.Tokens, error)
+}
+
+func (s *MockService) Register(email, password string) error {
+	return s.OnRegister(email, password)
+}
+func (s *MockService) ResendVerification(email string) error {
+	return s.OnResendVerification(email)
+}
+func (s *MockService) Verify(token string) (*session.Tokens, error) {
+	return s.OnVerify(token)
+}
+func (s *MockService) Login(email, password string) (*session.Tokens, error) {
+	return s.OnLogin(email, password)
+}
+func (s *MockService) RequestPasswordReset(email string) error {
+	return s.OnRequestPasswordReset(email)
+}
+func (s *MockService) ResetPassword(token, password string) (*session.Tokens, error) {
+	return s.OnResetPassword(token, password)
+}
+func (s *MockService) RequestMagicLink(email string) error {
+	return s.OnRequestMagicLink(email)
+}
+func (s *MockService) LoginWithMagicLink(token string) (*session.Tokens, error) {
+	return s.OnLoginWithMagicLink(token)
+}
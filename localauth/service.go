@@ -0,0 +1,388 @@
+// Package localauth provides a service that authenticates bebop users
+// with an email and a password or with a magic link sent by email,
+// without an external OAuth provider.
+package localauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/disintegration/bebop/mailer"
+	"github.com/disintegration/bebop/session"
+	"github.com/disintegration/bebop/store"
+)
+
+// Password length limits in bytes. Bcrypt ignores everything after 72 bytes.
+const (
+	MinPasswordLen = 8
+	MaxPasswordLen = 72
+)
+
+// Lifetimes of the tokens sent by email.
+const (
+	verifyEmailTTL   = 48 * time.Hour
+	resetPasswordTTL = time.Hour
+	magicLinkTTL     = 15 * time.Minute
+)
+
+// Local authentication errors.
+var (
+	ErrInvalidEmail       = errors.New("localauth: invalid email")
+	ErrInvalidPassword    = errors.New("localauth: invalid password length")
+	ErrInvalidCredentials = errors.New("localauth: invalid email or password")
+	ErrNotVerified        = errors.New("localauth: email is not verified")
+	ErrInvalidToken       = errors.New("localauth: invalid or expired token")
+	ErrUserBlocked        = errors.New("localauth: user is blocked")
+	ErrMagicLinksDisabled = errors.New("localauth: magic links are disabled")
+)
+
+// Service is a local authentication service. The methods that request
+// a token by email do not report whether an account with the email exists.
+type Service interface {
+	// Register creates a new account and sends an email verification link.
+	// If the email is taken, the account owner is sent a password reset link instead.
+	Register(email, password string) error
+
+	// ResendVerification sends a new email verification link to an unverified account.
+	ResendVerification(email string) error
+
+	// Verify marks the account email as verified and starts a new session.
+	Verify(token string) (*session.Tokens, error)
+
+	// Login starts a new session for the account with a verified email.
+	Login(email, password string) (*session.Tokens, error)
+
+	// RequestPasswordReset sends a password reset link.
+	RequestPasswordReset(email string) error
+
+	// ResetPassword sets a new password, ends all the user sessions and starts a new one.
+	ResetPassword(token, password string) (*session.Tokens, error)
+
+	// RequestMagicLink sends a link that signs in without a password.
+	RequestMagicLink(email string) error
+
+	// LoginWithMagicLink starts a new session using the token of a magic link.
+	LoginWithMagicLink(token string) (*session.Tokens, error)
+}
+
+// Config is a local authentication service configuration.
+type Config struct {
+	LocalAccountStore store.LocalAccountStore
+	AuthTokenStore    store.AuthTokenStore
+	UserStore         store.UserStore
+	SessionService    session.Service
+	Mailer            mailer.Mailer
+	// BaseURL is the web app URL the links in the emails point to.
+	BaseURL string
+	// Title is the forum title used in the emails.
+	Title string
+	// MagicLinks enables the passwordless sign in.
+	MagicLinks bool
+}
+
+// service is the main implementation of the Service.
+type service struct {
+	*Config
+}
+
+// NewService creates a new local authentication service.
+func NewService(config *Config) Service {
+	return &service{Config: config}
+}
+
+// dummyHash is compared with the passwords of unknown emails so that
+// login takes the same time whether or not an account exists.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// Register creates a new account and sends an email verification link.
+// If the email is taken, the account owner is sent a password reset link instead,
+// so the result does not tell whether an account with the email exists.
+func (s *service) Register(email, password string) error {
+	if !validEmail(email) {
+		return ErrInvalidEmail
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	userID, err := s.LocalAccountStore.New(email, hash)
+	if err == store.ErrConflict {
+		return s.sendAccountExists(email)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.sendToken(userID, email, store.AuthTokenVerifyEmail, verifyEmailTTL,
+		"Confirm your email",
+		"Open this link to confirm your email and sign in to %s:\n\n%s\n\nThe link expires in 48 hours.",
+		"/#/auth/verify/",
+	)
+}
+
+// sendAccountExists tells the owner of the account with the email that someone
+// tried to register it again and sends them a password reset link.
+func (s *service) sendAccountExists(email string) error {
+	account, err := s.LocalAccountStore.GetByEmail(email)
+	if err != nil {
+		return err
+	}
+
+	err = s.AuthTokenStore.DeleteByUser(account.UserID, store.AuthTokenResetPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.sendToken(account.UserID, account.Email, store.AuthTokenResetPassword, resetPasswordTTL,
+		"You already have an account",
+		"Someone tried to create a new account on %s with this email, but you already have one. "+
+			"Sign in with your password or open this link to set a new one:\n\n%s\n\n"+
+			"The link expires in 1 hour. If you did not try to create an account, ignore this email.",
+		"/#/auth/reset/",
+	)
+}
+
+// ResendVerification sends a new email verification link to an unverified account.
+func (s *service) ResendVerification(email string) error {
+	account, err := s.LocalAccountStore.GetByEmail(email)
+	if err == store.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if account.Verified {
+		return nil
+	}
+
+	err = s.AuthTokenStore.DeleteByUser(account.UserID, store.AuthTokenVerifyEmail)
+	if err != nil {
+		return err
+	}
+
+	return s.sendToken(account.UserID, account.Email, store.AuthTokenVerifyEmail, verifyEmailTTL,
+		"Confirm your email",
+		"Open this link to confirm your email and sign in to %s:\n\n%s\n\nThe link expires in 48 hours.",
+		"/#/auth/verify/",
+	)
+}
+
+// Verify marks the account email as verified and starts a new session.
+func (s *service) Verify(token string) (*session.Tokens, error) {
+	t, err := s.useToken(store.AuthTokenVerifyEmail, token)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.LocalAccountStore.SetVerified(t.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createSession(t.UserID)
+}
+
+// Login starts a new session for the account with a verified email.
+func (s *service) Login(email, password string) (*session.Tokens, error) {
+	account, err := s.LocalAccountStore.GetByEmail(email)
+	if err == store.ErrNotFound {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(password))
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if !account.Verified {
+		return nil, ErrNotVerified
+	}
+
+	return s.createSession(account.UserID)
+}
+
+// RequestPasswordReset sends a password reset link.
+func (s *service) RequestPasswordReset(email string) error {
+	account, err := s.LocalAccountStore.GetByEmail(email)
+	if err == store.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.AuthTokenStore.DeleteByUser(account.UserID, store.AuthTokenResetPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.sendToken(account.UserID, account.Email, store.AuthTokenResetPassword, resetPasswordTTL,
+		"Reset your password",
+		"Open this link to set a new password for %s:\n\n%s\n\nThe link expires in 1 hour. If you did not request a password reset, ignore this email.",
+		"/#/auth/reset/",
+	)
+}
+
+// ResetPassword sets a new password, ends all the user sessions and starts a new one.
+func (s *service) ResetPassword(token, password string) (*session.Tokens, error) {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := s.useToken(store.AuthTokenResetPassword, token)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.LocalAccountStore.SetPasswordHash(t.UserID, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// The link was received by email, so the email is verified.
+	err = s.LocalAccountStore.SetVerified(t.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.SessionService.RevokeAll(t.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createSession(t.UserID)
+}
+
+// RequestMagicLink sends a link that signs in without a password.
+func (s *service) RequestMagicLink(email string) error {
+	if !s.MagicLinks {
+		return ErrMagicLinksDisabled
+	}
+
+	account, err := s.LocalAccountStore.GetByEmail(email)
+	if err == store.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.sendToken(account.UserID, account.Email, store.AuthTokenMagicLink, magicLinkTTL,
+		"Sign in",
+		"Open this link to sign in to %s:\n\n%s\n\nThe link expires in 15 minutes. If you did not request it, ignore this email.",
+		"/#/auth/magic/",
+	)
+}
+
+// LoginWithMagicLink starts a new session using the token of a magic link.
+func (s *service) LoginWithMagicLink(token string) (*session.Tokens, error) {
+	if !s.MagicLinks {
+		return nil, ErrMagicLinksDisabled
+	}
+
+	t, err := s.useToken(store.AuthTokenMagicLink, token)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.LocalAccountStore.SetVerified(t.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createSession(t.UserID)
+}
+
+// sendToken creates a new token and emails a link with it to the user.
+// The text format gets the forum title and the link.
+func (s *service) sendToken(userID int64, email, purpose string, ttl time.Duration, subject, text, path string) error {
+	token, err := genToken()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.AuthTokenStore.New(userID, purpose, hashToken(token), time.Now().Add(ttl))
+	if err != nil {
+		return err
+	}
+
+	return s.Mailer.Send(&mailer.Message{
+		To:      email,
+		Subject: s.Title + ": " + subject,
+		Body:    fmt.Sprintf(text, s.Title, s.BaseURL+path+token),
+	})
+}
+
+func (s *service) useToken(purpose, token string) (*store.AuthToken, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+	t, err := s.AuthTokenStore.Use(purpose, hashToken(token))
+	if err == store.ErrNotFound {
+		return nil, ErrInvalidToken
+	}
+	return t, err
+}
+
+func (s *service) createSession(userID int64) (*session.Tokens, error) {
+	user, err := s.UserStore.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Blocked {
+		return nil, ErrUserBlocked
+	}
+
+	return s.SessionService.Create(userID)
+}
+
+func validEmail(email string) bool {
+	if len(email) > 254 {
+		return false
+	}
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Name == "" && addr.Address == email
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLen || len(password) > MaxPasswordLen {
+		return "", ErrInvalidPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// genToken generates a random token to send by email.
+func genToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hash of a token kept in the store.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,202 @@
+package localauth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/mailer"
+	"github.com/disintegration/bebop/session"
+	"github.com/disintegration/bebop/store/memory"
+)
+
+func TestService(t *testing.T) {
+	st := memory.New()
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionService := session.NewService(st.Sessions(), st.Users(), st.AuthTokens(), jwtService, time.Hour)
+
+	var sent []*mailer.Message
+	s := NewService(&Config{
+		LocalAccountStore: st.LocalAccounts(),
+		AuthTokenStore:    st.AuthTokens(),
+		UserStore:         st.Users(),
+		SessionService:    sessionService,
+		Mailer: &mailer.MockMailer{
+			OnSend: func(msg *mailer.Message) error {
+				sent = append(sent, msg)
+				return nil
+			},
+		},
+		BaseURL: "https://example.test/forum",
+		Title:   "Forum",
+	})
+
+	// lastToken returns the token from the link in the last sent email.
+	lastToken := func(path string) string {
+		if len(sent) == 0 {
+			t.Fatalf("no email sent")
+		}
+		msg := sent[len(sent)-1]
+		prefix := "https://example.test/forum/#/auth/" + path + "/"
+		i := strings.Index(msg.Body, prefix)
+		if i < 0 {
+			t.Fatalf("no %q link in the email: %q", path, msg.Body)
+		}
+		return strings.Fields(msg.Body[i+len(prefix):])[0]
+	}
+
+	verifyAccess := func(tokens *session.Tokens, wantUserID int64) {
+		gotUserID, _, err := jwtService.Verify(tokens.AccessToken)
+		if err != nil || gotUserID != wantUserID {
+			t.Fatalf("bad access token: user %d, %v", gotUserID, err)
+		}
+	}
+
+	for _, email := range []string{"", "user", "User <user@example.test>", "user@example.test "} {
+		if err := s.Register(email, "password1"); err != ErrInvalidEmail {
+			t.Fatalf("Register(%q): expected ErrInvalidEmail, got %v", email, err)
+		}
+	}
+	for _, password := range []string{"short", strings.Repeat("a", MaxPasswordLen+1)} {
+		if err := s.Register("user@example.test", password); err != ErrInvalidPassword {
+			t.Fatalf("Register: expected ErrInvalidPassword, got %v", err)
+		}
+	}
+
+	err = s.Register("User@Example.test", "password1")
+	if err != nil {
+		t.Fatalf("failed to register: %s", err)
+	}
+	if len(sent) != 1 || sent[0].To != "User@Example.test" || sent[0].Subject != "Forum: Confirm your email" {
+		t.Fatalf("bad verification email: %+v", sent)
+	}
+	verifyToken := lastToken("verify")
+
+	// Registering a taken email succeeds and sends a password reset link to the account owner.
+	err = s.Register("user@example.test", "password2")
+	if err != nil {
+		t.Fatalf("failed to register a taken email: %s", err)
+	}
+	if len(sent) != 2 || sent[1].To != "user@example.test" || sent[1].Subject != "Forum: You already have an account" {
+		t.Fatalf("bad account exists email: %+v", sent[len(sent)-1])
+	}
+	lastToken("reset")
+	if _, err = s.Login("user@example.test", "password2"); err != ErrInvalidCredentials {
+		t.Fatalf("expected ErrInvalidCredentials for the password of a taken email, got %v", err)
+	}
+
+	_, err = s.Login("user@example.test", "password1")
+	if err != ErrNotVerified {
+		t.Fatalf("expected ErrNotVerified, got %v", err)
+	}
+
+	err = s.ResendVerification("user@example.test")
+	if err != nil {
+		t.Fatalf("failed to resend verification: %s", err)
+	}
+	_, err = s.Verify(verifyToken)
+	if err != ErrInvalidToken {
+		t.Fatalf("expected ErrInvalidToken for a replaced verification token, got %v", err)
+	}
+	verifyToken = lastToken("verify")
+
+	tokens, err := s.Verify(verifyToken)
+	if err != nil {
+		t.Fatalf("failed to verify: %s", err)
+	}
+	account, err := st.LocalAccounts().GetByEmail("user@example.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := account.UserID
+	verifyAccess(tokens, userID)
+
+	_, err = s.Verify(verifyToken)
+	if err != ErrInvalidToken {
+		t.Fatalf("expected ErrInvalidToken on reusing a token, got %v", err)
+	}
+
+	_, err = s.Login("user@example.test", "password2")
+	if err != ErrInvalidCredentials {
+		t.Fatalf("expected ErrInvalidCredentials for a wrong password, got %v", err)
+	}
+	_, err = s.Login("nobody@example.test", "password1")
+	if err != ErrInvalidCredentials {
+		t.Fatalf("expected ErrInvalidCredentials for an unknown email, got %v", err)
+	}
+	tokens, err = s.Login("USER@example.test", "password1")
+	if err != nil {
+		t.Fatalf("failed to login: %s", err)
+	}
+	verifyAccess(tokens, userID)
+
+	n := len(sent)
+	err = s.RequestPasswordReset("nobody@example.test")
+	if err != nil || len(sent) != n {
+		t.Fatalf("RequestPasswordReset for an unknown email: err %v, sent %d", err, len(sent)-n)
+	}
+	err = s.RequestPasswordReset("user@example.test")
+	if err != nil {
+		t.Fatalf("failed to request password reset: %s", err)
+	}
+	resetToken := lastToken("reset")
+
+	_, err = s.ResetPassword(resetToken, "short")
+	if err != ErrInvalidPassword {
+		t.Fatalf("expected ErrInvalidPassword, got %v", err)
+	}
+	_, err = s.ResetPassword(verifyToken, "password2")
+	if err != ErrInvalidToken {
+		t.Fatalf("expected ErrInvalidToken for a token of another purpose, got %v", err)
+	}
+	tokens2, err := s.ResetPassword(resetToken, "password2")
+	if err != nil {
+		t.Fatalf("failed to reset password: %s", err)
+	}
+	verifyAccess(tokens2, userID)
+	_, err = sessionService.Refresh(tokens.RefreshToken)
+	if err != session.ErrInvalidToken {
+		t.Fatalf("expected old sessions to be revoked on password reset, got %v", err)
+	}
+	_, err = s.Login("user@example.test", "password1")
+	if err != ErrInvalidCredentials {
+		t.Fatalf("expected ErrInvalidCredentials for the old password, got %v", err)
+	}
+	_, err = s.Login("user@example.test", "password2")
+	if err != nil {
+		t.Fatalf("failed to login with the new password: %s", err)
+	}
+
+	err = s.RequestMagicLink("user@example.test")
+	if err != ErrMagicLinksDisabled {
+		t.Fatalf("expected ErrMagicLinksDisabled, got %v", err)
+	}
+	s.(*service).MagicLinks = true
+	err = s.RequestMagicLink("user@example.test")
+	if err != nil {
+		t.Fatalf("failed to request magic link: %s", err)
+	}
+	magicToken := lastToken("magic")
+	tokens, err = s.LoginWithMagicLink(magicToken)
+	if err != nil {
+		t.Fatalf("failed to login with magic link: %s", err)
+	}
+	verifyAccess(tokens, userID)
+	_, err = s.LoginWithMagicLink(magicToken)
+	if err != ErrInvalidToken {
+		t.Fatalf("expected ErrInvalidToken on reusing a magic link, got %v", err)
+	}
+
+	err = st.Users().SetBlocked(userID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Login("user@example.test", "password2")
+	if err != ErrUserBlocked {
+		t.Fatalf("expected ErrUserBlocked, got %v", err)
+	}
+}
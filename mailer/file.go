@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/satori/go.uuid"
+)
+
+// File is a mailer that writes messages to .eml files in a directory
+// instead of sending them. It is intended for testing.
+type File struct {
+	dir  string
+	from string
+}
+
+// NewFile returns a new file mailer.
+func NewFile(dir, from string) (*File, error) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, fmt.Errorf("failed to create a directory (%q): %v", dir, err)
+	}
+	return &File{dir: dir, from: from}, nil
+}
+
+// Send writes a message to a new file.
+func (m *File) Send(msg *Message) error {
+	data, err := msg.build(m.from)
+	if err != nil {
+		return err
+	}
+
+	name := time.Now().UTC().Format("20060102T150405") + "-" + uuid.NewV4().String() + ".eml"
+	path := filepath.Join(m.dir, name)
+	if err := ioutil.WriteFile(path, data, 0666); err != nil {
+		return fmt.Errorf("failed to write a message file (%q): %v", path, err)
+	}
+	return nil
+}
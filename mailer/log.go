@@ -0,0 +1,27 @@
+package mailer
+
+import (
+	"log"
+)
+
+// Log is a mailer that writes messages to a logger instead of sending them.
+// It is intended for testing and development.
+type Log struct {
+	logger *log.Logger
+	from   string
+}
+
+// NewLog returns a new log mailer.
+func NewLog(logger *log.Logger, from string) *Log {
+	return &Log{logger: logger, from: from}
+}
+
+// Send writes a message to the log.
+func (m *Log) Send(msg *Message) error {
+	data, err := msg.build(m.from)
+	if err != nil {
+		return err
+	}
+	m.logger.Printf("mail:\n%s", data)
+	return nil
+}
@@ -0,0 +1,49 @@
+// Package mailer provides a service that sends email messages
+// e.g. email verification and password reset links.
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Mailer sends email messages.
+type Mailer interface {
+	// Send sends a plain text message.
+	Send(msg *Message) error
+}
+
+// Message is a plain text email message.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// build formats the message as an RFC 5322 message with CRLF line endings.
+func (m *Message) build(from string) ([]byte, error) {
+	if _, err := mail.ParseAddress(m.To); err != nil {
+		return nil, fmt.Errorf("invalid recipient address %q: %v", m.To, err)
+	}
+	if strings.ContainsAny(m.Subject, "\r\n") {
+		return nil, fmt.Errorf("invalid subject: %q", m.Subject)
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "From: %s\r\n", from)
+	fmt.Fprintf(buf, "To: %s\r\n", m.To)
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(buf, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(buf, "Content-Transfer-Encoding: 8bit\r\n")
+	fmt.Fprintf(buf, "\r\n")
+	body := strings.Replace(m.Body, "\r\n", "\n", -1)
+	buf.WriteString(strings.Replace(body, "\n", "\r\n", -1))
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,10 @@
+package mailer
+
+// MockMailer is a mock implementation of mailer.Mailer
+type MockMailer struct {
+	OnSend func(msg *Message) error
+}
+
+func (m *MockMailer) Send(msg *Message) error {
+	return m.OnSend(msg)
+}
@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+)
+
+// SMTP is a mailer that sends messages through an SMTP server.
+type SMTP struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTP returns a new SMTP mailer. The address is "host:port".
+// If the username is empty, the messages are sent without authentication.
+// The server must support STARTTLS to authenticate.
+func NewSMTP(addr, username, password, from string) (*SMTP, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp address (%q): %v", addr, err)
+	}
+
+	if _, err := mail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("invalid sender address (%q): %v", from, err)
+	}
+
+	m := &SMTP{
+		addr: addr,
+		from: from,
+	}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m, nil
+}
+
+// Send sends a plain text message.
+func (m *SMTP) Send(msg *Message) error {
+	data, err := msg.build(m.from)
+	if err != nil {
+		return err
+	}
+
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+
+	err = smtp.SendMail(m.addr, m.auth, from.Address, []string{to.Address}, data)
+	if err != nil {
+		return fmt.Errorf("failed to send mail: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package oauth
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+
+	"github.com/disintegration/bebop/session"
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/mock"
+)
+
+func TestOAuthLink(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "provider-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer tokenServer.Close()
+
+	// The link codes are single-use and bound to a state: the code the API
+	// sets in the cookie is replaced with a new one bound to the flow state.
+	type linkCode struct {
+		userID int64
+		state  string
+	}
+	codes := map[string]linkCode{}
+	sessionService := &session.MockService{
+		OnCreateLinkCode: func(userID int64, state string) (string, error) {
+			code := "cookie-code-" + strconv.Itoa(len(codes))
+			codes[code] = linkCode{userID, state}
+			return code, nil
+		},
+		OnUseLinkCode: func(code, state string) (int64, error) {
+			c, ok := codes[code]
+			if !ok || c.state != state {
+				return 0, session.ErrInvalidCode
+			}
+			delete(codes, code)
+			if c.userID == 2 {
+				return 0, session.ErrUserBlocked
+			}
+			return c.userID, nil
+		},
+	}
+
+	var linked []string
+
+	handler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		UserStore: &mock.UserStore{
+			OnGet: func(id int64) (*store.User, error) {
+				switch id {
+				case 1:
+					return &store.User{ID: 1}, nil
+				case 2:
+					return &store.User{ID: 2, Blocked: true}, nil
+				}
+				return nil, store.ErrNotFound
+			},
+			OnGetByAuth: func(authService string, authID string) (*store.User, error) {
+				if authService == "testprovider" && authID == "owned" {
+					return &store.User{ID: 1}, nil
+				}
+				if authService == "testprovider" && authID == "taken" {
+					return &store.User{ID: 3}, nil
+				}
+				return nil, store.ErrNotFound
+			},
+		},
+		IdentityStore: &mock.IdentityStore{
+			OnNew: func(userID int64, authService, authID string) (int64, error) {
+				linked = append(linked, authService+":"+authID)
+				return 1, nil
+			},
+			OnSetProfile: func(authService, authID, displayName, picture string) error {
+				return nil
+			},
+		},
+		SessionService: sessionService,
+		MountURL:       "https://example.test/forum/oauth",
+		CookiePath:     "/forum/",
+		AppURL:         "https://example.test/forum/",
+	})
+
+	var providerUserID string
+	handler.providers = map[string]*provider{
+		"testprovider": {
+			config: &oauth2.Config{
+				ClientID:     "test-client-id",
+				ClientSecret: "test-client-secret",
+				Endpoint: oauth2.Endpoint{
+					AuthURL:  "https://provider.test/auth",
+					TokenURL: tokenServer.URL,
+				},
+				RedirectURL: "https://example.test/forum/oauth/end/testprovider",
+			},
+			getUser: func(*http.Client) (*user, error) {
+				return &user{id: providerUserID}, nil
+			},
+		},
+	}
+
+	tests := []struct {
+		desc   string
+		userID int64
+		// codeInURL passes the API link code in the URL instead of the cookie.
+		codeInURL bool
+		// otherState ends the flow with the state of another flow.
+		otherState bool
+		identity   string
+		wantResult string
+		wantLinked []string
+	}{
+		{
+			desc:       "link new identity",
+			userID:     1,
+			identity:   "new",
+			wantResult: "linked=testprovider",
+			wantLinked: []string{"testprovider:new"},
+		},
+		{
+			desc:       "link own identity",
+			userID:     1,
+			identity:   "owned",
+			wantResult: "linked=testprovider",
+		},
+		{
+			desc:       "link taken identity",
+			userID:     1,
+			identity:   "taken",
+			wantResult: "error=IdentityTaken",
+		},
+		{
+			desc:       "blocked user",
+			userID:     2,
+			identity:   "new",
+			wantResult: "error=Unauthorized",
+		},
+		{
+			desc:       "bad code",
+			identity:   "new",
+			wantResult: "error=Unauthorized",
+		},
+		{
+			desc:       "code in url",
+			userID:     1,
+			codeInURL:  true,
+			identity:   "new",
+			wantResult: "error=Unauthorized",
+		},
+		{
+			desc:       "other state",
+			userID:     1,
+			otherState: true,
+			identity:   "new",
+			wantResult: "error=Unauthorized",
+		},
+	}
+
+	for _, tc := range tests {
+		linked = nil
+		providerUserID = tc.identity
+
+		code := "BAD"
+		if tc.userID != 0 {
+			code = "api-code"
+			codes[code] = linkCode{userID: tc.userID}
+		}
+
+		beginURL := "/begin/testprovider?link=1"
+		if tc.codeInURL {
+			beginURL = "/begin/testprovider?link=" + code
+		}
+		req, err := http.NewRequest("GET", beginURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tc.codeInURL {
+			req.AddCookie(&http.Cookie{Name: linkCookie, Value: code})
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		cookies := map[string]*http.Cookie{}
+		for _, c := range w.Result().Cookies() {
+			cookies[c.Name] = c
+		}
+
+		resultPrefix := "https://example.test/forum/#/auth/oauth?"
+		if loc := w.Header().Get("Location"); strings.HasPrefix(loc, resultPrefix) {
+			if loc != resultPrefix+tc.wantResult {
+				t.Fatalf("test %q: begin: want result %q got %q", tc.desc, tc.wantResult, loc)
+			}
+			if _, ok := codes[code]; tc.codeInURL && !ok {
+				t.Fatalf("test %q: begin: the link code from the url is used", tc.desc)
+			}
+			delete(codes, code)
+			continue
+		}
+
+		state := cookies[stateCookie].Value
+		if c := cookies[linkCookie]; c == nil || codes[c.Value] != (linkCode{tc.userID, state}) || !c.HttpOnly {
+			t.Fatalf("test %q: begin: bad link cookie: %v", tc.desc, c)
+		}
+		if _, ok := codes[code]; ok {
+			t.Fatalf("test %q: begin: the link code is not used", tc.desc)
+		}
+		if tc.otherState {
+			state = "other-state"
+			cookies[stateCookie].Value = state
+		}
+
+		req, err = http.NewRequest("GET", "/end/testprovider?code=code&state="+state, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.AddCookie(cookies[stateCookie])
+		req.AddCookie(cookies[linkCookie])
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		cookies = map[string]*http.Cookie{}
+		for _, c := range w.Result().Cookies() {
+			cookies[c.Name] = c
+		}
+		if loc := w.Header().Get("Location"); loc != resultPrefix+tc.wantResult {
+			t.Fatalf("test %q: end: want result %q got %q", tc.desc, tc.wantResult, loc)
+		}
+		if c := cookies[linkCookie]; c == nil || c.MaxAge >= 0 {
+			t.Fatalf("test %q: end: link cookie is not removed: %v", tc.desc, c)
+		}
+		if strings.Join(linked, ",") != strings.Join(tc.wantLinked, ",") {
+			t.Fatalf("test %q: want linked %v got %v", tc.desc, tc.wantLinked, linked)
+		}
+	}
+}
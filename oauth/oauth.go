@@ -4,9 +4,13 @@ package oauth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"runtime"
 	"strings"
 	"time"
@@ -15,23 +19,40 @@ import (
 	"github.com/satori/go.uuid"
 	"golang.org/x/oauth2"
 
-	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/avatar"
+	"github.com/disintegration/bebop/safehttp"
+	"github.com/disintegration/bebop/session"
 	"github.com/disintegration/bebop/store"
 )
 
 const (
-	stateCookie   = "bebop_oauth_state"
-	resultCookie  = "bebop_oauth_result"
-	clientTimeout = 10 * time.Second
+	stateCookie     = "bebop_oauth_state"
+	linkCookie      = "bebop_oauth_link"
+	challengeCookie = "bebop_oauth_challenge"
+	verifierCookie  = "bebop_oauth_verifier"
+	clientTimeout   = 10 * time.Second
+
+	avatarImportMaxBytes = 5 * 1024 * 1024
 )
 
 // Config is a configuration of an OAuth handler.
 type Config struct {
-	Logger     *log.Logger
-	UserStore  store.UserStore
-	JWTService jwt.Service
-	MountURL   string
-	CookiePath string
+	Logger        *log.Logger
+	UserStore     store.UserStore
+	IdentityStore store.IdentityStore
+	// SessionService issues the one-time codes the app exchanges for a session
+	// and checks the link codes of the users linking a new identity.
+	SessionService session.Service
+	// AvatarService saves the provider pictures of new users if ImportAvatars is set.
+	AvatarService avatar.Service
+	ImportAvatars bool
+	// AvatarClient downloads the provider pictures. If it is nil, a client
+	// that refuses to connect to internal network addresses is used.
+	AvatarClient *http.Client
+	MountURL     string
+	CookiePath   string
+	// AppURL is the URL of the web app the users are redirected to at the end.
+	AppURL string
 }
 
 // Handler handles oauth2 authentication requests.
@@ -54,34 +75,50 @@ func New(config *Config) *Handler {
 	return h
 }
 
-// AddProvider adds a new provider to oauth handler.
-func (h *Handler) AddProvider(name, id, secret string) error {
+// ProviderConfig is a configuration of a built-in OAuth provider.
+type ProviderConfig struct {
+	ClientID string
+	Secret   string
+	// BaseURL is the URL of a self-hosted GitLab instance, "https://gitlab.com" by default.
+	BaseURL string
+	// Tenant is the Microsoft Azure AD tenant: "common" (default),
+	// "organizations", "consumers" or a tenant ID.
+	Tenant string
+}
+
+// AddProvider adds a new built-in provider to oauth handler.
+func (h *Handler) AddProvider(name string, cfg *ProviderConfig) error {
 	pc, ok := providerConfigs[name]
 	if !ok {
 		return fmt.Errorf("oauth: unknown provider: %q", name)
 	}
 
-	if id == "" {
+	if cfg.ClientID == "" {
 		return fmt.Errorf("oauth: empty client id of provider %q", name)
 	}
 
-	if secret == "" {
+	if cfg.Secret == "" {
 		return fmt.Errorf("oauth: empty client secret of provider %q", name)
 	}
 
+	if pc.configure != nil {
+		pc = pc.configure(pc, cfg)
+	}
+
 	if h.providers == nil {
 		h.providers = make(map[string]*provider)
 	}
 
 	h.providers[name] = &provider{
 		config: &oauth2.Config{
-			ClientID:     id,
-			ClientSecret: secret,
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.Secret,
 			RedirectURL:  h.MountURL + "/end/" + name,
 			Endpoint:     pc.endpoint,
 			Scopes:       pc.scopes,
 		},
 		getUser: pc.getUser,
+		pkce:    pc.pkce,
 	}
 
 	return nil
@@ -91,6 +128,11 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.router.ServeHTTP(w, r)
 }
 
+// handleBegin redirects to the provider login page. The app passes
+// the PKCE code challenge of the one-time code it receives at the end.
+// Given the "link" parameter, the provider identity is linked at the end instead of
+// signing in. The user is taken from the link cookie the API sets for the signed in user,
+// so the link can not be started from a URL opened in another browser.
 func (h *Handler) handleBegin(w http.ResponseWriter, r *http.Request) {
 	providerName := chi.URLParam(r, "provider")
 	provider, ok := h.providers[providerName]
@@ -99,17 +141,70 @@ func (h *Handler) handleBegin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	query := r.URL.Query()
+
 	state := h.genState()
-	redirectURL := provider.config.AuthCodeURL(state)
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     stateCookie,
-		Value:    state,
-		Path:     h.CookiePath,
-		HttpOnly: true,
-		Secure:   strings.HasPrefix(h.MountURL, "https"),
-		MaxAge:   1 * 60 * 60,
-	})
+	// The link code from the API cookie is used up here. A new one bound
+	// to the state is kept in the cookie until the end of the flow and is used there.
+	// The cookie is removed if the flow is not a link.
+	var linkCode string
+	challenge := query.Get("code_challenge")
+	if query.Get("link") != "" {
+		var code string
+		if c, err := r.Cookie(linkCookie); err == nil {
+			code = c.Value
+		}
+		if code == "" {
+			h.redirectResult(w, r, url.Values{"error": {"Unauthorized"}})
+			return
+		}
+		userID, err := h.SessionService.UseLinkCode(code, "")
+		if err != nil {
+			if err != session.ErrInvalidCode && err != session.ErrUserBlocked {
+				h.Logger.Printf("ERROR: use link code: %s", err)
+			}
+			h.redirectResult(w, r, url.Values{"error": {"Unauthorized"}})
+			return
+		}
+		linkCode, err = h.SessionService.CreateLinkCode(userID, state)
+		if err != nil {
+			h.handleError(w, r, "failed to create link code: %s", err)
+			return
+		}
+		challenge = ""
+	} else if !validCodeChallenge(challenge, query.Get("code_challenge_method")) {
+		h.redirectResult(w, r, url.Values{"error": {"BadRequest"}})
+		return
+	}
+
+	var opts []oauth2.AuthCodeOption
+	if provider.oidc != nil {
+		// The state is kept in a cookie, so it binds the ID token
+		// to the browser session when used as the nonce.
+		opts = append(opts, oauth2.SetAuthURLParam("nonce", state))
+	}
+
+	var verifier string
+	if provider.pkce {
+		var err error
+		verifier, err = genCodeVerifier()
+		if err != nil {
+			h.handleError(w, r, "failed to generate code verifier: %s", err)
+			return
+		}
+		opts = append(opts,
+			oauth2.SetAuthURLParam("code_challenge", codeChallenge(verifier)),
+			oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+		)
+	}
+
+	redirectURL := provider.config.AuthCodeURL(state, opts...)
+
+	h.setCookie(w, stateCookie, state)
+	h.setCookie(w, linkCookie, linkCode)
+	h.setCookie(w, challengeCookie, challenge)
+	h.setCookie(w, verifierCookie, verifier)
 
 	http.Redirect(w, r, redirectURL, http.StatusFound)
 }
@@ -122,116 +217,250 @@ func (h *Handler) handleEnd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cookie, err := r.Cookie(stateCookie)
-	if err != nil {
-		h.handleError(w, "failed to get oauth state cookie: %s", err)
-		return
+	cookies := make(map[string]string)
+	for _, name := range []string{stateCookie, linkCookie, challengeCookie, verifierCookie} {
+		if c, err := r.Cookie(name); err == nil {
+			cookies[name] = c.Value
+		}
+		h.setCookie(w, name, "")
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     stateCookie,
-		Value:    "",
-		Path:     h.CookiePath,
-		HttpOnly: true,
-		MaxAge:   -1,
-	})
-
-	state := cookie.Value
+	state := cookies[stateCookie]
 	if state == "" {
-		h.handleError(w, "empty oauth state cookie")
+		h.handleError(w, r, "empty oauth state cookie")
 		return
 	}
 
 	queryState := r.URL.Query().Get("state")
 	if queryState != state {
-		h.handleError(w, "bad state value")
+		h.handleError(w, r, "bad state value")
 		return
 	}
 
 	queryCode := r.URL.Query().Get("code")
 	if queryCode == "" {
-		h.handleError(w, "empty code value")
+		h.handleError(w, r, "empty code value")
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), clientTimeout)
 	defer cancel()
 
-	token, err := provider.config.Exchange(ctx, queryCode)
+	exchangeCtx := ctx
+	if provider.pkce {
+		if cookies[verifierCookie] == "" {
+			h.handleError(w, r, "empty code verifier cookie")
+			return
+		}
+		exchangeCtx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{
+			Transport: &pkceTransport{verifier: cookies[verifierCookie], base: http.DefaultTransport},
+		})
+	}
+
+	token, err := provider.config.Exchange(exchangeCtx, queryCode)
 	if err != nil {
-		h.handleError(w, "exchange failed: %s", err)
+		h.handleError(w, r, "exchange failed: %s", err)
 		return
 	}
 	if !token.Valid() {
-		h.handleError(w, "invalid token")
+		h.handleError(w, r, "invalid token")
 		return
 	}
 
-	u, err := provider.getUser(provider.config.Client(ctx, token))
+	var u *user
+	if provider.oidc != nil {
+		u, err = provider.oidc.getUser(&http.Client{Timeout: clientTimeout}, token, state)
+	} else {
+		u, err = provider.getUser(provider.config.Client(ctx, token))
+	}
 	if err != nil {
-		h.handleError(w, "get provider user: %s", err)
+		h.handleError(w, r, "get provider user: %s", err)
 		return
 	}
 
 	if u.id == "" {
-		h.handleError(w, "provider user id is empty")
+		h.handleError(w, r, "provider user id is empty")
 		return
 	}
 
-	var authToken string
+	if cookies[linkCookie] != "" {
+		h.linkIdentity(w, r, cookies[linkCookie], state, providerName, u)
+		return
+	}
+
+	challenge := cookies[challengeCookie]
+	if challenge == "" {
+		h.handleError(w, r, "empty code challenge cookie")
+		return
+	}
+
+	var userID int64
 
 	user, err := h.UserStore.GetByAuth(providerName, u.id)
 	switch err {
 	case nil:
 		if user.Blocked {
-			h.renderOAuthResult(w, "error:UserBlocked")
+			h.redirectResult(w, r, url.Values{"error": {"UserBlocked"}})
 			return
 		}
 
-		authToken, err = h.JWTService.Create(user.ID)
+		h.saveProfile(providerName, u)
+
+		userID = user.ID
+
+	case store.ErrNotFound:
+		userID, err = h.UserStore.New(providerName, u.id)
 		if err != nil {
-			h.handleError(w, "failed to create auth token: %s", err)
+			h.handleError(w, r, "failed to create user: %s", err)
 			return
 		}
 
-	case store.ErrNotFound:
-		userID, err := h.UserStore.New(providerName, u.id)
-		if err != nil {
-			h.handleError(w, "failed to create user: %s", err)
+		h.saveProfile(providerName, u)
+
+		if h.ImportAvatars && u.picture != "" {
+			err = h.importAvatar(userID, u.picture)
+			if err != nil {
+				h.Logger.Printf("ERROR: importAvatar: %s", err)
+			}
+		}
+
+	default:
+		h.handleError(w, r, "failed to get user by auth: %s", err)
+		return
+	}
+
+	code, err := h.SessionService.CreateCode(userID, challenge)
+	if err != nil {
+		h.handleError(w, r, "failed to create one-time code: %s", err)
+		return
+	}
+
+	h.redirectResult(w, r, url.Values{"code": {code}})
+}
+
+// linkIdentity links the provider identity to the user of the given link code.
+// The code must be bound to the state of the current flow.
+func (h *Handler) linkIdentity(w http.ResponseWriter, r *http.Request, linkCode, state, providerName string, u *user) {
+	userID, err := h.SessionService.UseLinkCode(linkCode, state)
+	if err != nil {
+		if err != session.ErrInvalidCode && err != session.ErrUserBlocked {
+			h.Logger.Printf("ERROR: use link code: %s", err)
+		}
+		h.redirectResult(w, r, url.Values{"error": {"Unauthorized"}})
+		return
+	}
+
+	owner, err := h.UserStore.GetByAuth(providerName, u.id)
+	switch err {
+	case nil:
+		if owner.ID != userID {
+			h.redirectResult(w, r, url.Values{"error": {"IdentityTaken"}})
 			return
 		}
 
-		authToken, err = h.JWTService.Create(userID)
+	case store.ErrNotFound:
+		_, err = h.IdentityStore.New(userID, providerName, u.id)
+		if err == store.ErrConflict {
+			h.redirectResult(w, r, url.Values{"error": {"IdentityTaken"}})
+			return
+		}
 		if err != nil {
-			h.handleError(w, "failed to create auth token: %s", err)
+			h.handleError(w, r, "failed to link identity: %s", err)
 			return
 		}
 
 	default:
-		h.handleError(w, "failed to get user by auth: %s", err)
+		h.handleError(w, r, "failed to get user by auth: %s", err)
 		return
 	}
 
-	h.renderOAuthResult(w, "success:"+authToken)
+	h.saveProfile(providerName, u)
+
+	h.redirectResult(w, r, url.Values{"linked": {providerName}})
+}
+
+// saveProfile saves the provider profile data of the identity.
+// The profile data is not required to sign in, so the errors are only logged.
+func (h *Handler) saveProfile(providerName string, u *user) {
+	err := h.IdentityStore.SetProfile(providerName, u.id, u.name, u.picture)
+	if err != nil {
+		h.Logger.Printf("ERROR: saveProfile: %s", err)
+	}
+}
+
+// importAvatar downloads the provider picture and saves it as the user avatar.
+func (h *Handler) importAvatar(userID int64, pictureURL string) error {
+	if !strings.HasPrefix(pictureURL, "https://") && !strings.HasPrefix(pictureURL, "http://") {
+		return fmt.Errorf("unsupported picture url: %q", pictureURL)
+	}
+
+	c := h.AvatarClient
+	if c == nil {
+		c = safehttp.NewClient(clientTimeout)
+	}
+	response, err := c.Get(pictureURL)
+	if err != nil {
+		return fmt.Errorf("request failed: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("bad request status code: %v", response.StatusCode)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(response.Body, avatarImportMaxBytes+1))
+	if err != nil {
+		return fmt.Errorf("failed to read picture: %v", err)
+	}
+	if len(data) > avatarImportMaxBytes {
+		return errors.New("picture is too large")
+	}
+
+	user, err := h.UserStore.Get(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %v", err)
+	}
+
+	return h.AvatarService.Save(user, data)
+}
+
+// setCookie sets a cookie used during the flow.
+// An empty value removes the cookie.
+// SetLinkCookie sets the cookie with the link code issued by the API to the signed in user.
+// The cookie starts linking a login provider identity when the user begins an OAuth flow
+// with the "link" parameter.
+func (h *Handler) SetLinkCookie(w http.ResponseWriter, code string) {
+	h.setCookie(w, linkCookie, code)
 }
 
-func (h *Handler) renderOAuthResult(w http.ResponseWriter, message string) {
+func (h *Handler) setCookie(w http.ResponseWriter, name, value string) {
+	maxAge := 1 * 60 * 60
+	if value == "" {
+		maxAge = -1
+	}
 	http.SetCookie(w, &http.Cookie{
-		Name:   resultCookie,
-		Value:  message,
-		Path:   h.CookiePath,
-		Secure: strings.HasPrefix(h.MountURL, "https"),
-		MaxAge: 10 * 60,
+		Name:     name,
+		Value:    value,
+		Path:     h.CookiePath,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(h.MountURL, "https"),
+		MaxAge:   maxAge,
 	})
-	fmt.Fprint(w, `<!doctype html><title>OAuth</title><script>try {opener.bebopOAuthEnd()} finally {window.close()}</script>`)
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, format string, a ...interface{}) {
+// redirectResult redirects to the app page that completes the flow.
+// The result is passed in the URL fragment, so it is not sent to the server:
+// a one-time code exchanged with the PKCE code verifier, "linked" or "error".
+func (h *Handler) redirectResult(w http.ResponseWriter, r *http.Request, result url.Values) {
+	http.Redirect(w, r, h.AppURL+"#/auth/oauth?"+result.Encode(), http.StatusFound)
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, format string, a ...interface{}) {
 	pc, _, _, _ := runtime.Caller(1)
 	callerNameSplit := strings.Split(runtime.FuncForPC(pc).Name(), ".")
 	funcName := callerNameSplit[len(callerNameSplit)-1]
 	h.Logger.Printf("ERROR: %s: %s", funcName, fmt.Sprintf(format, a...))
-	h.renderOAuthResult(w, "error:Other")
+	h.redirectResult(w, r, url.Values{"error": {"Other"}})
 }
 
 func (h *Handler) genState() string {
@@ -1,6 +1,7 @@
 package oauth
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -10,13 +11,21 @@ import (
 	"testing"
 
 	"golang.org/x/oauth2"
+
+	"github.com/disintegration/bebop/session"
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/mock"
 )
 
+// testCodeChallenge is the app PKCE code challenge used in the tests.
+const testCodeChallenge = "JAcpNsWWU7Oms5b8Gn1uZ7OoujAQRAXzmsZ9LtJhcoY"
+
 func TestOAuthBegin(t *testing.T) {
 	handler := New(&Config{
 		Logger:     log.New(ioutil.Discard, "", 0),
 		MountURL:   "https://example.test/forum/oauth",
 		CookiePath: "/forum/",
+		AppURL:     "https://example.test/forum/",
 	})
 	handler.providers = map[string]*provider{
 		"testprovider": {
@@ -31,30 +40,62 @@ func TestOAuthBegin(t *testing.T) {
 				Scopes:      []string{"testscope1", "testscope2"},
 			},
 		},
+		"pkceprovider": {
+			config: &oauth2.Config{
+				ClientID:     "test-client-id",
+				ClientSecret: "test-client-secret",
+				Endpoint: oauth2.Endpoint{
+					AuthURL:  "https://provider.test/auth",
+					TokenURL: "https://provider.test/token",
+				},
+				RedirectURL: "https://example.test/forum/oauth/end/pkceprovider",
+			},
+			pkce: true,
+		},
 	}
 
+	challenge := "?code_challenge=" + testCodeChallenge + "&code_challenge_method=S256"
+
 	tests := []struct {
 		desc          string
-		provider      string
+		url           string
 		wantCode      int
+		wantLocation  string
 		checkLocation bool
 	}{
 		{
 			desc:          "test provider",
-			provider:      "testprovider",
+			url:           "/begin/testprovider" + challenge,
 			wantCode:      http.StatusFound,
 			checkLocation: true,
 		},
 		{
-			desc:          "unknown provider",
-			provider:      "unknownprovider",
-			wantCode:      http.StatusNotFound,
-			checkLocation: false,
+			desc:          "pkce provider",
+			url:           "/begin/pkceprovider" + challenge,
+			wantCode:      http.StatusFound,
+			checkLocation: true,
+		},
+		{
+			desc:         "no code challenge",
+			url:          "/begin/testprovider",
+			wantCode:     http.StatusFound,
+			wantLocation: "https://example.test/forum/#/auth/oauth?error=BadRequest",
+		},
+		{
+			desc:         "plain code challenge",
+			url:          "/begin/testprovider?code_challenge=" + testCodeChallenge + "&code_challenge_method=plain",
+			wantCode:     http.StatusFound,
+			wantLocation: "https://example.test/forum/#/auth/oauth?error=BadRequest",
+		},
+		{
+			desc:     "unknown provider",
+			url:      "/begin/unknownprovider" + challenge,
+			wantCode: http.StatusNotFound,
 		},
 	}
 
 	for _, tc := range tests {
-		req, err := http.NewRequest("GET", "/begin/"+tc.provider, nil)
+		req, err := http.NewRequest("GET", tc.url, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -66,17 +107,30 @@ func TestOAuthBegin(t *testing.T) {
 			t.Fatalf("test %q: want status code %d got %d", tc.desc, tc.wantCode, w.Code)
 		}
 
+		if tc.wantLocation != "" && tc.wantLocation != w.Header().Get("Location") {
+			t.Fatalf("test %q: want location %q got %q", tc.desc, tc.wantLocation, w.Header().Get("Location"))
+		}
+
 		if !tc.checkLocation {
 			continue
 		}
 
+		cookies := map[string]*http.Cookie{}
+		for _, c := range w.Result().Cookies() {
+			cookies[c.Name] = c
+		}
+		if c := cookies[challengeCookie]; c == nil || c.Value != testCodeChallenge || !c.HttpOnly {
+			t.Fatalf("test %q: bad challenge cookie: %v", tc.desc, c)
+		}
+
+		provider := strings.TrimPrefix(req.URL.Path, "/begin/")
 		loc := w.Header().Get("Location")
 		locURL, err := url.Parse(loc)
 		if err != nil {
 			t.Fatal(err)
 		}
 
-		wantPrefix := handler.providers[tc.provider].config.Endpoint.AuthURL
+		wantPrefix := handler.providers[provider].config.Endpoint.AuthURL
 		if !strings.HasPrefix(loc, wantPrefix) {
 			t.Fatalf("test %q: bad location prefix: location: %q, want prefix: %q", tc.desc, loc, wantPrefix)
 		}
@@ -87,9 +141,161 @@ func TestOAuthBegin(t *testing.T) {
 		}
 
 		redirectURI := locURL.Query().Get("redirect_uri")
-		wantRedirectURI := handler.providers[tc.provider].config.RedirectURL
+		wantRedirectURI := handler.providers[provider].config.RedirectURL
 		if redirectURI != wantRedirectURI {
 			t.Fatalf("test %q: bad location redirect URI: want %q got %q", tc.desc, wantRedirectURI, redirectURI)
 		}
+
+		verifier := cookies[verifierCookie]
+		if !handler.providers[provider].pkce {
+			if locURL.Query().Get("code_challenge") != "" || verifier == nil || verifier.MaxAge >= 0 {
+				t.Fatalf("test %q: unexpected provider PKCE: %q, %v", tc.desc, loc, verifier)
+			}
+			continue
+		}
+		if verifier == nil || verifier.Value == "" ||
+			locURL.Query().Get("code_challenge") != codeChallenge(verifier.Value) ||
+			locURL.Query().Get("code_challenge_method") != "S256" {
+			t.Fatalf("test %q: bad provider PKCE: %q, %v", tc.desc, loc, verifier)
+		}
+	}
+}
+
+func TestOAuthEnd(t *testing.T) {
+	var tokenRequest url.Values
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		tokenRequest = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "provider-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	defer tokenServer.Close()
+
+	var gotChallenge string
+
+	handler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		UserStore: &mock.UserStore{
+			OnGetByAuth: func(authService string, authID string) (*store.User, error) {
+				switch authID {
+				case "user1":
+					return &store.User{ID: 1}, nil
+				case "user2":
+					return &store.User{ID: 2, Blocked: true}, nil
+				}
+				return nil, store.ErrNotFound
+			},
+		},
+		IdentityStore: &mock.IdentityStore{
+			OnSetProfile: func(authService, authID, displayName, picture string) error {
+				return nil
+			},
+		},
+		SessionService: &session.MockService{
+			OnCreateCode: func(userID int64, codeChallenge string) (string, error) {
+				gotChallenge = codeChallenge
+				return "one-time-code", nil
+			},
+		},
+		MountURL:   "https://example.test/forum/oauth",
+		CookiePath: "/forum/",
+		AppURL:     "https://example.test/forum/",
+	})
+
+	var providerUserID string
+	handler.providers = map[string]*provider{
+		"pkceprovider": {
+			config: &oauth2.Config{
+				ClientID:     "test-client-id",
+				ClientSecret: "test-client-secret",
+				Endpoint: oauth2.Endpoint{
+					AuthURL:  "https://provider.test/auth",
+					TokenURL: tokenServer.URL,
+				},
+				RedirectURL: "https://example.test/forum/oauth/end/pkceprovider",
+			},
+			getUser: func(*http.Client) (*user, error) {
+				return &user{id: providerUserID}, nil
+			},
+			pkce: true,
+		},
+	}
+
+	tests := []struct {
+		desc         string
+		userID       string
+		state        string
+		wantLocation string
+	}{
+		{
+			desc:         "sign in",
+			userID:       "user1",
+			wantLocation: "https://example.test/forum/#/auth/oauth?code=one-time-code",
+		},
+		{
+			desc:         "blocked user",
+			userID:       "user2",
+			wantLocation: "https://example.test/forum/#/auth/oauth?error=UserBlocked",
+		},
+		{
+			desc:         "bad state",
+			userID:       "user1",
+			state:        "BAD",
+			wantLocation: "https://example.test/forum/#/auth/oauth?error=Other",
+		},
+	}
+
+	for _, tc := range tests {
+		tokenRequest, gotChallenge = nil, ""
+		providerUserID = tc.userID
+
+		req, err := http.NewRequest("GET", "/begin/pkceprovider?code_challenge="+testCodeChallenge+"&code_challenge_method=S256", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		cookies := map[string]*http.Cookie{}
+		for _, c := range w.Result().Cookies() {
+			cookies[c.Name] = c
+		}
+		state := tc.state
+		if state == "" {
+			state = cookies[stateCookie].Value
+		}
+
+		req, err = http.NewRequest("GET", "/end/pkceprovider?code=code&state="+state, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusFound || w.Header().Get("Location") != tc.wantLocation {
+			t.Fatalf("test %q: want location %q got %d %q", tc.desc, tc.wantLocation, w.Code, w.Header().Get("Location"))
+		}
+		for _, c := range w.Result().Cookies() {
+			if c.MaxAge >= 0 {
+				t.Fatalf("test %q: cookie %q is not removed", tc.desc, c.Name)
+			}
+		}
+
+		if tc.state != "" {
+			continue
+		}
+		if got, want := tokenRequest.Get("code_verifier"), cookies[verifierCookie].Value; got != want {
+			t.Fatalf("test %q: want provider code verifier %q got %q", tc.desc, want, got)
+		}
+		if tc.wantLocation == "https://example.test/forum/#/auth/oauth?code=one-time-code" && gotChallenge != testCodeChallenge {
+			t.Fatalf("test %q: want code challenge %q got %q", tc.desc, testCodeChallenge, gotChallenge)
+		}
 	}
 }
@@ -0,0 +1,356 @@
+package oauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"gopkg.in/dgrijalva/jwt-go.v3"
+)
+
+const (
+	oidcDefaultIDClaim   = "sub"
+	oidcDefaultNameClaim = "name"
+
+	// oidcKeysMinRefresh limits how often the provider keys are refetched
+	// when an ID token is signed with an unknown key.
+	oidcKeysMinRefresh = time.Minute
+)
+
+var providerNameRE = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// OIDCConfig is a configuration of a generic OpenID Connect provider.
+type OIDCConfig struct {
+	// Issuer is the provider issuer URL. The provider endpoints are discovered
+	// from Issuer + "/.well-known/openid-configuration".
+	Issuer   string
+	ClientID string
+	Secret   string
+	// Scopes are requested in addition to the "openid" scope.
+	Scopes []string
+	// IDClaim is the ID token claim used as the user ID, "sub" by default.
+	IDClaim string
+	// NameClaim is the ID token claim used as the user display name, "name" by default.
+	NameClaim string
+}
+
+// AddOIDCProvider adds a new OpenID Connect provider to oauth handler.
+// It fetches the provider configuration from the issuer.
+func (h *Handler) AddOIDCProvider(name string, cfg *OIDCConfig) error {
+	if !providerNameRE.MatchString(name) {
+		return fmt.Errorf("oauth: invalid provider name: %q", name)
+	}
+
+	if _, ok := providerConfigs[name]; ok {
+		return fmt.Errorf("oauth: provider name %q is reserved", name)
+	}
+
+	if _, ok := h.providers[name]; ok {
+		return fmt.Errorf("oauth: duplicate provider %q", name)
+	}
+
+	if cfg.Issuer == "" {
+		return fmt.Errorf("oauth: empty issuer of provider %q", name)
+	}
+
+	if cfg.ClientID == "" {
+		return fmt.Errorf("oauth: empty client id of provider %q", name)
+	}
+
+	if cfg.Secret == "" {
+		return fmt.Errorf("oauth: empty client secret of provider %q", name)
+	}
+
+	p, err := discoverOIDC(&http.Client{Timeout: clientTimeout}, cfg.Issuer)
+	if err != nil {
+		return fmt.Errorf("oauth: provider %q: %s", name, err)
+	}
+
+	p.clientID = cfg.ClientID
+	p.idClaim = cfg.IDClaim
+	if p.idClaim == "" {
+		p.idClaim = oidcDefaultIDClaim
+	}
+	p.nameClaim = cfg.NameClaim
+	if p.nameClaim == "" {
+		p.nameClaim = oidcDefaultNameClaim
+	}
+
+	scopes := []string{"openid"}
+	for _, s := range cfg.Scopes {
+		if s != "openid" {
+			scopes = append(scopes, s)
+		}
+	}
+
+	if h.providers == nil {
+		h.providers = make(map[string]*provider)
+	}
+
+	h.providers[name] = &provider{
+		config: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.Secret,
+			RedirectURL:  h.MountURL + "/end/" + name,
+			Endpoint:     p.endpoint,
+			Scopes:       scopes,
+		},
+		oidc: p,
+		pkce: p.pkce,
+	}
+
+	return nil
+}
+
+// oidcProvider validates the ID tokens of an OpenID Connect provider.
+type oidcProvider struct {
+	issuer    string
+	endpoint  oauth2.Endpoint
+	jwksURL   string
+	clientID  string
+	idClaim   string
+	nameClaim string
+	pkce      bool
+
+	mu          sync.Mutex
+	keys        map[string]interface{}
+	keysFetched time.Time
+}
+
+// discoverOIDC fetches the provider configuration from the issuer.
+func discoverOIDC(c *http.Client, issuer string) (*oidcProvider, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	d := struct {
+		Issuer                        string   `json:"issuer"`
+		AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+		TokenEndpoint                 string   `json:"token_endpoint"`
+		JWKSURI                       string   `json:"jwks_uri"`
+		CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+	}{}
+
+	err := getJSON(c, issuer+"/.well-known/openid-configuration", &d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover provider configuration: %s", err)
+	}
+
+	if strings.TrimSuffix(d.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("issuer mismatch: configured %q, discovered %q", issuer, d.Issuer)
+	}
+
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("incomplete provider configuration")
+	}
+
+	pkce := false
+	for _, m := range d.CodeChallengeMethodsSupported {
+		if m == "S256" {
+			pkce = true
+		}
+	}
+
+	return &oidcProvider{
+		issuer: d.Issuer,
+		endpoint: oauth2.Endpoint{
+			AuthURL:  d.AuthorizationEndpoint,
+			TokenURL: d.TokenEndpoint,
+		},
+		jwksURL: d.JWKSURI,
+		pkce:    pkce,
+	}, nil
+}
+
+// getUser validates the ID token received along with the access token
+// and reads the user from its claims.
+func (p *oidcProvider) getUser(c *http.Client, token *oauth2.Token, nonce string) (*user, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("no id token in the token response")
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("unexpected signing method: %s", t.Method.Alg())
+		}
+		kid, _ := t.Header["kid"].(string)
+		return p.getKey(c, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %s", err)
+	}
+
+	if iss, _ := claims["iss"].(string); iss != p.issuer {
+		return nil, fmt.Errorf("invalid id token issuer: %q", iss)
+	}
+
+	if !p.validAudience(claims["aud"]) {
+		return nil, errors.New("invalid id token audience")
+	}
+
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("id token has no expiration time")
+	}
+
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, errors.New("invalid id token nonce")
+	}
+
+	return &user{
+		id:      claimString(claims[p.idClaim]),
+		name:    claimString(claims[p.nameClaim]),
+		picture: claimString(claims["picture"]),
+	}, nil
+}
+
+func (p *oidcProvider) validAudience(aud interface{}) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == p.clientID
+	case []interface{}:
+		for _, a := range aud {
+			if a == p.clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// claimString converts a string or number claim to a string.
+func claimString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// getKey returns the provider public key with the given ID.
+// The keys are refetched if the ID is unknown, e.g. after a key rotation.
+func (p *oidcProvider) getKey(c *http.Client, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key := p.lookupKey(kid); key != nil {
+		return key, nil
+	}
+
+	if time.Since(p.keysFetched) < oidcKeysMinRefresh {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	keys, err := fetchJWKS(c, p.jwksURL)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+	p.keysFetched = time.Now()
+
+	if key := p.lookupKey(kid); key != nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+func (p *oidcProvider) lookupKey(kid string) interface{} {
+	if key, ok := p.keys[kid]; ok {
+		return key
+	}
+
+	// Tokens may omit the key ID if the provider has a single key.
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+
+	return nil
+}
+
+// fetchJWKS fetches the RSA and EC signature keys from a JSON Web Key Set URL.
+// Keys of other types are skipped.
+func fetchJWKS(c *http.Client, url string) (map[string]interface{}, error) {
+	jwks := struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			Use     string `json:"use"`
+			KeyID   string `json:"kid"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+			Curve   string `json:"crv"`
+			X       string `json:"x"`
+			Y       string `json:"y"`
+		} `json:"keys"`
+	}{}
+
+	err := getJSON(c, url, &jwks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch provider keys: %s", err)
+	}
+
+	keys := make(map[string]interface{})
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		switch k.KeyType {
+		case "RSA":
+			n, err1 := decodeBigInt(k.N)
+			e, err2 := decodeBigInt(k.E)
+			if err1 != nil || err2 != nil || !e.IsInt64() {
+				return nil, fmt.Errorf("invalid RSA key %q", k.KeyID)
+			}
+			keys[k.KeyID] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Curve {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, err1 := decodeBigInt(k.X)
+			y, err2 := decodeBigInt(k.Y)
+			if err1 != nil || err2 != nil || !curve.IsOnCurve(x, y) {
+				return nil, fmt.Errorf("invalid EC key %q", k.KeyID)
+			}
+			keys[k.KeyID] = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		}
+	}
+
+	return keys, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
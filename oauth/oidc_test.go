@@ -0,0 +1,201 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"gopkg.in/dgrijalva/jwt-go.v3"
+)
+
+func TestOIDCProvider(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                           issuer,
+			"authorization_endpoint":           issuer + "/auth",
+			"token_endpoint":                   issuer + "/token",
+			"jwks_uri":                         issuer + "/certs",
+			"code_challenge_methods_supported": []string{"plain", "S256"},
+		})
+	})
+	mux.HandleFunc("/certs", func(w http.ResponseWriter, r *http.Request) {
+		enc := base64.RawURLEncoding.EncodeToString
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{
+				{
+					"kty": "RSA",
+					"use": "sig",
+					"kid": "key1",
+					"n":   enc(key.N.Bytes()),
+					"e":   enc(big.NewInt(int64(key.E)).Bytes()),
+				},
+			},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	issuer = server.URL
+
+	handler := New(&Config{
+		Logger:     log.New(ioutil.Discard, "", 0),
+		MountURL:   "https://example.test/forum/oauth",
+		CookiePath: "/forum/",
+	})
+
+	if err := handler.AddOIDCProvider("github", &OIDCConfig{Issuer: issuer, ClientID: "id", Secret: "secret"}); err == nil {
+		t.Fatalf("no error on adding a provider with reserved name")
+	}
+	if err := handler.AddOIDCProvider("other", &OIDCConfig{Issuer: issuer + "/other", ClientID: "id", Secret: "secret"}); err == nil {
+		t.Fatalf("no error on adding a provider with bad issuer")
+	}
+
+	err = handler.AddOIDCProvider("corp", &OIDCConfig{
+		Issuer:    issuer + "/",
+		ClientID:  "bebop",
+		Secret:    "secret",
+		Scopes:    []string{"profile"},
+		NameClaim: "preferred_username",
+	})
+	if err != nil {
+		t.Fatalf("failed to add oidc provider: %s", err)
+	}
+
+	p := handler.providers["corp"]
+	if p.config.Endpoint.AuthURL != issuer+"/auth" || p.config.Endpoint.TokenURL != issuer+"/token" {
+		t.Fatalf("bad discovered endpoint: %v", p.config.Endpoint)
+	}
+	if !reflect.DeepEqual(p.config.Scopes, []string{"openid", "profile"}) {
+		t.Fatalf("bad scopes: %v", p.config.Scopes)
+	}
+	if !p.pkce {
+		t.Fatalf("pkce is not enabled")
+	}
+
+	req, err := http.NewRequest("GET", "/begin/corp?code_challenge="+testCodeChallenge+"&code_challenge_method=S256", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusFound {
+		t.Fatalf("begin: want status code %d got %d", http.StatusFound, w.Code)
+	}
+	loc, err := req.URL.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Query().Get("nonce") != loc.Query().Get("state") {
+		t.Fatalf("begin: want nonce equal to state: %q", loc)
+	}
+	if loc.Query().Get("code_challenge_method") != "S256" {
+		t.Fatalf("begin: want provider code challenge: %q", loc)
+	}
+
+	now := time.Now()
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":                issuer,
+			"aud":                []string{"bebop", "other"},
+			"sub":                "f4b3e2a1",
+			"preferred_username": "jdoe",
+			"picture":            "https://sso.example.test/jdoe.png",
+			"nonce":              "test-nonce",
+			"iat":                now.Unix(),
+			"exp":                now.Add(time.Minute).Unix(),
+		}
+	}
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) *oauth2.Token {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return (&oauth2.Token{AccessToken: "access"}).WithExtra(map[string]interface{}{"id_token": s})
+	}
+
+	tests := []struct {
+		desc     string
+		token    *oauth2.Token
+		wantErr  bool
+		wantUser *user
+	}{
+		{
+			desc:     "valid",
+			token:    sign(jwt.SigningMethodRS256, "key1", key, validClaims()),
+			wantUser: &user{id: "f4b3e2a1", name: "jdoe", picture: "https://sso.example.test/jdoe.png"},
+		},
+		{
+			desc:    "no id token",
+			token:   &oauth2.Token{AccessToken: "access"},
+			wantErr: true,
+		},
+		{
+			desc:    "unknown key",
+			token:   sign(jwt.SigningMethodRS256, "key2", key, validClaims()),
+			wantErr: true,
+		},
+		{
+			desc:    "hmac",
+			token:   sign(jwt.SigningMethodHS256, "key1", []byte("secret"), validClaims()),
+			wantErr: true,
+		},
+	}
+
+	for _, c := range []struct {
+		desc  string
+		claim string
+		value interface{}
+	}{
+		{"bad issuer", "iss", "https://evil.test"},
+		{"bad audience", "aud", "other"},
+		{"bad nonce", "nonce", "other-nonce"},
+		{"expired", "exp", now.Add(-time.Minute).Unix()},
+		{"no expiration", "exp", nil},
+	} {
+		claims := validClaims()
+		if c.value == nil {
+			delete(claims, c.claim)
+		} else {
+			claims[c.claim] = c.value
+		}
+		tests = append(tests, struct {
+			desc     string
+			token    *oauth2.Token
+			wantErr  bool
+			wantUser *user
+		}{
+			desc:    c.desc,
+			token:   sign(jwt.SigningMethodRS256, "key1", key, claims),
+			wantErr: true,
+		})
+	}
+
+	for _, tc := range tests {
+		gotUser, gotErr := p.oidc.getUser(server.Client(), tc.token, "test-nonce")
+		if tc.wantErr != (gotErr != nil) {
+			t.Fatalf("test %q: wantErr=%v, got error: %v", tc.desc, tc.wantErr, gotErr)
+		}
+		if !reflect.DeepEqual(tc.wantUser, gotUser) {
+			t.Fatalf("test %q: want user %v, got %v", tc.desc, tc.wantUser, gotUser)
+		}
+	}
+}
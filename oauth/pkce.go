@@ -0,0 +1,73 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// codeChallengeRE matches a base64url-encoded SHA-256 hash,
+// the only PKCE code challenge supported.
+var codeChallengeRE = regexp.MustCompile(`^[A-Za-z0-9_-]{43}$`)
+
+// validCodeChallenge checks if the given PKCE code challenge is valid.
+func validCodeChallenge(challenge, method string) bool {
+	return method == "S256" && codeChallengeRE.MatchString(challenge)
+}
+
+// genCodeVerifier generates a new random PKCE code verifier.
+func genCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// codeChallenge returns the S256 code challenge of the given code verifier.
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// pkceTransport adds the PKCE code verifier to the authorization code
+// token requests: the oauth2 Exchange method has no option to add it.
+type pkceTransport struct {
+	verifier string
+	base     http.RoundTripper
+}
+
+func (t *pkceTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Method != "POST" || r.Body == nil {
+		return t.base.RoundTrip(r)
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+	if values.Get("grant_type") == "authorization_code" {
+		values.Set("code_verifier", t.verifier)
+	}
+	encoded := values.Encode()
+
+	// A RoundTripper must not modify the request.
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.Body = ioutil.NopCloser(strings.NewReader(encoded))
+	r2.ContentLength = int64(len(encoded))
+
+	return t.base.RoundTrip(r2)
+}
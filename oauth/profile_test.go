@@ -0,0 +1,171 @@
+package oauth
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/oauth2"
+
+	"github.com/disintegration/bebop/avatar"
+	"github.com/disintegration/bebop/session"
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/mock"
+)
+
+func TestOAuthProfile(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "provider-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	})
+	mux.HandleFunc("/picture.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("picture data"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		profile  string
+		imported string
+	)
+
+	handler := New(&Config{
+		Logger: log.New(ioutil.Discard, "", 0),
+		UserStore: &mock.UserStore{
+			OnGetByAuth: func(authService string, authID string) (*store.User, error) {
+				if authID == "existing" {
+					return &store.User{ID: 1}, nil
+				}
+				return nil, store.ErrNotFound
+			},
+			OnNew: func(authService string, authID string) (int64, error) {
+				return 2, nil
+			},
+			OnGet: func(id int64) (*store.User, error) {
+				return &store.User{ID: id}, nil
+			},
+		},
+		IdentityStore: &mock.IdentityStore{
+			OnSetProfile: func(authService, authID, displayName, picture string) error {
+				profile = authService + ":" + authID + ":" + displayName + ":" + picture
+				return nil
+			},
+		},
+		SessionService: &session.MockService{
+			OnCreateCode: func(userID int64, codeChallenge string) (string, error) {
+				return "one-time-code", nil
+			},
+		},
+		AvatarService: &avatar.MockService{
+			OnSave: func(user *store.User, imageData []byte) error {
+				imported = string(imageData)
+				if user.ID != 2 {
+					t.Fatalf("OnSave: unexpected user %v", user)
+				}
+				return nil
+			},
+		},
+		MountURL:   "https://example.test/forum/oauth",
+		CookiePath: "/forum/",
+		AppURL:     "https://example.test/forum/",
+	})
+
+	var providerUser *user
+	handler.providers = map[string]*provider{
+		"testprovider": {
+			config: &oauth2.Config{
+				ClientID:     "test-client-id",
+				ClientSecret: "test-client-secret",
+				Endpoint: oauth2.Endpoint{
+					AuthURL:  "https://provider.test/auth",
+					TokenURL: server.URL + "/token",
+				},
+				RedirectURL: "https://example.test/forum/oauth/end/testprovider",
+			},
+			getUser: func(*http.Client) (*user, error) {
+				return providerUser, nil
+			},
+		},
+	}
+
+	tests := []struct {
+		desc          string
+		importAvatars bool
+		// safeClient downloads the picture with the default client
+		// that refuses to connect to the loopback test server.
+		safeClient   bool
+		user         *user
+		wantProfile  string
+		wantImported string
+	}{
+		{
+			desc:          "new user",
+			importAvatars: true,
+			user:          &user{id: "new", name: "Jane Doe", picture: server.URL + "/picture.png"},
+			wantProfile:   "testprovider:new:Jane Doe:" + server.URL + "/picture.png",
+			wantImported:  "picture data",
+		},
+		{
+			desc:          "new user without import",
+			importAvatars: false,
+			user:          &user{id: "new", name: "Jane Doe", picture: server.URL + "/picture.png"},
+			wantProfile:   "testprovider:new:Jane Doe:" + server.URL + "/picture.png",
+		},
+		{
+			desc:          "new user with bad picture",
+			importAvatars: true,
+			user:          &user{id: "new", name: "Jane Doe", picture: server.URL + "/missing.png"},
+			wantProfile:   "testprovider:new:Jane Doe:" + server.URL + "/missing.png",
+		},
+		{
+			desc:          "new user with internal picture",
+			importAvatars: true,
+			safeClient:    true,
+			user:          &user{id: "new", name: "Jane Doe", picture: server.URL + "/picture.png"},
+			wantProfile:   "testprovider:new:Jane Doe:" + server.URL + "/picture.png",
+		},
+		{
+			desc:          "existing user",
+			importAvatars: true,
+			user:          &user{id: "existing", name: "John Doe", picture: server.URL + "/picture.png"},
+			wantProfile:   "testprovider:existing:John Doe:" + server.URL + "/picture.png",
+		},
+	}
+
+	for _, tc := range tests {
+		profile, imported = "", ""
+		handler.ImportAvatars = tc.importAvatars
+		handler.AvatarClient = server.Client()
+		if tc.safeClient {
+			handler.AvatarClient = nil
+		}
+		providerUser = tc.user
+
+		req, err := http.NewRequest("GET", "/end/testprovider?code=code&state=state", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.AddCookie(&http.Cookie{Name: stateCookie, Value: "state"})
+		req.AddCookie(&http.Cookie{Name: challengeCookie, Value: testCodeChallenge})
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if loc := w.Header().Get("Location"); loc != "https://example.test/forum/#/auth/oauth?code=one-time-code" {
+			t.Fatalf("test %q: bad result %q", tc.desc, loc)
+		}
+		if profile != tc.wantProfile {
+			t.Fatalf("test %q: want profile %q got %q", tc.desc, tc.wantProfile, profile)
+		}
+		if imported != tc.wantImported {
+			t.Fatalf("test %q: want imported avatar %q got %q", tc.desc, tc.wantImported, imported)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/facebook"
@@ -16,24 +17,41 @@ import (
 type provider struct {
 	config  *oauth2.Config
 	getUser func(*http.Client) (*user, error)
+	// oidc is set for OpenID Connect providers that get the user from the ID token.
+	oidc *oidcProvider
+	// pkce is set for providers that support PKCE.
+	pkce bool
 }
 
 type providerConfig struct {
 	endpoint oauth2.Endpoint
 	scopes   []string
 	getUser  func(*http.Client) (*user, error)
+	pkce     bool
+	// configure, if set, adapts the provider to the given configuration,
+	// e.g. to a self-hosted instance.
+	configure func(pc providerConfig, cfg *ProviderConfig) providerConfig
 }
 
+// user is the provider user. The name is a display name, not necessarily
+// a valid bebop user name, and picture is an image URL, both may be empty.
 type user struct {
-	id   string
-	name string
+	id      string
+	name    string
+	picture string
 }
 
+const (
+	gitlabURL       = "https://gitlab.com"
+	microsoftTenant = "common"
+)
+
 var providerConfigs = map[string]providerConfig{
 	"google": {
 		endpoint: google.Endpoint,
 		scopes:   []string{"profile"},
 		getUser:  getGoogleUser,
+		pkce:     true,
 	},
 	"facebook": {
 		endpoint: facebook.Endpoint,
@@ -45,14 +63,86 @@ var providerConfigs = map[string]providerConfig{
 		scopes:   []string{},
 		getUser:  getGithubUser,
 	},
+	"gitlab": {
+		endpoint: gitlabEndpoint(gitlabURL),
+		scopes:   []string{"read_user"},
+		getUser:  getGitlabUser,
+		pkce:     true,
+		configure: func(pc providerConfig, cfg *ProviderConfig) providerConfig {
+			if cfg.BaseURL == "" {
+				return pc
+			}
+			baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+			pc.endpoint = gitlabEndpoint(baseURL)
+			pc.getUser = func(c *http.Client) (*user, error) {
+				return getGitlabUserAt(c, baseURL)
+			}
+			return pc
+		},
+	},
+	"discord": {
+		endpoint: oauth2.Endpoint{
+			AuthURL:  "https://discord.com/api/oauth2/authorize",
+			TokenURL: "https://discord.com/api/oauth2/token",
+		},
+		scopes:  []string{"identify"},
+		getUser: getDiscordUser,
+	},
+	"microsoft": {
+		endpoint: microsoftEndpoint(microsoftTenant),
+		scopes:   []string{"User.Read"},
+		getUser:  getMicrosoftUser,
+		pkce:     true,
+		configure: func(pc providerConfig, cfg *ProviderConfig) providerConfig {
+			if cfg.Tenant != "" {
+				pc.endpoint = microsoftEndpoint(cfg.Tenant)
+			}
+			return pc
+		},
+	},
+	"twitch": {
+		endpoint: oauth2.Endpoint{
+			AuthURL:  "https://id.twitch.tv/oauth2/authorize",
+			TokenURL: "https://id.twitch.tv/oauth2/token",
+		},
+		scopes: []string{},
+		configure: func(pc providerConfig, cfg *ProviderConfig) providerConfig {
+			// The Twitch API requires the client ID in every request.
+			clientID := cfg.ClientID
+			pc.getUser = func(c *http.Client) (*user, error) {
+				return getTwitchUser(c, clientID)
+			}
+			return pc
+		},
+	},
+}
+
+func init() {
+	// Twitch accepts the client credentials in the request body only.
+	oauth2.RegisterBrokenAuthHeaderProvider("https://id.twitch.tv/")
+}
+
+func gitlabEndpoint(baseURL string) oauth2.Endpoint {
+	return oauth2.Endpoint{
+		AuthURL:  baseURL + "/oauth/authorize",
+		TokenURL: baseURL + "/oauth/token",
+	}
+}
+
+func microsoftEndpoint(tenant string) oauth2.Endpoint {
+	return oauth2.Endpoint{
+		AuthURL:  "https://login.microsoftonline.com/" + tenant + "/oauth2/v2.0/authorize",
+		TokenURL: "https://login.microsoftonline.com/" + tenant + "/oauth2/v2.0/token",
+	}
 }
 
 func getGoogleUser(c *http.Client) (*user, error) {
 	url := "https://www.googleapis.com/oauth2/v2/userinfo"
 
 	u := struct {
-		ID   string `json:"id"`
-		Name string `json:"name"`
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Picture string `json:"picture"`
 	}{}
 
 	err := getJSON(c, url, &u)
@@ -60,15 +150,21 @@ func getGoogleUser(c *http.Client) (*user, error) {
 		return nil, err
 	}
 
-	return &user{id: u.ID, name: u.Name}, nil
+	return &user{id: u.ID, name: u.Name, picture: u.Picture}, nil
 }
 
 func getFacebookUser(c *http.Client) (*user, error) {
-	url := "https://graph.facebook.com/me?fields=id,name"
+	url := "https://graph.facebook.com/me?fields=id,name,picture.type(large)"
 
 	u := struct {
-		ID   string `json:"id"`
-		Name string `json:"name"`
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Picture struct {
+			Data struct {
+				URL          string `json:"url"`
+				IsSilhouette bool   `json:"is_silhouette"`
+			} `json:"data"`
+		} `json:"picture"`
 	}{}
 
 	err := getJSON(c, url, &u)
@@ -76,15 +172,21 @@ func getFacebookUser(c *http.Client) (*user, error) {
 		return nil, err
 	}
 
-	return &user{id: u.ID, name: u.Name}, nil
+	var picture string
+	if !u.Picture.Data.IsSilhouette {
+		picture = u.Picture.Data.URL
+	}
+
+	return &user{id: u.ID, name: u.Name, picture: picture}, nil
 }
 
 func getGithubUser(c *http.Client) (*user, error) {
 	url := "https://api.github.com/user"
 
 	u := struct {
-		ID   int64  `json:"id"`
-		Name string `json:"name"`
+		ID        int64  `json:"id"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
 	}{}
 
 	err := getJSON(c, url, &u)
@@ -92,11 +194,111 @@ func getGithubUser(c *http.Client) (*user, error) {
 		return nil, err
 	}
 
-	return &user{id: strconv.FormatInt(u.ID, 10), name: u.Name}, nil
+	return &user{id: strconv.FormatInt(u.ID, 10), name: u.Name, picture: u.AvatarURL}, nil
+}
+
+func getGitlabUser(c *http.Client) (*user, error) {
+	return getGitlabUserAt(c, gitlabURL)
+}
+
+func getGitlabUserAt(c *http.Client, baseURL string) (*user, error) {
+	url := baseURL + "/api/v4/user"
+
+	u := struct {
+		ID        int64  `json:"id"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}{}
+
+	err := getJSON(c, url, &u)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user{id: strconv.FormatInt(u.ID, 10), name: u.Name, picture: u.AvatarURL}, nil
+}
+
+func getDiscordUser(c *http.Client) (*user, error) {
+	url := "https://discord.com/api/users/@me"
+
+	u := struct {
+		ID         string `json:"id"`
+		Username   string `json:"username"`
+		GlobalName string `json:"global_name"`
+		Avatar     string `json:"avatar"`
+	}{}
+
+	err := getJSON(c, url, &u)
+	if err != nil {
+		return nil, err
+	}
+
+	name := u.GlobalName
+	if name == "" {
+		name = u.Username
+	}
+
+	var picture string
+	if u.Avatar != "" {
+		picture = "https://cdn.discordapp.com/avatars/" + u.ID + "/" + u.Avatar + ".png"
+	}
+
+	return &user{id: u.ID, name: name, picture: picture}, nil
+}
+
+func getMicrosoftUser(c *http.Client) (*user, error) {
+	url := "https://graph.microsoft.com/v1.0/me"
+
+	u := struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"displayName"`
+	}{}
+
+	err := getJSON(c, url, &u)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user{id: u.ID, name: u.DisplayName}, nil
+}
+
+func getTwitchUser(c *http.Client, clientID string) (*user, error) {
+	url := "https://api.twitch.tv/helix/users"
+
+	u := struct {
+		Data []struct {
+			ID              string `json:"id"`
+			DisplayName     string `json:"display_name"`
+			ProfileImageURL string `json:"profile_image_url"`
+		} `json:"data"`
+	}{}
+
+	err := getJSONWithHeader(c, url, http.Header{"Client-Id": {clientID}}, &u)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(u.Data) != 1 {
+		return nil, fmt.Errorf("unexpected number of users: %d", len(u.Data))
+	}
+
+	return &user{id: u.Data[0].ID, name: u.Data[0].DisplayName, picture: u.Data[0].ProfileImageURL}, nil
 }
 
 func getJSON(c *http.Client, url string, v interface{}) error {
-	response, err := c.Get(url)
+	return getJSONWithHeader(c, url, nil, v)
+}
+
+func getJSONWithHeader(c *http.Client, url string, header http.Header, v interface{}) error {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	for k, vv := range header {
+		request.Header[k] = vv
+	}
+
+	response, err := c.Do(request)
 	if err != nil {
 		return fmt.Errorf("request failed: %v", err)
 	}
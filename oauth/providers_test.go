@@ -24,16 +24,25 @@ func TestGetUser(t *testing.T) {
 			fn:             getGoogleUser,
 			responseErr:    nil,
 			responseStatus: http.StatusOK,
-			responseBody:   `{"id":"123456789012345678901","name":"Google Username","key":"value"}`,
+			responseBody:   `{"id":"123456789012345678901","name":"Google Username","picture":"https://lh3.googleusercontent.com/a/photo","key":"value"}`,
 			wantErr:        false,
-			wantUser:       &user{id: "123456789012345678901", name: "Google Username"},
+			wantUser:       &user{id: "123456789012345678901", name: "Google Username", picture: "https://lh3.googleusercontent.com/a/photo"},
 		},
 		{
 			desc:           "facebook",
 			fn:             getFacebookUser,
 			responseErr:    nil,
 			responseStatus: http.StatusOK,
-			responseBody:   `{"id":"123456789012345","name":"Facebook Username","key":"value"}`,
+			responseBody:   `{"id":"123456789012345","name":"Facebook Username","picture":{"data":{"url":"https://platform-lookaside.fbsbx.com/photo","is_silhouette":false}},"key":"value"}`,
+			wantErr:        false,
+			wantUser:       &user{id: "123456789012345", name: "Facebook Username", picture: "https://platform-lookaside.fbsbx.com/photo"},
+		},
+		{
+			desc:           "facebook default picture",
+			fn:             getFacebookUser,
+			responseErr:    nil,
+			responseStatus: http.StatusOK,
+			responseBody:   `{"id":"123456789012345","name":"Facebook Username","picture":{"data":{"url":"https://platform-lookaside.fbsbx.com/photo","is_silhouette":true}}}`,
 			wantErr:        false,
 			wantUser:       &user{id: "123456789012345", name: "Facebook Username"},
 		},
@@ -42,9 +51,63 @@ func TestGetUser(t *testing.T) {
 			fn:             getGithubUser,
 			responseErr:    nil,
 			responseStatus: http.StatusOK,
-			responseBody:   `{"id":1234567,"name":"Github Username","key":"value"}`,
+			responseBody:   `{"id":1234567,"name":"Github Username","avatar_url":"https://avatars.githubusercontent.com/u/1234567","key":"value"}`,
+			wantErr:        false,
+			wantUser:       &user{id: "1234567", name: "Github Username", picture: "https://avatars.githubusercontent.com/u/1234567"},
+		},
+		{
+			desc:           "gitlab",
+			fn:             getGitlabUser,
+			responseErr:    nil,
+			responseStatus: http.StatusOK,
+			responseBody:   `{"id":1234567,"username":"gitlab_user","name":"Gitlab Username","avatar_url":"https://gitlab.com/uploads/user/avatar/1234567/avatar.png","key":"value"}`,
+			wantErr:        false,
+			wantUser:       &user{id: "1234567", name: "Gitlab Username", picture: "https://gitlab.com/uploads/user/avatar/1234567/avatar.png"},
+		},
+		{
+			desc:           "discord",
+			fn:             getDiscordUser,
+			responseErr:    nil,
+			responseStatus: http.StatusOK,
+			responseBody:   `{"id":"80351110224678912","username":"discord_user","global_name":"Discord Username","avatar":"8342729096ea3675442027381ff50dfe","key":"value"}`,
+			wantErr:        false,
+			wantUser:       &user{id: "80351110224678912", name: "Discord Username", picture: "https://cdn.discordapp.com/avatars/80351110224678912/8342729096ea3675442027381ff50dfe.png"},
+		},
+		{
+			desc:           "discord without global name",
+			fn:             getDiscordUser,
+			responseErr:    nil,
+			responseStatus: http.StatusOK,
+			responseBody:   `{"id":"80351110224678912","username":"discord_user","global_name":null}`,
+			wantErr:        false,
+			wantUser:       &user{id: "80351110224678912", name: "discord_user"},
+		},
+		{
+			desc:           "microsoft",
+			fn:             getMicrosoftUser,
+			responseErr:    nil,
+			responseStatus: http.StatusOK,
+			responseBody:   `{"id":"87d349ed-44d7-43e1-9a83-5f2406dee5bd","displayName":"Microsoft Username","key":"value"}`,
 			wantErr:        false,
-			wantUser:       &user{id: "1234567", name: "Github Username"},
+			wantUser:       &user{id: "87d349ed-44d7-43e1-9a83-5f2406dee5bd", name: "Microsoft Username"},
+		},
+		{
+			desc:           "twitch",
+			fn:             func(c *http.Client) (*user, error) { return getTwitchUser(c, "client-id") },
+			responseErr:    nil,
+			responseStatus: http.StatusOK,
+			responseBody:   `{"data":[{"id":"141981764","login":"twitch_user","display_name":"Twitch Username","profile_image_url":"https://static-cdn.jtvnw.net/user-default-pictures/photo.png"}]}`,
+			wantErr:        false,
+			wantUser:       &user{id: "141981764", name: "Twitch Username", picture: "https://static-cdn.jtvnw.net/user-default-pictures/photo.png"},
+		},
+		{
+			desc:           "twitch no user",
+			fn:             func(c *http.Client) (*user, error) { return getTwitchUser(c, "client-id") },
+			responseErr:    nil,
+			responseStatus: http.StatusOK,
+			responseBody:   `{"data":[]}`,
+			wantErr:        true,
+			wantUser:       nil,
 		},
 		{
 			desc:           "bad status",
@@ -108,3 +171,38 @@ func (t *testTransport) RoundTrip(*http.Request) (*http.Response, error) {
 		Body:       ioutil.NopCloser(strings.NewReader(t.responseBody)),
 	}, nil
 }
+
+func TestAddProvider(t *testing.T) {
+	h := New(&Config{MountURL: "https://example.test/forum/oauth"})
+
+	err := h.AddProvider("gitlab", &ProviderConfig{ClientID: "id", Secret: "secret", BaseURL: "https://git.example.test/"})
+	if err != nil {
+		t.Fatalf("failed to add gitlab provider: %s", err)
+	}
+	if got, want := h.providers["gitlab"].config.Endpoint.AuthURL, "https://git.example.test/oauth/authorize"; got != want {
+		t.Fatalf("bad gitlab auth url: want %q got %q", want, got)
+	}
+
+	err = h.AddProvider("microsoft", &ProviderConfig{ClientID: "id", Secret: "secret", Tenant: "organizations"})
+	if err != nil {
+		t.Fatalf("failed to add microsoft provider: %s", err)
+	}
+	if got, want := h.providers["microsoft"].config.Endpoint.TokenURL, "https://login.microsoftonline.com/organizations/oauth2/v2.0/token"; got != want {
+		t.Fatalf("bad microsoft token url: want %q got %q", want, got)
+	}
+
+	err = h.AddProvider("twitch", &ProviderConfig{ClientID: "id", Secret: "secret"})
+	if err != nil {
+		t.Fatalf("failed to add twitch provider: %s", err)
+	}
+	if h.providers["twitch"].getUser == nil {
+		t.Fatalf("twitch provider has no getUser")
+	}
+
+	if err := h.AddProvider("unknown", &ProviderConfig{ClientID: "id", Secret: "secret"}); err == nil {
+		t.Fatalf("no error on adding an unknown provider")
+	}
+	if err := h.AddProvider("discord", &ProviderConfig{ClientID: "id"}); err == nil {
+		t.Fatalf("no error on adding a provider without secret")
+	}
+}
@@ -0,0 +1,113 @@
+// Package safehttp provides an HTTP client for fetching user-supplied URLs.
+// The client refuses to connect to loopback, private, link-local and other
+// internal network addresses, including after redirects and DNS lookups.
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned when a URL points to an internal network address.
+var ErrForbiddenAddress = errors.New("safehttp: forbidden address")
+
+const maxRedirects = 10
+
+// forbiddenNets are the non-public networks not covered by the net.IP methods.
+var forbiddenNets = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("192.0.0.0/24"),
+	mustParseCIDR("198.18.0.0/15"),
+	mustParseCIDR("240.0.0.0/4"),
+	mustParseCIDR("64:ff9b::/96"),
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// AllowedIP checks if the IP address is a public unicast address.
+func AllowedIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, n := range forbiddenNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL checks if the URL is an absolute http or https URL
+// whose host is not an internal address. Host names are resolved when connecting.
+func CheckURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("safehttp: unsupported url scheme: %q", u.Scheme)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return errors.New("safehttp: empty url host")
+	}
+	if ip := net.ParseIP(host); ip != nil && !AllowedIP(ip) {
+		return ErrForbiddenAddress
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+// control checks the address right before connecting,
+// so that a host name can not be resolved to an internal address.
+func control(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !AllowedIP(ip) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client with the given timeout that connects
+// to public addresses only and follows redirects to http and https URLs only.
+// Proxies from the environment are not used, as the proxy address is what the
+// client would connect to.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: control,
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.New("safehttp: too many redirects")
+			}
+			return CheckURL(req.URL)
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package safehttp
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAllowedIP(t *testing.T) {
+	for ip, want := range map[string]bool{
+		"93.184.216.34":     true,
+		"2606:2800:220:1::": true,
+		"127.0.0.1":         false,
+		"::1":               false,
+		"10.1.2.3":          false,
+		"172.16.0.1":        false,
+		"192.168.1.1":       false,
+		"169.254.169.254":   false,
+		"fe80::1":           false,
+		"fd00::1":           false,
+		"0.0.0.0":           false,
+		"::":                false,
+		"100.64.0.1":        false,
+		"224.0.0.1":         false,
+		"::ffff:127.0.0.1":  false,
+		"::ffff:10.0.0.1":   false,
+	} {
+		if got := AllowedIP(net.ParseIP(ip)); got != want {
+			t.Fatalf("AllowedIP(%q): want %v got %v", ip, want, got)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	for rawURL, want := range map[string]bool{
+		"https://example.com/hook":      true,
+		"http://example.com:8080/a.png": true,
+		"http://localhost:8080":         false,
+		"http://LOCALHOST./":            false,
+		"http://app.localhost/":         false,
+		"http://127.0.0.1/":             false,
+		"http://[::1]:8080/":            false,
+		"http://169.254.169.254/latest": false,
+		"ftp://example.com":             false,
+		"file:///etc/passwd":            false,
+		"https://":                      false,
+	} {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %s", rawURL, err)
+		}
+		if got := CheckURL(u) == nil; got != want {
+			t.Fatalf("CheckURL(%q): want %v got %v", rawURL, want, got)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	c := NewClient(time.Second)
+
+	// The test server listens on a loopback address.
+	_, err := c.Get(server.URL)
+	if err == nil || !strings.Contains(err.Error(), ErrForbiddenAddress.Error()) {
+		t.Fatalf("expected a forbidden address error, got %v", err)
+	}
+
+	// Redirects to internal addresses are refused by a client
+	// that is allowed to reach the redirecting server.
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	c.Transport = server.Client().Transport
+	_, err = c.Get(redirect.URL)
+	if err == nil || !strings.Contains(err.Error(), ErrForbiddenAddress.Error()) {
+		t.Fatalf("expected a forbidden address error on redirect, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package session
+
+// MockService is a mock implementation of session.Service
+type MockService struct {
+	OnCreate     func(userID int64) (*Tokens, error)
+	OnRefresh    func(refreshToken string) (*Tokens, error)
+	OnRevoke     func(refreshToken string) error
+	OnRevokeAll  func(userID int64) error
+	OnCreateCode func(userID int64, codeChallenge string) (string, error)
+	OnExchange   func(code, codeVerifier string) (*Tokens, error)
+
+	OnCreateLinkCode func(userID int64, state string) (string, error)
+	OnUseLinkCode    func(code, state string) (int64, error)
+}
+
+func (s *MockService) Create(userID int64) (*Tokens, error) {
+	return s.OnCreate(userID)
+}
+func (s *MockService) Refresh(refreshToken string) (*Tokens, error) {
+	return s.OnRefresh(refreshToken)
+}
+func (s *MockService) Revoke(refreshToken string) error {
+	return s.OnRevoke(refreshToken)
+}
+func (s *MockService) RevokeAll(userID int64) error {
+	return s.OnRevokeAll(userID)
+}
+func (s *MockService) CreateCode(userID int64, codeChallenge string) (string, error) {
+	return s.OnCreateCode(userID, codeChallenge)
+}
+func (s *MockService) Exchange(code, codeVerifier string) (*Tokens, error) {
+	return s.OnExchange(code, codeVerifier)
+}
+func (s *MockService) CreateLinkCode(userID int64, state string) (string, error) {
+	return s.OnCreateLinkCode(userID, state)
+}
+func (s *MockService) UseLinkCode(code, state string) (int64, error) {
+	return s.OnUseLinkCode(code, state)
+}
@@ -0,0 +1,317 @@
+// Package session provides a service that manages bebop login sessions.
+// A session issues short-lived access tokens and a refresh token that is
+// replaced with a new one on every use.
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/store"
+)
+
+// Refresh, Exchange and UseLinkCode errors.
+var (
+	ErrInvalidToken = errors.New("session: invalid refresh token")
+	ErrInvalidCode  = errors.New("session: invalid one-time code")
+	ErrUserBlocked  = errors.New("session: user is blocked")
+)
+
+// codeTTL is the lifetime of the one-time codes exchanged for sessions.
+const codeTTL = 5 * time.Minute
+
+// Tokens is a pair of auth tokens issued for a session.
+type Tokens struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Service is a login session service.
+type Service interface {
+	// Create starts a new session for the given user.
+	Create(userID int64) (*Tokens, error)
+
+	// Refresh replaces the refresh token with a new one and issues a new access token.
+	Refresh(refreshToken string) (*Tokens, error)
+
+	// Revoke ends the session of the given refresh token.
+	Revoke(refreshToken string) error
+
+	// RevokeAll ends all the sessions of the given user
+	// and revokes all the access tokens issued to them.
+	RevokeAll(userID int64) error
+
+	// CreateCode issues a short-lived one-time code for the given user
+	// bound to a PKCE S256 code challenge.
+	CreateCode(userID int64, codeChallenge string) (string, error)
+
+	// Exchange starts a new session for the user of the given one-time code
+	// if the code verifier matches the code challenge.
+	Exchange(code, codeVerifier string) (*Tokens, error)
+
+	// CreateLinkCode issues a short-lived one-time code that links
+	// a login provider identity to the given user. The code is bound to
+	// the state of the OAuth flow it is used in, empty before the flow starts.
+	CreateLinkCode(userID int64, state string) (string, error)
+
+	// UseLinkCode returns the user of the given link code bound to the given state.
+	// The code can be used only once.
+	UseLinkCode(code, state string) (int64, error)
+}
+
+// service is the main implementation of the Service.
+type service struct {
+	sessionStore   store.SessionStore
+	userStore      store.UserStore
+	authTokenStore store.AuthTokenStore
+	jwtService     jwt.Service
+	ttl            time.Duration
+}
+
+// NewService creates a new session service.
+// The sessions expire if not refreshed within the given ttl.
+func NewService(sessionStore store.SessionStore, userStore store.UserStore, authTokenStore store.AuthTokenStore, jwtService jwt.Service, ttl time.Duration) Service {
+	return &service{
+		sessionStore:   sessionStore,
+		userStore:      userStore,
+		authTokenStore: authTokenStore,
+		jwtService:     jwtService,
+		ttl:            ttl,
+	}
+}
+
+// Create starts a new session for the given user.
+func (s *service) Create(userID int64) (*Tokens, error) {
+	refreshToken, err := genToken()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.sessionStore.New(userID, hashToken(refreshToken), time.Now().Add(s.ttl))
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.jwtService.Create(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tokens{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// Refresh replaces the refresh token with a new one and issues a new access token.
+func (s *service) Refresh(refreshToken string) (*Tokens, error) {
+	tokenHash := hashToken(refreshToken)
+
+	session, err := s.sessionStore.GetByToken(tokenHash)
+	if err != nil {
+		if err == store.ErrNotFound {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if !time.Now().Before(session.ExpiresAt) {
+		err = s.delete(session.ID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.userStore.Get(session.UserID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if user.Blocked {
+		err = s.delete(session.ID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrUserBlocked
+	}
+
+	newRefreshToken, err := genToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.sessionStore.Rotate(session.ID, tokenHash, hashToken(newRefreshToken), time.Now().Add(s.ttl))
+	if err != nil {
+		if err == store.ErrNotFound {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	accessToken, err := s.jwtService.Create(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tokens{AccessToken: accessToken, RefreshToken: newRefreshToken}, nil
+}
+
+// Revoke ends the session of the given refresh token.
+func (s *service) Revoke(refreshToken string) error {
+	session, err := s.sessionStore.GetByToken(hashToken(refreshToken))
+	if err != nil {
+		if err == store.ErrNotFound {
+			return ErrInvalidToken
+		}
+		return err
+	}
+	return s.delete(session.ID)
+}
+
+// RevokeAll ends all the sessions of the given user
+// and revokes all the access tokens issued to them.
+func (s *service) RevokeAll(userID int64) error {
+	// Access token issue times and the stored time are in microseconds.
+	// Round the time up to revoke the tokens issued within the current microsecond as well.
+	validAfter := time.Now().Truncate(time.Microsecond).Add(time.Microsecond)
+
+	err := s.userStore.SetTokensValidAfter(userID, validAfter)
+	if err != nil {
+		return err
+	}
+
+	err = s.authTokenStore.DeleteByUser(userID, store.AuthTokenOAuthLink)
+	if err != nil {
+		return err
+	}
+
+	return s.sessionStore.DeleteByUser(userID)
+}
+
+// CreateCode issues a short-lived one-time code for the given user
+// bound to a PKCE S256 code challenge.
+func (s *service) CreateCode(userID int64, codeChallenge string) (string, error) {
+	code, err := genToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.authTokenStore.New(userID, store.AuthTokenOAuthCode, hashCode(code, codeChallenge), time.Now().Add(codeTTL))
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// Exchange starts a new session for the user of the given one-time code
+// if the code verifier matches the code challenge.
+func (s *service) Exchange(code, codeVerifier string) (*Tokens, error) {
+	// The challenge is a part of the code hash, so a code used
+	// with a wrong verifier is not found.
+	sum := sha256.Sum256([]byte(codeVerifier))
+	codeChallenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	t, err := s.authTokenStore.Use(store.AuthTokenOAuthCode, hashCode(code, codeChallenge))
+	if err != nil {
+		if err == store.ErrNotFound {
+			return nil, ErrInvalidCode
+		}
+		return nil, err
+	}
+
+	user, err := s.userStore.Get(t.UserID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			return nil, ErrInvalidCode
+		}
+		return nil, err
+	}
+
+	if user.Blocked {
+		return nil, ErrUserBlocked
+	}
+
+	return s.Create(user.ID)
+}
+
+// CreateLinkCode issues a short-lived one-time code that links
+// a login provider identity to the given user. The code is bound to the given state.
+func (s *service) CreateLinkCode(userID int64, state string) (string, error) {
+	code, err := genToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.authTokenStore.New(userID, store.AuthTokenOAuthLink, hashCode(code, state), time.Now().Add(codeTTL))
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// UseLinkCode returns the user of the given link code bound to the given state.
+// The code can be used only once.
+func (s *service) UseLinkCode(code, state string) (int64, error) {
+	// The state is a part of the code hash, so a code used
+	// with a wrong state is not found.
+	t, err := s.authTokenStore.Use(store.AuthTokenOAuthLink, hashCode(code, state))
+	if err != nil {
+		if err == store.ErrNotFound {
+			return 0, ErrInvalidCode
+		}
+		return 0, err
+	}
+
+	user, err := s.userStore.Get(t.UserID)
+	if err != nil {
+		if err == store.ErrNotFound {
+			return 0, ErrInvalidCode
+		}
+		return 0, err
+	}
+
+	if user.Blocked {
+		return 0, ErrUserBlocked
+	}
+
+	return user.ID, nil
+}
+
+// delete deletes a session, ignoring sessions that are already deleted.
+func (s *service) delete(id int64) error {
+	err := s.sessionStore.Delete(id)
+	if err == store.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// genToken generates a new random refresh token.
+func genToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hash of a refresh token that is kept in the session store.
+func hashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+// hashCode returns the hash of a one-time code and the value it is bound to,
+// the code challenge or the OAuth state, that is kept in the auth token store.
+func hashCode(code, binding string) string {
+	return hashToken(code + "." + binding)
+}
@@ -0,0 +1,264 @@
+package session
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/jwt"
+	"github.com/disintegration/bebop/store/memory"
+)
+
+func TestService(t *testing.T) {
+	st := memory.New()
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(st.Sessions(), st.Users(), st.AuthTokens(), jwtService, time.Hour)
+
+	userID, err := st.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+
+	tokens1, err := s.Create(userID)
+	if err != nil {
+		t.Fatalf("failed to create a session: %s", err)
+	}
+	gotUserID, _, err := jwtService.Verify(tokens1.AccessToken)
+	if err != nil || gotUserID != userID {
+		t.Fatalf("bad access token: user %d, %v", gotUserID, err)
+	}
+
+	tokens2, err := s.Refresh(tokens1.RefreshToken)
+	if err != nil {
+		t.Fatalf("failed to refresh a session: %s", err)
+	}
+	if tokens2.RefreshToken == tokens1.RefreshToken {
+		t.Fatalf("refresh token is not rotated")
+	}
+	gotUserID, _, err = jwtService.Verify(tokens2.AccessToken)
+	if err != nil || gotUserID != userID {
+		t.Fatalf("bad access token: user %d, %v", gotUserID, err)
+	}
+
+	_, err = s.Refresh(tokens1.RefreshToken)
+	if err != ErrInvalidToken {
+		t.Fatalf("expected ErrInvalidToken on reusing a refresh token, got %v", err)
+	}
+
+	err = s.Revoke(tokens2.RefreshToken)
+	if err != nil {
+		t.Fatalf("failed to revoke a session: %s", err)
+	}
+	_, err = s.Refresh(tokens2.RefreshToken)
+	if err != ErrInvalidToken {
+		t.Fatalf("expected ErrInvalidToken after revoke, got %v", err)
+	}
+	err = s.Revoke(tokens2.RefreshToken)
+	if err != ErrInvalidToken {
+		t.Fatalf("expected ErrInvalidToken on revoking twice, got %v", err)
+	}
+
+	tokens3, err := s.Create(userID)
+	if err != nil {
+		t.Fatalf("failed to create a session: %s", err)
+	}
+	tokens4, err := s.Create(userID)
+	if err != nil {
+		t.Fatalf("failed to create a session: %s", err)
+	}
+	err = s.RevokeAll(userID)
+	if err != nil {
+		t.Fatalf("failed to revoke all sessions: %s", err)
+	}
+	for _, tokens := range []*Tokens{tokens3, tokens4} {
+		_, err = s.Refresh(tokens.RefreshToken)
+		if err != ErrInvalidToken {
+			t.Fatalf("expected ErrInvalidToken after revoking all, got %v", err)
+		}
+	}
+	user, err := st.Users().Get(userID)
+	if err != nil {
+		t.Fatalf("failed to get a user: %s", err)
+	}
+	for _, tokens := range []*Tokens{tokens3, tokens4} {
+		_, issuedAt, err := jwtService.Verify(tokens.AccessToken)
+		if err != nil {
+			t.Fatalf("failed to verify an access token: %s", err)
+		}
+		if !issuedAt.Before(user.TokensValidAfter) {
+			t.Fatalf("access token issued at %v is not revoked by %v", issuedAt, user.TokensValidAfter)
+		}
+	}
+
+	// Signing in right after logging out of all the sessions must work,
+	// even within the same second.
+	tokens5, err := s.Create(userID)
+	if err != nil {
+		t.Fatalf("failed to create a session: %s", err)
+	}
+	_, issuedAt, err := jwtService.Verify(tokens5.AccessToken)
+	if err != nil {
+		t.Fatalf("failed to verify an access token: %s", err)
+	}
+	if issuedAt.Before(user.TokensValidAfter) {
+		t.Fatalf("access token issued at %v right after revoking all is rejected by %v", issuedAt, user.TokensValidAfter)
+	}
+	err = st.Users().SetBlocked(userID, true)
+	if err != nil {
+		t.Fatalf("failed to block a user: %s", err)
+	}
+	_, err = s.Refresh(tokens5.RefreshToken)
+	if err != ErrUserBlocked {
+		t.Fatalf("expected ErrUserBlocked, got %v", err)
+	}
+	_, err = s.Refresh(tokens5.RefreshToken)
+	if err != ErrInvalidToken {
+		t.Fatalf("expected ErrInvalidToken after a blocked refresh, got %v", err)
+	}
+}
+
+func TestServiceExpiredSession(t *testing.T) {
+	st := memory.New()
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(st.Sessions(), st.Users(), st.AuthTokens(), jwtService, -time.Minute)
+
+	userID, err := st.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+
+	tokens, err := s.Create(userID)
+	if err != nil {
+		t.Fatalf("failed to create a session: %s", err)
+	}
+	_, err = s.Refresh(tokens.RefreshToken)
+	if err != ErrInvalidToken {
+		t.Fatalf("expected ErrInvalidToken for an expired session, got %v", err)
+	}
+}
+
+func TestServiceExchange(t *testing.T) {
+	st := memory.New()
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(st.Sessions(), st.Users(), st.AuthTokens(), jwtService, time.Hour)
+
+	userID, err := st.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+
+	// The challenge is base64url(sha256(verifier)).
+	verifier := "dBjftJeZ4CVP-mJ0kjwvYT6bU8z3HPsaa3_0Ua8Wfd7"
+	challenge := "JAcpNsWWU7Oms5b8Gn1uZ7OoujAQRAXzmsZ9LtJhcoY"
+
+	code, err := s.CreateCode(userID, challenge)
+	if err != nil {
+		t.Fatalf("failed to create a code: %s", err)
+	}
+
+	_, err = s.Exchange(code, "wrong-verifier")
+	if err != ErrInvalidCode {
+		t.Fatalf("expected ErrInvalidCode for a wrong verifier, got %v", err)
+	}
+
+	tokens, err := s.Exchange(code, verifier)
+	if err != nil {
+		t.Fatalf("failed to exchange a code: %s", err)
+	}
+	gotUserID, _, err := jwtService.Verify(tokens.AccessToken)
+	if err != nil || gotUserID != userID {
+		t.Fatalf("bad access token: user %d, %v", gotUserID, err)
+	}
+	if _, err = s.Refresh(tokens.RefreshToken); err != nil {
+		t.Fatalf("failed to refresh an exchanged session: %s", err)
+	}
+
+	_, err = s.Exchange(code, verifier)
+	if err != ErrInvalidCode {
+		t.Fatalf("expected ErrInvalidCode for a used code, got %v", err)
+	}
+
+	code, err = s.CreateCode(userID, challenge)
+	if err != nil {
+		t.Fatalf("failed to create a code: %s", err)
+	}
+	if err = st.Users().SetBlocked(userID, true); err != nil {
+		t.Fatalf("failed to block a user: %s", err)
+	}
+	_, err = s.Exchange(code, verifier)
+	if err != ErrUserBlocked {
+		t.Fatalf("expected ErrUserBlocked, got %v", err)
+	}
+}
+
+func TestServiceLinkCode(t *testing.T) {
+	st := memory.New()
+	jwtService, err := jwt.NewService(strings.Repeat("0", 64), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(st.Sessions(), st.Users(), st.AuthTokens(), jwtService, time.Hour)
+
+	userID, err := st.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+
+	code, err := s.CreateLinkCode(userID, "state1")
+	if err != nil {
+		t.Fatalf("failed to create a link code: %s", err)
+	}
+
+	if _, err = s.UseLinkCode("wrong-code", "state1"); err != ErrInvalidCode {
+		t.Fatalf("expected ErrInvalidCode for a wrong code, got %v", err)
+	}
+
+	if _, err = s.UseLinkCode(code, "state2"); err != ErrInvalidCode {
+		t.Fatalf("expected ErrInvalidCode for a wrong state, got %v", err)
+	}
+
+	if _, err = s.UseLinkCode(code, ""); err != ErrInvalidCode {
+		t.Fatalf("expected ErrInvalidCode for an empty state, got %v", err)
+	}
+
+	gotUserID, err := s.UseLinkCode(code, "state1")
+	if err != nil || gotUserID != userID {
+		t.Fatalf("failed to use a link code: user %d, %v", gotUserID, err)
+	}
+
+	if _, err = s.UseLinkCode(code, "state1"); err != ErrInvalidCode {
+		t.Fatalf("expected ErrInvalidCode for a used code, got %v", err)
+	}
+
+	// Logging out of all the sessions revokes the link codes.
+	code, err = s.CreateLinkCode(userID, "")
+	if err != nil {
+		t.Fatalf("failed to create a link code: %s", err)
+	}
+	if err = s.RevokeAll(userID); err != nil {
+		t.Fatalf("failed to revoke all sessions: %s", err)
+	}
+	if _, err = s.UseLinkCode(code, ""); err != ErrInvalidCode {
+		t.Fatalf("expected ErrInvalidCode for a revoked code, got %v", err)
+	}
+
+	code, err = s.CreateLinkCode(userID, "")
+	if err != nil {
+		t.Fatalf("failed to create a link code: %s", err)
+	}
+	if err = st.Users().SetBlocked(userID, true); err != nil {
+		t.Fatalf("failed to block a user: %s", err)
+	}
+	if _, err = s.UseLinkCode(code, ""); err != ErrUserBlocked {
+		t.Fatalf("expected ErrUserBlocked, got %v", err)
+	}
+}
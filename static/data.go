@@ -3,15 +3,19 @@
 package static
 
 var fs = embeddedFilesystem{
-	"/frontend/app.html":                   &fileData{name: "app.html", mtime: 1497990986, size: 3109, body: []byte("<!doctype html>\n<html>\n  <head>\n    <meta charset=\"utf-8\">\n    <meta name=\"viewport\" content=\"width=device-width, initial-scale=1, shrink-to-fit=no\">\n    <meta http-equiv=\"x-ua-compatible\" content=\"ie=edge\">\n    <title>-</title>\n    <link rel=\"stylesheet\" href=\"https://cdnjs.cloudflare.com/ajax/libs/twitter-bootstrap/3.3.7/css/bootstrap.min.css\" integrity=\"sha256-916EbMg70RQy9LHiGkXzG8hSg9EdNy97GazNG/aiY1w=\" crossorigin=\"anonymous\" />\n    <link rel=\"stylesheet\" href=\"https://cdnjs.cloudflare.com/ajax/libs/bootstrap-markdown/2.10.0/css/bootstrap-markdown.min.css\" integrity=\"sha256-umMZCcE/LUcJ3F3V/D6NmvQxdm3OWtRMiMApkNnDIOw=\" crossorigin=\"anonymous\" />\n    <link rel=\"stylesheet\" href=\"https://cdnjs.cloudflare.com/ajax/libs/font-awesome/4.7.0/css/font-awesome.min.css\" integrity=\"sha256-eZrrJcwDc/3uDhsdt61sL2oOBY362qM3lon1gyExkL0=\" crossorigin=\"anonymous\" />\n    <link rel=\"stylesheet\" href=\"static/-/frontend/css/bebop.css\">\n  </head>\n  <body> \n    <div id=\"app\"></div>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/jquery/3.2.1/jquery.min.js\" integrity=\"sha256-hwg4gsxgFZhOsEEamdOYGBf13FyQuiTwlAQgxVSNgt4=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/twitter-bootstrap/3.3.7/js/bootstrap.min.js\" integrity=\"sha256-U5ZEeKfGNOja007MMD3YBI0A3OSZOQbeG6z2f2Y0hu8=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/vue/2.2.6/vue.min.js\" integrity=\"sha256-cWZZjnj99rynB+b8FaNGUivxc1kJSRa8ZM/E77cDq0I=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/vue-router/2.4.0/vue-router.min.js\" integrity=\"sha256-fxzMMjPZbIwP33mgE/4GTQ9BTPM7X1PBAHaJ3Kvz6fo=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/vue-resource/1.3.1/vue-resource.min.js\" integrity=\"sha256-vLNsWeWD+1TzgeVJX92ft87XtRoH3UVqKwbfB2nopMY=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/marked/0.3.6/marked.min.js\" integrity=\"sha256-mJAzKDq6kSoKqZKnA6UNLtPaIj8zT2mFnWu/GSouhgQ=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/bootstrap-markdown/2.10.0/js/bootstrap-markdown.min.js\" integrity=\"sha256-vT9X0tmmfKfNTg0U/Iv0rM9mhu8LA0MaDFrzIflHN9A=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/moment.js/2.18.1/moment.min.js\" integrity=\"sha256-1hjUhpc44NwiNg8OwMu2QzJXhD8kcj+sJA3aCQZoUjg=\" crossorigin=\"anonymous\"></script>\n    <script src=\"static/-/frontend/js/bebop-init.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-nav.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-username-modal.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-topics.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-new-topic.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-comments.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-new-comment.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-user.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-app.js\"></script>\n  </body>\n</html>")},
-	"/frontend/css/bebop.css":              &fileData{name: "bebop.css", mtime: 1495846124, size: 3846, body: []byte("body { padding-top: 55px; font-family: Arial, Helvetica, sans-serif; color: #222; }\na { color: #375eab; }\nh1 { margin: 12px 5px; font-size: 2.4rem; color: #333; }\nh2 { margin: 11px 5px; font-size: 2.2rem; color: #333; }\nh3 { margin: 10px 5px; font-size: 2.0rem; color: #333; }\n\n.container { max-width: 800px; }\n.content-container { padding: 0 5px; }\n\n.navbar-default { background-color: #e0ebf5; border-bottom: #d0dbe5 1px solid; }\n.navbar-sign-in { padding: 15px 5px !important; color: #333 !important; }\n.navbar-user { padding: 8px 15px !important; }\n.navbar-title { color: #000; letter-spacing: 2px; }\n.nav>li>a:focus, .nav>li>a:hover, .nav .open>a, .nav .open>a:focus, .nav .open>a:hover { background-color: #d0dbe5; }\n\n.avatar-block { display: block; padding:5px; }\n.avatar-block-l { display: table-cell; vertical-align: middle; }\n.avatar-block-r { display: table-cell; padding-left: 10px; vertical-align: middle; }\n\n.icon-s { width:15px; padding-right: 5px; }\n.loading-info { text-align: center; padding: 50px 0; }\n.info-separator { padding: 0 3px; }\n.btn-fix { min-width: 36px; }\n\n.card { background-color: #fff; border-top: #ccc 1px dashed; }\n\n.topics-topic { margin: 2px 0; padding: 2px 0; }\n.topics-topic-title { font-size: 1.5rem; padding-left: 5px;}\n.topics-topic-info { font-size: 1.2rem; color: #777; padding-left: 5px; margin-top: 2px; }\n.topics-topic-admin-tools { padding-left: 5px; font-size: 1.2rem; color: #d55; margin-top: 2px; }\n.topics-topic-admin-tools a { color: #d55; }\n.topics-topic-admin-tools a:hover { color: #f55; text-decoration: none; }\n.topics-topic-top-buttons { margin: 10px 5px; }\n\n.comments-comment { margin: 5px 0; padding: 5px 0; }\n.comments-comment-author { font-size: 1.4rem; color: #333; }\n.comments-comment-date { font-size: 1.2rem; color: #777; }\n.comments-comment-content { padding: 10px 5px 0 5px; overflow-x: auto; font-size: 1.5rem; }\n.comments-comment-admin-tools {padding-left: 5px; font-size: 1.2rem; color: #d55; margin-top: 4px; }\n.comments-comment-admin-tools a { color: #d55; }\n.comments-comment-admin-tools a:hover { color: #f55; text-decoration: none; }\n.comments-comment-new { margin: 15px 5px; }\n\n.comments-comment-content h1, .md-preview h1 { font-size: 2.2rem; color: #333; margin: 10px 0; }\n.comments-comment-content h2, .md-preview h2 { font-size: 2.1rem; color: #333; margin: 10px 0; }\n.comments-comment-content h3, .md-preview h3 { font-size: 2.0rem; color: #333; margin: 10px 0; }\n.comments-comment-content h4, .md-preview h4 { font-size: 1.9rem; color: #333; margin: 10px 0; }\n.comments-comment-content h5, .md-preview h5 { font-size: 1.8rem; color: #333; margin: 10px 0; }\n.comments-comment-content h6, .md-preview h6 { font-size: 1.7rem; color: #333; margin: 10px 0; }\n.comments-comment-content td, .md-preview td { border: #ccc 1px solid; padding: 5px; }\n.comments-comment-content th, .md-preview th { border: #ccc 1px solid; padding: 5px; }\n.comments-comment-content blockquote, .md-preview blockquote { color: #777; font-size: 1.3rem; }\n\n.user-profile { margin: 5px 0; padding: 5px; }\n\n#comment-input { height: 240px; background-color: #fff; }\n.md-editor { border-radius: 3px; }\n.md-header { border-top-left-radius: 3px; border-top-right-radius: 3px; }\ntextarea.md-input { border-bottom-left-radius: 3px; border-bottom-right-radius: 3px; padding: 5px; }\n.md-preview { border-bottom-left-radius: 3px; border-bottom-right-radius: 3px; padding: 5px; }\n\npre { \n    border: 0;\n    color: #333;\n    background-color: #f5f6f7;\n    white-space: pre;\n    word-wrap: normal;\n    word-break: normal;\n    overflow-x: auto;\n    font-size: 1.3rem;\n    font-family: Consolas, Menlo, monospace;\n}\ncode, pre code {\n    color: #333;\n    background-color: #f5f6f7; \n    font-size: 1.3rem;\n    font-family: Consolas, Menlo, monospace;\n    white-space: pre;\n}\n\n.pagination { margin: 10px 5px; }")},
-	"/frontend/js/bebop-app.js":            &fileData{name: "bebop-app.js", mtime: 1498025716, size: 4187, body: []byte("const BEBOP_LOCAL_STORAGE_TOKEN_KEY = \"bebop_auth_token\";\nconst BEBOP_OAUTH_RESULT_COOKIE = \"bebop_oauth_result\";\n\nvar BebopApp = new Vue({\n  el: \"#app\",\n\n  template: `\n    <div>\n      <bebop-nav :config=\"config\" :auth=\"auth\"></bebop-nav>\n      <bebop-username-modal ref=\"usernameModal\"></bebop-username-modal>\n      <router-view :config=\"config\" :auth=\"auth\"></router-view>\n    </div>\n  `,\n\n  router: new VueRouter({\n    routes: [\n      { path: \"/\", component: BebopTopics },\n      { path: \"/p/:page\", component: BebopTopics },\n      { path: \"/t/:topic\", component: BebopComments },\n      { path: \"/t/:topic/p/:page\", component: BebopComments },\n      { path: \"/t/:topic/p/:page/c/:comment\", component: BebopComments },\n      { path: \"/new-topic\", component: BebopNewTopic },\n      { path: \"/new-comment/:topic\", component: BebopNewComment },\n      { path: \"/me\", component: BebopUser },\n      { path: \"/u/:user\", component: BebopUser },\n    ],\n    scrollBehavior: function(to, from, savedPosition) {\n      if (savedPosition) {\n        return savedPosition;\n      } else {\n        return { x: 0, y: 0 };\n      }\n    },\n  }),\n\n  data: function() {\n    return {\n      config: {\n        title: \"\",\n        oauth: [],\n      },\n      auth: {\n        authenticated: false,\n        user: {},\n      },\n    };\n  },\n\n  mounted: function() {\n    this.getConfig()\n    this.checkAuth();\n  },\n\n  methods: {\n    getConfig: function() {\n      this.$http.get(\"config.json\").then(\n        response => {\n          this.config = response.body;\n          if (this.config.title) {\n            document.title = this.config.title;\n          }\n        },\n        response => {\n          console.log(\"ERROR: getConfig: \" + response.status);\n        }\n      );\n    },\n\n    signIn: function(provider) {\n      window.open(\"oauth/begin/\" + provider, \"\", \"width=800,height=600\");\n    },\n\n    signOut: function() {\n      localStorage.removeItem(BEBOP_LOCAL_STORAGE_TOKEN_KEY);\n      Vue.http.headers.common[\"Authorization\"] = \"\";\n      this.auth = {\n        authenticated: false,\n        user: {},\n      };\n    },\n\n    oauthEnd: function() {\n      var result = this.getCookieByName(BEBOP_OAUTH_RESULT_COOKIE);\n      var parts = result.split(\":\");\n\n      if (parts.length !== 2) {\n        this.oauthError(\"Unknown\");\n        return;\n      }\n\n      if (parts[0] === \"error\") {\n        this.oauthError(parts[1]);\n        return;\n      }\n\n      if (parts[0] !== \"success\") {\n        this.oauthError(\"Unknown\");\n        return;\n      }\n\n      this.oauthSuccess(parts[1]);\n    },\n\n    getCookieByName: function(name) {\n      var value = \"; \" + document.cookie;\n      var parts = value.split(\"; \" + name + \"=\");\n      if (parts.length === 2) return parts.pop().split(\";\").shift();\n    },\n\n    oauthSuccess: function(token) {\n      localStorage.setItem(BEBOP_LOCAL_STORAGE_TOKEN_KEY, token);\n      this.checkAuth();\n    },\n\n    oauthError: function(error) {\n      if (error === \"UserBlocked\") {\n        console.log(\"oauth error: USER IS BLOCKED\");\n      } else {\n        console.log(\"oauth error: \" + error);\n      }\n      this.signOut();\n    },\n\n    checkAuth: function() {\n      var token = localStorage.getItem(BEBOP_LOCAL_STORAGE_TOKEN_KEY);\n      if (token) {\n        Vue.http.headers.common[\"Authorization\"] = \"Bearer \" + token;\n      }\n      this.getMe();\n    },\n\n    getMe: function() {\n      this.$http.get(\"api/v1/me\").then(\n        response => {\n          this.auth = {\n            authenticated: response.body.authenticated ? true : false,\n            user: response.body.authenticated ? response.body.user : {},\n          };\n          if (this.auth.authenticated && this.auth.user.name === \"\") {\n            this.setMyName();\n          }\n        },\n        response => {\n          console.log(\"ERROR: getMe: \" + JSON.stringify(response.body));\n          if (response.status === 401) {\n            this.signOut();\n          }\n        }\n      );\n    },\n\n    setMyName: function() {\n      this.$refs.usernameModal.show(this.auth.user.id, \"\", success => {\n        if (!success) {\n          this.signOut();\n        }\n        this.getMe();\n      });\n    },\n  },\n});\n\nfunction bebopOAuthEnd() {\n  BebopApp.oauthEnd();\n}\n")},
-	"/frontend/js/bebop-comments.js":       &fileData{name: "bebop-comments.js", mtime: 1495846124, size: 7755, body: []byte("const COMMENTS_PER_PAGE = 20;\n\nvar BebopComments = Vue.component(\"bebop-comments\", {\n  template: `\n    <div class=\"container content-container\">\n\n      <div v-if=\"!dataReady\" class=\"loading-info\">\n        <div v-if=\"error\" >\n          <p class=\"text-danger\">\n            Sorry, could not load that topic. Please check your connection.\n          </p>\n          <a class=\"btn btn-primary btn-sm\" role=\"button\" @click=\"load\">\n            <i class=\"fa fa-refresh\"></i> Try Again\n          </a>\n        </div>\n        <div v-else>\n          <i class=\"fa fa-circle-o-notch fa-spin fa-3x fa-fw\"></i>\n        </div>\n      </div>\n      <div v-else>\n\n        <h2>{{topic.title}}</h2>\n\n        <nav v-if=\"lastPage > 1\">\n          <ul class=\"pagination pagination-sm\">\n            <li v-for=\"p in pagination\" :class=\"{active: page === p}\">\n              <span v-if=\"p === '...'\">\u2026</span>\n              <router-link v-if=\"p !== '...'\" :to=\"'/t/' + topicId + '/p/' + p\">{{p}}</router-link>\n            </li>\n          </ul>\n        </nav>\n\n        <div v-for=\"comment in comments\" class=\"card comments-comment\" :id=\"'comment-' + comment.id\">\n\n          <div class=\"avatar-block\">\n            <div class=\"avatar-block-l\">\n              <img v-if=\"users[comment.authorId].avatar\" class=\"img-circle\" :src=\"users[comment.authorId].avatar\" width=\"35\" height=\"35\"> \n              <img v-else class=\"img-circle\" src=\"data:image/gif;base64,R0lGODlhAQABAIAAAP///wAAACH5BAEAAAAALAAAAAABAAEAAAICRAEAOw==\" width=\"35\" height=\"35\"> \n            </div>\n            <div class=\"avatar-block-r\">\n              <div class=\"comments-comment-author\">{{users[comment.authorId].name}}</div>\n              <div class=\"comments-comment-date\">\n                commented <span :title=\"comment.createdAt|formatTime\">{{comment.createdAt|formatTimeAgo}}</span>\n              </div>\n            </div>\n          </div>\n\n          <div class=\"comments-comment-content\" v-html=\"comment.content\">\n          </div>\n\n          <div v-if=\"auth.authenticated && auth.user.admin\" class=\"comments-comment-admin-tools\">\n            <a v-if=\"topic.commentCount > 1\" class=\"a-tool\" role=\"button\" @click=\"delComment(comment.id)\"><i class=\"fa fa-times\" aria-hidden=\"true\"></i> delete comment</a>\n            <span v-if=\"topic.commentCount > 1\" class=\"info-separator\"> | </span>\n            <router-link :to=\"'/u/' + users[comment.authorId].id\" class=\"a-tool\"><i class=\"fa fa-user\" aria-hidden=\"true\"></i> user profile</router-link>\n          </div>\n        \n        </div>\n\n        <div v-if=\"auth.authenticated && page === lastPage\" class=\"comments-comment-new\">\n          <router-link :to=\"'/new-comment/' + topicId\" class=\"btn btn-primary btn-sm\">\n            <i class=\"fa fa-reply\" aria-hidden=\"true\"></i>\n            Reply\n          </router-link>\n        </div>\n\n        <nav v-if=\"lastPage > 1\">\n          <ul class=\"pagination pagination-sm\">\n            <li v-for=\"p in pagination\" :class=\"{active: page === p}\">\n              <span v-if=\"p === '...'\">\u2026</span>\n              <router-link v-if=\"p !== '...'\" :to=\"'/t/' + topicId + '/p/' + p\">{{p}}</router-link>\n            </li>\n          </ul>\n        </nav>\n\n      </div>\n\n    </div>\n  `,\n\n  props: [\"config\", \"auth\"],\n\n  data: function() {\n    return {\n      topic: {},\n      topicReady: false,\n      comments: [],\n      commentCount: 0,\n      commentsReady: false,\n      users: {},\n      usersReady: false,\n      error: false,\n    };\n  },\n\n  computed: {\n    dataReady: function() {\n      return this.topicReady && this.commentsReady && this.usersReady;\n    },\n\n    topicId: function() {\n      var topicId = parseInt(this.$route.params.topic, 10);\n      if (!topicId) {\n        return 0;\n      }\n      return topicId;\n    },\n\n    page: function() {\n      var page = parseInt(this.$route.params.page, 10);\n      if (!page || page < 1) {\n        return 1;\n      }\n      return page;\n    },\n\n    lastPage: function() {\n      if (!this.commentsReady) {\n        return 1;\n      }\n      var p = Math.floor((this.commentCount - 1) / COMMENTS_PER_PAGE) + 1;\n      if (p < 1) {\n        p = 1;\n      }\n      return p;\n    },\n\n    pagination: function() {\n      if (!this.commentsReady) {\n        return [];\n      }\n      return getPagination(this.page, this.lastPage);\n    },\n  },\n\n  watch: {\n    page: function(val) {\n      this.load();\n    },\n    topicId: function(val) {\n      this.load();\n    },\n    dataReady: function(val) {\n      if (val && this.$route.params.comment) {\n        this.$nextTick(() => {\n          $(\"html, body\").animate(\n            {\n              scrollTop: $(\"#comment-\" + this.$route.params.comment).offset().top,\n            },\n            500\n          );\n        });\n      }\n    },\n  },\n\n  created: function() {\n    this.load();\n  },\n\n  methods: {\n    load: function() {\n      this.topic = {};\n      this.topicReady = false;\n      this.comments = [];\n      this.commentCount = 0;\n      this.commentsReady = false;\n      this.users = {};\n      this.usersReady = false;\n      this.waitNewComment = false;\n      this.error = false;\n      this.getTopic();\n      this.getComments();\n    },\n\n    getTopic: function() {\n      var url = \"api/v1/topics/\" + this.topicId;\n      this.$http.get(url).then(\n        response => {\n          this.topic = response.body.topic;\n          this.topicReady = true;\n        },\n        response => {\n          this.error = true;\n          console.log(\"ERROR: getTopic: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    getComments: function() {\n      var url = \"api/v1/comments?topic=\" + this.topicId + \"&limit=\" + COMMENTS_PER_PAGE;\n      if (this.page > 0) {\n        var offset = (this.page - 1) * COMMENTS_PER_PAGE;\n        url += \"&offset=\" + offset;\n      }\n      this.$http.get(url).then(\n        response => {\n          this.comments = response.body.comments;\n          this.commentCount = response.body.count;\n          for (var i = 0; i < this.comments.length; i++) {\n            this.comments[i].content = marked(this.comments[i].content, {\n              sanitize: true,\n              breaks: true,\n            });\n          }\n          this.commentsReady = true;\n\n          if (this.page > this.lastPage) {\n            this.$parent.$router.replace(\"/t/\" + this.topicId + \"/p/\" + this.lastPage);\n            return;\n          }\n\n          this.getUsers();\n        },\n        response => {\n          this.error = true;\n          console.log(\"ERROR: getComments: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    getUsers: function() {\n      var url = \"api/v1/users\";\n      var ids = [];\n      for (var i = 0; i < this.comments.length; i++) {\n        ids.push(this.comments[i].authorId);\n      }\n      ids = ids.filter((v, i, a) => a.indexOf(v) === i);\n      if (ids.length === 0) {\n        this.users = {};\n        this.usersReady = true;\n        return;\n      }\n      url += \"?ids=\" + ids.join(\",\");\n      this.$http.get(url).then(\n        response => {\n          var users = {};\n          for (var i = 0; i < response.body.users.length; i++) {\n            users[response.body.users[i].id] = response.body.users[i];\n          }\n          this.users = users;\n          this.usersReady = true;\n        },\n        response => {\n          this.error = true;\n          console.log(\"ERROR: getUsers: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    delComment: function(id) {\n      if (!confirm(\"Are you sure you want to delete comment \" + id + \"?\")) {\n        return;\n      }\n      var url = \"api/v1/comments/\" + id;\n      this.$http.delete(url).then(\n        response => {\n          this.load();\n        },\n        response => {\n          console.log(\"ERROR: delComment: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n  },\n});\n")},
-	"/frontend/js/bebop-init.js":           &fileData{name: "bebop-init.js", mtime: 1495846124, size: 890, body: []byte("marked.setOptions({\n  sanitize: true,\n  breaks: true,\n});\n\nVue.filter(\"formatTime\", function(value) {\n  if (value) {\n    return moment(String(value)).format(\"MMMM Do YYYY, hh:mm\");\n  }\n});\n\nVue.filter(\"formatTimeAgo\", function(value) {\n  if (value) {\n    return moment(String(value)).fromNow();\n  }\n});\n\nVue.filter(\"capitalize\", function(value) {\n  if (value) {\n    value = String(value);\n    return value[0].toUpperCase() + value.slice(1);\n  }\n});\n\nfunction getPagination(curPage, lastPage) {\n  var pagination = [];\n  var lr = 2;\n\n  pagination.push(1);\n\n  if (curPage - lr > 2) {\n    pagination.push(\"...\");\n  }\n\n  for (var p = curPage - lr; p <= curPage + lr; p++) {\n    if (p > 1 && p < lastPage) {\n      pagination.push(p);\n    }\n  }\n\n  if (curPage + lr < lastPage - 1) {\n    pagination.push(\"...\");\n  }\n\n  if (lastPage > 1) {\n    pagination.push(lastPage);\n  }\n\n  return pagination;\n}\n")},
-	"/frontend/js/bebop-nav.js":            &fileData{name: "bebop-nav.js", mtime: 1495846124, size: 2373, body: []byte("Vue.component(\"bebop-nav\", {\n  template: `\n    <nav class=\"navbar navbar-default navbar-fixed-top\">\n      <div class=\"container\">\n        <div class=\"navbar-header pull-left\">\n          <router-link to=\"/\" class=\"navbar-brand\">\n            <span class=\"navbar-title\">\n              <i class=\"fa fa-comments\"></i>\n              {{ config.title }}\n            </span>\n          </router-link>\n        </div>\n        <div class=\"navbar-header pull-right\">\n          <ul class=\"nav pull-left\">\n            <li v-if=\"auth.authenticated\">\n              <a class=\"navbar-link dropdown-toggle navbar-user\" role=\"button\" data-toggle=\"dropdown\" :title=\"auth.user.name\">\n                <img v-if=\"auth.user.avatar\" class=\"img-circle\" :src=\"auth.user.avatar\" width=\"35\" height=\"35\"> \n                <img v-else class=\"img-circle\" src=\"data:image/gif;base64,R0lGODlhAQABAIAAAP///wAAACH5BAEAAAAALAAAAAABAAEAAAICRAEAOw==\" width=\"35\" height=\"35\"> \n                <span class=\"caret\"></span>\n              </a>\n              <ul class=\"dropdown-menu pull-right\">\n                <li>\n                  <router-link to=\"/me\">\n                    <i class=\"fa fa-user icon-s\"></i>\n                    {{auth.user.name}}\n                  </router-link>\n                </li>\n                <li role=\"separator\" class=\"divider\"></li>\n                <li>\n                  <a href=\"#\" @click.prevent=\"$parent.signOut()\">\n                    <i class=\"fa fa-sign-out icon-s\"></i>\n                    Sign out\n                  </a>\n                </li>\n              </ul>\n            </li>\n            <li v-else>\n              <a class=\"navbar-link dropdown-toggle navbar-sign-in\" href=\"#\" data-toggle=\"dropdown\">\n                <i class=\"fa fa-user icon-s\"></i>\n                Sign In / Up \n                <span class=\"caret\"></span>\n              </a>\n              <ul class=\"dropdown-menu pull-right\">\n                <li v-for=\"provider in config.oauth\">\n                  <a href=\"#\" @click.prevent=\"$parent.signIn(provider)\">\n                    <i :class=\"'icon-s fa fa-' + provider\" aria-hidden=\"true\"></i>\n                    with {{provider|capitalize}}\n                  </a>\n                </li>\n              </ul>\n            </li>\n          </ul>\n        </div>\n      </div>\n    </nav>\n  `,\n\n  props: [\"config\", \"auth\"],\n\n  data: function() {\n    return {};\n  },\n});\n")},
+	"/frontend/app.html":                   &fileData{name: "app.html", mtime: 1792204242, size: 3586, body: []byte("<!doctype html>\n<html>\n  <head>\n    <meta charset=\"utf-8\">\n    <meta name=\"viewport\" content=\"width=device-width, initial-scale=1, shrink-to-fit=no\">\n    <meta http-equiv=\"x-ua-compatible\" content=\"ie=edge\">\n    <title>-</title>\n    <link rel=\"stylesheet\" href=\"https://cdnjs.cloudflare.com/ajax/libs/twitter-bootstrap/3.3.7/css/bootstrap.min.css\" integrity=\"sha256-916EbMg70RQy9LHiGkXzG8hSg9EdNy97GazNG/aiY1w=\" crossorigin=\"anonymous\" />\n    <link rel=\"stylesheet\" href=\"https://cdnjs.cloudflare.com/ajax/libs/bootstrap-markdown/2.10.0/css/bootstrap-markdown.min.css\" integrity=\"sha256-umMZCcE/LUcJ3F3V/D6NmvQxdm3OWtRMiMApkNnDIOw=\" crossorigin=\"anonymous\" />\n    <link rel=\"stylesheet\" href=\"https://cdnjs.cloudflare.com/ajax/libs/font-awesome/4.7.0/css/font-awesome.min.css\" integrity=\"sha256-eZrrJcwDc/3uDhsdt61sL2oOBY362qM3lon1gyExkL0=\" crossorigin=\"anonymous\" />\n    <link rel=\"stylesheet\" href=\"static/-/frontend/css/bebop.css\">\n    <link rel=\"alternate\" type=\"application/atom+xml\" title=\"Latest topics\" href=\"feeds/topics.atom\">\n    <link rel=\"alternate\" type=\"application/rss+xml\" title=\"Latest topics\" href=\"feeds/topics.rss\">\n  </head>\n  <body> \n    <div id=\"app\"></div>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/jquery/3.2.1/jquery.min.js\" integrity=\"sha256-hwg4gsxgFZhOsEEamdOYGBf13FyQuiTwlAQgxVSNgt4=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/twitter-bootstrap/3.3.7/js/bootstrap.min.js\" integrity=\"sha256-U5ZEeKfGNOja007MMD3YBI0A3OSZOQbeG6z2f2Y0hu8=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/vue/2.2.6/vue.min.js\" integrity=\"sha256-cWZZjnj99rynB+b8FaNGUivxc1kJSRa8ZM/E77cDq0I=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/vue-router/2.4.0/vue-router.min.js\" integrity=\"sha256-fxzMMjPZbIwP33mgE/4GTQ9BTPM7X1PBAHaJ3Kvz6fo=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/vue-resource/1.3.1/vue-resource.min.js\" integrity=\"sha256-vLNsWeWD+1TzgeVJX92ft87XtRoH3UVqKwbfB2nopMY=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/marked/0.3.6/marked.min.js\" integrity=\"sha256-mJAzKDq6kSoKqZKnA6UNLtPaIj8zT2mFnWu/GSouhgQ=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/bootstrap-markdown/2.10.0/js/bootstrap-markdown.min.js\" integrity=\"sha256-vT9X0tmmfKfNTg0U/Iv0rM9mhu8LA0MaDFrzIflHN9A=\" crossorigin=\"anonymous\"></script>\n    <script src=\"https://cdnjs.cloudflare.com/ajax/libs/moment.js/2.18.1/moment.min.js\" integrity=\"sha256-1hjUhpc44NwiNg8OwMu2QzJXhD8kcj+sJA3aCQZoUjg=\" crossorigin=\"anonymous\"></script>\n    <script src=\"static/-/frontend/js/bebop-init.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-nav.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-username-modal.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-local-auth.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-oauth.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-topics.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-new-topic.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-comments.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-new-comment.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-user.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-notifications.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-unsubscribe.js\"></script>\n    <script src=\"static/-/frontend/js/bebop-app.js\"></script>\n  </body>\n</html>")},
+	"/frontend/css/bebop.css":              &fileData{name: "bebop.css", mtime: 1792203507, size: 4370, body: []byte("body { padding-top: 55px; font-family: Arial, Helvetica, sans-serif; color: #222; }\na { color: #375eab; }\nh1 { margin: 12px 5px; font-size: 2.4rem; color: #333; }\nh2 { margin: 11px 5px; font-size: 2.2rem; color: #333; }\nh3 { margin: 10px 5px; font-size: 2.0rem; color: #333; }\n\n.container { max-width: 800px; }\n.content-container { padding: 0 5px; }\n\n.navbar-default { background-color: #e0ebf5; border-bottom: #d0dbe5 1px solid; }\n.navbar-sign-in { padding: 15px 5px !important; color: #333 !important; }\n.navbar-user { padding: 8px 15px !important; }\n.navbar-notifications { padding: 15px 5px !important; color: #333 !important; font-size: 18px; }\n.navbar-notifications .badge { background-color: #d9534f; font-size: 11px; vertical-align: top; }\n.navbar-title { color: #000; letter-spacing: 2px; }\n.nav>li>a:focus, .nav>li>a:hover, .nav .open>a, .nav .open>a:focus, .nav .open>a:hover { background-color: #d0dbe5; }\n\n.avatar-block { display: block; padding:5px; }\n.avatar-block-l { display: table-cell; vertical-align: middle; }\n.avatar-block-r { display: table-cell; padding-left: 10px; vertical-align: middle; }\n\n.icon-s { width:15px; padding-right: 5px; }\n.loading-info { text-align: center; padding: 50px 0; }\n.info-separator { padding: 0 3px; }\n.btn-fix { min-width: 36px; }\n\n.card { background-color: #fff; border-top: #ccc 1px dashed; }\n\n.topics-topic { margin: 2px 0; padding: 2px 0; }\n.topics-topic-title { font-size: 1.5rem; padding-left: 5px;}\n.topics-topic-info { font-size: 1.2rem; color: #777; padding-left: 5px; margin-top: 2px; }\n.topics-topic-admin-tools { padding-left: 5px; font-size: 1.2rem; color: #d55; margin-top: 2px; }\n.topics-topic-admin-tools a { color: #d55; }\n.topics-topic-admin-tools a:hover { color: #f55; text-decoration: none; }\n.topics-topic-top-buttons { margin: 10px 5px; }\n.updated-alert { margin: 10px 5px; padding: 8px 15px; }\n\n.notifications-notification { margin: 2px 0; padding: 2px 0; }\n.notifications-unread { border-left: 3px solid #337ab7; }\n.notifications-empty { padding: 10px; color: #777; }\n\n.comments-watch { margin: 0 5px 10px; }\n.comments-comment { margin: 5px 0; padding: 5px 0; }\n.comments-comment-author { font-size: 1.4rem; color: #333; }\n.comments-comment-date { font-size: 1.2rem; color: #777; }\n.comments-comment-content { padding: 10px 5px 0 5px; overflow-x: auto; font-size: 1.5rem; }\n.comments-comment-admin-tools {padding-left: 5px; font-size: 1.2rem; color: #d55; margin-top: 4px; }\n.comments-comment-admin-tools a { color: #d55; }\n.comments-comment-admin-tools a:hover { color: #f55; text-decoration: none; }\n.comments-comment-new { margin: 15px 5px; }\n\n.comments-comment-content h1, .md-preview h1 { font-size: 2.2rem; color: #333; margin: 10px 0; }\n.comments-comment-content h2, .md-preview h2 { font-size: 2.1rem; color: #333; margin: 10px 0; }\n.comments-comment-content h3, .md-preview h3 { font-size: 2.0rem; color: #333; margin: 10px 0; }\n.comments-comment-content h4, .md-preview h4 { font-size: 1.9rem; color: #333; margin: 10px 0; }\n.comments-comment-content h5, .md-preview h5 { font-size: 1.8rem; color: #333; margin: 10px 0; }\n.comments-comment-content h6, .md-preview h6 { font-size: 1.7rem; color: #333; margin: 10px 0; }\n.comments-comment-content td, .md-preview td { border: #ccc 1px solid; padding: 5px; }\n.comments-comment-content th, .md-preview th { border: #ccc 1px solid; padding: 5px; }\n.comments-comment-content blockquote, .md-preview blockquote { color: #777; font-size: 1.3rem; }\n\n.user-profile { margin: 5px 0; padding: 5px; }\n\n#comment-input { height: 240px; background-color: #fff; }\n.md-editor { border-radius: 3px; }\n.md-header { border-top-left-radius: 3px; border-top-right-radius: 3px; }\ntextarea.md-input { border-bottom-left-radius: 3px; border-bottom-right-radius: 3px; padding: 5px; }\n.md-preview { border-bottom-left-radius: 3px; border-bottom-right-radius: 3px; padding: 5px; }\n\npre { \n    border: 0;\n    color: #333;\n    background-color: #f5f6f7;\n    white-space: pre;\n    word-wrap: normal;\n    word-break: normal;\n    overflow-x: auto;\n    font-size: 1.3rem;\n    font-family: Consolas, Menlo, monospace;\n}\ncode, pre code {\n    color: #333;\n    background-color: #f5f6f7; \n    font-size: 1.3rem;\n    font-family: Consolas, Menlo, monospace;\n    white-space: pre;\n}\n\n.pagination { margin: 10px 5px; }\n\n.user-digest-hint { font-size: 12px; margin-top: 4px; }\n")},
+	"/frontend/js/bebop-app.js":            &fileData{name: "bebop-app.js", mtime: 1792204911, size: 7177, body: []byte("const BEBOP_LOCAL_STORAGE_TOKEN_KEY = \"bebop_auth_token\";\nconst BEBOP_LOCAL_STORAGE_REFRESH_TOKEN_KEY = \"bebop_refresh_token\";\nconst BEBOP_TOKEN_REFRESH_MARGIN = 60; // seconds before the access token expires\n\nvar BebopApp = new Vue({\n  el: \"#app\",\n\n  template: `\n    <div>\n      <bebop-nav :config=\"config\" :auth=\"auth\"></bebop-nav>\n      <bebop-username-modal ref=\"usernameModal\"></bebop-username-modal>\n      <bebop-local-auth-modal ref=\"localAuthModal\" :config=\"config\"></bebop-local-auth-modal>\n      <router-view :config=\"config\" :auth=\"auth\"></router-view>\n    </div>\n  `,\n\n  router: new VueRouter({\n    routes: [\n      { path: \"/\", component: BebopTopics },\n      { path: \"/p/:page\", component: BebopTopics },\n      { path: \"/t/:topic\", component: BebopComments },\n      { path: \"/t/:topic/p/:page\", component: BebopComments },\n      { path: \"/t/:topic/p/:page/c/:comment\", component: BebopComments },\n      { path: \"/new-topic\", component: BebopNewTopic },\n      { path: \"/new-comment/:topic\", component: BebopNewComment },\n      { path: \"/me\", component: BebopUser },\n      { path: \"/u/:user\", component: BebopUser },\n      { path: \"/notifications\", component: BebopNotifications },\n      { path: \"/unsubscribe/:token\", component: BebopUnsubscribe },\n      { path: \"/auth/oauth\", component: BebopOAuthEnd },\n      { path: \"/auth/:action/:token\", component: BebopLocalAuthLink },\n    ],\n    scrollBehavior: function(to, from, savedPosition) {\n      if (savedPosition) {\n        return savedPosition;\n      } else {\n        return { x: 0, y: 0 };\n      }\n    },\n  }),\n\n  data: function() {\n    return {\n      config: {\n        title: \"\",\n        oauth: [],\n        localAuth: false,\n        magicLinks: false,\n      },\n      auth: {\n        authenticated: false,\n        user: {},\n        permissions: [],\n        unreadNotifications: 0,\n      },\n      refreshTimer: null,\n    };\n  },\n\n  mounted: function() {\n    this.getConfig()\n    this.checkAuth();\n  },\n\n  methods: {\n    getConfig: function() {\n      this.$http.get(\"config.json\").then(\n        response => {\n          this.config = response.body;\n          if (this.config.title) {\n            document.title = this.config.title;\n          }\n        },\n        response => {\n          console.log(\"ERROR: getConfig: \" + response.status);\n        }\n      );\n    },\n\n    signIn: function(provider) {\n      bebopOAuthBegin(provider);\n    },\n\n    // linkIdentity links a provider identity to the signed in user.\n    linkIdentity: function(provider) {\n      bebopOAuthBegin(provider, true);\n    },\n\n    signOut: function() {\n      var refreshToken = localStorage.getItem(BEBOP_LOCAL_STORAGE_REFRESH_TOKEN_KEY);\n      if (refreshToken) {\n        this.$http.post(\"api/v1/auth/logout\", { refreshToken: refreshToken });\n      }\n      localStorage.removeItem(BEBOP_LOCAL_STORAGE_TOKEN_KEY);\n      localStorage.removeItem(BEBOP_LOCAL_STORAGE_REFRESH_TOKEN_KEY);\n      clearTimeout(this.refreshTimer);\n      Vue.http.headers.common[\"Authorization\"] = \"\";\n      this.auth = {\n        authenticated: false,\n        user: {},\n        permissions: [],\n        unreadNotifications: 0,\n      };\n    },\n\n    // can checks if the signed in user is granted the permission, e.g. \"comment.delete\".\n    can: function(permission) {\n      return this.auth.authenticated && this.auth.permissions.indexOf(permission) !== -1;\n    },\n\n    oauthSuccess: function(token, refreshToken) {\n      localStorage.setItem(BEBOP_LOCAL_STORAGE_TOKEN_KEY, token);\n      localStorage.setItem(BEBOP_LOCAL_STORAGE_REFRESH_TOKEN_KEY, refreshToken);\n      this.checkAuth();\n    },\n\n    checkAuth: function() {\n      var token = localStorage.getItem(BEBOP_LOCAL_STORAGE_TOKEN_KEY);\n      if (token && this.tokenTTL(token) <= BEBOP_TOKEN_REFRESH_MARGIN) {\n        this.refreshAuth(this.getMe);\n        return;\n      }\n      if (token) {\n        this.useToken(token);\n      }\n      this.getMe();\n    },\n\n    useToken: function(token) {\n      Vue.http.headers.common[\"Authorization\"] = \"Bearer \" + token;\n      clearTimeout(this.refreshTimer);\n      var delay = this.tokenTTL(token) - BEBOP_TOKEN_REFRESH_MARGIN;\n      this.refreshTimer = setTimeout(this.refreshAuth, Math.max(delay, 1) * 1000);\n    },\n\n    // tokenTTL returns the number of seconds until the access token expires.\n    tokenTTL: function(token) {\n      try {\n        var payload = token.split(\".\")[1].replace(/-/g, \"+\").replace(/_/g, \"/\");\n        return JSON.parse(atob(payload)).exp - Date.now() / 1000;\n      } catch (e) {\n        return 0;\n      }\n    },\n\n    refreshAuth: function(done) {\n      var token = localStorage.getItem(BEBOP_LOCAL_STORAGE_TOKEN_KEY);\n      var refreshToken = localStorage.getItem(BEBOP_LOCAL_STORAGE_REFRESH_TOKEN_KEY);\n      if (!token || !refreshToken) {\n        this.signOut();\n        return;\n      }\n\n      // The tokens may have been refreshed in another browser tab.\n      if (this.tokenTTL(token) > BEBOP_TOKEN_REFRESH_MARGIN) {\n        this.useToken(token);\n        if (done) done();\n        return;\n      }\n\n      this.$http.post(\"api/v1/auth/refresh\", { refreshToken: refreshToken }).then(\n        response => {\n          localStorage.setItem(BEBOP_LOCAL_STORAGE_TOKEN_KEY, response.body.accessToken);\n          localStorage.setItem(BEBOP_LOCAL_STORAGE_REFRESH_TOKEN_KEY, response.body.refreshToken);\n          this.useToken(response.body.accessToken);\n          if (done) done();\n        },\n        response => {\n          if (localStorage.getItem(BEBOP_LOCAL_STORAGE_REFRESH_TOKEN_KEY) !== refreshToken) {\n            this.refreshAuth(done);\n            return;\n          }\n          console.log(\"ERROR: refreshAuth: \" + JSON.stringify(response.body));\n          if (response.status === 401 || response.status === 403) {\n            this.signOut();\n          } else {\n            this.refreshTimer = setTimeout(this.refreshAuth, BEBOP_TOKEN_REFRESH_MARGIN / 2 * 1000);\n          }\n        }\n      );\n    },\n\n    getMe: function() {\n      this.$http.get(\"api/v1/me\").then(\n        response => {\n          this.auth = {\n            authenticated: response.body.authenticated ? true : false,\n            user: response.body.authenticated ? response.body.user : {},\n            permissions: response.body.permissions || [],\n            unreadNotifications: response.body.unreadNotifications || 0,\n          };\n          if (this.auth.authenticated && this.auth.user.name === \"\") {\n            this.setMyName();\n          }\n        },\n        response => {\n          console.log(\"ERROR: getMe: \" + JSON.stringify(response.body));\n          if (response.status === 401) {\n            this.signOut();\n          }\n        }\n      );\n    },\n\n    setMyName: function() {\n      var show = name => {\n        this.$refs.usernameModal.show(this.auth.user.id, name, success => {\n          if (!success) {\n            this.signOut();\n          }\n          this.getMe();\n        });\n      };\n      this.$http.get(\"api/v1/me/name-suggestion\").then(\n        response => {\n          show(response.body.name);\n        },\n        response => {\n          console.log(\"ERROR: setMyName: \" + JSON.stringify(response.body));\n          show(\"\");\n        }\n      );\n    },\n  },\n});\n")},
+	"/frontend/js/bebop-comments.js":       &fileData{name: "bebop-comments.js", mtime: 1792203487, size: 10094, body: []byte("const COMMENTS_PER_PAGE = 20;\n\nvar BebopComments = Vue.component(\"bebop-comments\", {\n  template: `\n    <div class=\"container content-container\">\n\n      <div v-if=\"!dataReady\" class=\"loading-info\">\n        <div v-if=\"error\" >\n          <p class=\"text-danger\">\n            Sorry, could not load that topic. Please check your connection.\n          </p>\n          <a class=\"btn btn-primary btn-sm\" role=\"button\" @click=\"load\">\n            <i class=\"fa fa-refresh\"></i> Try Again\n          </a>\n        </div>\n        <div v-else>\n          <i class=\"fa fa-circle-o-notch fa-spin fa-3x fa-fw\"></i>\n        </div>\n      </div>\n      <div v-else>\n\n        <h2>{{topic.title}}</h2>\n\n        <div v-if=\"auth.authenticated && watchingReady\" class=\"comments-watch\">\n          <a class=\"btn btn-default btn-xs\" role=\"button\" @click=\"setWatching(!watching)\" :title=\"watching ? 'Stop getting the new comments of this topic by email' : 'Get the new comments of this topic by email'\">\n            <i :class=\"watching ? 'fa fa-eye-slash' : 'fa fa-eye'\" aria-hidden=\"true\"></i>\n            {{watching ? \"Unwatch\" : \"Watch\"}}\n          </a>\n        </div>\n\n        <div v-if=\"updated\" class=\"alert alert-info updated-alert\">\n          There are new changes in this topic.\n          <a class=\"btn btn-primary btn-xs\" role=\"button\" @click=\"load\">\n            <i class=\"fa fa-refresh\"></i> Refresh\n          </a>\n        </div>\n\n        <nav v-if=\"lastPage > 1\">\n          <ul class=\"pagination pagination-sm\">\n            <li v-for=\"p in pagination\" :class=\"{active: page === p}\">\n              <span v-if=\"p === '...'\">\u2026</span>\n              <router-link v-if=\"p !== '...'\" :to=\"'/t/' + topicId + '/p/' + p\">{{p}}</router-link>\n            </li>\n          </ul>\n        </nav>\n\n        <div v-for=\"comment in comments\" class=\"card comments-comment\" :id=\"'comment-' + comment.id\">\n\n          <div class=\"avatar-block\">\n            <div class=\"avatar-block-l\">\n              <img v-if=\"users[comment.authorId].avatar\" class=\"img-circle\" :src=\"users[comment.authorId].avatar\" width=\"35\" height=\"35\"> \n              <img v-else class=\"img-circle\" src=\"data:image/gif;base64,R0lGODlhAQABAIAAAP///wAAACH5BAEAAAAALAAAAAABAAEAAAICRAEAOw==\" width=\"35\" height=\"35\"> \n            </div>\n            <div class=\"avatar-block-r\">\n              <div class=\"comments-comment-author\">{{users[comment.authorId].name}}</div>\n              <div class=\"comments-comment-date\">\n                commented <span :title=\"comment.createdAt|formatTime\">{{comment.createdAt|formatTimeAgo}}</span>\n                <span v-if=\"comment.editCount > 0\" :title=\"comment.updatedAt|formatTime\">(edited)</span>\n              </div>\n            </div>\n          </div>\n\n          <div class=\"comments-comment-content\" v-html=\"comment.content\">\n          </div>\n\n          <div v-if=\"$root.can('comment.delete')\" class=\"comments-comment-admin-tools\">\n            <a v-if=\"topic.commentCount > 1\" class=\"a-tool\" role=\"button\" @click=\"delComment(comment.id)\"><i class=\"fa fa-times\" aria-hidden=\"true\"></i> delete comment</a>\n            <span v-if=\"topic.commentCount > 1\" class=\"info-separator\"> | </span>\n            <router-link :to=\"'/u/' + users[comment.authorId].id\" class=\"a-tool\"><i class=\"fa fa-user\" aria-hidden=\"true\"></i> user profile</router-link>\n          </div>\n        \n        </div>\n\n        <div v-if=\"auth.authenticated && page === lastPage\" class=\"comments-comment-new\">\n          <router-link :to=\"'/new-comment/' + topicId\" class=\"btn btn-primary btn-sm\">\n            <i class=\"fa fa-reply\" aria-hidden=\"true\"></i>\n            Reply\n          </router-link>\n        </div>\n\n        <nav v-if=\"lastPage > 1\">\n          <ul class=\"pagination pagination-sm\">\n            <li v-for=\"p in pagination\" :class=\"{active: page === p}\">\n              <span v-if=\"p === '...'\">\u2026</span>\n              <router-link v-if=\"p !== '...'\" :to=\"'/t/' + topicId + '/p/' + p\">{{p}}</router-link>\n            </li>\n          </ul>\n        </nav>\n\n      </div>\n\n    </div>\n  `,\n\n  props: [\"config\", \"auth\"],\n\n  data: function() {\n    return {\n      topic: {},\n      topicReady: false,\n      comments: [],\n      commentCount: 0,\n      commentsReady: false,\n      users: {},\n      usersReady: false,\n      error: false,\n      updated: false,\n      eventSource: null,\n      watching: false,\n      watchingReady: false,\n    };\n  },\n\n  computed: {\n    dataReady: function() {\n      return this.topicReady && this.commentsReady && this.usersReady;\n    },\n\n    topicId: function() {\n      var topicId = parseInt(this.$route.params.topic, 10);\n      if (!topicId) {\n        return 0;\n      }\n      return topicId;\n    },\n\n    page: function() {\n      var page = parseInt(this.$route.params.page, 10);\n      if (!page || page < 1) {\n        return 1;\n      }\n      return page;\n    },\n\n    lastPage: function() {\n      if (!this.commentsReady) {\n        return 1;\n      }\n      var p = Math.floor((this.commentCount - 1) / COMMENTS_PER_PAGE) + 1;\n      if (p < 1) {\n        p = 1;\n      }\n      return p;\n    },\n\n    pagination: function() {\n      if (!this.commentsReady) {\n        return [];\n      }\n      return getPagination(this.page, this.lastPage);\n    },\n  },\n\n  watch: {\n    page: function(val) {\n      this.load();\n    },\n    topicId: function(val) {\n      this.load();\n      this.subscribe();\n      this.getWatching();\n    },\n    \"auth.authenticated\": function(val) {\n      this.getWatching();\n    },\n    dataReady: function(val) {\n      if (val && this.$route.params.comment) {\n        this.$nextTick(() => {\n          $(\"html, body\").animate(\n            {\n              scrollTop: $(\"#comment-\" + this.$route.params.comment).offset().top,\n            },\n            500\n          );\n        });\n      }\n    },\n  },\n\n  created: function() {\n    this.load();\n    this.subscribe();\n    this.getWatching();\n  },\n\n  destroyed: function() {\n    if (this.eventSource) {\n      this.eventSource.close();\n    }\n  },\n\n  methods: {\n    load: function() {\n      this.topic = {};\n      this.topicReady = false;\n      this.comments = [];\n      this.commentCount = 0;\n      this.commentsReady = false;\n      this.users = {};\n      this.usersReady = false;\n      this.waitNewComment = false;\n      this.error = false;\n      this.updated = false;\n      this.getTopic();\n      this.getComments();\n    },\n\n    getTopic: function() {\n      var url = \"api/v1/topics/\" + this.topicId;\n      this.$http.get(url).then(\n        response => {\n          this.topic = response.body.topic;\n          this.topicReady = true;\n        },\n        response => {\n          this.error = true;\n          console.log(\"ERROR: getTopic: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    getComments: function() {\n      var url = \"api/v1/comments?topic=\" + this.topicId + \"&limit=\" + COMMENTS_PER_PAGE;\n      if (this.page > 0) {\n        var offset = (this.page - 1) * COMMENTS_PER_PAGE;\n        url += \"&offset=\" + offset;\n      }\n      this.$http.get(url).then(\n        response => {\n          this.comments = response.body.comments;\n          this.commentCount = response.body.count;\n          for (var i = 0; i < this.comments.length; i++) {\n            this.comments[i].content = marked(this.comments[i].content, {\n              sanitize: true,\n              breaks: true,\n            });\n          }\n          this.commentsReady = true;\n\n          if (this.page > this.lastPage) {\n            this.$parent.$router.replace(\"/t/\" + this.topicId + \"/p/\" + this.lastPage);\n            return;\n          }\n\n          this.getUsers();\n        },\n        response => {\n          this.error = true;\n          console.log(\"ERROR: getComments: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    getUsers: function() {\n      var url = \"api/v1/users\";\n      var ids = [];\n      for (var i = 0; i < this.comments.length; i++) {\n        ids.push(this.comments[i].authorId);\n      }\n      ids = ids.filter((v, i, a) => a.indexOf(v) === i);\n      if (ids.length === 0) {\n        this.users = {};\n        this.usersReady = true;\n        return;\n      }\n      url += \"?ids=\" + ids.join(\",\");\n      this.$http.get(url).then(\n        response => {\n          var users = {};\n          for (var i = 0; i < response.body.users.length; i++) {\n            users[response.body.users[i].id] = response.body.users[i];\n          }\n          this.users = users;\n          this.usersReady = true;\n        },\n        response => {\n          this.error = true;\n          console.log(\"ERROR: getUsers: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    // subscribe shows a notice when the topic is changed by someone else.\n    subscribe: function() {\n      if (this.eventSource) {\n        this.eventSource.close();\n      }\n      this.eventSource = subscribeEvents(this.topicId, e => {\n        this.updated = this.dataReady;\n      });\n    },\n\n    getWatching: function() {\n      this.watchingReady = false;\n      if (!this.auth.authenticated) {\n        return;\n      }\n      var url = \"api/v1/topics/\" + this.topicId + \"/watching\";\n      this.$http.get(url).then(\n        response => {\n          this.watching = response.body.watching;\n          this.watchingReady = true;\n        },\n        response => {\n          console.log(\"ERROR: getWatching: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    setWatching: function(watching) {\n      var url = \"api/v1/topics/\" + this.topicId + \"/watching\";\n      this.$http.put(url, { watching: watching }).then(\n        response => {\n          this.watching = watching;\n        },\n        response => {\n          console.log(\"ERROR: setWatching: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    delComment: function(id) {\n      if (!confirm(\"Are you sure you want to delete comment \" + id + \"?\")) {\n        return;\n      }\n      var url = \"api/v1/comments/\" + id;\n      this.$http.delete(url).then(\n        response => {\n          this.load();\n        },\n        response => {\n          console.log(\"ERROR: delComment: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n  },\n});\n")},
+	"/frontend/js/bebop-init.js":           &fileData{name: "bebop-init.js", mtime: 1792202959, size: 1731, body: []byte("marked.setOptions({\n  sanitize: true,\n  breaks: true,\n});\n\nVue.filter(\"formatTime\", function(value) {\n  if (value) {\n    return moment(String(value)).format(\"MMMM Do YYYY, hh:mm\");\n  }\n});\n\nVue.filter(\"formatTimeAgo\", function(value) {\n  if (value) {\n    return moment(String(value)).fromNow();\n  }\n});\n\nVue.filter(\"capitalize\", function(value) {\n  if (value) {\n    value = String(value);\n    return value[0].toUpperCase() + value.slice(1);\n  }\n});\n\nfunction getPagination(curPage, lastPage) {\n  var pagination = [];\n  var lr = 2;\n\n  pagination.push(1);\n\n  if (curPage - lr > 2) {\n    pagination.push(\"...\");\n  }\n\n  for (var p = curPage - lr; p <= curPage + lr; p++) {\n    if (p > 1 && p < lastPage) {\n      pagination.push(p);\n    }\n  }\n\n  if (curPage + lr < lastPage - 1) {\n    pagination.push(\"...\");\n  }\n\n  if (lastPage > 1) {\n    pagination.push(lastPage);\n  }\n\n  return pagination;\n}\n\n// BEBOP_EVENT_TYPES are the content event types streamed by the API.\nconst BEBOP_EVENT_TYPES = [\"topic.created\", \"topic.deleted\", \"comment.created\", \"comment.deleted\", \"reset\"];\n\n// subscribeEvents opens the stream of the content events, limited to one topic\n// if topicId is not zero, and calls onEvent for every event. The browser reconnects\n// and resumes the stream automatically. It returns null if streaming is not supported.\nfunction subscribeEvents(topicId, onEvent) {\n  if (typeof EventSource === \"undefined\") {\n    return null;\n  }\n  var url = \"api/v1/events\";\n  if (topicId) {\n    url += \"?topic=\" + topicId;\n  }\n  var source = new EventSource(url);\n  for (var i = 0; i < BEBOP_EVENT_TYPES.length; i++) {\n    source.addEventListener(BEBOP_EVENT_TYPES[i], e => {\n      onEvent(JSON.parse(e.data));\n    });\n  }\n  return source;\n}\n")},
+	"/frontend/js/bebop-local-auth.js":     &fileData{name: "bebop-local-auth.js", mtime: 1792205301, size: 7952, body: []byte("// bebopLocalAuthErrors maps the local auth API error codes to messages.\nvar bebopLocalAuthErrors = {\n  BadRequest: \"Please enter a valid email and a password of 8 to 72 characters.\",\n  InvalidCredentials: \"Invalid email or password.\",\n  EmailNotVerified: \"Please confirm your email first. We can send you a new confirmation link.\",\n  InvalidToken: \"This link is invalid or has expired.\",\n  UserBlocked: \"This user is blocked.\",\n};\n\nfunction bebopLocalAuthError(response) {\n  var code = response.data && response.data.error ? response.data.error.code : \"\";\n  return bebopLocalAuthErrors[code] || \"An error occured.\";\n}\n\nvar BebopLocalAuthModal = Vue.component(\"bebop-local-auth-modal\", {\n  template: `\n    <div class=\"modal fade\" id=\"local-auth-modal\" tabindex=\"-1\" role=\"dialog\">\n      <div class=\"modal-dialog\" role=\"document\">\n        <div class=\"modal-content\">\n          <div class=\"modal-header\">\n            <button type=\"button\" class=\"close\" data-dismiss=\"modal\"><span>&times;</span></button>\n            <h2 class=\"modal-title\">{{titles[mode]}}</h2>\n          </div>\n          <div class=\"modal-body\">\n            <div v-if=\"message\" class=\"alert alert-success\" role=\"alert\">\n              {{message}}\n            </div>\n            <template v-else>\n              <div class=\"form-group\">\n                <label for=\"local-auth-email\" class=\"form-control-label\">Email:</label>\n                <input type=\"email\" class=\"form-control\" id=\"local-auth-email\" v-model=\"email\" @keyup=\"hideErrorMessage\" @keyup.13=\"send\">\n              </div>\n              <div class=\"form-group\" v-if=\"mode === 'signIn' || mode === 'register'\">\n                <label for=\"local-auth-password\" class=\"form-control-label\">Password:</label>\n                <input type=\"password\" class=\"form-control\" id=\"local-auth-password\" v-model=\"password\" @keyup=\"hideErrorMessage\" @keyup.13=\"send\">\n              </div>\n            </template>\n            <div class=\"alert alert-danger\" :class=\"{hidden: errorMessage===''}\" role=\"alert\" style=\"cursor:pointer\" @click=\"hideErrorMessage\">\n              {{errorMessage}}\n              <a v-if=\"unverified\" href=\"#\" @click.prevent=\"resendVerification\">Resend the link.</a>\n            </div>\n            <div v-if=\"!message\">\n              <a v-if=\"mode !== 'signIn'\" href=\"#\" @click.prevent=\"setMode('signIn')\">Sign in</a>\n              <a v-if=\"mode !== 'register'\" href=\"#\" @click.prevent=\"setMode('register')\">Create an account</a>\n              <a v-if=\"mode !== 'reset'\" href=\"#\" @click.prevent=\"setMode('reset')\">Forgot password?</a>\n              <a v-if=\"mode !== 'magic' && config.magicLinks\" href=\"#\" @click.prevent=\"setMode('magic')\">Email me a sign in link</a>\n            </div>\n          </div>\n          <div class=\"modal-footer\">\n            <button type=\"button\" class=\"btn btn-default\" data-dismiss=\"modal\">{{message ? \"Close\" : \"Cancel\"}}</button>\n            <button v-if=\"!message\" type=\"button\" class=\"btn btn-primary\" @click=\"send\" :disabled=\"sending\">{{titles[mode]}}</button>\n          </div>\n        </div>\n      </div>\n    </div>\n  `,\n\n  props: [\"config\"],\n\n  data: function() {\n    return {\n      mode: \"signIn\",\n      email: \"\",\n      password: \"\",\n      message: \"\",\n      errorMessage: \"\",\n      unverified: false,\n      sending: false,\n      titles: {\n        signIn: \"Sign in\",\n        register: \"Create an account\",\n        reset: \"Reset password\",\n        magic: \"Send a sign in link\",\n      },\n    };\n  },\n\n  mounted: function() {\n    $(\"#local-auth-modal\").on(\"shown.bs.modal\", () => {\n      $(\"#local-auth-email\")[0].focus();\n    });\n  },\n\n  methods: {\n    show: function() {\n      this.setMode(\"signIn\");\n      this.password = \"\";\n      $(\"#local-auth-modal\").modal(\"show\");\n    },\n\n    setMode: function(mode) {\n      this.mode = mode;\n      this.message = \"\";\n      this.hideErrorMessage();\n    },\n\n    send: function() {\n      var requests = {\n        signIn: [\"api/v1/auth/login\", { email: this.email, password: this.password }],\n        register: [\"api/v1/auth/register\", { email: this.email, password: this.password }],\n        reset: [\"api/v1/auth/password-reset\", { email: this.email }],\n        magic: [\"api/v1/auth/magic-link\", { email: this.email }],\n      };\n      var messages = {\n        register: \"Almost done! Open the link we have sent to \" + this.email + \" to continue.\",\n        reset: \"If an account with this email exists, we have sent it a link to set a new password.\",\n        magic: \"If an account with this email exists, we have sent it a sign in link.\",\n      };\n\n      this.sending = true;\n      this.$http.post(requests[this.mode][0], requests[this.mode][1]).then(\n        response => {\n          this.sending = false;\n          if (this.mode === \"signIn\") {\n            $(\"#local-auth-modal\").modal(\"hide\");\n            this.$parent.oauthSuccess(response.body.accessToken, response.body.refreshToken);\n            return;\n          }\n          this.message = messages[this.mode];\n        },\n        response => {\n          this.sending = false;\n          this.unverified = response.data.error && response.data.error.code === \"EmailNotVerified\";\n          this.errorMessage = bebopLocalAuthError(response);\n        }\n      );\n    },\n\n    resendVerification: function() {\n      this.$http.post(\"api/v1/auth/verify/resend\", { email: this.email }).then(\n        response => {\n          this.message = \"We have sent a new confirmation link to \" + this.email + \".\";\n          this.hideErrorMessage();\n        },\n        response => {\n          this.errorMessage = bebopLocalAuthError(response);\n        }\n      );\n    },\n\n    hideErrorMessage: function() {\n      this.errorMessage = \"\";\n      this.unverified = false;\n    },\n  },\n});\n\n// BebopLocalAuthLink handles the links sent by email.\nvar BebopLocalAuthLink = Vue.component(\"bebop-local-auth-link\", {\n  template: `\n    <div class=\"container\">\n      <div class=\"row\">\n        <div class=\"col-sm-6 col-sm-offset-3\">\n          <h2>{{$route.params.action === \"reset\" ? \"Set a new password\" : \"Signing in\"}}</h2>\n          <div v-if=\"$route.params.action === 'reset' && !failed\">\n            <div class=\"form-group\">\n              <label for=\"local-auth-new-password\" class=\"form-control-label\">New password:</label>\n              <input type=\"password\" class=\"form-control\" id=\"local-auth-new-password\" v-model=\"password\" @keyup.13=\"send\">\n            </div>\n            <button type=\"button\" class=\"btn btn-primary\" @click=\"send\" :disabled=\"sending\">Set password</button>\n          </div>\n          <div v-else-if=\"!failed\">\n            <i class=\"fa fa-spinner fa-spin\"></i>\n          </div>\n          <div class=\"alert alert-danger\" :class=\"{hidden: errorMessage===''}\" role=\"alert\">\n            {{errorMessage}}\n          </div>\n        </div>\n      </div>\n    </div>\n  `,\n\n  props: [\"config\", \"auth\"],\n\n  data: function() {\n    return {\n      password: \"\",\n      errorMessage: \"\",\n      failed: false,\n      sending: false,\n    };\n  },\n\n  mounted: function() {\n    if (this.$route.params.action !== \"reset\") {\n      this.send();\n    }\n  },\n\n  methods: {\n    send: function() {\n      var urls = {\n        verify: \"api/v1/auth/verify\",\n        reset: \"api/v1/auth/password-reset/confirm\",\n        magic: \"api/v1/auth/magic-link/confirm\",\n      };\n      var url = urls[this.$route.params.action];\n      if (!url) {\n        this.failed = true;\n        this.errorMessage = bebopLocalAuthErrors.InvalidToken;\n        return;\n      }\n\n      this.sending = true;\n      this.$http.post(url, { token: this.$route.params.token, password: this.password }).then(\n        response => {\n          this.$root.oauthSuccess(response.body.accessToken, response.body.refreshToken);\n          this.$router.replace(\"/\");\n        },\n        response => {\n          this.sending = false;\n          this.failed = response.status !== 400;\n          this.errorMessage = bebopLocalAuthError(response);\n        }\n      );\n    },\n  },\n});\n")},
+	"/frontend/js/bebop-nav.js":            &fileData{name: "bebop-nav.js", mtime: 1792202674, size: 3653, body: []byte("Vue.component(\"bebop-nav\", {\n  template: `\n    <nav class=\"navbar navbar-default navbar-fixed-top\">\n      <div class=\"container\">\n        <div class=\"navbar-header pull-left\">\n          <router-link to=\"/\" class=\"navbar-brand\">\n            <span class=\"navbar-title\">\n              <i class=\"fa fa-comments\"></i>\n              {{ config.title }}\n            </span>\n          </router-link>\n        </div>\n        <div class=\"navbar-header pull-right\">\n          <ul class=\"nav pull-left\">\n            <li v-if=\"auth.authenticated\" class=\"pull-left\">\n              <router-link to=\"/notifications\" class=\"navbar-link navbar-notifications\" title=\"Notifications\">\n                <i class=\"fa fa-bell-o\"></i>\n                <span v-if=\"auth.unreadNotifications > 0\" class=\"badge\">{{auth.unreadNotifications}}</span>\n              </router-link>\n            </li>\n            <li v-if=\"auth.authenticated\" class=\"pull-left\">\n              <a class=\"navbar-link dropdown-toggle navbar-user\" role=\"button\" data-toggle=\"dropdown\" :title=\"auth.user.name\">\n                <img v-if=\"auth.user.avatar\" class=\"img-circle\" :src=\"auth.user.avatar\" width=\"35\" height=\"35\"> \n                <img v-else class=\"img-circle\" src=\"data:image/gif;base64,R0lGODlhAQABAIAAAP///wAAACH5BAEAAAAALAAAAAABAAEAAAICRAEAOw==\" width=\"35\" height=\"35\"> \n                <span class=\"caret\"></span>\n              </a>\n              <ul class=\"dropdown-menu pull-right\">\n                <li>\n                  <router-link to=\"/me\">\n                    <i class=\"fa fa-user icon-s\"></i>\n                    {{auth.user.name}}\n                  </router-link>\n                </li>\n                <li>\n                  <router-link to=\"/notifications\">\n                    <i class=\"fa fa-bell icon-s\"></i>\n                    Notifications\n                  </router-link>\n                </li>\n                <li role=\"separator\" class=\"divider\"></li>\n                <li>\n                  <a href=\"#\" @click.prevent=\"$parent.signOut()\">\n                    <i class=\"fa fa-sign-out icon-s\"></i>\n                    Sign out\n                  </a>\n                </li>\n              </ul>\n            </li>\n            <li v-else>\n              <a class=\"navbar-link dropdown-toggle navbar-sign-in\" href=\"#\" data-toggle=\"dropdown\">\n                <i class=\"fa fa-user icon-s\"></i>\n                Sign In / Up \n                <span class=\"caret\"></span>\n              </a>\n              <ul class=\"dropdown-menu pull-right\">\n                <li v-for=\"provider in config.oauth\">\n                  <a href=\"#\" @click.prevent=\"$parent.signIn(provider)\">\n                    <i :class=\"'icon-s fa fa-' + providerIcon(provider)\" aria-hidden=\"true\"></i>\n                    with {{provider|capitalize}}\n                  </a>\n                </li>\n                <li v-if=\"config.localAuth\">\n                  <a href=\"#\" @click.prevent=\"$parent.$refs.localAuthModal.show()\">\n                    <i class=\"icon-s fa fa-envelope\" aria-hidden=\"true\"></i>\n                    with Email\n                  </a>\n                </li>\n              </ul>\n            </li>\n          </ul>\n        </div>\n      </div>\n    </nav>\n  `,\n\n  props: [\"config\", \"auth\"],\n\n  data: function() {\n    return {};\n  },\n\n  methods: {\n    // providerIcon returns the Font Awesome icon name of an oauth provider.\n    providerIcon: function(provider) {\n      var icons = {\n        google: \"google\",\n        facebook: \"facebook\",\n        github: \"github\",\n        gitlab: \"gitlab\",\n        microsoft: \"windows\",\n        twitch: \"twitch\",\n      };\n      return icons[provider] || \"sign-in\";\n    },\n  },\n});\n")},
 	"/frontend/js/bebop-new-comment.js":    &fileData{name: "bebop-new-comment.js", mtime: 1495846124, size: 2234, body: []byte("var BebopNewComment = Vue.component(\"bebop-new-comment\", {\n  template: `\n    <div class=\"container content-container\">\n      <h2>New Comment</h2>\n      <div>\n        <div class=\"form-group\">\n          <label for=\"user-name\" class=\"form-control-label\">Comment:</label>\n          <textarea class=\"form-control\" id=\"comment-input\" @change=\"hideErrorMessage\" @keyup=\"hideErrorMessage\" maxlength=\"10000\"></textarea>\n        </div>\n        <div id=\"form-error\" class=\"alert alert-danger\" :class=\"{hidden: errorMessage===''}\" role=\"alert\" style=\"cursor:pointer\" @click=\"hideErrorMessage\">\n          {{errorMessage}}\n        </div>\n      </div>\n      <div>\n        <button type=\"button\" class=\"btn btn-primary btn-sm\" @click=\"postComment\" :disabled=\"posting\">\n          <i class=\"fa fa-reply\"></i> Reply\n        </button>\n      </div>\n    </div>\n  `,\n\n  props: [\"config\", \"auth\"],\n\n  data: function() {\n    return {\n      errorMessage: \"\",\n      posting: false,\n    };\n  },\n\n  mounted: function() {\n    $(\"#comment-input\").markdown({\n      iconlibrary: \"fa\",\n      fullscreen: {\n        enable: false,\n      },\n    });\n  },\n\n  methods: {\n    postComment: function() {\n      var topicId = parseInt(this.$route.params.topic, 10);\n      var comment = $(\"#comment-input\").val().trim();\n      if (comment.length < 1 || comment.length > 10000) {\n        this.showErrorMessage(\"Invalid comment\");\n        return;\n      }\n      this.posting = true;\n      this.$http\n        .post(\"api/v1/comments\", {\n          topic: topicId,\n          content: comment,\n        })\n        .then(\n          response => {\n            var id = response.data.id;\n            var page = Math.floor((response.data.count - 1) / COMMENTS_PER_PAGE) + 1;\n            this.posting = false;\n            this.$parent.$router.push(\"/t/\" + topicId + \"/p/\" + page + /c/ + id);\n          },\n          response => {\n            this.posting = false;\n            this.showErrorMessage(\"An error occured\");\n            console.log(\"ERROR: postComment: \" + JSON.stringify(response.body));\n          }\n        );\n    },\n\n    showErrorMessage: function(message) {\n      this.errorMessage = message;\n    },\n\n    hideErrorMessage: function() {\n      this.errorMessage = \"\";\n    },\n  },\n});\n")},
 	"/frontend/js/bebop-new-topic.js":      &fileData{name: "bebop-new-topic.js", mtime: 1495846124, size: 2474, body: []byte("var BebopNewTopic = Vue.component(\"bebop-new-topic\", {\n  template: `\n    <div class=\"container content-container\">\n      <h2>New Topic</h2>\n      <div>\n        <div class=\"form-group\">\n          <label for=\"user-name\" class=\"form-control-label\">Title:</label>\n          <input type=\"text\" class=\"form-control\" id=\"topic-title-input\" @change=\"hideErrorMessage\" @keyup=\"hideErrorMessage\" maxlength=\"100\">\n        </div>\n        <div class=\"form-group\">\n          <label for=\"user-name\" class=\"form-control-label\">Comment:</label>\n          <textarea class=\"form-control\" id=\"comment-input\" @change=\"hideErrorMessage\" @keyup=\"hideErrorMessage\" maxlength=\"10000\"></textarea>\n        </div>\n        <div id=\"form-error\" class=\"alert alert-danger\" :class=\"{hidden: errorMessage===''}\" role=\"alert\" style=\"cursor:pointer\" @click=\"hideErrorMessage\">\n          {{errorMessage}}\n        </div>\n      </div>\n      <div>\n        <button type=\"button\" class=\"btn btn-primary btn-sm\" @click=\"postTopic\" :disabled=\"posting\">\n          <i class=\"fa fa-plus\"></i> Create Topic\n        </button>\n      </div>\n    </div>\n  `,\n\n  props: [\"config\", \"auth\"],\n\n  data: function() {\n    return {\n      errorMessage: \"\",\n      posting: false,\n    };\n  },\n\n  mounted: function() {\n    $(\"#comment-input\").markdown({\n      iconlibrary: \"fa\",\n      fullscreen: {\n        enable: false,\n      },\n    });\n  },\n\n  methods: {\n    postTopic: function() {\n      var title = $(\"#topic-title-input\").val().trim();\n      if (title.length < 1 || title.length > 100) {\n        this.showErrorMessage(\"Invalid topic title\");\n        return;\n      }\n      var comment = $(\"#comment-input\").val().trim();\n      if (comment.length < 1 || comment.length > 10000) {\n        this.showErrorMessage(\"Invalid comment\");\n        return;\n      }\n      this.posting = true;\n      this.$http\n        .post(\"api/v1/topics\", {\n          title: title,\n          content: comment,\n        })\n        .then(\n          response => {\n            this.posting = false;\n            this.$parent.$router.push(\"/t/\" + response.data.id);\n          },\n          response => {\n            this.posting = false;\n            this.showErrorMessage(\"An error occured\");\n            console.log(\"ERROR: postTopic: \" + JSON.stringify(response.body));\n          }\n        );\n    },\n\n    showErrorMessage: function(message) {\n      this.errorMessage = message;\n    },\n\n    hideErrorMessage: function() {\n      this.errorMessage = \"\";\n    },\n  },\n});\n")},
-	"/frontend/js/bebop-topics.js":         &fileData{name: "bebop-topics.js", mtime: 1495846124, size: 6491, body: []byte("const TOPICS_PER_PAGE = 20;\n\nvar BebopTopics = Vue.component(\"bebop-topics\", {\n  template: `\n    <div class=\"container content-container\">\n\n      <div v-if=\"!dataReady\" class=\"loading-info\">\n        <div v-if=\"error\" >\n          <p class=\"text-danger\">\n            Sorry, could not load topics. Please check your connection.\n          </p>\n          <a class=\"btn btn-primary btn-sm\" role=\"button\" @click=\"load\">\n            <i class=\"fa fa-refresh\"></i> Try Again\n          </a>\n        </div>\n        <div v-else>\n          <i class=\"fa fa-circle-o-notch fa-spin fa-3x fa-fw\"></i>\n        </div>\n      </div>\n      <div v-else>\n\n        <div class=\"topics-topic-top-buttons\">\n          <router-link v-if=\"auth.authenticated\" to=\"/new-topic\" class=\"btn btn-primary btn-sm\">\n            <i class=\"fa fa-plus\"></i> New Topic\n          </router-link>\n          <a class=\"btn btn-primary btn-sm\" role=\"button\" @click=\"load\">\n            <i class=\"fa fa-refresh\"></i> Refresh\n          </a>\n        </div>\n\n        <nav v-if=\"page > 1\">\n          <ul class=\"pagination pagination-sm\">\n            <li v-for=\"p in pagination\" :class=\"{active: page === p}\">\n              <span v-if=\"p === '...'\">\u2026</span>\n              <router-link v-if=\"p !== '...'\" :to=\"'/p/' + p\">{{p}}</router-link>\n            </li>\n          </ul>\n        </nav>\n\n        <div v-for=\"topic in topics\" class=\"card topics-topic\">\n          <div class=\"avatar-block\">\n            <div class=\"avatar-block-l\">\n              <img v-if=\"users[topic.authorId].avatar\" class=\"img-circle\" :src=\"users[topic.authorId].avatar\" width=\"40\" height=\"40\"> \n              <img v-else class=\"img-circle\" src=\"data:image/gif;base64,R0lGODlhAQABAIAAAP///wAAACH5BAEAAAAALAAAAAABAAEAAAICRAEAOw==\" width=\"40\" height=\"40\"> \n            </div>\n            <div class=\"avatar-block-r\">\n              <div class=\"topics-topic-title\">\n                <router-link :to=\"'/t/' + topic.id\">{{topic.title}}</router-link>\n              </div>\n              <div class=\"topics-topic-info\">\n                <i class=\"fa fa-user-o\"></i> {{users[topic.authorId].name}}\n                <span class=\"info-separator\"> | </span>\n                <i class=\"fa fa-comment-o\"></i> {{topic.commentCount}}\n                <span class=\"info-separator\"> | </span>\n                <i class=\"fa fa-clock-o\"></i> <span :title=\"topic.lastCommentAt|formatTime\">{{topic.lastCommentAt|formatTimeAgo}}</span>\n              </div>\n              <div class=\"topics-topic-admin-tools\" v-if=\"auth.authenticated && auth.user.admin\">\n                <a class=\"a-tool\" role=\"button\" @click=\"delTopic(topic.id)\"><i class=\"fa fa-times\" aria-hidden=\"true\"></i> delete topic</a>\n                <span class=\"info-separator\"> | </span> \n                <router-link :to=\"'/u/' + users[topic.authorId].id\" class=\"a-tool\"><i class=\"fa fa-user\" aria-hidden=\"true\"></i> user profile</router-link>\n              </div>\n            </div>\n          </div>\n        </div>\n\n        <nav v-if=\"lastPage > 1\">\n          <ul class=\"pagination pagination-sm\">\n            <li v-for=\"p in pagination\" :class=\"{active: page === p}\">\n              <span v-if=\"p === '...'\">\u2026</span>\n              <router-link v-if=\"p !== '...'\" :to=\"'/p/' + p\">{{p}}</router-link>\n            </li>\n          </ul>\n        </nav>\n\n      </div>\n\n    </div>\n  `,\n\n  props: [\"config\", \"auth\"],\n\n  data: function() {\n    return {\n      topics: [],\n      topicsReady: false,\n      topicCount: 0,\n      users: {},\n      usersReady: false,\n      error: false,\n    };\n  },\n\n  computed: {\n    dataReady: function() {\n      return this.topicsReady && this.usersReady;\n    },\n\n    page: function() {\n      var page = parseInt(this.$route.params.page, 10);\n      if (!page || page < 1) {\n        return 1;\n      }\n      return page;\n    },\n\n    lastPage: function() {\n      if (!this.topicsReady) {\n        return 1;\n      }\n      var p = Math.floor((this.topicCount - 1) / TOPICS_PER_PAGE) + 1;\n      if (p < 1) {\n        p = 1;\n      }\n      return p;\n    },\n\n    pagination: function() {\n      if (!this.topicsReady) {\n        return [];\n      }\n      return getPagination(this.page, this.lastPage);\n    },\n  },\n\n  watch: {\n    page: function(val) {\n      this.load();\n    },\n  },\n\n  created: function() {\n    this.load();\n  },\n\n  methods: {\n    load: function() {\n      this.topics = [];\n      this.topicsReady = false;\n      this.topicCount = 0;\n      this.users = {};\n      this.usersReady = false;\n      this.error = false;\n      this.getTopics();\n    },\n\n    getTopics: function() {\n      var url = \"api/v1/topics?limit=\" + TOPICS_PER_PAGE;\n      if (this.page > 1) {\n        var offset = (this.page - 1) * TOPICS_PER_PAGE;\n        url += \"&offset=\" + offset;\n      }\n      this.$http.get(url).then(\n        response => {\n          this.topics = response.body.topics;\n          this.topicCount = response.body.count;\n          this.topicsReady = true;\n\n          if (this.page > this.lastPage) {\n            this.$parent.$router.replace(\"/p/\" + this.lastPage);\n            return;\n          }\n\n          this.getUsers();\n        },\n        response => {\n          this.error = true;\n          console.log(\"ERROR: getTopics: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    getUsers: function() {\n      var url = \"api/v1/users\";\n      var ids = [];\n      for (var i = 0; i < this.topics.length; i++) {\n        ids.push(this.topics[i].authorId);\n      }\n      ids = ids.filter((v, i, a) => a.indexOf(v) === i);\n      if (ids.length === 0) {\n        this.users = {};\n        this.usersReady = true;\n        return;\n      }\n      url += \"?ids=\" + ids.join(\",\");\n      this.$http.get(url).then(\n        response => {\n          var users = {};\n          for (var i = 0; i < response.body.users.length; i++) {\n            users[response.body.users[i].id] = response.body.users[i];\n          }\n          this.users = users;\n          this.usersReady = true;\n        },\n        response => {\n          this.error = true;\n          console.log(\"ERROR: getUsers: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    delTopic: function(id) {\n      if (!confirm(\"Are you sure you want to delete topic \" + id + \"?\")) {\n        return;\n      }\n      var url = \"api/v1/topics/\" + id;\n      this.$http.delete(url).then(\n        response => {\n          this.load();\n        },\n        response => {\n          console.log(\"ERROR: delTopic: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n  },\n});\n")},
-	"/frontend/js/bebop-user.js":           &fileData{name: "bebop-user.js", mtime: 1495846124, size: 7799, body: []byte("var BebopUser = Vue.component(\"bebop-user\", {\n  template: `\n    <div class=\"container content-container\">\n\n      <div v-if=\"!dataReady\" class=\"loading-info\">\n        <div v-if=\"error\" >\n          <p class=\"text-danger\">\n            Sorry, could not load the user profile. Please check your connection.\n          </p>\n          <a class=\"btn btn-primary btn-sm\" role=\"button\" @click=\"load\">\n            <i class=\"fa fa-refresh\"></i> Try Again\n          </a>\n        </div>\n        <div v-else>\n          <i class=\"fa fa-circle-o-notch fa-spin fa-3x fa-fw\"></i>\n        </div>\n      </div>\n      <div v-else>\n\n        <h2 v-if=\"isMe\">My profile</h2>\n        <h2 v-else>User profile: {{user.name}}</h2>\n\n        <div class=\"card user-profile\">\n\n          <div class=\"row\">\n            <div class=\"col-xs-3\">\n              Username\n            </div>\n            <div class=\"col-xs-6\">\n              {{user.name}}\n            </div>\n            <div class=\"col-xs-3 text-right\">\n              <label class=\"btn btn-fix\" :class=\"{'btn-default': isMe, 'btn-danger': !isMe}\" role=\"button\" @click=\"changeUsername()\"><i class=\"fa fa-pencil-square-o\" aria-hidden=\"true\"></i></label>\n            </div>\n          </div>\n\n          <hr>\n\n          <div class=\"row\">\n            <div class=\"col-xs-3\">\n              Avatar\n            </div>\n            <div class=\"col-xs-6\">\n              <div v-if=\"uploadingAvatar\">\n                <i class=\"fa fa-circle-o-notch fa-spin fa-2x fa-fw\"></i>\n              </div>\n              <div v-else>\n                <img v-if=\"user.avatar\" class=\"img-circle\" :src=\"user.avatar\" width=\"35\" height=\"35\"> \n                <img v-else class=\"img-circle\" src=\"data:image/gif;base64,R0lGODlhAQABAIAAAP///wAAACH5BAEAAAAALAAAAAABAAEAAAICRAEAOw==\" width=\"35\" height=\"35\"> \n              </div>\n            </div>\n            <div class=\"col-xs-3 text-right\">\n              <label for=\"avatar-upload-input\" class=\"btn btn-fix\" :class=\"{'btn-default': isMe, 'btn-danger': !isMe}\" role=\"button\">\n                <i class=\"fa fa-cloud-upload\"></i>\n              </label>\n              <input id=\"avatar-upload-input\" class=\"hidden\" type=\"file\" @change=\"uploadAvatar()\"/>\n            </div>\n          </div>\n          <div v-if=\"avatarUploadError\" class=\"row\">\n            <div class=\"col-xs-12\">\n              <div class=\"alert alert-danger\" style=\"margin-top:10px\">{{avatarUploadError}}</div>\n            </div>\n          </div>\n\n          <hr>\n\n          <div class=\"row\">\n            <div class=\"col-xs-3\">\n              Sign in with\n            </div>\n            <div class=\"col-xs-6\">\n              {{user.authService|capitalize}}\n            </div>\n          </div>\n\n          <hr>\n\n          <div class=\"row\">\n            <div class=\"col-xs-3\">\n              Activated\n            </div>\n            <div class=\"col-xs-6\">\n              {{user.createdAt|formatTime}}\n            </div>\n          </div>\n          \n          <hr v-if=\"auth.authenticated && auth.user.admin && !isMe\">\n\n          <div v-if=\"auth.authenticated && auth.user.admin && !isMe\" class=\"row\">\n            <div class=\"col-xs-3\">\n              Blocked\n            </div>\n            <div class=\"col-xs-6\">\n              <span v-if=\"user.blocked\" class=\"text-danger\">Yes</span>\n              <span v-else class=\"text-success\">No</span>\n            </div>\n            <div class=\"col-xs-3 text-right\">\n              <button v-if=\"user.blocked\" class=\"btn btn-danger btn-fix\" @click=\"setBlocked(false)\"><i class=\"fa fa-unlock-alt\" aria-hidden=\"true\"></i></button>\n              <button v-else class=\"btn btn-danger btn-fix\" @click=\"setBlocked(true)\"><i class=\"fa fa-lock\" aria-hidden=\"true\"></i></button>\n            </div>\n          </div>\n\n        </div>\n      </div>\n\n    </div>\n  `,\n\n  props: [\"config\", \"auth\"],\n\n  data: function() {\n    return {\n      user: {},\n      userReady: false,\n      error: false,\n      uploadingAvatar: false,\n      avatarUploadError: \"\",\n    };\n  },\n\n  computed: {\n    dataReady: function() {\n      return this.userReady;\n    },\n\n    userId: function() {\n      if (!this.auth.authenticated) {\n        return 0;\n      }\n\n      var userId = parseInt(this.$route.params.user, 10);\n      if (!userId) {\n        return this.auth.user.id;\n      }\n\n      return userId;\n    },\n\n    isMe: function() {\n      if (!this.auth.authenticated) {\n        return false;\n      }\n      return this.userId === this.auth.user.id;\n    },\n  },\n\n  watch: {\n    userId: function(val) {\n      this.load();\n    },\n  },\n\n  created: function() {\n    this.load();\n  },\n\n  methods: {\n    load: function() {\n      this.user = {};\n      this.userReady = false;\n      this.error = false;\n      this.uploadingAvatar = false;\n      this.avatarUploadError = \"\";\n      this.getUser();\n    },\n\n    getUser: function() {\n      if (!this.auth.authenticated) {\n        this.$parent.$router.replace(\"/\");\n        return;\n      }\n\n      if (!this.auth.user.admin && this.auth.user.id !== this.userId) {\n        this.$parent.$router.replace(\"/me\");\n        return;\n      }\n\n      var url = \"api/v1/me\";\n      if (this.auth.user.id !== this.userId) {\n        url = \"api/v1/users/\" + this.userId;\n      }\n\n      this.$http.get(url).then(\n        response => {\n          this.user = response.body.user;\n          this.userReady = true;\n        },\n        response => {\n          console.log(\"ERROR: getUser: \" + JSON.stringify(response.body));\n          this.error = true;\n        }\n      );\n    },\n\n    changeUsername: function() {\n      if (!this.userReady) {\n        return;\n      }\n      this.$parent.$refs.usernameModal.show(this.userId, this.user.name, success => {\n        if (success) {\n          if (this.isMe) {\n            this.$parent.getMe();\n          }\n          this.load();\n        }\n      });\n    },\n\n    uploadAvatar: function() {\n      var input = document.getElementById(\"avatar-upload-input\");\n      var file = input.files[0];\n      input.value = \"\";\n      var reader = new FileReader();\n      reader.onload = () => {\n        var parts = reader.result.split(\";base64,\");\n        var imageData = \"\";\n        if (parts.length === 2) {\n          imageData = parts[1];\n        }\n        this.putUserAvatar(imageData);\n      };\n      reader.readAsDataURL(file);\n    },\n\n    putUserAvatar: function(imageData) {\n      if (!this.userReady) {\n        return;\n      }\n      this.uploadingAvatar = true;\n      this.avatarUploadError = \"\";\n      this.$http.put(\"api/v1/users/\" + this.userId + \"/avatar\", { avatar: imageData }).then(\n        response => {\n          if (this.isMe) {\n            this.$parent.getMe();\n          }\n          this.uploadingAvatar = false;\n          this.load();\n        },\n        response => {\n          console.log(\"ERROR: putUserAvatar: \" + JSON.stringify(response.body));\n          this.uploadingAvatar = false;\n          var error = \"Sorry, could not upload that image. An error occured.\";\n          if (response.body.error && response.body.error.code === \"BadRequest\") {\n            error = \"Sorry, could not upload that image. \";\n            error += \"Please choose an image from 50x50 to 2000x2000 pixels in size. \";\n            error += \"The supported formats are JPEG, PNG, GIF, TIFF, BMP. \";\n            error += \"The maximum file size is 5MB.\";\n          }\n          this.avatarUploadError = error;\n        }\n      );\n    },\n\n    setBlocked(val) {\n      action = val ? \"block\" : \"unblock\";\n      if (!confirm(\"Are you sure you want to \" + action + \" this user?\")) {\n        return;\n      }\n      this.$http.put(\"api/v1/users/\" + this.userId + \"/blocked\", { blocked: val }).then(\n        response => {\n          this.load();\n        },\n        response => {\n          console.log(\"ERROR: setBlocked: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n  },\n});\n")},
+	"/frontend/js/bebop-notifications.js":  &fileData{name: "bebop-notifications.js", mtime: 1792202674, size: 6448, body: []byte("const NOTIFICATIONS_PER_PAGE = 20;\n\nvar BebopNotifications = Vue.component(\"bebop-notifications\", {\n  template: `\n    <div class=\"container content-container\">\n\n      <div v-if=\"!dataReady\" class=\"loading-info\">\n        <div v-if=\"error\" >\n          <p class=\"text-danger\">\n            Sorry, could not load notifications. Please check your connection.\n          </p>\n          <a class=\"btn btn-primary btn-sm\" role=\"button\" @click=\"load\">\n            <i class=\"fa fa-refresh\"></i> Try Again\n          </a>\n        </div>\n        <div v-else>\n          <i class=\"fa fa-circle-o-notch fa-spin fa-3x fa-fw\"></i>\n        </div>\n      </div>\n      <div v-else>\n\n        <div class=\"topics-topic-top-buttons\">\n          <a class=\"btn btn-primary btn-sm\" role=\"button\" @click=\"markAllRead\">\n            <i class=\"fa fa-check\"></i> Mark All Read\n          </a>\n          <a class=\"btn btn-primary btn-sm\" role=\"button\" @click=\"load\">\n            <i class=\"fa fa-refresh\"></i> Refresh\n          </a>\n        </div>\n\n        <div v-if=\"notifications.length === 0\" class=\"card notifications-empty\">\n          No notifications yet.\n        </div>\n\n        <div v-for=\"n in notifications\" :class=\"{card: true, 'notifications-notification': true, 'notifications-unread': !n.read}\">\n          <div class=\"avatar-block\">\n            <div class=\"avatar-block-l\">\n              <img v-if=\"users[n.actorId].avatar\" class=\"img-circle\" :src=\"users[n.actorId].avatar\" width=\"40\" height=\"40\"> \n              <img v-else class=\"img-circle\" src=\"data:image/gif;base64,R0lGODlhAQABAIAAAP///wAAACH5BAEAAAAALAAAAAABAAEAAAICRAEAOw==\" width=\"40\" height=\"40\"> \n            </div>\n            <div class=\"avatar-block-r\">\n              <div class=\"topics-topic-title\">\n                <a href=\"#\" @click.prevent=\"open(n)\">\n                  {{users[n.actorId].name}}\n                  <span v-if=\"n.type === 'mention'\">mentioned you in</span>\n                  <span v-else>replied to</span>\n                  {{n.topicTitle}}\n                </a>\n              </div>\n              <div class=\"topics-topic-info\">\n                <i :class=\"n.type === 'mention' ? 'fa fa-at' : 'fa fa-reply'\"></i> {{n.type}}\n                <span class=\"info-separator\"> | </span>\n                <i class=\"fa fa-clock-o\"></i> <span :title=\"n.createdAt|formatTime\">{{n.createdAt|formatTimeAgo}}</span>\n                <span v-if=\"!n.read\">\n                  <span class=\"info-separator\"> | </span>\n                  <a class=\"a-tool\" role=\"button\" @click=\"markRead(n)\"><i class=\"fa fa-check\" aria-hidden=\"true\"></i> mark read</a>\n                </span>\n              </div>\n            </div>\n          </div>\n        </div>\n\n        <nav v-if=\"lastPage > 1\">\n          <ul class=\"pagination pagination-sm\">\n            <li v-for=\"p in pagination\" :class=\"{active: page === p}\">\n              <span v-if=\"p === '...'\">\u2026</span>\n              <a v-if=\"p !== '...'\" role=\"button\" @click=\"page = p\">{{p}}</a>\n            </li>\n          </ul>\n        </nav>\n\n      </div>\n\n    </div>\n  `,\n\n  props: [\"config\", \"auth\"],\n\n  data: function() {\n    return {\n      page: 1,\n      notifications: [],\n      notificationsReady: false,\n      notificationCount: 0,\n      users: {},\n      usersReady: false,\n      error: false,\n    };\n  },\n\n  computed: {\n    dataReady: function() {\n      return this.notificationsReady && this.usersReady;\n    },\n\n    lastPage: function() {\n      var p = Math.floor((this.notificationCount - 1) / NOTIFICATIONS_PER_PAGE) + 1;\n      if (p < 1) {\n        p = 1;\n      }\n      return p;\n    },\n\n    pagination: function() {\n      if (!this.notificationsReady) {\n        return [];\n      }\n      return getPagination(this.page, this.lastPage);\n    },\n  },\n\n  watch: {\n    page: function(val) {\n      this.load();\n    },\n  },\n\n  created: function() {\n    this.load();\n  },\n\n  methods: {\n    load: function() {\n      this.notifications = [];\n      this.notificationsReady = false;\n      this.users = {};\n      this.usersReady = false;\n      this.error = false;\n      this.getNotifications();\n    },\n\n    getNotifications: function() {\n      var url = \"api/v1/notifications?limit=\" + NOTIFICATIONS_PER_PAGE;\n      if (this.page > 1) {\n        url += \"&offset=\" + (this.page - 1) * NOTIFICATIONS_PER_PAGE;\n      }\n      this.$http.get(url).then(\n        response => {\n          this.notifications = response.body.notifications;\n          this.notificationCount = response.body.count;\n          this.notificationsReady = true;\n          this.getUsers();\n        },\n        response => {\n          this.error = true;\n          console.log(\"ERROR: getNotifications: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    getUsers: function() {\n      var ids = this.notifications.map(n => n.actorId).filter((v, i, a) => a.indexOf(v) === i);\n      if (ids.length === 0) {\n        this.users = {};\n        this.usersReady = true;\n        return;\n      }\n      this.$http.get(\"api/v1/users?ids=\" + ids.join(\",\")).then(\n        response => {\n          var users = {};\n          for (var i = 0; i < response.body.users.length; i++) {\n            users[response.body.users[i].id] = response.body.users[i];\n          }\n          this.users = users;\n          this.usersReady = true;\n        },\n        response => {\n          this.error = true;\n          console.log(\"ERROR: getUsers: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    // open marks the notification as read and goes to its topic.\n    open: function(n) {\n      this.markRead(n);\n      this.$parent.$router.push(\"/t/\" + n.topicId);\n    },\n\n    markRead: function(n) {\n      if (n.read) {\n        return;\n      }\n      this.$http.post(\"api/v1/notifications/\" + n.id + \"/read\").then(\n        response => {\n          n.read = true;\n          if (this.auth.unreadNotifications > 0) {\n            this.auth.unreadNotifications--;\n          }\n        },\n        response => {\n          console.log(\"ERROR: markRead: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    markAllRead: function() {\n      this.$http.post(\"api/v1/notifications/read\").then(\n        response => {\n          for (var i = 0; i < this.notifications.length; i++) {\n            this.notifications[i].read = true;\n          }\n          this.auth.unreadNotifications = 0;\n        },\n        response => {\n          console.log(\"ERROR: markAllRead: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n  },\n});\n")},
+	"/frontend/js/bebop-oauth.js":          &fileData{name: "bebop-oauth.js", mtime: 1792206501, size: 3841, body: []byte("const BEBOP_SESSION_STORAGE_OAUTH_VERIFIER_KEY = \"bebop_oauth_verifier\";\nconst BEBOP_SESSION_STORAGE_OAUTH_RETURN_KEY = \"bebop_oauth_return\";\n\n// bebopOAuthErrors maps the oauth error codes to messages.\nvar bebopOAuthErrors = {\n  UserBlocked: \"Sorry, your account is blocked.\",\n  IdentityTaken: \"Sorry, this account is already linked to another user.\",\n  Unauthorized: \"Please sign in again.\",\n  InvalidCode: \"Sign in has expired. Please try again.\",\n};\n\n// bebopBase64URL encodes the given bytes to base64url without padding.\nfunction bebopBase64URL(bytes) {\n  var s = \"\";\n  for (var i = 0; i < bytes.length; i++) {\n    s += String.fromCharCode(bytes[i]);\n  }\n  return btoa(s).replace(/\\+/g, \"-\").replace(/\\//g, \"_\").replace(/=+$/, \"\");\n}\n\n// bebopOAuthBegin redirects to the provider login page. The PKCE code verifier\n// stays in the session storage until the one-time code is exchanged for tokens.\n// When linking, the API sets a one-time link code cookie for the signed in user\n// and the provider identity is linked to that user.\nfunction bebopOAuthBegin(provider, link) {\n  sessionStorage.setItem(BEBOP_SESSION_STORAGE_OAUTH_RETURN_KEY, window.location.hash.replace(/^#/, \"\") || \"/\");\n\n  if (link) {\n    Vue.http.post(\"api/v1/auth/link\").then(\n      () => {\n        window.location.href = \"oauth/begin/\" + provider + \"?link=1\";\n      },\n      response => {\n        console.log(\"ERROR: link: \" + JSON.stringify(response.body));\n      }\n    );\n    return;\n  }\n\n  var verifier = bebopBase64URL(crypto.getRandomValues(new Uint8Array(32)));\n  crypto.subtle.digest(\"SHA-256\", new TextEncoder().encode(verifier)).then(digest => {\n    sessionStorage.setItem(BEBOP_SESSION_STORAGE_OAUTH_VERIFIER_KEY, verifier);\n    var challenge = bebopBase64URL(new Uint8Array(digest));\n    window.location.href = \"oauth/begin/\" + provider + \"?code_challenge=\" + challenge + \"&code_challenge_method=S256\";\n  });\n}\n\n// BebopOAuthEnd completes the oauth flow when the server redirects back to the app.\nvar BebopOAuthEnd = Vue.component(\"bebop-oauth-end\", {\n  template: `\n    <div class=\"container\">\n      <div class=\"row\">\n        <div class=\"col-sm-6 col-sm-offset-3\">\n          <div v-if=\"errorMessage === ''\">\n            <i class=\"fa fa-spinner fa-spin\"></i>\n          </div>\n          <div v-else>\n            <div class=\"alert alert-danger\" role=\"alert\">{{errorMessage}}</div>\n            <router-link :to=\"returnPath\">Back</router-link>\n          </div>\n        </div>\n      </div>\n    </div>\n  `,\n\n  props: [\"config\", \"auth\"],\n\n  data: function() {\n    return {\n      errorMessage: \"\",\n      returnPath: sessionStorage.getItem(BEBOP_SESSION_STORAGE_OAUTH_RETURN_KEY) || \"/\",\n    };\n  },\n\n  mounted: function() {\n    var query = this.$route.query;\n    var verifier = sessionStorage.getItem(BEBOP_SESSION_STORAGE_OAUTH_VERIFIER_KEY);\n    sessionStorage.removeItem(BEBOP_SESSION_STORAGE_OAUTH_VERIFIER_KEY);\n    sessionStorage.removeItem(BEBOP_SESSION_STORAGE_OAUTH_RETURN_KEY);\n\n    if (query.error) {\n      this.showError(query.error);\n      return;\n    }\n\n    if (query.linked) {\n      this.$router.replace(this.returnPath);\n      return;\n    }\n\n    if (!query.code || !verifier) {\n      this.showError(\"InvalidCode\");\n      return;\n    }\n\n    this.$http.post(\"api/v1/auth/exchange\", { code: query.code, codeVerifier: verifier }).then(\n      response => {\n        this.$root.oauthSuccess(response.body.accessToken, response.body.refreshToken);\n        this.$router.replace(this.returnPath);\n      },\n      response => {\n        console.log(\"ERROR: exchange: \" + JSON.stringify(response.body));\n        this.showError(response.body.error ? response.body.error.code : \"\");\n      }\n    );\n  },\n\n  methods: {\n    showError: function(code) {\n      this.errorMessage = bebopOAuthErrors[code] || \"Sorry, could not sign in. An error occured.\";\n    },\n  },\n});\n")},
+	"/frontend/js/bebop-topics.js":         &fileData{name: "bebop-topics.js", mtime: 1792202959, size: 7012, body: []byte("const TOPICS_PER_PAGE = 20;\n\nvar BebopTopics = Vue.component(\"bebop-topics\", {\n  template: `\n    <div class=\"container content-container\">\n\n      <div v-if=\"!dataReady\" class=\"loading-info\">\n        <div v-if=\"error\" >\n          <p class=\"text-danger\">\n            Sorry, could not load topics. Please check your connection.\n          </p>\n          <a class=\"btn btn-primary btn-sm\" role=\"button\" @click=\"load\">\n            <i class=\"fa fa-refresh\"></i> Try Again\n          </a>\n        </div>\n        <div v-else>\n          <i class=\"fa fa-circle-o-notch fa-spin fa-3x fa-fw\"></i>\n        </div>\n      </div>\n      <div v-else>\n\n        <div v-if=\"updated\" class=\"alert alert-info updated-alert\">\n          There are new topics or comments.\n          <a class=\"btn btn-primary btn-xs\" role=\"button\" @click=\"load\">\n            <i class=\"fa fa-refresh\"></i> Refresh\n          </a>\n        </div>\n\n        <div class=\"topics-topic-top-buttons\">\n          <router-link v-if=\"auth.authenticated\" to=\"/new-topic\" class=\"btn btn-primary btn-sm\">\n            <i class=\"fa fa-plus\"></i> New Topic\n          </router-link>\n          <a class=\"btn btn-primary btn-sm\" role=\"button\" @click=\"load\">\n            <i class=\"fa fa-refresh\"></i> Refresh\n          </a>\n        </div>\n\n        <nav v-if=\"page > 1\">\n          <ul class=\"pagination pagination-sm\">\n            <li v-for=\"p in pagination\" :class=\"{active: page === p}\">\n              <span v-if=\"p === '...'\">\u2026</span>\n              <router-link v-if=\"p !== '...'\" :to=\"'/p/' + p\">{{p}}</router-link>\n            </li>\n          </ul>\n        </nav>\n\n        <div v-for=\"topic in topics\" class=\"card topics-topic\">\n          <div class=\"avatar-block\">\n            <div class=\"avatar-block-l\">\n              <img v-if=\"users[topic.authorId].avatar\" class=\"img-circle\" :src=\"users[topic.authorId].avatar\" width=\"40\" height=\"40\"> \n              <img v-else class=\"img-circle\" src=\"data:image/gif;base64,R0lGODlhAQABAIAAAP///wAAACH5BAEAAAAALAAAAAABAAEAAAICRAEAOw==\" width=\"40\" height=\"40\"> \n            </div>\n            <div class=\"avatar-block-r\">\n              <div class=\"topics-topic-title\">\n                <router-link :to=\"'/t/' + topic.id\">{{topic.title}}</router-link>\n              </div>\n              <div class=\"topics-topic-info\">\n                <i class=\"fa fa-user-o\"></i> {{users[topic.authorId].name}}\n                <span class=\"info-separator\"> | </span>\n                <i class=\"fa fa-comment-o\"></i> {{topic.commentCount}}\n                <span class=\"info-separator\"> | </span>\n                <i class=\"fa fa-clock-o\"></i> <span :title=\"topic.lastCommentAt|formatTime\">{{topic.lastCommentAt|formatTimeAgo}}</span>\n              </div>\n              <div class=\"topics-topic-admin-tools\" v-if=\"$root.can('topic.delete')\">\n                <a class=\"a-tool\" role=\"button\" @click=\"delTopic(topic.id)\"><i class=\"fa fa-times\" aria-hidden=\"true\"></i> delete topic</a>\n                <span class=\"info-separator\"> | </span> \n                <router-link :to=\"'/u/' + users[topic.authorId].id\" class=\"a-tool\"><i class=\"fa fa-user\" aria-hidden=\"true\"></i> user profile</router-link>\n              </div>\n            </div>\n          </div>\n        </div>\n\n        <nav v-if=\"lastPage > 1\">\n          <ul class=\"pagination pagination-sm\">\n            <li v-for=\"p in pagination\" :class=\"{active: page === p}\">\n              <span v-if=\"p === '...'\">\u2026</span>\n              <router-link v-if=\"p !== '...'\" :to=\"'/p/' + p\">{{p}}</router-link>\n            </li>\n          </ul>\n        </nav>\n\n      </div>\n\n    </div>\n  `,\n\n  props: [\"config\", \"auth\"],\n\n  data: function() {\n    return {\n      topics: [],\n      topicsReady: false,\n      topicCount: 0,\n      users: {},\n      usersReady: false,\n      error: false,\n      updated: false,\n      eventSource: null,\n    };\n  },\n\n  computed: {\n    dataReady: function() {\n      return this.topicsReady && this.usersReady;\n    },\n\n    page: function() {\n      var page = parseInt(this.$route.params.page, 10);\n      if (!page || page < 1) {\n        return 1;\n      }\n      return page;\n    },\n\n    lastPage: function() {\n      if (!this.topicsReady) {\n        return 1;\n      }\n      var p = Math.floor((this.topicCount - 1) / TOPICS_PER_PAGE) + 1;\n      if (p < 1) {\n        p = 1;\n      }\n      return p;\n    },\n\n    pagination: function() {\n      if (!this.topicsReady) {\n        return [];\n      }\n      return getPagination(this.page, this.lastPage);\n    },\n  },\n\n  watch: {\n    page: function(val) {\n      this.load();\n    },\n  },\n\n  created: function() {\n    this.load();\n    this.eventSource = subscribeEvents(0, e => {\n      this.updated = this.dataReady;\n    });\n  },\n\n  destroyed: function() {\n    if (this.eventSource) {\n      this.eventSource.close();\n    }\n  },\n\n  methods: {\n    load: function() {\n      this.topics = [];\n      this.topicsReady = false;\n      this.topicCount = 0;\n      this.users = {};\n      this.usersReady = false;\n      this.error = false;\n      this.updated = false;\n      this.getTopics();\n    },\n\n    getTopics: function() {\n      var url = \"api/v1/topics?limit=\" + TOPICS_PER_PAGE;\n      if (this.page > 1) {\n        var offset = (this.page - 1) * TOPICS_PER_PAGE;\n        url += \"&offset=\" + offset;\n      }\n      this.$http.get(url).then(\n        response => {\n          this.topics = response.body.topics;\n          this.topicCount = response.body.count;\n          this.topicsReady = true;\n\n          if (this.page > this.lastPage) {\n            this.$parent.$router.replace(\"/p/\" + this.lastPage);\n            return;\n          }\n\n          this.getUsers();\n        },\n        response => {\n          this.error = true;\n          console.log(\"ERROR: getTopics: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    getUsers: function() {\n      var url = \"api/v1/users\";\n      var ids = [];\n      for (var i = 0; i < this.topics.length; i++) {\n        ids.push(this.topics[i].authorId);\n      }\n      ids = ids.filter((v, i, a) => a.indexOf(v) === i);\n      if (ids.length === 0) {\n        this.users = {};\n        this.usersReady = true;\n        return;\n      }\n      url += \"?ids=\" + ids.join(\",\");\n      this.$http.get(url).then(\n        response => {\n          var users = {};\n          for (var i = 0; i < response.body.users.length; i++) {\n            users[response.body.users[i].id] = response.body.users[i];\n          }\n          this.users = users;\n          this.usersReady = true;\n        },\n        response => {\n          this.error = true;\n          console.log(\"ERROR: getUsers: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    delTopic: function(id) {\n      if (!confirm(\"Are you sure you want to delete topic \" + id + \"?\")) {\n        return;\n      }\n      var url = \"api/v1/topics/\" + id;\n      this.$http.delete(url).then(\n        response => {\n          this.load();\n        },\n        response => {\n          console.log(\"ERROR: delTopic: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n  },\n});\n")},
+	"/frontend/js/bebop-unsubscribe.js":    &fileData{name: "bebop-unsubscribe.js", mtime: 1792203502, size: 1572, body: []byte("// BebopUnsubscribe handles the unsubscribe links of the email digests.\nvar BebopUnsubscribe = Vue.component(\"bebop-unsubscribe\", {\n  template: `\n    <div class=\"container\">\n      <div class=\"row\">\n        <div class=\"col-sm-6 col-sm-offset-3\">\n          <h2>Email digest</h2>\n          <div v-if=\"done\" class=\"alert alert-success\" role=\"alert\">\n            You will no longer receive the email digest. You can turn it back on in your profile.\n          </div>\n          <div v-else-if=\"errorMessage\" class=\"alert alert-danger\" role=\"alert\">\n            {{errorMessage}}\n          </div>\n          <div v-else>\n            <i class=\"fa fa-spinner fa-spin\"></i>\n          </div>\n        </div>\n      </div>\n    </div>\n  `,\n\n  props: [\"config\", \"auth\"],\n\n  data: function() {\n    return {\n      done: false,\n      errorMessage: \"\",\n    };\n  },\n\n  mounted: function() {\n    this.unsubscribe();\n  },\n\n  methods: {\n    unsubscribe: function() {\n      this.$http.post(\"api/v1/digest/unsubscribe\", { token: this.$route.params.token }).then(\n        response => {\n          this.done = true;\n        },\n        response => {\n          console.log(\"ERROR: unsubscribe: \" + JSON.stringify(response.body));\n          if (response.body.error && response.body.error.code === \"InvalidToken\") {\n            this.errorMessage = \"This link is invalid or has expired. You can turn the email digest off in your profile.\";\n          } else {\n            this.errorMessage = \"Sorry, could not turn the email digest off. Please try again later.\";\n          }\n        }\n      );\n    },\n  },\n});\n")},
+	"/frontend/js/bebop-user.js":           &fileData{name: "bebop-user.js", mtime: 1792203496, size: 12181, body: []byte("var BebopUser = Vue.component(\"bebop-user\", {\n  template: `\n    <div class=\"container content-container\">\n\n      <div v-if=\"!dataReady\" class=\"loading-info\">\n        <div v-if=\"error\" >\n          <p class=\"text-danger\">\n            Sorry, could not load the user profile. Please check your connection.\n          </p>\n          <a class=\"btn btn-primary btn-sm\" role=\"button\" @click=\"load\">\n            <i class=\"fa fa-refresh\"></i> Try Again\n          </a>\n        </div>\n        <div v-else>\n          <i class=\"fa fa-circle-o-notch fa-spin fa-3x fa-fw\"></i>\n        </div>\n      </div>\n      <div v-else>\n\n        <h2 v-if=\"isMe\">My profile</h2>\n        <h2 v-else>User profile: {{user.name}}</h2>\n\n        <div class=\"card user-profile\">\n\n          <div class=\"row\">\n            <div class=\"col-xs-3\">\n              Username\n            </div>\n            <div class=\"col-xs-6\">\n              {{user.name}}\n            </div>\n            <div class=\"col-xs-3 text-right\">\n              <label class=\"btn btn-fix\" :class=\"{'btn-default': isMe, 'btn-danger': !isMe}\" role=\"button\" @click=\"changeUsername()\"><i class=\"fa fa-pencil-square-o\" aria-hidden=\"true\"></i></label>\n            </div>\n          </div>\n\n          <hr>\n\n          <div class=\"row\">\n            <div class=\"col-xs-3\">\n              Avatar\n            </div>\n            <div class=\"col-xs-6\">\n              <div v-if=\"uploadingAvatar\">\n                <i class=\"fa fa-circle-o-notch fa-spin fa-2x fa-fw\"></i>\n              </div>\n              <div v-else>\n                <img v-if=\"user.avatar\" class=\"img-circle\" :src=\"user.avatar\" width=\"35\" height=\"35\"> \n                <img v-else class=\"img-circle\" src=\"data:image/gif;base64,R0lGODlhAQABAIAAAP///wAAACH5BAEAAAAALAAAAAABAAEAAAICRAEAOw==\" width=\"35\" height=\"35\"> \n              </div>\n            </div>\n            <div class=\"col-xs-3 text-right\">\n              <label for=\"avatar-upload-input\" class=\"btn btn-fix\" :class=\"{'btn-default': isMe, 'btn-danger': !isMe}\" role=\"button\">\n                <i class=\"fa fa-cloud-upload\"></i>\n              </label>\n              <input id=\"avatar-upload-input\" class=\"hidden\" type=\"file\" @change=\"uploadAvatar()\"/>\n            </div>\n          </div>\n          <div v-if=\"avatarUploadError\" class=\"row\">\n            <div class=\"col-xs-12\">\n              <div class=\"alert alert-danger\" style=\"margin-top:10px\">{{avatarUploadError}}</div>\n            </div>\n          </div>\n\n          <hr>\n\n          <div v-if=\"!isMe\" class=\"row\">\n            <div class=\"col-xs-3\">\n              Sign in with\n            </div>\n            <div class=\"col-xs-6\">\n              {{user.authService|capitalize}}\n            </div>\n          </div>\n\n          <div v-else class=\"row\">\n            <div class=\"col-xs-3\">\n              Sign in with\n            </div>\n            <div class=\"col-xs-6\">\n              <div v-for=\"identity in identities\" class=\"user-identity\">\n                {{identity.authService|capitalize}}\n                <span v-if=\"identity.displayName\" class=\"text-muted\">({{identity.displayName}})</span>\n                <a v-if=\"identities.length > 1\" href=\"#\" class=\"text-danger\" title=\"Unlink\" @click.prevent=\"unlinkIdentity(identity)\">\n                  <i class=\"fa fa-times\" aria-hidden=\"true\"></i>\n                </a>\n              </div>\n            </div>\n            <div class=\"col-xs-3 text-right\">\n              <div class=\"dropdown\" v-if=\"config.oauth.length\">\n                <button class=\"btn btn-default btn-fix dropdown-toggle\" data-toggle=\"dropdown\" title=\"Link another account\">\n                  <i class=\"fa fa-link\" aria-hidden=\"true\"></i>\n                </button>\n                <ul class=\"dropdown-menu dropdown-menu-right\">\n                  <li v-for=\"provider in config.oauth\">\n                    <a href=\"#\" @click.prevent=\"linkIdentity(provider)\">{{provider|capitalize}}</a>\n                  </li>\n                </ul>\n              </div>\n            </div>\n          </div>\n          <div v-if=\"identityError\" class=\"row\">\n            <div class=\"col-xs-12\">\n              <div class=\"alert alert-danger\" style=\"margin-top:10px\">{{identityError}}</div>\n            </div>\n          </div>\n\n          <hr>\n\n          <div v-if=\"isMe && config.localAuth\" class=\"row\">\n            <div class=\"col-xs-3\">\n              Email digest\n            </div>\n            <div class=\"col-xs-6\">\n              <select class=\"form-control input-sm user-digest-mode\" v-model=\"digestMode\" @change=\"setDigestMode\">\n                <option value=\"off\">Off</option>\n                <option value=\"immediate\">New comments as they come</option>\n                <option value=\"daily\">Once a day</option>\n              </select>\n              <div class=\"text-muted user-digest-hint\">\n                New comments in the topics you watch, sent to your sign-in email.\n              </div>\n            </div>\n          </div>\n          <div v-if=\"digestError\" class=\"row\">\n            <div class=\"col-xs-12\">\n              <div class=\"alert alert-danger\" style=\"margin-top:10px\">{{digestError}}</div>\n            </div>\n          </div>\n\n          <hr v-if=\"isMe && config.localAuth\">\n\n          <div class=\"row\">\n            <div class=\"col-xs-3\">\n              Activated\n            </div>\n            <div class=\"col-xs-6\">\n              {{user.createdAt|formatTime}}\n            </div>\n          </div>\n          \n          <hr v-if=\"$root.can('user.block') && !isMe\">\n\n          <div v-if=\"$root.can('user.block') && !isMe\" class=\"row\">\n            <div class=\"col-xs-3\">\n              Blocked\n            </div>\n            <div class=\"col-xs-6\">\n              <span v-if=\"user.blocked\" class=\"text-danger\">Yes</span>\n              <span v-else class=\"text-success\">No</span>\n            </div>\n            <div class=\"col-xs-3 text-right\">\n              <button v-if=\"user.blocked\" class=\"btn btn-danger btn-fix\" @click=\"setBlocked(false)\"><i class=\"fa fa-unlock-alt\" aria-hidden=\"true\"></i></button>\n              <button v-else class=\"btn btn-danger btn-fix\" @click=\"setBlocked(true)\"><i class=\"fa fa-lock\" aria-hidden=\"true\"></i></button>\n            </div>\n          </div>\n\n        </div>\n      </div>\n\n    </div>\n  `,\n\n  props: [\"config\", \"auth\"],\n\n  data: function() {\n    return {\n      user: {},\n      userReady: false,\n      error: false,\n      uploadingAvatar: false,\n      avatarUploadError: \"\",\n      identities: [],\n      identityError: \"\",\n      digestMode: \"off\",\n      digestError: \"\",\n    };\n  },\n\n  computed: {\n    dataReady: function() {\n      return this.userReady;\n    },\n\n    userId: function() {\n      if (!this.auth.authenticated) {\n        return 0;\n      }\n\n      var userId = parseInt(this.$route.params.user, 10);\n      if (!userId) {\n        return this.auth.user.id;\n      }\n\n      return userId;\n    },\n\n    isMe: function() {\n      if (!this.auth.authenticated) {\n        return false;\n      }\n      return this.userId === this.auth.user.id;\n    },\n  },\n\n  watch: {\n    userId: function(val) {\n      this.load();\n    },\n  },\n\n  created: function() {\n    this.load();\n  },\n\n  methods: {\n    load: function() {\n      this.user = {};\n      this.userReady = false;\n      this.error = false;\n      this.uploadingAvatar = false;\n      this.avatarUploadError = \"\";\n      this.identities = [];\n      this.identityError = \"\";\n      this.digestMode = \"off\";\n      this.digestError = \"\";\n      this.getUser();\n      if (this.isMe) {\n        this.getIdentities();\n        this.getDigestMode();\n      }\n    },\n\n    getUser: function() {\n      if (!this.auth.authenticated) {\n        this.$parent.$router.replace(\"/\");\n        return;\n      }\n\n      if (!this.$root.can(\"user.view\") && this.auth.user.id !== this.userId) {\n        this.$parent.$router.replace(\"/me\");\n        return;\n      }\n\n      var url = \"api/v1/me\";\n      if (this.auth.user.id !== this.userId) {\n        url = \"api/v1/users/\" + this.userId;\n      }\n\n      this.$http.get(url).then(\n        response => {\n          this.user = response.body.user;\n          this.userReady = true;\n        },\n        response => {\n          console.log(\"ERROR: getUser: \" + JSON.stringify(response.body));\n          this.error = true;\n        }\n      );\n    },\n\n    changeUsername: function() {\n      if (!this.userReady) {\n        return;\n      }\n      this.$parent.$refs.usernameModal.show(this.userId, this.user.name, success => {\n        if (success) {\n          if (this.isMe) {\n            this.$parent.getMe();\n          }\n          this.load();\n        }\n      });\n    },\n\n    uploadAvatar: function() {\n      var input = document.getElementById(\"avatar-upload-input\");\n      var file = input.files[0];\n      input.value = \"\";\n      var reader = new FileReader();\n      reader.onload = () => {\n        var parts = reader.result.split(\";base64,\");\n        var imageData = \"\";\n        if (parts.length === 2) {\n          imageData = parts[1];\n        }\n        this.putUserAvatar(imageData);\n      };\n      reader.readAsDataURL(file);\n    },\n\n    putUserAvatar: function(imageData) {\n      if (!this.userReady) {\n        return;\n      }\n      this.uploadingAvatar = true;\n      this.avatarUploadError = \"\";\n      this.$http.put(\"api/v1/users/\" + this.userId + \"/avatar\", { avatar: imageData }).then(\n        response => {\n          if (this.isMe) {\n            this.$parent.getMe();\n          }\n          this.uploadingAvatar = false;\n          this.load();\n        },\n        response => {\n          console.log(\"ERROR: putUserAvatar: \" + JSON.stringify(response.body));\n          this.uploadingAvatar = false;\n          var error = \"Sorry, could not upload that image. An error occured.\";\n          if (response.body.error && response.body.error.code === \"BadRequest\") {\n            error = \"Sorry, could not upload that image. \";\n            error += \"Please choose an image from 50x50 to 2000x2000 pixels in size. \";\n            error += \"The supported formats are JPEG, PNG, GIF, TIFF, BMP. \";\n            error += \"The maximum file size is 5MB.\";\n          }\n          this.avatarUploadError = error;\n        }\n      );\n    },\n\n    getIdentities: function() {\n      this.$http.get(\"api/v1/me/identities\").then(\n        response => {\n          this.identities = response.body.identities;\n        },\n        response => {\n          console.log(\"ERROR: getIdentities: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    linkIdentity: function(provider) {\n      this.$root.linkIdentity(provider);\n    },\n\n    unlinkIdentity: function(identity) {\n      if (!confirm(\"Are you sure you want to unlink \" + identity.authService + \"?\")) {\n        return;\n      }\n      this.identityError = \"\";\n      this.$http.delete(\"api/v1/me/identities/\" + identity.id).then(\n        response => {\n          this.getIdentities();\n        },\n        response => {\n          console.log(\"ERROR: unlinkIdentity: \" + JSON.stringify(response.body));\n          this.identityError = \"Sorry, could not unlink the account. An error occured.\";\n        }\n      );\n    },\n\n    getDigestMode: function() {\n      this.$http.get(\"api/v1/me/digest\").then(\n        response => {\n          this.digestMode = response.body.mode;\n        },\n        response => {\n          console.log(\"ERROR: getDigestMode: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n\n    setDigestMode: function() {\n      this.digestError = \"\";\n      this.$http.put(\"api/v1/me/digest\", { mode: this.digestMode }).then(\n        response => {},\n        response => {\n          console.log(\"ERROR: setDigestMode: \" + JSON.stringify(response.body));\n          this.digestError = \"Sorry, could not change the email digest. An error occured.\";\n          this.getDigestMode();\n        }\n      );\n    },\n\n    setBlocked(val) {\n      action = val ? \"block\" : \"unblock\";\n      if (!confirm(\"Are you sure you want to \" + action + \" this user?\")) {\n        return;\n      }\n      this.$http.put(\"api/v1/users/\" + this.userId + \"/blocked\", { blocked: val }).then(\n        response => {\n          this.load();\n        },\n        response => {\n          console.log(\"ERROR: setBlocked: \" + JSON.stringify(response.body));\n        }\n      );\n    },\n  },\n});\n")},
 	"/frontend/js/bebop-username-modal.js": &fileData{name: "bebop-username-modal.js", mtime: 1495846124, size: 3048, body: []byte("var BebopUsernameModal = Vue.component(\"bebop-username-modal\", {\n  template: `\n    <div class=\"modal fade\" id=\"username-modal\" tabindex=\"-1\" role=\"dialog\" data-backdrop=\"static\">\n      <div class=\"modal-dialog\" role=\"document\">\n        <div class=\"modal-content\">\n          <div class=\"modal-header\">\n            <h2 class=\"modal-title\">Username</h2>\n          </div>\n          <div class=\"modal-body\">\n            <div style=\"margin-bottom: 15px;\">\n              Please choose a username that is between 3 and 20 characters in length and containing only \n              alphanumeric characters (letters A-Z, numbers 0-9), hyphens, and underscores.\n            </div>\n            <div class=\"form-group\">\n              <label for=\"user-name\" class=\"form-control-label\">Username:</label>\n              <input type=\"text\" class=\"form-control\" id=\"username-modal-input\" v-model=\"name\" @change=\"hideErrorMessage\" @keyup=\"hideErrorMessage\" @keyup.13=\"send\">\n            </div>\n            <div id=\"username-modal-error\" class=\"alert alert-danger\" :class=\"{hidden: errorMessage===''}\" role=\"alert\" style=\"cursor:pointer\" @click=\"hideErrorMessage\">\n              {{errorMessage}}\n            </div>\n          </div>\n          <div class=\"modal-footer\">\n            <button type=\"button\" class=\"btn btn-default\" data-dismiss=\"modal\">Cancel</button>\n            <button type=\"button\" class=\"btn btn-primary\" id=\"username-modal-ok\" @click=\"send\">OK</button>\n          </div>\n        </div>\n      </div>\n    </div>\n  `,\n\n  data: function() {\n    return {\n      userId: 0,\n      success: false,\n      callback: function() {},\n      name: \"\",\n      errorMessage: \"\",\n    };\n  },\n\n  mounted: function() {\n    $(\"#username-modal\").on(\"hidden.bs.modal\", () => {\n      this.callback(this.success);\n    });\n    $(\"#username-modal\").on(\"shown.bs.modal\", () => {\n      $(\"#username-modal-input\")[0].focus();\n    });\n  },\n\n  methods: {\n    show: function(userId, initialName, callback) {\n      this.userId = userId;\n      this.success = false;\n      this.callback = callback;\n      this.errorMessage = \"\";\n      this.name = initialName;\n      $(\"#username-modal\").modal(\"show\");\n    },\n\n    send: function() {\n      this.$http.put(\"api/v1/users/\" + this.userId + \"/name\", { name: this.name }).then(\n        response => {\n          this.success = true;\n          $(\"#username-modal\").modal(\"hide\");\n        },\n        response => {\n          if (response.data.error && response.data.error.code === \"UnavailableUserName\") {\n            this.showErrorMessage(\"Sorry, that username is taken.\");\n          } else if (response.data.error && response.data.error.code === \"InvalidUserName\") {\n            this.showErrorMessage(\"Invalid username.\");\n          } else {\n            this.showErrorMessage(\"An error occured.\");\n          }\n          $(\"#username-modal-input\")[0].focus();\n        }\n      );\n    },\n\n    showErrorMessage: function(message) {\n      this.errorMessage = message;\n    },\n\n    hideErrorMessage: function() {\n      this.errorMessage = \"\";\n    },\n  },\n});\n")},
 }
@@ -1,5 +1,6 @@
 const BEBOP_LOCAL_STORAGE_TOKEN_KEY = "bebop_auth_token";
-const BEBOP_OAUTH_RESULT_COOKIE = "bebop_oauth_result";
+const BEBOP_LOCAL_STORAGE_REFRESH_TOKEN_KEY = "bebop_refresh_token";
+const BEBOP_TOKEN_REFRESH_MARGIN = 60; // seconds before the access token expires
 
 var BebopApp = new Vue({
   el: "#app",
@@ -8,6 +9,7 @@ var BebopApp = new Vue({
     <div>
       <bebop-nav :config="config" :auth="auth"></bebop-nav>
       <bebop-username-modal ref="usernameModal"></bebop-username-modal>
+      <bebop-local-auth-modal ref="localAuthModal" :config="config"></bebop-local-auth-modal>
       <router-view :config="config" :auth="auth"></router-view>
     </div>
   `,
@@ -23,6 +25,10 @@ var BebopApp = new Vue({
       { path: "/new-comment/:topic", component: BebopNewComment },
       { path: "/me", component: BebopUser },
       { path: "/u/:user", component: BebopUser },
+      { path: "/notifications", component: BebopNotifications },
+      { path: "/unsubscribe/:token", component: BebopUnsubscribe },
+      { path: "/auth/oauth", component: BebopOAuthEnd },
+      { path: "/auth/:action/:token", component: BebopLocalAuthLink },
     ],
     scrollBehavior: function(to, from, savedPosition) {
       if (savedPosition) {
@@ -38,11 +44,16 @@ var BebopApp = new Vue({
       config: {
         title: "",
         oauth: [],
+        localAuth: false,
+        magicLinks: false,
       },
       auth: {
         authenticated: false,
         user: {},
+        permissions: [],
+        unreadNotifications: 0,
       },
+      refreshTimer: null,
     };
   },
 
@@ -67,66 +78,106 @@ var BebopApp = new Vue({
     },
 
     signIn: function(provider) {
-      window.open("oauth/begin/" + provider, "", "width=800,height=600");
+      bebopOAuthBegin(provider);
+    },
+
+    // linkIdentity links a provider identity to the signed in user.
+    linkIdentity: function(provider) {
+      bebopOAuthBegin(provider, true);
     },
 
     signOut: function() {
+      var refreshToken = localStorage.getItem(BEBOP_LOCAL_STORAGE_REFRESH_TOKEN_KEY);
+      if (refreshToken) {
+        this.$http.post("api/v1/auth/logout", { refreshToken: refreshToken });
+      }
       localStorage.removeItem(BEBOP_LOCAL_STORAGE_TOKEN_KEY);
+      localStorage.removeItem(BEBOP_LOCAL_STORAGE_REFRESH_TOKEN_KEY);
+      clearTimeout(this.refreshTimer);
       Vue.http.headers.common["Authorization"] = "";
       this.auth = {
         authenticated: false,
         user: {},
+        permissions: [],
+        unreadNotifications: 0,
       };
     },
 
-    oauthEnd: function() {
-      var result = this.getCookieByName(BEBOP_OAUTH_RESULT_COOKIE);
-      var parts = result.split(":");
+    // can checks if the signed in user is granted the permission, e.g. "comment.delete".
+    can: function(permission) {
+      return this.auth.authenticated && this.auth.permissions.indexOf(permission) !== -1;
+    },
 
-      if (parts.length !== 2) {
-        this.oauthError("Unknown");
-        return;
-      }
+    oauthSuccess: function(token, refreshToken) {
+      localStorage.setItem(BEBOP_LOCAL_STORAGE_TOKEN_KEY, token);
+      localStorage.setItem(BEBOP_LOCAL_STORAGE_REFRESH_TOKEN_KEY, refreshToken);
+      this.checkAuth();
+    },
 
-      if (parts[0] === "error") {
-        this.oauthError(parts[1]);
+    checkAuth: function() {
+      var token = localStorage.getItem(BEBOP_LOCAL_STORAGE_TOKEN_KEY);
+      if (token && this.tokenTTL(token) <= BEBOP_TOKEN_REFRESH_MARGIN) {
+        this.refreshAuth(this.getMe);
         return;
       }
-
-      if (parts[0] !== "success") {
-        this.oauthError("Unknown");
-        return;
+      if (token) {
+        this.useToken(token);
       }
-
-      this.oauthSuccess(parts[1]);
+      this.getMe();
     },
 
-    getCookieByName: function(name) {
-      var value = "; " + document.cookie;
-      var parts = value.split("; " + name + "=");
-      if (parts.length === 2) return parts.pop().split(";").shift();
+    useToken: function(token) {
+      Vue.http.headers.common["Authorization"] = "Bearer " + token;
+      clearTimeout(this.refreshTimer);
+      var delay = this.tokenTTL(token) - BEBOP_TOKEN_REFRESH_MARGIN;
+      this.refreshTimer = setTimeout(this.refreshAuth, Math.max(delay, 1) * 1000);
     },
 
-    oauthSuccess: function(token) {
-      localStorage.setItem(BEBOP_LOCAL_STORAGE_TOKEN_KEY, token);
-      this.checkAuth();
-    },
-
-    oauthError: function(error) {
-      if (error === "UserBlocked") {
-        console.log("oauth error: USER IS BLOCKED");
-      } else {
-        console.log("oauth error: " + error);
+    // tokenTTL returns the number of seconds until the access token expires.
+    tokenTTL: function(token) {
+      try {
+        var payload = token.split(".")[1].replace(/-/g, "+").replace(/_/g, "/");
+        return JSON.parse(atob(payload)).exp - Date.now() / 1000;
+      } catch (e) {
+        return 0;
       }
-      this.signOut();
     },
 
-    checkAuth: function() {
+    refreshAuth: function(done) {
       var token = localStorage.getItem(BEBOP_LOCAL_STORAGE_TOKEN_KEY);
-      if (token) {
-        Vue.http.headers.common["Authorization"] = "Bearer " + token;
+      var refreshToken = localStorage.getItem(BEBOP_LOCAL_STORAGE_REFRESH_TOKEN_KEY);
+      if (!token || !refreshToken) {
+        this.signOut();
+        return;
       }
-      this.getMe();
+
+      // The tokens may have been refreshed in another browser tab.
+      if (this.tokenTTL(token) > BEBOP_TOKEN_REFRESH_MARGIN) {
+        this.useToken(token);
+        if (done) done();
+        return;
+      }
+
+      this.$http.post("api/v1/auth/refresh", { refreshToken: refreshToken }).then(
+        response => {
+          localStorage.setItem(BEBOP_LOCAL_STORAGE_TOKEN_KEY, response.body.accessToken);
+          localStorage.setItem(BEBOP_LOCAL_STORAGE_REFRESH_TOKEN_KEY, response.body.refreshToken);
+          this.useToken(response.body.accessToken);
+          if (done) done();
+        },
+        response => {
+          if (localStorage.getItem(BEBOP_LOCAL_STORAGE_REFRESH_TOKEN_KEY) !== refreshToken) {
+            this.refreshAuth(done);
+            return;
+          }
+          console.log("ERROR: refreshAuth: " + JSON.stringify(response.body));
+          if (response.status === 401 || response.status === 403) {
+            this.signOut();
+          } else {
+            this.refreshTimer = setTimeout(this.refreshAuth, BEBOP_TOKEN_REFRESH_MARGIN / 2 * 1000);
+          }
+        }
+      );
     },
 
     getMe: function() {
@@ -135,6 +186,8 @@ var BebopApp = new Vue({
           this.auth = {
             authenticated: response.body.authenticated ? true : false,
             user: response.body.authenticated ? response.body.user : {},
+            permissions: response.body.permissions || [],
+            unreadNotifications: response.body.unreadNotifications || 0,
           };
           if (this.auth.authenticated && this.auth.user.name === "") {
             this.setMyName();
@@ -150,16 +203,23 @@ var BebopApp = new Vue({
     },
 
     setMyName: function() {
-      this.$refs.usernameModal.show(this.auth.user.id, "", success => {
-        if (!success) {
-          this.signOut();
+      var show = name => {
+        this.$refs.usernameModal.show(this.auth.user.id, name, success => {
+          if (!success) {
+            this.signOut();
+          }
+          this.getMe();
+        });
+      };
+      this.$http.get("api/v1/me/name-suggestion").then(
+        response => {
+          show(response.body.name);
+        },
+        response => {
+          console.log("ERROR: setMyName: " + JSON.stringify(response.body));
+          show("");
         }
-        this.getMe();
-      });
+      );
     },
   },
 });
-
-function bebopOAuthEnd() {
-  BebopApp.oauthEnd();
-}
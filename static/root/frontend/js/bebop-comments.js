@@ -21,6 +21,20 @@ var BebopComments = Vue.component("bebop-comments", {
 
         <h2>{{topic.title}}</h2>
 
+        <div v-if="auth.authenticated && watchingReady" class="comments-watch">
+          <a class="btn btn-default btn-xs" role="button" @click="setWatching(!watching)" :title="watching ? 'Stop getting the new comments of this topic by email' : 'Get the new comments of this topic by email'">
+            <i :class="watching ? 'fa fa-eye-slash' : 'fa fa-eye'" aria-hidden="true"></i>
+            {{watching ? "Unwatch" : "Watch"}}
+          </a>
+        </div>
+
+        <div v-if="updated" class="alert alert-info updated-alert">
+          There are new changes in this topic.
+          <a class="btn btn-primary btn-xs" role="button" @click="load">
+            <i class="fa fa-refresh"></i> Refresh
+          </a>
+        </div>
+
         <nav v-if="lastPage > 1">
           <ul class="pagination pagination-sm">
             <li v-for="p in pagination" :class="{active: page === p}">
@@ -41,6 +55,7 @@ var BebopComments = Vue.component("bebop-comments", {
               <div class="comments-comment-author">{{users[comment.authorId].name}}</div>
               <div class="comments-comment-date">
                 commented <span :title="comment.createdAt|formatTime">{{comment.createdAt|formatTimeAgo}}</span>
+                <span v-if="comment.editCount > 0" :title="comment.updatedAt|formatTime">(edited)</span>
               </div>
             </div>
           </div>
@@ -48,7 +63,7 @@ var BebopComments = Vue.component("bebop-comments", {
           <div class="comments-comment-content" v-html="comment.content">
           </div>
 
-          <div v-if="auth.authenticated && auth.user.admin" class="comments-comment-admin-tools">
+          <div v-if="$root.can('comment.delete')" class="comments-comment-admin-tools">
             <a v-if="topic.commentCount > 1" class="a-tool" role="button" @click="delComment(comment.id)"><i class="fa fa-times" aria-hidden="true"></i> delete comment</a>
             <span v-if="topic.commentCount > 1" class="info-separator"> | </span>
             <router-link :to="'/u/' + users[comment.authorId].id" class="a-tool"><i class="fa fa-user" aria-hidden="true"></i> user profile</router-link>
@@ -89,6 +104,10 @@ var BebopComments = Vue.component("bebop-comments", {
       users: {},
       usersReady: false,
       error: false,
+      updated: false,
+      eventSource: null,
+      watching: false,
+      watchingReady: false,
     };
   },
 
@@ -138,6 +157,11 @@ var BebopComments = Vue.component("bebop-comments", {
     },
     topicId: function(val) {
       this.load();
+      this.subscribe();
+      this.getWatching();
+    },
+    "auth.authenticated": function(val) {
+      this.getWatching();
     },
     dataReady: function(val) {
       if (val && this.$route.params.comment) {
@@ -155,6 +179,14 @@ var BebopComments = Vue.component("bebop-comments", {
 
   created: function() {
     this.load();
+    this.subscribe();
+    this.getWatching();
+  },
+
+  destroyed: function() {
+    if (this.eventSource) {
+      this.eventSource.close();
+    }
   },
 
   methods: {
@@ -168,6 +200,7 @@ var BebopComments = Vue.component("bebop-comments", {
       this.usersReady = false;
       this.waitNewComment = false;
       this.error = false;
+      this.updated = false;
       this.getTopic();
       this.getComments();
     },
@@ -247,6 +280,45 @@ var BebopComments = Vue.component("bebop-comments", {
       );
     },
 
+    // subscribe shows a notice when the topic is changed by someone else.
+    subscribe: function() {
+      if (this.eventSource) {
+        this.eventSource.close();
+      }
+      this.eventSource = subscribeEvents(this.topicId, e => {
+        this.updated = this.dataReady;
+      });
+    },
+
+    getWatching: function() {
+      this.watchingReady = false;
+      if (!this.auth.authenticated) {
+        return;
+      }
+      var url = "api/v1/topics/" + this.topicId + "/watching";
+      this.$http.get(url).then(
+        response => {
+          this.watching = response.body.watching;
+          this.watchingReady = true;
+        },
+        response => {
+          console.log("ERROR: getWatching: " + JSON.stringify(response.body));
+        }
+      );
+    },
+
+    setWatching: function(watching) {
+      var url = "api/v1/topics/" + this.topicId + "/watching";
+      this.$http.put(url, { watching: watching }).then(
+        response => {
+          this.watching = watching;
+        },
+        response => {
+          console.log("ERROR: setWatching: " + JSON.stringify(response.body));
+        }
+      );
+    },
+
     delComment: function(id) {
       if (!confirm("Are you sure you want to delete comment " + id + "?")) {
         return;
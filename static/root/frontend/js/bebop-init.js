@@ -48,3 +48,26 @@ function getPagination(curPage, lastPage) {
 
   return pagination;
 }
+
+// BEBOP_EVENT_TYPES are the content event types streamed by the API.
+const BEBOP_EVENT_TYPES = ["topic.created", "topic.deleted", "comment.created", "comment.deleted", "reset"];
+
+// subscribeEvents opens the stream of the content events, limited to one topic
+// if topicId is not zero, and calls onEvent for every event. The browser reconnects
+// and resumes the stream automatically. It returns null if streaming is not supported.
+function subscribeEvents(topicId, onEvent) {
+  if (typeof EventSource === "undefined") {
+    return null;
+  }
+  var url = "api/v1/events";
+  if (topicId) {
+    url += "?topic=" + topicId;
+  }
+  var source = new EventSource(url);
+  for (var i = 0; i < BEBOP_EVENT_TYPES.length; i++) {
+    source.addEventListener(BEBOP_EVENT_TYPES[i], e => {
+      onEvent(JSON.parse(e.data));
+    });
+  }
+  return source;
+}
@@ -0,0 +1,218 @@
+// bebopLocalAuthErrors maps the local auth API error codes to messages.
+var bebopLocalAuthErrors = {
+  BadRequest: "Please enter a valid email and a password of 8 to 72 characters.",
+  InvalidCredentials: "Invalid email or password.",
+  EmailNotVerified: "Please confirm your email first. We can send you a new confirmation link.",
+  InvalidToken: "This link is invalid or has expired.",
+  UserBlocked: "This user is blocked.",
+};
+
+function bebopLocalAuthError(response) {
+  var code = response.data && response.data.error ? response.data.error.code : "";
+  return bebopLocalAuthErrors[code] || "An error occured.";
+}
+
+var BebopLocalAuthModal = Vue.component("bebop-local-auth-modal", {
+  template: `
+    <div class="modal fade" id="local-auth-modal" tabindex="-1" role="dialog">
+      <div class="modal-dialog" role="document">
+        <div class="modal-content">
+          <div class="modal-header">
+            <button type="button" class="close" data-dismiss="modal"><span>&times;</span></button>
+            <h2 class="modal-title">{{titles[mode]}}</h2>
+          </div>
+          <div class="modal-body">
+            <div v-if="message" class="alert alert-success" role="alert">
+              {{message}}
+            </div>
+            <template v-else>
+              <div class="form-group">
+                <label for="local-auth-email" class="form-control-label">Email:</label>
+                <input type="email" class="form-control" id="local-auth-email" v-model="email" @keyup="hideErrorMessage" @keyup.13="send">
+              </div>
+              <div class="form-group" v-if="mode === 'signIn' || mode === 'register'">
+                <label for="local-auth-password" class="form-control-label">Password:</label>
+                <input type="password" class="form-control" id="local-auth-password" v-model="password" @keyup="hideErrorMessage" @keyup.13="send">
+              </div>
+            </template>
+            <div class="alert alert-danger" :class="{hidden: errorMessage===''}" role="alert" style="cursor:pointer" @click="hideErrorMessage">
+              {{errorMessage}}
+              <a v-if="unverified" href="#" @click.prevent="resendVerification">Resend the link.</a>
+            </div>
+            <div v-if="!message">
+              <a v-if="mode !== 'signIn'" href="#" @click.prevent="setMode('signIn')">Sign in</a>
+              <a v-if="mode !== 'register'" href="#" @click.prevent="setMode('register')">Create an account</a>
+              <a v-if="mode !== 'reset'" href="#" @click.prevent="setMode('reset')">Forgot password?</a>
+              <a v-if="mode !== 'magic' && config.magicLinks" href="#" @click.prevent="setMode('magic')">Email me a sign in link</a>
+            </div>
+          </div>
+          <div class="modal-footer">
+            <button type="button" class="btn btn-default" data-dismiss="modal">{{message ? "Close" : "Cancel"}}</button>
+            <button v-if="!message" type="button" class="btn btn-primary" @click="send" :disabled="sending">{{titles[mode]}}</button>
+          </div>
+        </div>
+      </div>
+    </div>
+  `,
+
+  props: ["config"],
+
+  data: function() {
+    return {
+      mode: "signIn",
+      email: "",
+      password: "",
+      message: "",
+      errorMessage: "",
+      unverified: false,
+      sending: false,
+      titles: {
+        signIn: "Sign in",
+        register: "Create an account",
+        reset: "Reset password",
+        magic: "Send a sign in link",
+      },
+    };
+  },
+
+  mounted: function() {
+    $("#local-auth-modal").on("shown.bs.modal", () => {
+      $("#local-auth-email")[0].focus();
+    });
+  },
+
+  methods: {
+    show: function() {
+      this.setMode("signIn");
+      this.password = "";
+      $("#local-auth-modal").modal("show");
+    },
+
+    setMode: function(mode) {
+      this.mode = mode;
+      this.message = "";
+      this.hideErrorMessage();
+    },
+
+    send: function() {
+      var requests = {
+        signIn: ["api/v1/auth/login", { email: this.email, password: this.password }],
+        register: ["api/v1/auth/register", { email: this.email, password: this.password }],
+        reset: ["api/v1/auth/password-reset", { email: this.email }],
+        magic: ["api/v1/auth/magic-link", { email: this.email }],
+      };
+      var messages = {
+        register: "Almost done! Open the link we have sent to " + this.email + " to continue.",
+        reset: "If an account with this email exists, we have sent it a link to set a new password.",
+        magic: "If an account with this email exists, we have sent it a sign in link.",
+      };
+
+      this.sending = true;
+      this.$http.post(requests[this.mode][0], requests[this.mode][1]).then(
+        response => {
+          this.sending = false;
+          if (this.mode === "signIn") {
+            $("#local-auth-modal").modal("hide");
+            this.$parent.oauthSuccess(response.body.accessToken, response.body.refreshToken);
+            return;
+          }
+          this.message = messages[this.mode];
+        },
+        response => {
+          this.sending = false;
+          this.unverified = response.data.error && response.data.error.code === "EmailNotVerified";
+          this.errorMessage = bebopLocalAuthError(response);
+        }
+      );
+    },
+
+    resendVerification: function() {
+      this.$http.post("api/v1/auth/verify/resend", { email: this.email }).then(
+        response => {
+          this.message = "We have sent a new confirmation link to " + this.email + ".";
+          this.hideErrorMessage();
+        },
+        response => {
+          this.errorMessage = bebopLocalAuthError(response);
+        }
+      );
+    },
+
+    hideErrorMessage: function() {
+      this.errorMessage = "";
+      this.unverified = false;
+    },
+  },
+});
+
+// BebopLocalAuthLink handles the links sent by email.
+var BebopLocalAuthLink = Vue.component("bebop-local-auth-link", {
+  template: `
+    <div class="container">
+      <div class="row">
+        <div class="col-sm-6 col-sm-offset-3">
+          <h2>{{$route.params.action === "reset" ? "Set a new password" : "Signing in"}}</h2>
+          <div v-if="$route.params.action === 'reset' && !failed">
+            <div class="form-group">
+              <label for="local-auth-new-password" class="form-control-label">New password:</label>
+              <input type="password" class="form-control" id="local-auth-new-password" v-model="password" @keyup.13="send">
+            </div>
+            <button type="button" class="btn btn-primary" @click="send" :disabled="sending">Set password</button>
+          </div>
+          <div v-else-if="!failed">
+            <i class="fa fa-spinner fa-spin"></i>
+          </div>
+          <div class="alert alert-danger" :class="{hidden: errorMessage===''}" role="alert">
+            {{errorMessage}}
+          </div>
+        </div>
+      </div>
+    </div>
+  `,
+
+  props: ["config", "auth"],
+
+  data: function() {
+    return {
+      password: "",
+      errorMessage: "",
+      failed: false,
+      sending: false,
+    };
+  },
+
+  mounted: function() {
+    if (this.$route.params.action !== "reset") {
+      this.send();
+    }
+  },
+
+  methods: {
+    send: function() {
+      var urls = {
+        verify: "api/v1/auth/verify",
+        reset: "api/v1/auth/password-reset/confirm",
+        magic: "api/v1/auth/magic-link/confirm",
+      };
+      var url = urls[this.$route.params.action];
+      if (!url) {
+        this.failed = true;
+        this.errorMessage = bebopLocalAuthErrors.InvalidToken;
+        return;
+      }
+
+      this.sending = true;
+      this.$http.post(url, { token: this.$route.params.token, password: this.password }).then(
+        response => {
+          this.$root.oauthSuccess(response.body.accessToken, response.body.refreshToken);
+          this.$router.replace("/");
+        },
+        response => {
+          this.sending = false;
+          this.failed = response.status !== 400;
+          this.errorMessage = bebopLocalAuthError(response);
+        }
+      );
+    },
+  },
+});
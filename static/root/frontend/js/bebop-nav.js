@@ -12,7 +12,13 @@ Vue.component("bebop-nav", {
         </div>
         <div class="navbar-header pull-right">
           <ul class="nav pull-left">
-            <li v-if="auth.authenticated">
+            <li v-if="auth.authenticated" class="pull-left">
+              <router-link to="/notifications" class="navbar-link navbar-notifications" title="Notifications">
+                <i class="fa fa-bell-o"></i>
+                <span v-if="auth.unreadNotifications > 0" class="badge">{{auth.unreadNotifications}}</span>
+              </router-link>
+            </li>
+            <li v-if="auth.authenticated" class="pull-left">
               <a class="navbar-link dropdown-toggle navbar-user" role="button" data-toggle="dropdown" :title="auth.user.name">
                 <img v-if="auth.user.avatar" class="img-circle" :src="auth.user.avatar" width="35" height="35"> 
                 <img v-else class="img-circle" src="data:image/gif;base64,R0lGODlhAQABAIAAAP///wAAACH5BAEAAAAALAAAAAABAAEAAAICRAEAOw==" width="35" height="35"> 
@@ -25,6 +31,12 @@ Vue.component("bebop-nav", {
                     {{auth.user.name}}
                   </router-link>
                 </li>
+                <li>
+                  <router-link to="/notifications">
+                    <i class="fa fa-bell icon-s"></i>
+                    Notifications
+                  </router-link>
+                </li>
                 <li role="separator" class="divider"></li>
                 <li>
                   <a href="#" @click.prevent="$parent.signOut()">
@@ -43,10 +55,16 @@ Vue.component("bebop-nav", {
               <ul class="dropdown-menu pull-right">
                 <li v-for="provider in config.oauth">
                   <a href="#" @click.prevent="$parent.signIn(provider)">
-                    <i :class="'icon-s fa fa-' + provider" aria-hidden="true"></i>
+                    <i :class="'icon-s fa fa-' + providerIcon(provider)" aria-hidden="true"></i>
                     with {{provider|capitalize}}
                   </a>
                 </li>
+                <li v-if="config.localAuth">
+                  <a href="#" @click.prevent="$parent.$refs.localAuthModal.show()">
+                    <i class="icon-s fa fa-envelope" aria-hidden="true"></i>
+                    with Email
+                  </a>
+                </li>
               </ul>
             </li>
           </ul>
@@ -60,4 +78,19 @@ Vue.component("bebop-nav", {
   data: function() {
     return {};
   },
+
+  methods: {
+    // providerIcon returns the Font Awesome icon name of an oauth provider.
+    providerIcon: function(provider) {
+      var icons = {
+        google: "google",
+        facebook: "facebook",
+        github: "github",
+        gitlab: "gitlab",
+        microsoft: "windows",
+        twitch: "twitch",
+      };
+      return icons[provider] || "sign-in";
+    },
+  },
 });
@@ -0,0 +1,211 @@
+const NOTIFICATIONS_PER_PAGE = 20;
+
+var BebopNotifications = Vue.component("bebop-notifications", {
+  template: `
+    <div class="container content-container">
+
+      <div v-if="!dataReady" class="loading-info">
+        <div v-if="error" >
+          <p class="text-danger">
+            Sorry, could not load notifications. Please check your connection.
+          </p>
+          <a class="btn btn-primary btn-sm" role="button" @click="load">
+            <i class="fa fa-refresh"></i> Try Again
+          </a>
+        </div>
+        <div v-else>
+          <i class="fa fa-circle-o-notch fa-spin fa-3x fa-fw"></i>
+        </div>
+      </div>
+      <div v-else>
+
+        <div class="topics-topic-top-buttons">
+          <a class="btn btn-primary btn-sm" role="button" @click="markAllRead">
+            <i class="fa fa-check"></i> Mark All Read
+          </a>
+          <a class="btn btn-primary btn-sm" role="button" @click="load">
+            <i class="fa fa-refresh"></i> Refresh
+          </a>
+        </div>
+
+        <div v-if="notifications.length === 0" class="card notifications-empty">
+          No notifications yet.
+        </div>
+
+        <div v-for="n in notifications" :class="{card: true, 'notifications-notification': true, 'notifications-unread': !n.read}">
+          <div class="avatar-block">
+            <div class="avatar-block-l">
+              <img v-if="users[n.actorId].avatar" class="img-circle" :src="users[n.actorId].avatar" width="40" height="40"> 
+              <img v-else class="img-circle" src="data:image/gif;base64,R0lGODlhAQABAIAAAP///wAAACH5BAEAAAAALAAAAAABAAEAAAICRAEAOw==" width="40" height="40"> 
+            </div>
+            <div class="avatar-block-r">
+              <div class="topics-topic-title">
+                <a href="#" @click.prevent="open(n)">
+                  {{users[n.actorId].name}}
+                  <span v-if="n.type === 'mention'">mentioned you in</span>
+                  <span v-else>replied to</span>
+                  {{n.topicTitle}}
+                </a>
+              </div>
+              <div class="topics-topic-info">
+                <i :class="n.type === 'mention' ? 'fa fa-at' : 'fa fa-reply'"></i> {{n.type}}
+                <span class="info-separator"> | </span>
+                <i class="fa fa-clock-o"></i> <span :title="n.createdAt|formatTime">{{n.createdAt|formatTimeAgo}}</span>
+                <span v-if="!n.read">
+                  <span class="info-separator"> | </span>
+                  <a class="a-tool" role="button" @click="markRead(n)"><i class="fa fa-check" aria-hidden="true"></i> mark read</a>
+                </span>
+              </div>
+            </div>
+          </div>
+        </div>
+
+        <nav v-if="lastPage > 1">
+          <ul class="pagination pagination-sm">
+            <li v-for="p in pagination" :class="{active: page === p}">
+              <span v-if="p === '...'">…</span>
+              <a v-if="p !== '...'" role="button" @click="page = p">{{p}}</a>
+            </li>
+          </ul>
+        </nav>
+
+      </div>
+
+    </div>
+  `,
+
+  props: ["config", "auth"],
+
+  data: function() {
+    return {
+      page: 1,
+      notifications: [],
+      notificationsReady: false,
+      notificationCount: 0,
+      users: {},
+      usersReady: false,
+      error: false,
+    };
+  },
+
+  computed: {
+    dataReady: function() {
+      return this.notificationsReady && this.usersReady;
+    },
+
+    lastPage: function() {
+      var p = Math.floor((this.notificationCount - 1) / NOTIFICATIONS_PER_PAGE) + 1;
+      if (p < 1) {
+        p = 1;
+      }
+      return p;
+    },
+
+    pagination: function() {
+      if (!this.notificationsReady) {
+        return [];
+      }
+      return getPagination(this.page, this.lastPage);
+    },
+  },
+
+  watch: {
+    page: function(val) {
+      this.load();
+    },
+  },
+
+  created: function() {
+    this.load();
+  },
+
+  methods: {
+    load: function() {
+      this.notifications = [];
+      this.notificationsReady = false;
+      this.users = {};
+      this.usersReady = false;
+      this.error = false;
+      this.getNotifications();
+    },
+
+    getNotifications: function() {
+      var url = "api/v1/notifications?limit=" + NOTIFICATIONS_PER_PAGE;
+      if (this.page > 1) {
+        url += "&offset=" + (this.page - 1) * NOTIFICATIONS_PER_PAGE;
+      }
+      this.$http.get(url).then(
+        response => {
+          this.notifications = response.body.notifications;
+          this.notificationCount = response.body.count;
+          this.notificationsReady = true;
+          this.getUsers();
+        },
+        response => {
+          this.error = true;
+          console.log("ERROR: getNotifications: " + JSON.stringify(response.body));
+        }
+      );
+    },
+
+    getUsers: function() {
+      var ids = this.notifications.map(n => n.actorId).filter((v, i, a) => a.indexOf(v) === i);
+      if (ids.length === 0) {
+        this.users = {};
+        this.usersReady = true;
+        return;
+      }
+      this.$http.get("api/v1/users?ids=" + ids.join(",")).then(
+        response => {
+          var users = {};
+          for (var i = 0; i < response.body.users.length; i++) {
+            users[response.body.users[i].id] = response.body.users[i];
+          }
+          this.users = users;
+          this.usersReady = true;
+        },
+        response => {
+          this.error = true;
+          console.log("ERROR: getUsers: " + JSON.stringify(response.body));
+        }
+      );
+    },
+
+    // open marks the notification as read and goes to its topic.
+    open: function(n) {
+      this.markRead(n);
+      this.$parent.$router.push("/t/" + n.topicId);
+    },
+
+    markRead: function(n) {
+      if (n.read) {
+        return;
+      }
+      this.$http.post("api/v1/notifications/" + n.id + "/read").then(
+        response => {
+          n.read = true;
+          if (this.auth.unreadNotifications > 0) {
+            this.auth.unreadNotifications--;
+          }
+        },
+        response => {
+          console.log("ERROR: markRead: " + JSON.stringify(response.body));
+        }
+      );
+    },
+
+    markAllRead: function() {
+      this.$http.post("api/v1/notifications/read").then(
+        response => {
+          for (var i = 0; i < this.notifications.length; i++) {
+            this.notifications[i].read = true;
+          }
+          this.auth.unreadNotifications = 0;
+        },
+        response => {
+          console.log("ERROR: markAllRead: " + JSON.stringify(response.body));
+        }
+      );
+    },
+  },
+});
@@ -0,0 +1,113 @@
+const BEBOP_SESSION_STORAGE_OAUTH_VERIFIER_KEY = "bebop_oauth_verifier";
+const BEBOP_SESSION_STORAGE_OAUTH_RETURN_KEY = "bebop_oauth_return";
+
+// bebopOAuthErrors maps the oauth error codes to messages.
+var bebopOAuthErrors = {
+  UserBlocked: "Sorry, your account is blocked.",
+  IdentityTaken: "Sorry, this account is already linked to another user.",
+  Unauthorized: "Please sign in again.",
+  InvalidCode: "Sign in has expired. Please try again.",
+};
+
+// bebopBase64URL encodes the given bytes to base64url without padding.
+function bebopBase64URL(bytes) {
+  var s = "";
+  for (var i = 0; i < bytes.length; i++) {
+    s += String.fromCharCode(bytes[i]);
+  }
+  return btoa(s).replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "");
+}
+
+// bebopOAuthBegin redirects to the provider login page. The PKCE code verifier
+// stays in the session storage until the one-time code is exchanged for tokens.
+// When linking, the API sets a one-time link code cookie for the signed in user
+// and the provider identity is linked to that user.
+function bebopOAuthBegin(provider, link) {
+  sessionStorage.setItem(BEBOP_SESSION_STORAGE_OAUTH_RETURN_KEY, window.location.hash.replace(/^#/, "") || "/");
+
+  if (link) {
+    Vue.http.post("api/v1/auth/link").then(
+      () => {
+        window.location.href = "oauth/begin/" + provider + "?link=1";
+      },
+      response => {
+        console.log("ERROR: link: " + JSON.stringify(response.body));
+      }
+    );
+    return;
+  }
+
+  var verifier = bebopBase64URL(crypto.getRandomValues(new Uint8Array(32)));
+  crypto.subtle.digest("SHA-256", new TextEncoder().encode(verifier)).then(digest => {
+    sessionStorage.setItem(BEBOP_SESSION_STORAGE_OAUTH_VERIFIER_KEY, verifier);
+    var challenge = bebopBase64URL(new Uint8Array(digest));
+    window.location.href = "oauth/begin/" + provider + "?code_challenge=" + challenge + "&code_challenge_method=S256";
+  });
+}
+
+// BebopOAuthEnd completes the oauth flow when the server redirects back to the app.
+var BebopOAuthEnd = Vue.component("bebop-oauth-end", {
+  template: `
+    <div class="container">
+      <div class="row">
+        <div class="col-sm-6 col-sm-offset-3">
+          <div v-if="errorMessage === ''">
+            <i class="fa fa-spinner fa-spin"></i>
+          </div>
+          <div v-else>
+            <div class="alert alert-danger" role="alert">{{errorMessage}}</div>
+            <router-link :to="returnPath">Back</router-link>
+          </div>
+        </div>
+      </div>
+    </div>
+  `,
+
+  props: ["config", "auth"],
+
+  data: function() {
+    return {
+      errorMessage: "",
+      returnPath: sessionStorage.getItem(BEBOP_SESSION_STORAGE_OAUTH_RETURN_KEY) || "/",
+    };
+  },
+
+  mounted: function() {
+    var query = this.$route.query;
+    var verifier = sessionStorage.getItem(BEBOP_SESSION_STORAGE_OAUTH_VERIFIER_KEY);
+    sessionStorage.removeItem(BEBOP_SESSION_STORAGE_OAUTH_VERIFIER_KEY);
+    sessionStorage.removeItem(BEBOP_SESSION_STORAGE_OAUTH_RETURN_KEY);
+
+    if (query.error) {
+      this.showError(query.error);
+      return;
+    }
+
+    if (query.linked) {
+      this.$router.replace(this.returnPath);
+      return;
+    }
+
+    if (!query.code || !verifier) {
+      this.showError("InvalidCode");
+      return;
+    }
+
+    this.$http.post("api/v1/auth/exchange", { code: query.code, codeVerifier: verifier }).then(
+      response => {
+        this.$root.oauthSuccess(response.body.accessToken, response.body.refreshToken);
+        this.$router.replace(this.returnPath);
+      },
+      response => {
+        console.log("ERROR: exchange: " + JSON.stringify(response.body));
+        this.showError(response.body.error ? response.body.error.code : "");
+      }
+    );
+  },
+
+  methods: {
+    showError: function(code) {
+      this.errorMessage = bebopOAuthErrors[code] || "Sorry, could not sign in. An error occured.";
+    },
+  },
+});
@@ -19,6 +19,13 @@ var BebopTopics = Vue.component("bebop-topics", {
       </div>
       <div v-else>
 
+        <div v-if="updated" class="alert alert-info updated-alert">
+          There are new topics or comments.
+          <a class="btn btn-primary btn-xs" role="button" @click="load">
+            <i class="fa fa-refresh"></i> Refresh
+          </a>
+        </div>
+
         <div class="topics-topic-top-buttons">
           <router-link v-if="auth.authenticated" to="/new-topic" class="btn btn-primary btn-sm">
             <i class="fa fa-plus"></i> New Topic
@@ -54,7 +61,7 @@ var BebopTopics = Vue.component("bebop-topics", {
                 <span class="info-separator"> | </span>
                 <i class="fa fa-clock-o"></i> <span :title="topic.lastCommentAt|formatTime">{{topic.lastCommentAt|formatTimeAgo}}</span>
               </div>
-              <div class="topics-topic-admin-tools" v-if="auth.authenticated && auth.user.admin">
+              <div class="topics-topic-admin-tools" v-if="$root.can('topic.delete')">
                 <a class="a-tool" role="button" @click="delTopic(topic.id)"><i class="fa fa-times" aria-hidden="true"></i> delete topic</a>
                 <span class="info-separator"> | </span> 
                 <router-link :to="'/u/' + users[topic.authorId].id" class="a-tool"><i class="fa fa-user" aria-hidden="true"></i> user profile</router-link>
@@ -87,6 +94,8 @@ var BebopTopics = Vue.component("bebop-topics", {
       users: {},
       usersReady: false,
       error: false,
+      updated: false,
+      eventSource: null,
     };
   },
 
@@ -130,6 +139,15 @@ var BebopTopics = Vue.component("bebop-topics", {
 
   created: function() {
     this.load();
+    this.eventSource = subscribeEvents(0, e => {
+      this.updated = this.dataReady;
+    });
+  },
+
+  destroyed: function() {
+    if (this.eventSource) {
+      this.eventSource.close();
+    }
   },
 
   methods: {
@@ -140,6 +158,7 @@ var BebopTopics = Vue.component("bebop-topics", {
       this.users = {};
       this.usersReady = false;
       this.error = false;
+      this.updated = false;
       this.getTopics();
     },
 
@@ -0,0 +1,52 @@
+// BebopUnsubscribe handles the unsubscribe links of the email digests.
+var BebopUnsubscribe = Vue.component("bebop-unsubscribe", {
+  template: `
+    <div class="container">
+      <div class="row">
+        <div class="col-sm-6 col-sm-offset-3">
+          <h2>Email digest</h2>
+          <div v-if="done" class="alert alert-success" role="alert">
+            You will no longer receive the email digest. You can turn it back on in your profile.
+          </div>
+          <div v-else-if="errorMessage" class="alert alert-danger" role="alert">
+            {{errorMessage}}
+          </div>
+          <div v-else>
+            <i class="fa fa-spinner fa-spin"></i>
+          </div>
+        </div>
+      </div>
+    </div>
+  `,
+
+  props: ["config", "auth"],
+
+  data: function() {
+    return {
+      done: false,
+      errorMessage: "",
+    };
+  },
+
+  mounted: function() {
+    this.unsubscribe();
+  },
+
+  methods: {
+    unsubscribe: function() {
+      this.$http.post("api/v1/digest/unsubscribe", { token: this.$route.params.token }).then(
+        response => {
+          this.done = true;
+        },
+        response => {
+          console.log("ERROR: unsubscribe: " + JSON.stringify(response.body));
+          if (response.body.error && response.body.error.code === "InvalidToken") {
+            this.errorMessage = "This link is invalid or has expired. You can turn the email digest off in your profile.";
+          } else {
+            this.errorMessage = "Sorry, could not turn the email digest off. Please try again later.";
+          }
+        }
+      );
+    },
+  },
+});
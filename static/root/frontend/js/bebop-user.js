@@ -64,7 +64,7 @@ var BebopUser = Vue.component("bebop-user", {
 
           <hr>
 
-          <div class="row">
+          <div v-if="!isMe" class="row">
             <div class="col-xs-3">
               Sign in with
             </div>
@@ -73,8 +73,63 @@ var BebopUser = Vue.component("bebop-user", {
             </div>
           </div>
 
+          <div v-else class="row">
+            <div class="col-xs-3">
+              Sign in with
+            </div>
+            <div class="col-xs-6">
+              <div v-for="identity in identities" class="user-identity">
+                {{identity.authService|capitalize}}
+                <span v-if="identity.displayName" class="text-muted">({{identity.displayName}})</span>
+                <a v-if="identities.length > 1" href="#" class="text-danger" title="Unlink" @click.prevent="unlinkIdentity(identity)">
+                  <i class="fa fa-times" aria-hidden="true"></i>
+                </a>
+              </div>
+            </div>
+            <div class="col-xs-3 text-right">
+              <div class="dropdown" v-if="config.oauth.length">
+                <button class="btn btn-default btn-fix dropdown-toggle" data-toggle="dropdown" title="Link another account">
+                  <i class="fa fa-link" aria-hidden="true"></i>
+                </button>
+                <ul class="dropdown-menu dropdown-menu-right">
+                  <li v-for="provider in config.oauth">
+                    <a href="#" @click.prevent="linkIdentity(provider)">{{provider|capitalize}}</a>
+                  </li>
+                </ul>
+              </div>
+            </div>
+          </div>
+          <div v-if="identityError" class="row">
+            <div class="col-xs-12">
+              <div class="alert alert-danger" style="margin-top:10px">{{identityError}}</div>
+            </div>
+          </div>
+
           <hr>
 
+          <div v-if="isMe && config.localAuth" class="row">
+            <div class="col-xs-3">
+              Email digest
+            </div>
+            <div class="col-xs-6">
+              <select class="form-control input-sm user-digest-mode" v-model="digestMode" @change="setDigestMode">
+                <option value="off">Off</option>
+                <option value="immediate">New comments as they come</option>
+                <option value="daily">Once a day</option>
+              </select>
+              <div class="text-muted user-digest-hint">
+                New comments in the topics you watch, sent to your sign-in email.
+              </div>
+            </div>
+          </div>
+          <div v-if="digestError" class="row">
+            <div class="col-xs-12">
+              <div class="alert alert-danger" style="margin-top:10px">{{digestError}}</div>
+            </div>
+          </div>
+
+          <hr v-if="isMe && config.localAuth">
+
           <div class="row">
             <div class="col-xs-3">
               Activated
@@ -84,9 +139,9 @@ var BebopUser = Vue.component("bebop-user", {
             </div>
           </div>
           
-          <hr v-if="auth.authenticated && auth.user.admin && !isMe">
+          <hr v-if="$root.can('user.block') && !isMe">
 
-          <div v-if="auth.authenticated && auth.user.admin && !isMe" class="row">
+          <div v-if="$root.can('user.block') && !isMe" class="row">
             <div class="col-xs-3">
               Blocked
             </div>
@@ -115,6 +170,10 @@ var BebopUser = Vue.component("bebop-user", {
       error: false,
       uploadingAvatar: false,
       avatarUploadError: "",
+      identities: [],
+      identityError: "",
+      digestMode: "off",
+      digestError: "",
     };
   },
 
@@ -161,7 +220,15 @@ var BebopUser = Vue.component("bebop-user", {
       this.error = false;
       this.uploadingAvatar = false;
       this.avatarUploadError = "";
+      this.identities = [];
+      this.identityError = "";
+      this.digestMode = "off";
+      this.digestError = "";
       this.getUser();
+      if (this.isMe) {
+        this.getIdentities();
+        this.getDigestMode();
+      }
     },
 
     getUser: function() {
@@ -170,7 +237,7 @@ var BebopUser = Vue.component("bebop-user", {
         return;
       }
 
-      if (!this.auth.user.admin && this.auth.user.id !== this.userId) {
+      if (!this.$root.can("user.view") && this.auth.user.id !== this.userId) {
         this.$parent.$router.replace("/me");
         return;
       }
@@ -251,6 +318,60 @@ var BebopUser = Vue.component("bebop-user", {
       );
     },
 
+    getIdentities: function() {
+      this.$http.get("api/v1/me/identities").then(
+        response => {
+          this.identities = response.body.identities;
+        },
+        response => {
+          console.log("ERROR: getIdentities: " + JSON.stringify(response.body));
+        }
+      );
+    },
+
+    linkIdentity: function(provider) {
+      this.$root.linkIdentity(provider);
+    },
+
+    unlinkIdentity: function(identity) {
+      if (!confirm("Are you sure you want to unlink " + identity.authService + "?")) {
+        return;
+      }
+      this.identityError = "";
+      this.$http.delete("api/v1/me/identities/" + identity.id).then(
+        response => {
+          this.getIdentities();
+        },
+        response => {
+          console.log("ERROR: unlinkIdentity: " + JSON.stringify(response.body));
+          this.identityError = "Sorry, could not unlink the account. An error occured.";
+        }
+      );
+    },
+
+    getDigestMode: function() {
+      this.$http.get("api/v1/me/digest").then(
+        response => {
+          this.digestMode = response.body.mode;
+        },
+        response => {
+          console.log("ERROR: getDigestMode: " + JSON.stringify(response.body));
+        }
+      );
+    },
+
+    setDigestMode: function() {
+      this.digestError = "";
+      this.$http.put("api/v1/me/digest", { mode: this.digestMode }).then(
+        response => {},
+        response => {
+          console.log("ERROR: setDigestMode: " + JSON.stringify(response.body));
+          this.digestError = "Sorry, could not change the email digest. An error occured.";
+          this.getDigestMode();
+        }
+      );
+    },
+
     setBlocked(val) {
       action = val ? "block" : "unblock";
       if (!confirm("Are you sure you want to " + action + " this user?")) {
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry is a record of an administrative action.
+type AuditEntry struct {
+	ID int64 `json:"id"`
+	// ActorID is the user who performed the action, zero for the command-line tool.
+	ActorID    int64  `json:"actorId"`
+	Action     string `json:"action"`
+	TargetType string `json:"targetType"`
+	TargetID   int64  `json:"targetId"`
+	// Before and After hold the JSON-encoded state of the target.
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	IP        string          `json:"ip"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// Audit log actions.
+const (
+	AuditUserBlocked    = "user.blocked"
+	AuditUserAdmin      = "user.admin"
+	AuditUserRole       = "user.role"
+	AuditRolePermission = "role.permission"
+	AuditTopicDelete    = "topic.delete"
+	AuditTopicCategory  = "topic.category"
+	AuditTopicPinned    = "topic.pinned"
+	AuditTopicLocked    = "topic.locked"
+	AuditCommentEdit    = "comment.edit"
+	AuditCommentDelete  = "comment.delete"
+	AuditCategoryCreate = "category.create"
+	AuditCategoryUpdate = "category.update"
+	AuditCategoryDelete = "category.delete"
+	AuditReportResolve  = "report.resolve"
+	AuditWebhookCreate  = "webhook.create"
+	AuditWebhookDelete  = "webhook.delete"
+)
+
+// Audit log target types.
+const (
+	AuditTargetUser     = "user"
+	AuditTargetTopic    = "topic"
+	AuditTargetComment  = "comment"
+	AuditTargetCategory = "category"
+	AuditTargetReport   = "report"
+	AuditTargetWebhook  = "webhook"
+	AuditTargetRole     = "role"
+)
+
+// AuditFilter selects audit log entries. Zero fields match any entry.
+type AuditFilter struct {
+	ActorID    int64
+	Action     string
+	TargetType string
+	TargetID   int64
+	// Since and Until limit the entry creation time, inclusive and exclusive respectively.
+	Since time.Time
+	Until time.Time
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"unicode/utf8"
+)
+
+// Category is a board that groups related topics.
+type Category struct {
+	ID          int64  `json:"id"`
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	SortOrder   int    `json:"sortOrder"`
+	AdminOnly   bool   `json:"adminOnly"`
+}
+
+const (
+	categorySlugMinLen        = 1
+	categorySlugMaxLen        = 50
+	categoryNameMinLen        = 1
+	categoryNameMaxLen        = 50
+	categoryDescriptionMaxLen = 500
+)
+
+// ValidCategorySlug checks if category slug is valid.
+// A slug consists of lowercase latin letters, digits and hyphens.
+func ValidCategorySlug(slug string) bool {
+	length := utf8.RuneCountInString(slug)
+	if !(categorySlugMinLen <= length && length <= categorySlugMaxLen) {
+		return false
+	}
+
+	// Numeric slugs are not allowed as they would be ambiguous with category IDs.
+	numeric := true
+	for _, r := range slug {
+		switch {
+		case '0' <= r && r <= '9':
+		case 'a' <= r && r <= 'z' || r == '-':
+			numeric = false
+		default:
+			return false
+		}
+	}
+
+	return !numeric
+}
+
+// ValidCategoryName checks if category name is valid.
+func ValidCategoryName(name string) bool {
+	if !utf8.ValidString(name) {
+		return false
+	}
+
+	length := utf8.RuneCountInString(name)
+	if !(categoryNameMinLen <= length && length <= categoryNameMaxLen) {
+		return false
+	}
+
+	return true
+}
+
+// ValidCategoryDescription checks if category description is valid.
+func ValidCategoryDescription(description string) bool {
+	if !utf8.ValidString(description) {
+		return false
+	}
+
+	return utf8.RuneCountInString(description) <= categoryDescriptionMaxLen
+}
@@ -12,6 +12,18 @@ type Comment struct {
 	AuthorID  int64     `json:"authorId"`
 	Content   string    `json:"content"`
 	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	EditCount int       `json:"editCount"`
+}
+
+// CommentRevision is a single version of an edited comment.
+// The first revision holds the original content written by the comment author.
+type CommentRevision struct {
+	ID        int64     `json:"id"`
+	CommentID int64     `json:"commentId"`
+	EditorID  int64     `json:"editorId"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 const (
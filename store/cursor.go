@@ -0,0 +1,120 @@
+package store
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor means the pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("store: invalid cursor")
+
+// TopicCursor is a position in a topic list ordered by
+// (pinned desc, last_comment_at desc, id desc).
+// The next page starts right after the topic it points to.
+type TopicCursor struct {
+	Pinned        bool
+	LastCommentAt time.Time
+	ID            int64
+}
+
+// NewTopicCursor returns a cursor pointing to the given topic.
+func NewTopicCursor(t *Topic) *TopicCursor {
+	return &TopicCursor{
+		Pinned:        t.Pinned,
+		LastCommentAt: t.LastCommentAt,
+		ID:            t.ID,
+	}
+}
+
+// String encodes the cursor to an opaque URL-safe string.
+func (c *TopicCursor) String() string {
+	pinned := "0"
+	if c.Pinned {
+		pinned = "1"
+	}
+	return encodeCursor(pinned, strconv.FormatInt(c.LastCommentAt.UnixNano(), 10), strconv.FormatInt(c.ID, 10))
+}
+
+// ParseTopicCursor decodes a topic cursor encoded with TopicCursor.String.
+func ParseTopicCursor(s string) (*TopicCursor, error) {
+	fields, err := decodeCursor(s, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	if fields[0] != "0" && fields[0] != "1" {
+		return nil, ErrInvalidCursor
+	}
+	t, id, err := parseCursorPosition(fields[1], fields[2])
+	if err != nil {
+		return nil, err
+	}
+
+	return &TopicCursor{Pinned: fields[0] == "1", LastCommentAt: t, ID: id}, nil
+}
+
+// CommentCursor is a position in a comment list ordered by (created_at, id).
+// The next page starts right after the comment it points to.
+type CommentCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// NewCommentCursor returns a cursor pointing to the given comment.
+func NewCommentCursor(c *Comment) *CommentCursor {
+	return &CommentCursor{
+		CreatedAt: c.CreatedAt,
+		ID:        c.ID,
+	}
+}
+
+// String encodes the cursor to an opaque URL-safe string.
+func (c *CommentCursor) String() string {
+	return encodeCursor(strconv.FormatInt(c.CreatedAt.UnixNano(), 10), strconv.FormatInt(c.ID, 10))
+}
+
+// ParseCommentCursor decodes a comment cursor encoded with CommentCursor.String.
+func ParseCommentCursor(s string) (*CommentCursor, error) {
+	fields, err := decodeCursor(s, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	t, id, err := parseCursorPosition(fields[0], fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &CommentCursor{CreatedAt: t, ID: id}, nil
+}
+
+func encodeCursor(fields ...string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(fields, ":")))
+}
+
+func decodeCursor(s string, n int) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	fields := strings.Split(string(data), ":")
+	if len(fields) != n {
+		return nil, ErrInvalidCursor
+	}
+	return fields, nil
+}
+
+func parseCursorPosition(timeField, idField string) (time.Time, int64, error) {
+	nsec, err := strconv.ParseInt(timeField, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(idField, 10, 64)
+	if err != nil || id < 1 {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	return time.Unix(0, nsec).UTC(), id, nil
+}
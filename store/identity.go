@@ -0,0 +1,19 @@
+package store
+
+import (
+	"time"
+)
+
+// Identity is a login identity of a user: an auth service and the ID
+// of the user in it. A user can sign in with any of their identities.
+// DisplayName and Picture are the profile data received from the auth service
+// on the last sign in, they are empty if the service does not provide them.
+type Identity struct {
+	ID          int64     `json:"id"`
+	UserID      int64     `json:"userId"`
+	AuthService string    `json:"authService"`
+	AuthID      string    `json:"-"`
+	CreatedAt   time.Time `json:"createdAt"`
+	DisplayName string    `json:"displayName"`
+	Picture     string    `json:"picture"`
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"time"
+)
+
+// LocalAuthService is the auth service of the users signed up
+// with an email and a password. Their auth ID is the email.
+const LocalAuthService = "local"
+
+// LocalAccount is the email and password of a user signed up without OAuth.
+type LocalAccount struct {
+	UserID       int64     `json:"userId"`
+	Email        string    `json:"email"`
+	PasswordHash string    `json:"-"`
+	Verified     bool      `json:"verified"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Auth token purposes.
+const (
+	AuthTokenVerifyEmail   = "verify_email"
+	AuthTokenResetPassword = "reset_password"
+	AuthTokenMagicLink     = "magic_link"
+	AuthTokenOAuthCode     = "oauth_code"
+	AuthTokenOAuthLink     = "oauth_link"
+)
+
+// AuthToken is a single-use token sent to a user by email
+// or a one-time code handed to the app after an OAuth sign in.
+// The store keeps only the token hash.
+type AuthToken struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"userId"`
+	Purpose   string    `json:"purpose"`
+	TokenHash string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
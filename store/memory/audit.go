@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"encoding/json"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type auditStore struct {
+	db *db
+}
+
+// New appends an entry to the audit log. The entry creation time is set to the current time.
+func (s *auditStore) New(entry *store.AuditEntry) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	e := *entry
+	e.ID = s.db.nextID("audit_log")
+	e.Before = auditJSON(entry.Before)
+	e.After = auditJSON(entry.After)
+	e.CreatedAt = now()
+	s.db.auditLog = append(s.db.auditLog, &e)
+
+	return e.ID, nil
+}
+
+// GetByFilter returns a limited number of the latest audit log entries matching
+// the filter and a total count of the matching entries.
+func (s *auditStore) GetByFilter(filter *store.AuditFilter, offset, limit int) ([]*store.AuditEntry, int, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	// The log is append-only, so it is already sorted by creation time.
+	var all []*store.AuditEntry
+	for i := len(s.db.auditLog) - 1; i >= 0; i-- {
+		e := s.db.auditLog[i]
+		switch {
+		case filter.ActorID != 0 && e.ActorID != filter.ActorID,
+			filter.Action != "" && e.Action != filter.Action,
+			filter.TargetType != "" && e.TargetType != filter.TargetType,
+			filter.TargetID != 0 && e.TargetID != filter.TargetID,
+			!filter.Since.IsZero() && e.CreatedAt.Before(filter.Since),
+			!filter.Until.IsZero() && !e.CreatedAt.Before(filter.Until):
+			continue
+		}
+		all = append(all, e)
+	}
+	count := len(all)
+
+	if limit <= 0 || offset > count {
+		return []*store.AuditEntry{}, count, nil
+	}
+
+	entries := []*store.AuditEntry{}
+	for i := offset; i < count && i < offset+limit; i++ {
+		e := *all[i]
+		entries = append(entries, &e)
+	}
+
+	return entries, count, nil
+}
+
+// auditJSON returns a copy of the JSON payload, "null" for an empty one.
+func auditJSON(data json.RawMessage) json.RawMessage {
+	if len(data) == 0 {
+		return json.RawMessage("null")
+	}
+	return append(json.RawMessage(nil), data...)
+}
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type categoryStore struct {
+	db *db
+}
+
+// New creates a new category. It returns ErrConflict if the given slug is already taken.
+func (s *categoryStore) New(slug, name, description string, sortOrder int, adminOnly bool) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, c := range s.db.categories {
+		if c.Slug == slug {
+			return 0, store.ErrConflict
+		}
+	}
+
+	c := &store.Category{
+		ID:          s.db.nextID("categories"),
+		Slug:        slug,
+		Name:        name,
+		Description: description,
+		SortOrder:   sortOrder,
+		AdminOnly:   adminOnly,
+	}
+	s.db.categories[c.ID] = c
+
+	return c.ID, nil
+}
+
+// Get finds a category by ID.
+func (s *categoryStore) Get(id int64) (*store.Category, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	c, ok := s.db.categories[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return copyCategory(c), nil
+}
+
+// GetBySlug finds a category by slug.
+func (s *categoryStore) GetBySlug(slug string) (*store.Category, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	for _, c := range s.db.categories {
+		if c.Slug == slug {
+			return copyCategory(c), nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+// GetAll returns all the categories ordered by sort order.
+func (s *categoryStore) GetAll() ([]*store.Category, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	categories := []*store.Category{}
+	for _, c := range s.db.categories {
+		categories = append(categories, copyCategory(c))
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].SortOrder != categories[j].SortOrder {
+			return categories[i].SortOrder < categories[j].SortOrder
+		}
+		return categories[i].ID < categories[j].ID
+	})
+	return categories, nil
+}
+
+// Update saves all the category fields. It returns ErrConflict if the new slug is already taken.
+func (s *categoryStore) Update(category *store.Category) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.categories[category.ID]; !ok {
+		return store.ErrNotFound
+	}
+	for _, c := range s.db.categories {
+		if c.ID != category.ID && c.Slug == category.Slug {
+			return store.ErrConflict
+		}
+	}
+
+	s.db.categories[category.ID] = copyCategory(category)
+	return nil
+}
+
+// Delete deletes a category. It returns ErrConflict if the category still has topics.
+// Deleted topics are moved out of the category.
+func (s *categoryStore) Delete(id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, t := range s.db.topics {
+		if !t.deleted && t.CategoryID == id {
+			return store.ErrConflict
+		}
+	}
+
+	for _, t := range s.db.topics {
+		if t.CategoryID == id {
+			t.CategoryID = 0
+		}
+	}
+	delete(s.db.categories, id)
+
+	return nil
+}
+
+func copyCategory(c *store.Category) *store.Category {
+	cc := *c
+	return &cc
+}
@@ -0,0 +1,268 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type commentStore struct {
+	db *db
+}
+
+// New creates a new comment.
+func (s *commentStore) New(topicID int64, authorID int64, content string, mentionIDs []int64) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	t, ok := s.db.topics[topicID]
+	if !ok {
+		return 0, store.ErrNotFound
+	}
+	if _, ok := s.db.users[authorID]; !ok {
+		return 0, store.ErrNotFound
+	}
+
+	now := now()
+	c := &comment{
+		Comment: store.Comment{
+			ID:        s.db.nextID("comments"),
+			TopicID:   topicID,
+			AuthorID:  authorID,
+			Content:   content,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+	s.db.comments[c.ID] = c
+
+	t.LastCommentAt = now
+	t.CommentCount++
+
+	for _, n := range store.CommentNotifications(topicID, t.AuthorID, c.ID, authorID, mentionIDs) {
+		n.ID = s.db.nextID("notifications")
+		n.CreatedAt = now
+		s.db.notifications[n.ID] = n
+	}
+
+	for k := range s.db.watches {
+		if k.topicID != topicID || k.userID == authorID {
+			continue
+		}
+		if d, ok := s.db.digests[k.userID]; ok && d.mode != store.DigestOff {
+			s.db.digestQueue[digestQueueKey{userID: k.userID, commentID: c.ID}] = true
+		}
+	}
+
+	return c.ID, nil
+}
+
+// Get finds a comment by ID.
+func (s *commentStore) Get(id int64) (*store.Comment, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	c, ok := s.db.comments[id]
+	if !ok || c.deleted {
+		return nil, store.ErrNotFound
+	}
+	return copyComment(c), nil
+}
+
+// GetByTopic finds comments by topic.
+func (s *commentStore) GetByTopic(topicID int64, offset, limit int) ([]*store.Comment, int, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	var all []*comment
+	for _, c := range s.db.comments {
+		if !c.deleted && c.TopicID == topicID {
+			all = append(all, c)
+		}
+	}
+	count := len(all)
+
+	if limit <= 0 || offset > count {
+		return []*store.Comment{}, count, nil
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].CreatedAt.Equal(all[j].CreatedAt) {
+			return all[i].CreatedAt.Before(all[j].CreatedAt)
+		}
+		return all[i].ID < all[j].ID
+	})
+
+	comments := []*store.Comment{}
+	for i := offset; i < count && i < offset+limit; i++ {
+		comments = append(comments, copyComment(all[i]))
+	}
+
+	return comments, count, nil
+}
+
+// GetByTopicAfter finds a limited number of comments by topic following the cursor.
+func (s *commentStore) GetByTopicAfter(topicID int64, cursor *store.CommentCursor, limit int) ([]*store.Comment, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	if limit <= 0 {
+		return []*store.Comment{}, nil
+	}
+
+	var all []*comment
+	for _, c := range s.db.comments {
+		if c.deleted || c.TopicID != topicID {
+			continue
+		}
+		if cursor != nil && !commentAfterCursor(&c.Comment, cursor) {
+			continue
+		}
+		all = append(all, c)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].CreatedAt.Equal(all[j].CreatedAt) {
+			return all[i].CreatedAt.Before(all[j].CreatedAt)
+		}
+		return all[i].ID < all[j].ID
+	})
+
+	comments := []*store.Comment{}
+	for i := 0; i < len(all) && i < limit; i++ {
+		comments = append(comments, copyComment(all[i]))
+	}
+
+	return comments, nil
+}
+
+// commentAfterCursor reports whether the comment follows the cursor position
+// in the (created_at, id) order.
+func commentAfterCursor(c *store.Comment, cur *store.CommentCursor) bool {
+	if !c.CreatedAt.Equal(cur.CreatedAt) {
+		return c.CreatedAt.After(cur.CreatedAt)
+	}
+	return c.ID > cur.ID
+}
+
+// Search finds comments having all the query terms in the content, latest first.
+// Comments of deleted topics are excluded.
+func (s *commentStore) Search(query string, offset, limit int) ([]*store.Comment, int, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	terms := store.SearchTerms(query)
+
+	var all []*comment
+	for _, c := range s.db.comments {
+		if c.deleted || len(terms) == 0 || !matchTerms(c.Content, terms) {
+			continue
+		}
+		if t, ok := s.db.topics[c.TopicID]; !ok || t.deleted {
+			continue
+		}
+		all = append(all, c)
+	}
+	count := len(all)
+
+	if limit <= 0 || offset > count {
+		return []*store.Comment{}, count, nil
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].CreatedAt.Equal(all[j].CreatedAt) {
+			return all[i].CreatedAt.After(all[j].CreatedAt)
+		}
+		return all[i].ID > all[j].ID
+	})
+
+	comments := []*store.Comment{}
+	for i := offset; i < count && i < offset+limit; i++ {
+		comments = append(comments, copyComment(all[i]))
+	}
+
+	return comments, count, nil
+}
+
+// GetRevisions returns the edit history of a comment, oldest first.
+func (s *commentStore) GetRevisions(id int64) ([]*store.CommentRevision, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	revisions := []*store.CommentRevision{}
+	for _, r := range s.db.commentRevisions {
+		if r.CommentID == id {
+			c := *r
+			revisions = append(revisions, &c)
+		}
+	}
+	return revisions, nil
+}
+
+// SetContent updates comment.Content value and saves the edit to the comment revisions.
+func (s *commentStore) SetContent(id int64, editorID int64, content string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	c, ok := s.db.comments[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+
+	// Keep the original content as the first revision.
+	if c.EditCount == 0 {
+		s.db.commentRevisions = append(s.db.commentRevisions, &store.CommentRevision{
+			ID:        s.db.nextID("comment_revisions"),
+			CommentID: id,
+			EditorID:  c.AuthorID,
+			Content:   c.Content,
+			CreatedAt: c.CreatedAt,
+		})
+	}
+
+	now := now()
+	s.db.commentRevisions = append(s.db.commentRevisions, &store.CommentRevision{
+		ID:        s.db.nextID("comment_revisions"),
+		CommentID: id,
+		EditorID:  editorID,
+		Content:   content,
+		CreatedAt: now,
+	})
+
+	c.Content = content
+	c.UpdatedAt = now
+	c.EditCount++
+	return nil
+}
+
+// Delete soft-deletes a comment.
+func (s *commentStore) Delete(id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	c, ok := s.db.comments[id]
+	if !ok || c.deleted {
+		return nil
+	}
+	c.deleted = true
+
+	t, ok := s.db.topics[c.TopicID]
+	if !ok {
+		return nil
+	}
+	t.CommentCount--
+	last := t.CreatedAt
+	for _, other := range s.db.comments {
+		if other.TopicID == t.ID && !other.deleted && other.CreatedAt.After(last) {
+			last = other.CreatedAt
+		}
+	}
+	t.LastCommentAt = last
+
+	return nil
+}
+
+func copyComment(c *comment) *store.Comment {
+	cc := c.Comment
+	return &cc
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type digestStore struct {
+	db *db
+}
+
+// GetMode returns the user digest mode, DigestOff if it is not set.
+func (s *digestStore) GetMode(userID int64) (string, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	d, ok := s.db.digests[userID]
+	if !ok {
+		return store.DigestOff, nil
+	}
+	return d.mode, nil
+}
+
+// SetMode sets the user digest mode. The DigestOff mode clears the user digest queue.
+func (s *digestStore) SetMode(userID int64, mode string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[userID]; !ok {
+		return store.ErrNotFound
+	}
+
+	d, ok := s.db.digests[userID]
+	if !ok {
+		d = &digestSetting{}
+		s.db.digests[userID] = d
+	}
+	d.mode = mode
+
+	if mode == store.DigestOff {
+		for k := range s.db.digestQueue {
+			if k.userID == userID {
+				delete(s.db.digestQueue, k)
+			}
+		}
+	}
+	return nil
+}
+
+// queued returns the queued user comments that are not deleted, in order.
+// The caller must hold the lock.
+func (s *digestStore) queued(userID int64) []*store.DigestComment {
+	comments := []*store.DigestComment{}
+	for k := range s.db.digestQueue {
+		if k.userID != userID {
+			continue
+		}
+		c, ok := s.db.comments[k.commentID]
+		if !ok || c.deleted {
+			continue
+		}
+		t, ok := s.db.topics[c.TopicID]
+		if !ok || t.deleted {
+			continue
+		}
+		u, ok := s.db.users[c.AuthorID]
+		if !ok {
+			continue
+		}
+		comments = append(comments, &store.DigestComment{
+			CommentID:  c.ID,
+			TopicID:    c.TopicID,
+			TopicTitle: t.Title,
+			AuthorID:   c.AuthorID,
+			AuthorName: u.Name,
+			Content:    c.Content,
+			CreatedAt:  c.CreatedAt,
+		})
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		return comments[i].CommentID < comments[j].CommentID
+	})
+	return comments
+}
+
+// GetDueUsers returns the IDs of the users whose digest is due, in order.
+func (s *digestStore) GetDueUsers(dailySentBefore time.Time) ([]int64, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	userIDs := []int64{}
+	for userID, d := range s.db.digests {
+		switch d.mode {
+		case store.DigestImmediate:
+		case store.DigestDaily:
+			if !d.lastSentAt.IsZero() && !d.lastSentAt.Before(dailySentBefore) {
+				continue
+			}
+		default:
+			continue
+		}
+		if len(s.queued(userID)) > 0 {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	sort.Slice(userIDs, func(i, j int) bool {
+		return userIDs[i] < userIDs[j]
+	})
+	return userIDs, nil
+}
+
+// GetQueued returns the comments queued for the user digest in order.
+func (s *digestStore) GetQueued(userID int64) ([]*store.DigestComment, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	return s.queued(userID), nil
+}
+
+// MarkSent removes the comments up to lastCommentID from the user digest queue.
+func (s *digestStore) MarkSent(userID int64, lastCommentID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for k := range s.db.digestQueue {
+		if k.userID == userID && k.commentID <= lastCommentID {
+			delete(s.db.digestQueue, k)
+		}
+	}
+	if d, ok := s.db.digests[userID]; ok {
+		d.lastSentAt = now()
+	}
+	return nil
+}
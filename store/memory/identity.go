@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type identityStore struct {
+	db *db
+}
+
+// findIdentity returns the identity with the given auth service and ID.
+// The caller must hold the lock.
+func (d *db) findIdentity(authService, authID string) *store.Identity {
+	for _, i := range d.identities {
+		if i.AuthService == authService && i.AuthID == authID {
+			return i
+		}
+	}
+	return nil
+}
+
+// addIdentity creates a new identity. The caller must hold the write lock.
+func (d *db) addIdentity(userID int64, authService, authID string) int64 {
+	i := &store.Identity{
+		ID:          d.nextID("identities"),
+		UserID:      userID,
+		AuthService: authService,
+		AuthID:      authID,
+		CreatedAt:   now(),
+	}
+	d.identities[i.ID] = i
+	return i.ID
+}
+
+// New links a new identity to the user.
+// It returns ErrConflict if the identity belongs to any user.
+func (s *identityStore) New(userID int64, authService, authID string) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if s.db.findIdentity(authService, authID) != nil {
+		return 0, store.ErrConflict
+	}
+
+	return s.db.addIdentity(userID, authService, authID), nil
+}
+
+// GetByUser returns the identities of the user ordered by creation time.
+func (s *identityStore) GetByUser(userID int64) ([]*store.Identity, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	identities := []*store.Identity{}
+	for _, i := range s.db.identities {
+		if i.UserID == userID {
+			c := *i
+			identities = append(identities, &c)
+		}
+	}
+	sort.Slice(identities, func(a, b int) bool {
+		return identities[a].ID < identities[b].ID
+	})
+	return identities, nil
+}
+
+// SetProfile updates the profile data of the identity.
+func (s *identityStore) SetProfile(authService, authID, displayName, picture string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if i := s.db.findIdentity(authService, authID); i != nil {
+		i.DisplayName = displayName
+		i.Picture = picture
+	}
+	return nil
+}
+
+// Delete unlinks the identity from the user. It returns ErrNotFound if the user
+// has no such identity and ErrConflict if it is the last identity of the user.
+func (s *identityStore) Delete(userID int64, id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	identity, ok := s.db.identities[id]
+	if !ok || identity.UserID != userID {
+		return store.ErrNotFound
+	}
+
+	count := 0
+	for _, i := range s.db.identities {
+		if i.UserID == userID {
+			count++
+		}
+	}
+	if count <= 1 {
+		return store.ErrConflict
+	}
+
+	delete(s.db.identities, id)
+
+	if identity.AuthService == store.LocalAuthService {
+		for tokenID, t := range s.db.authTokens {
+			if t.UserID == userID {
+				delete(s.db.authTokens, tokenID)
+			}
+		}
+		delete(s.db.localAccounts, userID)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"strings"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type localAccountStore struct {
+	db *db
+}
+
+// New creates a new user with the local auth service and its account.
+// It returns ErrConflict if the email is already taken.
+func (s *localAccountStore) New(email, passwordHash string) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	email = strings.ToLower(email)
+
+	if s.db.findIdentity(store.LocalAuthService, email) != nil {
+		return 0, store.ErrConflict
+	}
+	for _, a := range s.db.localAccounts {
+		if a.Email == email {
+			return 0, store.ErrConflict
+		}
+	}
+
+	u := &store.User{
+		ID:          s.db.nextID("users"),
+		CreatedAt:   now(),
+		AuthService: store.LocalAuthService,
+		AuthID:      email,
+	}
+	s.db.users[u.ID] = u
+	s.db.addIdentity(u.ID, store.LocalAuthService, email)
+
+	s.db.localAccounts[u.ID] = &store.LocalAccount{
+		UserID:       u.ID,
+		Email:        email,
+		PasswordHash: passwordHash,
+		CreatedAt:    u.CreatedAt,
+	}
+
+	return u.ID, nil
+}
+
+// Get finds an account by user ID.
+func (s *localAccountStore) Get(userID int64) (*store.LocalAccount, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	a, ok := s.db.localAccounts[userID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	c := *a
+	return &c, nil
+}
+
+// GetByEmail finds an account by email.
+func (s *localAccountStore) GetByEmail(email string) (*store.LocalAccount, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	email = strings.ToLower(email)
+	for _, a := range s.db.localAccounts {
+		if a.Email == email {
+			c := *a
+			return &c, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+// SetPasswordHash updates the account password hash.
+func (s *localAccountStore) SetPasswordHash(userID int64, passwordHash string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	a, ok := s.db.localAccounts[userID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	a.PasswordHash = passwordHash
+	return nil
+}
+
+// SetVerified marks the account email as verified.
+func (s *localAccountStore) SetVerified(userID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	a, ok := s.db.localAccounts[userID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	a.Verified = true
+	return nil
+}
+
+type authTokenStore struct {
+	db *db
+}
+
+// New creates a new auth token. The expired tokens of the user are removed.
+// It returns ErrConflict if a token with the same hash exists.
+func (s *authTokenStore) New(userID int64, purpose, tokenHash string, expiresAt time.Time) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	t := now()
+	for id, token := range s.db.authTokens {
+		if token.UserID == userID && !token.ExpiresAt.After(t) {
+			delete(s.db.authTokens, id)
+		}
+	}
+
+	for _, token := range s.db.authTokens {
+		if token.TokenHash == tokenHash {
+			return 0, store.ErrConflict
+		}
+	}
+
+	id := s.db.nextID("auth_tokens")
+	s.db.authTokens[id] = &store.AuthToken{
+		ID:        id,
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		CreatedAt: t,
+		ExpiresAt: expiresAt.Round(0),
+	}
+
+	return id, nil
+}
+
+// Use deletes the unexpired token with the given purpose and hash and returns it.
+// It returns ErrNotFound if there is no such token.
+func (s *authTokenStore) Use(purpose, tokenHash string) (*store.AuthToken, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	t := now()
+	for id, token := range s.db.authTokens {
+		if token.Purpose == purpose && token.TokenHash == tokenHash && token.ExpiresAt.After(t) {
+			delete(s.db.authTokens, id)
+			return token, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+// DeleteByUser deletes all the tokens of a user with the given purpose.
+func (s *authTokenStore) DeleteByUser(userID int64, purpose string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for id, token := range s.db.authTokens {
+		if token.UserID == userID && token.Purpose == purpose {
+			delete(s.db.authTokens, id)
+		}
+	}
+	return nil
+}
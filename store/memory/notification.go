@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type notificationStore struct {
+	db *db
+}
+
+// visible reports whether the notification comment and topic are not deleted.
+// The caller must hold the lock.
+func (s *notificationStore) visible(n *store.Notification) bool {
+	c, ok := s.db.comments[n.CommentID]
+	if !ok || c.deleted {
+		return false
+	}
+	t, ok := s.db.topics[n.TopicID]
+	return ok && !t.deleted
+}
+
+// GetByUser returns the user notifications, latest first.
+func (s *notificationStore) GetByUser(userID int64, unreadOnly bool, offset, limit int) ([]*store.Notification, int, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	var all []*store.Notification
+	for _, n := range s.db.notifications {
+		if n.UserID == userID && (!unreadOnly || !n.Read) && s.visible(n) {
+			all = append(all, n)
+		}
+	}
+	count := len(all)
+
+	if limit <= 0 || offset > count {
+		return []*store.Notification{}, count, nil
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].ID > all[j].ID
+	})
+
+	notifications := []*store.Notification{}
+	for i := offset; i < count && i < offset+limit; i++ {
+		c := *all[i]
+		c.TopicTitle = s.db.topics[c.TopicID].Title
+		notifications = append(notifications, &c)
+	}
+
+	return notifications, count, nil
+}
+
+// CountUnread returns the number of the unread user notifications.
+func (s *notificationStore) CountUnread(userID int64) (int, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	count := 0
+	for _, n := range s.db.notifications {
+		if n.UserID == userID && !n.Read && s.visible(n) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// MarkRead marks the user notification as read.
+// It returns ErrNotFound if the user has no such notification.
+func (s *notificationStore) MarkRead(userID int64, id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	n, ok := s.db.notifications[id]
+	if !ok || n.UserID != userID {
+		return store.ErrNotFound
+	}
+	n.Read = true
+	return nil
+}
+
+// MarkAllRead marks all the user notifications as read.
+func (s *notificationStore) MarkAllRead(userID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, n := range s.db.notifications {
+		if n.UserID == userID {
+			n.Read = true
+		}
+	}
+	return nil
+}
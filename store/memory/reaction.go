@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type reactionStore struct {
+	db *db
+}
+
+// Add adds a user reaction to a comment.
+// It returns ErrConflict if the user has already reacted with the same emoji.
+func (s *reactionStore) Add(commentID int64, userID int64, emoji string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.comments[commentID]; !ok {
+		return store.ErrNotFound
+	}
+	if _, ok := s.db.users[userID]; !ok {
+		return store.ErrNotFound
+	}
+
+	key := reactionKey{commentID: commentID, userID: userID, emoji: emoji}
+	if _, ok := s.db.reactions[key]; ok {
+		return store.ErrConflict
+	}
+	s.db.reactions[key] = now()
+
+	return nil
+}
+
+// Remove removes a user reaction from a comment.
+// It returns ErrNotFound if there is no such reaction.
+func (s *reactionStore) Remove(commentID int64, userID int64, emoji string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	key := reactionKey{commentID: commentID, userID: userID, emoji: emoji}
+	if _, ok := s.db.reactions[key]; !ok {
+		return store.ErrNotFound
+	}
+	delete(s.db.reactions, key)
+
+	return nil
+}
+
+// GetCounts returns the reaction counts of the given comments, grouped by emoji.
+// The Reacted flag is set for the reactions of the given user.
+func (s *reactionStore) GetCounts(commentIDs []int64, userID int64) (map[int64][]*store.ReactionCount, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	wanted := make(map[int64]bool)
+	for _, id := range commentIDs {
+		wanted[id] = true
+	}
+
+	byEmoji := make(map[int64]map[string]*store.ReactionCount)
+	for key := range s.db.reactions {
+		if !wanted[key.commentID] {
+			continue
+		}
+		if byEmoji[key.commentID] == nil {
+			byEmoji[key.commentID] = make(map[string]*store.ReactionCount)
+		}
+		rc := byEmoji[key.commentID][key.emoji]
+		if rc == nil {
+			rc = &store.ReactionCount{Emoji: key.emoji}
+			byEmoji[key.commentID][key.emoji] = rc
+		}
+		rc.Count++
+		if key.userID == userID {
+			rc.Reacted = true
+		}
+	}
+
+	counts := make(map[int64][]*store.ReactionCount)
+	for commentID, m := range byEmoji {
+		for _, rc := range m {
+			counts[commentID] = append(counts[commentID], rc)
+		}
+		sort.Slice(counts[commentID], func(i, j int) bool {
+			return counts[commentID][i].Emoji < counts[commentID][j].Emoji
+		})
+	}
+
+	return counts, nil
+}
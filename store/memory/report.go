@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type reportStore struct {
+	db *db
+}
+
+// New creates a new open report. It returns ErrConflict
+// if the reporter already has an open report about the same content.
+func (s *reportStore) New(reporterID int64, targetType string, targetID int64, authorID int64, reason string) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[reporterID]; !ok {
+		return 0, store.ErrNotFound
+	}
+	if _, ok := s.db.users[authorID]; !ok {
+		return 0, store.ErrNotFound
+	}
+
+	for _, r := range s.db.reports {
+		if r.ReporterID == reporterID && r.TargetType == targetType && r.TargetID == targetID && r.Status == store.ReportStatusOpen {
+			return 0, store.ErrConflict
+		}
+	}
+
+	r := &store.Report{
+		ID:         s.db.nextID("reports"),
+		ReporterID: reporterID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		AuthorID:   authorID,
+		Reason:     reason,
+		Status:     store.ReportStatusOpen,
+		CreatedAt:  now(),
+	}
+	s.db.reports[r.ID] = r
+
+	return r.ID, nil
+}
+
+// Get finds a report by ID.
+func (s *reportStore) Get(id int64) (*store.Report, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	r, ok := s.db.reports[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return copyReport(r), nil
+}
+
+// GetByStatus returns a limited number of reports with the given status, oldest first,
+// and a total count of such reports. Empty status means all the reports.
+func (s *reportStore) GetByStatus(status string, offset, limit int) ([]*store.Report, int, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	var all []*store.Report
+	for _, r := range s.db.reports {
+		if status == "" || r.Status == status {
+			all = append(all, r)
+		}
+	}
+	count := len(all)
+
+	if limit <= 0 || offset > count {
+		return []*store.Report{}, count, nil
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].CreatedAt.Equal(all[j].CreatedAt) {
+			return all[i].CreatedAt.Before(all[j].CreatedAt)
+		}
+		return all[i].ID < all[j].ID
+	})
+
+	reports := []*store.Report{}
+	for i := offset; i < count && i < offset+limit; i++ {
+		reports = append(reports, copyReport(all[i]))
+	}
+
+	return reports, count, nil
+}
+
+// Resolve closes an open report with the given resolution status
+// and records the resolving admin and time. The other open reports
+// about the same content are resolved the same way.
+// It returns ErrConflict if the report is already resolved.
+func (s *reportStore) Resolve(id int64, status string, resolverID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	report, ok := s.db.reports[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if report.Status != store.ReportStatusOpen {
+		return store.ErrConflict
+	}
+
+	now := now()
+	for _, r := range s.db.reports {
+		if r.TargetType == report.TargetType && r.TargetID == report.TargetID && r.Status == store.ReportStatusOpen {
+			r.Status = status
+			r.ResolvedBy = resolverID
+			resolvedAt := now
+			r.ResolvedAt = &resolvedAt
+		}
+	}
+
+	return nil
+}
+
+func copyReport(r *store.Report) *store.Report {
+	rr := *r
+	if r.ResolvedAt != nil {
+		t := *r.ResolvedAt
+		rr.ResolvedAt = &t
+	}
+	return &rr
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type roleStore struct {
+	db *db
+}
+
+// Grant grants the role to the user.
+// It returns ErrConflict if the user already has the role.
+func (s *roleStore) Grant(userID int64, role string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	key := userRoleKey{userID: userID, role: role}
+	if s.db.userRoles[key] {
+		return store.ErrConflict
+	}
+	s.db.userRoles[key] = true
+	return nil
+}
+
+// Revoke revokes the role from the user.
+// It returns ErrNotFound if the user does not have the role.
+func (s *roleStore) Revoke(userID int64, role string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	key := userRoleKey{userID: userID, role: role}
+	if !s.db.userRoles[key] {
+		return store.ErrNotFound
+	}
+	delete(s.db.userRoles, key)
+	return nil
+}
+
+// GetByUser returns the roles of the user in alphabetical order.
+func (s *roleStore) GetByUser(userID int64) ([]string, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	roles := []string{}
+	for key := range s.db.userRoles {
+		if key.userID == userID {
+			roles = append(roles, key.role)
+		}
+	}
+	sort.Strings(roles)
+	return roles, nil
+}
+
+// GetUsers returns the IDs of the users that have the role in ascending order.
+func (s *roleStore) GetUsers(role string) ([]int64, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	userIDs := []int64{}
+	for key := range s.db.userRoles {
+		if key.role == role {
+			userIDs = append(userIDs, key.userID)
+		}
+	}
+	sort.Slice(userIDs, func(i, j int) bool {
+		return userIDs[i] < userIDs[j]
+	})
+	return userIDs, nil
+}
+
+// GrantPermission grants the permission to the role.
+// It returns ErrConflict if the role already grants the permission.
+func (s *roleStore) GrantPermission(role, permission string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	key := rolePermKey{role: role, permission: permission}
+	if s.db.rolePerms[key] {
+		return store.ErrConflict
+	}
+	s.db.rolePerms[key] = true
+	return nil
+}
+
+// RevokePermission revokes the permission from the role.
+// It returns ErrNotFound if the role does not grant the permission.
+func (s *roleStore) RevokePermission(role, permission string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	key := rolePermKey{role: role, permission: permission}
+	if !s.db.rolePerms[key] {
+		return store.ErrNotFound
+	}
+	delete(s.db.rolePerms, key)
+	return nil
+}
+
+// GetPermissions returns the permissions granted by any of the roles in alphabetical order.
+func (s *roleStore) GetPermissions(roles []string) ([]string, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	set := make(map[string]bool)
+	for _, role := range roles {
+		for key := range s.db.rolePerms {
+			if key.role == role {
+				set[key.permission] = true
+			}
+		}
+	}
+
+	permissions := make([]string, 0, len(set))
+	for p := range set {
+		permissions = append(permissions, p)
+	}
+	sort.Strings(permissions)
+	return permissions, nil
+}
+
+// HasPermission checks if any of the user roles grants the permission.
+func (s *roleStore) HasPermission(userID int64, permission string) (bool, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	for key := range s.db.userRoles {
+		if key.userID == userID && s.db.rolePerms[rolePermKey{role: key.role, permission: permission}] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
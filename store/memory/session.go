@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type sessionStore struct {
+	db *db
+}
+
+// New creates a new session. The expired sessions of the user are removed.
+// It returns ErrConflict if a session with the same token hash exists.
+func (s *sessionStore) New(userID int64, tokenHash string, expiresAt time.Time) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	t := now()
+	for id, session := range s.db.sessions {
+		if session.UserID == userID && !session.ExpiresAt.After(t) {
+			delete(s.db.sessions, id)
+		}
+	}
+
+	for _, session := range s.db.sessions {
+		if session.TokenHash == tokenHash {
+			return 0, store.ErrConflict
+		}
+	}
+
+	id := s.db.nextID("sessions")
+	s.db.sessions[id] = &store.Session{
+		ID:        id,
+		UserID:    userID,
+		TokenHash: tokenHash,
+		CreatedAt: t,
+		ExpiresAt: expiresAt.Round(0),
+	}
+
+	return id, nil
+}
+
+// GetByToken finds a session by the refresh token hash.
+func (s *sessionStore) GetByToken(tokenHash string) (*store.Session, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	for _, session := range s.db.sessions {
+		if session.TokenHash == tokenHash {
+			c := *session
+			return &c, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+// Rotate replaces the session token hash and expiration time.
+// It returns ErrNotFound if the current token hash does not match,
+// e.g. if the session has already been rotated by a concurrent request.
+func (s *sessionStore) Rotate(id int64, tokenHash, newTokenHash string, expiresAt time.Time) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	session, ok := s.db.sessions[id]
+	if !ok || session.TokenHash != tokenHash {
+		return store.ErrNotFound
+	}
+	session.TokenHash = newTokenHash
+	session.ExpiresAt = expiresAt.Round(0)
+	return nil
+}
+
+// Delete deletes a session.
+func (s *sessionStore) Delete(id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.sessions[id]; !ok {
+		return store.ErrNotFound
+	}
+	delete(s.db.sessions, id)
+	return nil
+}
+
+// DeleteByUser deletes all the sessions of a user.
+func (s *sessionStore) DeleteByUser(userID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for id, session := range s.db.sessions {
+		if session.UserID == userID {
+			delete(s.db.sessions, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+// Package memory provides an in-memory implementation of the bebop data store interface.
+// It is safe for concurrent use and is intended for tests and demo deployments:
+// all the data is lost when the process exits.
+package memory
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+// Store is an in-memory implementation of store.
+type Store struct {
+	db            *db
+	userStore     *userStore
+	topicStore    *topicStore
+	commentStore  *commentStore
+	categoryStore *categoryStore
+	reactionStore *reactionStore
+	reportStore   *reportStore
+	auditStore    *auditStore
+	sessionStore  *sessionStore
+	localStore    *localAccountStore
+	tokenStore    *authTokenStore
+	identityStore *identityStore
+	roleStore     *roleStore
+	notifyStore   *notificationStore
+	watchStore    *watchStore
+	digestStore   *digestStore
+	webhookStore  *webhookStore
+}
+
+// Users returns a user store.
+func (s *Store) Users() store.UserStore {
+	return s.userStore
+}
+
+// Topics returns a topic store.
+func (s *Store) Topics() store.TopicStore {
+	return s.topicStore
+}
+
+// Comments returns a comment store.
+func (s *Store) Comments() store.CommentStore {
+	return s.commentStore
+}
+
+// Categories returns a category store.
+func (s *Store) Categories() store.CategoryStore {
+	return s.categoryStore
+}
+
+// Reactions returns a reaction store.
+func (s *Store) Reactions() store.ReactionStore {
+	return s.reactionStore
+}
+
+// Reports returns a report store.
+func (s *Store) Reports() store.ReportStore {
+	return s.reportStore
+}
+
+// Audit returns an audit log store.
+func (s *Store) Audit() store.AuditStore {
+	return s.auditStore
+}
+
+// Sessions returns a login session store.
+func (s *Store) Sessions() store.SessionStore {
+	return s.sessionStore
+}
+
+// LocalAccounts returns a local account store.
+func (s *Store) LocalAccounts() store.LocalAccountStore {
+	return s.localStore
+}
+
+// AuthTokens returns a single-use auth token store.
+func (s *Store) AuthTokens() store.AuthTokenStore {
+	return s.tokenStore
+}
+
+// Identities returns a user identity store.
+func (s *Store) Identities() store.IdentityStore {
+	return s.identityStore
+}
+
+// Roles returns a user role store.
+func (s *Store) Roles() store.RoleStore {
+	return s.roleStore
+}
+
+// Notifications returns a user notification store.
+func (s *Store) Notifications() store.NotificationStore {
+	return s.notifyStore
+}
+
+// Watches returns a topic watch store.
+func (s *Store) Watches() store.WatchStore {
+	return s.watchStore
+}
+
+// Digests returns an email digest store.
+func (s *Store) Digests() store.DigestStore {
+	return s.digestStore
+}
+
+// Webhooks returns a webhook store.
+func (s *Store) Webhooks() store.WebhookStore {
+	return s.webhookStore
+}
+
+var _ store.Store = (*Store)(nil)
+
+// New creates a new empty store.
+func New() *Store {
+	db := newDB()
+	return &Store{
+		db:            db,
+		userStore:     &userStore{db: db},
+		topicStore:    &topicStore{db: db},
+		commentStore:  &commentStore{db: db},
+		categoryStore: &categoryStore{db: db},
+		reactionStore: &reactionStore{db: db},
+		reportStore:   &reportStore{db: db},
+		auditStore:    &auditStore{db: db},
+		sessionStore:  &sessionStore{db: db},
+		localStore:    &localAccountStore{db: db},
+		tokenStore:    &authTokenStore{db: db},
+		identityStore: &identityStore{db: db},
+		roleStore:     &roleStore{db: db},
+		notifyStore:   &notificationStore{db: db},
+		watchStore:    &watchStore{db: db},
+		digestStore:   &digestStore{db: db},
+		webhookStore:  &webhookStore{db: db},
+	}
+}
+
+// Reset removes all the data from the store.
+func (s *Store) Reset() {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.reset()
+}
+
+// db holds all the store data. A single lock guards every table
+// so that operations touching several tables stay consistent.
+type db struct {
+	mu sync.RWMutex
+
+	users      map[int64]*store.User
+	topics     map[int64]*topic
+	comments   map[int64]*comment
+	categories map[int64]*store.Category
+	reactions  map[reactionKey]time.Time
+	reports    map[int64]*store.Report
+	sessions   map[int64]*store.Session
+
+	localAccounts map[int64]*store.LocalAccount
+	authTokens    map[int64]*store.AuthToken
+	identities    map[int64]*store.Identity
+	userRoles     map[userRoleKey]bool
+	rolePerms     map[rolePermKey]bool
+	notifications map[int64]*store.Notification
+	watches       map[watchKey]time.Time
+	digests       map[int64]*digestSetting
+	digestQueue   map[digestQueueKey]bool
+	webhooks      map[int64]*store.Webhook
+	deliveries    map[int64]*store.WebhookDelivery
+	deliveryLocks map[int64]time.Time
+
+	topicRevisions   []*store.TopicRevision
+	commentRevisions []*store.CommentRevision
+	auditLog         []*store.AuditEntry
+
+	lastID map[string]int64
+}
+
+type topic struct {
+	store.Topic
+	deleted bool
+}
+
+type comment struct {
+	store.Comment
+	deleted bool
+}
+
+// userRoleKey identifies a role granted to a user.
+type userRoleKey struct {
+	userID int64
+	role   string
+}
+
+// rolePermKey identifies a permission granted by a role.
+type rolePermKey struct {
+	role       string
+	permission string
+}
+
+// watchKey identifies a topic watched by a user. The value is the watch time.
+type watchKey struct {
+	userID  int64
+	topicID int64
+}
+
+// digestQueueKey identifies a comment queued for a user digest.
+type digestQueueKey struct {
+	userID    int64
+	commentID int64
+}
+
+// digestSetting is the user email digest mode and the time of the last sent digest.
+type digestSetting struct {
+	mode       string
+	lastSentAt time.Time
+}
+
+// reactionKey identifies a single reaction. The value is the reaction time.
+type reactionKey struct {
+	commentID int64
+	userID    int64
+	emoji     string
+}
+
+func newDB() *db {
+	d := &db{}
+	d.reset()
+	return d
+}
+
+// reset initializes empty tables with the default role permissions.
+// The caller must hold the write lock.
+func (d *db) reset() {
+	d.users = make(map[int64]*store.User)
+	d.topics = make(map[int64]*topic)
+	d.comments = make(map[int64]*comment)
+	d.categories = make(map[int64]*store.Category)
+	d.reactions = make(map[reactionKey]time.Time)
+	d.reports = make(map[int64]*store.Report)
+	d.sessions = make(map[int64]*store.Session)
+	d.localAccounts = make(map[int64]*store.LocalAccount)
+	d.authTokens = make(map[int64]*store.AuthToken)
+	d.identities = make(map[int64]*store.Identity)
+	d.userRoles = make(map[userRoleKey]bool)
+	d.rolePerms = make(map[rolePermKey]bool)
+	for role, permissions := range store.DefaultRolePermissions {
+		for _, p := range permissions {
+			d.rolePerms[rolePermKey{role: role, permission: p}] = true
+		}
+	}
+	d.notifications = make(map[int64]*store.Notification)
+	d.watches = make(map[watchKey]time.Time)
+	d.digests = make(map[int64]*digestSetting)
+	d.digestQueue = make(map[digestQueueKey]bool)
+	d.webhooks = make(map[int64]*store.Webhook)
+	d.deliveries = make(map[int64]*store.WebhookDelivery)
+	d.deliveryLocks = make(map[int64]time.Time)
+	d.topicRevisions = nil
+	d.commentRevisions = nil
+	d.auditLog = nil
+	d.lastID = make(map[string]int64)
+}
+
+// nextID allocates a new ID in the given table, starting from 1.
+func (d *db) nextID(table string) int64 {
+	d.lastID[table]++
+	return d.lastID[table]
+}
+
+// now returns the current time without the monotonic clock reading,
+// the same way it would be returned from an SQL database.
+func now() time.Time {
+	return time.Now().Round(0)
+}
+
+// matchTerms reports whether the text contains all the lowercase search terms.
+func matchTerms(text string, terms []string) bool {
+	text = strings.ToLower(text)
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/storetest"
+)
+
+func getTestStore(t *testing.T) (*Store, func()) {
+	s := New()
+	teardown := func() {
+		s.Reset()
+	}
+	return s, teardown
+}
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (store.Store, func()) {
+		return getTestStore(t)
+	})
+}
+
+func TestConcurrentComments(t *testing.T) {
+	s, teardown := getTestStore(t)
+	defer teardown()
+
+	u, err := s.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+	topicID, err := s.Topics().New(u, 0, "topic1")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Comments().New(topicID, u, "comment", nil); err != nil {
+				t.Errorf("failed to create a comment: %s", err)
+			}
+			if _, _, err := s.Topics().GetLatest(0, 10); err != nil {
+				t.Errorf("failed to get latest topics: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	topic, err := s.Topics().Get(topicID)
+	if err != nil {
+		t.Fatalf("failed to get a topic: %s", err)
+	}
+	if topic.CommentCount != n {
+		t.Fatalf("bad topic.CommentCount: %d, want %d", topic.CommentCount, n)
+	}
+
+	comments, count, err := s.Comments().GetByTopic(topicID, 0, n)
+	if err != nil {
+		t.Fatalf("failed to get comments: %s", err)
+	}
+	if count != n || len(comments) != n {
+		t.Fatalf("bad comment count: %d (%d returned), want %d", count, len(comments), n)
+	}
+	seen := make(map[int64]bool)
+	for _, c := range comments {
+		if seen[c.ID] {
+			t.Fatalf("duplicate comment id: %d", c.ID)
+		}
+		seen[c.ID] = true
+	}
+}
@@ -0,0 +1,328 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type topicStore struct {
+	db *db
+}
+
+// New creates a new topic. Zero categoryID means no category.
+func (s *topicStore) New(authorID int64, categoryID int64, title string) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[authorID]; !ok {
+		return 0, store.ErrNotFound
+	}
+	if _, ok := s.db.categories[categoryID]; categoryID != 0 && !ok {
+		return 0, store.ErrNotFound
+	}
+
+	now := now()
+	t := &topic{
+		Topic: store.Topic{
+			ID:            s.db.nextID("topics"),
+			AuthorID:      authorID,
+			CategoryID:    categoryID,
+			Title:         title,
+			CreatedAt:     now,
+			LastCommentAt: now,
+		},
+	}
+	s.db.topics[t.ID] = t
+
+	return t.ID, nil
+}
+
+// Get finds a topic by ID.
+func (s *topicStore) Get(id int64) (*store.Topic, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	t, ok := s.db.topics[id]
+	if !ok || t.deleted {
+		return nil, store.ErrNotFound
+	}
+	return copyTopic(t), nil
+}
+
+// GetLatest returns a limited number of latest topics and a total topic count.
+// Pinned topics go first.
+func (s *topicStore) GetLatest(offset, limit int) ([]*store.Topic, int, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	var all []*topic
+	for _, t := range s.db.topics {
+		if !t.deleted {
+			all = append(all, t)
+		}
+	}
+	count := len(all)
+
+	if limit <= 0 || offset > count {
+		return []*store.Topic{}, count, nil
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Pinned != all[j].Pinned {
+			return all[i].Pinned
+		}
+		if !all[i].LastCommentAt.Equal(all[j].LastCommentAt) {
+			return all[i].LastCommentAt.After(all[j].LastCommentAt)
+		}
+		return all[i].ID > all[j].ID
+	})
+
+	topics := []*store.Topic{}
+	for i := offset; i < count && i < offset+limit; i++ {
+		topics = append(topics, copyTopic(all[i]))
+	}
+
+	return topics, count, nil
+}
+
+// GetByCategory returns a limited number of latest topics in a category and a total topic count.
+// Pinned topics go first.
+func (s *topicStore) GetByCategory(categoryID int64, offset, limit int) ([]*store.Topic, int, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	var all []*topic
+	for _, t := range s.db.topics {
+		if !t.deleted && t.CategoryID == categoryID {
+			all = append(all, t)
+		}
+	}
+	count := len(all)
+
+	if limit <= 0 || offset > count {
+		return []*store.Topic{}, count, nil
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Pinned != all[j].Pinned {
+			return all[i].Pinned
+		}
+		if !all[i].LastCommentAt.Equal(all[j].LastCommentAt) {
+			return all[i].LastCommentAt.After(all[j].LastCommentAt)
+		}
+		return all[i].ID > all[j].ID
+	})
+
+	topics := []*store.Topic{}
+	for i := offset; i < count && i < offset+limit; i++ {
+		topics = append(topics, copyTopic(all[i]))
+	}
+
+	return topics, count, nil
+}
+
+// GetLatestAfter returns a limited number of latest topics following the cursor.
+// Pinned topics go first.
+func (s *topicStore) GetLatestAfter(cursor *store.TopicCursor, limit int) ([]*store.Topic, error) {
+	return s.getAfter(func(t *topic) bool { return true }, cursor, limit)
+}
+
+// GetByCategoryAfter returns a limited number of latest topics in a category following the cursor.
+// Pinned topics go first.
+func (s *topicStore) GetByCategoryAfter(categoryID int64, cursor *store.TopicCursor, limit int) ([]*store.Topic, error) {
+	return s.getAfter(func(t *topic) bool { return t.CategoryID == categoryID }, cursor, limit)
+}
+
+// getAfter returns the topics matching the filter that follow the cursor position.
+func (s *topicStore) getAfter(filter func(t *topic) bool, cursor *store.TopicCursor, limit int) ([]*store.Topic, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	if limit <= 0 {
+		return []*store.Topic{}, nil
+	}
+
+	var all []*topic
+	for _, t := range s.db.topics {
+		if !t.deleted && filter(t) && (cursor == nil || topicAfterCursor(&t.Topic, cursor)) {
+			all = append(all, t)
+		}
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Pinned != all[j].Pinned {
+			return all[i].Pinned
+		}
+		if !all[i].LastCommentAt.Equal(all[j].LastCommentAt) {
+			return all[i].LastCommentAt.After(all[j].LastCommentAt)
+		}
+		return all[i].ID > all[j].ID
+	})
+
+	topics := []*store.Topic{}
+	for i := 0; i < len(all) && i < limit; i++ {
+		topics = append(topics, copyTopic(all[i]))
+	}
+
+	return topics, nil
+}
+
+// topicAfterCursor reports whether the topic follows the cursor position
+// in the (pinned desc, last_comment_at desc, id desc) order.
+func topicAfterCursor(t *store.Topic, c *store.TopicCursor) bool {
+	if t.Pinned != c.Pinned {
+		return c.Pinned
+	}
+	if !t.LastCommentAt.Equal(c.LastCommentAt) {
+		return t.LastCommentAt.Before(c.LastCommentAt)
+	}
+	return t.ID < c.ID
+}
+
+// Search finds topics having all the query terms in the title, latest first.
+func (s *topicStore) Search(query string, offset, limit int) ([]*store.Topic, int, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	terms := store.SearchTerms(query)
+
+	var all []*topic
+	for _, t := range s.db.topics {
+		if !t.deleted && len(terms) > 0 && matchTerms(t.Title, terms) {
+			all = append(all, t)
+		}
+	}
+	count := len(all)
+
+	if limit <= 0 || offset > count {
+		return []*store.Topic{}, count, nil
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].LastCommentAt.Equal(all[j].LastCommentAt) {
+			return all[i].LastCommentAt.After(all[j].LastCommentAt)
+		}
+		return all[i].ID > all[j].ID
+	})
+
+	topics := []*store.Topic{}
+	for i := offset; i < count && i < offset+limit; i++ {
+		topics = append(topics, copyTopic(all[i]))
+	}
+
+	return topics, count, nil
+}
+
+// GetRevisions returns the edit history of a topic title, oldest first.
+func (s *topicStore) GetRevisions(id int64) ([]*store.TopicRevision, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	revisions := []*store.TopicRevision{}
+	for _, r := range s.db.topicRevisions {
+		if r.TopicID == id {
+			c := *r
+			revisions = append(revisions, &c)
+		}
+	}
+	return revisions, nil
+}
+
+// SetTitle updates topic.Title value and saves the edit to the topic revisions.
+func (s *topicStore) SetTitle(id int64, editorID int64, title string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	t, ok := s.db.topics[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+
+	// Keep the original title as the first revision.
+	hasRevisions := false
+	for _, r := range s.db.topicRevisions {
+		if r.TopicID == id {
+			hasRevisions = true
+			break
+		}
+	}
+	if !hasRevisions {
+		s.db.topicRevisions = append(s.db.topicRevisions, &store.TopicRevision{
+			ID:        s.db.nextID("topic_revisions"),
+			TopicID:   id,
+			EditorID:  t.AuthorID,
+			Title:     t.Title,
+			CreatedAt: t.CreatedAt,
+		})
+	}
+
+	s.db.topicRevisions = append(s.db.topicRevisions, &store.TopicRevision{
+		ID:        s.db.nextID("topic_revisions"),
+		TopicID:   id,
+		EditorID:  editorID,
+		Title:     title,
+		CreatedAt: now(),
+	})
+
+	t.Title = title
+	return nil
+}
+
+// SetCategory updates topic.CategoryID value. Zero categoryID means no category.
+func (s *topicStore) SetCategory(id int64, categoryID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	t, ok := s.db.topics[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	if _, ok := s.db.categories[categoryID]; categoryID != 0 && !ok {
+		return store.ErrNotFound
+	}
+	t.CategoryID = categoryID
+	return nil
+}
+
+// SetPinned updates topic.Pinned value.
+func (s *topicStore) SetPinned(id int64, pinned bool) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	t, ok := s.db.topics[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	t.Pinned = pinned
+	return nil
+}
+
+// SetLocked updates topic.Locked value.
+func (s *topicStore) SetLocked(id int64, locked bool) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	t, ok := s.db.topics[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	t.Locked = locked
+	return nil
+}
+
+// Delete soft-deletes a topic.
+func (s *topicStore) Delete(id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if t, ok := s.db.topics[id]; ok {
+		t.deleted = true
+	}
+	return nil
+}
+
+func copyTopic(t *topic) *store.Topic {
+	c := t.Topic
+	return &c
+}
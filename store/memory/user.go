@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"strings"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type userStore struct {
+	db *db
+}
+
+// New creates a new user with the given identity.
+// It returns ErrConflict if the identity belongs to another user.
+func (s *userStore) New(authService string, authID string) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if s.db.findIdentity(authService, authID) != nil {
+		return 0, store.ErrConflict
+	}
+
+	u := &store.User{
+		ID:          s.db.nextID("users"),
+		CreatedAt:   now(),
+		AuthService: authService,
+		AuthID:      authID,
+	}
+	s.db.users[u.ID] = u
+	s.db.addIdentity(u.ID, authService, authID)
+
+	return u.ID, nil
+}
+
+// Get finds a user by ID.
+func (s *userStore) Get(id int64) (*store.User, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	u, ok := s.db.users[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return copyUser(u), nil
+}
+
+// GetMany finds users by IDs.
+func (s *userStore) GetMany(ids []int64) (map[int64]*store.User, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	users := make(map[int64]*store.User)
+	for _, id := range ids {
+		u, ok := s.db.users[id]
+		if !ok {
+			return nil, store.ErrNotFound
+		}
+		users[id] = copyUser(u)
+	}
+	return users, nil
+}
+
+// GetByName finds a user by name.
+func (s *userStore) GetByName(name string) (*store.User, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	for _, u := range s.db.users {
+		if u.Name != "" && u.Name == name {
+			return copyUser(u), nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+// GetByNameFold finds a user by name ignoring case.
+func (s *userStore) GetByNameFold(name string) (*store.User, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	name = strings.ToLower(name)
+	for _, u := range s.db.users {
+		if u.Name != "" && strings.ToLower(u.Name) == name {
+			return copyUser(u), nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+// GetByAuth finds a user by any of their identities.
+func (s *userStore) GetByAuth(authService string, authID string) (*store.User, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	i := s.db.findIdentity(authService, authID)
+	if i == nil {
+		return nil, store.ErrNotFound
+	}
+	u, ok := s.db.users[i.UserID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return copyUser(u), nil
+}
+
+// SetName updates user.Name value. It returns ErrConflict if the given name is already taken.
+// User names are compared case-insensitively.
+func (s *userStore) SetName(id int64, name string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, u := range s.db.users {
+		if u.ID != id && strings.EqualFold(u.Name, name) {
+			return store.ErrConflict
+		}
+	}
+	return s.update(id, func(u *store.User) { u.Name = name })
+}
+
+// SetBlocked updates user.Blocked value.
+func (s *userStore) SetBlocked(id int64, blocked bool) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.update(id, func(u *store.User) { u.Blocked = blocked })
+}
+
+// SetAvatar updates user.Avatar value.
+func (s *userStore) SetAvatar(id int64, avatar string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.update(id, func(u *store.User) { u.Avatar = avatar })
+}
+
+// SetTokensValidAfter updates user.TokensValidAfter value.
+func (s *userStore) SetTokensValidAfter(id int64, t time.Time) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.update(id, func(u *store.User) { u.TokensValidAfter = t })
+}
+
+// update applies fn to the user with the given ID. The caller must hold the write lock.
+func (s *userStore) update(id int64, fn func(u *store.User)) error {
+	u, ok := s.db.users[id]
+	if !ok {
+		return store.ErrNotFound
+	}
+	fn(u)
+	return nil
+}
+
+func copyUser(u *store.User) *store.User {
+	c := *u
+	return &c
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"github.com/disintegration/bebop/store"
+)
+
+type watchStore struct {
+	db *db
+}
+
+// Watch starts watching the topic. It does nothing if the user already watches it.
+func (s *watchStore) Watch(userID, topicID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if _, ok := s.db.users[userID]; !ok {
+		return store.ErrNotFound
+	}
+	if _, ok := s.db.topics[topicID]; !ok {
+		return store.ErrNotFound
+	}
+
+	k := watchKey{userID: userID, topicID: topicID}
+	if _, ok := s.db.watches[k]; !ok {
+		s.db.watches[k] = now()
+	}
+	return nil
+}
+
+// Unwatch stops watching the topic and removes its comments from the user digest queue.
+func (s *watchStore) Unwatch(userID, topicID int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	delete(s.db.watches, watchKey{userID: userID, topicID: topicID})
+	for k := range s.db.digestQueue {
+		if c, ok := s.db.comments[k.commentID]; ok && k.userID == userID && c.TopicID == topicID {
+			delete(s.db.digestQueue, k)
+		}
+	}
+	return nil
+}
+
+// IsWatching checks if the user watches the topic.
+func (s *watchStore) IsWatching(userID, topicID int64) (bool, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	_, ok := s.db.watches[watchKey{userID: userID, topicID: topicID}]
+	return ok, nil
+}
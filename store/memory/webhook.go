@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type webhookStore struct {
+	db *db
+}
+
+// New creates a new webhook.
+func (s *webhookStore) New(url, secret string, events []string) (int64, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	w := &store.Webhook{
+		ID:        s.db.nextID("webhooks"),
+		URL:       url,
+		Secret:    secret,
+		Events:    append([]string{}, events...),
+		CreatedAt: now(),
+	}
+	s.db.webhooks[w.ID] = w
+	return w.ID, nil
+}
+
+// Get finds a webhook by ID.
+func (s *webhookStore) Get(id int64) (*store.Webhook, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	w, ok := s.db.webhooks[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return copyWebhook(w), nil
+}
+
+// GetAll returns all the webhooks in order of creation.
+func (s *webhookStore) GetAll() ([]*store.Webhook, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	webhooks := []*store.Webhook{}
+	for _, w := range s.db.webhooks {
+		webhooks = append(webhooks, copyWebhook(w))
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks, nil
+}
+
+// Delete deletes a webhook and its deliveries.
+func (s *webhookStore) Delete(id int64) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	for _, d := range s.db.deliveries {
+		if d.WebhookID == id {
+			delete(s.db.deliveries, d.ID)
+			delete(s.db.deliveryLocks, d.ID)
+		}
+	}
+	delete(s.db.webhooks, id)
+	return nil
+}
+
+// Enqueue adds a pending delivery of the event for every webhook subscribed to it.
+func (s *webhookStore) Enqueue(event string, payload []byte) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	var ids []int64
+	for _, w := range s.db.webhooks {
+		if w.Subscribed(event) {
+			ids = append(ids, w.ID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	now := now()
+	for _, id := range ids {
+		d := &store.WebhookDelivery{
+			ID:            s.db.nextID("webhook_deliveries"),
+			WebhookID:     id,
+			Event:         event,
+			Payload:       append([]byte{}, payload...),
+			Status:        store.WebhookDeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		s.db.deliveries[d.ID] = d
+	}
+	return nil
+}
+
+// ClaimDue claims the pending deliveries whose next attempt is due, oldest first.
+func (s *webhookStore) ClaimDue(now, lockedUntil time.Time, limit int) ([]*store.WebhookDelivery, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	deliveries := []*store.WebhookDelivery{}
+	for _, d := range s.db.deliveries {
+		if d.Status != store.WebhookDeliveryPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		if t, ok := s.db.deliveryLocks[d.ID]; ok && t.After(now) {
+			continue
+		}
+		deliveries = append(deliveries, copyDelivery(d))
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].NextAttemptAt.Equal(deliveries[j].NextAttemptAt) {
+			return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt)
+		}
+		return deliveries[i].ID < deliveries[j].ID
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	for _, d := range deliveries {
+		s.db.deliveryLocks[d.ID] = lockedUntil.Round(0)
+	}
+	return deliveries, nil
+}
+
+// SetDeliveryResult records a delivery attempt.
+func (s *webhookStore) SetDeliveryResult(id int64, status string, responseCode int, errorMessage string, nextAttemptAt time.Time) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	d, ok := s.db.deliveries[id]
+	if !ok {
+		return nil
+	}
+	d.Status = status
+	d.Attempts++
+	d.ResponseCode = responseCode
+	d.Error = errorMessage
+	d.NextAttemptAt = nextAttemptAt.Round(0)
+	d.UpdatedAt = now()
+	delete(s.db.deliveryLocks, id)
+	return nil
+}
+
+// GetDeliveries returns a limited number of the latest webhook deliveries
+// and a total count of the webhook deliveries.
+func (s *webhookStore) GetDeliveries(webhookID int64, offset, limit int) ([]*store.WebhookDelivery, int, error) {
+	s.db.mu.RLock()
+	defer s.db.mu.RUnlock()
+
+	var all []*store.WebhookDelivery
+	for _, d := range s.db.deliveries {
+		if d.WebhookID == webhookID {
+			all = append(all, d)
+		}
+	}
+	count := len(all)
+
+	if limit <= 0 || offset > count {
+		return []*store.WebhookDelivery{}, count, nil
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].ID > all[j].ID
+	})
+
+	end := offset + limit
+	if end > count {
+		end = count
+	}
+	deliveries := []*store.WebhookDelivery{}
+	for _, d := range all[offset:end] {
+		deliveries = append(deliveries, copyDelivery(d))
+	}
+	return deliveries, count, nil
+}
+
+func copyWebhook(w *store.Webhook) *store.Webhook {
+	ww := *w
+	ww.Events = append([]string{}, w.Events...)
+	return &ww
+}
+
+func copyDelivery(d *store.WebhookDelivery) *store.WebhookDelivery {
+	dd := *d
+	dd.Payload = append([]byte{}, d.Payload...)
+	return &dd
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrPendingMigrations means the database schema is behind the current version
+// and the store was configured not to apply the migrations automatically.
+var ErrPendingMigrations = errors.New("store: pending schema migrations")
+
+// MigrateMode defines what a store does with pending schema migrations on connect.
+type MigrateMode int
+
+// Migrate modes.
+const (
+	// MigrateAuto applies all the pending migrations.
+	MigrateAuto MigrateMode = iota
+	// MigrateCheck refuses to connect if there are pending migrations.
+	MigrateCheck
+	// MigrateNone leaves the database schema as is.
+	MigrateNone
+)
+
+// ParseMigrateMode parses a migrate mode name: "auto", "check" or "none".
+// An empty string means "auto".
+func ParseMigrateMode(s string) (MigrateMode, error) {
+	switch s {
+	case "", "auto":
+		return MigrateAuto, nil
+	case "check":
+		return MigrateCheck, nil
+	case "none":
+		return MigrateNone, nil
+	}
+	return 0, fmt.Errorf("unknown migrate mode: %s", s)
+}
+
+// Migrator is implemented by stores that have a versioned database schema.
+type Migrator interface {
+	MigrationStatus() ([]*MigrationStatus, error)
+	Migrate() error
+	Rollback(steps int) error
+}
+
+// Migration is a numbered database schema change.
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+// Checksum returns a hex-encoded SHA-256 hash of the migration up statements.
+// It is saved when the migration is applied to detect later modifications.
+func (m *Migration) Checksum() string {
+	h := sha256.New()
+	for _, q := range m.Up {
+		h.Write([]byte(strings.TrimSpace(q)))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// MigrationStatus describes the state of a single migration in the database.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Checksum  string
+	Applied   bool
+	AppliedAt time.Time
+	// Modified means the migration has changed since it was applied.
+	Modified bool
+	// Unknown means the migration is applied to the database
+	// but is missing from the current version of bebop.
+	Unknown bool
+}
+
+// GetMigrationStatus merges the known migrations with the migrations
+// applied to the database. The result is sorted by version.
+func GetMigrationStatus(migrations []*Migration, applied []*MigrationStatus) []*MigrationStatus {
+	appliedMap := make(map[int]*MigrationStatus)
+	for _, a := range applied {
+		appliedMap[a.Version] = a
+	}
+
+	var status []*MigrationStatus
+	for _, m := range migrations {
+		st := &MigrationStatus{
+			Version:  m.Version,
+			Name:     m.Name,
+			Checksum: m.Checksum(),
+		}
+		if a, ok := appliedMap[m.Version]; ok {
+			st.Applied = true
+			st.AppliedAt = a.AppliedAt
+			st.Modified = a.Checksum != st.Checksum
+			delete(appliedMap, m.Version)
+		}
+		status = append(status, st)
+	}
+
+	for _, a := range appliedMap {
+		status = append(status, &MigrationStatus{
+			Version:   a.Version,
+			Name:      a.Name,
+			Checksum:  a.Checksum,
+			Applied:   true,
+			AppliedAt: a.AppliedAt,
+			Unknown:   true,
+		})
+	}
+
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].Version < status[j].Version
+	})
+
+	return status
+}
+
+// CheckMigrationStatus returns an error if any of the applied migrations
+// has been modified or is unknown to the current version of bebop.
+func CheckMigrationStatus(status []*MigrationStatus) error {
+	for _, st := range status {
+		if st.Modified {
+			return fmt.Errorf("store: migration %d (%s) has been modified after it was applied", st.Version, st.Name)
+		}
+		if st.Unknown {
+			return fmt.Errorf("store: unknown migration %d (%s) is applied to the database", st.Version, st.Name)
+		}
+	}
+	return nil
+}
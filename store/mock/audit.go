@@ -0,0 +1,18 @@
+package mock
+
+import (
+	"github.com/disintegration/bebop/store"
+)
+
+// AuditStore is a mock implementation of store.AuditStore.
+type AuditStore struct {
+	OnNew         func(entry *store.AuditEntry) (int64, error)
+	OnGetByFilter func(filter *store.AuditFilter, offset, limit int) ([]*store.AuditEntry, int, error)
+}
+
+func (s *AuditStore) New(entry *store.AuditEntry) (int64, error) {
+	return s.OnNew(entry)
+}
+func (s *AuditStore) GetByFilter(filter *store.AuditFilter, offset, limit int) ([]*store.AuditEntry, int, error) {
+	return s.OnGetByFilter(filter, offset, limit)
+}
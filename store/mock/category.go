@@ -0,0 +1,34 @@
+package mock
+
+import (
+	"github.com/disintegration/bebop/store"
+)
+
+// CategoryStore is a mock implementation of store.CategoryStore.
+type CategoryStore struct {
+	OnNew       func(slug, name, description string, sortOrder int, adminOnly bool) (int64, error)
+	OnGet       func(id int64) (*store.Category, error)
+	OnGetBySlug func(slug string) (*store.Category, error)
+	OnGetAll    func() ([]*store.Category, error)
+	OnUpdate    func(category *store.Category) error
+	OnDelete    func(id int64) error
+}
+
+func (s *CategoryStore) New(slug, name, description string, sortOrder int, adminOnly bool) (int64, error) {
+	return s.OnNew(slug, name, description, sortOrder, adminOnly)
+}
+func (s *CategoryStore) Get(id int64) (*store.Category, error) {
+	return s.OnGet(id)
+}
+func (s *CategoryStore) GetBySlug(slug string) (*store.Category, error) {
+	return s.OnGetBySlug(slug)
+}
+func (s *CategoryStore) GetAll() ([]*store.Category, error) {
+	return s.OnGetAll()
+}
+func (s *CategoryStore) Update(category *store.Category) error {
+	return s.OnUpdate(category)
+}
+func (s *CategoryStore) Delete(id int64) error {
+	return s.OnDelete(id)
+}
@@ -6,15 +6,18 @@ import (
 
 // CommentStore is a mock implementation of store.CommentStore.
 type CommentStore struct {
-	OnNew        func(topicID int64, authorID int64, content string) (int64, error)
-	OnGet        func(id int64) (*store.Comment, error)
-	OnGetByTopic func(topicID int64, offset, limit int) ([]*store.Comment, int, error)
-	OnSetContent func(id int64, content string) error
-	OnDelete     func(id int64) error
+	OnNew             func(topicID int64, authorID int64, content string, mentionIDs []int64) (int64, error)
+	OnGet             func(id int64) (*store.Comment, error)
+	OnGetByTopic      func(topicID int64, offset, limit int) ([]*store.Comment, int, error)
+	OnGetByTopicAfter func(topicID int64, cursor *store.CommentCursor, limit int) ([]*store.Comment, error)
+	OnSearch          func(query string, offset, limit int) ([]*store.Comment, int, error)
+	OnGetRevisions    func(id int64) ([]*store.CommentRevision, error)
+	OnSetContent      func(id int64, editorID int64, content string) error
+	OnDelete          func(id int64) error
 }
 
-func (s *CommentStore) New(topicID int64, authorID int64, content string) (int64, error) {
-	return s.OnNew(topicID, authorID, content)
+func (s *CommentStore) New(topicID int64, authorID int64, content string, mentionIDs []int64) (int64, error) {
+	return s.OnNew(topicID, authorID, content, mentionIDs)
 }
 func (s *CommentStore) Get(id int64) (*store.Comment, error) {
 	return s.OnGet(id)
@@ -22,8 +25,17 @@ func (s *CommentStore) Get(id int64) (*store.Comment, error) {
 func (s *CommentStore) GetByTopic(topicID int64, offset, limit int) ([]*store.Comment, int, error) {
 	return s.OnGetByTopic(topicID, offset, limit)
 }
-func (s *CommentStore) SetContent(id int64, content string) error {
-	return s.OnSetContent(id, content)
+func (s *CommentStore) GetByTopicAfter(topicID int64, cursor *store.CommentCursor, limit int) ([]*store.Comment, error) {
+	return s.OnGetByTopicAfter(topicID, cursor, limit)
+}
+func (s *CommentStore) Search(query string, offset, limit int) ([]*store.Comment, int, error) {
+	return s.OnSearch(query, offset, limit)
+}
+func (s *CommentStore) GetRevisions(id int64) ([]*store.CommentRevision, error) {
+	return s.OnGetRevisions(id)
+}
+func (s *CommentStore) SetContent(id int64, editorID int64, content string) error {
+	return s.OnSetContent(id, editorID, content)
 }
 func (s *CommentStore) Delete(id int64) error {
 	return s.OnDelete(id)
@@ -0,0 +1,32 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+// DigestStore is a mock implementation of store.DigestStore.
+type DigestStore struct {
+	OnGetMode     func(userID int64) (string, error)
+	OnSetMode     func(userID int64, mode string) error
+	OnGetDueUsers func(dailySentBefore time.Time) ([]int64, error)
+	OnGetQueued   func(userID int64) ([]*store.DigestComment, error)
+	OnMarkSent    func(userID int64, lastCommentID int64) error
+}
+
+func (s *DigestStore) GetMode(userID int64) (string, error) {
+	return s.OnGetMode(userID)
+}
+func (s *DigestStore) SetMode(userID int64, mode string) error {
+	return s.OnSetMode(userID, mode)
+}
+func (s *DigestStore) GetDueUsers(dailySentBefore time.Time) ([]int64, error) {
+	return s.OnGetDueUsers(dailySentBefore)
+}
+func (s *DigestStore) GetQueued(userID int64) ([]*store.DigestComment, error) {
+	return s.OnGetQueued(userID)
+}
+func (s *DigestStore) MarkSent(userID int64, lastCommentID int64) error {
+	return s.OnMarkSent(userID, lastCommentID)
+}
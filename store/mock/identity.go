@@ -0,0 +1,26 @@
+package mock
+
+import (
+	"github.com/disintegration/bebop/store"
+)
+
+// IdentityStore is a mock implementation of store.IdentityStore.
+type IdentityStore struct {
+	OnNew        func(userID int64, authService, authID string) (int64, error)
+	OnGetByUser  func(userID int64) ([]*store.Identity, error)
+	OnSetProfile func(authService, authID, displayName, picture string) error
+	OnDelete     func(userID int64, id int64) error
+}
+
+func (s *IdentityStore) New(userID int64, authService, authID string) (int64, error) {
+	return s.OnNew(userID, authService, authID)
+}
+func (s *IdentityStore) GetByUser(userID int64) ([]*store.Identity, error) {
+	return s.OnGetByUser(userID)
+}
+func (s *IdentityStore) SetProfile(authService, authID, displayName, picture string) error {
+	return s.OnSetProfile(authService, authID, displayName, picture)
+}
+func (s *IdentityStore) Delete(userID int64, id int64) error {
+	return s.OnDelete(userID, id)
+}
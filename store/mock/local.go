@@ -0,0 +1,49 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+// LocalAccountStore is a mock implementation of store.LocalAccountStore.
+type LocalAccountStore struct {
+	OnNew             func(email, passwordHash string) (int64, error)
+	OnGet             func(userID int64) (*store.LocalAccount, error)
+	OnGetByEmail      func(email string) (*store.LocalAccount, error)
+	OnSetPasswordHash func(userID int64, passwordHash string) error
+	OnSetVerified     func(userID int64) error
+}
+
+func (s *LocalAccountStore) New(email, passwordHash string) (int64, error) {
+	return s.OnNew(email, passwordHash)
+}
+func (s *LocalAccountStore) Get(userID int64) (*store.LocalAccount, error) {
+	return s.OnGet(userID)
+}
+func (s *LocalAccountStore) GetByEmail(email string) (*store.LocalAccount, error) {
+	return s.OnGetByEmail(email)
+}
+func (s *LocalAccountStore) SetPasswordHash(userID int64, passwordHash string) error {
+	return s.OnSetPasswordHash(userID, passwordHash)
+}
+func (s *LocalAccountStore) SetVerified(userID int64) error {
+	return s.OnSetVerified(userID)
+}
+
+// AuthTokenStore is a mock implementation of store.AuthTokenStore.
+type AuthTokenStore struct {
+	OnNew          func(userID int64, purpose, tokenHash string, expiresAt time.Time) (int64, error)
+	OnUse          func(purpose, tokenHash string) (*store.AuthToken, error)
+	OnDeleteByUser func(userID int64, purpose string) error
+}
+
+func (s *AuthTokenStore) New(userID int64, purpose, tokenHash string, expiresAt time.Time) (int64, error) {
+	return s.OnNew(userID, purpose, tokenHash, expiresAt)
+}
+func (s *AuthTokenStore) Use(purpose, tokenHash string) (*store.AuthToken, error) {
+	return s.OnUse(purpose, tokenHash)
+}
+func (s *AuthTokenStore) DeleteByUser(userID int64, purpose string) error {
+	return s.OnDeleteByUser(userID, purpose)
+}
@@ -0,0 +1,26 @@
+package mock
+
+import (
+	"github.com/disintegration/bebop/store"
+)
+
+// NotificationStore is a mock implementation of store.NotificationStore.
+type NotificationStore struct {
+	OnGetByUser   func(userID int64, unreadOnly bool, offset, limit int) ([]*store.Notification, int, error)
+	OnCountUnread func(userID int64) (int, error)
+	OnMarkRead    func(userID int64, id int64) error
+	OnMarkAllRead func(userID int64) error
+}
+
+func (s *NotificationStore) GetByUser(userID int64, unreadOnly bool, offset, limit int) ([]*store.Notification, int, error) {
+	return s.OnGetByUser(userID, unreadOnly, offset, limit)
+}
+func (s *NotificationStore) CountUnread(userID int64) (int, error) {
+	return s.OnCountUnread(userID)
+}
+func (s *NotificationStore) MarkRead(userID int64, id int64) error {
+	return s.OnMarkRead(userID, id)
+}
+func (s *NotificationStore) MarkAllRead(userID int64) error {
+	return s.OnMarkAllRead(userID)
+}
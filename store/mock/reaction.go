@@ -0,0 +1,22 @@
+package mock
+
+import (
+	"github.com/disintegration/bebop/store"
+)
+
+// ReactionStore is a mock implementation of store.ReactionStore.
+type ReactionStore struct {
+	OnAdd       func(commentID int64, userID int64, emoji string) error
+	OnRemove    func(commentID int64, userID int64, emoji string) error
+	OnGetCounts func(commentIDs []int64, userID int64) (map[int64][]*store.ReactionCount, error)
+}
+
+func (s *ReactionStore) Add(commentID int64, userID int64, emoji string) error {
+	return s.OnAdd(commentID, userID, emoji)
+}
+func (s *ReactionStore) Remove(commentID int64, userID int64, emoji string) error {
+	return s.OnRemove(commentID, userID, emoji)
+}
+func (s *ReactionStore) GetCounts(commentIDs []int64, userID int64) (map[int64][]*store.ReactionCount, error) {
+	return s.OnGetCounts(commentIDs, userID)
+}
@@ -0,0 +1,26 @@
+package mock
+
+import (
+	"github.com/disintegration/bebop/store"
+)
+
+// ReportStore is a mock implementation of store.ReportStore.
+type ReportStore struct {
+	OnNew         func(reporterID int64, targetType string, targetID int64, authorID int64, reason string) (int64, error)
+	OnGet         func(id int64) (*store.Report, error)
+	OnGetByStatus func(status string, offset, limit int) ([]*store.Report, int, error)
+	OnResolve     func(id int64, status string, resolverID int64) error
+}
+
+func (s *ReportStore) New(reporterID int64, targetType string, targetID int64, authorID int64, reason string) (int64, error) {
+	return s.OnNew(reporterID, targetType, targetID, authorID, reason)
+}
+func (s *ReportStore) Get(id int64) (*store.Report, error) {
+	return s.OnGet(id)
+}
+func (s *ReportStore) GetByStatus(status string, offset, limit int) ([]*store.Report, int, error) {
+	return s.OnGetByStatus(status, offset, limit)
+}
+func (s *ReportStore) Resolve(id int64, status string, resolverID int64) error {
+	return s.OnResolve(id, status, resolverID)
+}
@@ -0,0 +1,39 @@
+package mock
+
+// RoleStore is a mock implementation of store.RoleStore.
+type RoleStore struct {
+	OnGrant     func(userID int64, role string) error
+	OnRevoke    func(userID int64, role string) error
+	OnGetByUser func(userID int64) ([]string, error)
+	OnGetUsers  func(role string) ([]int64, error)
+
+	OnGrantPermission  func(role, permission string) error
+	OnRevokePermission func(role, permission string) error
+	OnGetPermissions   func(roles []string) ([]string, error)
+	OnHasPermission    func(userID int64, permission string) (bool, error)
+}
+
+func (s *RoleStore) Grant(userID int64, role string) error {
+	return s.OnGrant(userID, role)
+}
+func (s *RoleStore) Revoke(userID int64, role string) error {
+	return s.OnRevoke(userID, role)
+}
+func (s *RoleStore) GetByUser(userID int64) ([]string, error) {
+	return s.OnGetByUser(userID)
+}
+func (s *RoleStore) GetUsers(role string) ([]int64, error) {
+	return s.OnGetUsers(role)
+}
+func (s *RoleStore) GrantPermission(role, permission string) error {
+	return s.OnGrantPermission(role, permission)
+}
+func (s *RoleStore) RevokePermission(role, permission string) error {
+	return s.OnRevokePermission(role, permission)
+}
+func (s *RoleStore) GetPermissions(roles []string) ([]string, error) {
+	return s.OnGetPermissions(roles)
+}
+func (s *RoleStore) HasPermission(userID int64, permission string) (bool, error) {
+	return s.OnHasPermission(userID, permission)
+}
@@ -0,0 +1,32 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+// SessionStore is a mock implementation of store.SessionStore.
+type SessionStore struct {
+	OnNew          func(userID int64, tokenHash string, expiresAt time.Time) (int64, error)
+	OnGetByToken   func(tokenHash string) (*store.Session, error)
+	OnRotate       func(id int64, tokenHash, newTokenHash string, expiresAt time.Time) error
+	OnDelete       func(id int64) error
+	OnDeleteByUser func(userID int64) error
+}
+
+func (s *SessionStore) New(userID int64, tokenHash string, expiresAt time.Time) (int64, error) {
+	return s.OnNew(userID, tokenHash, expiresAt)
+}
+func (s *SessionStore) GetByToken(tokenHash string) (*store.Session, error) {
+	return s.OnGetByToken(tokenHash)
+}
+func (s *SessionStore) Rotate(id int64, tokenHash, newTokenHash string, expiresAt time.Time) error {
+	return s.OnRotate(id, tokenHash, newTokenHash, expiresAt)
+}
+func (s *SessionStore) Delete(id int64) error {
+	return s.OnDelete(id)
+}
+func (s *SessionStore) DeleteByUser(userID int64) error {
+	return s.OnDeleteByUser(userID)
+}
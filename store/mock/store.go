@@ -6,9 +6,22 @@ import (
 
 // Store is a mock implementation of store.Store.
 type Store struct {
-	UserStore    *UserStore
-	TopicStore   *TopicStore
-	CommentStore *CommentStore
+	UserStore     *UserStore
+	TopicStore    *TopicStore
+	CommentStore  *CommentStore
+	CategoryStore *CategoryStore
+	ReactionStore *ReactionStore
+	ReportStore   *ReportStore
+	AuditStore    *AuditStore
+	SessionStore  *SessionStore
+	LocalStore    *LocalAccountStore
+	TokenStore    *AuthTokenStore
+	IdentityStore *IdentityStore
+	RoleStore     *RoleStore
+	NotifyStore   *NotificationStore
+	WatchStore    *WatchStore
+	DigestStore   *DigestStore
+	WebhookStore  *WebhookStore
 }
 
 func (s *Store) Users() store.UserStore {
@@ -20,3 +33,42 @@ func (s *Store) Topics() store.TopicStore {
 func (s *Store) Comments() store.CommentStore {
 	return s.CommentStore
 }
+func (s *Store) Categories() store.CategoryStore {
+	return s.CategoryStore
+}
+func (s *Store) Reactions() store.ReactionStore {
+	return s.ReactionStore
+}
+func (s *Store) Reports() store.ReportStore {
+	return s.ReportStore
+}
+func (s *Store) Audit() store.AuditStore {
+	return s.AuditStore
+}
+func (s *Store) Sessions() store.SessionStore {
+	return s.SessionStore
+}
+func (s *Store) LocalAccounts() store.LocalAccountStore {
+	return s.LocalStore
+}
+func (s *Store) AuthTokens() store.AuthTokenStore {
+	return s.TokenStore
+}
+func (s *Store) Identities() store.IdentityStore {
+	return s.IdentityStore
+}
+func (s *Store) Roles() store.RoleStore {
+	return s.RoleStore
+}
+func (s *Store) Notifications() store.NotificationStore {
+	return s.NotifyStore
+}
+func (s *Store) Watches() store.WatchStore {
+	return s.WatchStore
+}
+func (s *Store) Digests() store.DigestStore {
+	return s.DigestStore
+}
+func (s *Store) Webhooks() store.WebhookStore {
+	return s.WebhookStore
+}
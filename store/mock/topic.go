@@ -6,15 +6,23 @@ import (
 
 // TopicStore is a mock implementation of store.TopicStore.
 type TopicStore struct {
-	OnNew       func(authorID int64, title string) (int64, error)
-	OnGet       func(id int64) (*store.Topic, error)
-	OnGetLatest func(offset, limit int) ([]*store.Topic, int, error)
-	OnSetTitle  func(id int64, title string) error
-	OnDelete    func(id int64) error
+	OnNew                func(authorID int64, categoryID int64, title string) (int64, error)
+	OnGet                func(id int64) (*store.Topic, error)
+	OnGetLatest          func(offset, limit int) ([]*store.Topic, int, error)
+	OnGetByCategory      func(categoryID int64, offset, limit int) ([]*store.Topic, int, error)
+	OnGetLatestAfter     func(cursor *store.TopicCursor, limit int) ([]*store.Topic, error)
+	OnGetByCategoryAfter func(categoryID int64, cursor *store.TopicCursor, limit int) ([]*store.Topic, error)
+	OnSearch             func(query string, offset, limit int) ([]*store.Topic, int, error)
+	OnGetRevisions       func(id int64) ([]*store.TopicRevision, error)
+	OnSetTitle           func(id int64, editorID int64, title string) error
+	OnSetCategory        func(id int64, categoryID int64) error
+	OnSetPinned          func(id int64, pinned bool) error
+	OnSetLocked          func(id int64, locked bool) error
+	OnDelete             func(id int64) error
 }
 
-func (s *TopicStore) New(authorID int64, title string) (int64, error) {
-	return s.OnNew(authorID, title)
+func (s *TopicStore) New(authorID int64, categoryID int64, title string) (int64, error) {
+	return s.OnNew(authorID, categoryID, title)
 }
 func (s *TopicStore) Get(id int64) (*store.Topic, error) {
 	return s.OnGet(id)
@@ -22,8 +30,32 @@ func (s *TopicStore) Get(id int64) (*store.Topic, error) {
 func (s *TopicStore) GetLatest(offset, limit int) ([]*store.Topic, int, error) {
 	return s.OnGetLatest(offset, limit)
 }
-func (s *TopicStore) SetTitle(id int64, title string) error {
-	return s.OnSetTitle(id, title)
+func (s *TopicStore) GetByCategory(categoryID int64, offset, limit int) ([]*store.Topic, int, error) {
+	return s.OnGetByCategory(categoryID, offset, limit)
+}
+func (s *TopicStore) GetLatestAfter(cursor *store.TopicCursor, limit int) ([]*store.Topic, error) {
+	return s.OnGetLatestAfter(cursor, limit)
+}
+func (s *TopicStore) GetByCategoryAfter(categoryID int64, cursor *store.TopicCursor, limit int) ([]*store.Topic, error) {
+	return s.OnGetByCategoryAfter(categoryID, cursor, limit)
+}
+func (s *TopicStore) Search(query string, offset, limit int) ([]*store.Topic, int, error) {
+	return s.OnSearch(query, offset, limit)
+}
+func (s *TopicStore) GetRevisions(id int64) ([]*store.TopicRevision, error) {
+	return s.OnGetRevisions(id)
+}
+func (s *TopicStore) SetTitle(id int64, editorID int64, title string) error {
+	return s.OnSetTitle(id, editorID, title)
+}
+func (s *TopicStore) SetCategory(id int64, categoryID int64) error {
+	return s.OnSetCategory(id, categoryID)
+}
+func (s *TopicStore) SetPinned(id int64, pinned bool) error {
+	return s.OnSetPinned(id, pinned)
+}
+func (s *TopicStore) SetLocked(id int64, locked bool) error {
+	return s.OnSetLocked(id, locked)
 }
 func (s *TopicStore) Delete(id int64) error {
 	return s.OnDelete(id)
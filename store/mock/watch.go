@@ -0,0 +1,18 @@
+package mock
+
+// WatchStore is a mock implementation of store.WatchStore.
+type WatchStore struct {
+	OnWatch      func(userID, topicID int64) error
+	OnUnwatch    func(userID, topicID int64) error
+	OnIsWatching func(userID, topicID int64) (bool, error)
+}
+
+func (s *WatchStore) Watch(userID, topicID int64) error {
+	return s.OnWatch(userID, topicID)
+}
+func (s *WatchStore) Unwatch(userID, topicID int64) error {
+	return s.OnUnwatch(userID, topicID)
+}
+func (s *WatchStore) IsWatching(userID, topicID int64) (bool, error) {
+	return s.OnIsWatching(userID, topicID)
+}
@@ -0,0 +1,44 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+// WebhookStore is a mock implementation of store.WebhookStore.
+type WebhookStore struct {
+	OnNew               func(url, secret string, events []string) (int64, error)
+	OnGet               func(id int64) (*store.Webhook, error)
+	OnGetAll            func() ([]*store.Webhook, error)
+	OnDelete            func(id int64) error
+	OnEnqueue           func(event string, payload []byte) error
+	OnClaimDue          func(now, lockedUntil time.Time, limit int) ([]*store.WebhookDelivery, error)
+	OnSetDeliveryResult func(id int64, status string, responseCode int, errorMessage string, nextAttemptAt time.Time) error
+	OnGetDeliveries     func(webhookID int64, offset, limit int) ([]*store.WebhookDelivery, int, error)
+}
+
+func (s *WebhookStore) New(url, secret string, events []string) (int64, error) {
+	return s.OnNew(url, secret, events)
+}
+func (s *WebhookStore) Get(id int64) (*store.Webhook, error) {
+	return s.OnGet(id)
+}
+func (s *WebhookStore) GetAll() ([]*store.Webhook, error) {
+	return s.OnGetAll()
+}
+func (s *WebhookStore) Delete(id int64) error {
+	return s.OnDelete(id)
+}
+func (s *WebhookStore) Enqueue(event string, payload []byte) error {
+	return s.OnEnqueue(event, payload)
+}
+func (s *WebhookStore) ClaimDue(now, lockedUntil time.Time, limit int) ([]*store.WebhookDelivery, error) {
+	return s.OnClaimDue(now, lockedUntil, limit)
+}
+func (s *WebhookStore) SetDeliveryResult(id int64, status string, responseCode int, errorMessage string, nextAttemptAt time.Time) error {
+	return s.OnSetDeliveryResult(id, status, responseCode, errorMessage, nextAttemptAt)
+}
+func (s *WebhookStore) GetDeliveries(webhookID int64, offset, limit int) ([]*store.WebhookDelivery, int, error) {
+	return s.OnGetDeliveries(webhookID, offset, limit)
+}
@@ -0,0 +1,114 @@
+package mysql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type auditStore struct {
+	db *sql.DB
+}
+
+// New appends an entry to the audit log. The entry creation time is set to the current time.
+func (s *auditStore) New(entry *store.AuditEntry) (int64, error) {
+	res, err := s.db.Exec(
+		`
+			insert into audit_log(actor_id, action, target_type, target_id, before_json, after_json, ip, created_at)
+			values(?, ?, ?, ?, coalesce(nullif(?, ''), 'null'), coalesce(nullif(?, ''), 'null'), ?, ?)
+		`,
+		entry.ActorID, entry.Action, entry.TargetType, entry.TargetID,
+		string(entry.Before), string(entry.After), entry.IP, time.Now(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+const selectFromAuditLog = `select id, actor_id, action, target_type, target_id, before_json, after_json, ip, created_at from audit_log`
+
+func (s *auditStore) scanEntry(scanner scanner) (*store.AuditEntry, error) {
+	e := new(store.AuditEntry)
+	var before, after string
+	err := scanner.Scan(&e.ID, &e.ActorID, &e.Action, &e.TargetType, &e.TargetID, &before, &after, &e.IP, &e.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.Before = json.RawMessage(before)
+	e.After = json.RawMessage(after)
+	return e, nil
+}
+
+// GetByFilter returns a limited number of the latest audit log entries matching
+// the filter and a total count of the matching entries.
+func (s *auditStore) GetByFilter(filter *store.AuditFilter, offset, limit int) ([]*store.AuditEntry, int, error) {
+	var conds []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, cond+`?`)
+	}
+
+	conds = append(conds, `true`)
+	if filter.ActorID != 0 {
+		add(`actor_id=`, filter.ActorID)
+	}
+	if filter.Action != "" {
+		add(`action=`, filter.Action)
+	}
+	if filter.TargetType != "" {
+		add(`target_type=`, filter.TargetType)
+	}
+	if filter.TargetID != 0 {
+		add(`target_id=`, filter.TargetID)
+	}
+	if !filter.Since.IsZero() {
+		add(`created_at>=`, filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		add(`created_at<`, filter.Until)
+	}
+	where := strings.Join(conds, ` and `)
+
+	var count int
+	err := s.db.QueryRow(`select count(*) from audit_log where `+where, args...).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if limit <= 0 || offset > count {
+		return []*store.AuditEntry{}, count, nil
+	}
+
+	args = append(args, limit, offset)
+	rows, err := s.db.Query(
+		selectFromAuditLog+` where `+where+` order by created_at desc, id desc limit ? offset ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []*store.AuditEntry{}
+	for rows.Next() {
+		entry, err := s.scanEntry(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, count, nil
+}
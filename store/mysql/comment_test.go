@@ -17,28 +17,28 @@ func TestComment(t *testing.T) {
 		t.Fatalf("failed to create a user: %s", err)
 	}
 
-	t1, err := s.Topics().New(u1, "topic1")
+	t1, err := s.Topics().New(u1, 0, "topic1")
 	if err != nil {
 		t.Fatalf("failed to create a topic: %s", err)
 	}
-	t2, err := s.Topics().New(u2, "topic2")
+	t2, err := s.Topics().New(u2, 0, "topic2")
 	if err != nil {
 		t.Fatalf("failed to create a topic: %s", err)
 	}
 
-	c1, err := s.Comments().New(t1, u1, "comment1")
+	c1, err := s.Comments().New(t1, u1, "comment1", nil)
 	if err != nil {
 		t.Fatalf("failed to create a comment: %s", err)
 	}
-	c2, err := s.Comments().New(t1, u2, "comment2")
+	c2, err := s.Comments().New(t1, u2, "comment2", nil)
 	if err != nil {
 		t.Fatalf("failed to create a comment: %s", err)
 	}
-	c3, err := s.Comments().New(t2, u1, "comment3")
+	c3, err := s.Comments().New(t2, u1, "comment3", nil)
 	if err != nil {
 		t.Fatalf("failed to create a comment: %s", err)
 	}
-	c4, err := s.Comments().New(t2, u2, "comment4 日本 Доброе утро")
+	c4, err := s.Comments().New(t2, u2, "comment4 日本 Доброе утро", nil)
 	if err != nil {
 		t.Fatalf("failed to create a comment: %s", err)
 	}
@@ -77,7 +77,19 @@ func TestComment(t *testing.T) {
 		t.Fatalf("bad comment content: %s", comment1.Content)
 	}
 
-	err = s.Comments().SetContent(c1, "new content")
+	if comment1.EditCount != 0 {
+		t.Fatalf("bad comment edit count: %d", comment1.EditCount)
+	}
+
+	revisions, err := s.Comments().GetRevisions(c1)
+	if err != nil {
+		t.Fatalf("failed to get comment revisions: %s", err)
+	}
+	if len(revisions) != 0 {
+		t.Fatalf("bad revisions len: %d", len(revisions))
+	}
+
+	err = s.Comments().SetContent(c1, u2, "new content")
 	if err != nil {
 		t.Fatalf("failed to SetContent: %s", err)
 	}
@@ -89,6 +101,39 @@ func TestComment(t *testing.T) {
 	if comment1.Content != "new content" {
 		t.Fatalf("bad comment content: %s", comment1.Content)
 	}
+	if comment1.EditCount != 1 {
+		t.Fatalf("bad comment edit count: %d", comment1.EditCount)
+	}
+	if comment1.UpdatedAt.Before(comment1.CreatedAt) {
+		t.Fatalf("bad comment updatedAt: %v < %v", comment1.UpdatedAt, comment1.CreatedAt)
+	}
+
+	err = s.Comments().SetContent(c1, u1, "newer content")
+	if err != nil {
+		t.Fatalf("failed to SetContent: %s", err)
+	}
+
+	revisions, err = s.Comments().GetRevisions(c1)
+	if err != nil {
+		t.Fatalf("failed to get comment revisions: %s", err)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("bad revisions len: %d", len(revisions))
+	}
+	wantRevisions := []struct {
+		editorID int64
+		content  string
+	}{
+		{u1, "comment1"},
+		{u2, "new content"},
+		{u1, "newer content"},
+	}
+	for i, want := range wantRevisions {
+		got := revisions[i]
+		if got.CommentID != c1 || got.EditorID != want.editorID || got.Content != want.content {
+			t.Fatalf("bad revision %d: got (%d, %d, %q) want (%d, %d, %q)", i, got.CommentID, got.EditorID, got.Content, c1, want.editorID, want.content)
+		}
+	}
 
 	comments, count, err := s.Comments().GetByTopic(c2, 0, 10)
 	if err != nil {
@@ -0,0 +1,123 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type identityStore struct {
+	db *sql.DB
+}
+
+// New links a new identity to the user.
+// It returns ErrConflict if the identity belongs to any user.
+func (s *identityStore) New(userID int64, authService, authID string) (int64, error) {
+	res, err := s.db.Exec(
+		`insert into identities(user_id, auth_service, auth_id, created_at) values(?, ?, ?, ?)`,
+		userID, authService, authID, time.Now(),
+	)
+	if isUniqueConstraintError(err) {
+		return 0, store.ErrConflict
+	}
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// GetByUser returns the identities of the user ordered by creation time.
+func (s *identityStore) GetByUser(userID int64) ([]*store.Identity, error) {
+	rows, err := s.db.Query(
+		`select id, user_id, auth_service, auth_id, created_at, display_name, picture from identities where user_id=? order by id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []*store.Identity{}
+	for rows.Next() {
+		i := new(store.Identity)
+		err := rows.Scan(&i.ID, &i.UserID, &i.AuthService, &i.AuthID, &i.CreatedAt, &i.DisplayName, &i.Picture)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, i)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+// SetProfile updates the profile data of the identity.
+func (s *identityStore) SetProfile(authService, authID, displayName, picture string) error {
+	_, err := s.db.Exec(
+		`update identities set display_name=?, picture=? where auth_service=? and auth_id=?`,
+		displayName, picture, authService, authID,
+	)
+	return err
+}
+
+// Delete unlinks the identity from the user. It returns ErrNotFound if the user
+// has no such identity and ErrConflict if it is the last identity of the user.
+func (s *identityStore) Delete(userID int64, id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var authService string
+	err = tx.QueryRow(`select auth_service from identities where id=? and user_id=?`, id, userID).Scan(&authService)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return store.ErrNotFound
+		}
+		return err
+	}
+
+	// Lock the user identities so that concurrent requests cannot unlink all of them.
+	var count int
+	err = tx.QueryRow(`select count(*) from (select id from identities where user_id=? for update) t`, userID).Scan(&count)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if count <= 1 {
+		tx.Rollback()
+		return store.ErrConflict
+	}
+
+	_, err = tx.Exec(`delete from identities where id=?`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if authService == store.LocalAuthService {
+		_, err = tx.Exec(`delete from auth_tokens where user_id=?`, userID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		_, err = tx.Exec(`delete from local_accounts where user_id=?`, userID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package mysql
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type localAccountStore struct {
+	db *sql.DB
+}
+
+// New creates a new user with the local auth service and its account.
+// It returns ErrConflict if the email is already taken.
+func (s *localAccountStore) New(email, passwordHash string) (int64, error) {
+	email = strings.ToLower(email)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+
+	res, err := tx.Exec(
+		`insert into users(created_at, auth_service, auth_id) values(?, ?, ?)`,
+		now, store.LocalAuthService, email,
+	)
+	if err != nil {
+		tx.Rollback()
+		if isUniqueConstraintError(err) {
+			return 0, store.ErrConflict
+		}
+		return 0, err
+	}
+
+	userID, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	_, err = tx.Exec(
+		`insert into local_accounts(user_id, email, password_hash, created_at) values(?, ?, ?, ?)`,
+		userID, email, passwordHash, now,
+	)
+	if err != nil {
+		tx.Rollback()
+		if isUniqueConstraintError(err) {
+			return 0, store.ErrConflict
+		}
+		return 0, err
+	}
+
+	_, err = tx.Exec(
+		`insert into identities(user_id, auth_service, auth_id, created_at) values(?, ?, ?, ?)`,
+		userID, store.LocalAuthService, email, now,
+	)
+	if err != nil {
+		tx.Rollback()
+		if isUniqueConstraintError(err) {
+			return 0, store.ErrConflict
+		}
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+const selectFromLocalAccounts = `select user_id, email, password_hash, verified, created_at from local_accounts`
+
+func (s *localAccountStore) scanLocalAccount(scanner scanner) (*store.LocalAccount, error) {
+	a := new(store.LocalAccount)
+	err := scanner.Scan(&a.UserID, &a.Email, &a.PasswordHash, &a.Verified, &a.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Get finds an account by user ID.
+func (s *localAccountStore) Get(userID int64) (*store.LocalAccount, error) {
+	row := s.db.QueryRow(selectFromLocalAccounts+` where user_id=?`, userID)
+	return s.scanLocalAccount(row)
+}
+
+// GetByEmail finds an account by email.
+func (s *localAccountStore) GetByEmail(email string) (*store.LocalAccount, error) {
+	row := s.db.QueryRow(selectFromLocalAccounts+` where email=?`, strings.ToLower(email))
+	return s.scanLocalAccount(row)
+}
+
+// SetPasswordHash updates the account password hash.
+func (s *localAccountStore) SetPasswordHash(userID int64, passwordHash string) error {
+	res, err := s.db.Exec(`update local_accounts set password_hash=? where user_id=?`, passwordHash, userID)
+	if err != nil {
+		return err
+	}
+	return checkLocalAffected(res)
+}
+
+// SetVerified marks the account email as verified.
+func (s *localAccountStore) SetVerified(userID int64) error {
+	res, err := s.db.Exec(`update local_accounts set verified=? where user_id=?`, true, userID)
+	if err != nil {
+		return err
+	}
+	return checkLocalAffected(res)
+}
+
+type authTokenStore struct {
+	db *sql.DB
+}
+
+// New creates a new auth token. The expired tokens of the user are removed.
+// It returns ErrConflict if a token with the same hash exists.
+func (s *authTokenStore) New(userID int64, purpose, tokenHash string, expiresAt time.Time) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+
+	_, err = tx.Exec(`delete from auth_tokens where user_id=? and expires_at<=?`, userID, now)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	res, err := tx.Exec(
+		`insert into auth_tokens(user_id, purpose, token_hash, created_at, expires_at) values(?, ?, ?, ?, ?)`,
+		userID, purpose, tokenHash, now, expiresAt,
+	)
+	if err != nil {
+		tx.Rollback()
+		if isUniqueConstraintError(err) {
+			return 0, store.ErrConflict
+		}
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Use deletes the unexpired token with the given purpose and hash and returns it.
+// It returns ErrNotFound if there is no such token.
+func (s *authTokenStore) Use(purpose, tokenHash string) (*store.AuthToken, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	t := new(store.AuthToken)
+	err = tx.QueryRow(
+		`select id, user_id, purpose, token_hash, created_at, expires_at from auth_tokens where purpose=? and token_hash=? and expires_at>?`,
+		purpose, tokenHash, time.Now(),
+	).Scan(&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.CreatedAt, &t.ExpiresAt)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+
+	res, err := tx.Exec(`delete from auth_tokens where id=?`, t.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = checkLocalAffected(res)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// DeleteByUser deletes all the tokens of a user with the given purpose.
+func (s *authTokenStore) DeleteByUser(userID int64, purpose string) error {
+	_, err := s.db.Exec(`delete from auth_tokens where user_id=? and purpose=?`, userID, purpose)
+	return err
+}
+
+func checkLocalAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
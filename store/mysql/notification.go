@@ -0,0 +1,95 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type notificationStore struct {
+	db *sql.DB
+}
+
+// visibleNotifications selects the notifications about the comments
+// and topics that are not deleted.
+const visibleNotifications = `
+	from notifications n
+	join comments c on c.id=n.comment_id
+	join topics t on t.id=n.topic_id
+	where c.deleted=false and t.deleted=false
+`
+
+// GetByUser returns the user notifications, latest first.
+func (s *notificationStore) GetByUser(userID int64, unreadOnly bool, offset, limit int) ([]*store.Notification, int, error) {
+	cond := ` and n.user_id=?`
+	if unreadOnly {
+		cond += ` and n.is_read=false`
+	}
+
+	var count int
+	err := s.db.QueryRow(`select count(*)`+visibleNotifications+cond, userID).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if limit <= 0 || offset > count {
+		return []*store.Notification{}, count, nil
+	}
+
+	rows, err := s.db.Query(
+		`select n.id, n.user_id, n.type, n.topic_id, t.title, n.comment_id, n.actor_id, n.is_read, n.created_at`+
+			visibleNotifications+cond+` order by n.id desc limit ? offset ?`,
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	notifications := []*store.Notification{}
+	for rows.Next() {
+		n := new(store.Notification)
+		err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.TopicID, &n.TopicTitle, &n.CommentID, &n.ActorID, &n.Read, &n.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, n)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, count, nil
+}
+
+// CountUnread returns the number of the unread user notifications.
+func (s *notificationStore) CountUnread(userID int64) (int, error) {
+	var count int
+	err := s.db.QueryRow(
+		`select count(*)`+visibleNotifications+` and n.user_id=? and n.is_read=false`,
+		userID,
+	).Scan(&count)
+	return count, err
+}
+
+// MarkRead marks the user notification as read.
+// It returns ErrNotFound if the user has no such notification.
+func (s *notificationStore) MarkRead(userID int64, id int64) error {
+	var n int
+	err := s.db.QueryRow(`select count(*) from notifications where id=? and user_id=?`, id, userID).Scan(&n)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+
+	_, err = s.db.Exec(`update notifications set is_read=true where id=?`, id)
+	return err
+}
+
+// MarkAllRead marks all the user notifications as read.
+func (s *notificationStore) MarkAllRead(userID int64) error {
+	_, err := s.db.Exec(`update notifications set is_read=true where user_id=? and is_read=false`, userID)
+	return err
+}
@@ -0,0 +1,91 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type reactionStore struct {
+	db *sql.DB
+}
+
+// Add adds a user reaction to a comment.
+// It returns ErrConflict if the user has already reacted with the same emoji.
+func (s *reactionStore) Add(commentID int64, userID int64, emoji string) error {
+	_, err := s.db.Exec(
+		`insert into reactions(comment_id, user_id, emoji, created_at) values (?, ?, ?, ?)`,
+		commentID, userID, emoji, time.Now(),
+	)
+	if isUniqueConstraintError(err) {
+		return store.ErrConflict
+	}
+	return err
+}
+
+// Remove removes a user reaction from a comment.
+// It returns ErrNotFound if there is no such reaction.
+func (s *reactionStore) Remove(commentID int64, userID int64, emoji string) error {
+	res, err := s.db.Exec(
+		`delete from reactions where comment_id=? and user_id=? and emoji=?`,
+		commentID, userID, emoji,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetCounts returns the reaction counts of the given comments, grouped by emoji.
+// The Reacted flag is set for the reactions of the given user.
+func (s *reactionStore) GetCounts(commentIDs []int64, userID int64) (map[int64][]*store.ReactionCount, error) {
+	counts := make(map[int64][]*store.ReactionCount)
+	if len(commentIDs) == 0 {
+		return counts, nil
+	}
+
+	params := []interface{}{userID}
+	for _, id := range commentIDs {
+		params = append(params, id)
+	}
+
+	rows, err := s.db.Query(
+		`
+			select comment_id, emoji, count(*), max(user_id=?)
+			from reactions
+			where comment_id in (`+placeholders(len(commentIDs))+`)
+			group by comment_id, emoji
+			order by comment_id, emoji
+		`,
+		params...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var commentID int64
+		rc := new(store.ReactionCount)
+		err := rows.Scan(&commentID, &rc.Emoji, &rc.Count, &rc.Reacted)
+		if err != nil {
+			return nil, err
+		}
+		counts[commentID] = append(counts[commentID], rc)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,192 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type reportStore struct {
+	db *sql.DB
+}
+
+// New creates a new open report. It returns ErrConflict
+// if the reporter already has an open report about the same content.
+func (s *reportStore) New(reporterID int64, targetType string, targetID int64, authorID int64, reason string) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	var exists bool
+	err = tx.QueryRow(
+		`select exists(select 1 from reports where reporter_id=? and target_type=? and target_id=? and status='open')`,
+		reporterID, targetType, targetID,
+	).Scan(&exists)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if exists {
+		tx.Rollback()
+		return 0, store.ErrConflict
+	}
+
+	res, err := tx.Exec(
+		`
+			insert into reports(reporter_id, target_type, target_id, author_id, reason, status, created_at)
+			values(?, ?, ?, ?, ?, 'open', ?)
+		`,
+		reporterID, targetType, targetID, authorID, reason, time.Now(),
+	)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+const selectFromReports = `
+	select
+		id,
+		reporter_id,
+		target_type,
+		target_id,
+		author_id,
+		reason,
+		status,
+		created_at,
+		coalesce(resolved_by, 0) as resolved_by,
+		resolved_at
+	from reports
+`
+
+func (s *reportStore) scanReport(scanner scanner) (*store.Report, error) {
+	r := new(store.Report)
+	var resolvedAt sql.NullTime
+	err := scanner.Scan(&r.ID, &r.ReporterID, &r.TargetType, &r.TargetID, &r.AuthorID, &r.Reason, &r.Status, &r.CreatedAt, &r.ResolvedBy, &resolvedAt)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resolvedAt.Valid {
+		r.ResolvedAt = &resolvedAt.Time
+	}
+	return r, nil
+}
+
+// Get finds a report by ID.
+func (s *reportStore) Get(id int64) (*store.Report, error) {
+	row := s.db.QueryRow(selectFromReports+` where id=?`, id)
+	return s.scanReport(row)
+}
+
+// GetByStatus returns a limited number of reports with the given status, oldest first,
+// and a total count of such reports. Empty status means all the reports.
+func (s *reportStore) GetByStatus(status string, offset, limit int) ([]*store.Report, int, error) {
+	cond := `true`
+	var args []interface{}
+	if status != "" {
+		cond = `status=?`
+		args = append(args, status)
+	}
+
+	var count int
+	err := s.db.QueryRow(`select count(*) from reports where `+cond, args...).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if limit <= 0 || offset > count {
+		return []*store.Report{}, count, nil
+	}
+
+	args = append(args, limit, offset)
+	rows, err := s.db.Query(
+		selectFromReports+` where `+cond+` order by created_at, id limit ? offset ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	reports := []*store.Report{}
+	for rows.Next() {
+		report, err := s.scanReport(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		reports = append(reports, report)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return reports, count, nil
+}
+
+// Resolve closes an open report with the given resolution status
+// and records the resolving admin and time. The other open reports
+// about the same content are resolved the same way.
+// It returns ErrConflict if the report is already resolved.
+func (s *reportStore) Resolve(id int64, status string, resolverID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var targetType, currentStatus string
+	var targetID int64
+	err = tx.QueryRow(
+		`select target_type, target_id, status from reports where id=? for update`,
+		id,
+	).Scan(&targetType, &targetID, &currentStatus)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return store.ErrNotFound
+		}
+		return err
+	}
+	if currentStatus != store.ReportStatusOpen {
+		tx.Rollback()
+		return store.ErrConflict
+	}
+
+	_, err = tx.Exec(
+		`
+			update reports set status=?, resolved_by=?, resolved_at=?
+			where target_type=? and target_id=? and status='open'
+		`,
+		status, resolverID, time.Now(), targetType, targetID,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
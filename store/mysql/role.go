@@ -0,0 +1,175 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type roleStore struct {
+	db *sql.DB
+}
+
+// Grant grants the role to the user.
+// It returns ErrConflict if the user already has the role.
+func (s *roleStore) Grant(userID int64, role string) error {
+	_, err := s.db.Exec(
+		`insert into user_roles(user_id, role, created_at) values(?, ?, ?)`,
+		userID, role, time.Now(),
+	)
+	if isUniqueConstraintError(err) {
+		return store.ErrConflict
+	}
+	return err
+}
+
+// Revoke revokes the role from the user.
+// It returns ErrNotFound if the user does not have the role.
+func (s *roleStore) Revoke(userID int64, role string) error {
+	res, err := s.db.Exec(`delete from user_roles where user_id=? and role=?`, userID, role)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// GetByUser returns the roles of the user in alphabetical order.
+func (s *roleStore) GetByUser(userID int64) ([]string, error) {
+	rows, err := s.db.Query(`select role from user_roles where user_id=? order by role`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []string{}
+	for rows.Next() {
+		var role string
+		err := rows.Scan(&role)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// GetUsers returns the IDs of the users that have the role in ascending order.
+func (s *roleStore) GetUsers(role string) ([]int64, error) {
+	rows, err := s.db.Query(`select user_id from user_roles where role=? order by user_id`, role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	userIDs := []int64{}
+	for rows.Next() {
+		var userID int64
+		err := rows.Scan(&userID)
+		if err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return userIDs, nil
+}
+
+// GrantPermission grants the permission to the role.
+// It returns ErrConflict if the role already grants the permission.
+func (s *roleStore) GrantPermission(role, permission string) error {
+	_, err := s.db.Exec(
+		`insert into role_permissions(role, permission, created_at) values(?, ?, ?)`,
+		role, permission, time.Now(),
+	)
+	if isUniqueConstraintError(err) {
+		return store.ErrConflict
+	}
+	return err
+}
+
+// RevokePermission revokes the permission from the role.
+// It returns ErrNotFound if the role does not grant the permission.
+func (s *roleStore) RevokePermission(role, permission string) error {
+	res, err := s.db.Exec(`delete from role_permissions where role=? and permission=?`, role, permission)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// GetPermissions returns the permissions granted by any of the roles in alphabetical order.
+func (s *roleStore) GetPermissions(roles []string) ([]string, error) {
+	permissions := []string{}
+	if len(roles) == 0 {
+		return permissions, nil
+	}
+
+	args := make([]interface{}, len(roles))
+	for i, role := range roles {
+		args[i] = role
+	}
+	rows, err := s.db.Query(
+		`select distinct permission from role_permissions where role in (`+placeholders(len(roles))+`) order by permission`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var permission string
+		err := rows.Scan(&permission)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// HasPermission checks if any of the user roles grants the permission.
+func (s *roleStore) HasPermission(userID int64, permission string) (bool, error) {
+	var count int
+	err := s.db.QueryRow(
+		`
+			select count(*)
+			from user_roles ur
+			join role_permissions rp on rp.role=ur.role
+			where ur.user_id=? and rp.permission=?
+		`,
+		userID, permission,
+	).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -1,54 +1,554 @@
 package mysql
 
-var migrate = []string{
-	`
-		create table if not exists users (
-			id            bigint            not null auto_increment,
-			name          varchar(50)       default null,
-			created_at    datetime(6)       not null,
-			auth_service  varchar(50)       not null,
-			auth_id       varchar(50)       not null,
-			blocked       boolean           not null default false,
-			admin         boolean           not null default false,
-			avatar        varchar(50)       not null default '',
-
-			primary key (id),
-			unique index (name),
-			unique index (auth_service, auth_id)
-		) default charset = utf8mb4;
-	`,
-	`
-		create table if not exists topics (
-			id               bigint        not null auto_increment,
-			author_id        bigint        not null references users(id),
-			title            varchar(200)  not null,
-			created_at       datetime(6)   not null,
-			last_comment_at  datetime(6)   not null,
-			deleted          boolean       not null default false,
-			comment_count    int           not null default 0,
-
-			primary key (id),
-			index (last_comment_at)
-		) default charset = utf8mb4;
-	`,
-	`
-		create table if not exists comments (
-			id          bigint       not null auto_increment,
-			topic_id    bigint       not null references topics(id),
-			author_id   bigint       not null references users(id),
-			content     text         not null,
-			created_at  datetime(6)  not null,
-			deleted     boolean      not null default false,
-
-			primary key (id),
-			index (topic_id),
-			index (created_at)
-		) default charset = utf8mb4;
-	`,
+import (
+	"github.com/disintegration/bebop/store"
+)
+
+// migrations is the list of database schema migrations.
+// Never change a migration once it has been released: add a new one instead.
+var migrations = []*store.Migration{
+	{
+		Version: 1,
+		Name:    "initial schema",
+		Up: []string{
+			`
+				create table if not exists users (
+					id            bigint            not null auto_increment,
+					name          varchar(50)       default null,
+					created_at    datetime(6)       not null,
+					auth_service  varchar(50)       not null,
+					auth_id       varchar(50)       not null,
+					blocked       boolean           not null default false,
+					admin         boolean           not null default false,
+					avatar        varchar(50)       not null default '',
+
+					primary key (id),
+					unique index (name),
+					unique index (auth_service, auth_id)
+				) default charset = utf8mb4
+			`,
+			`
+				create table if not exists topics (
+					id               bigint        not null auto_increment,
+					author_id        bigint        not null references users(id),
+					title            varchar(200)  not null,
+					created_at       datetime(6)   not null,
+					last_comment_at  datetime(6)   not null,
+					deleted          boolean       not null default false,
+					comment_count    int           not null default 0,
+
+					primary key (id),
+					index (last_comment_at)
+				) default charset = utf8mb4
+			`,
+			`
+				create table if not exists comments (
+					id          bigint       not null auto_increment,
+					topic_id    bigint       not null references topics(id),
+					author_id   bigint       not null references users(id),
+					content     text         not null,
+					created_at  datetime(6)  not null,
+					deleted     boolean      not null default false,
+
+					primary key (id),
+					index (topic_id),
+					index (created_at)
+				) default charset = utf8mb4
+			`,
+		},
+		Down: []string{
+			`drop table if exists comments`,
+			`drop table if exists topics`,
+			`drop table if exists users`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "edit history",
+		Up: []string{
+			`alter table comments add column updated_at datetime(6) null`,
+			`update comments set updated_at = created_at`,
+			`alter table comments modify column updated_at datetime(6) not null`,
+			`alter table comments add column edit_count int not null default 0`,
+			`
+				create table if not exists topic_revisions (
+					id          bigint        not null auto_increment,
+					topic_id    bigint        not null references topics(id),
+					editor_id   bigint        not null references users(id),
+					title       varchar(200)  not null,
+					created_at  datetime(6)   not null,
+
+					primary key (id),
+					index (topic_id)
+				) default charset = utf8mb4
+			`,
+			`
+				create table if not exists comment_revisions (
+					id          bigint       not null auto_increment,
+					comment_id  bigint       not null references comments(id),
+					editor_id   bigint       not null references users(id),
+					content     text         not null,
+					created_at  datetime(6)  not null,
+
+					primary key (id),
+					index (comment_id)
+				) default charset = utf8mb4
+			`,
+		},
+		Down: []string{
+			`drop table if exists comment_revisions`,
+			`drop table if exists topic_revisions`,
+			`alter table comments drop column edit_count`,
+			`alter table comments drop column updated_at`,
+		},
+	},
+	{
+		Version: 3,
+		Name:    "full-text search",
+		Up: []string{
+			`alter table topics add fulltext index topics_title_search_idx (title)`,
+			`alter table comments add fulltext index comments_content_search_idx (content)`,
+		},
+		Down: []string{
+			`alter table comments drop index comments_content_search_idx`,
+			`alter table topics drop index topics_title_search_idx`,
+		},
+	},
+	{
+		Version: 4,
+		Name:    "categories",
+		Up: []string{
+			`
+				create table if not exists categories (
+					id           bigint         not null auto_increment,
+					slug         varchar(50)    not null,
+					name         varchar(200)   not null,
+					description  varchar(2000)  not null default '',
+					sort_order   int            not null default 0,
+					admin_only   boolean        not null default false,
+
+					primary key (id),
+					unique index (slug)
+				) default charset = utf8mb4
+			`,
+			`alter table topics add column category_id bigint null references categories(id)`,
+			`alter table topics add index topics_category_id_idx (category_id, last_comment_at)`,
+		},
+		Down: []string{
+			`alter table topics drop index topics_category_id_idx`,
+			`alter table topics drop column category_id`,
+			`drop table if exists categories`,
+		},
+	},
+	{
+		Version: 5,
+		Name:    "pinned and locked topics",
+		Up: []string{
+			`alter table topics add column pinned boolean not null default false`,
+			`alter table topics add column locked boolean not null default false`,
+			`alter table topics add index topics_pinned_idx (pinned, last_comment_at)`,
+		},
+		Down: []string{
+			`alter table topics drop index topics_pinned_idx`,
+			`alter table topics drop column locked`,
+			`alter table topics drop column pinned`,
+		},
+	},
+	{
+		Version: 6,
+		Name:    "comment pagination index",
+		Up: []string{
+			`alter table comments add index comments_topic_id_created_at_idx (topic_id, created_at, id)`,
+		},
+		Down: []string{
+			`alter table comments drop index comments_topic_id_created_at_idx`,
+		},
+	},
+	{
+		Version: 7,
+		Name:    "reactions",
+		Up: []string{
+			`
+				create table if not exists reactions (
+					comment_id  bigint       not null,
+					user_id     bigint       not null,
+					emoji       varchar(32)  character set utf8mb4 collate utf8mb4_bin not null,
+					created_at  datetime(6)  not null,
+
+					primary key (comment_id, user_id, emoji)
+				) default charset = utf8mb4
+			`,
+		},
+		Down: []string{
+			`drop table if exists reactions`,
+		},
+	},
+	{
+		Version: 8,
+		Name:    "reports",
+		Up: []string{
+			`
+				create table if not exists reports (
+					id           bigint         not null auto_increment,
+					reporter_id  bigint         not null,
+					target_type  varchar(20)    not null,
+					target_id    bigint         not null,
+					author_id    bigint         not null,
+					reason       varchar(1000)  not null,
+					status       varchar(20)    not null default 'open',
+					created_at   datetime(6)    not null,
+					resolved_by  bigint         null,
+					resolved_at  datetime(6)    null,
+
+					primary key (id),
+					index (status, created_at),
+					index (target_type, target_id)
+				) default charset = utf8mb4
+			`,
+		},
+		Down: []string{
+			`drop table if exists reports`,
+		},
+	},
+	{
+		Version: 9,
+		Name:    "audit log",
+		Up: []string{
+			`
+				create table if not exists audit_log (
+					id           bigint        not null auto_increment,
+					actor_id     bigint        not null,
+					action       varchar(50)   not null,
+					target_type  varchar(20)   not null,
+					target_id    bigint        not null,
+					before_json  mediumtext    not null,
+					after_json   mediumtext    not null,
+					ip           varchar(100)  not null,
+					created_at   datetime(6)   not null,
+
+					primary key (id),
+					index (created_at),
+					index (actor_id),
+					index (target_type, target_id)
+				) default charset = utf8mb4
+			`,
+		},
+		Down: []string{
+			`drop table if exists audit_log`,
+		},
+	},
+	{
+		Version: 10,
+		Name:    "sessions",
+		Up: []string{
+			`alter table users add column tokens_valid_after datetime(6) null`,
+			`
+				create table if not exists sessions (
+					id          bigint       not null auto_increment,
+					user_id     bigint       not null references users(id),
+					token_hash  varchar(64)  not null,
+					created_at  datetime(6)  not null,
+					expires_at  datetime(6)  not null,
+
+					primary key (id),
+					unique index (token_hash),
+					index (user_id)
+				) default charset = utf8mb4
+			`,
+		},
+		Down: []string{
+			`drop table if exists sessions`,
+			`alter table users drop column tokens_valid_after`,
+		},
+	},
+	{
+		Version: 11,
+		Name:    "local accounts",
+		Up: []string{
+			// Emails are used as the auth IDs of the local accounts.
+			`alter table users modify auth_id varchar(255) not null`,
+			`
+				create table if not exists local_accounts (
+					user_id        bigint        not null references users(id),
+					email          varchar(255)  not null,
+					password_hash  varchar(255)  not null,
+					verified       boolean       not null default false,
+					created_at     datetime(6)   not null,
+
+					primary key (user_id),
+					unique index (email)
+				) default charset = utf8mb4
+			`,
+			`
+				create table if not exists auth_tokens (
+					id          bigint       not null auto_increment,
+					user_id     bigint       not null references users(id),
+					purpose     varchar(50)  not null,
+					token_hash  varchar(64)  not null,
+					created_at  datetime(6)  not null,
+					expires_at  datetime(6)  not null,
+
+					primary key (id),
+					unique index (token_hash),
+					index (user_id)
+				) default charset = utf8mb4
+			`,
+		},
+		Down: []string{
+			`drop table if exists auth_tokens`,
+			`drop table if exists local_accounts`,
+			`alter table users modify auth_id varchar(50) not null`,
+		},
+	},
+	{
+		Version: 12,
+		Name:    "identities",
+		Up: []string{
+			`
+				create table if not exists identities (
+					id            bigint        not null auto_increment,
+					user_id       bigint        not null references users(id),
+					auth_service  varchar(50)   not null,
+					auth_id       varchar(255)  not null,
+					created_at    datetime(6)   not null,
+
+					primary key (id),
+					unique index (auth_service, auth_id),
+					index (user_id)
+				) default charset = utf8mb4
+			`,
+			`
+				insert into identities(user_id, auth_service, auth_id, created_at)
+				select id, auth_service, auth_id, created_at from users
+			`,
+			// The users keep the identity they signed up with, but it can be unlinked
+			// and then used by another user.
+			`alter table users drop index auth_service`,
+		},
+		Down: []string{
+			`alter table users add unique index auth_service (auth_service, auth_id)`,
+			`drop table if exists identities`,
+		},
+	},
+	{
+		Version: 13,
+		Name:    "identity profiles",
+		Up: []string{
+			`alter table identities add column display_name varchar(255) not null default ''`,
+			`alter table identities add column picture varchar(2000) not null default ''`,
+		},
+		Down: []string{
+			`alter table identities drop column picture`,
+			`alter table identities drop column display_name`,
+		},
+	},
+	{
+		Version: 14,
+		Name:    "user roles",
+		Up: []string{
+			`
+				create table if not exists user_roles (
+					user_id     bigint       not null references users(id),
+					role        varchar(50)  not null,
+					created_at  datetime(6)  not null,
+
+					primary key (user_id, role),
+					index (role)
+				) default charset = utf8mb4
+			`,
+			`
+				insert into user_roles(user_id, role, created_at)
+				select id, 'admin', now(6) from users where admin=true
+			`,
+			`alter table users drop column admin`,
+		},
+		Down: []string{
+			`alter table users add column admin boolean not null default false`,
+			`update users set admin=true where id in (select user_id from user_roles where role='admin')`,
+			`drop table if exists user_roles`,
+		},
+	},
+	{
+		Version: 15,
+		Name:    "notifications",
+		Up: []string{
+			`
+				create table if not exists notifications (
+					id          bigint       not null auto_increment,
+					user_id     bigint       not null references users(id),
+					type        varchar(20)  not null,
+					topic_id    bigint       not null references topics(id),
+					comment_id  bigint       not null references comments(id),
+					actor_id    bigint       not null references users(id),
+					is_read     boolean      not null default false,
+					created_at  datetime(6)  not null,
+
+					primary key (id),
+					index (user_id, id)
+				) default charset = utf8mb4
+			`,
+		},
+		Down: []string{
+			`drop table if exists notifications`,
+		},
+	},
+	{
+		Version: 16,
+		Name:    "topic watches and email digests",
+		Up: []string{
+			`
+				create table if not exists topic_watches (
+					user_id     bigint       not null references users(id),
+					topic_id    bigint       not null references topics(id),
+					created_at  datetime(6)  not null,
+
+					primary key (user_id, topic_id),
+					index (topic_id)
+				) default charset = utf8mb4
+			`,
+			`
+				create table if not exists digest_settings (
+					user_id       bigint       not null references users(id),
+					mode          varchar(20)  not null,
+					last_sent_at  datetime(6)  default null,
+
+					primary key (user_id)
+				) default charset = utf8mb4
+			`,
+			`
+				create table if not exists digest_queue (
+					user_id     bigint  not null references users(id),
+					comment_id  bigint  not null references comments(id),
+
+					primary key (user_id, comment_id)
+				) default charset = utf8mb4
+			`,
+		},
+		Down: []string{
+			`drop table if exists digest_queue`,
+			`drop table if exists digest_settings`,
+			`drop table if exists topic_watches`,
+		},
+	},
+	{
+		Version: 17,
+		Name:    "webhooks",
+		Up: []string{
+			`
+				create table if not exists webhooks (
+					id          bigint         not null auto_increment,
+					url         varchar(2000)  not null,
+					secret      varchar(200)   not null,
+					events      varchar(500)   not null,
+					created_at  datetime(6)    not null,
+
+					primary key (id)
+				) default charset = utf8mb4
+			`,
+			`
+				create table if not exists webhook_deliveries (
+					id               bigint        not null auto_increment,
+					webhook_id       bigint        not null references webhooks(id),
+					event            varchar(50)   not null,
+					payload          mediumtext    not null,
+					status           varchar(20)   not null,
+					attempts         int           not null default 0,
+					response_code    int           not null default 0,
+					error            text          not null,
+					next_attempt_at  datetime(6)   not null,
+					created_at       datetime(6)   not null,
+					updated_at       datetime(6)   not null,
+
+					primary key (id),
+					index (status, next_attempt_at),
+					index (webhook_id, id)
+				) default charset = utf8mb4
+			`,
+		},
+		Down: []string{
+			`drop table if exists webhook_deliveries`,
+			`drop table if exists webhooks`,
+		},
+	},
+	{
+		Version: 18,
+		Name:    "webhook delivery locks",
+		Up: []string{
+			`alter table webhook_deliveries add column locked_until datetime(6) null`,
+		},
+		Down: []string{
+			`alter table webhook_deliveries drop column locked_until`,
+		},
+	},
+	{
+		Version: 19,
+		Name:    "role permissions",
+		Up: []string{
+			`
+				create table if not exists role_permissions (
+					role        varchar(50)  not null,
+					permission  varchar(50)  not null,
+					created_at  datetime(6)  not null,
+
+					primary key (role, permission)
+				) default charset = utf8mb4
+			`,
+			`
+				insert into role_permissions(role, permission, created_at) values
+				('admin', 'topic.edit', now(6)),
+				('admin', 'topic.delete', now(6)),
+				('admin', 'topic.move', now(6)),
+				('admin', 'topic.pin', now(6)),
+				('admin', 'topic.lock', now(6)),
+				('admin', 'topic.post_admin_only', now(6)),
+				('admin', 'comment.edit', now(6)),
+				('admin', 'comment.delete', now(6)),
+				('admin', 'comment.locked_topic', now(6)),
+				('admin', 'user.view', now(6)),
+				('admin', 'user.rename', now(6)),
+				('admin', 'user.avatar', now(6)),
+				('admin', 'user.block', now(6)),
+				('admin', 'category.manage', now(6)),
+				('admin', 'report.manage', now(6)),
+				('admin', 'audit.read', now(6)),
+				('admin', 'webhook.manage', now(6)),
+				('moderator', 'topic.delete', now(6)),
+				('moderator', 'topic.move', now(6)),
+				('moderator', 'topic.pin', now(6)),
+				('moderator', 'topic.lock', now(6)),
+				('moderator', 'comment.delete', now(6)),
+				('moderator', 'comment.locked_topic', now(6)),
+				('moderator', 'user.view', now(6)),
+				('moderator', 'report.manage', now(6))
+			`,
+		},
+		Down: []string{
+			`drop table if exists role_permissions`,
+		},
+	},
 }
 
 var drop = []string{
 	`drop table if exists users cascade`,
 	`drop table if exists topics cascade`,
 	`drop table if exists comments cascade`,
+	`drop table if exists topic_revisions cascade`,
+	`drop table if exists comment_revisions cascade`,
+	`drop table if exists categories cascade`,
+	`drop table if exists reactions cascade`,
+	`drop table if exists reports cascade`,
+	`drop table if exists audit_log cascade`,
+	`drop table if exists sessions cascade`,
+	`drop table if exists local_accounts cascade`,
+	`drop table if exists auth_tokens cascade`,
+	`drop table if exists identities cascade`,
+	`drop table if exists user_roles cascade`,
+	`drop table if exists notifications cascade`,
+	`drop table if exists topic_watches cascade`,
+	`drop table if exists digest_settings cascade`,
+	`drop table if exists digest_queue cascade`,
+	`drop table if exists webhooks cascade`,
+	`drop table if exists webhook_deliveries cascade`,
+	`drop table if exists role_permissions cascade`,
+	`drop table if exists schema_migrations cascade`,
 }
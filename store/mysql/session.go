@@ -0,0 +1,115 @@
+package mysql
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type sessionStore struct {
+	db *sql.DB
+}
+
+// New creates a new session. The expired sessions of the user are removed.
+// It returns ErrConflict if a session with the same token hash exists.
+func (s *sessionStore) New(userID int64, tokenHash string, expiresAt time.Time) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+
+	_, err = tx.Exec(`delete from sessions where user_id=? and expires_at<=?`, userID, now)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	res, err := tx.Exec(
+		`insert into sessions(user_id, token_hash, created_at, expires_at) values(?, ?, ?, ?)`,
+		userID, tokenHash, now, expiresAt,
+	)
+	if err != nil {
+		tx.Rollback()
+		if isUniqueConstraintError(err) {
+			return 0, store.ErrConflict
+		}
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+const selectFromSessions = `select id, user_id, token_hash, created_at, expires_at from sessions`
+
+func (s *sessionStore) scanSession(scanner scanner) (*store.Session, error) {
+	session := new(store.Session)
+	err := scanner.Scan(&session.ID, &session.UserID, &session.TokenHash, &session.CreatedAt, &session.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetByToken finds a session by the refresh token hash.
+func (s *sessionStore) GetByToken(tokenHash string) (*store.Session, error) {
+	row := s.db.QueryRow(selectFromSessions+` where token_hash=?`, tokenHash)
+	return s.scanSession(row)
+}
+
+// Rotate replaces the session token hash and expiration time.
+// It returns ErrNotFound if the current token hash does not match,
+// e.g. if the session has already been rotated by a concurrent request.
+func (s *sessionStore) Rotate(id int64, tokenHash, newTokenHash string, expiresAt time.Time) error {
+	res, err := s.db.Exec(
+		`update sessions set token_hash=?, expires_at=? where id=? and token_hash=?`,
+		newTokenHash, expiresAt, id, tokenHash,
+	)
+	if err != nil {
+		return err
+	}
+	return checkSessionAffected(res)
+}
+
+// Delete deletes a session.
+func (s *sessionStore) Delete(id int64) error {
+	res, err := s.db.Exec(`delete from sessions where id=?`, id)
+	if err != nil {
+		return err
+	}
+	return checkSessionAffected(res)
+}
+
+// DeleteByUser deletes all the sessions of a user.
+func (s *sessionStore) DeleteByUser(userID int64) error {
+	_, err := s.db.Exec(`delete from sessions where user_id=?`, userID)
+	return err
+}
+
+func checkSessionAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
@@ -1,6 +1,11 @@
 package mysql
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/storetest"
+)
 
 const (
 	testAddress  = "127.0.0.1:3306"
@@ -10,7 +15,7 @@ const (
 )
 
 func getTestStore(t *testing.T) (*Store, func()) {
-	s, err := Connect(testAddress, testUsername, testPassword, testDatabase)
+	s, err := Connect(testAddress, testUsername, testPassword, testDatabase, store.MigrateAuto)
 	if err != nil {
 		t.Fatalf(
 			"failed to connect to the test mysql database: address=%q, username=%q, password=%q, database=%q: %s",
@@ -33,6 +38,12 @@ func getTestStore(t *testing.T) (*Store, func()) {
 	return s, teardown
 }
 
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (store.Store, func()) {
+		return getTestStore(t)
+	})
+}
+
 func TestPlaceholders(t *testing.T) {
 	testTable := map[int]string{
 		0:  "",
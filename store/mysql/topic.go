@@ -11,16 +11,16 @@ type topicStore struct {
 	db *sql.DB
 }
 
-// New creates a new topic.
-func (s *topicStore) New(authorID int64, title string) (int64, error) {
+// New creates a new topic. Zero categoryID means no category.
+func (s *topicStore) New(authorID int64, categoryID int64, title string) (int64, error) {
 	now := time.Now()
 
 	res, err := s.db.Exec(
 		`
-			insert into topics(author_id, title, created_at, last_comment_at)
-			values(?, ?, ?, ?)
+			insert into topics(author_id, category_id, title, created_at, last_comment_at)
+			values(?, nullif(?, 0), ?, ?, ?)
 		`,
-		authorID, title, now, now,
+		authorID, categoryID, title, now, now,
 	)
 	if err != nil {
 		return 0, err
@@ -29,11 +29,23 @@ func (s *topicStore) New(authorID int64, title string) (int64, error) {
 	return res.LastInsertId()
 }
 
-const selectFromTopics = `select id, author_id, title, created_at, last_comment_at, comment_count from topics`
+const selectFromTopics = `
+	select
+		id,
+		author_id,
+		coalesce(category_id, 0) as category_id,
+		title,
+		created_at,
+		last_comment_at,
+		comment_count,
+		pinned,
+		locked
+	from topics
+`
 
 func (s *topicStore) scanTopic(scanner scanner) (*store.Topic, error) {
 	t := new(store.Topic)
-	err := scanner.Scan(&t.ID, &t.AuthorID, &t.Title, &t.CreatedAt, &t.LastCommentAt, &t.CommentCount)
+	err := scanner.Scan(&t.ID, &t.AuthorID, &t.CategoryID, &t.Title, &t.CreatedAt, &t.LastCommentAt, &t.CommentCount, &t.Pinned, &t.Locked)
 	if err == sql.ErrNoRows {
 		return nil, store.ErrNotFound
 	}
@@ -50,6 +62,7 @@ func (s *topicStore) Get(id int64) (*store.Topic, error) {
 }
 
 // GetLatest returns a limited number of latest topics and a total topic count.
+// Pinned topics go first.
 func (s *topicStore) GetLatest(offset, limit int) ([]*store.Topic, int, error) {
 	var count int
 	err := s.db.QueryRow(`select count(*) from topics where deleted=false`).Scan(&count)
@@ -62,7 +75,7 @@ func (s *topicStore) GetLatest(offset, limit int) ([]*store.Topic, int, error) {
 	}
 
 	rows, err := s.db.Query(
-		selectFromTopics+` where deleted=false order by last_comment_at desc, id desc limit ? offset ?`,
+		selectFromTopics+` where deleted=false order by pinned desc, last_comment_at desc, id desc limit ? offset ?`,
 		limit,
 		offset,
 	)
@@ -86,9 +99,226 @@ func (s *topicStore) GetLatest(offset, limit int) ([]*store.Topic, int, error) {
 	return topics, count, nil
 }
 
-// SetTitle updates topic.Title value.
-func (s *topicStore) SetTitle(id int64, title string) error {
-	_, err := s.db.Exec(`update topics set title=? where id=?`, title, id)
+// GetByCategory returns a limited number of latest topics in a category and a total topic count.
+// Pinned topics go first.
+func (s *topicStore) GetByCategory(categoryID int64, offset, limit int) ([]*store.Topic, int, error) {
+	var count int
+	err := s.db.QueryRow(`select count(*) from topics where deleted=false and category_id=?`, categoryID).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if limit <= 0 || offset > count {
+		return []*store.Topic{}, count, nil
+	}
+
+	rows, err := s.db.Query(
+		selectFromTopics+` where deleted=false and category_id=? order by pinned desc, last_comment_at desc, id desc limit ? offset ?`,
+		categoryID,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	topics := []*store.Topic{}
+	for rows.Next() {
+		topic, err := s.scanTopic(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		topics = append(topics, topic)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return topics, count, nil
+}
+
+// GetLatestAfter returns a limited number of latest topics following the cursor.
+// Pinned topics go first.
+func (s *topicStore) GetLatestAfter(cursor *store.TopicCursor, limit int) ([]*store.Topic, error) {
+	return s.getAfter(`deleted=false`, nil, cursor, limit)
+}
+
+// GetByCategoryAfter returns a limited number of latest topics in a category following the cursor.
+// Pinned topics go first.
+func (s *topicStore) GetByCategoryAfter(categoryID int64, cursor *store.TopicCursor, limit int) ([]*store.Topic, error) {
+	return s.getAfter(`deleted=false and category_id=?`, []interface{}{categoryID}, cursor, limit)
+}
+
+// getAfter returns the topics matching the condition that follow the cursor position.
+func (s *topicStore) getAfter(cond string, args []interface{}, cursor *store.TopicCursor, limit int) ([]*store.Topic, error) {
+	if limit <= 0 {
+		return []*store.Topic{}, nil
+	}
+
+	if cursor != nil {
+		cond += ` and (pinned < ? or (pinned = ? and (last_comment_at < ? or (last_comment_at = ? and id < ?))))`
+		args = append(args, cursor.Pinned, cursor.Pinned, cursor.LastCommentAt, cursor.LastCommentAt, cursor.ID)
+	}
+	args = append(args, limit)
+
+	rows, err := s.db.Query(
+		selectFromTopics+` where `+cond+` order by pinned desc, last_comment_at desc, id desc limit ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	topics := []*store.Topic{}
+	for rows.Next() {
+		topic, err := s.scanTopic(rows)
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, topic)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return topics, nil
+}
+
+// Search finds topics by title using the full-text index.
+// All the query terms must be present. The results are ordered by relevance.
+func (s *topicStore) Search(query string, offset, limit int) ([]*store.Topic, int, error) {
+	const match = `deleted=false and match(title) against(? in boolean mode)`
+
+	query = booleanQuery(query)
+
+	var count int
+	err := s.db.QueryRow(`select count(*) from topics where `+match, query).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if limit <= 0 || offset > count {
+		return []*store.Topic{}, count, nil
+	}
+
+	rows, err := s.db.Query(
+		selectFromTopics+` where `+match+`
+			order by match(title) against(? in boolean mode) desc, last_comment_at desc, id desc
+			limit ? offset ?`,
+		query,
+		query,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	topics := []*store.Topic{}
+	for rows.Next() {
+		topic, err := s.scanTopic(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		topics = append(topics, topic)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return topics, count, nil
+}
+
+// GetRevisions returns the edit history of a topic title, oldest first.
+func (s *topicStore) GetRevisions(id int64) ([]*store.TopicRevision, error) {
+	rows, err := s.db.Query(
+		`select id, topic_id, editor_id, title, created_at from topic_revisions where topic_id=? order by created_at, id`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*store.TopicRevision{}
+	for rows.Next() {
+		r := new(store.TopicRevision)
+		err := rows.Scan(&r.ID, &r.TopicID, &r.EditorID, &r.Title, &r.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// SetTitle updates topic.Title value and saves the edit to the topic revisions.
+func (s *topicStore) SetTitle(id int64, editorID int64, title string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// Keep the original title as the first revision.
+	_, err = tx.Exec(
+		`
+		insert into topic_revisions(topic_id, editor_id, title, created_at)
+		select id, author_id, title, created_at from topics
+		where id=? and not exists (select 1 from topic_revisions where topic_id=?)
+		`,
+		id, id,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(
+		`insert into topic_revisions(topic_id, editor_id, title, created_at) values (?, ?, ?, ?)`,
+		id, editorID, title, time.Now(),
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`update topics set title=? where id=?`, title, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// SetCategory updates topic.CategoryID value. Zero categoryID means no category.
+func (s *topicStore) SetCategory(id int64, categoryID int64) error {
+	_, err := s.db.Exec(`update topics set category_id=nullif(?, 0) where id=?`, categoryID, id)
+	return err
+}
+
+// SetPinned updates topic.Pinned value.
+func (s *topicStore) SetPinned(id int64, pinned bool) error {
+	_, err := s.db.Exec(`update topics set pinned=? where id=?`, pinned, id)
+	return err
+}
+
+// SetLocked updates topic.Locked value.
+func (s *topicStore) SetLocked(id int64, locked bool) error {
+	_, err := s.db.Exec(`update topics set locked=? where id=?`, locked, id)
 	return err
 }
 
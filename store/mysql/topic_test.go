@@ -20,19 +20,19 @@ func TestTopic(t *testing.T) {
 		t.Fatalf("failed to create a user: %s", err)
 	}
 
-	id1, err := s.Topics().New(u1, "topic1")
+	id1, err := s.Topics().New(u1, 0, "topic1")
 	if err != nil {
 		t.Fatalf("failed to create a topic: %s", err)
 	}
-	id2, err := s.Topics().New(u2, "topic2")
+	id2, err := s.Topics().New(u2, 0, "topic2")
 	if err != nil {
 		t.Fatalf("failed to create a topic: %s", err)
 	}
-	id3, err := s.Topics().New(u1, "topic3")
+	id3, err := s.Topics().New(u1, 0, "topic3")
 	if err != nil {
 		t.Fatalf("failed to create a topic: %s", err)
 	}
-	id4, err := s.Topics().New(u2, "topic4 日本 Доброе утро")
+	id4, err := s.Topics().New(u2, 0, "topic4 日本 Доброе утро")
 	if err != nil {
 		t.Fatalf("failed to create a topic: %s", err)
 	}
@@ -85,7 +85,7 @@ func TestTopic(t *testing.T) {
 		t.Fatalf("got topic %v, want %v", got, want)
 	}
 
-	err = s.Topics().SetTitle(id3, "new title")
+	err = s.Topics().SetTitle(id3, u2, "new title")
 	if err != nil {
 		t.Fatalf("failed to SetTitle: %s", err)
 	}
@@ -106,6 +106,20 @@ func TestTopic(t *testing.T) {
 		t.Fatalf("got topic %v, want %v", got, want)
 	}
 
+	revisions, err := s.Topics().GetRevisions(id3)
+	if err != nil {
+		t.Fatalf("failed to get topic revisions: %s", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("bad revisions len: %d", len(revisions))
+	}
+	if revisions[0].EditorID != u1 || revisions[0].Title != "topic3" {
+		t.Fatalf("bad first revision: got (%d, %q) want (%d, %q)", revisions[0].EditorID, revisions[0].Title, u1, "topic3")
+	}
+	if revisions[1].EditorID != u2 || revisions[1].Title != "new title" {
+		t.Fatalf("bad second revision: got (%d, %q) want (%d, %q)", revisions[1].EditorID, revisions[1].Title, u2, "new title")
+	}
+
 	err = s.Topics().Delete(id3)
 	if err != nil {
 		t.Fatalf("failed to delete topic: %s", err)
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"time"
+)
+
+// Notification tells a user about a new comment that concerns them.
+// ActorID is the comment author. TopicTitle is filled in by the GetByUser method.
+type Notification struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"userId"`
+	Type       string    `json:"type"`
+	TopicID    int64     `json:"topicId"`
+	TopicTitle string    `json:"topicTitle"`
+	CommentID  int64     `json:"commentId"`
+	ActorID    int64     `json:"actorId"`
+	Read       bool      `json:"read"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Notification types.
+const (
+	// NotificationReply is sent to the topic author on a new comment in the topic.
+	NotificationReply = "reply"
+	// NotificationMention is sent to the users mentioned in a new comment as @username.
+	NotificationMention = "mention"
+)
+
+// maxMentions is the maximum number of users that can be notified
+// about mentions in a single comment.
+const maxMentions = 20
+
+// Mentions returns the user names mentioned in the comment content as @username.
+// An @ preceded by a user name character, e.g. in an email address, is not a mention.
+// The names are returned in order of appearance without duplicates.
+func Mentions(content string) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	runes := []rune(content)
+	for i := 0; i < len(runes) && len(names) < maxMentions; i++ {
+		if runes[i] != '@' || (i > 0 && validUserNameRune(runes[i-1])) {
+			continue
+		}
+
+		j := i + 1
+		for j < len(runes) && validUserNameRune(runes[j]) {
+			j++
+		}
+
+		name := string(runes[i+1 : j])
+		if ValidUserName(name) && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+		i = j - 1
+	}
+
+	return names
+}
+
+// CommentNotifications returns the notifications about a new comment: a reply
+// notification for the topic author and mention notifications for the mentioned users,
+// in the order given. The topic author is not notified of being mentioned in addition
+// to the reply, and nobody is notified about their own comment.
+func CommentNotifications(topicID, topicAuthorID, commentID, commentAuthorID int64, mentionIDs []int64) []*Notification {
+	var notifications []*Notification
+	seen := map[int64]bool{commentAuthorID: true}
+
+	add := func(userID int64, typ string) {
+		if seen[userID] {
+			return
+		}
+		seen[userID] = true
+		notifications = append(notifications, &Notification{
+			UserID:    userID,
+			Type:      typ,
+			TopicID:   topicID,
+			CommentID: commentID,
+			ActorID:   commentAuthorID,
+		})
+	}
+
+	add(topicAuthorID, NotificationReply)
+	for _, id := range mentionIDs {
+		add(id, NotificationMention)
+	}
+
+	return notifications
+}
@@ -12,7 +12,7 @@ type commentStore struct {
 }
 
 // New creates a new comment.
-func (s *commentStore) New(topicID int64, authorID int64, content string) (int64, error) {
+func (s *commentStore) New(topicID int64, authorID int64, content string, mentionIDs []int64) (int64, error) {
 	var id int64
 	now := time.Now()
 
@@ -21,9 +21,9 @@ func (s *commentStore) New(topicID int64, authorID int64, content string) (int64
 		return 0, err
 	}
 
-	err = s.db.QueryRow(
-		`insert into comments(topic_id, author_id, content, created_at) values ($1, $2, $3, $4) returning id`,
-		topicID, authorID, content, now,
+	err = tx.QueryRow(
+		`insert into comments(topic_id, author_id, content, created_at, updated_at) values ($1, $2, $3, $4, $5) returning id`,
+		topicID, authorID, content, now, now,
 	).Scan(&id)
 	if err != nil {
 		tx.Rollback()
@@ -36,6 +36,41 @@ func (s *commentStore) New(topicID int64, authorID int64, content string) (int64
 		return 0, err
 	}
 
+	var topicAuthorID int64
+	err = tx.QueryRow(`select author_id from topics where id=$1`, topicID).Scan(&topicAuthorID)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return 0, store.ErrNotFound
+		}
+		return 0, err
+	}
+
+	for _, n := range store.CommentNotifications(topicID, topicAuthorID, id, authorID, mentionIDs) {
+		_, err = tx.Exec(
+			`insert into notifications(user_id, type, topic_id, comment_id, actor_id, is_read, created_at) values ($1, $2, $3, $4, $5, $6, $7)`,
+			n.UserID, n.Type, n.TopicID, n.CommentID, n.ActorID, false, now,
+		)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	_, err = tx.Exec(
+		`
+		insert into digest_queue(user_id, comment_id)
+		select w.user_id, $1 from topic_watches w
+		join digest_settings d on d.user_id=w.user_id
+		where w.topic_id=$2 and w.user_id<>$3 and d.mode<>$4
+		`,
+		id, topicID, authorID, store.DigestOff,
+	)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		tx.Rollback()
@@ -45,11 +80,11 @@ func (s *commentStore) New(topicID int64, authorID int64, content string) (int64
 	return id, nil
 }
 
-const selectFromComments = `select id, topic_id, author_id, content, created_at from comments`
+const selectFromComments = `select id, topic_id, author_id, content, created_at, updated_at, edit_count from comments`
 
 func (s *commentStore) scanComment(scanner scanner) (*store.Comment, error) {
 	c := new(store.Comment)
-	err := scanner.Scan(&c.ID, &c.TopicID, &c.AuthorID, &c.Content, &c.CreatedAt)
+	err := scanner.Scan(&c.ID, &c.TopicID, &c.AuthorID, &c.Content, &c.CreatedAt, &c.UpdatedAt, &c.EditCount)
 	if err == sql.ErrNoRows {
 		return nil, store.ErrNotFound
 	}
@@ -103,13 +138,168 @@ func (s *commentStore) GetByTopic(topicID int64, offset, limit int) ([]*store.Co
 	return comments, count, nil
 }
 
-// SetContent updates comment.Content value.
-func (s *commentStore) SetContent(id int64, content string) error {
-	_, err := s.db.Exec(
-		`update comments set content=$1 where id=$2`,
-		content, id,
+// GetByTopicAfter finds a limited number of comments by topic following the cursor.
+func (s *commentStore) GetByTopicAfter(topicID int64, cursor *store.CommentCursor, limit int) ([]*store.Comment, error) {
+	if limit <= 0 {
+		return []*store.Comment{}, nil
+	}
+
+	var rows *sql.Rows
+	var err error
+	if cursor == nil {
+		rows, err = s.db.Query(
+			selectFromComments+` where deleted=false and topic_id=$1 order by created_at, id limit $2`,
+			topicID,
+			limit,
+		)
+	} else {
+		rows, err = s.db.Query(
+			selectFromComments+` where deleted=false and topic_id=$1 and (created_at, id) > ($2, $3) order by created_at, id limit $4`,
+			topicID,
+			cursor.CreatedAt,
+			cursor.ID,
+			limit,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []*store.Comment{}
+	for rows.Next() {
+		comment, err := s.scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// Search finds comments by content using the full-text index.
+// All the query terms must be present. The results are ordered by relevance.
+// Comments of deleted topics are excluded.
+func (s *commentStore) Search(query string, offset, limit int) ([]*store.Comment, int, error) {
+	const match = `deleted=false and topic_id in (select id from topics where deleted=false) and
+		to_tsvector('simple', content) @@ plainto_tsquery('simple', $1)`
+
+	var count int
+	err := s.db.QueryRow(`select count(*) from comments where `+match, query).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if limit <= 0 || offset > count {
+		return []*store.Comment{}, count, nil
+	}
+
+	rows, err := s.db.Query(
+		selectFromComments+` where `+match+`
+			order by ts_rank(to_tsvector('simple', content), plainto_tsquery('simple', $1)) desc, created_at desc, id desc
+			limit $2 offset $3`,
+		query,
+		limit,
+		offset,
 	)
-	return err
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	comments := []*store.Comment{}
+	for rows.Next() {
+		comment, err := s.scanComment(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		comments = append(comments, comment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return comments, count, nil
+}
+
+// GetRevisions returns the edit history of a comment, oldest first.
+func (s *commentStore) GetRevisions(id int64) ([]*store.CommentRevision, error) {
+	rows, err := s.db.Query(
+		`select id, comment_id, editor_id, content, created_at from comment_revisions where comment_id=$1 order by created_at, id`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*store.CommentRevision{}
+	for rows.Next() {
+		r := new(store.CommentRevision)
+		err := rows.Scan(&r.ID, &r.CommentID, &r.EditorID, &r.Content, &r.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// SetContent updates comment.Content value and saves the edit to the comment revisions.
+func (s *commentStore) SetContent(id int64, editorID int64, content string) error {
+	now := time.Now()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// Keep the original content as the first revision.
+	_, err = tx.Exec(
+		`
+		insert into comment_revisions(comment_id, editor_id, content, created_at)
+		select id, author_id, content, created_at from comments where id=$1 and edit_count=0
+		`,
+		id,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(
+		`insert into comment_revisions(comment_id, editor_id, content, created_at) values ($1, $2, $3, $4)`,
+		id, editorID, content, now,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(
+		`update comments set content=$1, updated_at=$2, edit_count=edit_count+1 where id=$3`,
+		content, now, id,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
 }
 
 // Delete soft-deletes a comment.
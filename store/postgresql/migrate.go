@@ -0,0 +1,166 @@
+package postgresql
+
+import (
+	"fmt"
+
+	"github.com/disintegration/bebop/store"
+)
+
+const createMigrationsTable = `
+	create table if not exists schema_migrations (
+		version     int          not null primary key,
+		name        text         not null,
+		checksum    text         not null,
+		applied_at  timestamptz  not null
+	)
+`
+
+// MigrationStatus returns the state of all the known and applied schema migrations.
+func (s *Store) MigrationStatus() ([]*store.MigrationStatus, error) {
+	_, err := s.db.Exec(createMigrationsTable)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`select version, name, checksum, applied_at from schema_migrations order by version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applied []*store.MigrationStatus
+	for rows.Next() {
+		a := &store.MigrationStatus{}
+		err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt)
+		if err != nil {
+			return nil, err
+		}
+		applied = append(applied, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return store.GetMigrationStatus(migrations, applied), nil
+}
+
+// Migrate applies all the pending schema migrations.
+func (s *Store) Migrate() error {
+	status, err := s.MigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	err = store.CheckMigrationStatus(status)
+	if err != nil {
+		return err
+	}
+
+	for i, st := range status {
+		if !st.Applied {
+			err = s.applyMigration(migrations[i])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Rollback reverts the given number of the most recently applied schema migrations.
+func (s *Store) Rollback(steps int) error {
+	status, err := s.MigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	for _, st := range status {
+		if st.Unknown {
+			return fmt.Errorf("store: cannot roll back unknown migration %d (%s)", st.Version, st.Name)
+		}
+	}
+
+	for i := len(status) - 1; i >= 0 && steps > 0; i-- {
+		if !status[i].Applied {
+			continue
+		}
+		err = s.revertMigration(migrations[i])
+		if err != nil {
+			return err
+		}
+		steps--
+	}
+
+	return nil
+}
+
+// checkMigrations returns ErrPendingMigrations if the database schema is not up to date.
+func (s *Store) checkMigrations() error {
+	status, err := s.MigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	err = store.CheckMigrationStatus(status)
+	if err != nil {
+		return err
+	}
+
+	for _, st := range status {
+		if !st.Applied {
+			return store.ErrPendingMigrations
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) applyMigration(m *store.Migration) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, q := range m.Up {
+		_, err = tx.Exec(q)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d (%s): sql exec error: %s; query: %q", m.Version, m.Name, err, q)
+		}
+	}
+
+	_, err = tx.Exec(
+		`insert into schema_migrations(version, name, checksum, applied_at) values($1, $2, $3, now())`,
+		m.Version, m.Name, m.Checksum(),
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) revertMigration(m *store.Migration) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, q := range m.Down {
+		_, err = tx.Exec(q)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d (%s): sql exec error: %s; query: %q", m.Version, m.Name, err, q)
+		}
+	}
+
+	_, err = tx.Exec(`delete from schema_migrations where version=$1`, m.Version)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
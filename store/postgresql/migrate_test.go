@@ -0,0 +1,116 @@
+package postgresql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func TestMigrate(t *testing.T) {
+	s, teardown := getTestStore(t)
+	defer teardown()
+
+	status, err := s.MigrationStatus()
+	if err != nil {
+		t.Fatalf("failed to get migration status: %s", err)
+	}
+	if len(status) != len(migrations) {
+		t.Fatalf("bad migration status length: %d, want %d", len(status), len(migrations))
+	}
+	for _, st := range status {
+		if !st.Applied || st.Modified || st.Unknown {
+			t.Fatalf("bad migration status: %#v", st)
+		}
+	}
+
+	err = s.checkMigrations()
+	if err != nil {
+		t.Fatalf("failed to check migrations: %s", err)
+	}
+
+	u1, err := s.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+	t1, err := s.Topics().New(u1, 0, "topic1")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+	c1, err := s.Comments().New(t1, u1, "comment1", nil)
+	if err != nil {
+		t.Fatalf("failed to create a comment: %s", err)
+	}
+	err = s.Roles().Grant(u1, store.RoleAdmin)
+	if err != nil {
+		t.Fatalf("failed to grant a role: %s", err)
+	}
+
+	err = s.Rollback(1)
+	if err != nil {
+		t.Fatalf("failed to roll back a migration: %s", err)
+	}
+
+	status, err = s.MigrationStatus()
+	if err != nil {
+		t.Fatalf("failed to get migration status: %s", err)
+	}
+	if status[len(status)-1].Applied {
+		t.Fatalf("expected the last migration to be rolled back: %#v", status[len(status)-1])
+	}
+
+	err = s.checkMigrations()
+	if err != store.ErrPendingMigrations {
+		t.Fatalf("expected error ErrPendingMigrations, got %v", err)
+	}
+
+	err = s.Migrate()
+	if err != nil {
+		t.Fatalf("failed to migrate: %s", err)
+	}
+
+	comment, err := s.Comments().Get(c1)
+	if err != nil {
+		t.Fatalf("failed to get a comment: %s", err)
+	}
+	if comment.Content != "comment1" || !comment.UpdatedAt.Equal(comment.CreatedAt) || comment.EditCount != 0 {
+		t.Fatalf("bad comment after migration: %#v", comment)
+	}
+
+	roles, err := s.Roles().GetByUser(u1)
+	if err != nil {
+		t.Fatalf("failed to get user roles: %s", err)
+	}
+	if !reflect.DeepEqual(roles, []string{store.RoleAdmin}) {
+		t.Fatalf("bad user roles after migration: %v", roles)
+	}
+
+	_, err = s.db.Exec(`update schema_migrations set checksum='modified' where version=1`)
+	if err != nil {
+		t.Fatalf("failed to update a migration checksum: %s", err)
+	}
+	err = s.Migrate()
+	if err == nil {
+		t.Fatalf("expected an error on a modified migration")
+	}
+
+	err = s.Rollback(len(migrations))
+	if err != nil {
+		t.Fatalf("failed to roll back all the migrations: %s", err)
+	}
+
+	status, err = s.MigrationStatus()
+	if err != nil {
+		t.Fatalf("failed to get migration status: %s", err)
+	}
+	for _, st := range status {
+		if st.Applied {
+			t.Fatalf("expected all the migrations to be rolled back: %#v", st)
+		}
+	}
+
+	err = s.Migrate()
+	if err != nil {
+		t.Fatalf("failed to migrate: %s", err)
+	}
+}
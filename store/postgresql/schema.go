@@ -1,48 +1,519 @@
 package postgresql
 
-var migrate = []string{
-	`
-		create table if not exists users (
-			id            bigserial    not null primary key,
-			name          text         default null,
-			created_at    timestamptz  not null,
-			auth_service  text         not null,
-			auth_id       text         not null,
-			blocked       boolean      not null default false,
-			admin         boolean      not null default false,
-			avatar        text         not null default ''
-		);
-		create unique index on users(lower(name));
-		create unique index on users(auth_service, auth_id);
-	`,
-	`
-		create table if not exists topics (
-			id               bigserial    not null primary key,
-			author_id        bigint       not null references users(id),
-			title            text         not null,
-			created_at       timestamptz  not null,
-			last_comment_at  timestamptz  not null,
-			deleted          boolean      not null default false,
-			comment_count    int          not null default 0
-		);
-		create index on topics(last_comment_at);
-	`,
-	`
-		create table if not exists comments (
-			id          bigserial     not null primary key,
-			topic_id    bigint        not null references topics(id),
-			author_id   bigint        not null references users(id),
-			content     text          not null,
-			created_at  timestamptz   not null,
-			deleted     boolean       not null default false
-		);
-		create index on comments(topic_id);
-		create index on comments(created_at);
-	`,
+import (
+	"github.com/disintegration/bebop/store"
+)
+
+// migrations is the list of database schema migrations.
+// Never change a migration once it has been released: add a new one instead.
+var migrations = []*store.Migration{
+	{
+		Version: 1,
+		Name:    "initial schema",
+		Up: []string{
+			`
+				create table if not exists users (
+					id            bigserial    not null primary key,
+					name          text         default null,
+					created_at    timestamptz  not null,
+					auth_service  text         not null,
+					auth_id       text         not null,
+					blocked       boolean      not null default false,
+					admin         boolean      not null default false,
+					avatar        text         not null default ''
+				)
+			`,
+			`create unique index if not exists users_lower_idx on users(lower(name))`,
+			`create unique index if not exists users_auth_service_auth_id_idx on users(auth_service, auth_id)`,
+			`
+				create table if not exists topics (
+					id               bigserial    not null primary key,
+					author_id        bigint       not null references users(id),
+					title            text         not null,
+					created_at       timestamptz  not null,
+					last_comment_at  timestamptz  not null,
+					deleted          boolean      not null default false,
+					comment_count    int          not null default 0
+				)
+			`,
+			`create index if not exists topics_last_comment_at_idx on topics(last_comment_at)`,
+			`
+				create table if not exists comments (
+					id          bigserial     not null primary key,
+					topic_id    bigint        not null references topics(id),
+					author_id   bigint        not null references users(id),
+					content     text          not null,
+					created_at  timestamptz   not null,
+					deleted     boolean       not null default false
+				)
+			`,
+			`create index if not exists comments_topic_id_idx on comments(topic_id)`,
+			`create index if not exists comments_created_at_idx on comments(created_at)`,
+		},
+		Down: []string{
+			`drop table if exists comments cascade`,
+			`drop table if exists topics cascade`,
+			`drop table if exists users cascade`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "edit history",
+		Up: []string{
+			`alter table comments add column if not exists updated_at timestamptz`,
+			`update comments set updated_at = created_at where updated_at is null`,
+			`alter table comments alter column updated_at set not null`,
+			`alter table comments add column if not exists edit_count int not null default 0`,
+			`
+				create table if not exists topic_revisions (
+					id          bigserial    not null primary key,
+					topic_id    bigint       not null references topics(id),
+					editor_id   bigint       not null references users(id),
+					title       text         not null,
+					created_at  timestamptz  not null
+				)
+			`,
+			`create index if not exists topic_revisions_topic_id_idx on topic_revisions(topic_id)`,
+			`
+				create table if not exists comment_revisions (
+					id          bigserial    not null primary key,
+					comment_id  bigint       not null references comments(id),
+					editor_id   bigint       not null references users(id),
+					content     text         not null,
+					created_at  timestamptz  not null
+				)
+			`,
+			`create index if not exists comment_revisions_comment_id_idx on comment_revisions(comment_id)`,
+		},
+		Down: []string{
+			`drop table if exists comment_revisions cascade`,
+			`drop table if exists topic_revisions cascade`,
+			`alter table comments drop column if exists edit_count`,
+			`alter table comments drop column if exists updated_at`,
+		},
+	},
+	{
+		Version: 3,
+		Name:    "full-text search",
+		Up: []string{
+			`create index if not exists topics_title_search_idx on topics using gin(to_tsvector('simple', title))`,
+			`create index if not exists comments_content_search_idx on comments using gin(to_tsvector('simple', content))`,
+		},
+		Down: []string{
+			`drop index if exists comments_content_search_idx`,
+			`drop index if exists topics_title_search_idx`,
+		},
+	},
+	{
+		Version: 4,
+		Name:    "categories",
+		Up: []string{
+			`
+				create table if not exists categories (
+					id           bigserial  not null primary key,
+					slug         text       not null,
+					name         text       not null,
+					description  text       not null default '',
+					sort_order   int        not null default 0,
+					admin_only   boolean    not null default false
+				)
+			`,
+			`create unique index if not exists categories_slug_idx on categories(slug)`,
+			`alter table topics add column if not exists category_id bigint references categories(id)`,
+			`create index if not exists topics_category_id_last_comment_at_idx on topics(category_id, last_comment_at)`,
+		},
+		Down: []string{
+			`drop index if exists topics_category_id_last_comment_at_idx`,
+			`alter table topics drop column if exists category_id`,
+			`drop table if exists categories cascade`,
+		},
+	},
+	{
+		Version: 5,
+		Name:    "pinned and locked topics",
+		Up: []string{
+			`alter table topics add column if not exists pinned boolean not null default false`,
+			`alter table topics add column if not exists locked boolean not null default false`,
+			`create index if not exists topics_pinned_last_comment_at_idx on topics(pinned, last_comment_at)`,
+		},
+		Down: []string{
+			`drop index if exists topics_pinned_last_comment_at_idx`,
+			`alter table topics drop column if exists locked`,
+			`alter table topics drop column if exists pinned`,
+		},
+	},
+	{
+		Version: 6,
+		Name:    "comment pagination index",
+		Up: []string{
+			`create index if not exists comments_topic_id_created_at_idx on comments(topic_id, created_at, id)`,
+		},
+		Down: []string{
+			`drop index if exists comments_topic_id_created_at_idx`,
+		},
+	},
+	{
+		Version: 7,
+		Name:    "reactions",
+		Up: []string{
+			`
+				create table if not exists reactions (
+					comment_id  bigint       not null references comments(id),
+					user_id     bigint       not null references users(id),
+					emoji       text         not null,
+					created_at  timestamptz  not null,
+
+					primary key (comment_id, user_id, emoji)
+				)
+			`,
+		},
+		Down: []string{
+			`drop table if exists reactions cascade`,
+		},
+	},
+	{
+		Version: 8,
+		Name:    "reports",
+		Up: []string{
+			`
+				create table if not exists reports (
+					id           bigserial    not null primary key,
+					reporter_id  bigint       not null references users(id),
+					target_type  text         not null,
+					target_id    bigint       not null,
+					author_id    bigint       not null references users(id),
+					reason       text         not null,
+					status       text         not null default 'open',
+					created_at   timestamptz  not null,
+					resolved_by  bigint       default null references users(id),
+					resolved_at  timestamptz  default null
+				)
+			`,
+			`create index if not exists reports_status_created_at_idx on reports(status, created_at)`,
+			`create index if not exists reports_target_type_target_id_idx on reports(target_type, target_id)`,
+		},
+		Down: []string{
+			`drop table if exists reports cascade`,
+		},
+	},
+	{
+		Version: 9,
+		Name:    "audit log",
+		Up: []string{
+			`
+				create table if not exists audit_log (
+					id           bigserial    not null primary key,
+					actor_id     bigint       not null,
+					action       text         not null,
+					target_type  text         not null,
+					target_id    bigint       not null,
+					before_json  text         not null,
+					after_json   text         not null,
+					ip           text         not null,
+					created_at   timestamptz  not null
+				)
+			`,
+			`create index if not exists audit_log_created_at_idx on audit_log(created_at)`,
+			`create index if not exists audit_log_actor_id_idx on audit_log(actor_id)`,
+			`create index if not exists audit_log_target_type_target_id_idx on audit_log(target_type, target_id)`,
+		},
+		Down: []string{
+			`drop table if exists audit_log cascade`,
+		},
+	},
+	{
+		Version: 10,
+		Name:    "sessions",
+		Up: []string{
+			`alter table users add column if not exists tokens_valid_after timestamptz default null`,
+			`
+				create table if not exists sessions (
+					id          bigserial    not null primary key,
+					user_id     bigint       not null references users(id),
+					token_hash  text         not null,
+					created_at  timestamptz  not null,
+					expires_at  timestamptz  not null
+				)
+			`,
+			`create unique index if not exists sessions_token_hash_idx on sessions(token_hash)`,
+			`create index if not exists sessions_user_id_idx on sessions(user_id)`,
+		},
+		Down: []string{
+			`drop table if exists sessions cascade`,
+			`alter table users drop column if exists tokens_valid_after`,
+		},
+	},
+	{
+		Version: 11,
+		Name:    "local accounts",
+		Up: []string{
+			`
+				create table if not exists local_accounts (
+					user_id        bigint       not null primary key references users(id),
+					email          text         not null,
+					password_hash  text         not null,
+					verified       boolean      not null default false,
+					created_at     timestamptz  not null
+				)
+			`,
+			`create unique index if not exists local_accounts_email_idx on local_accounts(email)`,
+			`
+				create table if not exists auth_tokens (
+					id          bigserial    not null primary key,
+					user_id     bigint       not null references users(id),
+					purpose     text         not null,
+					token_hash  text         not null,
+					created_at  timestamptz  not null,
+					expires_at  timestamptz  not null
+				)
+			`,
+			`create unique index if not exists auth_tokens_token_hash_idx on auth_tokens(token_hash)`,
+			`create index if not exists auth_tokens_user_id_idx on auth_tokens(user_id)`,
+		},
+		Down: []string{
+			`drop table if exists auth_tokens cascade`,
+			`drop table if exists local_accounts cascade`,
+		},
+	},
+	{
+		Version: 12,
+		Name:    "identities",
+		Up: []string{
+			`
+				create table if not exists identities (
+					id            bigserial    not null primary key,
+					user_id       bigint       not null references users(id),
+					auth_service  text         not null,
+					auth_id       text         not null,
+					created_at    timestamptz  not null
+				)
+			`,
+			`create unique index if not exists identities_auth_service_auth_id_idx on identities(auth_service, auth_id)`,
+			`create index if not exists identities_user_id_idx on identities(user_id)`,
+			`
+				insert into identities(user_id, auth_service, auth_id, created_at)
+				select id, auth_service, auth_id, created_at from users
+			`,
+			// The users keep the identity they signed up with, but it can be unlinked
+			// and then used by another user.
+			`drop index if exists users_auth_service_auth_id_idx`,
+		},
+		Down: []string{
+			`create unique index if not exists users_auth_service_auth_id_idx on users(auth_service, auth_id)`,
+			`drop table if exists identities cascade`,
+		},
+	},
+	{
+		Version: 13,
+		Name:    "identity profiles",
+		Up: []string{
+			`alter table identities add column if not exists display_name text not null default ''`,
+			`alter table identities add column if not exists picture text not null default ''`,
+		},
+		Down: []string{
+			`alter table identities drop column if exists picture`,
+			`alter table identities drop column if exists display_name`,
+		},
+	},
+	{
+		Version: 14,
+		Name:    "user roles",
+		Up: []string{
+			`
+				create table if not exists user_roles (
+					user_id     bigint       not null references users(id),
+					role        text         not null,
+					created_at  timestamptz  not null,
+
+					primary key (user_id, role)
+				)
+			`,
+			`create index if not exists user_roles_role_idx on user_roles(role)`,
+			`
+				insert into user_roles(user_id, role, created_at)
+				select id, 'admin', now() from users where admin=true
+			`,
+			`alter table users drop column if exists admin`,
+		},
+		Down: []string{
+			`alter table users add column if not exists admin boolean not null default false`,
+			`update users set admin=true where id in (select user_id from user_roles where role='admin')`,
+			`drop table if exists user_roles cascade`,
+		},
+	},
+	{
+		Version: 15,
+		Name:    "notifications",
+		Up: []string{
+			`
+				create table if not exists notifications (
+					id          bigserial    not null primary key,
+					user_id     bigint       not null references users(id),
+					type        text         not null,
+					topic_id    bigint       not null references topics(id),
+					comment_id  bigint       not null references comments(id),
+					actor_id    bigint       not null references users(id),
+					is_read     boolean      not null default false,
+					created_at  timestamptz  not null
+				)
+			`,
+			`create index if not exists notifications_user_id_id_idx on notifications(user_id, id)`,
+		},
+		Down: []string{
+			`drop table if exists notifications cascade`,
+		},
+	},
+	{
+		Version: 16,
+		Name:    "topic watches and email digests",
+		Up: []string{
+			`
+				create table if not exists topic_watches (
+					user_id     bigint       not null references users(id),
+					topic_id    bigint       not null references topics(id),
+					created_at  timestamptz  not null,
+
+					primary key (user_id, topic_id)
+				)
+			`,
+			`create index if not exists topic_watches_topic_id_idx on topic_watches(topic_id)`,
+			`
+				create table if not exists digest_settings (
+					user_id       bigint       not null primary key references users(id),
+					mode          text         not null,
+					last_sent_at  timestamptz  default null
+				)
+			`,
+			`
+				create table if not exists digest_queue (
+					user_id     bigint  not null references users(id),
+					comment_id  bigint  not null references comments(id),
+
+					primary key (user_id, comment_id)
+				)
+			`,
+		},
+		Down: []string{
+			`drop table if exists digest_queue cascade`,
+			`drop table if exists digest_settings cascade`,
+			`drop table if exists topic_watches cascade`,
+		},
+	},
+	{
+		Version: 17,
+		Name:    "webhooks",
+		Up: []string{
+			`
+				create table if not exists webhooks (
+					id          bigserial    not null primary key,
+					url         text         not null,
+					secret      text         not null,
+					events      text         not null,
+					created_at  timestamptz  not null
+				)
+			`,
+			`
+				create table if not exists webhook_deliveries (
+					id               bigserial    not null primary key,
+					webhook_id       bigint       not null references webhooks(id),
+					event            text         not null,
+					payload          text         not null,
+					status           text         not null,
+					attempts         integer      not null default 0,
+					response_code    integer      not null default 0,
+					error            text         not null default '',
+					next_attempt_at  timestamptz  not null,
+					created_at       timestamptz  not null,
+					updated_at       timestamptz  not null
+				)
+			`,
+			`create index if not exists webhook_deliveries_status_idx on webhook_deliveries(status, next_attempt_at)`,
+			`create index if not exists webhook_deliveries_webhook_id_idx on webhook_deliveries(webhook_id, id)`,
+		},
+		Down: []string{
+			`drop table if exists webhook_deliveries cascade`,
+			`drop table if exists webhooks cascade`,
+		},
+	},
+	{
+		Version: 18,
+		Name:    "webhook delivery locks",
+		Up: []string{
+			`alter table webhook_deliveries add column if not exists locked_until timestamptz`,
+		},
+		Down: []string{
+			`alter table webhook_deliveries drop column if exists locked_until`,
+		},
+	},
+	{
+		Version: 19,
+		Name:    "role permissions",
+		Up: []string{
+			`
+				create table if not exists role_permissions (
+					role        text         not null,
+					permission  text         not null,
+					created_at  timestamptz  not null,
+
+					primary key (role, permission)
+				)
+			`,
+			`
+				insert into role_permissions(role, permission, created_at) values
+				('admin', 'topic.edit', now()),
+				('admin', 'topic.delete', now()),
+				('admin', 'topic.move', now()),
+				('admin', 'topic.pin', now()),
+				('admin', 'topic.lock', now()),
+				('admin', 'topic.post_admin_only', now()),
+				('admin', 'comment.edit', now()),
+				('admin', 'comment.delete', now()),
+				('admin', 'comment.locked_topic', now()),
+				('admin', 'user.view', now()),
+				('admin', 'user.rename', now()),
+				('admin', 'user.avatar', now()),
+				('admin', 'user.block', now()),
+				('admin', 'category.manage', now()),
+				('admin', 'report.manage', now()),
+				('admin', 'audit.read', now()),
+				('admin', 'webhook.manage', now()),
+				('moderator', 'topic.delete', now()),
+				('moderator', 'topic.move', now()),
+				('moderator', 'topic.pin', now()),
+				('moderator', 'topic.lock', now()),
+				('moderator', 'comment.delete', now()),
+				('moderator', 'comment.locked_topic', now()),
+				('moderator', 'user.view', now()),
+				('moderator', 'report.manage', now())
+			`,
+		},
+		Down: []string{
+			`drop table if exists role_permissions cascade`,
+		},
+	},
 }
 
 var drop = []string{
 	`drop table if exists users cascade`,
 	`drop table if exists topics cascade`,
 	`drop table if exists comments cascade`,
+	`drop table if exists topic_revisions cascade`,
+	`drop table if exists comment_revisions cascade`,
+	`drop table if exists categories cascade`,
+	`drop table if exists reactions cascade`,
+	`drop table if exists reports cascade`,
+	`drop table if exists audit_log cascade`,
+	`drop table if exists sessions cascade`,
+	`drop table if exists local_accounts cascade`,
+	`drop table if exists auth_tokens cascade`,
+	`drop table if exists identities cascade`,
+	`drop table if exists user_roles cascade`,
+	`drop table if exists notifications cascade`,
+	`drop table if exists topic_watches cascade`,
+	`drop table if exists digest_settings cascade`,
+	`drop table if exists digest_queue cascade`,
+	`drop table if exists webhooks cascade`,
+	`drop table if exists webhook_deliveries cascade`,
+	`drop table if exists role_permissions cascade`,
+	`drop table if exists schema_migrations cascade`,
 }
@@ -15,10 +15,23 @@ import (
 
 // Store is a postgresql implementation of store.
 type Store struct {
-	db           *sql.DB
-	userStore    *userStore
-	topicStore   *topicStore
-	commentStore *commentStore
+	db            *sql.DB
+	userStore     *userStore
+	topicStore    *topicStore
+	commentStore  *commentStore
+	categoryStore *categoryStore
+	reactionStore *reactionStore
+	reportStore   *reportStore
+	auditStore    *auditStore
+	sessionStore  *sessionStore
+	localStore    *localAccountStore
+	tokenStore    *authTokenStore
+	identityStore *identityStore
+	roleStore     *roleStore
+	notifyStore   *notificationStore
+	watchStore    *watchStore
+	digestStore   *digestStore
+	webhookStore  *webhookStore
 }
 
 // Users returns a user store.
@@ -36,10 +49,75 @@ func (s *Store) Comments() store.CommentStore {
 	return s.commentStore
 }
 
+// Categories returns a category store.
+func (s *Store) Categories() store.CategoryStore {
+	return s.categoryStore
+}
+
+// Reactions returns a reaction store.
+func (s *Store) Reactions() store.ReactionStore {
+	return s.reactionStore
+}
+
+// Reports returns a report store.
+func (s *Store) Reports() store.ReportStore {
+	return s.reportStore
+}
+
+// Audit returns an audit log store.
+func (s *Store) Audit() store.AuditStore {
+	return s.auditStore
+}
+
+// Sessions returns a login session store.
+func (s *Store) Sessions() store.SessionStore {
+	return s.sessionStore
+}
+
+// LocalAccounts returns a local account store.
+func (s *Store) LocalAccounts() store.LocalAccountStore {
+	return s.localStore
+}
+
+// AuthTokens returns a single-use auth token store.
+func (s *Store) AuthTokens() store.AuthTokenStore {
+	return s.tokenStore
+}
+
+// Identities returns a user identity store.
+func (s *Store) Identities() store.IdentityStore {
+	return s.identityStore
+}
+
+// Roles returns a user role store.
+func (s *Store) Roles() store.RoleStore {
+	return s.roleStore
+}
+
+// Notifications returns a user notification store.
+func (s *Store) Notifications() store.NotificationStore {
+	return s.notifyStore
+}
+
+// Watches returns a topic watch store.
+func (s *Store) Watches() store.WatchStore {
+	return s.watchStore
+}
+
+// Digests returns an email digest store.
+func (s *Store) Digests() store.DigestStore {
+	return s.digestStore
+}
+
+// Webhooks returns a webhook store.
+func (s *Store) Webhooks() store.WebhookStore {
+	return s.webhookStore
+}
+
 var _ store.Store = (*Store)(nil)
 
-// Connect connects to a store.
-func Connect(address, username, password, database, sslmode, sslrootcert string) (*Store, error) {
+// Connect connects to a store. The migrate mode defines what to do with pending schema migrations.
+func Connect(address, username, password, database, sslmode, sslrootcert string, migrate store.MigrateMode) (*Store, error) {
 	connstr := fmt.Sprintf(
 		"postgres://%s:%s@%s/%s?sslmode=%s&connect_timeout=10",
 		username, password, address, database, sslmode,
@@ -60,13 +138,31 @@ func Connect(address, username, password, database, sslmode, sslrootcert string)
 	}
 
 	s := &Store{
-		db:           db,
-		userStore:    &userStore{db: db},
-		topicStore:   &topicStore{db: db},
-		commentStore: &commentStore{db: db},
+		db:            db,
+		userStore:     &userStore{db: db},
+		topicStore:    &topicStore{db: db},
+		commentStore:  &commentStore{db: db},
+		categoryStore: &categoryStore{db: db},
+		reactionStore: &reactionStore{db: db},
+		reportStore:   &reportStore{db: db},
+		auditStore:    &auditStore{db: db},
+		sessionStore:  &sessionStore{db: db},
+		localStore:    &localAccountStore{db: db},
+		tokenStore:    &authTokenStore{db: db},
+		identityStore: &identityStore{db: db},
+		roleStore:     &roleStore{db: db},
+		notifyStore:   &notificationStore{db: db},
+		watchStore:    &watchStore{db: db},
+		digestStore:   &digestStore{db: db},
+		webhookStore:  &webhookStore{db: db},
 	}
 
-	err = s.Migrate()
+	switch migrate {
+	case store.MigrateAuto:
+		err = s.Migrate()
+	case store.MigrateCheck:
+		err = s.checkMigrations()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -74,17 +170,6 @@ func Connect(address, username, password, database, sslmode, sslrootcert string)
 	return s, nil
 }
 
-// Migrate migrates the store database.
-func (s *Store) Migrate() error {
-	for _, q := range migrate {
-		_, err := s.db.Exec(q)
-		if err != nil {
-			return fmt.Errorf("sql exec error: %s; query: %q", err, q)
-		}
-	}
-	return nil
-}
-
 // Drop drops the store database.
 func (s *Store) Drop() error {
 	for _, q := range drop {
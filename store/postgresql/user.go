@@ -11,16 +11,45 @@ type userStore struct {
 	db *sql.DB
 }
 
-// New creates a new user.
+// New creates a new user with the given identity.
+// It returns ErrConflict if the identity belongs to another user.
 func (s *userStore) New(authService string, authID string) (int64, error) {
-	var id int64
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
 
-	err := s.db.QueryRow(
+	now := time.Now()
+
+	var id int64
+	err = tx.QueryRow(
 		`insert into users(created_at, auth_service, auth_id) values($1, $2, $3) returning id`,
-		time.Now(), authService, authID,
+		now, authService, authID,
 	).Scan(&id)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	_, err = tx.Exec(
+		`insert into identities(user_id, auth_service, auth_id, created_at) values($1, $2, $3, $4)`,
+		id, authService, authID, now,
+	)
+	if err != nil {
+		tx.Rollback()
+		if isUniqueConstraintError(err) {
+			return 0, store.ErrConflict
+		}
+		return 0, err
+	}
 
-	return id, err
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
 }
 
 const selectFromUsers = `
@@ -31,13 +60,14 @@ const selectFromUsers = `
 		auth_service,
 		auth_id,
 		blocked,
-		admin, 
-		avatar 
+		avatar,
+		tokens_valid_after
 	from users
 `
 
 func (s *userStore) scanUser(scanner scanner) (*store.User, error) {
 	u := new(store.User)
+	var tokensValidAfter sql.NullTime
 	err := scanner.Scan(
 		&u.ID,
 		&u.Name,
@@ -45,8 +75,8 @@ func (s *userStore) scanUser(scanner scanner) (*store.User, error) {
 		&u.AuthService,
 		&u.AuthID,
 		&u.Blocked,
-		&u.Admin,
 		&u.Avatar,
+		&tokensValidAfter,
 	)
 	if err == sql.ErrNoRows {
 		return nil, store.ErrNotFound
@@ -54,6 +84,9 @@ func (s *userStore) scanUser(scanner scanner) (*store.User, error) {
 	if err != nil {
 		return nil, err
 	}
+	if tokensValidAfter.Valid {
+		u.TokensValidAfter = tokensValidAfter.Time
+	}
 	return u, nil
 }
 
@@ -107,39 +140,24 @@ func (s *userStore) GetMany(ids []int64) (map[int64]*store.User, error) {
 	return users, nil
 }
 
-// GetAdmins finds all the admin users.
-func (s *userStore) GetAdmins() ([]*store.User, error) {
-	var users []*store.User
-
-	rows, err := s.db.Query(selectFromUsers + ` where admin=true`)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		user, err := s.scanUser(rows)
-		if err != nil {
-			return nil, err
-		}
-		users = append(users, user)
-	}
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return users, nil
-}
-
 // GetByName finds a user by name.
 func (s *userStore) GetByName(name string) (*store.User, error) {
 	row := s.db.QueryRow(selectFromUsers+` where name=$1`, name)
 	return s.scanUser(row)
 }
 
-// GetByAuth finds a user by authService and authID.
+// GetByNameFold finds a user by name ignoring case.
+func (s *userStore) GetByNameFold(name string) (*store.User, error) {
+	row := s.db.QueryRow(selectFromUsers+` where lower(name)=lower($1)`, name)
+	return s.scanUser(row)
+}
+
+// GetByAuth finds a user by any of their identities.
 func (s *userStore) GetByAuth(authService string, authID string) (*store.User, error) {
-	row := s.db.QueryRow(selectFromUsers+` where auth_service=$1 and auth_id=$2`, authService, authID)
+	row := s.db.QueryRow(
+		selectFromUsers+` where id=(select user_id from identities where auth_service=$1 and auth_id=$2)`,
+		authService, authID,
+	)
 	return s.scanUser(row)
 }
 
@@ -158,14 +176,14 @@ func (s *userStore) SetBlocked(id int64, blocked bool) error {
 	return err
 }
 
-// SetAdmin updates user.Admin value.
-func (s *userStore) SetAdmin(id int64, admin bool) error {
-	_, err := s.db.Exec(`update users set admin=$1 where id=$2`, admin, id)
-	return err
-}
-
 // SetAvatar updates user.Avatar value.
 func (s *userStore) SetAvatar(id int64, avatar string) error {
 	_, err := s.db.Exec(`update users set avatar=$1 where id=$2`, avatar, id)
 	return err
 }
+
+// SetTokensValidAfter updates user.TokensValidAfter value.
+func (s *userStore) SetTokensValidAfter(id int64, t time.Time) error {
+	_, err := s.db.Exec(`update users set tokens_valid_after=$1 where id=$2`, t, id)
+	return err
+}
@@ -51,11 +51,6 @@ func TestUser(t *testing.T) {
 		t.Fatalf("got user %v want %v", user, want)
 	}
 
-	err = s.Users().SetAdmin(id, true)
-	if err != nil {
-		t.Fatalf("failed to SetAdmin: %s", err)
-	}
-
 	err = s.Users().SetAvatar(id, "avatar1")
 	if err != nil {
 		t.Fatalf("failed to SetAvatar: %s", err)
@@ -84,7 +79,6 @@ func TestUser(t *testing.T) {
 		Name:        "user1",
 		Blocked:     true,
 		Avatar:      "avatar1",
-		Admin:       true,
 	}
 
 	if !reflect.DeepEqual(got, want) {
@@ -106,15 +100,6 @@ func TestUser(t *testing.T) {
 		t.Fatalf("failed to get user by auth: %s", err)
 	}
 
-	admins, err := s.Users().GetAdmins()
-	if err != nil {
-		t.Fatalf("failed to get admins: %s", err)
-	}
-
-	if len(admins) != 1 || admins[0].ID != id {
-		t.Fatalf("bad admin list: %#v", admins)
-	}
-
 	err = s.Users().SetName(user2.ID, "USER1")
 	if err != store.ErrConflict {
 		t.Fatalf("expected error ErrConflict on duplicate user name, got: %v", err)
@@ -0,0 +1,283 @@
+package postgresql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type webhookStore struct {
+	db *sql.DB
+}
+
+// New creates a new webhook.
+func (s *webhookStore) New(url, secret string, events []string) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(
+		`insert into webhooks(url, secret, events, created_at) values($1, $2, $3, $4) returning id`,
+		url, secret, strings.Join(events, ","), time.Now(),
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+const selectFromWebhooks = `select id, url, secret, events, created_at from webhooks`
+
+func (s *webhookStore) scanWebhook(scanner scanner) (*store.Webhook, error) {
+	w := new(store.Webhook)
+	var events string
+	err := scanner.Scan(&w.ID, &w.URL, &w.Secret, &events, &w.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	w.Events = splitWebhookEvents(events)
+	return w, nil
+}
+
+// splitWebhookEvents parses the comma-separated list of webhook events.
+func splitWebhookEvents(events string) []string {
+	if events == "" {
+		return []string{}
+	}
+	return strings.Split(events, ",")
+}
+
+// Get finds a webhook by ID.
+func (s *webhookStore) Get(id int64) (*store.Webhook, error) {
+	row := s.db.QueryRow(selectFromWebhooks+` where id=$1`, id)
+	return s.scanWebhook(row)
+}
+
+// GetAll returns all the webhooks in order of creation.
+func (s *webhookStore) GetAll() ([]*store.Webhook, error) {
+	rows, err := s.db.Query(selectFromWebhooks + ` order by id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []*store.Webhook{}
+	for rows.Next() {
+		w, err := s.scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// Delete deletes a webhook and its deliveries.
+func (s *webhookStore) Delete(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`delete from webhook_deliveries where webhook_id=$1`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`delete from webhooks where id=$1`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// Enqueue adds a pending delivery of the event for every webhook subscribed to it.
+func (s *webhookStore) Enqueue(event string, payload []byte) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(`select id, events from webhooks order by id`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var events string
+		err := rows.Scan(&id, &events)
+		if err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+		w := store.Webhook{Events: splitWebhookEvents(events)}
+		if w.Subscribed(event) {
+			ids = append(ids, id)
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		_, err = tx.Exec(
+			`
+				insert into webhook_deliveries(webhook_id, event, payload, status, error, next_attempt_at, created_at, updated_at)
+				values($1, $2, $3, $4, '', $5, $6, $7)
+			`,
+			id, event, string(payload), store.WebhookDeliveryPending, now, now, now,
+		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+const selectFromWebhookDeliveries = `
+	select id, webhook_id, event, payload, status, attempts, response_code, error, next_attempt_at, created_at, updated_at
+	from webhook_deliveries
+`
+
+func (s *webhookStore) scanDelivery(scanner scanner) (*store.WebhookDelivery, error) {
+	d := new(store.WebhookDelivery)
+	var payload string
+	err := scanner.Scan(
+		&d.ID, &d.WebhookID, &d.Event, &payload, &d.Status, &d.Attempts, &d.ResponseCode, &d.Error,
+		&d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = json.RawMessage(payload)
+	return d, nil
+}
+
+func (s *webhookStore) scanDeliveries(rows *sql.Rows) ([]*store.WebhookDelivery, error) {
+	deliveries := []*store.WebhookDelivery{}
+	for rows.Next() {
+		d, err := s.scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ClaimDue claims the pending deliveries whose next attempt is due, oldest first.
+// Rows locked by a concurrent claim are skipped.
+func (s *webhookStore) ClaimDue(now, lockedUntil time.Time, limit int) ([]*store.WebhookDelivery, error) {
+	rows, err := s.db.Query(
+		`
+			update webhook_deliveries
+			set locked_until=$1
+			where id in (
+				select id from webhook_deliveries
+				where status=$2 and next_attempt_at<=$3 and (locked_until is null or locked_until<=$3)
+				order by next_attempt_at, id limit $4
+				for update skip locked
+			)
+			returning id, webhook_id, event, payload, status, attempts, response_code, error, next_attempt_at, created_at, updated_at
+		`,
+		lockedUntil, store.WebhookDeliveryPending, now, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries, err := s.scanDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].NextAttemptAt.Equal(deliveries[j].NextAttemptAt) {
+			return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt)
+		}
+		return deliveries[i].ID < deliveries[j].ID
+	})
+	return deliveries, nil
+}
+
+// SetDeliveryResult records a delivery attempt.
+func (s *webhookStore) SetDeliveryResult(id int64, status string, responseCode int, errorMessage string, nextAttemptAt time.Time) error {
+	_, err := s.db.Exec(
+		`
+			update webhook_deliveries
+			set status=$1, attempts=attempts+1, response_code=$2, error=$3, next_attempt_at=$4, updated_at=$5, locked_until=null
+			where id=$6
+		`,
+		status, responseCode, errorMessage, nextAttemptAt, time.Now(), id,
+	)
+	return err
+}
+
+// GetDeliveries returns a limited number of the latest webhook deliveries
+// and a total count of the webhook deliveries.
+func (s *webhookStore) GetDeliveries(webhookID int64, offset, limit int) ([]*store.WebhookDelivery, int, error) {
+	var count int
+	err := s.db.QueryRow(`select count(*) from webhook_deliveries where webhook_id=$1`, webhookID).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if limit <= 0 || offset > count {
+		return []*store.WebhookDelivery{}, count, nil
+	}
+
+	rows, err := s.db.Query(
+		selectFromWebhookDeliveries+` where webhook_id=$1 order by id desc limit $2 offset $3`,
+		webhookID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries, err := s.scanDeliveries(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, count, nil
+}
@@ -0,0 +1,32 @@
+package store
+
+import (
+	"unicode/utf8"
+)
+
+// ReactionCount is the number of reactions with the same emoji on a comment.
+type ReactionCount struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+	// Reacted means the current user is one of the reactors.
+	Reacted bool `json:"reacted"`
+}
+
+const (
+	reactionEmojiMinLen = 1
+	reactionEmojiMaxLen = 32
+)
+
+// ValidReactionEmoji checks if reaction emoji name is valid.
+func ValidReactionEmoji(emoji string) bool {
+	if !utf8.ValidString(emoji) {
+		return false
+	}
+
+	length := utf8.RuneCountInString(emoji)
+	if !(reactionEmojiMinLen <= length && length <= reactionEmojiMaxLen) {
+		return false
+	}
+
+	return true
+}
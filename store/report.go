@@ -0,0 +1,64 @@
+package store
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// Report is a user complaint about a topic or a comment.
+type Report struct {
+	ID         int64  `json:"id"`
+	ReporterID int64  `json:"reporterId"`
+	TargetType string `json:"targetType"`
+	TargetID   int64  `json:"targetId"`
+	// AuthorID is the author of the reported content.
+	AuthorID  int64     `json:"authorId"`
+	Reason    string    `json:"reason"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	// ResolvedBy is the ID of the admin who resolved the report, zero if the report is open.
+	ResolvedBy int64      `json:"resolvedBy"`
+	ResolvedAt *time.Time `json:"resolvedAt"`
+}
+
+// Report target types.
+const (
+	ReportTargetTopic   = "topic"
+	ReportTargetComment = "comment"
+)
+
+// Report statuses. A resolved report status tells how it was resolved.
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusDismissed = "dismissed"
+	ReportStatusDeleted   = "deleted"
+	ReportStatusBlocked   = "blocked"
+)
+
+// ValidReportStatus checks if report status is valid.
+func ValidReportStatus(status string) bool {
+	switch status {
+	case ReportStatusOpen, ReportStatusDismissed, ReportStatusDeleted, ReportStatusBlocked:
+		return true
+	}
+	return false
+}
+
+const (
+	reportReasonMinLen = 1
+	reportReasonMaxLen = 1000
+)
+
+// ValidReportReason checks if report reason is valid.
+func ValidReportReason(reason string) bool {
+	if !utf8.ValidString(reason) {
+		return false
+	}
+
+	length := utf8.RuneCountInString(reason)
+	if !(reportReasonMinLen <= length && length <= reportReasonMaxLen) {
+		return false
+	}
+
+	return true
+}
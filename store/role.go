@@ -0,0 +1,95 @@
+package store
+
+// Permissions allow users to act on the content and the users they don't own.
+const (
+	PermTopicEdit          = "topic.edit"
+	PermTopicDelete        = "topic.delete"
+	PermTopicMove          = "topic.move"
+	PermTopicPin           = "topic.pin"
+	PermTopicLock          = "topic.lock"
+	PermTopicPostAdminOnly = "topic.post_admin_only"
+	PermCommentEdit        = "comment.edit"
+	PermCommentDelete      = "comment.delete"
+	PermCommentLockedTopic = "comment.locked_topic"
+	PermUserView           = "user.view"
+	PermUserRename         = "user.rename"
+	PermUserAvatar         = "user.avatar"
+	PermUserBlock          = "user.block"
+	PermCategoryManage     = "category.manage"
+	PermReportManage       = "report.manage"
+	PermAuditRead          = "audit.read"
+	PermWebhookManage      = "webhook.manage"
+)
+
+// User roles.
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+)
+
+// AllPermissions lists the permissions the roles can grant.
+var AllPermissions = []string{
+	PermTopicEdit,
+	PermTopicDelete,
+	PermTopicMove,
+	PermTopicPin,
+	PermTopicLock,
+	PermTopicPostAdminOnly,
+	PermCommentEdit,
+	PermCommentDelete,
+	PermCommentLockedTopic,
+	PermUserView,
+	PermUserRename,
+	PermUserAvatar,
+	PermUserBlock,
+	PermCategoryManage,
+	PermReportManage,
+	PermAuditRead,
+	PermWebhookManage,
+}
+
+// DefaultRolePermissions are the permissions the roles are granted by the schema
+// migrations. The memory store starts with them. The grants are kept in the store
+// and can be changed with "bebop role add-permission|remove-permission".
+var DefaultRolePermissions = map[string][]string{
+	RoleAdmin: AllPermissions,
+	RoleModerator: {
+		PermTopicDelete,
+		PermTopicMove,
+		PermTopicPin,
+		PermTopicLock,
+		PermCommentDelete,
+		PermCommentLockedTopic,
+		PermUserView,
+		PermReportManage,
+	},
+}
+
+const roleMaxLen = 50
+
+// ValidRole checks if the role name is valid: lowercase latin letters,
+// digits, underscores and hyphens, starting with a letter.
+func ValidRole(role string) bool {
+	if role == "" || len(role) > roleMaxLen {
+		return false
+	}
+	for i, r := range role {
+		switch {
+		case 'a' <= r && r <= 'z':
+		case i > 0 && ('0' <= r && r <= '9' || r == '_' || r == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ValidPermission checks if the permission is one of AllPermissions.
+func ValidPermission(permission string) bool {
+	for _, p := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	searchQueryMaxLen   = 100
+	searchQueryMaxTerms = 10
+)
+
+// ValidSearchQuery checks if search query is valid.
+func ValidSearchQuery(query string) bool {
+	if !utf8.ValidString(query) {
+		return false
+	}
+
+	if utf8.RuneCountInString(query) > searchQueryMaxLen {
+		return false
+	}
+
+	return len(SearchTerms(query)) > 0
+}
+
+// SearchTerms splits a search query into unique lowercase terms.
+func SearchTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+		if len(terms) == searchQueryMaxTerms {
+			break
+		}
+	}
+	return terms
+}
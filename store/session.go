@@ -0,0 +1,15 @@
+package store
+
+import (
+	"time"
+)
+
+// Session is a server-side login session. The client holds the session
+// refresh token, the store keeps only its hash.
+type Session struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"userId"`
+	TokenHash string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type categoryStore struct {
+	db *sql.DB
+}
+
+// New creates a new category. It returns ErrConflict if the given slug is already taken.
+func (s *categoryStore) New(slug, name, description string, sortOrder int, adminOnly bool) (int64, error) {
+	res, err := s.db.Exec(
+		`
+			insert into categories(slug, name, description, sort_order, admin_only)
+			values(?, ?, ?, ?, ?)
+		`,
+		slug, name, description, sortOrder, adminOnly,
+	)
+	if isUniqueConstraintError(err) {
+		return 0, store.ErrConflict
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+const selectFromCategories = `select id, slug, name, description, sort_order, admin_only from categories`
+
+func (s *categoryStore) scanCategory(scanner scanner) (*store.Category, error) {
+	c := new(store.Category)
+	err := scanner.Scan(&c.ID, &c.Slug, &c.Name, &c.Description, &c.SortOrder, &c.AdminOnly)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Get finds a category by ID.
+func (s *categoryStore) Get(id int64) (*store.Category, error) {
+	row := s.db.QueryRow(selectFromCategories+` where id=?`, id)
+	return s.scanCategory(row)
+}
+
+// GetBySlug finds a category by slug.
+func (s *categoryStore) GetBySlug(slug string) (*store.Category, error) {
+	row := s.db.QueryRow(selectFromCategories+` where slug=?`, slug)
+	return s.scanCategory(row)
+}
+
+// GetAll returns all the categories ordered by sort order.
+func (s *categoryStore) GetAll() ([]*store.Category, error) {
+	rows, err := s.db.Query(selectFromCategories + ` order by sort_order, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []*store.Category{}
+	for rows.Next() {
+		category, err := s.scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// Update saves all the category fields. It returns ErrConflict if the new slug is already taken.
+func (s *categoryStore) Update(category *store.Category) error {
+	res, err := s.db.Exec(
+		`update categories set slug=?, name=?, description=?, sort_order=?, admin_only=? where id=?`,
+		category.Slug, category.Name, category.Description, category.SortOrder, category.AdminOnly, category.ID,
+	)
+	if isUniqueConstraintError(err) {
+		return store.ErrConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a category. It returns ErrConflict if the category still has topics.
+// Deleted topics are moved out of the category.
+func (s *categoryStore) Delete(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var count int
+	err = tx.QueryRow(`select count(*) from topics where deleted=false and category_id=?`, id).Scan(&count)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if count > 0 {
+		tx.Rollback()
+		return store.ErrConflict
+	}
+
+	_, err = tx.Exec(`update topics set category_id=null where category_id=?`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`delete from categories where id=?`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,340 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type commentStore struct {
+	db *sql.DB
+}
+
+// New creates a new comment.
+func (s *commentStore) New(topicID int64, authorID int64, content string, mentionIDs []int64) (int64, error) {
+	now := utcNow()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.Exec(
+		`insert into comments(topic_id, author_id, content, created_at, updated_at) values (?, ?, ?, ?, ?)`,
+		topicID, authorID, content, now, now,
+	)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	_, err = tx.Exec(`update topics set last_comment_at=?, comment_count=comment_count+1 where id=?`, now, topicID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	var topicAuthorID int64
+	err = tx.QueryRow(`select author_id from topics where id=?`, topicID).Scan(&topicAuthorID)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return 0, store.ErrNotFound
+		}
+		return 0, err
+	}
+
+	for _, n := range store.CommentNotifications(topicID, topicAuthorID, id, authorID, mentionIDs) {
+		_, err = tx.Exec(
+			`insert into notifications(user_id, type, topic_id, comment_id, actor_id, is_read, created_at) values (?, ?, ?, ?, ?, ?, ?)`,
+			n.UserID, n.Type, n.TopicID, n.CommentID, n.ActorID, false, now,
+		)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	_, err = tx.Exec(
+		`
+		insert into digest_queue(user_id, comment_id)
+		select w.user_id, ? from topic_watches w
+		join digest_settings d on d.user_id=w.user_id
+		where w.topic_id=? and w.user_id<>? and d.mode<>?
+		`,
+		id, topicID, authorID, store.DigestOff,
+	)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+const selectFromComments = `select id, topic_id, author_id, content, created_at, updated_at, edit_count from comments`
+
+func (s *commentStore) scanComment(scanner scanner) (*store.Comment, error) {
+	c := new(store.Comment)
+	err := scanner.Scan(&c.ID, &c.TopicID, &c.AuthorID, &c.Content, &c.CreatedAt, &c.UpdatedAt, &c.EditCount)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Get finds a comment by ID.
+func (s *commentStore) Get(id int64) (*store.Comment, error) {
+	row := s.db.QueryRow(selectFromComments+` where deleted=false and id=?`, id)
+	return s.scanComment(row)
+}
+
+// GetByTopic finds comments by topic.
+func (s *commentStore) GetByTopic(topicID int64, offset, limit int) ([]*store.Comment, int, error) {
+	var count int
+	err := s.db.QueryRow(`select count(*) from comments where deleted=false and topic_id=?`, topicID).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if limit <= 0 || offset > count {
+		return []*store.Comment{}, count, nil
+	}
+
+	rows, err := s.db.Query(
+		selectFromComments+` where deleted=false and topic_id=? order by created_at, id limit ? offset ?`,
+		topicID,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	comments := []*store.Comment{}
+	for rows.Next() {
+		comment, err := s.scanComment(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		comments = append(comments, comment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return comments, count, nil
+}
+
+// GetByTopicAfter finds a limited number of comments by topic following the cursor.
+func (s *commentStore) GetByTopicAfter(topicID int64, cursor *store.CommentCursor, limit int) ([]*store.Comment, error) {
+	if limit <= 0 {
+		return []*store.Comment{}, nil
+	}
+
+	var rows *sql.Rows
+	var err error
+	if cursor == nil {
+		rows, err = s.db.Query(
+			selectFromComments+` where deleted=false and topic_id=? order by created_at, id limit ?`,
+			topicID,
+			limit,
+		)
+	} else {
+		rows, err = s.db.Query(
+			selectFromComments+` where deleted=false and topic_id=? and (created_at > ? or (created_at = ? and id > ?)) order by created_at, id limit ?`,
+			topicID,
+			cursor.CreatedAt.UTC(),
+			cursor.CreatedAt.UTC(),
+			cursor.ID,
+			limit,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []*store.Comment{}
+	for rows.Next() {
+		comment, err := s.scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// Search finds comments having all the query terms in the content.
+// SQLite has no full-text index enabled by default, so the results are ordered by date.
+// Comments of deleted topics are excluded.
+func (s *commentStore) Search(query string, offset, limit int) ([]*store.Comment, int, error) {
+	match, args := searchCondition("content", query)
+	match = `deleted=false and topic_id in (select id from topics where deleted=false) and ` + match
+
+	var count int
+	err := s.db.QueryRow(`select count(*) from comments where `+match, args...).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if limit <= 0 || offset > count {
+		return []*store.Comment{}, count, nil
+	}
+
+	rows, err := s.db.Query(
+		selectFromComments+` where `+match+` order by created_at desc, id desc limit ? offset ?`,
+		append(args, limit, offset)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	comments := []*store.Comment{}
+	for rows.Next() {
+		comment, err := s.scanComment(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		comments = append(comments, comment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return comments, count, nil
+}
+
+// GetRevisions returns the edit history of a comment, oldest first.
+func (s *commentStore) GetRevisions(id int64) ([]*store.CommentRevision, error) {
+	rows, err := s.db.Query(
+		`select id, comment_id, editor_id, content, created_at from comment_revisions where comment_id=? order by created_at, id`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*store.CommentRevision{}
+	for rows.Next() {
+		r := new(store.CommentRevision)
+		err := rows.Scan(&r.ID, &r.CommentID, &r.EditorID, &r.Content, &r.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// SetContent updates comment.Content value and saves the edit to the comment revisions.
+func (s *commentStore) SetContent(id int64, editorID int64, content string) error {
+	now := utcNow()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// Keep the original content as the first revision.
+	_, err = tx.Exec(
+		`
+		insert into comment_revisions(comment_id, editor_id, content, created_at)
+		select id, author_id, content, created_at from comments where id=? and edit_count=0
+		`,
+		id,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(
+		`insert into comment_revisions(comment_id, editor_id, content, created_at) values (?, ?, ?, ?)`,
+		id, editorID, content, now,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(
+		`update comments set content=?, updated_at=?, edit_count=edit_count+1 where id=?`,
+		content, now, id,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// Delete soft-deletes a comment.
+func (s *commentStore) Delete(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`update comments set deleted=true where id=?`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(
+		`
+		update topics set
+			last_comment_at=(select max(created_at) from comments c where c.topic_id=topics.id and c.deleted=false),
+			comment_count=comment_count-1
+		where id=(select topic_id from comments where id = ?)
+		`,
+		id,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package sqlite
+
+import (
+	"testing"
+)
+
+func TestComment(t *testing.T) {
+	s, teardown := getTestStore(t)
+	defer teardown()
+
+	u1, err := s.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+	u2, err := s.Users().New("service2", "uid2")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+
+	t1, err := s.Topics().New(u1, 0, "topic1")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+	t2, err := s.Topics().New(u2, 0, "topic2")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+
+	c1, err := s.Comments().New(t1, u1, "comment1", nil)
+	if err != nil {
+		t.Fatalf("failed to create a comment: %s", err)
+	}
+	c2, err := s.Comments().New(t1, u2, "comment2", nil)
+	if err != nil {
+		t.Fatalf("failed to create a comment: %s", err)
+	}
+	c3, err := s.Comments().New(t2, u1, "comment3", nil)
+	if err != nil {
+		t.Fatalf("failed to create a comment: %s", err)
+	}
+	c4, err := s.Comments().New(t2, u2, "comment4 日本 Доброе утро", nil)
+	if err != nil {
+		t.Fatalf("failed to create a comment: %s", err)
+	}
+
+	topic1, err := s.Topics().Get(t1)
+	if err != nil {
+		t.Fatalf("failed to get a topic: %s", err)
+	}
+	if topic1.CommentCount != 2 {
+		t.Fatalf("bad topic1.CommentCount: %d", topic1.CommentCount)
+	}
+
+	err = s.Comments().Delete(c2)
+	if err != nil {
+		t.Fatalf("failed to delete a comment: %s", err)
+	}
+
+	_, err = s.Comments().Get(c2)
+	if err == nil {
+		t.Fatal("expected error getting deleted comment")
+	}
+
+	topic1, err = s.Topics().Get(t1)
+	if err != nil {
+		t.Fatalf("failed to get a topic: %s", err)
+	}
+	if topic1.CommentCount != 1 {
+		t.Fatalf("bad topic1.CommentCount: %d", topic1.CommentCount)
+	}
+
+	comment1, err := s.Comments().Get(c1)
+	if err != nil {
+		t.Fatalf("failed to get a comment: %s", err)
+	}
+	if comment1.Content != "comment1" {
+		t.Fatalf("bad comment content: %s", comment1.Content)
+	}
+
+	if comment1.EditCount != 0 {
+		t.Fatalf("bad comment edit count: %d", comment1.EditCount)
+	}
+
+	revisions, err := s.Comments().GetRevisions(c1)
+	if err != nil {
+		t.Fatalf("failed to get comment revisions: %s", err)
+	}
+	if len(revisions) != 0 {
+		t.Fatalf("bad revisions len: %d", len(revisions))
+	}
+
+	err = s.Comments().SetContent(c1, u2, "new content")
+	if err != nil {
+		t.Fatalf("failed to SetContent: %s", err)
+	}
+
+	comment1, err = s.Comments().Get(c1)
+	if err != nil {
+		t.Fatalf("failed to get a comment: %s", err)
+	}
+	if comment1.Content != "new content" {
+		t.Fatalf("bad comment content: %s", comment1.Content)
+	}
+	if comment1.EditCount != 1 {
+		t.Fatalf("bad comment edit count: %d", comment1.EditCount)
+	}
+	if comment1.UpdatedAt.Before(comment1.CreatedAt) {
+		t.Fatalf("bad comment updatedAt: %v < %v", comment1.UpdatedAt, comment1.CreatedAt)
+	}
+
+	err = s.Comments().SetContent(c1, u1, "newer content")
+	if err != nil {
+		t.Fatalf("failed to SetContent: %s", err)
+	}
+
+	revisions, err = s.Comments().GetRevisions(c1)
+	if err != nil {
+		t.Fatalf("failed to get comment revisions: %s", err)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("bad revisions len: %d", len(revisions))
+	}
+	wantRevisions := []struct {
+		editorID int64
+		content  string
+	}{
+		{u1, "comment1"},
+		{u2, "new content"},
+		{u1, "newer content"},
+	}
+	for i, want := range wantRevisions {
+		got := revisions[i]
+		if got.CommentID != c1 || got.EditorID != want.editorID || got.Content != want.content {
+			t.Fatalf("bad revision %d: got (%d, %d, %q) want (%d, %d, %q)", i, got.CommentID, got.EditorID, got.Content, c1, want.editorID, want.content)
+		}
+	}
+
+	comments, count, err := s.Comments().GetByTopic(c2, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get comments by topic: %s", err)
+	}
+
+	if len(comments) != 2 {
+		t.Fatalf("bad comments len: %d", len(comments))
+	}
+	if count != 2 {
+		t.Fatalf("bad comment count: %d", count)
+	}
+	if comments[0].ID != c3 || comments[1].ID != c4 {
+		t.Fatalf("bad comment ids: got (%d, %d) want (%d, %d)", comments[0].ID, comments[1].ID, c3, c4)
+	}
+
+	comments, count, err = s.Comments().GetByTopic(c2, 10, 1)
+	if err != nil {
+		t.Fatalf("failed to get comments by topic: %s", err)
+	}
+
+	if len(comments) != 0 {
+		t.Fatalf("bad comments len: %d", len(comments))
+	}
+	if count != 2 {
+		t.Fatalf("bad comment count: %d", count)
+	}
+}
@@ -0,0 +1,163 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type digestStore struct {
+	db *sql.DB
+}
+
+// GetMode returns the user digest mode, DigestOff if it is not set.
+func (s *digestStore) GetMode(userID int64) (string, error) {
+	var mode string
+	err := s.db.QueryRow(`select mode from digest_settings where user_id=?`, userID).Scan(&mode)
+	if err == sql.ErrNoRows {
+		return store.DigestOff, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return mode, nil
+}
+
+// SetMode sets the user digest mode. The DigestOff mode clears the user digest queue.
+func (s *digestStore) SetMode(userID int64, mode string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var n int
+	err = tx.QueryRow(`select count(*) from digest_settings where user_id=?`, userID).Scan(&n)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if n == 0 {
+		_, err = tx.Exec(`insert into digest_settings(user_id, mode) values(?, ?)`, userID, mode)
+	} else {
+		_, err = tx.Exec(`update digest_settings set mode=? where user_id=?`, mode, userID)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if mode == store.DigestOff {
+		_, err = tx.Exec(`delete from digest_queue where user_id=?`, userID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// queuedComments selects the queued comments that are not deleted.
+const queuedComments = `
+	from digest_queue q
+	join comments c on c.id=q.comment_id
+	join topics t on t.id=c.topic_id
+	join users u on u.id=c.author_id
+	where c.deleted=false and t.deleted=false
+`
+
+// GetDueUsers returns the IDs of the users whose digest is due, in order.
+func (s *digestStore) GetDueUsers(dailySentBefore time.Time) ([]int64, error) {
+	rows, err := s.db.Query(
+		`
+		select d.user_id from digest_settings d
+		where (d.mode=? or (d.mode=? and (d.last_sent_at is null or d.last_sent_at<?)))
+		and exists (select 1`+queuedComments+` and q.user_id=d.user_id)
+		order by d.user_id
+		`,
+		store.DigestImmediate, store.DigestDaily, dailySentBefore.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	userIDs := []int64{}
+	for rows.Next() {
+		var id int64
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return userIDs, nil
+}
+
+// GetQueued returns the comments queued for the user digest in order.
+func (s *digestStore) GetQueued(userID int64) ([]*store.DigestComment, error) {
+	rows, err := s.db.Query(
+		`select c.id, c.topic_id, t.title, c.author_id, coalesce(u.name, ''), c.content, c.created_at`+
+			queuedComments+` and q.user_id=? order by c.id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []*store.DigestComment{}
+	for rows.Next() {
+		c := new(store.DigestComment)
+		err := rows.Scan(&c.CommentID, &c.TopicID, &c.TopicTitle, &c.AuthorID, &c.AuthorName, &c.Content, &c.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// MarkSent removes the comments up to lastCommentID from the user digest queue.
+func (s *digestStore) MarkSent(userID int64, lastCommentID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`delete from digest_queue where user_id=? and comment_id<=?`, userID, lastCommentID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`update digest_settings set last_sent_at=? where user_id=?`, utcNow(), userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
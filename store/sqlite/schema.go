@@ -0,0 +1,510 @@
+package sqlite
+
+import (
+	"github.com/disintegration/bebop/store"
+)
+
+// migrations is the list of database schema migrations.
+// Never change a migration once it has been released: add a new one instead.
+var migrations = []*store.Migration{
+	{
+		Version: 1,
+		Name:    "initial schema",
+		Up: []string{
+			`
+				create table if not exists users (
+					id            integer    not null primary key autoincrement,
+					name          text       default null,
+					created_at    timestamp  not null,
+					auth_service  text       not null,
+					auth_id       text       not null,
+					blocked       boolean    not null default false,
+					admin         boolean    not null default false,
+					avatar        text       not null default ''
+				)
+			`,
+			`create unique index if not exists users_name on users(lower(name))`,
+			`create unique index if not exists users_auth on users(auth_service, auth_id)`,
+			`
+				create table if not exists topics (
+					id               integer    not null primary key autoincrement,
+					author_id        integer    not null references users(id),
+					title            text       not null,
+					created_at       timestamp  not null,
+					last_comment_at  timestamp  not null,
+					deleted          boolean    not null default false,
+					comment_count    integer    not null default 0
+				)
+			`,
+			`create index if not exists topics_last_comment_at on topics(last_comment_at)`,
+			`
+				create table if not exists comments (
+					id          integer    not null primary key autoincrement,
+					topic_id    integer    not null references topics(id),
+					author_id   integer    not null references users(id),
+					content     text       not null,
+					created_at  timestamp  not null,
+					deleted     boolean    not null default false
+				)
+			`,
+			`create index if not exists comments_topic_id on comments(topic_id)`,
+			`create index if not exists comments_created_at on comments(created_at)`,
+		},
+		Down: []string{
+			`drop table if exists comments`,
+			`drop table if exists topics`,
+			`drop table if exists users`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "edit history",
+		Up: []string{
+			// sqlite requires a non-null default value to add a "not null" column.
+			`alter table comments add column updated_at timestamp not null default ''`,
+			`update comments set updated_at = created_at`,
+			`alter table comments add column edit_count integer not null default 0`,
+			`
+				create table if not exists topic_revisions (
+					id          integer    not null primary key autoincrement,
+					topic_id    integer    not null references topics(id),
+					editor_id   integer    not null references users(id),
+					title       text       not null,
+					created_at  timestamp  not null
+				)
+			`,
+			`create index if not exists topic_revisions_topic_id on topic_revisions(topic_id)`,
+			`
+				create table if not exists comment_revisions (
+					id          integer    not null primary key autoincrement,
+					comment_id  integer    not null references comments(id),
+					editor_id   integer    not null references users(id),
+					content     text       not null,
+					created_at  timestamp  not null
+				)
+			`,
+			`create index if not exists comment_revisions_comment_id on comment_revisions(comment_id)`,
+		},
+		Down: []string{
+			`drop table if exists comment_revisions`,
+			`drop table if exists topic_revisions`,
+			`alter table comments drop column edit_count`,
+			`alter table comments drop column updated_at`,
+		},
+	},
+	{
+		Version: 3,
+		Name:    "categories",
+		Up: []string{
+			`
+				create table if not exists categories (
+					id           integer  not null primary key autoincrement,
+					slug         text     not null,
+					name         text     not null,
+					description  text     not null default '',
+					sort_order   integer  not null default 0,
+					admin_only   boolean  not null default false
+				)
+			`,
+			`create unique index if not exists categories_slug on categories(slug)`,
+			// No foreign key here: sqlite cannot drop a column used in a foreign key constraint.
+			`alter table topics add column category_id integer default null`,
+			`create index if not exists topics_category_id_last_comment_at on topics(category_id, last_comment_at)`,
+		},
+		Down: []string{
+			`drop index if exists topics_category_id_last_comment_at`,
+			`alter table topics drop column category_id`,
+			`drop table if exists categories`,
+		},
+	},
+	{
+		Version: 4,
+		Name:    "pinned and locked topics",
+		Up: []string{
+			`alter table topics add column pinned boolean not null default false`,
+			`alter table topics add column locked boolean not null default false`,
+			`create index if not exists topics_pinned_last_comment_at on topics(pinned, last_comment_at)`,
+		},
+		Down: []string{
+			`drop index if exists topics_pinned_last_comment_at`,
+			`alter table topics drop column locked`,
+			`alter table topics drop column pinned`,
+		},
+	},
+	{
+		Version: 5,
+		Name:    "comment pagination index",
+		Up: []string{
+			`create index if not exists comments_topic_id_created_at on comments(topic_id, created_at, id)`,
+		},
+		Down: []string{
+			`drop index if exists comments_topic_id_created_at`,
+		},
+	},
+	{
+		Version: 6,
+		Name:    "reactions",
+		Up: []string{
+			`
+				create table if not exists reactions (
+					comment_id  integer    not null references comments(id),
+					user_id     integer    not null references users(id),
+					emoji       text       not null,
+					created_at  timestamp  not null,
+
+					primary key (comment_id, user_id, emoji)
+				)
+			`,
+		},
+		Down: []string{
+			`drop table if exists reactions`,
+		},
+	},
+	{
+		Version: 7,
+		Name:    "reports",
+		Up: []string{
+			`
+				create table if not exists reports (
+					id           integer    not null primary key autoincrement,
+					reporter_id  integer    not null references users(id),
+					target_type  text       not null,
+					target_id    integer    not null,
+					author_id    integer    not null references users(id),
+					reason       text       not null,
+					status       text       not null default 'open',
+					created_at   timestamp  not null,
+					resolved_by  integer    default null references users(id),
+					resolved_at  timestamp  default null
+				)
+			`,
+			`create index if not exists reports_status_created_at on reports(status, created_at)`,
+			`create index if not exists reports_target_type_target_id on reports(target_type, target_id)`,
+		},
+		Down: []string{
+			`drop table if exists reports`,
+		},
+	},
+	{
+		Version: 8,
+		Name:    "audit log",
+		Up: []string{
+			`
+				create table if not exists audit_log (
+					id           integer    not null primary key autoincrement,
+					actor_id     integer    not null,
+					action       text       not null,
+					target_type  text       not null,
+					target_id    integer    not null,
+					before_json  text       not null,
+					after_json   text       not null,
+					ip           text       not null,
+					created_at   timestamp  not null
+				)
+			`,
+			`create index if not exists audit_log_created_at on audit_log(created_at)`,
+			`create index if not exists audit_log_actor_id on audit_log(actor_id)`,
+			`create index if not exists audit_log_target_type_target_id on audit_log(target_type, target_id)`,
+		},
+		Down: []string{
+			`drop table if exists audit_log`,
+		},
+	},
+	{
+		Version: 9,
+		Name:    "sessions",
+		Up: []string{
+			`alter table users add column tokens_valid_after timestamp default null`,
+			`
+				create table if not exists sessions (
+					id          integer    not null primary key autoincrement,
+					user_id     integer    not null references users(id),
+					token_hash  text       not null,
+					created_at  timestamp  not null,
+					expires_at  timestamp  not null
+				)
+			`,
+			`create unique index if not exists sessions_token_hash on sessions(token_hash)`,
+			`create index if not exists sessions_user_id on sessions(user_id)`,
+		},
+		Down: []string{
+			`drop table if exists sessions`,
+			`alter table users drop column tokens_valid_after`,
+		},
+	},
+	{
+		Version: 10,
+		Name:    "local accounts",
+		Up: []string{
+			`
+				create table if not exists local_accounts (
+					user_id        integer    not null primary key references users(id),
+					email          text       not null,
+					password_hash  text       not null,
+					verified       boolean    not null default false,
+					created_at     timestamp  not null
+				)
+			`,
+			`create unique index if not exists local_accounts_email on local_accounts(email)`,
+			`
+				create table if not exists auth_tokens (
+					id          integer    not null primary key autoincrement,
+					user_id     integer    not null references users(id),
+					purpose     text       not null,
+					token_hash  text       not null,
+					created_at  timestamp  not null,
+					expires_at  timestamp  not null
+				)
+			`,
+			`create unique index if not exists auth_tokens_token_hash on auth_tokens(token_hash)`,
+			`create index if not exists auth_tokens_user_id on auth_tokens(user_id)`,
+		},
+		Down: []string{
+			`drop table if exists auth_tokens`,
+			`drop table if exists local_accounts`,
+		},
+	},
+	{
+		Version: 11,
+		Name:    "identities",
+		Up: []string{
+			`
+				create table if not exists identities (
+					id            integer    not null primary key autoincrement,
+					user_id       integer    not null references users(id),
+					auth_service  text       not null,
+					auth_id       text       not null,
+					created_at    timestamp  not null
+				)
+			`,
+			`create unique index if not exists identities_auth on identities(auth_service, auth_id)`,
+			`create index if not exists identities_user_id on identities(user_id)`,
+			`
+				insert into identities(user_id, auth_service, auth_id, created_at)
+				select id, auth_service, auth_id, created_at from users
+			`,
+			// The users keep the identity they signed up with, but it can be unlinked
+			// and then used by another user.
+			`drop index if exists users_auth`,
+		},
+		Down: []string{
+			`create unique index if not exists users_auth on users(auth_service, auth_id)`,
+			`drop table if exists identities`,
+		},
+	},
+	{
+		Version: 12,
+		Name:    "identity profiles",
+		Up: []string{
+			`alter table identities add column display_name text not null default ''`,
+			`alter table identities add column picture text not null default ''`,
+		},
+		Down: []string{
+			`alter table identities drop column picture`,
+			`alter table identities drop column display_name`,
+		},
+	},
+	{
+		Version: 13,
+		Name:    "user roles",
+		Up: []string{
+			`
+				create table if not exists user_roles (
+					user_id     integer    not null references users(id),
+					role        text       not null,
+					created_at  timestamp  not null,
+
+					primary key (user_id, role)
+				)
+			`,
+			`create index if not exists user_roles_role on user_roles(role)`,
+			`
+				insert into user_roles(user_id, role, created_at)
+				select id, 'admin', current_timestamp from users where admin=true
+			`,
+			`alter table users drop column admin`,
+		},
+		Down: []string{
+			`alter table users add column admin boolean not null default false`,
+			`update users set admin=true where id in (select user_id from user_roles where role='admin')`,
+			`drop table if exists user_roles`,
+		},
+	},
+	{
+		Version: 14,
+		Name:    "notifications",
+		Up: []string{
+			`
+				create table if not exists notifications (
+					id          integer    not null primary key autoincrement,
+					user_id     integer    not null references users(id),
+					type        text       not null,
+					topic_id    integer    not null references topics(id),
+					comment_id  integer    not null references comments(id),
+					actor_id    integer    not null references users(id),
+					is_read     boolean    not null default false,
+					created_at  timestamp  not null
+				)
+			`,
+			`create index if not exists notifications_user_id on notifications(user_id, id)`,
+		},
+		Down: []string{
+			`drop table if exists notifications`,
+		},
+	},
+	{
+		Version: 15,
+		Name:    "topic watches and email digests",
+		Up: []string{
+			`
+				create table if not exists topic_watches (
+					user_id     integer    not null references users(id),
+					topic_id    integer    not null references topics(id),
+					created_at  timestamp  not null,
+
+					primary key (user_id, topic_id)
+				)
+			`,
+			`create index if not exists topic_watches_topic_id on topic_watches(topic_id)`,
+			`
+				create table if not exists digest_settings (
+					user_id       integer    not null primary key references users(id),
+					mode          text       not null,
+					last_sent_at  timestamp  default null
+				)
+			`,
+			`
+				create table if not exists digest_queue (
+					user_id     integer  not null references users(id),
+					comment_id  integer  not null references comments(id),
+
+					primary key (user_id, comment_id)
+				)
+			`,
+		},
+		Down: []string{
+			`drop table if exists digest_queue`,
+			`drop table if exists digest_settings`,
+			`drop table if exists topic_watches`,
+		},
+	},
+	{
+		Version: 16,
+		Name:    "webhooks",
+		Up: []string{
+			`
+				create table if not exists webhooks (
+					id          integer    not null primary key autoincrement,
+					url         text       not null,
+					secret      text       not null,
+					events      text       not null,
+					created_at  timestamp  not null
+				)
+			`,
+			`
+				create table if not exists webhook_deliveries (
+					id               integer    not null primary key autoincrement,
+					webhook_id       integer    not null references webhooks(id),
+					event            text       not null,
+					payload          text       not null,
+					status           text       not null,
+					attempts         integer    not null default 0,
+					response_code    integer    not null default 0,
+					error            text       not null default '',
+					next_attempt_at  timestamp  not null,
+					created_at       timestamp  not null,
+					updated_at       timestamp  not null
+				)
+			`,
+			`create index if not exists webhook_deliveries_status on webhook_deliveries(status, next_attempt_at)`,
+			`create index if not exists webhook_deliveries_webhook_id on webhook_deliveries(webhook_id, id)`,
+		},
+		Down: []string{
+			`drop table if exists webhook_deliveries`,
+			`drop table if exists webhooks`,
+		},
+	},
+	{
+		Version: 17,
+		Name:    "webhook delivery locks",
+		Up: []string{
+			`alter table webhook_deliveries add column locked_until timestamp default null`,
+		},
+		Down: []string{
+			`alter table webhook_deliveries drop column locked_until`,
+		},
+	},
+	{
+		Version: 18,
+		Name:    "role permissions",
+		Up: []string{
+			`
+				create table if not exists role_permissions (
+					role        text       not null,
+					permission  text       not null,
+					created_at  timestamp  not null,
+
+					primary key (role, permission)
+				)
+			`,
+			`
+				insert into role_permissions(role, permission, created_at) values
+				('admin', 'topic.edit', current_timestamp),
+				('admin', 'topic.delete', current_timestamp),
+				('admin', 'topic.move', current_timestamp),
+				('admin', 'topic.pin', current_timestamp),
+				('admin', 'topic.lock', current_timestamp),
+				('admin', 'topic.post_admin_only', current_timestamp),
+				('admin', 'comment.edit', current_timestamp),
+				('admin', 'comment.delete', current_timestamp),
+				('admin', 'comment.locked_topic', current_timestamp),
+				('admin', 'user.view', current_timestamp),
+				('admin', 'user.rename', current_timestamp),
+				('admin', 'user.avatar', current_timestamp),
+				('admin', 'user.block', current_timestamp),
+				('admin', 'category.manage', current_timestamp),
+				('admin', 'report.manage', current_timestamp),
+				('admin', 'audit.read', current_timestamp),
+				('admin', 'webhook.manage', current_timestamp),
+				('moderator', 'topic.delete', current_timestamp),
+				('moderator', 'topic.move', current_timestamp),
+				('moderator', 'topic.pin', current_timestamp),
+				('moderator', 'topic.lock', current_timestamp),
+				('moderator', 'comment.delete', current_timestamp),
+				('moderator', 'comment.locked_topic', current_timestamp),
+				('moderator', 'user.view', current_timestamp),
+				('moderator', 'report.manage', current_timestamp)
+			`,
+		},
+		Down: []string{
+			`drop table if exists role_permissions`,
+		},
+	},
+}
+
+// Tables are dropped in reverse dependency order
+// because sqlite does not support "drop table ... cascade".
+var drop = []string{
+	`drop table if exists role_permissions`,
+	`drop table if exists webhook_deliveries`,
+	`drop table if exists webhooks`,
+	`drop table if exists digest_queue`,
+	`drop table if exists digest_settings`,
+	`drop table if exists topic_watches`,
+	`drop table if exists notifications`,
+	`drop table if exists user_roles`,
+	`drop table if exists identities`,
+	`drop table if exists auth_tokens`,
+	`drop table if exists local_accounts`,
+	`drop table if exists sessions`,
+	`drop table if exists audit_log`,
+	`drop table if exists reports`,
+	`drop table if exists reactions`,
+	`drop table if exists comment_revisions`,
+	`drop table if exists topic_revisions`,
+	`drop table if exists comments`,
+	`drop table if exists topics`,
+	`drop table if exists categories`,
+	`drop table if exists users`,
+	`drop table if exists schema_migrations`,
+}
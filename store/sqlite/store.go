@@ -0,0 +1,242 @@
+// Package sqlite provides an SQLite implementation of the bebop data store interface.
+package sqlite
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/disintegration/bebop/store"
+)
+
+// Store is an sqlite implementation of store.
+type Store struct {
+	db            *sql.DB
+	userStore     *userStore
+	topicStore    *topicStore
+	commentStore  *commentStore
+	categoryStore *categoryStore
+	reactionStore *reactionStore
+	reportStore   *reportStore
+	auditStore    *auditStore
+	sessionStore  *sessionStore
+	localStore    *localAccountStore
+	tokenStore    *authTokenStore
+	identityStore *identityStore
+	roleStore     *roleStore
+	notifyStore   *notificationStore
+	watchStore    *watchStore
+	digestStore   *digestStore
+	webhookStore  *webhookStore
+}
+
+// Users returns a user store.
+func (s *Store) Users() store.UserStore {
+	return s.userStore
+}
+
+// Topics returns a topic store.
+func (s *Store) Topics() store.TopicStore {
+	return s.topicStore
+}
+
+// Comments returns a comment store.
+func (s *Store) Comments() store.CommentStore {
+	return s.commentStore
+}
+
+// Categories returns a category store.
+func (s *Store) Categories() store.CategoryStore {
+	return s.categoryStore
+}
+
+// Reactions returns a reaction store.
+func (s *Store) Reactions() store.ReactionStore {
+	return s.reactionStore
+}
+
+// Reports returns a report store.
+func (s *Store) Reports() store.ReportStore {
+	return s.reportStore
+}
+
+// Audit returns an audit log store.
+func (s *Store) Audit() store.AuditStore {
+	return s.auditStore
+}
+
+// Sessions returns a login session store.
+func (s *Store) Sessions() store.SessionStore {
+	return s.sessionStore
+}
+
+// LocalAccounts returns a local account store.
+func (s *Store) LocalAccounts() store.LocalAccountStore {
+	return s.localStore
+}
+
+// AuthTokens returns a single-use auth token store.
+func (s *Store) AuthTokens() store.AuthTokenStore {
+	return s.tokenStore
+}
+
+// Identities returns a user identity store.
+func (s *Store) Identities() store.IdentityStore {
+	return s.identityStore
+}
+
+// Roles returns a user role store.
+func (s *Store) Roles() store.RoleStore {
+	return s.roleStore
+}
+
+// Notifications returns a user notification store.
+func (s *Store) Notifications() store.NotificationStore {
+	return s.notifyStore
+}
+
+// Watches returns a topic watch store.
+func (s *Store) Watches() store.WatchStore {
+	return s.watchStore
+}
+
+// Digests returns an email digest store.
+func (s *Store) Digests() store.DigestStore {
+	return s.digestStore
+}
+
+// Webhooks returns a webhook store.
+func (s *Store) Webhooks() store.WebhookStore {
+	return s.webhookStore
+}
+
+var _ store.Store = (*Store)(nil)
+
+// Connect connects to a store. The migrate mode defines what to do with pending schema migrations. The database file is created if it does not exist.
+func Connect(path string, migrate store.MigrateMode) (*Store, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0777)
+	if err != nil {
+		return nil, err
+	}
+
+	connstr := fmt.Sprintf(
+		"file:%s?_foreign_keys=1&_busy_timeout=10000&_journal_mode=WAL",
+		path,
+	)
+
+	db, err := sql.Open("sqlite3", connstr)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows only one writer at a time. Serialize all the queries
+	// through a single connection to avoid "database is locked" errors.
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		db:            db,
+		userStore:     &userStore{db: db},
+		topicStore:    &topicStore{db: db},
+		commentStore:  &commentStore{db: db},
+		categoryStore: &categoryStore{db: db},
+		reactionStore: &reactionStore{db: db},
+		reportStore:   &reportStore{db: db},
+		auditStore:    &auditStore{db: db},
+		sessionStore:  &sessionStore{db: db},
+		localStore:    &localAccountStore{db: db},
+		tokenStore:    &authTokenStore{db: db},
+		identityStore: &identityStore{db: db},
+		roleStore:     &roleStore{db: db},
+		notifyStore:   &notificationStore{db: db},
+		watchStore:    &watchStore{db: db},
+		digestStore:   &digestStore{db: db},
+		webhookStore:  &webhookStore{db: db},
+	}
+
+	switch migrate {
+	case store.MigrateAuto:
+		err = s.Migrate()
+	case store.MigrateCheck:
+		err = s.checkMigrations()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Drop drops the store database.
+func (s *Store) Drop() error {
+	for _, q := range drop {
+		_, err := s.db.Exec(q)
+		if err != nil {
+			return fmt.Errorf("sql exec error: %s; query: %q", err, q)
+		}
+	}
+	return nil
+}
+
+// Reset resets the store database.
+func (s *Store) Reset() error {
+	err := s.Drop()
+	if err != nil {
+		return err
+	}
+	return s.Migrate()
+}
+
+type scanner interface {
+	Scan(v ...interface{}) error
+}
+
+func placeholders(count int) string {
+	buf := new(bytes.Buffer)
+	for i := 0; i < count; i++ {
+		buf.WriteByte('?')
+		if i < count-1 {
+			buf.WriteByte(',')
+		}
+	}
+	return buf.String()
+}
+
+func isUniqueConstraintError(err error) bool {
+	if err, ok := err.(sqlite3.Error); ok {
+		return err.ExtendedCode == sqlite3.ErrConstraintUnique || err.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+	return false
+}
+
+// utcNow returns the current time in UTC. Timestamps are stored as text in sqlite,
+// so they must share the same time zone to be compared and sorted correctly.
+func utcNow() time.Time {
+	return time.Now().UTC()
+}
+
+// searchCondition returns an SQL condition matching rows that have
+// all the search query terms in the given column, and the query arguments.
+func searchCondition(column, query string) (string, []interface{}) {
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+	var conds []string
+	var args []interface{}
+	for _, term := range store.SearchTerms(query) {
+		conds = append(conds, column+` like ? escape '\'`)
+		args = append(args, "%"+escaper.Replace(term)+"%")
+	}
+	if len(conds) == 0 {
+		return "0", nil
+	}
+
+	return "(" + strings.Join(conds, " and ") + ")", args
+}
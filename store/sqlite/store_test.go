@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/storetest"
+)
+
+func getTestStore(t *testing.T) (*Store, func()) {
+	dir, err := ioutil.TempDir("", "bebop_test")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %s", err)
+	}
+	path := filepath.Join(dir, "bebop_test.db")
+
+	s, err := Connect(path, store.MigrateAuto)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("failed to connect to the test sqlite database: path=%q: %s", path, err)
+	}
+
+	err = s.Reset()
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("failed to reset the test sqlite database: %s", err)
+	}
+
+	teardown := func() {
+		defer os.RemoveAll(dir)
+		err = s.Drop()
+		if err != nil {
+			t.Fatalf("failed to drop tables of the test sqlite database: %s", err)
+		}
+	}
+
+	return s, teardown
+}
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (store.Store, func()) {
+		return getTestStore(t)
+	})
+}
+
+func TestPlaceholders(t *testing.T) {
+	testTable := map[int]string{
+		0:  "",
+		1:  "?",
+		2:  "?,?",
+		5:  "?,?,?,?,?",
+		-1: "",
+	}
+	for n, want := range testTable {
+		got := placeholders(n)
+		if got != want {
+			t.Fatalf("got placeholders %q for %d, want %q", got, n, want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type userStore struct {
+	db *sql.DB
+}
+
+// New creates a new user with the given identity.
+// It returns ErrConflict if the identity belongs to another user.
+func (s *userStore) New(authService string, authID string) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	now := utcNow()
+
+	res, err := tx.Exec(
+		`insert into users(created_at, auth_service, auth_id) values(?, ?, ?)`,
+		now, authService, authID,
+	)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	_, err = tx.Exec(
+		`insert into identities(user_id, auth_service, auth_id, created_at) values(?, ?, ?, ?)`,
+		id, authService, authID, now,
+	)
+	if err != nil {
+		tx.Rollback()
+		if isUniqueConstraintError(err) {
+			return 0, store.ErrConflict
+		}
+		return 0, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, nil
+}
+
+const selectFromUsers = `
+	select 
+		id, 
+		coalesce(name, '') as name, 
+		created_at,
+		auth_service,
+		auth_id,
+		blocked,
+		avatar,
+		tokens_valid_after
+	from users
+`
+
+func (s *userStore) scanUser(scanner scanner) (*store.User, error) {
+	u := new(store.User)
+	var tokensValidAfter sql.NullTime
+	err := scanner.Scan(
+		&u.ID,
+		&u.Name,
+		&u.CreatedAt,
+		&u.AuthService,
+		&u.AuthID,
+		&u.Blocked,
+		&u.Avatar,
+		&tokensValidAfter,
+	)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tokensValidAfter.Valid {
+		u.TokensValidAfter = tokensValidAfter.Time
+	}
+	return u, nil
+}
+
+// Get finds a user by ID.
+func (s *userStore) Get(id int64) (*store.User, error) {
+	row := s.db.QueryRow(selectFromUsers+` where id=?`, id)
+	return s.scanUser(row)
+}
+
+// GetMany finds users by IDs.
+func (s *userStore) GetMany(ids []int64) (map[int64]*store.User, error) {
+	if len(ids) == 0 {
+		return make(map[int64]*store.User), nil
+	}
+
+	users := make(map[int64]*store.User)
+	for _, id := range ids {
+		users[id] = nil
+	}
+
+	var params []interface{}
+	for id := range users {
+		params = append(params, id)
+	}
+
+	rows, err := s.db.Query(
+		selectFromUsers+` where id in (`+placeholders(len(params))+`)`,
+		params...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := s.scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users[user.ID] = user
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		if user == nil {
+			return nil, store.ErrNotFound
+		}
+	}
+	return users, nil
+}
+
+// GetByName finds a user by name.
+func (s *userStore) GetByName(name string) (*store.User, error) {
+	row := s.db.QueryRow(selectFromUsers+` where name=?`, name)
+	return s.scanUser(row)
+}
+
+// GetByNameFold finds a user by name ignoring case.
+func (s *userStore) GetByNameFold(name string) (*store.User, error) {
+	row := s.db.QueryRow(selectFromUsers+` where lower(name)=lower(?)`, name)
+	return s.scanUser(row)
+}
+
+// GetByAuth finds a user by any of their identities.
+func (s *userStore) GetByAuth(authService string, authID string) (*store.User, error) {
+	row := s.db.QueryRow(
+		selectFromUsers+` where id=(select user_id from identities where auth_service=? and auth_id=?)`,
+		authService, authID,
+	)
+	return s.scanUser(row)
+}
+
+// SetName updates user.Name value. It returns ErrConflict if the given name is already taken.
+func (s *userStore) SetName(id int64, name string) error {
+	_, err := s.db.Exec(`update users set name=? where id=?`, name, id)
+	if isUniqueConstraintError(err) {
+		return store.ErrConflict
+	}
+	return err
+}
+
+// SetBlocked updates user.Blocked value.
+func (s *userStore) SetBlocked(id int64, blocked bool) error {
+	_, err := s.db.Exec(`update users set blocked=? where id=?`, blocked, id)
+	return err
+}
+
+// SetAvatar updates user.Avatar value.
+func (s *userStore) SetAvatar(id int64, avatar string) error {
+	_, err := s.db.Exec(`update users set avatar=? where id=?`, avatar, id)
+	return err
+}
+
+// SetTokensValidAfter updates user.TokensValidAfter value.
+func (s *userStore) SetTokensValidAfter(id int64, t time.Time) error {
+	_, err := s.db.Exec(`update users set tokens_valid_after=? where id=?`, t.UTC(), id)
+	return err
+}
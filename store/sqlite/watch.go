@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"database/sql"
+)
+
+type watchStore struct {
+	db *sql.DB
+}
+
+// Watch starts watching the topic. It does nothing if the user already watches it.
+func (s *watchStore) Watch(userID, topicID int64) error {
+	_, err := s.db.Exec(
+		`insert into topic_watches(user_id, topic_id, created_at) values(?, ?, ?)`,
+		userID, topicID, utcNow(),
+	)
+	if isUniqueConstraintError(err) {
+		return nil
+	}
+	return err
+}
+
+// Unwatch stops watching the topic and removes its comments from the user digest queue.
+func (s *watchStore) Unwatch(userID, topicID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`delete from topic_watches where user_id=? and topic_id=?`, userID, topicID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(
+		`delete from digest_queue where user_id=? and comment_id in (select id from comments where topic_id=?)`,
+		userID, topicID,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// IsWatching checks if the user watches the topic.
+func (s *watchStore) IsWatching(userID, topicID int64) (bool, error) {
+	var n int
+	err := s.db.QueryRow(`select count(*) from topic_watches where user_id=? and topic_id=?`, userID, topicID).Scan(&n)
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
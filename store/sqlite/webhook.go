@@ -0,0 +1,290 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+type webhookStore struct {
+	db *sql.DB
+}
+
+// New creates a new webhook.
+func (s *webhookStore) New(url, secret string, events []string) (int64, error) {
+	res, err := s.db.Exec(
+		`insert into webhooks(url, secret, events, created_at) values(?, ?, ?, ?)`,
+		url, secret, strings.Join(events, ","), utcNow(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+const selectFromWebhooks = `select id, url, secret, events, created_at from webhooks`
+
+func (s *webhookStore) scanWebhook(scanner scanner) (*store.Webhook, error) {
+	w := new(store.Webhook)
+	var events string
+	err := scanner.Scan(&w.ID, &w.URL, &w.Secret, &events, &w.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	w.Events = splitWebhookEvents(events)
+	return w, nil
+}
+
+// splitWebhookEvents parses the comma-separated list of webhook events.
+func splitWebhookEvents(events string) []string {
+	if events == "" {
+		return []string{}
+	}
+	return strings.Split(events, ",")
+}
+
+// Get finds a webhook by ID.
+func (s *webhookStore) Get(id int64) (*store.Webhook, error) {
+	row := s.db.QueryRow(selectFromWebhooks+` where id=?`, id)
+	return s.scanWebhook(row)
+}
+
+// GetAll returns all the webhooks in order of creation.
+func (s *webhookStore) GetAll() ([]*store.Webhook, error) {
+	rows, err := s.db.Query(selectFromWebhooks + ` order by id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []*store.Webhook{}
+	for rows.Next() {
+		w, err := s.scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// Delete deletes a webhook and its deliveries.
+func (s *webhookStore) Delete(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`delete from webhook_deliveries where webhook_id=?`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec(`delete from webhooks where id=?`, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+// Enqueue adds a pending delivery of the event for every webhook subscribed to it.
+func (s *webhookStore) Enqueue(event string, payload []byte) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(`select id, events from webhooks order by id`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var events string
+		err := rows.Scan(&id, &events)
+		if err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+		w := store.Webhook{Events: splitWebhookEvents(events)}
+		if w.Subscribed(event) {
+			ids = append(ids, id)
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	now := utcNow()
+	for _, id := range ids {
+		_, err = tx.Exec(
+			`
+				insert into webhook_deliveries(webhook_id, event, payload, status, error, next_attempt_at, created_at, updated_at)
+				values(?, ?, ?, ?, '', ?, ?, ?)
+			`,
+			id, event, string(payload), store.WebhookDeliveryPending, now, now, now,
+		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return nil
+}
+
+const selectFromWebhookDeliveries = `
+	select id, webhook_id, event, payload, status, attempts, response_code, error, next_attempt_at, created_at, updated_at
+	from webhook_deliveries
+`
+
+func (s *webhookStore) scanDelivery(scanner scanner) (*store.WebhookDelivery, error) {
+	d := new(store.WebhookDelivery)
+	var payload string
+	err := scanner.Scan(
+		&d.ID, &d.WebhookID, &d.Event, &payload, &d.Status, &d.Attempts, &d.ResponseCode, &d.Error,
+		&d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = json.RawMessage(payload)
+	return d, nil
+}
+
+func (s *webhookStore) scanDeliveries(rows *sql.Rows) ([]*store.WebhookDelivery, error) {
+	deliveries := []*store.WebhookDelivery{}
+	for rows.Next() {
+		d, err := s.scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ClaimDue claims the pending deliveries whose next attempt is due, oldest first.
+// Each candidate is claimed with a conditional update, so a delivery claimed
+// by another process in the meantime is skipped.
+func (s *webhookStore) ClaimDue(now, lockedUntil time.Time, limit int) ([]*store.WebhookDelivery, error) {
+	rows, err := s.db.Query(
+		selectFromWebhookDeliveries+`
+			where status=? and next_attempt_at<=? and (locked_until is null or locked_until<=?)
+			order by next_attempt_at, id limit ?
+		`,
+		store.WebhookDeliveryPending, now.UTC(), now.UTC(), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := s.scanDeliveries(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := []*store.WebhookDelivery{}
+	for _, d := range candidates {
+		res, err := s.db.Exec(
+			`
+				update webhook_deliveries
+				set locked_until=?
+				where id=? and status=? and next_attempt_at<=? and (locked_until is null or locked_until<=?)
+			`,
+			lockedUntil.UTC(), d.ID, store.WebhookDeliveryPending, now.UTC(), now.UTC(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if n == 1 {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
+
+// SetDeliveryResult records a delivery attempt.
+func (s *webhookStore) SetDeliveryResult(id int64, status string, responseCode int, errorMessage string, nextAttemptAt time.Time) error {
+	_, err := s.db.Exec(
+		`
+			update webhook_deliveries
+			set status=?, attempts=attempts+1, response_code=?, error=?, next_attempt_at=?, updated_at=?, locked_until=null
+			where id=?
+		`,
+		status, responseCode, errorMessage, nextAttemptAt.UTC(), utcNow(), id,
+	)
+	return err
+}
+
+// GetDeliveries returns a limited number of the latest webhook deliveries
+// and a total count of the webhook deliveries.
+func (s *webhookStore) GetDeliveries(webhookID int64, offset, limit int) ([]*store.WebhookDelivery, int, error) {
+	var count int
+	err := s.db.QueryRow(`select count(*) from webhook_deliveries where webhook_id=?`, webhookID).Scan(&count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if limit <= 0 || offset > count {
+		return []*store.WebhookDelivery{}, count, nil
+	}
+
+	rows, err := s.db.Query(
+		selectFromWebhookDeliveries+` where webhook_id=? order by id desc limit ? offset ?`,
+		webhookID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries, err := s.scanDeliveries(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, count, nil
+}
@@ -3,6 +3,7 @@ package store
 
 import (
 	"errors"
+	"time"
 )
 
 var (
@@ -17,36 +18,220 @@ type Store interface {
 	Users() UserStore
 	Topics() TopicStore
 	Comments() CommentStore
+	Categories() CategoryStore
+	Reactions() ReactionStore
+	Reports() ReportStore
+	Audit() AuditStore
+	Sessions() SessionStore
+	LocalAccounts() LocalAccountStore
+	AuthTokens() AuthTokenStore
+	Identities() IdentityStore
+	Roles() RoleStore
+	Notifications() NotificationStore
+	Watches() WatchStore
+	Digests() DigestStore
+	Webhooks() WebhookStore
 }
 
 // UserStore is a bebop user data store interface.
+// New creates the user along with its first identity,
+// GetByAuth finds the user by any of their identities.
+// GetByNameFold finds the user by name ignoring case, as user names
+// are unique regardless of case.
 type UserStore interface {
 	New(authService string, authID string) (int64, error)
 	Get(id int64) (*User, error)
 	GetMany(ids []int64) (map[int64]*User, error)
-	GetAdmins() ([]*User, error)
 	GetByName(name string) (*User, error)
+	GetByNameFold(name string) (*User, error)
 	GetByAuth(authService string, authID string) (*User, error)
 	SetName(id int64, name string) error
 	SetBlocked(id int64, blocked bool) error
-	SetAdmin(id int64, admin bool) error
 	SetAvatar(id int64, avatar string) error
+	SetTokensValidAfter(id int64, t time.Time) error
 }
 
 // TopicStore is a bebop topic data store interface.
+// Topic lists are ordered with pinned topics first.
+// The ...After methods return the topics that follow the cursor position
+// (the first page if cursor is nil) without counting the total.
 type TopicStore interface {
-	New(authorID int64, title string) (int64, error)
+	New(authorID int64, categoryID int64, title string) (int64, error)
 	Get(id int64) (*Topic, error)
 	GetLatest(offset, limit int) ([]*Topic, int, error)
-	SetTitle(id int64, title string) error
+	GetByCategory(categoryID int64, offset, limit int) ([]*Topic, int, error)
+	GetLatestAfter(cursor *TopicCursor, limit int) ([]*Topic, error)
+	GetByCategoryAfter(categoryID int64, cursor *TopicCursor, limit int) ([]*Topic, error)
+	Search(query string, offset, limit int) ([]*Topic, int, error)
+	GetRevisions(id int64) ([]*TopicRevision, error)
+	SetTitle(id int64, editorID int64, title string) error
+	SetCategory(id int64, categoryID int64) error
+	SetPinned(id int64, pinned bool) error
+	SetLocked(id int64, locked bool) error
 	Delete(id int64) error
 }
 
 // CommentStore is a bebop comment data store interface.
+// New creates the comment along with the notifications for the topic author
+// and the mentioned users, see CommentNotifications. It also queues the comment
+// for the digest emails of the users watching the topic, see DigestStore.
 type CommentStore interface {
-	New(topicID int64, authorID int64, content string) (int64, error)
+	New(topicID int64, authorID int64, content string, mentionIDs []int64) (int64, error)
 	Get(id int64) (*Comment, error)
 	GetByTopic(topicID int64, offset, limit int) ([]*Comment, int, error)
-	SetContent(id int64, content string) error
+	GetByTopicAfter(topicID int64, cursor *CommentCursor, limit int) ([]*Comment, error)
+	Search(query string, offset, limit int) ([]*Comment, int, error)
+	GetRevisions(id int64) ([]*CommentRevision, error)
+	SetContent(id int64, editorID int64, content string) error
 	Delete(id int64) error
 }
+
+// CategoryStore is a bebop category data store interface.
+type CategoryStore interface {
+	New(slug, name, description string, sortOrder int, adminOnly bool) (int64, error)
+	Get(id int64) (*Category, error)
+	GetBySlug(slug string) (*Category, error)
+	GetAll() ([]*Category, error)
+	Update(category *Category) error
+	Delete(id int64) error
+}
+
+// ReactionStore is a bebop comment reaction data store interface.
+// A user can react to a comment with each emoji only once.
+type ReactionStore interface {
+	Add(commentID int64, userID int64, emoji string) error
+	Remove(commentID int64, userID int64, emoji string) error
+	GetCounts(commentIDs []int64, userID int64) (map[int64][]*ReactionCount, error)
+}
+
+// ReportStore is a bebop content report data store interface.
+type ReportStore interface {
+	New(reporterID int64, targetType string, targetID int64, authorID int64, reason string) (int64, error)
+	Get(id int64) (*Report, error)
+	GetByStatus(status string, offset, limit int) ([]*Report, int, error)
+	Resolve(id int64, status string, resolverID int64) error
+}
+
+// AuditStore is a bebop audit log data store interface.
+// The audit log is append-only. Entries are returned latest first.
+type AuditStore interface {
+	New(entry *AuditEntry) (int64, error)
+	GetByFilter(filter *AuditFilter, offset, limit int) ([]*AuditEntry, int, error)
+}
+
+// SessionStore is a bebop login session data store interface.
+// Sessions are looked up by the refresh token hash. Expired sessions
+// are returned as is, it's up to the caller to check ExpiresAt.
+type SessionStore interface {
+	New(userID int64, tokenHash string, expiresAt time.Time) (int64, error)
+	GetByToken(tokenHash string) (*Session, error)
+	Rotate(id int64, tokenHash, newTokenHash string, expiresAt time.Time) error
+	Delete(id int64) error
+	DeleteByUser(userID int64) error
+}
+
+// LocalAccountStore is a bebop local account data store interface.
+// New creates both the user and the account. Emails are stored in lowercase.
+type LocalAccountStore interface {
+	New(email, passwordHash string) (userID int64, err error)
+	Get(userID int64) (*LocalAccount, error)
+	GetByEmail(email string) (*LocalAccount, error)
+	SetPasswordHash(userID int64, passwordHash string) error
+	SetVerified(userID int64) error
+}
+
+// AuthTokenStore is a bebop single-use auth token data store interface.
+// Use deletes an unexpired token and returns it: a token can be used only once.
+type AuthTokenStore interface {
+	New(userID int64, purpose, tokenHash string, expiresAt time.Time) (int64, error)
+	Use(purpose, tokenHash string) (*AuthToken, error)
+	DeleteByUser(userID int64, purpose string) error
+}
+
+// IdentityStore is a bebop user identity data store interface.
+// New returns ErrConflict if the identity belongs to any user.
+// Delete returns ErrConflict if it is the last identity of the user.
+// Deleting a local identity also deletes the local account.
+// SetProfile updates the profile data of the identity with the given auth service and ID.
+type IdentityStore interface {
+	New(userID int64, authService, authID string) (int64, error)
+	GetByUser(userID int64) ([]*Identity, error)
+	SetProfile(authService, authID, displayName, picture string) error
+	Delete(userID int64, id int64) error
+}
+
+// RoleStore is a bebop user role data store interface.
+// Grant returns ErrConflict if the user already has the role,
+// Revoke returns ErrNotFound if the user does not have it.
+// GrantPermission returns ErrConflict if the role already grants the permission,
+// RevokePermission returns ErrNotFound if it does not.
+// GetPermissions returns the permissions granted by any of the roles,
+// HasPermission checks if any of the user roles grants the permission.
+// Roles and permissions are returned in alphabetical order, users in ID order.
+type RoleStore interface {
+	Grant(userID int64, role string) error
+	Revoke(userID int64, role string) error
+	GetByUser(userID int64) ([]string, error)
+	GetUsers(role string) ([]int64, error)
+	GrantPermission(role, permission string) error
+	RevokePermission(role, permission string) error
+	GetPermissions(roles []string) ([]string, error)
+	HasPermission(userID int64, permission string) (bool, error)
+}
+
+// NotificationStore is a bebop user notification data store interface.
+// Notifications are created by CommentStore.New and returned latest first.
+// The notifications about deleted comments and topics are skipped.
+// MarkRead returns ErrNotFound if the user has no such notification.
+type NotificationStore interface {
+	GetByUser(userID int64, unreadOnly bool, offset, limit int) ([]*Notification, int, error)
+	CountUnread(userID int64) (int, error)
+	MarkRead(userID int64, id int64) error
+	MarkAllRead(userID int64) error
+}
+
+// WatchStore is a bebop topic watch data store interface.
+// Watch does nothing if the user already watches the topic, Unwatch does nothing
+// if the user does not. Unwatch also removes the topic comments from the user digest queue.
+type WatchStore interface {
+	Watch(userID, topicID int64) error
+	Unwatch(userID, topicID int64) error
+	IsWatching(userID, topicID int64) (bool, error)
+}
+
+// DigestStore is a bebop email digest data store interface.
+// New comments are queued for the users watching the topic, except the comment
+// author and the users with the DigestOff mode. Users have the DigestOff mode
+// until they set another one. Setting the DigestOff mode clears the queue.
+// GetDueUsers returns the users with queued comments whose digest is due:
+// the ones with the DigestImmediate mode and the ones with the DigestDaily mode
+// whose last digest was sent before dailySentBefore.
+// GetQueued returns the queued comments in order, skipping the deleted ones.
+// MarkSent removes the comments up to lastCommentID from the queue and records the sending time.
+type DigestStore interface {
+	GetMode(userID int64) (string, error)
+	SetMode(userID int64, mode string) error
+	GetDueUsers(dailySentBefore time.Time) ([]int64, error)
+	GetQueued(userID int64) ([]*DigestComment, error)
+	MarkSent(userID int64, lastCommentID int64) error
+}
+
+// WebhookStore is a bebop webhook data store interface.
+// Deleting a webhook deletes its deliveries as well.
+// Enqueue adds a pending delivery of the event for every webhook subscribed to it.
+// ClaimDue returns the pending deliveries whose next attempt is due at the given time
+// and which are not claimed already, oldest first, and claims them until lockedUntil,
+// so that concurrent callers never get the same delivery. SetDeliveryResult releases the claim.
+// SetDeliveryResult records an attempt: it increments the attempt count and sets the status,
+// the response code, the error and the time of the next attempt.
+// GetDeliveries returns the webhook deliveries, latest first.
+type WebhookStore interface {
+	New(url, secret string, events []string) (int64, error)
+	Get(id int64) (*Webhook, error)
+	GetAll() ([]*Webhook, error)
+	Delete(id int64) error
+	Enqueue(event string, payload []byte) error
+	ClaimDue(now, lockedUntil time.Time, limit int) ([]*WebhookDelivery, error)
+	SetDeliveryResult(id int64, status string, responseCode int, errorMessage string, nextAttemptAt time.Time) error
+	GetDeliveries(webhookID int64, offset, limit int) ([]*WebhookDelivery, int, error)
+}
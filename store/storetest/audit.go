@@ -0,0 +1,84 @@
+package storetest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testAudit(t *testing.T, s store.Store) {
+	entries := []*store.AuditEntry{
+		{ActorID: 1, Action: store.AuditUserBlocked, TargetType: store.AuditTargetUser, TargetID: 2, Before: json.RawMessage(`{"blocked":false}`), After: json.RawMessage(`{"blocked":true}`), IP: "127.0.0.1"},
+		{ActorID: 1, Action: store.AuditTopicDelete, TargetType: store.AuditTargetTopic, TargetID: 3, Before: json.RawMessage(`{"id":3}`), IP: "127.0.0.1"},
+		{ActorID: 0, Action: store.AuditUserAdmin, TargetType: store.AuditTargetUser, TargetID: 1, Before: json.RawMessage(`{"admin":false}`), After: json.RawMessage(`{"admin":true}`)},
+	}
+
+	var ids []int64
+	for _, e := range entries {
+		id, err := s.Audit().New(e)
+		if err != nil {
+			t.Fatalf("failed to add an audit log entry: %s", err)
+		}
+		ids = append(ids, id)
+	}
+
+	got, count, err := s.Audit().GetByFilter(&store.AuditFilter{}, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get audit log entries: %s", err)
+	}
+	if count != 3 || len(got) != 3 || got[0].ID != ids[2] || got[1].ID != ids[1] || got[2].ID != ids[0] {
+		t.Fatalf("bad audit log entries: %d, %v", count, got)
+	}
+
+	e := got[2]
+	sinceCreated := time.Since(e.CreatedAt)
+	if sinceCreated > 3*time.Second || sinceCreated < 0 {
+		t.Fatalf("bad entry.CreatedAt: %v", e.CreatedAt)
+	}
+	if e.ActorID != 1 || e.Action != store.AuditUserBlocked || e.TargetType != store.AuditTargetUser || e.TargetID != 2 ||
+		string(e.Before) != `{"blocked":false}` || string(e.After) != `{"blocked":true}` || e.IP != "127.0.0.1" {
+		t.Fatalf("bad audit log entry: %#v", e)
+	}
+
+	if string(got[1].After) != "null" {
+		t.Fatalf("expected null after payload, got %q", got[1].After)
+	}
+
+	tests := []struct {
+		filter  store.AuditFilter
+		wantIDs []int64
+	}{
+		{store.AuditFilter{ActorID: 1}, []int64{ids[1], ids[0]}},
+		{store.AuditFilter{Action: store.AuditUserAdmin}, []int64{ids[2]}},
+		{store.AuditFilter{TargetType: store.AuditTargetUser}, []int64{ids[2], ids[0]}},
+		{store.AuditFilter{TargetType: store.AuditTargetUser, TargetID: 2}, []int64{ids[0]}},
+		{store.AuditFilter{Since: time.Now().Add(-time.Hour), Until: time.Now().Add(time.Hour)}, []int64{ids[2], ids[1], ids[0]}},
+		{store.AuditFilter{Since: time.Now().Add(time.Hour)}, []int64{}},
+		{store.AuditFilter{Until: time.Now().Add(-time.Hour)}, []int64{}},
+	}
+
+	for _, tc := range tests {
+		got, count, err := s.Audit().GetByFilter(&tc.filter, 0, 10)
+		if err != nil {
+			t.Fatalf("failed to get audit log entries: %s", err)
+		}
+		if count != len(tc.wantIDs) || len(got) != len(tc.wantIDs) {
+			t.Fatalf("filter %+v: got %d entries (count %d) want %d", tc.filter, len(got), count, len(tc.wantIDs))
+		}
+		for i := range got {
+			if got[i].ID != tc.wantIDs[i] {
+				t.Fatalf("filter %+v: got entry %d want %d", tc.filter, got[i].ID, tc.wantIDs[i])
+			}
+		}
+	}
+
+	got, count, err = s.Audit().GetByFilter(&store.AuditFilter{}, 1, 1)
+	if err != nil {
+		t.Fatalf("failed to get audit log entries: %s", err)
+	}
+	if count != 3 || len(got) != 1 || got[0].ID != ids[1] {
+		t.Fatalf("bad audit log entries: %d, %v", count, got)
+	}
+}
@@ -0,0 +1,152 @@
+package storetest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testCategory(t *testing.T, s store.Store) {
+	c1, err := s.Categories().New("help", "Help", "Ask for help", 2, false)
+	if err != nil {
+		t.Fatalf("failed to create a category: %s", err)
+	}
+	c2, err := s.Categories().New("announcements", "Announcements", "", 1, true)
+	if err != nil {
+		t.Fatalf("failed to create a category: %s", err)
+	}
+
+	_, err = s.Categories().New("help", "Help 2", "", 0, false)
+	if err != store.ErrConflict {
+		t.Fatalf("expected error ErrConflict on duplicate slug, got: %v", err)
+	}
+
+	got, err := s.Categories().Get(c1)
+	if err != nil {
+		t.Fatalf("failed to get a category: %s", err)
+	}
+	want := &store.Category{
+		ID:          c1,
+		Slug:        "help",
+		Name:        "Help",
+		Description: "Ask for help",
+		SortOrder:   2,
+		AdminOnly:   false,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got category %v, want %v", got, want)
+	}
+
+	got, err = s.Categories().GetBySlug("announcements")
+	if err != nil {
+		t.Fatalf("failed to get a category by slug: %s", err)
+	}
+	if got.ID != c2 || !got.AdminOnly {
+		t.Fatalf("bad category: %v", got)
+	}
+
+	_, err = s.Categories().GetBySlug("off-topic")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected error ErrNotFound, got: %v", err)
+	}
+
+	all, err := s.Categories().GetAll()
+	if err != nil {
+		t.Fatalf("failed to get all categories: %s", err)
+	}
+	if len(all) != 2 || all[0].ID != c2 || all[1].ID != c1 {
+		t.Fatalf("bad category list: %v", all)
+	}
+
+	want.Slug = "support"
+	want.Name = "Support"
+	want.SortOrder = 0
+	err = s.Categories().Update(want)
+	if err != nil {
+		t.Fatalf("failed to update a category: %s", err)
+	}
+	got, err = s.Categories().Get(c1)
+	if err != nil {
+		t.Fatalf("failed to get a category: %s", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got category %v, want %v", got, want)
+	}
+
+	err = s.Categories().Update(&store.Category{ID: c1, Slug: "announcements", Name: "Support"})
+	if err != store.ErrConflict {
+		t.Fatalf("expected error ErrConflict on duplicate slug, got: %v", err)
+	}
+
+	err = s.Categories().Update(&store.Category{ID: c2 + 100, Slug: "other", Name: "Other"})
+	if err != store.ErrNotFound {
+		t.Fatalf("expected error ErrNotFound, got: %v", err)
+	}
+
+	u1, err := s.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+	t1, err := s.Topics().New(u1, c1, "topic1")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+	t2, err := s.Topics().New(u1, 0, "topic2")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+
+	topic, err := s.Topics().Get(t1)
+	if err != nil {
+		t.Fatalf("failed to get a topic: %s", err)
+	}
+	if topic.CategoryID != c1 {
+		t.Fatalf("bad topic.CategoryID: %d", topic.CategoryID)
+	}
+
+	topics, count, err := s.Topics().GetByCategory(c1, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get topics by category: %s", err)
+	}
+	if count != 1 || len(topics) != 1 || topics[0].ID != t1 {
+		t.Fatalf("bad topics by category: count %d, topics %v", count, topics)
+	}
+
+	err = s.Topics().SetCategory(t2, c1)
+	if err != nil {
+		t.Fatalf("failed to set topic category: %s", err)
+	}
+	err = s.Topics().SetCategory(t1, 0)
+	if err != nil {
+		t.Fatalf("failed to set topic category: %s", err)
+	}
+
+	topics, count, err = s.Topics().GetByCategory(c1, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get topics by category: %s", err)
+	}
+	if count != 1 || len(topics) != 1 || topics[0].ID != t2 {
+		t.Fatalf("bad topics by category: count %d, topics %v", count, topics)
+	}
+
+	err = s.Categories().Delete(c1)
+	if err != store.ErrConflict {
+		t.Fatalf("expected error ErrConflict on deleting a category with topics, got: %v", err)
+	}
+
+	err = s.Topics().Delete(t2)
+	if err != nil {
+		t.Fatalf("failed to delete a topic: %s", err)
+	}
+
+	err = s.Categories().Delete(c1)
+	if err != nil {
+		t.Fatalf("failed to delete a category: %s", err)
+	}
+
+	_, err = s.Categories().Get(c1)
+	if err != store.ErrNotFound {
+		t.Fatalf("expected error ErrNotFound, got: %v", err)
+	}
+}
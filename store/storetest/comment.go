@@ -0,0 +1,123 @@
+package storetest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testCommentSearch(t *testing.T, s store.Store) {
+	u1, err := s.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+
+	t1, err := s.Topics().New(u1, 0, "topic1")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+	t2, err := s.Topics().New(u1, 0, "topic2")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+
+	c1, err := s.Comments().New(t1, u1, "Generics are finally here", nil)
+	if err != nil {
+		t.Fatalf("failed to create a comment: %s", err)
+	}
+	c2, err := s.Comments().New(t1, u1, "What about generics performance?", nil)
+	if err != nil {
+		t.Fatalf("failed to create a comment: %s", err)
+	}
+	_, err = s.Comments().New(t2, u1, "Generics discussion continues", nil)
+	if err != nil {
+		t.Fatalf("failed to create a comment: %s", err)
+	}
+
+	comments, count, err := s.Comments().Search("generics", 0, 10)
+	if err != nil {
+		t.Fatalf("failed to search comments: %s", err)
+	}
+	if count != 3 || len(comments) != 3 {
+		t.Fatalf("bad search result: count %d, len %d", count, len(comments))
+	}
+
+	comments, count, err = s.Comments().Search("generics performance", 0, 10)
+	if err != nil {
+		t.Fatalf("failed to search comments: %s", err)
+	}
+	if count != 1 || len(comments) != 1 || comments[0].ID != c2 {
+		t.Fatalf("bad search result: count %d, comments %v", count, comments)
+	}
+
+	err = s.Comments().Delete(c2)
+	if err != nil {
+		t.Fatalf("failed to delete comment: %s", err)
+	}
+	err = s.Topics().Delete(t2)
+	if err != nil {
+		t.Fatalf("failed to delete topic: %s", err)
+	}
+
+	comments, count, err = s.Comments().Search("generics", 0, 10)
+	if err != nil {
+		t.Fatalf("failed to search comments: %s", err)
+	}
+	if count != 1 || len(comments) != 1 || comments[0].ID != c1 {
+		t.Fatalf("bad search result: count %d, comments %v", count, comments)
+	}
+}
+
+func testCommentCursor(t *testing.T, s store.Store) {
+	u1, err := s.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+
+	t1, err := s.Topics().New(u1, 0, "topic1")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+
+	var want []int64
+	for i := 0; i < 5; i++ {
+		id, err := s.Comments().New(t1, u1, fmt.Sprintf("comment%d", i), nil)
+		if err != nil {
+			t.Fatalf("failed to create a comment: %s", err)
+		}
+		want = append(want, id)
+	}
+
+	err = s.Comments().Delete(want[2])
+	if err != nil {
+		t.Fatalf("failed to delete a comment: %s", err)
+	}
+	want = append(want[:2], want[3:]...)
+
+	var got []int64
+	var cursor *store.CommentCursor
+	for i := 0; i < 10; i++ {
+		comments, err := s.Comments().GetByTopicAfter(t1, cursor, 3)
+		if err != nil {
+			t.Fatalf("failed to get comments by topic after cursor: %s", err)
+		}
+		if len(comments) == 0 {
+			break
+		}
+		for _, c := range comments {
+			got = append(got, c.ID)
+		}
+
+		// Round-trip the cursor through its string encoding.
+		cursor, err = store.ParseCommentCursor(store.NewCommentCursor(comments[len(comments)-1]).String())
+		if err != nil {
+			t.Fatalf("failed to parse comment cursor: %s", err)
+		}
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got comments %v want %v", got, want)
+	}
+}
@@ -0,0 +1,196 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testDigest(t *testing.T, s store.Store) {
+	u1, err := s.Users().New("service1", "user1")
+	if err != nil {
+		t.Fatalf("failed to create user1: %s", err)
+	}
+	u2, err := s.Users().New("service1", "user2")
+	if err != nil {
+		t.Fatalf("failed to create user2: %s", err)
+	}
+	u3, err := s.Users().New("service1", "user3")
+	if err != nil {
+		t.Fatalf("failed to create user3: %s", err)
+	}
+
+	mode, err := s.Digests().GetMode(u1)
+	if err != nil {
+		t.Fatalf("failed to get digest mode: %s", err)
+	}
+	if mode != store.DigestOff {
+		t.Fatalf("expected the default digest mode %q, got %q", store.DigestOff, mode)
+	}
+
+	err = s.Digests().SetMode(u1, store.DigestImmediate)
+	if err != nil {
+		t.Fatalf("failed to set digest mode: %s", err)
+	}
+	err = s.Digests().SetMode(u2, store.DigestDaily)
+	if err != nil {
+		t.Fatalf("failed to set digest mode: %s", err)
+	}
+	err = s.Digests().SetMode(u2, store.DigestImmediate)
+	if err != nil {
+		t.Fatalf("failed to update digest mode: %s", err)
+	}
+	mode, err = s.Digests().GetMode(u2)
+	if err != nil {
+		t.Fatalf("failed to get digest mode: %s", err)
+	}
+	if mode != store.DigestImmediate {
+		t.Fatalf("expected digest mode %q, got %q", store.DigestImmediate, mode)
+	}
+
+	t1, err := s.Topics().New(u1, 0, "topic1")
+	if err != nil {
+		t.Fatalf("failed to create topic1: %s", err)
+	}
+	t2, err := s.Topics().New(u1, 0, "topic2")
+	if err != nil {
+		t.Fatalf("failed to create topic2: %s", err)
+	}
+
+	// user3 watches the topics but has the digest turned off.
+	for _, w := range []struct{ userID, topicID int64 }{{u1, t1}, {u1, t2}, {u2, t1}, {u3, t1}} {
+		err = s.Watches().Watch(w.userID, w.topicID)
+		if err != nil {
+			t.Fatalf("failed to watch topic: %s", err)
+		}
+	}
+
+	// The comment authors do not get their own comments.
+	c1, err := s.Comments().New(t1, u2, "comment1", nil)
+	if err != nil {
+		t.Fatalf("failed to create comment1: %s", err)
+	}
+	c2, err := s.Comments().New(t2, u3, "comment2", nil)
+	if err != nil {
+		t.Fatalf("failed to create comment2: %s", err)
+	}
+	c3, err := s.Comments().New(t1, u3, "comment3", nil)
+	if err != nil {
+		t.Fatalf("failed to create comment3: %s", err)
+	}
+
+	due, err := s.Digests().GetDueUsers(time.Now())
+	if err != nil {
+		t.Fatalf("failed to get due users: %s", err)
+	}
+	if len(due) != 2 || due[0] != u1 || due[1] != u2 {
+		t.Fatalf("expected due users [%d %d], got %v", u1, u2, due)
+	}
+
+	queued, err := s.Digests().GetQueued(u1)
+	if err != nil {
+		t.Fatalf("failed to get queued comments: %s", err)
+	}
+	if len(queued) != 3 || queued[0].CommentID != c1 || queued[1].CommentID != c2 || queued[2].CommentID != c3 {
+		t.Fatalf("expected queued comments [%d %d %d], got %+v", c1, c2, c3, queued)
+	}
+	q := queued[0]
+	if q.TopicID != t1 || q.TopicTitle != "topic1" || q.AuthorID != u2 || q.AuthorName != "" || q.Content != "comment1" || q.CreatedAt.IsZero() {
+		t.Fatalf("bad queued comment: %+v", q)
+	}
+
+	queued, err = s.Digests().GetQueued(u2)
+	if err != nil {
+		t.Fatalf("failed to get queued comments: %s", err)
+	}
+	if len(queued) != 1 || queued[0].CommentID != c3 {
+		t.Fatalf("expected queued comments [%d], got %+v", c3, queued)
+	}
+
+	queued, err = s.Digests().GetQueued(u3)
+	if err != nil {
+		t.Fatalf("failed to get queued comments: %s", err)
+	}
+	if len(queued) != 0 {
+		t.Fatalf("expected no queued comments for user3, got %+v", queued)
+	}
+
+	// Deleted comments are not sent, unwatched topics are removed from the queue.
+	err = s.Comments().Delete(c3)
+	if err != nil {
+		t.Fatalf("failed to delete comment3: %s", err)
+	}
+	err = s.Watches().Unwatch(u1, t2)
+	if err != nil {
+		t.Fatalf("failed to unwatch topic2: %s", err)
+	}
+	queued, err = s.Digests().GetQueued(u1)
+	if err != nil {
+		t.Fatalf("failed to get queued comments: %s", err)
+	}
+	if len(queued) != 1 || queued[0].CommentID != c1 {
+		t.Fatalf("expected queued comments [%d], got %+v", c1, queued)
+	}
+
+	due, err = s.Digests().GetDueUsers(time.Now())
+	if err != nil {
+		t.Fatalf("failed to get due users: %s", err)
+	}
+	if len(due) != 1 || due[0] != u1 {
+		t.Fatalf("expected due users [%d], got %v", u1, due)
+	}
+
+	err = s.Digests().MarkSent(u1, c1)
+	if err != nil {
+		t.Fatalf("failed to mark digest sent: %s", err)
+	}
+	queued, err = s.Digests().GetQueued(u1)
+	if err != nil {
+		t.Fatalf("failed to get queued comments: %s", err)
+	}
+	if len(queued) != 0 {
+		t.Fatalf("expected no queued comments after sending, got %+v", queued)
+	}
+
+	// A daily digest is due once a day.
+	err = s.Digests().SetMode(u1, store.DigestDaily)
+	if err != nil {
+		t.Fatalf("failed to set digest mode: %s", err)
+	}
+	_, err = s.Comments().New(t1, u2, "comment4", nil)
+	if err != nil {
+		t.Fatalf("failed to create comment4: %s", err)
+	}
+	due, err = s.Digests().GetDueUsers(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("failed to get due users: %s", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("expected no due users, got %v", due)
+	}
+	due, err = s.Digests().GetDueUsers(time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("failed to get due users: %s", err)
+	}
+	if len(due) != 1 || due[0] != u1 {
+		t.Fatalf("expected due users [%d], got %v", u1, due)
+	}
+
+	// Turning the digest off clears the queue.
+	err = s.Digests().SetMode(u1, store.DigestOff)
+	if err != nil {
+		t.Fatalf("failed to set digest mode: %s", err)
+	}
+	err = s.Digests().SetMode(u1, store.DigestImmediate)
+	if err != nil {
+		t.Fatalf("failed to set digest mode: %s", err)
+	}
+	queued, err = s.Digests().GetQueued(u1)
+	if err != nil {
+		t.Fatalf("failed to get queued comments: %s", err)
+	}
+	if len(queued) != 0 {
+		t.Fatalf("expected the queue to be cleared, got %+v", queued)
+	}
+}
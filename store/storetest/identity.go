@@ -0,0 +1,131 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testIdentity(t *testing.T, s store.Store) {
+	u1, err := s.Users().New("github", "1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+	u2, err := s.LocalAccounts().New("user2@example.com", "hash")
+	if err != nil {
+		t.Fatalf("failed to create a local account: %s", err)
+	}
+
+	_, err = s.Users().New("github", "1")
+	if err != store.ErrConflict {
+		t.Fatalf("expected store.ErrConflict on creating a user with a taken identity, got %v", err)
+	}
+
+	i2, err := s.Identities().New(u1, "google", "2")
+	if err != nil {
+		t.Fatalf("failed to link an identity: %s", err)
+	}
+	_, err = s.Identities().New(u2, "google", "2")
+	if err != store.ErrConflict {
+		t.Fatalf("expected store.ErrConflict on linking a taken identity, got %v", err)
+	}
+
+	for _, auth := range [][2]string{{"github", "1"}, {"google", "2"}} {
+		user, err := s.Users().GetByAuth(auth[0], auth[1])
+		if err != nil {
+			t.Fatalf("failed to get a user by auth %v: %s", auth, err)
+		}
+		if user.ID != u1 || user.AuthService != "github" || user.AuthID != "1" {
+			t.Fatalf("bad user by auth %v: %v", auth, user)
+		}
+	}
+	user, err := s.Users().GetByAuth(store.LocalAuthService, "user2@example.com")
+	if err != nil || user.ID != u2 {
+		t.Fatalf("bad local user by auth: %v, %v", user, err)
+	}
+	_, err = s.Users().GetByAuth("google", "1")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound on getting a user by unknown auth, got %v", err)
+	}
+
+	err = s.Identities().SetProfile("google", "2", "Google User", "https://example.com/2.png")
+	if err != nil {
+		t.Fatalf("failed to set identity profile: %s", err)
+	}
+	err = s.Identities().SetProfile("google", "1", "Unknown", "")
+	if err != nil {
+		t.Fatalf("failed to set profile of an unknown identity: %s", err)
+	}
+
+	identities, err := s.Identities().GetByUser(u1)
+	if err != nil {
+		t.Fatalf("failed to get identities: %s", err)
+	}
+	if len(identities) != 2 {
+		t.Fatalf("expected 2 identities, got %d", len(identities))
+	}
+	for n, want := range []store.Identity{
+		{UserID: u1, AuthService: "github", AuthID: "1"},
+		{ID: i2, UserID: u1, AuthService: "google", AuthID: "2", DisplayName: "Google User", Picture: "https://example.com/2.png"},
+	} {
+		got := identities[n]
+		sinceCreated := time.Since(got.CreatedAt)
+		if sinceCreated > 3*time.Second || sinceCreated < 0 {
+			t.Fatalf("bad identity.CreatedAt: %v", got.CreatedAt)
+		}
+		if want.ID == 0 {
+			want.ID = got.ID
+		}
+		want.CreatedAt = got.CreatedAt
+		if *got != want {
+			t.Fatalf("got identity %v want %v", got, want)
+		}
+	}
+	i1 := identities[0].ID
+
+	err = s.Identities().Delete(u2, i1)
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound on deleting an identity of another user, got %v", err)
+	}
+	err = s.Identities().Delete(u1, i1)
+	if err != nil {
+		t.Fatalf("failed to delete an identity: %s", err)
+	}
+	err = s.Identities().Delete(u1, i2)
+	if err != store.ErrConflict {
+		t.Fatalf("expected store.ErrConflict on deleting the last identity, got %v", err)
+	}
+	_, err = s.Users().GetByAuth("github", "1")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound on getting a user by unlinked auth, got %v", err)
+	}
+
+	// An unlinked identity can be used by another user.
+	u3, err := s.Users().New("github", "1")
+	if err != nil {
+		t.Fatalf("failed to create a user with an unlinked identity: %s", err)
+	}
+	user, err = s.Users().GetByAuth("github", "1")
+	if err != nil || user.ID != u3 {
+		t.Fatalf("bad user by auth after relinking: %v, %v", user, err)
+	}
+
+	// Unlinking the local identity deletes the local account.
+	_, err = s.Identities().New(u2, "github", "2")
+	if err != nil {
+		t.Fatalf("failed to link an identity: %s", err)
+	}
+	identities, err = s.Identities().GetByUser(u2)
+	if err != nil || len(identities) != 2 || identities[0].AuthService != store.LocalAuthService {
+		t.Fatalf("bad local user identities: %v, %v", identities, err)
+	}
+	err = s.Identities().Delete(u2, identities[0].ID)
+	if err != nil {
+		t.Fatalf("failed to delete a local identity: %s", err)
+	}
+	_, err = s.LocalAccounts().Get(u2)
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound on getting an unlinked local account, got %v", err)
+	}
+}
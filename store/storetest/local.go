@@ -0,0 +1,133 @@
+package storetest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testLocalAccount(t *testing.T, s store.Store) {
+	u1, err := s.LocalAccounts().New("User1@Example.com", "hash1")
+	if err != nil {
+		t.Fatalf("failed to create a local account: %s", err)
+	}
+
+	_, err = s.LocalAccounts().New("user1@example.com", "hash2")
+	if err != store.ErrConflict {
+		t.Fatalf("expected store.ErrConflict on duplicate email, got %v", err)
+	}
+
+	user, err := s.Users().Get(u1)
+	if err != nil {
+		t.Fatalf("failed to get a user: %s", err)
+	}
+	if user.AuthService != store.LocalAuthService || user.AuthID != "user1@example.com" {
+		t.Fatalf("bad local user auth: %q %q", user.AuthService, user.AuthID)
+	}
+
+	a, err := s.LocalAccounts().GetByEmail("USER1@example.com")
+	if err != nil {
+		t.Fatalf("failed to get a local account: %s", err)
+	}
+	sinceCreated := time.Since(a.CreatedAt)
+	if sinceCreated > 3*time.Second || sinceCreated < 0 {
+		t.Fatalf("bad account.CreatedAt: %v", a.CreatedAt)
+	}
+	want := &store.LocalAccount{UserID: u1, Email: "user1@example.com", PasswordHash: "hash1", CreatedAt: a.CreatedAt}
+	if !reflect.DeepEqual(a, want) {
+		t.Fatalf("got account %v want %v", a, want)
+	}
+
+	err = s.LocalAccounts().SetPasswordHash(u1, "hash3")
+	if err != nil {
+		t.Fatalf("failed to set password hash: %s", err)
+	}
+	err = s.LocalAccounts().SetVerified(u1)
+	if err != nil {
+		t.Fatalf("failed to set verified: %s", err)
+	}
+	a, err = s.LocalAccounts().Get(u1)
+	if err != nil {
+		t.Fatalf("failed to get a local account: %s", err)
+	}
+	if a.PasswordHash != "hash3" || !a.Verified {
+		t.Fatalf("bad updated account: %v", a)
+	}
+
+	_, err = s.LocalAccounts().Get(u1 + 1)
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound error, got %v", err)
+	}
+	_, err = s.LocalAccounts().GetByEmail("user2@example.com")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound error, got %v", err)
+	}
+	err = s.LocalAccounts().SetVerified(u1 + 1)
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound error, got %v", err)
+	}
+}
+
+func testAuthToken(t *testing.T, s store.Store) {
+	u1, err := s.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+
+	id1, err := s.AuthTokens().New(u1, store.AuthTokenVerifyEmail, "hash1", expiresAt)
+	if err != nil {
+		t.Fatalf("failed to create an auth token: %s", err)
+	}
+	_, err = s.AuthTokens().New(u1, store.AuthTokenResetPassword, "hash2", expiresAt)
+	if err != nil {
+		t.Fatalf("failed to create an auth token: %s", err)
+	}
+	_, err = s.AuthTokens().New(u1, store.AuthTokenResetPassword, "hash3", time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("failed to create an auth token: %s", err)
+	}
+
+	_, err = s.AuthTokens().New(u1, store.AuthTokenMagicLink, "hash1", expiresAt)
+	if err != store.ErrConflict {
+		t.Fatalf("expected store.ErrConflict on duplicate token hash, got %v", err)
+	}
+
+	_, err = s.AuthTokens().Use(store.AuthTokenResetPassword, "hash1")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound on using a token with another purpose, got %v", err)
+	}
+	_, err = s.AuthTokens().Use(store.AuthTokenResetPassword, "hash3")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound on using an expired token, got %v", err)
+	}
+
+	token, err := s.AuthTokens().Use(store.AuthTokenVerifyEmail, "hash1")
+	if err != nil {
+		t.Fatalf("failed to use an auth token: %s", err)
+	}
+	want := &store.AuthToken{ID: id1, UserID: u1, Purpose: store.AuthTokenVerifyEmail, TokenHash: "hash1", CreatedAt: token.CreatedAt, ExpiresAt: token.ExpiresAt}
+	if !reflect.DeepEqual(token, want) {
+		t.Fatalf("got token %v want %v", token, want)
+	}
+	if !token.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("got token.ExpiresAt %v want %v", token.ExpiresAt, expiresAt)
+	}
+
+	_, err = s.AuthTokens().Use(store.AuthTokenVerifyEmail, "hash1")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound on reusing a token, got %v", err)
+	}
+
+	err = s.AuthTokens().DeleteByUser(u1, store.AuthTokenResetPassword)
+	if err != nil {
+		t.Fatalf("failed to delete auth tokens: %s", err)
+	}
+	_, err = s.AuthTokens().Use(store.AuthTokenResetPassword, "hash2")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound on using a deleted token, got %v", err)
+	}
+}
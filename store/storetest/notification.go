@@ -0,0 +1,134 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testNotification(t *testing.T, s store.Store) {
+	u1, err := s.Users().New("service1", "user1")
+	if err != nil {
+		t.Fatalf("failed to create user1: %s", err)
+	}
+	u2, err := s.Users().New("service1", "user2")
+	if err != nil {
+		t.Fatalf("failed to create user2: %s", err)
+	}
+	u3, err := s.Users().New("service1", "user3")
+	if err != nil {
+		t.Fatalf("failed to create user3: %s", err)
+	}
+
+	t1, err := s.Topics().New(u1, 0, "topic1")
+	if err != nil {
+		t.Fatalf("failed to create topic1: %s", err)
+	}
+
+	// The topic author is notified of the reply once, the comment author is never notified.
+	c1, err := s.Comments().New(t1, u2, "comment1", []int64{u1, u2, u3, u3})
+	if err != nil {
+		t.Fatalf("failed to create comment1: %s", err)
+	}
+	// Nobody is notified about the author's own comment.
+	_, err = s.Comments().New(t1, u1, "comment2", []int64{u1})
+	if err != nil {
+		t.Fatalf("failed to create comment2: %s", err)
+	}
+	c3, err := s.Comments().New(t1, u3, "comment3", nil)
+	if err != nil {
+		t.Fatalf("failed to create comment3: %s", err)
+	}
+
+	notifications, count, err := s.Notifications().GetByUser(u1, false, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get notifications: %s", err)
+	}
+	if count != 2 || len(notifications) != 2 {
+		t.Fatalf("expected 2 notifications, got count %d len %d", count, len(notifications))
+	}
+	n := notifications[0]
+	if n.UserID != u1 || n.Type != store.NotificationReply || n.TopicID != t1 || n.TopicTitle != "topic1" || n.CommentID != c3 || n.ActorID != u3 || n.Read || n.CreatedAt.IsZero() {
+		t.Fatalf("bad notification: %+v", n)
+	}
+	if n := notifications[1]; n.Type != store.NotificationReply || n.CommentID != c1 || n.ActorID != u2 {
+		t.Fatalf("bad notification: %+v", n)
+	}
+
+	notifications, count, err = s.Notifications().GetByUser(u3, false, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get notifications: %s", err)
+	}
+	if count != 1 || len(notifications) != 1 {
+		t.Fatalf("expected 1 notification, got count %d len %d", count, len(notifications))
+	}
+	if n := notifications[0]; n.Type != store.NotificationMention || n.CommentID != c1 || n.ActorID != u2 {
+		t.Fatalf("bad notification: %+v", n)
+	}
+
+	_, count, err = s.Notifications().GetByUser(u2, false, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get notifications: %s", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no notifications for the comment author, got %d", count)
+	}
+
+	notifications, count, err = s.Notifications().GetByUser(u1, false, 1, 1)
+	if err != nil {
+		t.Fatalf("failed to get notifications: %s", err)
+	}
+	if count != 2 || len(notifications) != 1 || notifications[0].CommentID != c1 {
+		t.Fatalf("bad notifications page: count %d, %+v", count, notifications)
+	}
+
+	err = s.Notifications().MarkRead(u2, notifications[0].ID)
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound on marking another user's notification, got %v", err)
+	}
+	err = s.Notifications().MarkRead(u1, notifications[0].ID)
+	if err != nil {
+		t.Fatalf("failed to mark notification read: %s", err)
+	}
+
+	unread, err := s.Notifications().CountUnread(u1)
+	if err != nil {
+		t.Fatalf("failed to count unread notifications: %s", err)
+	}
+	if unread != 1 {
+		t.Fatalf("expected 1 unread notification, got %d", unread)
+	}
+
+	notifications, count, err = s.Notifications().GetByUser(u1, true, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get notifications: %s", err)
+	}
+	if count != 1 || len(notifications) != 1 || notifications[0].CommentID != c3 {
+		t.Fatalf("bad unread notifications: count %d, %+v", count, notifications)
+	}
+
+	err = s.Notifications().MarkAllRead(u1)
+	if err != nil {
+		t.Fatalf("failed to mark all notifications read: %s", err)
+	}
+	unread, err = s.Notifications().CountUnread(u1)
+	if err != nil {
+		t.Fatalf("failed to count unread notifications: %s", err)
+	}
+	if unread != 0 {
+		t.Fatalf("expected no unread notifications, got %d", unread)
+	}
+
+	// Notifications about deleted comments are hidden.
+	err = s.Comments().Delete(c1)
+	if err != nil {
+		t.Fatalf("failed to delete comment1: %s", err)
+	}
+	_, count, err = s.Notifications().GetByUser(u3, false, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get notifications: %s", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no notifications about a deleted comment, got %d", count)
+	}
+}
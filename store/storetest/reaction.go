@@ -0,0 +1,90 @@
+package storetest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testReaction(t *testing.T, s store.Store) {
+	u1, err := s.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+	u2, err := s.Users().New("service1", "uid2")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+
+	t1, err := s.Topics().New(u1, 0, "topic1")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+	c1, err := s.Comments().New(t1, u1, "comment1", nil)
+	if err != nil {
+		t.Fatalf("failed to create a comment: %s", err)
+	}
+	c2, err := s.Comments().New(t1, u1, "comment2", nil)
+	if err != nil {
+		t.Fatalf("failed to create a comment: %s", err)
+	}
+
+	for _, r := range []struct {
+		commentID int64
+		userID    int64
+		emoji     string
+	}{
+		{c1, u1, "heart"},
+		{c1, u2, "heart"},
+		{c1, u2, "+1"},
+		{c1, u1, "😀"},
+		{c2, u2, "+1"},
+	} {
+		err = s.Reactions().Add(r.commentID, r.userID, r.emoji)
+		if err != nil {
+			t.Fatalf("failed to add a reaction: %s", err)
+		}
+	}
+
+	err = s.Reactions().Add(c1, u1, "heart")
+	if err != store.ErrConflict {
+		t.Fatalf("expected error ErrConflict on duplicate reaction, got: %v", err)
+	}
+
+	err = s.Reactions().Remove(c1, u1, "😀")
+	if err != nil {
+		t.Fatalf("failed to remove a reaction: %s", err)
+	}
+
+	err = s.Reactions().Remove(c1, u1, "😀")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected error ErrNotFound on removing a missing reaction, got: %v", err)
+	}
+
+	counts, err := s.Reactions().GetCounts([]int64{c1, c2}, u1)
+	if err != nil {
+		t.Fatalf("failed to get reaction counts: %s", err)
+	}
+
+	want := map[int64][]*store.ReactionCount{
+		c1: {
+			{Emoji: "+1", Count: 1, Reacted: false},
+			{Emoji: "heart", Count: 2, Reacted: true},
+		},
+		c2: {
+			{Emoji: "+1", Count: 1, Reacted: false},
+		},
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Fatalf("got reaction counts %v want %v", counts, want)
+	}
+
+	counts, err = s.Reactions().GetCounts(nil, u1)
+	if err != nil {
+		t.Fatalf("failed to get reaction counts: %s", err)
+	}
+	if len(counts) != 0 {
+		t.Fatalf("expected no reaction counts, got %v", counts)
+	}
+}
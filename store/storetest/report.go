@@ -0,0 +1,117 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testReport(t *testing.T, s store.Store) {
+	u1, err := s.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+	u2, err := s.Users().New("service1", "uid2")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+	admin, err := s.Users().New("service1", "uid3")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+
+	r1, err := s.Reports().New(u1, store.ReportTargetComment, 10, u2, "spam")
+	if err != nil {
+		t.Fatalf("failed to create a report: %s", err)
+	}
+	r2, err := s.Reports().New(admin, store.ReportTargetComment, 10, u2, "offensive")
+	if err != nil {
+		t.Fatalf("failed to create a report: %s", err)
+	}
+	r3, err := s.Reports().New(u1, store.ReportTargetTopic, 10, u2, "off-topic")
+	if err != nil {
+		t.Fatalf("failed to create a report: %s", err)
+	}
+
+	_, err = s.Reports().New(u1, store.ReportTargetComment, 10, u2, "spam again")
+	if err != store.ErrConflict {
+		t.Fatalf("expected error ErrConflict on duplicate open report, got: %v", err)
+	}
+
+	report, err := s.Reports().Get(r1)
+	if err != nil {
+		t.Fatalf("failed to get a report: %s", err)
+	}
+
+	sinceCreated := time.Since(report.CreatedAt)
+	if sinceCreated > 3*time.Second || sinceCreated < 0 {
+		t.Fatalf("bad report.CreatedAt: %v", report.CreatedAt)
+	}
+
+	if report.ID != r1 || report.ReporterID != u1 || report.TargetType != store.ReportTargetComment ||
+		report.TargetID != 10 || report.AuthorID != u2 || report.Reason != "spam" ||
+		report.Status != store.ReportStatusOpen || report.ResolvedBy != 0 || report.ResolvedAt != nil {
+		t.Fatalf("bad report: %#v", report)
+	}
+
+	reports, count, err := s.Reports().GetByStatus(store.ReportStatusOpen, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get open reports: %s", err)
+	}
+	if count != 3 || len(reports) != 3 || reports[0].ID != r1 || reports[1].ID != r2 || reports[2].ID != r3 {
+		t.Fatalf("bad open reports: %d, %v", count, reports)
+	}
+
+	err = s.Reports().Resolve(r1, store.ReportStatusDeleted, admin)
+	if err != nil {
+		t.Fatalf("failed to resolve a report: %s", err)
+	}
+
+	err = s.Reports().Resolve(r2, store.ReportStatusDismissed, admin)
+	if err != store.ErrConflict {
+		t.Fatalf("expected error ErrConflict on resolving a resolved report, got: %v", err)
+	}
+
+	err = s.Reports().Resolve(r3+100, store.ReportStatusDismissed, admin)
+	if err != store.ErrNotFound {
+		t.Fatalf("expected error ErrNotFound on resolving a missing report, got: %v", err)
+	}
+
+	// Both reports about the same comment are resolved.
+	for _, id := range []int64{r1, r2} {
+		report, err = s.Reports().Get(id)
+		if err != nil {
+			t.Fatalf("failed to get a report: %s", err)
+		}
+		if report.Status != store.ReportStatusDeleted || report.ResolvedBy != admin || report.ResolvedAt == nil {
+			t.Fatalf("bad resolved report: %#v", report)
+		}
+		sinceResolved := time.Since(*report.ResolvedAt)
+		if sinceResolved > 3*time.Second || sinceResolved < 0 {
+			t.Fatalf("bad report.ResolvedAt: %v", report.ResolvedAt)
+		}
+	}
+
+	reports, count, err = s.Reports().GetByStatus(store.ReportStatusOpen, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get open reports: %s", err)
+	}
+	if count != 1 || len(reports) != 1 || reports[0].ID != r3 {
+		t.Fatalf("bad open reports: %d, %v", count, reports)
+	}
+
+	reports, count, err = s.Reports().GetByStatus("", 1, 1)
+	if err != nil {
+		t.Fatalf("failed to get all reports: %s", err)
+	}
+	if count != 3 || len(reports) != 1 || reports[0].ID != r2 {
+		t.Fatalf("bad reports: %d, %v", count, reports)
+	}
+
+	// The reporter can report the same content again once the report is resolved.
+	_, err = s.Reports().New(u1, store.ReportTargetComment, 10, u2, "spam again")
+	if err != nil {
+		t.Fatalf("failed to create a report: %s", err)
+	}
+}
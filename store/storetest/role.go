@@ -0,0 +1,184 @@
+package storetest
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testRole(t *testing.T, s store.Store) {
+	u1, err := s.Users().New("service1", "user1")
+	if err != nil {
+		t.Fatalf("failed to create user1: %s", err)
+	}
+	u2, err := s.Users().New("service1", "user2")
+	if err != nil {
+		t.Fatalf("failed to create user2: %s", err)
+	}
+
+	roles, err := s.Roles().GetByUser(u1)
+	if err != nil {
+		t.Fatalf("failed to get user roles: %s", err)
+	}
+	if len(roles) != 0 {
+		t.Fatalf("expected no roles, got %v", roles)
+	}
+
+	for _, g := range []struct {
+		userID int64
+		role   string
+	}{
+		{u1, store.RoleModerator},
+		{u1, store.RoleAdmin},
+		{u2, store.RoleModerator},
+	} {
+		err = s.Roles().Grant(g.userID, g.role)
+		if err != nil {
+			t.Fatalf("failed to grant role %q to user %d: %s", g.role, g.userID, err)
+		}
+	}
+
+	err = s.Roles().Grant(u1, store.RoleAdmin)
+	if err != store.ErrConflict {
+		t.Fatalf("expected store.ErrConflict on granting a role twice, got %v", err)
+	}
+
+	roles, err = s.Roles().GetByUser(u1)
+	if err != nil {
+		t.Fatalf("failed to get user roles: %s", err)
+	}
+	if want := []string{store.RoleAdmin, store.RoleModerator}; !reflect.DeepEqual(roles, want) {
+		t.Fatalf("got roles %v want %v", roles, want)
+	}
+
+	userIDs, err := s.Roles().GetUsers(store.RoleModerator)
+	if err != nil {
+		t.Fatalf("failed to get role users: %s", err)
+	}
+	if want := []int64{u1, u2}; !reflect.DeepEqual(userIDs, want) {
+		t.Fatalf("got role users %v want %v", userIDs, want)
+	}
+
+	err = s.Roles().Revoke(u1, store.RoleModerator)
+	if err != nil {
+		t.Fatalf("failed to revoke a role: %s", err)
+	}
+
+	err = s.Roles().Revoke(u1, store.RoleModerator)
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound on revoking a missing role, got %v", err)
+	}
+
+	roles, err = s.Roles().GetByUser(u1)
+	if err != nil {
+		t.Fatalf("failed to get user roles: %s", err)
+	}
+	if want := []string{store.RoleAdmin}; !reflect.DeepEqual(roles, want) {
+		t.Fatalf("got roles %v want %v", roles, want)
+	}
+
+	userIDs, err = s.Roles().GetUsers(store.RoleModerator)
+	if err != nil {
+		t.Fatalf("failed to get role users: %s", err)
+	}
+	if want := []int64{u2}; !reflect.DeepEqual(userIDs, want) {
+		t.Fatalf("got role users %v want %v", userIDs, want)
+	}
+}
+
+func testRolePermission(t *testing.T, s store.Store) {
+	for role, defaults := range store.DefaultRolePermissions {
+		want := append([]string{}, defaults...)
+		sort.Strings(want)
+		got, err := s.Roles().GetPermissions([]string{role})
+		if err != nil {
+			t.Fatalf("failed to get role permissions: %s", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %s permissions %v want %v", role, got, want)
+		}
+	}
+
+	got, err := s.Roles().GetPermissions(nil)
+	if err != nil {
+		t.Fatalf("failed to get role permissions: %s", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no permissions, got %v", got)
+	}
+
+	u1, err := s.Users().New("service1", "user1")
+	if err != nil {
+		t.Fatalf("failed to create user1: %s", err)
+	}
+	err = s.Roles().Grant(u1, "editor")
+	if err != nil {
+		t.Fatalf("failed to grant role: %s", err)
+	}
+
+	ok, err := s.Roles().HasPermission(u1, store.PermTopicEdit)
+	if err != nil {
+		t.Fatalf("failed to check permission: %s", err)
+	}
+	if ok {
+		t.Fatalf("expected no permission before the grant")
+	}
+
+	for _, p := range []string{store.PermTopicEdit, store.PermCommentEdit} {
+		err = s.Roles().GrantPermission("editor", p)
+		if err != nil {
+			t.Fatalf("failed to grant permission %q: %s", p, err)
+		}
+	}
+
+	err = s.Roles().GrantPermission("editor", store.PermTopicEdit)
+	if err != store.ErrConflict {
+		t.Fatalf("expected store.ErrConflict on granting a permission twice, got %v", err)
+	}
+
+	ok, err = s.Roles().HasPermission(u1, store.PermTopicEdit)
+	if err != nil {
+		t.Fatalf("failed to check permission: %s", err)
+	}
+	if !ok {
+		t.Fatalf("expected permission after the grant")
+	}
+
+	got, err = s.Roles().GetPermissions([]string{"editor", store.RoleModerator})
+	if err != nil {
+		t.Fatalf("failed to get role permissions: %s", err)
+	}
+	want := append([]string{store.PermTopicEdit, store.PermCommentEdit}, store.DefaultRolePermissions[store.RoleModerator]...)
+	sort.Strings(want)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got permissions %v want %v", got, want)
+	}
+
+	err = s.Roles().RevokePermission("editor", store.PermTopicEdit)
+	if err != nil {
+		t.Fatalf("failed to revoke permission: %s", err)
+	}
+
+	err = s.Roles().RevokePermission("editor", store.PermTopicEdit)
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound on revoking a missing permission, got %v", err)
+	}
+
+	ok, err = s.Roles().HasPermission(u1, store.PermTopicEdit)
+	if err != nil {
+		t.Fatalf("failed to check permission: %s", err)
+	}
+	if ok {
+		t.Fatalf("expected no permission after the revoke")
+	}
+
+	got, err = s.Roles().GetPermissions([]string{"editor"})
+	if err != nil {
+		t.Fatalf("failed to get role permissions: %s", err)
+	}
+	if want := []string{store.PermCommentEdit}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got permissions %v want %v", got, want)
+	}
+}
@@ -0,0 +1,141 @@
+package storetest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testSession(t *testing.T, s store.Store) {
+	u1, err := s.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+	u2, err := s.Users().New("service1", "uid2")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+
+	id1, err := s.Sessions().New(u1, "hash1", expiresAt)
+	if err != nil {
+		t.Fatalf("failed to create a session: %s", err)
+	}
+	id2, err := s.Sessions().New(u1, "hash2", expiresAt)
+	if err != nil {
+		t.Fatalf("failed to create a session: %s", err)
+	}
+	id3, err := s.Sessions().New(u2, "hash3", expiresAt)
+	if err != nil {
+		t.Fatalf("failed to create a session: %s", err)
+	}
+
+	_, err = s.Sessions().New(u2, "hash3", expiresAt)
+	if err != store.ErrConflict {
+		t.Fatalf("expected store.ErrConflict on duplicate token hash, got %v", err)
+	}
+
+	session, err := s.Sessions().GetByToken("hash1")
+	if err != nil {
+		t.Fatalf("failed to get a session: %s", err)
+	}
+	sinceCreated := time.Since(session.CreatedAt)
+	if sinceCreated > 3*time.Second || sinceCreated < 0 {
+		t.Fatalf("bad session.CreatedAt: %v", session.CreatedAt)
+	}
+	want := &store.Session{ID: id1, UserID: u1, TokenHash: "hash1", CreatedAt: session.CreatedAt, ExpiresAt: session.ExpiresAt}
+	if !reflect.DeepEqual(session, want) {
+		t.Fatalf("got session %v want %v", session, want)
+	}
+	if !session.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("got session.ExpiresAt %v want %v", session.ExpiresAt, expiresAt)
+	}
+
+	_, err = s.Sessions().GetByToken("hash4")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound error, got %v", err)
+	}
+
+	err = s.Sessions().Rotate(id1, "hash2", "hash4", expiresAt)
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound on rotating with a wrong hash, got %v", err)
+	}
+	err = s.Sessions().Rotate(id1, "hash1", "hash4", expiresAt.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to rotate a session: %s", err)
+	}
+	_, err = s.Sessions().GetByToken("hash1")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound error, got %v", err)
+	}
+	session, err = s.Sessions().GetByToken("hash4")
+	if err != nil {
+		t.Fatalf("failed to get a session: %s", err)
+	}
+	if session.ID != id1 || !session.ExpiresAt.Equal(expiresAt.Add(time.Hour)) {
+		t.Fatalf("bad rotated session: %v", session)
+	}
+
+	err = s.Sessions().Delete(id2)
+	if err != nil {
+		t.Fatalf("failed to delete a session: %s", err)
+	}
+	err = s.Sessions().Delete(id2)
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound on deleting twice, got %v", err)
+	}
+
+	err = s.Sessions().DeleteByUser(u1)
+	if err != nil {
+		t.Fatalf("failed to delete user sessions: %s", err)
+	}
+	_, err = s.Sessions().GetByToken("hash4")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound error, got %v", err)
+	}
+	session, err = s.Sessions().GetByToken("hash3")
+	if err != nil || session.ID != id3 {
+		t.Fatalf("failed to get a session of another user: %v, %s", session, err)
+	}
+
+	// Creating a session removes the expired sessions of the user.
+	_, err = s.Sessions().New(u2, "hash5", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("failed to create a session: %s", err)
+	}
+	_, err = s.Sessions().New(u2, "hash6", expiresAt)
+	if err != nil {
+		t.Fatalf("failed to create a session: %s", err)
+	}
+	_, err = s.Sessions().GetByToken("hash5")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected store.ErrNotFound for an expired session, got %v", err)
+	}
+	_, err = s.Sessions().GetByToken("hash3")
+	if err != nil {
+		t.Fatalf("failed to get a session: %s", err)
+	}
+
+	validAfter := time.Now().Truncate(time.Microsecond)
+	err = s.Users().SetTokensValidAfter(u1, validAfter)
+	if err != nil {
+		t.Fatalf("failed to SetTokensValidAfter: %s", err)
+	}
+	user, err := s.Users().Get(u1)
+	if err != nil {
+		t.Fatalf("failed to get a user: %s", err)
+	}
+	if !user.TokensValidAfter.Equal(validAfter) {
+		t.Fatalf("got user.TokensValidAfter %v want %v", user.TokensValidAfter, validAfter)
+	}
+	user, err = s.Users().Get(u2)
+	if err != nil {
+		t.Fatalf("failed to get a user: %s", err)
+	}
+	if !user.TokensValidAfter.IsZero() {
+		t.Fatalf("got user.TokensValidAfter %v want zero time", user.TokensValidAfter)
+	}
+}
@@ -0,0 +1,47 @@
+// Package storetest provides the data store tests shared by the store implementations.
+// Every implementation runs them from its own tests with Run.
+package storetest
+
+import (
+	"testing"
+
+	"github.com/disintegration/bebop/store"
+)
+
+// Run runs the shared data store tests. Every test gets a new empty store from
+// newStore, which returns the store along with a teardown function called after the test.
+func Run(t *testing.T, newStore func(t *testing.T) (store.Store, func())) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s store.Store)
+	}{
+		{"UserNameCase", testUserNameCase},
+		{"TopicSearch", testTopicSearch},
+		{"TopicPinnedAndLocked", testTopicPinnedAndLocked},
+		{"TopicCursor", testTopicCursor},
+		{"CommentSearch", testCommentSearch},
+		{"CommentCursor", testCommentCursor},
+		{"Category", testCategory},
+		{"Reaction", testReaction},
+		{"Report", testReport},
+		{"Audit", testAudit},
+		{"Session", testSession},
+		{"LocalAccount", testLocalAccount},
+		{"AuthToken", testAuthToken},
+		{"Identity", testIdentity},
+		{"Role", testRole},
+		{"RolePermission", testRolePermission},
+		{"Notification", testNotification},
+		{"Watch", testWatch},
+		{"Digest", testDigest},
+		{"Webhook", testWebhook},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, teardown := newStore(t)
+			defer teardown()
+
+			tc.test(t, s)
+		})
+	}
+}
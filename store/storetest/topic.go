@@ -0,0 +1,191 @@
+package storetest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testTopicSearch(t *testing.T, s store.Store) {
+	u1, err := s.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+
+	t1, err := s.Topics().New(u1, 0, "Gopher conference announcement")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+	t2, err := s.Topics().New(u1, 0, "Weekly gopher meetup")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+	_, err = s.Topics().New(u1, 0, "Unrelated discussion")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+
+	topics, count, err := s.Topics().Search("gopher", 0, 10)
+	if err != nil {
+		t.Fatalf("failed to search topics: %s", err)
+	}
+	if count != 2 || len(topics) != 2 {
+		t.Fatalf("bad search result: count %d, len %d", count, len(topics))
+	}
+
+	topics, count, err = s.Topics().Search("gopher meetup", 0, 10)
+	if err != nil {
+		t.Fatalf("failed to search topics: %s", err)
+	}
+	if count != 1 || len(topics) != 1 || topics[0].ID != t2 {
+		t.Fatalf("bad search result: count %d, topics %v", count, topics)
+	}
+
+	topics, count, err = s.Topics().Search("gopher", 10, 10)
+	if err != nil {
+		t.Fatalf("failed to search topics: %s", err)
+	}
+	if count != 2 || len(topics) != 0 {
+		t.Fatalf("bad search result: count %d, len %d", count, len(topics))
+	}
+
+	err = s.Topics().Delete(t1)
+	if err != nil {
+		t.Fatalf("failed to delete topic: %s", err)
+	}
+
+	topics, count, err = s.Topics().Search("gopher", 0, 10)
+	if err != nil {
+		t.Fatalf("failed to search topics: %s", err)
+	}
+	if count != 1 || len(topics) != 1 || topics[0].ID != t2 {
+		t.Fatalf("bad search result: count %d, topics %v", count, topics)
+	}
+
+	topics, count, err = s.Topics().Search("nothing", 0, 10)
+	if err != nil {
+		t.Fatalf("failed to search topics: %s", err)
+	}
+	if count != 0 || topics == nil || len(topics) != 0 {
+		t.Fatalf("bad search result: count %d, topics %v", count, topics)
+	}
+}
+
+func testTopicPinnedAndLocked(t *testing.T, s store.Store) {
+	u1, err := s.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+
+	t1, err := s.Topics().New(u1, 0, "topic1")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+	t2, err := s.Topics().New(u1, 0, "topic2")
+	if err != nil {
+		t.Fatalf("failed to create a topic: %s", err)
+	}
+	_, err = s.Comments().New(t2, u1, "comment", nil)
+	if err != nil {
+		t.Fatalf("failed to create a comment: %s", err)
+	}
+
+	topics, _, err := s.Topics().GetLatest(0, 10)
+	if err != nil {
+		t.Fatalf("failed to get latest topics: %s", err)
+	}
+	if len(topics) != 2 || topics[0].ID != t2 || topics[1].ID != t1 {
+		t.Fatalf("bad latest topics: %v", topics)
+	}
+
+	err = s.Topics().SetPinned(t1, true)
+	if err != nil {
+		t.Fatalf("failed to pin a topic: %s", err)
+	}
+	err = s.Topics().SetLocked(t2, true)
+	if err != nil {
+		t.Fatalf("failed to lock a topic: %s", err)
+	}
+
+	topics, _, err = s.Topics().GetLatest(0, 10)
+	if err != nil {
+		t.Fatalf("failed to get latest topics: %s", err)
+	}
+	if len(topics) != 2 || topics[0].ID != t1 || topics[1].ID != t2 {
+		t.Fatalf("bad latest topics: %v", topics)
+	}
+	if !topics[0].Pinned || topics[0].Locked || topics[1].Pinned || !topics[1].Locked {
+		t.Fatalf("bad pinned/locked flags: %v, %v", topics[0], topics[1])
+	}
+
+	err = s.Topics().SetPinned(t1, false)
+	if err != nil {
+		t.Fatalf("failed to unpin a topic: %s", err)
+	}
+
+	topics, _, err = s.Topics().GetLatest(0, 10)
+	if err != nil {
+		t.Fatalf("failed to get latest topics: %s", err)
+	}
+	if len(topics) != 2 || topics[0].ID != t2 || topics[1].ID != t1 {
+		t.Fatalf("bad latest topics: %v", topics)
+	}
+}
+
+func testTopicCursor(t *testing.T, s store.Store) {
+	u1, err := s.Users().New("service1", "uid1")
+	if err != nil {
+		t.Fatalf("failed to create a user: %s", err)
+	}
+
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		id, err := s.Topics().New(u1, 0, fmt.Sprintf("topic%d", i))
+		if err != nil {
+			t.Fatalf("failed to create a topic: %s", err)
+		}
+		ids = append(ids, id)
+	}
+
+	err = s.Topics().SetPinned(ids[1], true)
+	if err != nil {
+		t.Fatalf("failed to pin a topic: %s", err)
+	}
+
+	want := []int64{ids[1], ids[4], ids[3], ids[2], ids[0]}
+
+	var got []int64
+	var cursor *store.TopicCursor
+	for i := 0; i < 10; i++ {
+		topics, err := s.Topics().GetLatestAfter(cursor, 2)
+		if err != nil {
+			t.Fatalf("failed to get latest topics after cursor: %s", err)
+		}
+		if len(topics) == 0 {
+			break
+		}
+		for _, topic := range topics {
+			got = append(got, topic.ID)
+		}
+
+		// Round-trip the cursor through its string encoding.
+		cursor, err = store.ParseTopicCursor(store.NewTopicCursor(topics[len(topics)-1]).String())
+		if err != nil {
+			t.Fatalf("failed to parse topic cursor: %s", err)
+		}
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got topics %v want %v", got, want)
+	}
+
+	topics, err := s.Topics().GetByCategoryAfter(1, nil, 10)
+	if err != nil {
+		t.Fatalf("failed to get topics by category after cursor: %s", err)
+	}
+	if len(topics) != 0 {
+		t.Fatalf("expected no topics in category, got %v", topics)
+	}
+}
@@ -0,0 +1,36 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testUserNameCase(t *testing.T, s store.Store) {
+	id, err := s.Users().New("service1", "user1")
+	if err != nil {
+		t.Fatalf("failed to create user: %s", err)
+	}
+	err = s.Users().SetName(id, "user1")
+	if err != nil {
+		t.Fatalf("failed to SetName: %s", err)
+	}
+
+	got, err := s.Users().GetByNameFold("USER1")
+	if err != nil {
+		t.Fatalf("failed to get user by name ignoring case: %s", err)
+	}
+	if got.ID != id || got.Name != "user1" {
+		t.Fatalf("got user %v, want %d", got, id)
+	}
+
+	_, err = s.Users().GetByName("USER1")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected error ErrNotFound on getting user by name in another case, got: %v", err)
+	}
+
+	_, err = s.Users().GetByNameFold("user2")
+	if err != store.ErrNotFound {
+		t.Fatalf("expected error ErrNotFound on getting a missing user by name, got: %v", err)
+	}
+}
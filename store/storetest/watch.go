@@ -0,0 +1,70 @@
+package storetest
+
+import (
+	"testing"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testWatch(t *testing.T, s store.Store) {
+	u1, err := s.Users().New("service1", "user1")
+	if err != nil {
+		t.Fatalf("failed to create user1: %s", err)
+	}
+	u2, err := s.Users().New("service1", "user2")
+	if err != nil {
+		t.Fatalf("failed to create user2: %s", err)
+	}
+
+	t1, err := s.Topics().New(u1, 0, "topic1")
+	if err != nil {
+		t.Fatalf("failed to create topic1: %s", err)
+	}
+
+	watching, err := s.Watches().IsWatching(u1, t1)
+	if err != nil {
+		t.Fatalf("failed to check watch: %s", err)
+	}
+	if watching {
+		t.Fatalf("expected the topic not to be watched")
+	}
+
+	// Watching is idempotent.
+	for i := 0; i < 2; i++ {
+		err = s.Watches().Watch(u1, t1)
+		if err != nil {
+			t.Fatalf("failed to watch topic: %s", err)
+		}
+	}
+
+	watching, err = s.Watches().IsWatching(u1, t1)
+	if err != nil {
+		t.Fatalf("failed to check watch: %s", err)
+	}
+	if !watching {
+		t.Fatalf("expected the topic to be watched")
+	}
+
+	watching, err = s.Watches().IsWatching(u2, t1)
+	if err != nil {
+		t.Fatalf("failed to check watch: %s", err)
+	}
+	if watching {
+		t.Fatalf("expected the topic not to be watched by user2")
+	}
+
+	for i := 0; i < 2; i++ {
+		err = s.Watches().Unwatch(u1, t1)
+		if err != nil {
+			t.Fatalf("failed to unwatch topic: %s", err)
+		}
+	}
+
+	watching, err = s.Watches().IsWatching(u1, t1)
+	if err != nil {
+		t.Fatalf("failed to check watch: %s", err)
+	}
+	if watching {
+		t.Fatalf("expected the topic not to be watched after unwatch")
+	}
+}
@@ -0,0 +1,187 @@
+package storetest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+)
+
+func testWebhook(t *testing.T, s store.Store) {
+	webhooks, err := s.Webhooks().GetAll()
+	if err != nil {
+		t.Fatalf("failed to get webhooks: %s", err)
+	}
+	if len(webhooks) != 0 {
+		t.Fatalf("expected no webhooks, got %d", len(webhooks))
+	}
+
+	w1, err := s.Webhooks().New("https://example.test/hook1", "secret1", []string{store.WebhookTopicCreated, store.WebhookCommentCreated})
+	if err != nil {
+		t.Fatalf("failed to create webhook1: %s", err)
+	}
+	w2, err := s.Webhooks().New("https://example.test/hook2", "secret2", []string{store.WebhookCommentCreated})
+	if err != nil {
+		t.Fatalf("failed to create webhook2: %s", err)
+	}
+
+	w, err := s.Webhooks().Get(w1)
+	if err != nil {
+		t.Fatalf("failed to get webhook1: %s", err)
+	}
+	if w.ID != w1 || w.URL != "https://example.test/hook1" || w.Secret != "secret1" || w.CreatedAt.IsZero() ||
+		!reflect.DeepEqual(w.Events, []string{store.WebhookTopicCreated, store.WebhookCommentCreated}) {
+		t.Fatalf("bad webhook: %+v", w)
+	}
+
+	_, err = s.Webhooks().Get(100)
+	if err != store.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	webhooks, err = s.Webhooks().GetAll()
+	if err != nil {
+		t.Fatalf("failed to get webhooks: %s", err)
+	}
+	if len(webhooks) != 2 || webhooks[0].ID != w1 || webhooks[1].ID != w2 {
+		t.Fatalf("bad webhooks: %+v", webhooks)
+	}
+
+	err = s.Webhooks().Enqueue(store.WebhookTopicCreated, []byte(`{"event":"topic.created"}`))
+	if err != nil {
+		t.Fatalf("failed to enqueue event: %s", err)
+	}
+	err = s.Webhooks().Enqueue(store.WebhookCommentCreated, []byte(`{"event":"comment.created"}`))
+	if err != nil {
+		t.Fatalf("failed to enqueue event: %s", err)
+	}
+	err = s.Webhooks().Enqueue(store.WebhookUserBlocked, []byte(`{"event":"user.blocked"}`))
+	if err != nil {
+		t.Fatalf("failed to enqueue event: %s", err)
+	}
+
+	due, err := s.Webhooks().ClaimDue(time.Now().Add(-time.Minute), time.Now(), 10)
+	if err != nil {
+		t.Fatalf("failed to claim due deliveries: %s", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("expected no due deliveries, got %d", len(due))
+	}
+
+	now := time.Now().Add(time.Minute)
+	lockedUntil := now.Add(time.Minute)
+	due, err = s.Webhooks().ClaimDue(now, lockedUntil, 1)
+	if err != nil {
+		t.Fatalf("failed to claim due deliveries: %s", err)
+	}
+	if len(due) != 1 {
+		t.Fatalf("expected 1 due delivery, got %d", len(due))
+	}
+	d := due[0]
+	if d.WebhookID != w1 || d.Event != store.WebhookTopicCreated || string(d.Payload) != `{"event":"topic.created"}` ||
+		d.Status != store.WebhookDeliveryPending || d.Attempts != 0 || d.ResponseCode != 0 || d.Error != "" ||
+		d.NextAttemptAt.IsZero() || d.CreatedAt.IsZero() || d.UpdatedAt.IsZero() {
+		t.Fatalf("bad delivery: %+v", d)
+	}
+
+	// Claimed deliveries are skipped until the claim expires.
+	due, err = s.Webhooks().ClaimDue(now, lockedUntil, 10)
+	if err != nil {
+		t.Fatalf("failed to claim due deliveries: %s", err)
+	}
+	if len(due) != 2 {
+		t.Fatalf("expected 2 due deliveries, got %d", len(due))
+	}
+	if due[0].WebhookID != w1 || due[0].Event != store.WebhookCommentCreated || due[1].WebhookID != w2 || due[1].Event != store.WebhookCommentCreated {
+		t.Fatalf("bad deliveries: %+v %+v", due[0], due[1])
+	}
+	due, err = s.Webhooks().ClaimDue(now, lockedUntil, 10)
+	if err != nil {
+		t.Fatalf("failed to claim due deliveries: %s", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("expected the deliveries to be claimed, got %+v", due)
+	}
+	due, err = s.Webhooks().ClaimDue(lockedUntil, lockedUntil.Add(time.Minute), 10)
+	if err != nil {
+		t.Fatalf("failed to claim due deliveries: %s", err)
+	}
+	if len(due) != 3 || due[0].ID != d.ID {
+		t.Fatalf("expected the expired claims to be claimed again, got %+v", due)
+	}
+
+	// Recording an attempt releases the claim.
+	err = s.Webhooks().SetDeliveryResult(d.ID, store.WebhookDeliveryPending, 500, "bad status", time.Now())
+	if err != nil {
+		t.Fatalf("failed to set delivery result: %s", err)
+	}
+	due, err = s.Webhooks().ClaimDue(lockedUntil, lockedUntil.Add(time.Minute), 10)
+	if err != nil {
+		t.Fatalf("failed to claim due deliveries: %s", err)
+	}
+	if len(due) != 1 || due[0].ID != d.ID || due[0].Attempts != 1 {
+		t.Fatalf("expected the released delivery, got %+v", due)
+	}
+
+	// A failed attempt is retried later.
+	retryAt := time.Now().Add(time.Hour)
+	err = s.Webhooks().SetDeliveryResult(d.ID, store.WebhookDeliveryPending, 500, "bad status", retryAt)
+	if err != nil {
+		t.Fatalf("failed to set delivery result: %s", err)
+	}
+	due, err = s.Webhooks().ClaimDue(retryAt.Add(time.Minute), retryAt.Add(2*time.Minute), 10)
+	if err != nil {
+		t.Fatalf("failed to claim due deliveries: %s", err)
+	}
+	if len(due) != 3 || due[2].ID != d.ID {
+		t.Fatalf("expected the retried delivery last, got %+v", due)
+	}
+
+	err = s.Webhooks().SetDeliveryResult(d.ID, store.WebhookDeliverySucceeded, 200, "", retryAt)
+	if err != nil {
+		t.Fatalf("failed to set delivery result: %s", err)
+	}
+
+	deliveries, count, err := s.Webhooks().GetDeliveries(w1, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get deliveries: %s", err)
+	}
+	if count != 2 || len(deliveries) != 2 || deliveries[1].ID != d.ID {
+		t.Fatalf("bad deliveries: count %d, %+v", count, deliveries)
+	}
+	if d := deliveries[1]; d.Status != store.WebhookDeliverySucceeded || d.Attempts != 3 || d.ResponseCode != 200 || d.Error != "" {
+		t.Fatalf("bad delivery: %+v", d)
+	}
+
+	deliveries, count, err = s.Webhooks().GetDeliveries(w1, 1, 10)
+	if err != nil {
+		t.Fatalf("failed to get deliveries: %s", err)
+	}
+	if count != 2 || len(deliveries) != 1 || deliveries[0].ID != d.ID {
+		t.Fatalf("bad deliveries page: count %d, %+v", count, deliveries)
+	}
+
+	err = s.Webhooks().Delete(w1)
+	if err != nil {
+		t.Fatalf("failed to delete webhook1: %s", err)
+	}
+	_, err = s.Webhooks().Get(w1)
+	if err != store.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	_, count, err = s.Webhooks().GetDeliveries(w1, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get deliveries: %s", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected the deliveries to be deleted, got %d", count)
+	}
+	due, err = s.Webhooks().ClaimDue(retryAt.Add(3*time.Minute), retryAt.Add(4*time.Minute), 10)
+	if err != nil {
+		t.Fatalf("failed to claim due deliveries: %s", err)
+	}
+	if len(due) != 1 || due[0].WebhookID != w2 {
+		t.Fatalf("expected the webhook2 delivery, got %+v", due)
+	}
+}
@@ -9,10 +9,23 @@ import (
 type Topic struct {
 	ID            int64     `json:"id"`
 	AuthorID      int64     `json:"authorId"`
+	CategoryID    int64     `json:"categoryId"`
 	Title         string    `json:"title"`
 	CreatedAt     time.Time `json:"createdAt"`
 	LastCommentAt time.Time `json:"lastCommentAt"`
 	CommentCount  int       `json:"commentCount"`
+	Pinned        bool      `json:"pinned"`
+	Locked        bool      `json:"locked"`
+}
+
+// TopicRevision is a single version of an edited topic title.
+// The first revision holds the original title written by the topic author.
+type TopicRevision struct {
+	ID        int64     `json:"id"`
+	TopicID   int64     `json:"topicId"`
+	EditorID  int64     `json:"editorId"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 const (
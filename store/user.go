@@ -1,12 +1,16 @@
 package store
 
 import (
+	"strconv"
 	"time"
+	"unicode"
 	"unicode/utf8"
 )
 
 // User represents an authenticated user.
 // Only public fields are marshalled to JSON by default.
+// AuthService and AuthID are the identity the user signed up with,
+// the IdentityStore keeps all of them.
 type User struct {
 	ID          int64     `json:"id"`
 	Name        string    `json:"name"`
@@ -14,8 +18,9 @@ type User struct {
 	AuthService string    `json:"-"`
 	AuthID      string    `json:"-"`
 	Blocked     bool      `json:"-"`
-	Admin       bool      `json:"-"`
 	Avatar      string    `json:"avatar"`
+	// TokensValidAfter is the time before which all the user auth tokens are revoked.
+	TokensValidAfter time.Time `json:"-"`
 }
 
 const (
@@ -55,3 +60,38 @@ func ValidUserName(userName string) bool {
 
 	return true
 }
+
+// SuggestUserName converts the given display name to a valid user name:
+// spaces and dots are replaced with underscores and other invalid characters are dropped.
+// If n > 1, it is appended to the name, e.g. to find a name that is not taken.
+// It returns an empty string if the display name has too few valid characters.
+func SuggestUserName(displayName string, n int) string {
+	var name []byte
+	separate := false
+	for _, r := range displayName {
+		switch {
+		case validUserNameRune(r):
+			if separate && len(name) > 0 {
+				name = append(name, '_')
+			}
+			separate = false
+			name = append(name, byte(r))
+		case unicode.IsSpace(r) || r == '.':
+			separate = true
+		}
+	}
+
+	if len(name) < userNameMinLen {
+		return ""
+	}
+
+	var suffix string
+	if n > 1 {
+		suffix = strconv.Itoa(n)
+	}
+	if len(name)+len(suffix) > userNameMaxLen {
+		name = name[:userNameMaxLen-len(suffix)]
+	}
+
+	return string(name) + suffix
+}
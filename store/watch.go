@@ -0,0 +1,33 @@
+package store
+
+import (
+	"time"
+)
+
+// Email digest modes. The new comments in the watched topics are emailed
+// to the user as soon as possible, once a day or not at all.
+const (
+	DigestOff       = "off"
+	DigestDaily     = "daily"
+	DigestImmediate = "immediate"
+)
+
+// ValidDigestMode checks if mode is a valid email digest mode.
+func ValidDigestMode(mode string) bool {
+	switch mode {
+	case DigestOff, DigestDaily, DigestImmediate:
+		return true
+	}
+	return false
+}
+
+// DigestComment is a new comment in a watched topic queued for a digest email.
+type DigestComment struct {
+	CommentID  int64     `json:"commentId"`
+	TopicID    int64     `json:"topicId"`
+	TopicTitle string    `json:"topicTitle"`
+	AuthorID   int64     `json:"authorId"`
+	AuthorName string    `json:"authorName"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
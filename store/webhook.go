@@ -0,0 +1,80 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook is an HTTP endpoint the forum events are posted to.
+// The requests are signed with the secret.
+type Webhook struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Webhook events.
+const (
+	WebhookTopicCreated   = "topic.created"
+	WebhookTopicDeleted   = "topic.deleted"
+	WebhookCommentCreated = "comment.created"
+	WebhookCommentDeleted = "comment.deleted"
+	WebhookUserBlocked    = "user.blocked"
+	WebhookUserUnblocked  = "user.unblocked"
+)
+
+// WebhookEvents is the list of all the webhook events.
+var WebhookEvents = []string{
+	WebhookTopicCreated,
+	WebhookTopicDeleted,
+	WebhookCommentCreated,
+	WebhookCommentDeleted,
+	WebhookUserBlocked,
+	WebhookUserUnblocked,
+}
+
+// ValidWebhookEvent checks if event is a valid webhook event.
+func ValidWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribed checks if the webhook is subscribed to the event.
+func (w *Webhook) Subscribed(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is a webhook request. A pending delivery is retried
+// until it succeeds or runs out of attempts.
+type WebhookDelivery struct {
+	ID        int64           `json:"id"`
+	WebhookID int64           `json:"webhookId"`
+	Event     string          `json:"event"`
+	Payload   json.RawMessage `json:"payload"`
+	Status    string          `json:"status"`
+	Attempts  int             `json:"attempts"`
+	// ResponseCode is the HTTP status code of the last attempt, zero if there was no response.
+	ResponseCode  int       `json:"responseCode"`
+	Error         string    `json:"error"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// Webhook delivery statuses.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
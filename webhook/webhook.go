@@ -0,0 +1,237 @@
+// Package webhook provides a dispatcher that posts the forum events
+// to the webhooks registered by the admins. The events are queued in the
+// data store and the failed deliveries are retried with exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/disintegration/bebop/safehttp"
+	"github.com/disintegration/bebop/store"
+)
+
+// Request headers. The signature is the hex-encoded HMAC-SHA256 of the request body
+// keyed with the webhook secret, prefixed with "sha256=".
+const (
+	SignatureHeader = "X-Bebop-Signature"
+	EventHeader     = "X-Bebop-Event"
+	DeliveryHeader  = "X-Bebop-Delivery"
+)
+
+const (
+	// maxAttempts is the number of attempts after which a delivery fails.
+	maxAttempts = 8
+	// retryDelay is the delay before the first retry, doubled on every next one.
+	retryDelay = 30 * time.Second
+	// maxRetryDelay is the maximum delay between the retries.
+	maxRetryDelay = 6 * time.Hour
+	// batchSize is the number of the due deliveries claimed at once.
+	batchSize = 20
+	// requestTimeout is the default timeout of the webhook requests.
+	requestTimeout = 10 * time.Second
+	// claimTimeout is how long the claimed deliveries are kept from the other
+	// dispatchers. It is long enough to attempt a whole batch.
+	claimTimeout = batchSize*requestTimeout + time.Minute
+	// maxErrorLen is the maximum length of the delivery error recorded in the store.
+	maxErrorLen = 500
+)
+
+// Payload is the JSON body of a webhook request. The topic, the comment
+// and the user are included depending on the event.
+type Payload struct {
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"createdAt"`
+	// ActorID is the user who caused the event.
+	ActorID int64          `json:"actorId"`
+	Topic   *store.Topic   `json:"topic,omitempty"`
+	Comment *store.Comment `json:"comment,omitempty"`
+	User    *store.User    `json:"user,omitempty"`
+}
+
+// Config is a webhook dispatcher configuration.
+type Config struct {
+	Logger       *log.Logger
+	WebhookStore store.WebhookStore
+	// Client sends the webhook requests. If it is nil, a client with a 10 second timeout
+	// that refuses to connect to internal network addresses is used.
+	Client *http.Client
+}
+
+// Dispatcher queues the webhook events and delivers them.
+type Dispatcher struct {
+	*Config
+	client *http.Client
+}
+
+// NewDispatcher creates a new webhook dispatcher.
+func NewDispatcher(config *Config) *Dispatcher {
+	d := &Dispatcher{Config: config, client: config.Client}
+	if d.client == nil {
+		d.client = safehttp.NewClient(requestTimeout)
+	}
+	return d
+}
+
+// Publish queues the event for the webhooks subscribed to it.
+// Errors are logged: a missing webhook event is not worth failing the request that caused it.
+func (d *Dispatcher) Publish(p *Payload) {
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		d.Logger.Printf("webhook: marshal payload: %s", err)
+		return
+	}
+
+	err = d.WebhookStore.Enqueue(p.Event, data)
+	if err != nil {
+		d.Logger.Printf("webhook: enqueue %s: %s", p.Event, err)
+	}
+}
+
+// Run delivers the due events every interval until the stop channel is closed.
+func (d *Dispatcher) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		d.SendDue()
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// SendDue attempts all the due deliveries. The deliveries are claimed in the store
+// first, so several dispatchers sharing the store never attempt the same delivery.
+func (d *Dispatcher) SendDue() {
+	webhooks := make(map[int64]*store.Webhook)
+
+	for {
+		now := time.Now()
+		deliveries, err := d.WebhookStore.ClaimDue(now, now.Add(claimTimeout), batchSize)
+		if err != nil {
+			d.Logger.Printf("webhook: claim due deliveries: %s", err)
+			return
+		}
+
+		for _, delivery := range deliveries {
+			w, ok := webhooks[delivery.WebhookID]
+			if !ok {
+				w, err = d.WebhookStore.Get(delivery.WebhookID)
+				if err == store.ErrNotFound {
+					// The webhook has been deleted with its deliveries.
+					continue
+				}
+				if err != nil {
+					d.Logger.Printf("webhook: get webhook %d: %s", delivery.WebhookID, err)
+					return
+				}
+				webhooks[w.ID] = w
+			}
+
+			err = d.deliver(w, delivery)
+			if err != nil {
+				d.Logger.Printf("webhook: save delivery %d result: %s", delivery.ID, err)
+				return
+			}
+		}
+
+		if len(deliveries) < batchSize {
+			return
+		}
+	}
+}
+
+// deliver makes a delivery attempt and records its result.
+func (d *Dispatcher) deliver(w *store.Webhook, delivery *store.WebhookDelivery) error {
+	code, err := d.send(w, delivery)
+	if err == nil {
+		return d.WebhookStore.SetDeliveryResult(delivery.ID, store.WebhookDeliverySucceeded, code, "", time.Now())
+	}
+
+	errorMessage := err.Error()
+	if len(errorMessage) > maxErrorLen {
+		errorMessage = errorMessage[:maxErrorLen]
+	}
+
+	attempts := delivery.Attempts + 1
+	if attempts >= maxAttempts {
+		return d.WebhookStore.SetDeliveryResult(delivery.ID, store.WebhookDeliveryFailed, code, errorMessage, time.Now())
+	}
+	return d.WebhookStore.SetDeliveryResult(delivery.ID, store.WebhookDeliveryPending, code, errorMessage, time.Now().Add(backoff(attempts)))
+}
+
+// send posts the delivery payload to the webhook. A response with a status
+// other than 2xx is an error. It returns the response status code, if any.
+func (d *Dispatcher) send(w *store.Webhook, delivery *store.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequest("POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bebop-webhook")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt after the given number of attempts.
+func backoff(attempts int) time.Duration {
+	delay := retryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+// Sign returns the signature of the request body sent with the SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidURL checks if the webhook URL is an absolute http or https URL
+// that does not point to an internal network address.
+func ValidURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return safehttp.CheckURL(u) == nil
+}
@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/disintegration/bebop/store"
+	"github.com/disintegration/bebop/store/memory"
+)
+
+func TestDispatcher(t *testing.T) {
+	type request struct {
+		event     string
+		delivery  string
+		signature string
+		body      []byte
+	}
+	var (
+		requests []request
+		status   = http.StatusOK
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read request body: %s", err)
+		}
+		requests = append(requests, request{
+			event:     r.Header.Get(EventHeader),
+			delivery:  r.Header.Get(DeliveryHeader),
+			signature: r.Header.Get(SignatureHeader),
+			body:      body,
+		})
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	st := memory.New()
+	d := NewDispatcher(&Config{
+		Logger:       log.New(ioutil.Discard, "", 0),
+		WebhookStore: st.Webhooks(),
+		Client:       server.Client(),
+	})
+
+	id, err := st.Webhooks().New(server.URL, "secret", []string{store.WebhookTopicCreated})
+	if err != nil {
+		t.Fatalf("failed to create webhook: %s", err)
+	}
+
+	d.Publish(&Payload{
+		Event:   store.WebhookTopicCreated,
+		ActorID: 1,
+		Topic:   &store.Topic{ID: 2, AuthorID: 1, Title: "Topic"},
+	})
+	d.Publish(&Payload{Event: store.WebhookCommentCreated, ActorID: 1})
+	d.SendDue()
+
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	req := requests[0]
+	if req.event != store.WebhookTopicCreated || req.delivery == "" || req.signature != Sign("secret", req.body) {
+		t.Fatalf("bad request headers: %+v", req)
+	}
+	var p Payload
+	if err := json.Unmarshal(req.body, &p); err != nil {
+		t.Fatalf("failed to unmarshal payload: %s", err)
+	}
+	if p.Event != store.WebhookTopicCreated || p.ActorID != 1 || p.Topic == nil || p.Topic.Title != "Topic" || p.Comment != nil || p.CreatedAt.IsZero() {
+		t.Fatalf("bad payload: %s", req.body)
+	}
+
+	deliveries, count, err := st.Webhooks().GetDeliveries(id, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to get deliveries: %s", err)
+	}
+	if count != 1 || deliveries[0].Status != store.WebhookDeliverySucceeded || deliveries[0].Attempts != 1 || deliveries[0].ResponseCode != http.StatusOK {
+		t.Fatalf("bad delivery: %+v", deliveries[0])
+	}
+
+	// A failed delivery is retried later.
+	status = http.StatusInternalServerError
+	d.Publish(&Payload{Event: store.WebhookTopicCreated, ActorID: 1})
+	d.SendDue()
+	d.SendDue()
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	deliveries, _, err = st.Webhooks().GetDeliveries(id, 0, 1)
+	if err != nil {
+		t.Fatalf("failed to get deliveries: %s", err)
+	}
+	delivery := deliveries[0]
+	if delivery.Status != store.WebhookDeliveryPending || delivery.Attempts != 1 || delivery.ResponseCode != http.StatusInternalServerError || delivery.Error == "" {
+		t.Fatalf("bad failed delivery: %+v", delivery)
+	}
+	if delay := delivery.NextAttemptAt.Sub(time.Now()); delay < 20*time.Second || delay > retryDelay {
+		t.Fatalf("bad retry delay: %s", delay)
+	}
+
+	// The delivery fails after the last attempt.
+	for i := 1; i < maxAttempts-1; i++ {
+		err = st.Webhooks().SetDeliveryResult(delivery.ID, store.WebhookDeliveryPending, 0, "", time.Now())
+		if err != nil {
+			t.Fatalf("failed to set delivery result: %s", err)
+		}
+	}
+	d.SendDue()
+	deliveries, _, err = st.Webhooks().GetDeliveries(id, 0, 1)
+	if err != nil {
+		t.Fatalf("failed to get deliveries: %s", err)
+	}
+	if deliveries[0].Status != store.WebhookDeliveryFailed || deliveries[0].Attempts != maxAttempts {
+		t.Fatalf("expected failed delivery, got %+v", deliveries[0])
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for _, tc := range []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{20, maxRetryDelay},
+	} {
+		if got := backoff(tc.attempts); got != tc.want {
+			t.Fatalf("backoff(%d): want %s got %s", tc.attempts, tc.want, got)
+		}
+	}
+}
+
+func TestValidURL(t *testing.T) {
+	for url, want := range map[string]bool{
+		"https://example.com/hook": true,
+		"http://example.com:8080":  true,
+		"http://localhost:8080":    false,
+		"http://127.0.0.1/hook":    false,
+		"http://10.0.0.1/hook":     false,
+		"http://169.254.169.254/":  false,
+		"http://[::1]/hook":        false,
+		"ftp://example.com":        false,
+		"https://":                 false,
+		"/hook":                    false,
+		"":                         false,
+	} {
+		if got := ValidURL(url); got != want {
+			t.Fatalf("ValidURL(%q): want %v got %v", url, want, got)
+		}
+	}
+}